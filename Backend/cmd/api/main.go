@@ -19,7 +19,10 @@ import (
 	channelApp "pink/internal/application/channel"
 	dmApp "pink/internal/application/dm"
 	feedApp "pink/internal/application/feed"
+	notificationApp "pink/internal/application/notification"
+	permissionApp "pink/internal/application/permission"
 	privacyApp "pink/internal/application/privacy"
+	realtimeApp "pink/internal/application/realtime"
 	serverApp "pink/internal/application/server"
 	userApp "pink/internal/application/user"
 	"pink/internal/config"
@@ -29,6 +32,7 @@ import (
 	"pink/internal/infrastructure/livekit"
 	"pink/internal/infrastructure/ome"
 	"pink/internal/infrastructure/postgres"
+	"pink/internal/infrastructure/storage"
 	wsInfra "pink/internal/infrastructure/ws"
 )
 
@@ -105,37 +109,122 @@ func main() {
 	reactionRepo := postgres.NewReactionRepository(dbPool)
 	convRepo := postgres.NewConversationRepository(dbPool)
 	dmMessageRepo := postgres.NewDMMessageRepository(dbPool)
+	messageReactionRepo := postgres.NewMessageReactionRepository(dbPool)
+	messageRevisionRepo := postgres.NewMessageRevisionRepository(dbPool)
+	mediaRepo := postgres.NewMediaRepository(dbPool)
 	wallPostRepo := postgres.NewWallPostRepository(dbPool)
 	banRepo := postgres.NewBanRepository(dbPool)
 	auditRepo := postgres.NewAuditLogRepository(dbPool)
+	inviteRepo := postgres.NewInviteRepository(dbPool)
 	notificationRepo := postgres.NewNotificationRepository(dbPool)
+	notificationDispatcher := notificationApp.NewDispatcher(notificationRepo)
+
+	// Uploaded files, shared by uploads and message attachments
+	uploadDir := "./uploads"
+	mediaStorage := storage.NewLocalStorage(uploadDir)
+
+	// Initialize permission resolution, shared by every channel access check
+	overwriteRepo := postgres.NewOverwriteRepository(dbPool)
+	permissionResolver := permissionApp.NewResolver(permissionApp.NewEngine(), serverRepo, memberRepo, roleRepo, channelRepo, overwriteRepo)
 
 	// Initialize services
 	privacyRepo := postgres.NewPrivacyRepository(dbPool)
 	readStateRepo := postgres.NewReadStateRepository(dbPool)
 	userService := userApp.NewService(userRepo, sessionRepo, followRepo, privacyRepo, passwordHasher, jwtService)
+	userService.SetNotifier(notificationDispatcher)
 	serverService := serverApp.NewService(serverRepo, memberRepo, roleRepo, channelRepo, joinRequestRepo, banRepo, auditRepo)
-	channelService := channelApp.NewService(channelRepo, memberRepo, serverRepo, readStateRepo)
-	feedService := feedApp.NewService(postRepo, reactionRepo, userRepo, notificationRepo)
+	inviteService := serverApp.NewInviteService(serverService, inviteRepo, permissionResolver)
+	channelService := channelApp.NewService(channelRepo, memberRepo, serverRepo, readStateRepo, overwriteRepo, roleRepo, auditRepo, permissionResolver)
+	feedService := feedApp.NewService(postRepo, reactionRepo, userRepo, notificationDispatcher)
 	dmService := dmApp.NewService(convRepo, dmMessageRepo)
+	dmService.SetReactionRepository(messageReactionRepo)
+	dmService.SetRevisionRepository(messageRevisionRepo)
+	dmService.SetMediaStore(mediaRepo, mediaStorage)
 
 	// Initialize privacy service
 	privacyService := privacyApp.NewService(privacyRepo, followRepo)
 
 	// Initialize WebSocket hub
-	wsHub := wsInfra.NewHub()
-	go wsHub.Run()
+	wsHub := wsInfra.NewHub(redisClient, wsInfra.Options{
+		SendBufferSize:     cfg.WS.SendBufferSize,
+		SlowConsumerPolicy: wsInfra.SlowConsumerPolicy(cfg.WS.SlowConsumerPolicy),
+		WriteTimeout:       cfg.WS.WriteTimeout,
+		PongTimeout:        cfg.WS.PongTimeout,
+	})
+	go wsHub.Run(ctx)
 	logger.Info("WebSocket hub started")
 
+	// Push notifications to the recipient's connections as they are raised
+	notificationDispatcher.AddDeliverer(handlers.NewWebSocketNotificationDeliverer(wsHub))
+
 	// Initialize live streaming repositories
 	streamRepo := postgres.NewStreamRepository(dbPool)
 	streamMessageRepo := postgres.NewStreamMessageRepository(dbPool)
 
-	wsHandler := handlers.NewWebSocketHandler(wsHub, userService, streamMessageRepo)
+	// Initialize WebSocket subscription authorization
+	subscriptionAuthorizer := realtimeApp.NewAuthorizer(convRepo, serverRepo, memberRepo, streamRepo, permissionResolver)
+	wsHub.SetAuthorizer(subscriptionAuthorizer)
+
+	// Initialize rich presence, delivered only to users allowed to see it
+	presenceRepo := postgres.NewPresenceRepository(dbPool)
+	presenceService := realtimeApp.NewPresenceService(presenceRepo, presenceRepo, privacyService, wsHub)
+	wsHub.SetPresenceListener(func(userID string, online bool) {
+		if online {
+			presenceService.UserOnline(userID)
+		} else {
+			presenceService.UserOffline(userID)
+			inviteService.RemoveTemporaryMemberships(userID)
+		}
+	})
+
+	// Publish server, member, role and channel changes to server subscribers
+	serverEvents := handlers.NewServerEventPublisher(wsHub, serverRepo, userRepo)
+	serverService.SetEventPublisher(serverEvents)
+	channelService.SetEventPublisher(serverEvents)
+	notificationSettingsRepo := postgres.NewNotificationSettingsRepository(dbPool)
+	serverService.SetNotificationSettingsRepository(notificationSettingsRepo)
 
-	// Initialize channel message service and handler
+	// Initialize channel message service
 	channelMessageRepo := postgres.NewChannelMessageRepository(dbPool)
-	messageService := channelApp.NewMessageService(channelMessageRepo, channelRepo, memberRepo, serverRepo)
+	messageService := channelApp.NewMessageService(channelMessageRepo, channelRepo, memberRepo, serverRepo, auditRepo, permissionResolver)
+	messageService.SetReactionRepository(messageReactionRepo)
+	messageService.SetRevisionRepository(messageRevisionRepo)
+	messageService.SetMediaStore(mediaRepo, mediaStorage)
+	messageService.SetMentionService(channelApp.NewMentionService(
+		userRepo, memberRepo, roleRepo, notificationSettingsRepo, readStateRepo,
+		notificationDispatcher, permissionResolver, wsHub,
+	))
+
+	// Threads publish their changes to subscribers of the parent channel
+	threadRepo := postgres.NewThreadRepository(dbPool)
+	channelEvents := handlers.NewChannelEventPublisher(wsHub)
+	threadService := channelApp.NewThreadService(messageService, threadRepo)
+	threadService.SetEventPublisher(channelEvents)
+
+	// Scheduled messages are sent as their author once due
+	scheduledMessageRepo := postgres.NewScheduledMessageRepository(dbPool)
+	scheduledService := channelApp.NewScheduledMessageService(messageService, scheduledMessageRepo)
+	scheduledService.SetEventPublisher(channelEvents)
+	scheduledService.SetNotifier(notificationDispatcher)
+
+	// Published announcements are copied into the channels following them
+	channelFollowRepo := postgres.NewChannelFollowRepository(dbPool)
+	followService := channelApp.NewFollowService(messageService, channelFollowRepo)
+	followService.SetEventPublisher(channelEvents)
+
+	wsHandler := handlers.NewWebSocketHandler(
+		wsHub, userService, streamMessageRepo, subscriptionAuthorizer, channelRepo, presenceService,
+		messageService, channelService, dmService,
+	)
+
+	// Slowmode cooldowns and publish limits live in Redis so they hold across
+	// instances
+	if redisClient != nil {
+		slowmodeLimiter := cache.NewSlowmodeLimiter(redisClient)
+		messageService.SetSlowmodeLimiter(slowmodeLimiter)
+		wsHandler.SetStreamSlowmode(streamRepo, slowmodeLimiter, permissionResolver)
+		followService.SetCrosspostLimiter(cache.NewCrosspostLimiter(redisClient))
+	}
 
 	// Initialize live streaming repositories
 	// streamRepo already initialized above
@@ -145,14 +234,18 @@ func main() {
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userService)
 	userHandler := handlers.NewUserHandler(userService)
-	serverHandler := handlers.NewServerHandler(serverService, userRepo)
+	serverHandler := handlers.NewServerHandler(serverService, userRepo, wsHandler)
+	inviteHandler := handlers.NewInviteHandler(inviteService)
 	serverWallHandler := handlers.NewServerWallHandler(wallPostRepo, memberRepo, serverRepo)
-	channelHandler := handlers.NewChannelHandler(channelService)
+	channelHandler := handlers.NewChannelHandler(channelService, wsHandler)
 	channelMessageHandler := handlers.NewChannelMessageHandler(messageService, wsHandler)
+	threadHandler := handlers.NewThreadHandler(threadService, wsHandler)
+	scheduledHandler := handlers.NewScheduledMessageHandler(scheduledService)
+	channelFollowHandler := handlers.NewChannelFollowHandler(followService)
 	feedHandler := handlers.NewFeedHandler(feedService)
 	dmHandler := handlers.NewDMHandler(dmService, wsHub, userRepo)
 	liveHandler := handlers.NewLiveHandler(streamRepo, streamMessageRepo, categoryRepo, memberRepo, recordingRepo)
-	notificationHandler := handlers.NewNotificationHandler(notificationRepo)
+	notificationHandler := handlers.NewNotificationHandler(notificationRepo, notificationDispatcher)
 	searchRepo := postgres.NewSearchRepository(dbPool)
 	searchHandler := handlers.NewSearchHandler(searchRepo)
 	// Note: voiceChannelRepo removed - using unified channelRepo for voice channels
@@ -179,15 +272,13 @@ func main() {
 		logger.Warn("LiveKit not configured, voice features will be unavailable")
 	}
 
-	voiceHandler := handlers.NewVoiceHandler(channelRepo, memberRepo, serverRepo, userRepo, voiceParticipantRepo, livekitService)
+	voiceHandler := handlers.NewVoiceHandler(channelRepo, memberRepo, serverRepo, userRepo, voiceParticipantRepo, permissionResolver, livekitService)
 	webhookHandler := handlers.NewWebhookHandler(voiceParticipantRepo, channelRepo, userRepo, wsHub, livekitAPIKey, livekitAPISecret)
-	mediaRepo := postgres.NewMediaRepository(dbPool)
-	uploadDir := "./uploads"
 	baseURL := "http://localhost:" + cfg.HTTP.Port
 	mediaHandler := handlers.NewMediaHandler(mediaRepo, uploadDir, baseURL)
 	privacyHandler := handlers.NewPrivacyHandler(privacyService)
 	settingsHandler := handlers.NewSettingsHandler(userRepo)
-	healthHandler := handlers.NewHealthHandler(dbPool, redisClient)
+	healthHandler := handlers.NewHealthHandler(dbPool, redisClient, wsHub)
 
 	// Initialize OME integration
 	omeSecretKey := os.Getenv("OME_SECRET_KEY")
@@ -197,7 +288,19 @@ func main() {
 	omeWorker := ome.NewWorker(omeClient, streamRepo, logger)
 	go omeWorker.Start(ctx)
 
-	omeWebhookHandler := handlers.NewOMEWebhookHandler(streamRepo, followRepo, notificationRepo, recordingRepo, omeSecretKey, logger)
+	auditRetentionWorker := serverApp.NewAuditRetentionWorker(auditRepo, cfg.Audit.Retention, cfg.Audit.SweepInterval)
+	go auditRetentionWorker.Start(ctx)
+
+	threadArchiveWorker := channelApp.NewThreadArchiveWorker(threadService, cfg.Threads.ArchiveInterval)
+	go threadArchiveWorker.Start(ctx)
+
+	revisionRetentionWorker := channelApp.NewRevisionRetentionWorker(messageRevisionRepo, cfg.Revisions.SweepInterval)
+	go revisionRetentionWorker.Start(ctx)
+
+	scheduledMessageWorker := channelApp.NewScheduledMessageWorker(scheduledService, cfg.Scheduled.PublishInterval)
+	go scheduledMessageWorker.Start(ctx)
+
+	omeWebhookHandler := handlers.NewOMEWebhookHandler(streamRepo, followRepo, notificationDispatcher, recordingRepo, omeSecretKey, logger)
 
 	// Initialize call handler for voice/video call signaling
 	callHandler := handlers.NewCallHandler(wsHandler, userRepo, livekitService)
@@ -235,9 +338,13 @@ func main() {
 		AuthHandler:           authHandler,
 		UserHandler:           userHandler,
 		ServerHandler:         serverHandler,
+		InviteHandler:         inviteHandler,
 		ServerWallHandler:     serverWallHandler,
 		ChannelHandler:        channelHandler,
 		ChannelMessageHandler: channelMessageHandler,
+		ThreadHandler:         threadHandler,
+		ScheduledHandler:      scheduledHandler,
+		ChannelFollowHandler:  channelFollowHandler,
 		FeedHandler:           feedHandler,
 		DMHandler:             dmHandler,
 		LiveHandler:           liveHandler,
@@ -186,6 +186,9 @@ type UpdateServerRequest struct {
 	Description string  `json:"description" validate:"max=500"`
 	IsPublic    bool    `json:"isPublic"`
 	Tag         *string `json:"tag,omitempty" validate:"omitempty,min=1,max=9"`
+
+	// Days channel message revisions are kept; 0 = as long as the message
+	RevisionRetentionDays *int `json:"revisionRetentionDays,omitempty"`
 }
 
 // ServerResponse represents a server in API responses.
@@ -201,6 +204,8 @@ type ServerResponse struct {
 	Tag          string    `json:"tag,omitempty"`
 	MyRole       string    `json:"myRole,omitempty"`
 	CreatedAt    string    `json:"createdAt,omitempty"`
+
+	RevisionRetentionDays int `json:"revisionRetentionDays"`
 }
 
 // MemberResponse represents a server member in API responses.
@@ -219,6 +224,10 @@ type MemberWithUserResponse struct {
 	JoinedAt string              `json:"joinedAt"`
 	User     *PublicUserResponse `json:"user,omitempty"`
 	RoleIDs  []string            `json:"roleIds,omitempty"`
+
+	CommunicationDisabledUntil *string `json:"communicationDisabledUntil,omitempty"`
+	InviteCode                 *string `json:"inviteCode,omitempty"`
+	IsTemporary                bool    `json:"isTemporary,omitempty"`
 }
 
 // UpdateMemberRoleRequest represents a role update request.
@@ -245,6 +254,50 @@ type BanResponse struct {
 	CreatedAt string `json:"createdAt"`
 }
 
+// AuditLogResponse represents an audit log entry in API responses.
+type AuditLogResponse struct {
+	ID         string                 `json:"id"`
+	ActorID    string                 `json:"actorId,omitempty"`
+	TargetID   string                 `json:"targetId,omitempty"`
+	ActionType string                 `json:"actionType"`
+	Changes    map[string]interface{} `json:"changes,omitempty"`
+	Reason     string                 `json:"reason,omitempty"`
+	CreatedAt  string                 `json:"createdAt"`
+}
+
+// ServerNotificationSettingsRequest represents a request to replace the
+// user's notification settings for a server.
+type ServerNotificationSettingsRequest struct {
+	Level            string  `json:"level" validate:"required,oneof=all mentions nothing"`
+	SuppressEveryone bool    `json:"suppressEveryone"`
+	SuppressRoles    bool    `json:"suppressRoles"`
+	MutedUntil       *string `json:"mutedUntil,omitempty"` // RFC 3339; omit to unmute
+}
+
+// ServerNotificationSettingsResponse represents the user's notification
+// settings for a server.
+type ServerNotificationSettingsResponse struct {
+	ServerID         string  `json:"serverId"`
+	Level            string  `json:"level"`
+	SuppressEveryone bool    `json:"suppressEveryone"`
+	SuppressRoles    bool    `json:"suppressRoles"`
+	MutedUntil       *string `json:"mutedUntil,omitempty"`
+}
+
+// ServerEventResponse is the payload of server-scoped WebSocket events.
+// Only the field matching the event type is set.
+type ServerEventResponse struct {
+	ServerID string                  `json:"serverId"`
+	ActorID  string                  `json:"actorId,omitempty"`
+	Server   *ServerResponse         `json:"server,omitempty"`
+	Member   *MemberWithUserResponse `json:"member,omitempty"`
+	Role     *RoleResponse           `json:"role,omitempty"`
+	Roles    []RoleResponse          `json:"roles,omitempty"`
+	Ban      *BanResponse            `json:"ban,omitempty"`
+	Channel  *ChannelResponse        `json:"channel,omitempty"`
+	Channels []ChannelResponse       `json:"channels,omitempty"`
+}
+
 type JoinRequestResponse struct {
 	ServerID  string `json:"serverId"`
 	UserID    string `json:"userId"`
@@ -266,6 +319,58 @@ type TimeoutMemberRequest struct {
 	Reason          string `json:"reason"`
 }
 
+// CreateInviteRequest represents a request to create an invite.
+type CreateInviteRequest struct {
+	ChannelID     *string `json:"channelId,omitempty"`
+	MaxUses       int     `json:"maxUses"`       // 0 = unlimited
+	MaxAgeSeconds int     `json:"maxAgeSeconds"` // 0 = never expires
+	Temporary     bool    `json:"temporary"`
+}
+
+// InviteResponse represents a server invite.
+type InviteResponse struct {
+	Code      string  `json:"code"`
+	ServerID  string  `json:"serverId"`
+	ChannelID *string `json:"channelId,omitempty"`
+	CreatorID string  `json:"creatorId"`
+	MaxUses   int     `json:"maxUses"`
+	Uses      int     `json:"uses"`
+	ExpiresAt *string `json:"expiresAt,omitempty"`
+	Temporary bool    `json:"temporary"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+// InvitePreviewResponse is the public view of an invite, shown before
+// joining.
+type InvitePreviewResponse struct {
+	Code      string                 `json:"code"`
+	Server    InviteServerResponse   `json:"server"`
+	Channel   *InviteChannelResponse `json:"channel,omitempty"`
+	ExpiresAt *string                `json:"expiresAt,omitempty"`
+}
+
+// InviteServerResponse is the part of a server shown in an invite preview.
+type InviteServerResponse struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description,omitempty"`
+	IconGradient [2]string `json:"iconGradient"`
+	MemberCount  int       `json:"memberCount"`
+}
+
+// InviteChannelResponse is the channel shown in an invite preview.
+type InviteChannelResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// RedeemInviteResponse is returned after redeeming an invite.
+type RedeemInviteResponse struct {
+	Server        ServerResponse `json:"server"`
+	ChannelID     *string        `json:"channelId,omitempty"`
+	AlreadyMember bool           `json:"alreadyMember"`
+}
+
 // CreateRoleRequest represents a request to create a role.
 type CreateRoleRequest struct {
 	Name        string `json:"name" validate:"required,min=1,max=100"`
@@ -281,6 +386,17 @@ type UpdateRoleRequest struct {
 	Position    *int    `json:"position,omitempty"`
 }
 
+// RolePositionUpdate moves a role to a new position.
+type RolePositionUpdate struct {
+	ID       string `json:"id" validate:"required"`
+	Position int    `json:"position" validate:"min=1"`
+}
+
+// ReorderRolesRequest represents a bulk role reorder request.
+type ReorderRolesRequest struct {
+	Updates []RolePositionUpdate `json:"updates" validate:"required,min=1,dive"`
+}
+
 // UpdateMemberRolesRequest represents a request to update member roles.
 type UpdateMemberRolesRequest struct {
 	RoleIDs []string `json:"roleIds"`
@@ -294,6 +410,7 @@ type CreateChannelRequest struct {
 	Description string  `json:"description" validate:"max=500"`
 	Type        string  `json:"type" validate:"omitempty,oneof=text voice announcement category video stage hybrid"`
 	ParentID    *string `json:"parentId,omitempty"`
+	IsPrivate   bool    `json:"isPrivate"`
 }
 
 // UpdateChannelRequest represents a channel update request.
@@ -302,6 +419,9 @@ type UpdateChannelRequest struct {
 	Description string  `json:"description" validate:"max=500"`
 	Position    *int    `json:"position,omitempty"`
 	ParentID    *string `json:"parentId,omitempty"` // Category ID for hierarchy
+	IsPrivate   *bool   `json:"isPrivate,omitempty"`
+
+	SlowmodeSeconds *int `json:"slowmodeSeconds,omitempty"` // 0 turns slowmode off
 }
 
 // ChannelPositionUpdate represents a single channel position update for bulk reordering.
@@ -327,6 +447,66 @@ type ChannelResponse struct {
 	ParentID    *string `json:"parentId,omitempty"`
 	IsPrivate   bool    `json:"isPrivate"`
 	CreatedAt   string  `json:"createdAt,omitempty"`
+
+	SlowmodeSeconds int `json:"slowmodeSeconds"`
+}
+
+// ChannelReadStateResponse represents the user's read state in a channel.
+type ChannelReadStateResponse struct {
+	ChannelID         string  `json:"channelId"`
+	LastReadMessageID *string `json:"lastReadMessageId,omitempty"`
+	LastReadAt        string  `json:"lastReadAt"`
+	MentionCount      int     `json:"mentionCount"`
+}
+
+// SetOverwriteRequest represents a request to create or replace a channel
+// permission overwrite.
+type SetOverwriteRequest struct {
+	Allow int64 `json:"allow"`
+	Deny  int64 `json:"deny"`
+}
+
+// OverwriteResponse represents a channel permission overwrite.
+type OverwriteResponse struct {
+	ID         string `json:"id"`
+	ChannelID  string `json:"channelId"`
+	TargetType string `json:"targetType"`
+	TargetID   string `json:"targetId"`
+	Allow      int64  `json:"allow"`
+	Deny       int64  `json:"deny"`
+}
+
+// PermissionExplanationResponse breaks down a member's effective permissions
+// in a channel.
+type PermissionExplanationResponse struct {
+	UserID       string                   `json:"userId"`
+	ChannelID    string                   `json:"channelId"`
+	Permissions  int64                    `json:"permissions"`
+	ShortCircuit string                   `json:"shortCircuit,omitempty"` // "owner" or "administrator"
+	Steps        []PermissionStepResponse `json:"steps"`
+	Bits         []PermissionBitResponse  `json:"bits"`
+}
+
+// PermissionStepResponse is one role or overwrite applied while resolving
+// permissions.
+type PermissionStepResponse struct {
+	Stage      string   `json:"stage"`
+	TargetType string   `json:"targetType"`
+	TargetID   string   `json:"targetId"`
+	Name       string   `json:"name,omitempty"`
+	Allow      int64    `json:"allow"`
+	Deny       int64    `json:"deny"`
+	Granted    []string `json:"granted"`
+	Revoked    []string `json:"revoked"`
+	Result     int64    `json:"result"`
+}
+
+// PermissionBitResponse explains the final state of a single permission.
+type PermissionBitResponse struct {
+	Permission string `json:"permission"`
+	Bit        int64  `json:"bit"`
+	Allowed    bool   `json:"allowed"`
+	Step       *int   `json:"step"` // index into steps, null if no step decided it
 }
 
 // === Feed DTOs ===
@@ -379,7 +559,8 @@ type StartConversationRequest struct {
 
 // SendMessageRequest represents a request to send a message.
 type SendMessageRequest struct {
-	Content string `json:"content" validate:"required,min=1,max=2000"`
+	Content       string   `json:"content" validate:"max=2000"`
+	AttachmentIDs []string `json:"attachmentIds,omitempty"`
 }
 
 // EditMessageRequest represents a request to edit a message.
@@ -420,7 +601,11 @@ type DMMessageResponse struct {
 	SenderID       string                    `json:"senderId"`
 	Content        string                    `json:"content"`
 	IsEdited       bool                      `json:"isEdited"`
+	IsPinned       bool                      `json:"isPinned"`
+	PinnedAt       *string                   `json:"pinnedAt,omitempty"`
 	Sender         *ConversationUserResponse `json:"sender,omitempty"`
+	Attachments    []AttachmentResponse      `json:"attachments,omitempty"`
+	Reactions      []ReactionResponse        `json:"reactions,omitempty"`
 	CreatedAt      string                    `json:"createdAt"`
 }
 
@@ -428,8 +613,9 @@ type DMMessageResponse struct {
 
 // SendChannelMessageRequest represents a request to send a channel message.
 type SendChannelMessageRequest struct {
-	Content   string  `json:"content" validate:"required,min=1,max=2000"`
-	ReplyToID *string `json:"replyToId,omitempty"`
+	Content       string   `json:"content" validate:"max=2000"`
+	ReplyToID     *string  `json:"replyToId,omitempty"`
+	AttachmentIDs []string `json:"attachmentIds,omitempty"`
 }
 
 // EditChannelMessageRequest represents a request to edit a channel message.
@@ -437,18 +623,69 @@ type EditChannelMessageRequest struct {
 	Content string `json:"content" validate:"required,min=1,max=2000"`
 }
 
+// BulkDeleteMessagesRequest represents a request to delete many channel
+// messages at once. Every filter that is set must match.
+type BulkDeleteMessagesRequest struct {
+	MessageIDs     []string `json:"messageIds,omitempty"`
+	AuthorID       string   `json:"authorId,omitempty"`
+	After          *string  `json:"after,omitempty"`  // RFC 3339
+	Before         *string  `json:"before,omitempty"` // RFC 3339
+	HasLinks       bool     `json:"hasLinks"`
+	HasAttachments bool     `json:"hasAttachments"`
+	Limit          int      `json:"limit"` // Defaults to the number of messageIds, or 100
+}
+
+// BulkDeleteMessagesResponse represents the result of a bulk delete.
+type BulkDeleteMessagesResponse struct {
+	Deleted    int      `json:"deleted"`
+	MessageIDs []string `json:"messageIds"`
+}
+
 // ChannelMessageResponse represents a channel message in API responses.
 type ChannelMessageResponse struct {
-	ID        string                        `json:"id"`
-	ChannelID string                        `json:"channelId"`
-	ServerID  string                        `json:"serverId"`
-	AuthorID  string                        `json:"authorId"`
-	Content   string                        `json:"content"`
-	IsEdited  bool                          `json:"isEdited"`
-	IsPinned  bool                          `json:"isPinned"`
-	ReplyToID *string                       `json:"replyToId,omitempty"`
-	Author    *ChannelMessageAuthorResponse `json:"author,omitempty"`
-	CreatedAt string                        `json:"createdAt"`
+	ID          string                        `json:"id"`
+	ChannelID   string                        `json:"channelId"`
+	ServerID    string                        `json:"serverId"`
+	AuthorID    string                        `json:"authorId"`
+	Content     string                        `json:"content"`
+	IsEdited    bool                          `json:"isEdited"`
+	IsPinned    bool                          `json:"isPinned"`
+	PinnedAt    *string                       `json:"pinnedAt,omitempty"`
+	ReplyToID   *string                       `json:"replyToId,omitempty"`
+	ThreadID    *string                       `json:"threadId,omitempty"`
+	Author      *ChannelMessageAuthorResponse `json:"author,omitempty"`
+	Attachments []AttachmentResponse          `json:"attachments,omitempty"`
+	Reactions   []ReactionResponse            `json:"reactions,omitempty"`
+	Mentions    *MessageMentionsResponse      `json:"mentions,omitempty"`
+	CreatedAt   string                        `json:"createdAt"`
+
+	CrosspostedAt *string                  `json:"crosspostedAt,omitempty"` // When an announcement was published
+	Source        *CrosspostSourceResponse `json:"source,omitempty"`        // Set on copies of published announcements
+}
+
+// CrosspostSourceResponse attributes a copy of a published announcement to
+// the channel and server it came from.
+type CrosspostSourceResponse struct {
+	MessageID   string `json:"messageId"`
+	ChannelID   string `json:"channelId"`
+	ServerID    string `json:"serverId"`
+	ChannelName string `json:"channelName"`
+	ServerName  string `json:"serverName"`
+}
+
+// MessageSearchHitResponse represents a message found by a server-wide
+// search. Snippet is HTML-escaped, with matched words wrapped in <mark>.
+type MessageSearchHitResponse struct {
+	Message ChannelMessageResponse `json:"message"`
+	Snippet string                 `json:"snippet"`
+}
+
+// MessageMentionsResponse represents who a channel message mentions.
+type MessageMentionsResponse struct {
+	Users    []string `json:"users"`
+	Roles    []string `json:"roles"`
+	Everyone bool     `json:"everyone"`
+	Here     bool     `json:"here"`
 }
 
 // ChannelMessageAuthorResponse represents the author info in a channel message.
@@ -459,6 +696,154 @@ type ChannelMessageAuthorResponse struct {
 	AvatarGradient [2]string `json:"avatarGradient"`
 }
 
+// === Thread DTOs ===
+
+// CreateThreadRequest represents a request to create a thread.
+type CreateThreadRequest struct {
+	Name               string  `json:"name"`
+	MessageID          *string `json:"messageId,omitempty"`          // Start the thread from this message
+	AutoArchiveMinutes int     `json:"autoArchiveMinutes,omitempty"` // 60, 1440, 4320 or 10080
+}
+
+// UpdateThreadRequest represents a request to update a thread.
+type UpdateThreadRequest struct {
+	Name               *string `json:"name,omitempty"`
+	Archived           *bool   `json:"archived,omitempty"`
+	Locked             *bool   `json:"locked,omitempty"`
+	AutoArchiveMinutes *int    `json:"autoArchiveMinutes,omitempty"`
+}
+
+// ThreadResponse represents a thread in API responses.
+type ThreadResponse struct {
+	ID                 string                   `json:"id"`
+	ChannelID          string                   `json:"channelId"`
+	ServerID           string                   `json:"serverId"`
+	ParentMessageID    *string                  `json:"parentMessageId,omitempty"`
+	OwnerID            string                   `json:"ownerId"`
+	Name               string                   `json:"name"`
+	MessageCount       int                      `json:"messageCount"`
+	MemberCount        int                      `json:"memberCount"`
+	IsArchived         bool                     `json:"isArchived"`
+	IsLocked           bool                     `json:"isLocked"`
+	AutoArchiveMinutes int                      `json:"autoArchiveMinutes"`
+	LastMessageAt      string                   `json:"lastMessageAt"`
+	ArchivedAt         *string                  `json:"archivedAt,omitempty"`
+	CreatedAt          string                   `json:"createdAt"`
+	ReadState          *ThreadReadStateResponse `json:"readState,omitempty"`
+}
+
+// ThreadReadStateResponse is how far the requesting user has read a thread.
+type ThreadReadStateResponse struct {
+	LastReadMessageID *string `json:"lastReadMessageId,omitempty"`
+	LastReadAt        string  `json:"lastReadAt"`
+}
+
+// ThreadMemberResponse represents a thread member in API responses.
+type ThreadMemberResponse struct {
+	ThreadID string `json:"threadId"`
+	UserID   string `json:"userId"`
+	JoinedAt string `json:"joinedAt"`
+}
+
+// ChannelEventResponse is the payload of channel-scoped WebSocket events.
+// Only the field matching the event type is set.
+type ChannelEventResponse struct {
+	ServerID     string                `json:"serverId"`
+	ChannelID    string                `json:"channelId"`
+	ActorID      string                `json:"actorId,omitempty"`
+	Thread       *ThreadResponse       `json:"thread,omitempty"`
+	ThreadMember *ThreadMemberResponse `json:"threadMember,omitempty"`
+}
+
+// === Scheduled Message DTOs ===
+
+// ScheduleMessageRequest represents a request to send a channel message later.
+type ScheduleMessageRequest struct {
+	Content       string   `json:"content" validate:"max=2000"`
+	AttachmentIDs []string `json:"attachmentIds,omitempty"`
+	PublishAt     string   `json:"publishAt"` // RFC 3339
+}
+
+// UpdateScheduledMessageRequest represents a change to a scheduled message.
+type UpdateScheduledMessageRequest struct {
+	Content       *string   `json:"content,omitempty" validate:"omitempty,max=2000"`
+	AttachmentIDs *[]string `json:"attachmentIds,omitempty"`
+	PublishAt     *string   `json:"publishAt,omitempty"` // RFC 3339
+}
+
+// ScheduledMessageResponse represents a scheduled message in API responses.
+type ScheduledMessageResponse struct {
+	ID            string   `json:"id"`
+	ServerID      string   `json:"serverId"`
+	ChannelID     string   `json:"channelId"`
+	AuthorID      string   `json:"authorId"`
+	Content       string   `json:"content"`
+	AttachmentIDs []string `json:"attachmentIds"`
+	PublishAt     string   `json:"publishAt"`
+	Status        string   `json:"status"`            // pending, publishing or failed
+	Failure       string   `json:"failure,omitempty"` // Why publishing failed
+	CreatedAt     string   `json:"createdAt"`
+	UpdatedAt     string   `json:"updatedAt"`
+}
+
+// === Channel Follow DTOs ===
+
+// FollowChannelRequest represents a request to follow an announcement
+// channel into the channel in the path.
+type FollowChannelRequest struct {
+	SourceChannelID string `json:"sourceChannelId" validate:"required"`
+}
+
+// ChannelFollowResponse represents a channel follow in API responses.
+type ChannelFollowResponse struct {
+	ID                string  `json:"id"`
+	SourceServerID    string  `json:"sourceServerId"`
+	SourceServerName  string  `json:"sourceServerName"`
+	SourceChannelID   string  `json:"sourceChannelId"`
+	SourceChannelName string  `json:"sourceChannelName"`
+	TargetServerID    string  `json:"targetServerId"`
+	TargetServerName  string  `json:"targetServerName"`
+	TargetChannelID   string  `json:"targetChannelId"`
+	TargetChannelName string  `json:"targetChannelName"`
+	CreatedBy         *string `json:"createdBy,omitempty"`
+	CreatedAt         string  `json:"createdAt"`
+}
+
+// === Reaction DTOs ===
+
+// EmojiResponse represents an emoji. ID is only set for custom emoji.
+type EmojiResponse struct {
+	Name string `json:"name"`
+	ID   string `json:"id,omitempty"`
+}
+
+// ReactionResponse represents one emoji's reactions on a message.
+type ReactionResponse struct {
+	Emoji EmojiResponse `json:"emoji"`
+	Count int           `json:"count"`
+	Me    bool          `json:"me"`
+}
+
+// ReactionUserResponse represents a user who reacted to a message.
+type ReactionUserResponse struct {
+	ID             string    `json:"id"`
+	Handle         string    `json:"handle"`
+	DisplayName    string    `json:"displayName"`
+	AvatarGradient [2]string `json:"avatarGradient"`
+	ReactedAt      string    `json:"reactedAt"`
+}
+
+// === Revision DTOs ===
+
+// MessageRevisionResponse represents what a message said before one of its
+// edits.
+type MessageRevisionResponse struct {
+	ID        string `json:"id"`
+	MessageID string `json:"messageId"`
+	Content   string `json:"content"`
+	EditedAt  string `json:"editedAt"`
+}
+
 // === Live Streaming DTOs ===
 
 // StartStreamRequest represents a request to start a stream.
@@ -477,6 +862,8 @@ type UpdateStreamRequest struct {
 	Description *string `json:"description,omitempty"`
 	CategoryID  *string `json:"categoryId,omitempty"`
 	IsNSFW      *bool   `json:"isNsfw,omitempty"`
+
+	SlowmodeSeconds *int `json:"slowmodeSeconds,omitempty"` // 0 turns slowmode off
 }
 
 // StreamResponse represents a stream in API responses.
@@ -499,6 +886,8 @@ type StreamResponse struct {
 	CreatedAt   string            `json:"createdAt"`
 	Streamer    *StreamerResponse `json:"streamer,omitempty"`
 	Category    *CategoryResponse `json:"category,omitempty"`
+
+	SlowmodeSeconds int `json:"slowmodeSeconds"`
 }
 
 // StreamerResponse represents streamer info in a stream response.
@@ -551,6 +940,13 @@ type NotificationActorResponse struct {
 	AvatarGradient [2]string `json:"avatarGradient"`
 }
 
+// NotificationEventResponse is the payload of a real-time notification event.
+// Notification is omitted when only the unread count changed.
+type NotificationEventResponse struct {
+	Notification *NotificationResponse `json:"notification,omitempty"`
+	UnreadCount  int                   `json:"unreadCount"`
+}
+
 // === Search DTOs ===
 
 // SearchUserResult represents a user in search results.
@@ -638,10 +1034,24 @@ type MediaResponse struct {
 	MimeType     string `json:"mimeType"`
 	Type         string `json:"type"`
 	Size         int64  `json:"size"`
+	Width        *int   `json:"width,omitempty"`
+	Height       *int   `json:"height,omitempty"`
 	URL          string `json:"url"`
 	CreatedAt    string `json:"createdAt"`
 }
 
+// AttachmentResponse represents a file attached to a message.
+type AttachmentResponse struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Type        string `json:"type"`
+	Size        int64  `json:"size"`
+	Width       *int   `json:"width,omitempty"`
+	Height      *int   `json:"height,omitempty"`
+	URL         string `json:"url"`
+}
+
 // === Privacy DTOs ===
 
 // PrivacySettingsResponse represents user privacy settings in API responses.
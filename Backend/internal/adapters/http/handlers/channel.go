@@ -7,19 +7,22 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"pink/internal/adapters/http/dto"
+	"pink/internal/adapters/http/middleware"
 	channelApp "pink/internal/application/channel"
 	"pink/internal/domain/channel"
 	"pink/internal/domain/server"
+	"pink/internal/domain/slowmode"
 )
 
 // ChannelHandler handles channel-related requests.
 type ChannelHandler struct {
 	channelService *channelApp.Service
+	wsHandler      *WebSocketHandler
 }
 
 // NewChannelHandler creates a new ChannelHandler.
-func NewChannelHandler(channelService *channelApp.Service) *ChannelHandler {
-	return &ChannelHandler{channelService: channelService}
+func NewChannelHandler(channelService *channelApp.Service, wsHandler *WebSocketHandler) *ChannelHandler {
+	return &ChannelHandler{channelService: channelService, wsHandler: wsHandler}
 }
 
 // List returns all channels in a server.
@@ -76,6 +79,7 @@ func (h *ChannelHandler) Create(c *fiber.Ctx) error {
 		Description: req.Description,
 		Type:        channel.ChannelType(channelType),
 		ParentID:    req.ParentID,
+		IsPrivate:   req.IsPrivate,
 		UserID:      userID,
 	})
 
@@ -111,12 +115,21 @@ func (h *ChannelHandler) Update(c *fiber.Ctx) error {
 		UserID:      userID,
 		Position:    req.Position,
 		ParentID:    req.ParentID,
+		IsPrivate:   req.IsPrivate,
+
+		SlowmodeSeconds: req.SlowmodeSeconds,
 	})
 
 	if err != nil {
 		return h.handleError(c, err)
 	}
 
+	// Moving a channel changes the category overwrites it inherits, and
+	// changing its privacy changes who may stay subscribed
+	if req.ParentID != nil || req.IsPrivate != nil {
+		h.wsHandler.RevalidateChannel(channelID)
+	}
+
 	return c.JSON(fiber.Map{
 		"data": channelToDTO(ch),
 	})
@@ -179,6 +192,131 @@ func (h *ChannelHandler) Reorder(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusOK)
 }
 
+// GetOverwrite returns a channel permission overwrite.
+// GET /servers/:id/channels/:chId/permissions/:targetType/:targetId
+func (h *ChannelHandler) GetOverwrite(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	ow, err := h.channelService.GetOverwrite(c.Context(), c.Params("id"), c.Params("chId"), userID,
+		channel.OverwriteTargetType(c.Params("targetType")), c.Params("targetId"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": overwriteToDTO(ow),
+	})
+}
+
+// SetOverwrite creates or replaces a channel permission overwrite.
+// PUT /servers/:id/channels/:chId/permissions/:targetType/:targetId
+func (h *ChannelHandler) SetOverwrite(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	channelID := c.Params("chId")
+
+	var req dto.SetOverwriteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	ow, err := h.channelService.SetOverwrite(c.Context(), channelApp.SetOverwriteCommand{
+		ServerID:   c.Params("id"),
+		ChannelID:  channelID,
+		UserID:     userID,
+		TargetType: channel.OverwriteTargetType(c.Params("targetType")),
+		TargetID:   c.Params("targetId"),
+		Allow:      server.Permission(req.Allow),
+		Deny:       server.Permission(req.Deny),
+	})
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	h.wsHandler.RevalidateChannel(channelID)
+
+	return c.JSON(fiber.Map{
+		"data": overwriteToDTO(ow),
+	})
+}
+
+// DeleteOverwrite removes a channel permission overwrite.
+// DELETE /servers/:id/channels/:chId/permissions/:targetType/:targetId
+func (h *ChannelHandler) DeleteOverwrite(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	channelID := c.Params("chId")
+
+	err := h.channelService.DeleteOverwrite(c.Context(), c.Params("id"), channelID, userID,
+		channel.OverwriteTargetType(c.Params("targetType")), c.Params("targetId"))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	h.wsHandler.RevalidateChannel(channelID)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ExplainPermissions breaks down a member's effective permissions in a channel.
+// GET /servers/:id/channels/:chId/members/:userId/permissions
+func (h *ChannelHandler) ExplainPermissions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	channelID := c.Params("chId")
+	targetUserID := c.Params("userId")
+
+	ex, err := h.channelService.ExplainPermissions(c.Context(), c.Params("id"), channelID, userID, targetUserID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	resp := dto.PermissionExplanationResponse{
+		UserID:       targetUserID,
+		ChannelID:    channelID,
+		Permissions:  int64(ex.Permissions),
+		ShortCircuit: string(ex.ShortCircuit),
+		Steps:        make([]dto.PermissionStepResponse, len(ex.Steps)),
+		Bits:         make([]dto.PermissionBitResponse, len(ex.Bits)),
+	}
+	for i, step := range ex.Steps {
+		resp.Steps[i] = dto.PermissionStepResponse{
+			Stage:      string(step.Stage),
+			TargetType: string(step.TargetType),
+			TargetID:   step.TargetID,
+			Name:       step.Name,
+			Allow:      int64(step.Allow),
+			Deny:       int64(step.Deny),
+			Granted:    permissionNames(step.Granted),
+			Revoked:    permissionNames(step.Revoked),
+			Result:     int64(step.Result),
+		}
+	}
+	for i, bit := range ex.Bits {
+		resp.Bits[i] = dto.PermissionBitResponse{
+			Permission: bit.Permission.Name(),
+			Bit:        int64(bit.Permission),
+			Allowed:    bit.Allowed,
+		}
+		if bit.Step >= 0 {
+			step := bit.Step
+			resp.Bits[i].Step = &step
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": resp,
+	})
+}
+
+func permissionNames(perms server.Permission) []string {
+	names := []string{}
+	for _, flag := range perms.Flags() {
+		names = append(names, flag.Name())
+	}
+	return names
+}
+
 type AckRequest struct {
 	MessageID string `json:"message_id"`
 }
@@ -211,6 +349,31 @@ func (h *ChannelHandler) Ack(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusOK)
 }
 
+// ReadStates returns the user's read states and mention counts in the
+// channels of a server they can see.
+// GET /servers/:id/read-states
+func (h *ChannelHandler) ReadStates(c *fiber.Ctx) error {
+	serverID := c.Params("id")
+	userID := c.Locals("userID").(string)
+
+	states, err := h.channelService.GetReadStates(c.Context(), serverID, userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	response := make([]dto.ChannelReadStateResponse, len(states))
+	for i, rs := range states {
+		response[i] = dto.ChannelReadStateResponse{
+			ChannelID:         rs.ChannelID,
+			LastReadMessageID: rs.LastReadMessageID,
+			LastReadAt:        rs.LastReadAt.Format("2006-01-02T15:04:05.000Z"),
+			MentionCount:      rs.MentionCount,
+		}
+	}
+
+	return c.JSON(fiber.Map{"data": response})
+}
+
 func (h *ChannelHandler) handleError(c *fiber.Ctx, err error) error {
 	switch {
 	case errors.Is(err, channel.ErrNotFound):
@@ -231,6 +394,11 @@ func (h *ChannelHandler) handleError(c *fiber.Ctx, err error) error {
 			"Not a member of this server",
 		))
 
+	case errors.Is(err, channel.ErrOverwriteNotFound), errors.Is(err, channel.ErrInvalidOverwrite),
+		errors.Is(err, server.ErrRoleNotFound), errors.Is(err, slowmode.ErrInvalidInterval):
+		status, resp := middleware.DomainError(err)
+		return c.Status(status).JSON(resp)
+
 	default:
 		slog.Error("channel handler error", slog.Any("error", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse(
@@ -251,5 +419,18 @@ func channelToDTO(ch *channel.Channel) dto.ChannelResponse {
 		ParentID:    ch.ParentID,
 		IsPrivate:   ch.IsPrivate,
 		CreatedAt:   ch.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+
+		SlowmodeSeconds: ch.SlowmodeSeconds,
+	}
+}
+
+func overwriteToDTO(ow *channel.PermissionOverwrite) dto.OverwriteResponse {
+	return dto.OverwriteResponse{
+		ID:         ow.ID,
+		ChannelID:  ow.ChannelID,
+		TargetType: string(ow.TargetType),
+		TargetID:   ow.TargetID,
+		Allow:      int64(ow.Allow),
+		Deny:       int64(ow.Deny),
 	}
 }
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"pink/internal/adapters/http/dto"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/ws"
+	wsInfra "pink/internal/infrastructure/ws"
+)
+
+// ChannelEventPublisher implements ws.ChannelEventPublisher. It converts the
+// changed domain objects to the DTOs the HTTP API returns and broadcasts them
+// to "channel:<id>" subscribers.
+type ChannelEventPublisher struct {
+	hub *wsInfra.Hub
+}
+
+// NewChannelEventPublisher creates a new ChannelEventPublisher.
+func NewChannelEventPublisher(hub *wsInfra.Hub) *ChannelEventPublisher {
+	return &ChannelEventPublisher{hub: hub}
+}
+
+// PublishChannelEvent broadcasts an event to the channel's subscribers.
+// Message payloads are sent in the same shape as messages sent over HTTP.
+func (p *ChannelEventPublisher) PublishChannelEvent(event ws.ChannelEvent) {
+	if message, ok := event.Payload.(*channel.ChannelMessage); ok {
+		p.broadcast(event.Type, event.ChannelID, ws.ChannelMessageEventData{
+			ServerID:  event.ServerID,
+			ChannelID: event.ChannelID,
+			Message:   toMap(channelMessageToDTO(message)),
+		})
+		return
+	}
+
+	resp := dto.ChannelEventResponse{
+		ServerID:  event.ServerID,
+		ChannelID: event.ChannelID,
+		ActorID:   event.ActorID,
+	}
+
+	switch payload := event.Payload.(type) {
+	case *channel.Thread:
+		thread := threadToDTO(payload)
+		resp.Thread = &thread
+	case *channel.ThreadMember:
+		member := threadMemberToDTO(payload)
+		resp.ThreadMember = &member
+	}
+
+	p.broadcast(event.Type, event.ChannelID, resp)
+}
+
+func (p *ChannelEventPublisher) broadcast(eventType ws.EventType, channelID string, payload interface{}) {
+	msg, err := ws.NewMessage(eventType, payload)
+	if err != nil {
+		slog.Error("failed to create WS message", slog.Any("error", err))
+		return
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("failed to marshal WS message", slog.Any("error", err))
+		return
+	}
+
+	p.hub.BroadcastToSubscription(ws.SubChannel, channelID, data)
+}
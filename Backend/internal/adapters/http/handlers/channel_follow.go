@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"pink/internal/adapters/http/dto"
+	"pink/internal/adapters/http/middleware"
+	channelApp "pink/internal/application/channel"
+	"pink/internal/domain/channel"
+)
+
+// ChannelFollowHandler handles announcement channel follow and publish
+// requests.
+type ChannelFollowHandler struct {
+	followService *channelApp.FollowService
+}
+
+// NewChannelFollowHandler creates a new ChannelFollowHandler.
+func NewChannelFollowHandler(followService *channelApp.FollowService) *ChannelFollowHandler {
+	return &ChannelFollowHandler{followService: followService}
+}
+
+// Follow follows an announcement channel into a text channel.
+// POST /servers/:id/channels/:chId/following
+func (h *ChannelFollowHandler) Follow(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	var req dto.FollowChannelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	if err := dto.Validate(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"VALIDATION_ERROR",
+			err.Error(),
+		))
+	}
+
+	follow, err := h.followService.Follow(c.Context(), channelApp.FollowChannelCommand{
+		ServerID:        c.Params("id"),
+		ChannelID:       c.Params("chId"),
+		SourceChannelID: req.SourceChannelID,
+		UserID:          userID,
+	})
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": channelFollowToDTO(follow),
+	})
+}
+
+// ListFollowing lists the announcement channels a channel follows.
+// GET /servers/:id/channels/:chId/following
+func (h *ChannelFollowHandler) ListFollowing(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	follows, err := h.followService.ListFollowing(c.Context(), c.Params("id"), c.Params("chId"), userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": channelFollowsToDTO(follows),
+	})
+}
+
+// ListFollowers lists the channels following an announcement channel.
+// GET /servers/:id/channels/:chId/followers
+func (h *ChannelFollowHandler) ListFollowers(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	follows, err := h.followService.ListFollowers(c.Context(), c.Params("id"), c.Params("chId"), userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": channelFollowsToDTO(follows),
+	})
+}
+
+// Unfollow removes a follow from either of its channels.
+// DELETE /servers/:id/channels/:chId/following/:followId
+// DELETE /servers/:id/channels/:chId/followers/:followId
+func (h *ChannelFollowHandler) Unfollow(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	err := h.followService.Unfollow(c.Context(), c.Params("id"), c.Params("chId"), c.Params("followId"), userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Crosspost publishes an announcement to the channels following its channel.
+// Copies are delivered in the background.
+// POST /servers/:id/channels/:chId/messages/:msgId/crosspost
+func (h *ChannelFollowHandler) Crosspost(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	msg, err := h.followService.Crosspost(c.Context(), c.Params("id"), c.Params("chId"), c.Params("msgId"), userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"data": channelMessageToDTO(msg),
+	})
+}
+
+func channelFollowsToDTO(follows []*channel.ChannelFollow) []dto.ChannelFollowResponse {
+	response := make([]dto.ChannelFollowResponse, len(follows))
+	for i, f := range follows {
+		response[i] = channelFollowToDTO(f)
+	}
+	return response
+}
+
+func channelFollowToDTO(f *channel.ChannelFollow) dto.ChannelFollowResponse {
+	return dto.ChannelFollowResponse{
+		ID:                f.ID,
+		SourceServerID:    f.SourceServerID,
+		SourceServerName:  f.SourceServerName,
+		SourceChannelID:   f.SourceChannelID,
+		SourceChannelName: f.SourceChannelName,
+		TargetServerID:    f.TargetServerID,
+		TargetServerName:  f.TargetServerName,
+		TargetChannelID:   f.TargetChannelID,
+		TargetChannelName: f.TargetChannelName,
+		CreatedBy:         f.CreatedBy,
+		CreatedAt:         f.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+	}
+}
@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 
 	"pink/internal/adapters/http/dto"
 	"pink/internal/adapters/http/middleware"
 	channelApp "pink/internal/application/channel"
 	"pink/internal/domain/channel"
+	"pink/internal/domain/history"
+	"pink/internal/domain/reaction"
 	"pink/internal/domain/ws"
 )
 
@@ -33,15 +37,17 @@ func (h *ChannelMessageHandler) GetMessages(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
 	serverID := c.Params("id")
 	channelID := c.Params("chId")
-	cursor := c.Query("cursor")
-	limit := c.QueryInt("limit", 20)
 
-	messages, nextCursor, err := h.messageService.GetMessages(c.Context(), channelApp.GetMessagesCommand{
+	cursor, err := historyCursor(c, history.DefaultLimit)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	messages, page, err := h.messageService.GetMessages(c.Context(), channelApp.GetMessagesCommand{
 		ServerID:  serverID,
 		ChannelID: channelID,
 		UserID:    userID,
 		Cursor:    cursor,
-		Limit:     limit,
 	})
 	if err != nil {
 		return h.handleError(c, err)
@@ -52,12 +58,7 @@ func (h *ChannelMessageHandler) GetMessages(c *fiber.Ctx) error {
 		response[i] = channelMessageToDTO(msg)
 	}
 
-	result := fiber.Map{"data": response}
-	if nextCursor != "" {
-		result["nextCursor"] = nextCursor
-	}
-
-	return c.JSON(result)
+	return c.JSON(historyResult(response, page))
 }
 
 // SendMessage sends a message to a channel.
@@ -76,11 +77,12 @@ func (h *ChannelMessageHandler) SendMessage(c *fiber.Ctx) error {
 	}
 
 	msg, err := h.messageService.SendMessage(c.Context(), channelApp.SendMessageCommand{
-		ServerID:  serverID,
-		ChannelID: channelID,
-		UserID:    userID,
-		Content:   req.Content,
-		ReplyToID: req.ReplyToID,
+		ServerID:      serverID,
+		ChannelID:     channelID,
+		UserID:        userID,
+		Content:       req.Content,
+		ReplyToID:     req.ReplyToID,
+		AttachmentIDs: req.AttachmentIDs,
 	})
 	if err != nil {
 		return h.handleError(c, err)
@@ -125,6 +127,21 @@ func (h *ChannelMessageHandler) EditMessage(c *fiber.Ctx) error {
 	})
 }
 
+// GetRevisions returns a message's edit history.
+// GET /servers/:id/channels/:chId/messages/:msgId/revisions
+func (h *ChannelMessageHandler) GetRevisions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	revisions, err := h.messageService.GetRevisions(c.Context(), c.Params("id"), c.Params("chId"), c.Params("msgId"), userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": revisionsToDTO(revisions),
+	})
+}
+
 // DeleteMessage deletes a message.
 // DELETE /servers/:id/channels/:chId/messages/:msgId
 func (h *ChannelMessageHandler) DeleteMessage(c *fiber.Ctx) error {
@@ -144,6 +161,74 @@ func (h *ChannelMessageHandler) DeleteMessage(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// BulkDeleteMessages deletes many messages at once.
+// POST /servers/:id/channels/:chId/messages/bulk-delete
+func (h *ChannelMessageHandler) BulkDeleteMessages(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+	channelID := c.Params("chId")
+
+	var req dto.BulkDeleteMessagesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	filter := channel.BulkDeleteFilter{
+		MessageIDs:     req.MessageIDs,
+		AuthorID:       req.AuthorID,
+		HasLinks:       req.HasLinks,
+		HasAttachments: req.HasAttachments,
+		Limit:          req.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 100
+		if len(req.MessageIDs) > 0 {
+			filter.Limit = len(req.MessageIDs)
+		}
+	}
+	after, errAfter := optionalTime(req.After)
+	before, errBefore := optionalTime(req.Before)
+	if errAfter != nil || errBefore != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"after and before must be RFC 3339 times",
+		))
+	}
+	filter.After = after
+	filter.Before = before
+
+	deleted, err := h.messageService.BulkDeleteMessages(c.Context(), channelApp.BulkDeleteCommand{
+		ServerID:  serverID,
+		ChannelID: channelID,
+		UserID:    userID,
+		Filter:    filter,
+	})
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	ids := make([]string, len(deleted))
+	for i, msg := range deleted {
+		ids[i] = msg.ID
+	}
+
+	if len(ids) > 0 && h.websocketHandler != nil {
+		h.websocketHandler.BroadcastToChannel(channelID, ws.EventChannelMessagesBulkDeleted, ws.MessagesBulkDeletedEventData{
+			ServerID:   serverID,
+			ChannelID:  channelID,
+			MessageIDs: ids,
+			ActorID:    userID,
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": dto.BulkDeleteMessagesResponse{Deleted: len(ids), MessageIDs: ids},
+	})
+}
+
 // SearchMessages searches messages in a channel.
 // GET /servers/:id/channels/:chId/messages/search
 func (h *ChannelMessageHandler) SearchMessages(c *fiber.Ctx) error {
@@ -175,21 +260,256 @@ func (h *ChannelMessageHandler) SearchMessages(c *fiber.Ctx) error {
 	})
 }
 
+// SearchServerMessages searches the messages of every channel in a server
+// the user can view.
+// GET /servers/:id/messages/search
+func (h *ChannelMessageHandler) SearchServerMessages(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	offset := c.QueryInt("offset", 0)
+
+	hits, total, err := h.messageService.SearchServerMessages(c.Context(), channelApp.SearchServerCommand{
+		ServerID: c.Params("id"),
+		UserID:   userID,
+		Query:    c.Query("q"),
+		Offset:   offset,
+		Limit:    c.QueryInt("limit", 25),
+	})
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	response := make([]dto.MessageSearchHitResponse, len(hits))
+	for i, hit := range hits {
+		response[i] = dto.MessageSearchHitResponse{
+			Message: channelMessageToDTO(hit.Message),
+			Snippet: hit.Snippet,
+		}
+	}
+
+	result := fiber.Map{"data": response, "total": total}
+	if next := offset + len(hits); len(hits) > 0 && next < total && next <= channel.MaxSearchOffset {
+		result["nextOffset"] = next
+	}
+
+	return c.JSON(result)
+}
+
+// GetPins returns a channel's pinned messages.
+// GET /servers/:id/channels/:chId/pins
+func (h *ChannelMessageHandler) GetPins(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+	channelID := c.Params("chId")
+
+	messages, err := h.messageService.GetPins(c.Context(), serverID, channelID, userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	response := make([]dto.ChannelMessageResponse, len(messages))
+	for i, msg := range messages {
+		response[i] = channelMessageToDTO(msg)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// PinMessage pins a message.
+// PUT /servers/:id/channels/:chId/pins/:msgId
+func (h *ChannelMessageHandler) PinMessage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+	channelID := c.Params("chId")
+	messageID := c.Params("msgId")
+
+	_, pinned, err := h.messageService.PinMessage(c.Context(), serverID, channelID, messageID, userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	if pinned {
+		h.broadcastPinsUpdate(serverID, channelID, messageID, userID, true)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnpinMessage unpins a message.
+// DELETE /servers/:id/channels/:chId/pins/:msgId
+func (h *ChannelMessageHandler) UnpinMessage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+	channelID := c.Params("chId")
+	messageID := c.Params("msgId")
+
+	_, unpinned, err := h.messageService.UnpinMessage(c.Context(), serverID, channelID, messageID, userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	if unpinned {
+		h.broadcastPinsUpdate(serverID, channelID, messageID, userID, false)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *ChannelMessageHandler) broadcastPinsUpdate(serverID, channelID, messageID, actorID string, pinned bool) {
+	if h.websocketHandler == nil {
+		return
+	}
+
+	h.websocketHandler.BroadcastToChannel(channelID, ws.EventPinsUpdate, ws.PinsUpdateEventData{
+		ServerID:  serverID,
+		ChannelID: channelID,
+		MessageID: messageID,
+		Pinned:    pinned,
+		ActorID:   actorID,
+	})
+}
+
+// ListReactions returns who reacted to a message with an emoji.
+// GET /servers/:id/channels/:chId/messages/:msgId/reactions/:emoji
+func (h *ChannelMessageHandler) ListReactions(c *fiber.Ctx) error {
+	reactions, nextCursor, err := h.messageService.ListReactionUsers(c.Context(), h.reactionCommand(c), c.Query("cursor"), c.QueryInt("limit", 25))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	result := fiber.Map{"data": reactionUsersToDTO(reactions)}
+	if nextCursor != "" {
+		result["nextCursor"] = nextCursor
+	}
+
+	return c.JSON(result)
+}
+
+// AddReaction reacts to a message.
+// PUT /servers/:id/channels/:chId/messages/:msgId/reactions/:emoji/@me
+func (h *ChannelMessageHandler) AddReaction(c *fiber.Ctx) error {
+	cmd := h.reactionCommand(c)
+
+	rc, added, err := h.messageService.AddReaction(c.Context(), cmd)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	if added {
+		h.broadcastReaction(cmd, ws.EventReactionAdd, rc)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RemoveReaction removes the user's own reaction from a message.
+// DELETE /servers/:id/channels/:chId/messages/:msgId/reactions/:emoji/@me
+func (h *ChannelMessageHandler) RemoveReaction(c *fiber.Ctx) error {
+	return h.removeReaction(c, c.Locals("userID").(string))
+}
+
+// RemoveUserReaction removes another user's reaction from a message.
+// DELETE /servers/:id/channels/:chId/messages/:msgId/reactions/:emoji/:userId
+func (h *ChannelMessageHandler) RemoveUserReaction(c *fiber.Ctx) error {
+	return h.removeReaction(c, c.Params("userId"))
+}
+
+func (h *ChannelMessageHandler) removeReaction(c *fiber.Ctx, targetUserID string) error {
+	cmd := h.reactionCommand(c)
+
+	rc, removed, err := h.messageService.RemoveReaction(c.Context(), cmd, targetUserID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	if removed {
+		h.broadcastReaction(cmd, ws.EventReactionRemove, rc)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *ChannelMessageHandler) reactionCommand(c *fiber.Ctx) channelApp.ReactionCommand {
+	return channelApp.ReactionCommand{
+		ServerID:  c.Params("id"),
+		ChannelID: c.Params("chId"),
+		MessageID: c.Params("msgId"),
+		UserID:    c.Locals("userID").(string),
+		Emoji:     emojiParam(c),
+	}
+}
+
+func (h *ChannelMessageHandler) broadcastReaction(cmd channelApp.ReactionCommand, eventType ws.EventType, rc *reaction.Reaction) {
+	if h.websocketHandler == nil {
+		return
+	}
+
+	event := reactionEventData(rc)
+	event.ServerID = cmd.ServerID
+	event.ChannelID = cmd.ChannelID
+	h.websocketHandler.BroadcastToChannel(cmd.ChannelID, eventType, event)
+}
+
+// optionalTime parses an optional RFC 3339 time from a request body.
+func optionalTime(value *string) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 func (h *ChannelMessageHandler) handleError(c *fiber.Ctx, err error) error {
 	return middleware.HandleDomainError(c, err)
 }
 
 func channelMessageToDTO(msg *channel.ChannelMessage) dto.ChannelMessageResponse {
 	resp := dto.ChannelMessageResponse{
-		ID:        msg.ID,
-		ChannelID: msg.ChannelID,
-		ServerID:  msg.ServerID,
-		AuthorID:  msg.AuthorID,
-		Content:   msg.Content,
-		IsEdited:  msg.IsEdited,
-		IsPinned:  msg.IsPinned,
-		ReplyToID: msg.ReplyToID,
-		CreatedAt: msg.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+		ID:          msg.ID,
+		ChannelID:   msg.ChannelID,
+		ServerID:    msg.ServerID,
+		AuthorID:    msg.AuthorID,
+		Content:     msg.Content,
+		IsEdited:    msg.IsEdited,
+		IsPinned:    msg.IsPinned,
+		ReplyToID:   msg.ReplyToID,
+		ThreadID:    msg.ThreadID,
+		Attachments: attachmentsToDTO(msg.Attachments),
+		Reactions:   reactionsToDTO(msg.Reactions),
+		CreatedAt:   msg.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+	}
+
+	if msg.PinnedAt != nil {
+		pinnedAt := msg.PinnedAt.Format("2006-01-02T15:04:05.000Z")
+		resp.PinnedAt = &pinnedAt
+	}
+
+	if msg.CrosspostedAt != nil {
+		crosspostedAt := msg.CrosspostedAt.Format("2006-01-02T15:04:05.000Z")
+		resp.CrosspostedAt = &crosspostedAt
+	}
+
+	if source := msg.Source; source != nil {
+		resp.Source = &dto.CrosspostSourceResponse{
+			MessageID:   source.MessageID,
+			ChannelID:   source.ChannelID,
+			ServerID:    source.ServerID,
+			ChannelName: source.ChannelName,
+			ServerName:  source.ServerName,
+		}
+	}
+
+	if !msg.Mentions.IsEmpty() {
+		resp.Mentions = &dto.MessageMentionsResponse{
+			Users:    nonNil(msg.Mentions.UserIDs),
+			Roles:    nonNil(msg.Mentions.RoleIDs),
+			Everyone: msg.Mentions.Everyone,
+			Here:     msg.Mentions.Here,
+		}
 	}
 
 	if msg.Author != nil {
@@ -203,3 +523,11 @@ func channelMessageToDTO(msg *channel.ChannelMessage) dto.ChannelMessageResponse
 
 	return resp
 }
+
+// nonNil returns ids, or an empty slice so that it encodes as [].
+func nonNil(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
@@ -11,6 +11,9 @@ import (
 	"pink/internal/adapters/http/dto"
 	dmApp "pink/internal/application/dm"
 	"pink/internal/domain/dm"
+	"pink/internal/domain/history"
+	"pink/internal/domain/media"
+	"pink/internal/domain/reaction"
 	"pink/internal/domain/user"
 	"pink/internal/domain/ws"
 	wsInfra "pink/internal/infrastructure/ws"
@@ -100,10 +103,13 @@ func (h *DMHandler) GetConversation(c *fiber.Ctx) error {
 func (h *DMHandler) GetMessages(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
 	convID := c.Params("id")
-	cursor := c.Query("cursor")
-	limit := c.QueryInt("limit", 20)
 
-	messages, nextCursor, err := h.dmService.GetMessages(c.Context(), convID, userID, cursor, limit)
+	cursor, err := historyCursor(c, history.DefaultLimit)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	messages, page, err := h.dmService.GetMessages(c.Context(), convID, userID, cursor)
 	if err != nil {
 		return h.handleError(c, err)
 	}
@@ -113,12 +119,7 @@ func (h *DMHandler) GetMessages(c *fiber.Ctx) error {
 		response[i] = messageToDTO(msg)
 	}
 
-	result := fiber.Map{"data": response}
-	if nextCursor != "" {
-		result["nextCursor"] = nextCursor
-	}
-
-	return c.JSON(result)
+	return c.JSON(historyResult(response, page))
 }
 
 // SendMessage sends a message in a conversation.
@@ -139,6 +140,7 @@ func (h *DMHandler) SendMessage(c *fiber.Ctx) error {
 		ConversationID: convID,
 		SenderID:       userID,
 		Content:        req.Content,
+		AttachmentIDs:  req.AttachmentIDs,
 	})
 
 	if err != nil {
@@ -194,28 +196,42 @@ func (h *DMHandler) EditMessage(c *fiber.Ctx) error {
 	})
 }
 
+// GetRevisions returns a message's edit history.
+// GET /dm/messages/:id/revisions
+func (h *DMHandler) GetRevisions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	revisions, err := h.dmService.GetRevisions(c.Context(), c.Params("id"), userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": revisionsToDTO(revisions),
+	})
+}
+
 // DeleteMessage deletes a message.
 // DELETE /dm/messages/:id
 func (h *DMHandler) DeleteMessage(c *fiber.Ctx) error {
 	userID := c.Locals("userID").(string)
 	messageID := c.Params("id")
-	convID := c.Query("conversationId") // For broadcast
 
-	if err := h.dmService.DeleteMessage(c.Context(), messageID, userID); err != nil {
+	msg, err := h.dmService.DeleteMessage(c.Context(), messageID, userID)
+	if err != nil {
 		return h.handleError(c, err)
 	}
 
-	// Broadcast deleted message to conversation subscribers with panic recovery
-	if convID != "" {
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					slog.Error("panic in broadcastDMMessage", slog.Any("panic", r), slog.String("convId", convID))
-				}
-			}()
-			h.broadcastDMMessage(convID, ws.EventDMMessageDeleted, &dm.Message{ID: messageID, ConversationID: convID})
+	// Broadcast deleted message to its conversation's subscribers with panic recovery
+	convID := msg.ConversationID
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("panic in broadcastDMMessage", slog.Any("panic", r), slog.String("convId", convID))
+			}
 		}()
-	}
+		h.broadcastDMMessage(convID, ws.EventDMMessageDeleted, &dm.Message{ID: messageID, ConversationID: convID})
+	}()
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
@@ -243,6 +259,113 @@ func (h *DMHandler) MarkAsRead(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// GetPins returns a conversation's pinned messages.
+// GET /dm/conversations/:id/pins
+func (h *DMHandler) GetPins(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	convID := c.Params("id")
+
+	messages, err := h.dmService.GetPins(c.Context(), convID, userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	response := make([]dto.DMMessageResponse, len(messages))
+	for i, msg := range messages {
+		response[i] = messageToDTO(msg)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// PinMessage pins a message.
+// PUT /dm/conversations/:id/pins/:msgId
+func (h *DMHandler) PinMessage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	msg, pinned, err := h.dmService.PinMessage(c.Context(), c.Params("id"), c.Params("msgId"), userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	if pinned {
+		h.broadcastDMPinsUpdate(msg, userID)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnpinMessage unpins a message.
+// DELETE /dm/conversations/:id/pins/:msgId
+func (h *DMHandler) UnpinMessage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	msg, unpinned, err := h.dmService.UnpinMessage(c.Context(), c.Params("id"), c.Params("msgId"), userID)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	if unpinned {
+		h.broadcastDMPinsUpdate(msg, userID)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListReactions returns who reacted to a message with an emoji.
+// GET /dm/messages/:id/reactions/:emoji
+func (h *DMHandler) ListReactions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	reactions, nextCursor, err := h.dmService.ListReactionUsers(c.Context(), c.Params("id"), userID, emojiParam(c), c.Query("cursor"), c.QueryInt("limit", 25))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	result := fiber.Map{"data": reactionUsersToDTO(reactions)}
+	if nextCursor != "" {
+		result["nextCursor"] = nextCursor
+	}
+
+	return c.JSON(result)
+}
+
+// AddReaction reacts to a message.
+// PUT /dm/messages/:id/reactions/:emoji/@me
+func (h *DMHandler) AddReaction(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	msg, rc, added, err := h.dmService.AddReaction(c.Context(), c.Params("id"), userID, emojiParam(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	if added {
+		h.broadcastDMReaction(msg.ConversationID, ws.EventReactionAdd, rc)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RemoveReaction removes the user's own reaction from a message.
+// DELETE /dm/messages/:id/reactions/:emoji/@me
+func (h *DMHandler) RemoveReaction(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	msg, rc, removed, err := h.dmService.RemoveReaction(c.Context(), c.Params("id"), userID, emojiParam(c))
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	if removed {
+		h.broadcastDMReaction(msg.ConversationID, ws.EventReactionRemove, rc)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
 func (h *DMHandler) handleError(c *fiber.Ctx, err error) error {
 	switch {
 	case errors.Is(err, dm.ErrConversationNotFound):
@@ -269,12 +392,66 @@ func (h *DMHandler) handleError(c *fiber.Ctx, err error) error {
 			"No permission to perform this action",
 		))
 
+	case errors.Is(err, history.ErrInvalidCursor):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"INVALID_CURSOR",
+			"Only one of before, after and around can be given",
+		))
+
 	case errors.Is(err, dm.ErrCannotMessageSelf):
 		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
 			"CANNOT_MESSAGE_SELF",
 			"Cannot start a conversation with yourself",
 		))
 
+	case errors.Is(err, reaction.ErrInvalidEmoji):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"INVALID_EMOJI",
+			"Invalid emoji",
+		))
+
+	case errors.Is(err, reaction.ErrTooManyReactions):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"TOO_MANY_REACTIONS",
+			"Message has reached the maximum number of different reactions",
+		))
+
+	case errors.Is(err, reaction.ErrInvalidCursor):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"INVALID_CURSOR",
+			"Invalid cursor",
+		))
+
+	case errors.Is(err, dm.ErrTooManyPins):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"TOO_MANY_PINS",
+			"Conversation has reached the maximum number of pinned messages",
+		))
+
+	case errors.Is(err, media.ErrNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Media not found",
+		))
+
+	case errors.Is(err, media.ErrNoPermission):
+		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
+			"FORBIDDEN",
+			"Only your own uploads can be attached",
+		))
+
+	case errors.Is(err, media.ErrAlreadyAttached):
+		return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse(
+			"ALREADY_ATTACHED",
+			"Media is already attached to a message",
+		))
+
+	case errors.Is(err, media.ErrTooManyAttachments):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"TOO_MANY_ATTACHMENTS",
+			"Message has too many attachments",
+		))
+
 	case errors.Is(err, dm.ErrInvalidContent):
 		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
 			"INVALID_CONTENT",
@@ -326,9 +503,17 @@ func messageToDTO(msg *dm.Message) dto.DMMessageResponse {
 		SenderID:       msg.SenderID,
 		Content:        msg.Content,
 		IsEdited:       msg.IsEdited,
+		IsPinned:       msg.IsPinned,
+		Attachments:    attachmentsToDTO(msg.Attachments),
+		Reactions:      reactionsToDTO(msg.Reactions),
 		CreatedAt:      msg.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
 	}
 
+	if msg.PinnedAt != nil {
+		pinnedAt := msg.PinnedAt.Format("2006-01-02T15:04:05.000Z")
+		resp.PinnedAt = &pinnedAt
+	}
+
 	if msg.Sender != nil {
 		resp.Sender = &dto.ConversationUserResponse{
 			ID:             msg.Sender.ID,
@@ -398,6 +583,45 @@ func (h *DMHandler) broadcastDMRead(convID, userID string) {
 	h.hub.BroadcastToSubscription(ws.SubConversation, convID, data)
 }
 
+// broadcastDMReaction broadcasts a reaction change to conversation subscribers.
+func (h *DMHandler) broadcastDMReaction(convID string, eventType ws.EventType, rc *reaction.Reaction) {
+	if h.hub == nil {
+		return
+	}
+
+	event := reactionEventData(rc)
+	event.ConversationID = convID
+
+	wsMsg, err := ws.NewMessage(eventType, event)
+	if err != nil {
+		return
+	}
+
+	data, _ := json.Marshal(wsMsg)
+	h.hub.BroadcastToSubscription(ws.SubConversation, convID, data)
+}
+
+// broadcastDMPinsUpdate broadcasts a message being pinned or unpinned to
+// conversation subscribers.
+func (h *DMHandler) broadcastDMPinsUpdate(msg *dm.Message, actorID string) {
+	if h.hub == nil {
+		return
+	}
+
+	wsMsg, err := ws.NewMessage(ws.EventPinsUpdate, ws.PinsUpdateEventData{
+		ConversationID: msg.ConversationID,
+		MessageID:      msg.ID,
+		Pinned:         msg.IsPinned,
+		ActorID:        actorID,
+	})
+	if err != nil {
+		return
+	}
+
+	data, _ := json.Marshal(wsMsg)
+	h.hub.BroadcastToSubscription(ws.SubConversation, msg.ConversationID, data)
+}
+
 // messageToMap converts a message to a map for WS broadcast.
 func messageToMap(msg *dm.Message) map[string]interface{} {
 	m := map[string]interface{}{
@@ -418,5 +642,9 @@ func messageToMap(msg *dm.Message) map[string]interface{} {
 		}
 	}
 
+	if len(msg.Attachments) > 0 {
+		m["attachments"] = attachmentsToDTO(msg.Attachments)
+	}
+
 	return m
 }
@@ -4,19 +4,23 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
+
+	wsInfra "pink/internal/infrastructure/ws"
 )
 
 // HealthHandler handles health check requests.
 type HealthHandler struct {
 	db    *pgxpool.Pool
 	redis *redis.Client
+	hub   *wsInfra.Hub
 }
 
 // NewHealthHandler creates a new HealthHandler.
-func NewHealthHandler(db *pgxpool.Pool, redis *redis.Client) *HealthHandler {
+func NewHealthHandler(db *pgxpool.Pool, redis *redis.Client, hub *wsInfra.Hub) *HealthHandler {
 	return &HealthHandler{
 		db:    db,
 		redis: redis,
+		hub:   hub,
 	}
 }
 
@@ -75,3 +79,12 @@ func (h *HealthHandler) Ready(c *fiber.Ctx) error {
 		"checks": checks,
 	})
 }
+
+// WebSocketStats returns aggregate queue metrics for this node. The endpoint
+// is unauthenticated, so nothing identifying a user or session is included.
+// GET /ready/ws
+func (h *HealthHandler) WebSocketStats(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"data": h.hub.Stats(),
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"pink/internal/domain/history"
+)
+
+// historyCursor reads the cursor of a message history request: at most one
+// of the before, after and around message IDs, and the page size. cursor is
+// accepted as before, which nextCursor is passed back as.
+func historyCursor(c *fiber.Ctx, defaultLimit int) (history.Cursor, error) {
+	before := c.Query("before", c.Query("cursor"))
+	return history.NewCursor(before, c.Query("after"), c.Query("around"), c.QueryInt("limit", defaultLimit), defaultLimit)
+}
+
+// historyResult wraps a page of messages with the cursors that continue it:
+// nextCursor for older messages and prevCursor for newer ones.
+func historyResult(data interface{}, page history.Page) fiber.Map {
+	result := fiber.Map{"data": data}
+	if page.Before != "" {
+		result["nextCursor"] = page.Before
+	}
+	if page.After != "" {
+		result["prevCursor"] = page.After
+	}
+	return result
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pink/internal/adapters/http/dto"
+	"pink/internal/adapters/http/middleware"
+	serverApp "pink/internal/application/server"
+	"pink/internal/domain/server"
+)
+
+// InviteHandler handles server invite requests.
+type InviteHandler struct {
+	inviteService *serverApp.InviteService
+}
+
+// NewInviteHandler creates a new InviteHandler.
+func NewInviteHandler(inviteService *serverApp.InviteService) *InviteHandler {
+	return &InviteHandler{inviteService: inviteService}
+}
+
+// Create creates an invite for a server.
+// POST /servers/:id/invites
+func (h *InviteHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+
+	var req dto.CreateInviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	invite, err := h.inviteService.Create(c.Context(), serverApp.CreateInviteCommand{
+		ServerID:  serverID,
+		UserID:    userID,
+		ChannelID: req.ChannelID,
+		MaxUses:   req.MaxUses,
+		MaxAge:    time.Duration(req.MaxAgeSeconds) * time.Second,
+		Temporary: req.Temporary,
+	})
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": inviteToDTO(invite),
+	})
+}
+
+// List lists a server's invites.
+// GET /servers/:id/invites
+func (h *InviteHandler) List(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+
+	invites, err := h.inviteService.List(c.Context(), serverID, userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	resp := make([]dto.InviteResponse, len(invites))
+	for i, inv := range invites {
+		resp[i] = inviteToDTO(inv)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": resp,
+	})
+}
+
+// Revoke deletes an invite.
+// DELETE /servers/:id/invites/:code
+func (h *InviteHandler) Revoke(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+	code := c.Params("code")
+
+	if err := h.inviteService.Revoke(c.Context(), serverID, code, userID); err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Preview returns the public view of the server an invite leads to. It does
+// not require authentication.
+// GET /invites/:code
+func (h *InviteHandler) Preview(c *fiber.Ctx) error {
+	preview, err := h.inviteService.Preview(c.Context(), c.Params("code"))
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	resp := dto.InvitePreviewResponse{
+		Code: preview.Invite.Code,
+		Server: dto.InviteServerResponse{
+			ID:           preview.Server.ID,
+			Name:         preview.Server.Name,
+			Description:  preview.Server.Description,
+			IconGradient: preview.Server.IconGradient,
+			MemberCount:  preview.Server.MemberCount,
+		},
+		ExpiresAt: formatOptionalTime(preview.Invite.ExpiresAt),
+	}
+	if preview.Channel != nil {
+		resp.Channel = &dto.InviteChannelResponse{
+			ID:   preview.Channel.ID,
+			Name: preview.Channel.Name,
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": resp,
+	})
+}
+
+// Redeem joins the server an invite leads to.
+// POST /invites/:code
+func (h *InviteHandler) Redeem(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	result, err := h.inviteService.Redeem(c.Context(), c.Params("code"), userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": dto.RedeemInviteResponse{
+			Server:        serverToDTO(result.Server),
+			ChannelID:     result.Invite.ChannelID,
+			AlreadyMember: result.AlreadyMember,
+		},
+	})
+}
+
+func inviteToDTO(inv *server.Invite) dto.InviteResponse {
+	return dto.InviteResponse{
+		Code:      inv.Code,
+		ServerID:  inv.ServerID,
+		ChannelID: inv.ChannelID,
+		CreatorID: inv.CreatorID,
+		MaxUses:   inv.MaxUses,
+		Uses:      inv.Uses,
+		ExpiresAt: formatOptionalTime(inv.ExpiresAt),
+		Temporary: inv.Temporary,
+		CreatedAt: inv.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+	}
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02T15:04:05.000Z")
+	return &s
+}
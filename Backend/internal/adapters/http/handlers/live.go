@@ -11,8 +11,10 @@ import (
 	"github.com/google/uuid"
 
 	"pink/internal/adapters/http/dto"
+	"pink/internal/domain/history"
 	"pink/internal/domain/live"
 	"pink/internal/domain/server"
+	"pink/internal/domain/slowmode"
 )
 
 // LiveHandler handles live streaming requests.
@@ -86,10 +88,13 @@ func (h *LiveHandler) GetStream(c *fiber.Ctx) error {
 // GET /live/streams/:id/messages
 func (h *LiveHandler) GetChatHistory(c *fiber.Ctx) error {
 	id := c.Params("id")
-	limit := c.QueryInt("limit", 50)
-	before := c.Query("before")
 
-	messages, err := h.streamMsgRepo.FindByStreamID(c.Context(), id, limit, before)
+	cursor, err := historyCursor(c, history.MaxLimit)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	messages, page, err := h.streamMsgRepo.FindByStreamID(c.Context(), id, cursor)
 	if err != nil {
 		slog.Error("get chat history error", slog.Any("error", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse(
@@ -103,7 +108,7 @@ func (h *LiveHandler) GetChatHistory(c *fiber.Ctx) error {
 		response[i] = chatMessageToDTO(msg)
 	}
 
-	return c.JSON(fiber.Map{"data": response})
+	return c.JSON(historyResult(response, page))
 }
 
 // GetStreamRecordings returns recordings for a stream.
@@ -172,6 +177,15 @@ func (h *LiveHandler) UpdateMyStream(c *fiber.Ctx) error {
 	if req.IsNSFW != nil {
 		stream.IsNSFW = *req.IsNSFW
 	}
+	if req.SlowmodeSeconds != nil {
+		if err := slowmode.ValidateSeconds(*req.SlowmodeSeconds); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+				"INVALID_SLOWMODE",
+				"Slowmode must be between 0 and 21600 seconds",
+			))
+		}
+		stream.SlowmodeSeconds = *req.SlowmodeSeconds
+	}
 
 	if err := h.streamRepo.Update(c.Context(), stream); err != nil {
 		slog.Error("update my stream error", slog.Any("error", err))
@@ -385,6 +399,15 @@ func (h *LiveHandler) UpdateStream(c *fiber.Ctx) error {
 	if req.IsNSFW != nil {
 		stream.IsNSFW = *req.IsNSFW
 	}
+	if req.SlowmodeSeconds != nil {
+		if err := slowmode.ValidateSeconds(*req.SlowmodeSeconds); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+				"INVALID_SLOWMODE",
+				"Slowmode must be between 0 and 21600 seconds",
+			))
+		}
+		stream.SlowmodeSeconds = *req.SlowmodeSeconds
+	}
 
 	if err := h.streamRepo.Update(c.Context(), stream); err != nil {
 		slog.Error("update stream error", slog.Any("error", err))
@@ -530,6 +553,11 @@ func (h *LiveHandler) handleError(c *fiber.Ctx, err error) error {
 			"NOT_FOUND",
 			"Category not found",
 		))
+	case errors.Is(err, history.ErrInvalidCursor):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"INVALID_CURSOR",
+			"Only one of before, after and around can be given",
+		))
 	default:
 		slog.Error("live handler error", slog.Any("error", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse(
@@ -556,6 +584,8 @@ func streamToDTO(stream *live.Stream) dto.StreamResponse {
 		StreamKey:   stream.StreamKey, // Only sent to owner ideally, but here simplifies for now
 		MaxQuality:  stream.MaxQuality,
 		CreatedAt:   stream.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+
+		SlowmodeSeconds: stream.SlowmodeSeconds,
 	}
 
 	if stream.StartedAt != nil {
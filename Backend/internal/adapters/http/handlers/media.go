@@ -3,6 +3,10 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
 	"io"
 	"log/slog"
 	"os"
@@ -126,6 +130,9 @@ func (h *MediaHandler) Upload(c *fiber.Ctx) error {
 		URL:          url,
 		CreatedAt:    time.Now(),
 	}
+	if mediaType == media.TypeImage {
+		m.Width, m.Height = imageDimensions(filePath)
+	}
 
 	if err := h.mediaRepo.Create(c.Context(), m); err != nil {
 		// Delete file if database insert fails
@@ -240,11 +247,51 @@ func mediaToDTO(m *media.Media) dto.MediaResponse {
 		MimeType:     m.MimeType,
 		Type:         string(m.Type),
 		Size:         m.Size,
+		Width:        m.Width,
+		Height:       m.Height,
 		URL:          m.URL,
 		CreatedAt:    m.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
 	}
 }
 
+// imageDimensions reads an image's size from its header. Formats without a
+// registered decoder, such as WebP, are left without dimensions.
+func imageDimensions(path string) (*int, *int) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, nil
+	}
+	return &cfg.Width, &cfg.Height
+}
+
+// attachmentsToDTO converts a message's attachments for responses.
+func attachmentsToDTO(items []*media.Media) []dto.AttachmentResponse {
+	if len(items) == 0 {
+		return nil
+	}
+
+	resp := make([]dto.AttachmentResponse, len(items))
+	for i, m := range items {
+		resp[i] = dto.AttachmentResponse{
+			ID:          m.ID,
+			Filename:    m.OriginalName,
+			ContentType: m.MimeType,
+			Type:        string(m.Type),
+			Size:        m.Size,
+			Width:       m.Width,
+			Height:      m.Height,
+			URL:         m.URL,
+		}
+	}
+	return resp
+}
+
 // ServeUploads creates a handler to serve uploaded files.
 func ServeUploads(uploadDir string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
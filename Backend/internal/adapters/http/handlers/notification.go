@@ -13,12 +13,14 @@ import (
 // NotificationHandler handles notification requests.
 type NotificationHandler struct {
 	notifRepo notification.Repository
+	notifier  notification.Dispatcher
 }
 
 // NewNotificationHandler creates a new NotificationHandler.
-func NewNotificationHandler(notifRepo notification.Repository) *NotificationHandler {
+func NewNotificationHandler(notifRepo notification.Repository, notifier notification.Dispatcher) *NotificationHandler {
 	return &NotificationHandler{
 		notifRepo: notifRepo,
+		notifier:  notifier,
 	}
 }
 
@@ -99,6 +101,8 @@ func (h *NotificationHandler) MarkAsRead(c *fiber.Ctx) error {
 		))
 	}
 
+	h.syncUnreadCount(c, userID)
+
 	return c.JSON(fiber.Map{"message": "Marked as read"})
 }
 
@@ -115,6 +119,8 @@ func (h *NotificationHandler) MarkAllAsRead(c *fiber.Ctx) error {
 		))
 	}
 
+	h.syncUnreadCount(c, userID)
+
 	return c.JSON(fiber.Map{"message": "All notifications marked as read"})
 }
 
@@ -145,9 +151,23 @@ func (h *NotificationHandler) DeleteNotification(c *fiber.Ctx) error {
 		))
 	}
 
+	if !notif.IsRead {
+		h.syncUnreadCount(c, userID)
+	}
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// syncUnreadCount updates the bell on the user's other connections.
+func (h *NotificationHandler) syncUnreadCount(c *fiber.Ctx, userID string) {
+	if h.notifier == nil {
+		return
+	}
+	if err := h.notifier.SyncUnreadCount(c.Context(), userID); err != nil {
+		slog.Warn("sync unread count error", slog.Any("error", err))
+	}
+}
+
 func (h *NotificationHandler) handleError(c *fiber.Ctx, err error) error {
 	switch {
 	case errors.Is(err, notification.ErrNotFound):
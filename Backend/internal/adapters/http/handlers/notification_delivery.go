@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"pink/internal/adapters/http/dto"
+	"pink/internal/domain/notification"
+	"pink/internal/domain/ws"
+	wsInfra "pink/internal/infrastructure/ws"
+)
+
+// WebSocketNotificationDeliverer implements notification.Deliverer by pushing
+// notifications to the recipient's "user:<id>" channel.
+type WebSocketNotificationDeliverer struct {
+	hub *wsInfra.Hub
+}
+
+// NewWebSocketNotificationDeliverer creates a new WebSocketNotificationDeliverer.
+func NewWebSocketNotificationDeliverer(hub *wsInfra.Hub) *WebSocketNotificationDeliverer {
+	return &WebSocketNotificationDeliverer{hub: hub}
+}
+
+// Deliver sends a new notification with the recipient's unread count.
+func (d *WebSocketNotificationDeliverer) Deliver(ctx context.Context, notif *notification.Notification, unreadCount int) error {
+	resp := notificationToDTO(notif)
+	return d.send(ws.EventNotification, notif.UserID, dto.NotificationEventResponse{
+		Notification: &resp,
+		UnreadCount:  unreadCount,
+	})
+}
+
+// DeliverUnreadCount sends the user's updated unread count.
+func (d *WebSocketNotificationDeliverer) DeliverUnreadCount(ctx context.Context, userID string, unreadCount int) error {
+	return d.send(ws.EventNotificationCount, userID, dto.NotificationEventResponse{
+		UnreadCount: unreadCount,
+	})
+}
+
+func (d *WebSocketNotificationDeliverer) send(eventType ws.EventType, userID string, payload dto.NotificationEventResponse) error {
+	msg, err := ws.NewMessage(eventType, payload)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	d.hub.BroadcastToUser(userID, data)
+	return nil
+}
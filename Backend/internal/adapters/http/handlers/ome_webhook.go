@@ -19,30 +19,30 @@ import (
 
 // OMEWebhookHandler handles OvenMediaEngine admission webhooks.
 type OMEWebhookHandler struct {
-	streamRepo       live.StreamRepository
-	followRepo       user.FollowRepository
-	notificationRepo notification.Repository
-	recordingRepo    live.RecordingRepository
-	secretKey        string
-	logger           *slog.Logger
+	streamRepo    live.StreamRepository
+	followRepo    user.FollowRepository
+	notifier      notification.Dispatcher
+	recordingRepo live.RecordingRepository
+	secretKey     string
+	logger        *slog.Logger
 }
 
 // NewOMEWebhookHandler creates a new OMEWebhookHandler.
 func NewOMEWebhookHandler(
 	streamRepo live.StreamRepository,
 	followRepo user.FollowRepository,
-	notificationRepo notification.Repository,
+	notifier notification.Dispatcher,
 	recordingRepo live.RecordingRepository,
 	secretKey string,
 	logger *slog.Logger,
 ) *OMEWebhookHandler {
 	return &OMEWebhookHandler{
-		streamRepo:       streamRepo,
-		followRepo:       followRepo,
-		notificationRepo: notificationRepo,
-		recordingRepo:    recordingRepo,
-		secretKey:        secretKey,
-		logger:           logger,
+		streamRepo:    streamRepo,
+		followRepo:    followRepo,
+		notifier:      notifier,
+		recordingRepo: recordingRepo,
+		secretKey:     secretKey,
+		logger:        logger,
 	}
 }
 
@@ -271,18 +271,15 @@ func (h *OMEWebhookHandler) sendGoLiveNotifications(stream *live.Stream) {
 		for _, follow := range followers {
 			// Create notification
 			notif := &notification.Notification{
-				ID:         uuid.New().String(),
 				UserID:     follow.FollowerID,
 				Type:       notification.TypeStreamLive,
 				ActorID:    &stream.UserID,
 				TargetType: stringPtr("stream"),
 				TargetID:   &stream.ID,
 				Message:    stream.Title,
-				IsRead:     false,
-				CreatedAt:  time.Now(),
 			}
 
-			if err := h.notificationRepo.Create(ctx, notif); err != nil {
+			if err := h.notifier.Dispatch(ctx, notif); err != nil {
 				h.logger.Error("Failed to create notification", "error", err, "target_user_id", follow.FollowerID)
 			}
 		}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pink/internal/adapters/http/dto"
+	"pink/internal/domain/reaction"
+	"pink/internal/domain/ws"
+)
+
+// emojiParam returns the :emoji route parameter. Unicode emoji arrive
+// percent-encoded since Fiber does not unescape paths.
+func emojiParam(c *fiber.Ctx) string {
+	raw := c.Params("emoji")
+	if emoji, err := url.PathUnescape(raw); err == nil {
+		return emoji
+	}
+	return raw
+}
+
+func reactionsToDTO(summaries []reaction.Summary) []dto.ReactionResponse {
+	if len(summaries) == 0 {
+		return nil
+	}
+
+	resp := make([]dto.ReactionResponse, len(summaries))
+	for i, s := range summaries {
+		resp[i] = dto.ReactionResponse{
+			Emoji: dto.EmojiResponse{Name: s.Emoji.Name, ID: s.Emoji.ID},
+			Count: s.Count,
+			Me:    s.Me,
+		}
+	}
+	return resp
+}
+
+func reactionUsersToDTO(reactions []*reaction.Reaction) []dto.ReactionUserResponse {
+	resp := make([]dto.ReactionUserResponse, 0, len(reactions))
+	for _, rc := range reactions {
+		user := dto.ReactionUserResponse{
+			ID:        rc.UserID,
+			ReactedAt: rc.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+		}
+		if rc.User != nil {
+			user.Handle = rc.User.Handle
+			user.DisplayName = rc.User.DisplayName
+			user.AvatarGradient = rc.User.AvatarGradient
+		}
+		resp = append(resp, user)
+	}
+	return resp
+}
+
+// reactionEventData builds the reaction_add / reaction_remove payload. The
+// caller fills in where the message lives.
+func reactionEventData(rc *reaction.Reaction) ws.ReactionEventData {
+	return ws.ReactionEventData{
+		MessageID: rc.MessageID,
+		UserID:    rc.UserID,
+		Emoji:     ws.ReactionEmojiData{Name: rc.Emoji.Name, ID: rc.Emoji.ID},
+	}
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"pink/internal/adapters/http/dto"
+	"pink/internal/domain/revision"
+)
+
+func revisionsToDTO(revisions []*revision.Revision) []dto.MessageRevisionResponse {
+	resp := make([]dto.MessageRevisionResponse, 0, len(revisions))
+	for _, rev := range revisions {
+		resp = append(resp, dto.MessageRevisionResponse{
+			ID:        rev.ID,
+			MessageID: rev.MessageID,
+			Content:   rev.Content,
+			EditedAt:  rev.EditedAt.Format("2006-01-02T15:04:05.000Z"),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pink/internal/adapters/http/dto"
+	"pink/internal/adapters/http/middleware"
+	channelApp "pink/internal/application/channel"
+	"pink/internal/domain/channel"
+)
+
+// ScheduledMessageHandler handles scheduled message requests.
+type ScheduledMessageHandler struct {
+	scheduledService *channelApp.ScheduledMessageService
+}
+
+// NewScheduledMessageHandler creates a new ScheduledMessageHandler.
+func NewScheduledMessageHandler(scheduledService *channelApp.ScheduledMessageService) *ScheduledMessageHandler {
+	return &ScheduledMessageHandler{scheduledService: scheduledService}
+}
+
+// Create schedules a message in a text or announcement channel.
+// POST /servers/:id/channels/:chId/scheduled-messages
+func (h *ScheduledMessageHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	var req dto.ScheduleMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	publishAt, err := time.Parse(time.RFC3339, req.PublishAt)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"publishAt must be an RFC 3339 time",
+		))
+	}
+
+	msg, err := h.scheduledService.Schedule(c.Context(), channelApp.ScheduleMessageCommand{
+		ServerID:      c.Params("id"),
+		ChannelID:     c.Params("chId"),
+		UserID:        userID,
+		Content:       req.Content,
+		AttachmentIDs: req.AttachmentIDs,
+		PublishAt:     publishAt,
+	})
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": scheduledMessageToDTO(msg),
+	})
+}
+
+// List lists the user's scheduled messages in a server.
+// GET /servers/:id/scheduled-messages
+func (h *ScheduledMessageHandler) List(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	messages, err := h.scheduledService.List(c.Context(), c.Params("id"), userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	response := make([]dto.ScheduledMessageResponse, len(messages))
+	for i, msg := range messages {
+		response[i] = scheduledMessageToDTO(msg)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Update edits or reschedules a scheduled message.
+// PATCH /servers/:id/scheduled-messages/:scheduledId
+func (h *ScheduledMessageHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	var req dto.UpdateScheduledMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	publishAt, err := optionalTime(req.PublishAt)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"publishAt must be an RFC 3339 time",
+		))
+	}
+
+	msg, err := h.scheduledService.Edit(c.Context(), channelApp.EditScheduledCommand{
+		ServerID:      c.Params("id"),
+		ScheduledID:   c.Params("scheduledId"),
+		UserID:        userID,
+		Content:       req.Content,
+		AttachmentIDs: req.AttachmentIDs,
+		PublishAt:     publishAt,
+	})
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": scheduledMessageToDTO(msg),
+	})
+}
+
+// Delete cancels a scheduled message.
+// DELETE /servers/:id/scheduled-messages/:scheduledId
+func (h *ScheduledMessageHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	if err := h.scheduledService.Cancel(c.Context(), c.Params("id"), c.Params("scheduledId"), userID); err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func scheduledMessageToDTO(msg *channel.ScheduledMessage) dto.ScheduledMessageResponse {
+	attachmentIDs := msg.AttachmentIDs
+	if attachmentIDs == nil {
+		attachmentIDs = []string{}
+	}
+	return dto.ScheduledMessageResponse{
+		ID:            msg.ID,
+		ServerID:      msg.ServerID,
+		ChannelID:     msg.ChannelID,
+		AuthorID:      msg.AuthorID,
+		Content:       msg.Content,
+		AttachmentIDs: attachmentIDs,
+		PublishAt:     msg.PublishAt.Format("2006-01-02T15:04:05.000Z"),
+		Status:        string(msg.Status),
+		Failure:       msg.Failure,
+		CreatedAt:     msg.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+		UpdatedAt:     msg.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
+	}
+}
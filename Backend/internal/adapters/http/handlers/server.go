@@ -8,7 +8,9 @@ import (
 	"github.com/gofiber/fiber/v2"
 
 	"pink/internal/adapters/http/dto"
+	"pink/internal/adapters/http/middleware"
 	serverApp "pink/internal/application/server"
+	"pink/internal/domain/revision"
 	"pink/internal/domain/server"
 	"pink/internal/domain/user"
 )
@@ -17,11 +19,12 @@ import (
 type ServerHandler struct {
 	serverService *serverApp.Service
 	userRepo      user.Repository
+	wsHandler     *WebSocketHandler
 }
 
 // NewServerHandler creates a new ServerHandler.
-func NewServerHandler(serverService *serverApp.Service, userRepo user.Repository) *ServerHandler {
-	return &ServerHandler{serverService: serverService, userRepo: userRepo}
+func NewServerHandler(serverService *serverApp.Service, userRepo user.Repository, wsHandler *WebSocketHandler) *ServerHandler {
+	return &ServerHandler{serverService: serverService, userRepo: userRepo, wsHandler: wsHandler}
 }
 
 // List returns all servers the user is a member of.
@@ -153,6 +156,8 @@ func (h *ServerHandler) Update(c *fiber.Ctx) error {
 		IsPublic:    req.IsPublic,
 		Tag:         req.Tag,
 		UserID:      userID,
+
+		RevisionRetentionDays: req.RevisionRetentionDays,
 	})
 
 	if err != nil {
@@ -265,6 +270,8 @@ func (h *ServerHandler) Leave(c *fiber.Ctx) error {
 		return h.handleError(c, err)
 	}
 
+	h.wsHandler.RevokeUserSubscriptions(userID)
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -304,6 +311,9 @@ func (h *ServerHandler) ListMembers(c *fiber.Ctx) error {
 			Role:     displayRole,
 			JoinedAt: m.JoinedAt.Format("2006-01-02T15:04:05.000Z"),
 			RoleIDs:  roleIDs,
+
+			InviteCode:  m.InviteCode,
+			IsTemporary: m.IsTemporary,
 		}
 
 		// Fetch user data
@@ -337,6 +347,8 @@ func (h *ServerHandler) RemoveMember(c *fiber.Ctx) error {
 		return h.handleError(c, err)
 	}
 
+	h.wsHandler.RevokeUserSubscriptions(targetUserID)
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -365,6 +377,8 @@ func (h *ServerHandler) Ban(c *fiber.Ctx) error {
 		return h.handleError(c, err)
 	}
 
+	h.wsHandler.RevokeUserSubscriptions(req.UserID)
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -395,13 +409,7 @@ func (h *ServerHandler) GetBans(c *fiber.Ctx) error {
 
 	response := make([]dto.BanResponse, len(bans))
 	for i, b := range bans {
-		response[i] = dto.BanResponse{
-			ID:        b.ID,
-			UserID:    b.UserID,
-			BannedBy:  b.BannedBy,
-			Reason:    b.Reason,
-			CreatedAt: b.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
-		}
+		response[i] = banToDTO(b)
 	}
 
 	return c.JSON(fiber.Map{
@@ -409,6 +417,117 @@ func (h *ServerHandler) GetBans(c *fiber.Ctx) error {
 	})
 }
 
+// ListAuditLogs returns a server's audit log, newest first.
+// GET /servers/:id/audit-logs?actorId=&targetId=&actionType=&after=&before=&cursor=&limit=
+func (h *ServerHandler) ListAuditLogs(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+
+	filter := server.AuditLogFilter{
+		ActorID:    c.Query("actorId"),
+		TargetID:   c.Query("targetId"),
+		ActionType: server.AuditLogAction(c.Query("actionType")),
+	}
+	var err error
+	if filter.After, err = queryTime(c, "after"); err == nil {
+		filter.Before, err = queryTime(c, "before")
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"after and before must be RFC 3339 times",
+		))
+	}
+
+	logs, nextCursor, err := h.serverService.ListAuditLogs(c.Context(), serverID, userID, filter, c.Query("cursor"), c.QueryInt("limit", 50))
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	response := make([]dto.AuditLogResponse, len(logs))
+	for i, l := range logs {
+		response[i] = dto.AuditLogResponse{
+			ID:         l.ID,
+			ActorID:    l.ActorID,
+			TargetID:   l.TargetID,
+			ActionType: string(l.ActionType),
+			Changes:    l.Changes,
+			Reason:     l.Reason,
+			CreatedAt:  l.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+		}
+	}
+
+	result := fiber.Map{"data": response}
+	if nextCursor != "" {
+		result["nextCursor"] = nextCursor
+	}
+
+	return c.JSON(result)
+}
+
+// GetNotificationSettings returns the user's notification settings for a
+// server.
+// GET /servers/:id/notification-settings
+func (h *ServerHandler) GetNotificationSettings(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+
+	settings, err := h.serverService.GetNotificationSettings(c.Context(), serverID, userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"data": notificationSettingsToDTO(settings)})
+}
+
+// UpdateNotificationSettings replaces the user's notification settings for a
+// server.
+// PUT /servers/:id/notification-settings
+func (h *ServerHandler) UpdateNotificationSettings(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+
+	var req dto.ServerNotificationSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	if err := dto.Validate(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"VALIDATION_ERROR",
+			err.Error(),
+		))
+	}
+
+	cmd := serverApp.UpdateNotificationSettingsCommand{
+		ServerID:         serverID,
+		UserID:           userID,
+		Level:            server.NotificationLevel(req.Level),
+		SuppressEveryone: req.SuppressEveryone,
+		SuppressRoles:    req.SuppressRoles,
+	}
+	if req.MutedUntil != nil {
+		mutedUntil, err := time.Parse(time.RFC3339, *req.MutedUntil)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+				"BAD_REQUEST",
+				"mutedUntil must be an RFC 3339 time",
+			))
+		}
+		cmd.MutedUntil = &mutedUntil
+	}
+
+	settings, err := h.serverService.UpdateNotificationSettings(c.Context(), cmd)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"data": notificationSettingsToDTO(settings)})
+}
+
 // Timeout times out a member.
 // POST /servers/:id/members/:userId/timeout
 func (h *ServerHandler) Timeout(c *fiber.Ctx) error {
@@ -467,14 +586,7 @@ func (h *ServerHandler) ListRoles(c *fiber.Ctx) error {
 
 	response := make([]dto.RoleResponse, len(roles))
 	for i, r := range roles {
-		response[i] = dto.RoleResponse{
-			ID:          r.ID,
-			Name:        r.Name,
-			Color:       r.Color,
-			Position:    r.Position,
-			Permissions: int64(r.Permissions),
-			IsDefault:   r.IsDefault,
-		}
+		response[i] = roleToDTO(r)
 	}
 
 	return c.JSON(fiber.Map{
@@ -509,14 +621,7 @@ func (h *ServerHandler) CreateRole(c *fiber.Ctx) error {
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"data": dto.RoleResponse{
-			ID:          role.ID,
-			Name:        role.Name,
-			Color:       role.Color,
-			Position:    role.Position,
-			Permissions: int64(role.Permissions),
-			IsDefault:   role.IsDefault,
-		},
+		"data": roleToDTO(role),
 	})
 }
 
@@ -554,15 +659,53 @@ func (h *ServerHandler) UpdateRole(c *fiber.Ctx) error {
 		return h.handleError(c, err)
 	}
 
+	if perms != nil {
+		h.wsHandler.RevalidateServer(serverID)
+	}
+
 	return c.JSON(fiber.Map{
-		"data": dto.RoleResponse{
-			ID:          role.ID,
-			Name:        role.Name,
-			Color:       role.Color,
-			Position:    role.Position,
-			Permissions: int64(role.Permissions),
-			IsDefault:   role.IsDefault,
-		},
+		"data": roleToDTO(role),
+	})
+}
+
+// ReorderRoles moves roles to new positions.
+// PATCH /servers/:id/roles/reorder
+func (h *ServerHandler) ReorderRoles(c *fiber.Ctx) error {
+	actorID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+
+	var req dto.ReorderRolesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	if err := dto.Validate(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"VALIDATION_ERROR",
+			err.Error(),
+		))
+	}
+
+	positions := make(map[string]int, len(req.Updates))
+	for _, u := range req.Updates {
+		positions[u.ID] = u.Position
+	}
+
+	roles, err := h.serverService.ReorderRoles(c.Context(), serverID, actorID, positions)
+	if err != nil {
+		return h.handleError(c, err)
+	}
+
+	response := make([]dto.RoleResponse, len(roles))
+	for i, r := range roles {
+		response[i] = roleToDTO(r)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": response,
 	})
 }
 
@@ -577,6 +720,8 @@ func (h *ServerHandler) DeleteRole(c *fiber.Ctx) error {
 		return h.handleError(c, err)
 	}
 
+	h.wsHandler.RevalidateServer(serverID)
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -599,6 +744,8 @@ func (h *ServerHandler) UpdateMemberRoles(c *fiber.Ctx) error {
 		return h.handleError(c, err)
 	}
 
+	h.wsHandler.RevokeUserSubscriptions(targetUserID)
+
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
@@ -634,12 +781,30 @@ func (h *ServerHandler) handleError(c *fiber.Ctx, err error) error {
 			"Server owner cannot leave. Transfer ownership or delete the server.",
 		))
 
+	case errors.Is(err, server.ErrRoleHierarchy):
+		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
+			"ROLE_HIERARCHY",
+			"You can only act on members and roles below your highest role",
+		))
+
+	case errors.Is(err, server.ErrRoleNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Role not found",
+		))
+
 	case errors.Is(err, server.ErrJoinRequestNotFound):
 		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
 			"JOIN_REQUEST_NOT_FOUND",
 			"Join request not found",
 		))
 
+	case errors.Is(err, revision.ErrInvalidRetention):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"INVALID_REVISION_RETENTION",
+			"Revision retention must be between 0 and 3650 days",
+		))
+
 	default:
 		slog.Error("server handler error", slog.Any("error", err))
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse(
@@ -649,6 +814,33 @@ func (h *ServerHandler) handleError(c *fiber.Ctx, err error) error {
 	}
 }
 
+// queryTime parses an optional RFC 3339 query parameter.
+func queryTime(c *fiber.Ctx, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func notificationSettingsToDTO(s *server.NotificationSettings) dto.ServerNotificationSettingsResponse {
+	resp := dto.ServerNotificationSettingsResponse{
+		ServerID:         s.ServerID,
+		Level:            string(s.Level),
+		SuppressEveryone: s.SuppressEveryone,
+		SuppressRoles:    s.SuppressRoles,
+	}
+	if s.IsMuted() {
+		mutedUntil := s.MutedUntil.Format("2006-01-02T15:04:05.000Z")
+		resp.MutedUntil = &mutedUntil
+	}
+	return resp
+}
+
 func serverToDTO(s *server.Server) dto.ServerResponse {
 	return dto.ServerResponse{
 		ID:           s.ID,
@@ -661,5 +853,28 @@ func serverToDTO(s *server.Server) dto.ServerResponse {
 		IsPublic:     s.IsPublic,
 		Tag:          s.Tag,
 		CreatedAt:    s.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+
+		RevisionRetentionDays: s.RevisionRetentionDays,
+	}
+}
+
+func roleToDTO(r *server.Role) dto.RoleResponse {
+	return dto.RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		Color:       r.Color,
+		Position:    r.Position,
+		Permissions: int64(r.Permissions),
+		IsDefault:   r.IsDefault,
+	}
+}
+
+func banToDTO(b *server.Ban) dto.BanResponse {
+	return dto.BanResponse{
+		ID:        b.ID,
+		UserID:    b.UserID,
+		BannedBy:  b.BannedBy,
+		Reason:    b.Reason,
+		CreatedAt: b.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
 	}
 }
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"pink/internal/adapters/http/dto"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+	"pink/internal/domain/user"
+	"pink/internal/domain/ws"
+	wsInfra "pink/internal/infrastructure/ws"
+)
+
+// ServerEventPublisher implements ws.ServerEventPublisher. It converts the
+// changed domain objects to the DTOs the HTTP API returns and broadcasts them
+// to "server:<id>" subscribers, or to the users the event names. Members
+// joining or leaving are also told on their own user channel so their other
+// devices update the server list.
+type ServerEventPublisher struct {
+	hub        *wsInfra.Hub
+	serverRepo server.Repository
+	userRepo   user.Repository
+}
+
+// NewServerEventPublisher creates a new ServerEventPublisher.
+func NewServerEventPublisher(hub *wsInfra.Hub, serverRepo server.Repository, userRepo user.Repository) *ServerEventPublisher {
+	return &ServerEventPublisher{
+		hub:        hub,
+		serverRepo: serverRepo,
+		userRepo:   userRepo,
+	}
+}
+
+// PublishServerEvent broadcasts an event without blocking the caller.
+func (p *ServerEventPublisher) PublishServerEvent(event ws.ServerEvent) {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("panic in PublishServerEvent", slog.Any("panic", r), slog.String("event", string(event.Type)))
+			}
+		}()
+		p.publish(event)
+	}()
+}
+
+func (p *ServerEventPublisher) publish(event ws.ServerEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp := dto.ServerEventResponse{
+		ServerID: event.ServerID,
+		ActorID:  event.ActorID,
+	}
+
+	switch payload := event.Payload.(type) {
+	case *server.Server:
+		srv := serverToDTO(payload)
+		resp.Server = &srv
+	case *server.Member:
+		member := p.memberToDTO(ctx, payload)
+		resp.Member = &member
+	case *server.Role:
+		role := roleToDTO(payload)
+		resp.Role = &role
+	case []*server.Role:
+		resp.Roles = make([]dto.RoleResponse, len(payload))
+		for i, r := range payload {
+			resp.Roles[i] = roleToDTO(r)
+		}
+	case *server.Ban:
+		ban := banToDTO(payload)
+		resp.Ban = &ban
+	case *channel.Channel:
+		ch := channelToDTO(payload)
+		resp.Channel = &ch
+	case []*channel.Channel:
+		resp.Channels = make([]dto.ChannelResponse, len(payload))
+		for i, ch := range payload {
+			resp.Channels[i] = channelToDTO(ch)
+		}
+	}
+
+	if event.UserIDs != nil {
+		for _, userID := range event.UserIDs {
+			p.broadcast(ws.SubUser, userID, event.Type, resp)
+		}
+		return
+	}
+	p.broadcast(ws.SubServer, event.ServerID, event.Type, resp)
+
+	// Keep the member's own server list in sync across devices
+	member, ok := event.Payload.(*server.Member)
+	if !ok {
+		return
+	}
+	switch event.Type {
+	case ws.EventMemberJoin:
+		userResp := dto.ServerEventResponse{ServerID: event.ServerID, ActorID: event.ActorID}
+		if srv, err := p.serverRepo.FindByID(ctx, event.ServerID); err == nil {
+			srvDTO := serverToDTO(srv)
+			userResp.Server = &srvDTO
+		}
+		p.broadcast(ws.SubUser, member.UserID, ws.EventServerJoin, userResp)
+	case ws.EventMemberLeave:
+		p.broadcast(ws.SubUser, member.UserID, ws.EventServerLeave, dto.ServerEventResponse{
+			ServerID: event.ServerID,
+			ActorID:  event.ActorID,
+		})
+	}
+}
+
+func (p *ServerEventPublisher) broadcast(subType ws.SubscriptionType, id string, eventType ws.EventType, payload dto.ServerEventResponse) {
+	msg, err := ws.NewMessage(eventType, payload)
+	if err != nil {
+		slog.Error("failed to create WS message", slog.Any("error", err))
+		return
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("failed to marshal WS message", slog.Any("error", err))
+		return
+	}
+
+	p.hub.BroadcastToSubscription(subType, id, data)
+}
+
+// memberToDTO converts a member the same way the member list does, with the
+// owner shown as such.
+func (p *ServerEventPublisher) memberToDTO(ctx context.Context, m *server.Member) dto.MemberWithUserResponse {
+	displayRole := "member"
+	if len(m.Roles) > 0 {
+		displayRole = m.Roles[0].Name
+	}
+	if srv, err := p.serverRepo.FindByID(ctx, m.ServerID); err == nil && srv.OwnerID == m.UserID {
+		displayRole = "owner"
+	}
+
+	roleIDs := make([]string, len(m.Roles))
+	for i, r := range m.Roles {
+		roleIDs[i] = r.ID
+	}
+
+	resp := dto.MemberWithUserResponse{
+		ID:          m.ID,
+		UserID:      m.UserID,
+		Role:        displayRole,
+		RoleIDs:     roleIDs,
+		InviteCode:  m.InviteCode,
+		IsTemporary: m.IsTemporary,
+	}
+	if !m.JoinedAt.IsZero() {
+		resp.JoinedAt = m.JoinedAt.Format("2006-01-02T15:04:05.000Z")
+	}
+	if m.IsTimedOut() {
+		until := m.CommunicationDisabledUntil.Format("2006-01-02T15:04:05.000Z")
+		resp.CommunicationDisabledUntil = &until
+	}
+
+	if p.userRepo != nil {
+		if u, err := p.userRepo.FindByID(ctx, m.UserID); err == nil && u != nil {
+			resp.User = &dto.PublicUserResponse{
+				ID:             u.ID,
+				Handle:         u.Handle,
+				DisplayName:    u.DisplayName,
+				AvatarGradient: u.AvatarGradient,
+			}
+		}
+	}
+
+	return resp
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pink/internal/adapters/http/dto"
+	"pink/internal/adapters/http/middleware"
+	channelApp "pink/internal/application/channel"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/history"
+	"pink/internal/domain/ws"
+)
+
+// ThreadHandler handles thread requests.
+type ThreadHandler struct {
+	threadService    *channelApp.ThreadService
+	websocketHandler *WebSocketHandler
+}
+
+// NewThreadHandler creates a new ThreadHandler.
+func NewThreadHandler(threadService *channelApp.ThreadService, websocketHandler *WebSocketHandler) *ThreadHandler {
+	return &ThreadHandler{
+		threadService:    threadService,
+		websocketHandler: websocketHandler,
+	}
+}
+
+// Create creates a thread in a channel.
+// POST /servers/:id/channels/:chId/threads
+func (h *ThreadHandler) Create(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	var req dto.CreateThreadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	thread, err := h.threadService.CreateThread(c.Context(), channelApp.CreateThreadCommand{
+		ServerID:         c.Params("id"),
+		ChannelID:        c.Params("chId"),
+		UserID:           userID,
+		Name:             req.Name,
+		MessageID:        req.MessageID,
+		AutoArchiveAfter: time.Duration(req.AutoArchiveMinutes) * time.Minute,
+	})
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": threadToDTO(thread),
+	})
+}
+
+// List lists a channel's threads. Pass archived=true for archived threads.
+// GET /servers/:id/channels/:chId/threads
+func (h *ThreadHandler) List(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	threads, nextCursor, err := h.threadService.ListThreads(c.Context(),
+		c.Params("id"), c.Params("chId"), userID,
+		c.QueryBool("archived"), c.Query("cursor"), c.QueryInt("limit", 20),
+	)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	response := make([]dto.ThreadResponse, len(threads))
+	for i, t := range threads {
+		response[i] = threadToDTO(t)
+	}
+
+	result := fiber.Map{"data": response}
+	if nextCursor != "" {
+		result["nextCursor"] = nextCursor
+	}
+
+	return c.JSON(result)
+}
+
+// Get returns a thread with the user's read state.
+// GET /servers/:id/channels/:chId/threads/:threadId
+func (h *ThreadHandler) Get(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	thread, readState, err := h.threadService.GetThread(c.Context(), c.Params("id"), c.Params("chId"), c.Params("threadId"), userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	resp := threadToDTO(thread)
+	if readState != nil {
+		resp.ReadState = &dto.ThreadReadStateResponse{
+			LastReadMessageID: readState.LastReadMessageID,
+			LastReadAt:        readState.LastReadAt.Format("2006-01-02T15:04:05.000Z"),
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": resp,
+	})
+}
+
+// Update renames, archives or locks a thread.
+// PATCH /servers/:id/channels/:chId/threads/:threadId
+func (h *ThreadHandler) Update(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	var req dto.UpdateThreadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	cmd := channelApp.UpdateThreadCommand{
+		ServerID:  c.Params("id"),
+		ChannelID: c.Params("chId"),
+		ThreadID:  c.Params("threadId"),
+		UserID:    userID,
+		Name:      req.Name,
+		Archived:  req.Archived,
+		Locked:    req.Locked,
+	}
+	if req.AutoArchiveMinutes != nil {
+		autoArchive := time.Duration(*req.AutoArchiveMinutes) * time.Minute
+		cmd.AutoArchiveAfter = &autoArchive
+	}
+
+	thread, err := h.threadService.UpdateThread(c.Context(), cmd)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": threadToDTO(thread),
+	})
+}
+
+// Delete deletes a thread and its messages.
+// DELETE /servers/:id/channels/:chId/threads/:threadId
+func (h *ThreadHandler) Delete(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	if err := h.threadService.DeleteThread(c.Context(), c.Params("id"), c.Params("chId"), c.Params("threadId"), userID); err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetMessages returns messages in a thread.
+// GET /servers/:id/channels/:chId/threads/:threadId/messages
+func (h *ThreadHandler) GetMessages(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	cursor, err := historyCursor(c, history.DefaultLimit)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	messages, page, err := h.threadService.GetMessages(c.Context(), channelApp.GetThreadMessagesCommand{
+		ServerID:  c.Params("id"),
+		ChannelID: c.Params("chId"),
+		ThreadID:  c.Params("threadId"),
+		UserID:    userID,
+		Cursor:    cursor,
+	})
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	response := make([]dto.ChannelMessageResponse, len(messages))
+	for i, msg := range messages {
+		response[i] = channelMessageToDTO(msg)
+	}
+
+	return c.JSON(historyResult(response, page))
+}
+
+// SendMessage sends a message in a thread.
+// POST /servers/:id/channels/:chId/threads/:threadId/messages
+func (h *ThreadHandler) SendMessage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+	channelID := c.Params("chId")
+
+	var req dto.SendChannelMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	msg, err := h.threadService.SendMessage(c.Context(), channelApp.SendThreadMessageCommand{
+		ServerID:      serverID,
+		ChannelID:     channelID,
+		ThreadID:      c.Params("threadId"),
+		UserID:        userID,
+		Content:       req.Content,
+		ReplyToID:     req.ReplyToID,
+		AttachmentIDs: req.AttachmentIDs,
+	})
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	// Thread messages go to the parent channel's subscribers
+	if h.websocketHandler != nil {
+		h.websocketHandler.BroadcastChannelMessage(serverID, channelID, ws.EventThreadMessage, channelMessageToDTO(msg))
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"data": channelMessageToDTO(msg),
+	})
+}
+
+// EditMessage edits a message in a thread.
+// PATCH /servers/:id/channels/:chId/threads/:threadId/messages/:msgId
+func (h *ThreadHandler) EditMessage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+	channelID := c.Params("chId")
+
+	var req dto.EditChannelMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	msg, err := h.threadService.EditMessage(c.Context(), serverID, channelID, c.Params("threadId"), c.Params("msgId"), userID, req.Content)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	if h.websocketHandler != nil {
+		h.websocketHandler.BroadcastChannelMessage(serverID, channelID, ws.EventThreadMessageEdited, channelMessageToDTO(msg))
+	}
+
+	return c.JSON(fiber.Map{
+		"data": channelMessageToDTO(msg),
+	})
+}
+
+// GetRevisions returns a thread message's edit history.
+// GET /servers/:id/channels/:chId/threads/:threadId/messages/:msgId/revisions
+func (h *ThreadHandler) GetRevisions(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	revisions, err := h.threadService.GetRevisions(c.Context(), c.Params("id"), c.Params("chId"), c.Params("threadId"), c.Params("msgId"), userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": revisionsToDTO(revisions),
+	})
+}
+
+// DeleteMessage deletes a message in a thread.
+// DELETE /servers/:id/channels/:chId/threads/:threadId/messages/:msgId
+func (h *ThreadHandler) DeleteMessage(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	serverID := c.Params("id")
+	channelID := c.Params("chId")
+	threadID := c.Params("threadId")
+	messageID := c.Params("msgId")
+
+	if err := h.threadService.DeleteMessage(c.Context(), serverID, channelID, threadID, messageID, userID); err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	if h.websocketHandler != nil {
+		h.websocketHandler.BroadcastChannelMessage(serverID, channelID, ws.EventThreadMessageDeleted, fiber.Map{"id": messageID, "threadId": threadID})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListMembers lists a thread's members.
+// GET /servers/:id/channels/:chId/threads/:threadId/members
+func (h *ThreadHandler) ListMembers(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	members, err := h.threadService.ListMembers(c.Context(), c.Params("id"), c.Params("chId"), c.Params("threadId"), userID)
+	if err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	response := make([]dto.ThreadMemberResponse, len(members))
+	for i, m := range members {
+		response[i] = threadMemberToDTO(m)
+	}
+
+	return c.JSON(fiber.Map{
+		"data": response,
+	})
+}
+
+// Join adds the user to a thread.
+// PUT /servers/:id/channels/:chId/threads/:threadId/members/@me
+func (h *ThreadHandler) Join(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	if err := h.threadService.JoinThread(c.Context(), c.Params("id"), c.Params("chId"), c.Params("threadId"), userID); err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Leave removes the user from a thread.
+// DELETE /servers/:id/channels/:chId/threads/:threadId/members/@me
+func (h *ThreadHandler) Leave(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	if err := h.threadService.LeaveThread(c.Context(), c.Params("id"), c.Params("chId"), c.Params("threadId"), userID); err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Ack marks a thread as read up to a message.
+// POST /servers/:id/channels/:chId/threads/:threadId/ack
+func (h *ThreadHandler) Ack(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	var req AckRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"BAD_REQUEST",
+			"Invalid request body",
+		))
+	}
+
+	if req.MessageID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+			"INVALID_REQUEST",
+			"Message ID is required",
+		))
+	}
+
+	if err := h.threadService.MarkRead(c.Context(), c.Params("id"), c.Params("chId"), c.Params("threadId"), userID, req.MessageID); err != nil {
+		return middleware.HandleDomainError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func threadToDTO(t *channel.Thread) dto.ThreadResponse {
+	resp := dto.ThreadResponse{
+		ID:                 t.ID,
+		ChannelID:          t.ChannelID,
+		ServerID:           t.ServerID,
+		ParentMessageID:    t.ParentMessageID,
+		OwnerID:            t.OwnerID,
+		Name:               t.Name,
+		MessageCount:       t.MessageCount,
+		MemberCount:        t.MemberCount,
+		IsArchived:         t.IsArchived,
+		IsLocked:           t.IsLocked,
+		AutoArchiveMinutes: int(t.AutoArchiveAfter / time.Minute),
+		LastMessageAt:      t.LastMessageAt.Format("2006-01-02T15:04:05.000Z"),
+		CreatedAt:          t.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+	}
+	if t.ArchivedAt != nil {
+		archivedAt := t.ArchivedAt.Format("2006-01-02T15:04:05.000Z")
+		resp.ArchivedAt = &archivedAt
+	}
+	return resp
+}
+
+func threadMemberToDTO(m *channel.ThreadMember) dto.ThreadMemberResponse {
+	return dto.ThreadMemberResponse{
+		ThreadID: m.ThreadID,
+		UserID:   m.UserID,
+		JoinedAt: m.JoinedAt.Format("2006-01-02T15:04:05.000Z"),
+	}
+}
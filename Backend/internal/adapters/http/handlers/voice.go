@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 
 	"pink/internal/adapters/http/dto"
+	permissionApp "pink/internal/application/permission"
 	"pink/internal/domain/channel"
 	"pink/internal/domain/server"
 	"pink/internal/domain/user" // Added as per instruction, though it's a duplicate
@@ -24,6 +25,7 @@ type VoiceHandler struct {
 	serverRepo      server.Repository
 	userRepo        user.Repository
 	participantRepo voice.ParticipantRepository
+	permissions     *permissionApp.Resolver
 	livekit         *livekit.Service
 }
 
@@ -34,6 +36,7 @@ func NewVoiceHandler(
 	serverRepo server.Repository,
 	userRepo user.Repository,
 	participantRepo voice.ParticipantRepository,
+	permissions *permissionApp.Resolver,
 	livekit *livekit.Service,
 ) *VoiceHandler {
 	return &VoiceHandler{
@@ -42,6 +45,7 @@ func NewVoiceHandler(
 		serverRepo:      serverRepo,
 		userRepo:        userRepo,
 		participantRepo: participantRepo,
+		permissions:     permissions,
 		livekit:         livekit,
 	}
 }
@@ -119,9 +123,25 @@ func (h *VoiceHandler) GetVoiceChannels(c *fiber.Ctx) error {
 		))
 	}
 
-	response := make([]dto.VoiceChannelResponse, len(channels))
-	for i, ch := range channels {
-		response[i] = voiceChannelToDTO(ch)
+	// Hide channels the member can't view. All channels are evaluated so
+	// category overwrites apply.
+	all, err := h.channelRepo.FindByServerID(c.Context(), serverID)
+	if err == nil {
+		all, err = h.permissions.VisibleChannels(c.Context(), serverID, userID, all)
+	}
+	if err != nil {
+		return h.handleError(c, err)
+	}
+	visible := make(map[string]bool, len(all))
+	for _, ch := range all {
+		visible[ch.ID] = true
+	}
+
+	response := make([]dto.VoiceChannelResponse, 0, len(channels))
+	for _, ch := range channels {
+		if visible[ch.ID] {
+			response = append(response, voiceChannelToDTO(ch))
+		}
 	}
 
 	return c.JSON(fiber.Map{"data": response})
@@ -238,14 +258,21 @@ func (h *VoiceHandler) GetVoiceToken(c *fiber.Ctx) error {
 		return h.handleError(c, err)
 	}
 
-	// Verify membership and get member for timeout check (single call)
-	member, err := h.memberRepo.FindByServerAndUser(c.Context(), channel.ServerID, userID)
+	// Resolve the member's permissions in this channel, overwrites included
+	input, err := h.permissions.ChannelInput(c.Context(), channel, userID)
 	if err != nil {
 		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
 			"FORBIDDEN",
 			"Not a server member",
 		))
 	}
+	perms := h.permissions.Calculate(input)
+	if !perms.Has(server.PermissionViewChannel | server.PermissionConnect) {
+		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
+			"FORBIDDEN",
+			"No permission to connect to this channel",
+		))
+	}
 
 	// Check user limit
 	if channel.UserLimit > 0 && channel.ParticipantCount >= channel.UserLimit {
@@ -262,9 +289,9 @@ func (h *VoiceHandler) GetVoiceToken(c *fiber.Ctx) error {
 		))
 	}
 
-	// Check for timeout using already fetched member
-	canPublish := true
-	if member.IsTimedOut() {
+	// Timed out members and members without Speak can only listen
+	canPublish := perms.Has(server.PermissionSpeak)
+	if !input.IsOwner && input.Member.IsTimedOut() {
 		canPublish = false
 	}
 
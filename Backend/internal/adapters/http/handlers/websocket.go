@@ -3,15 +3,25 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"strconv"
 	"time"
 
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 
+	channelApp "pink/internal/application/channel"
+	dmApp "pink/internal/application/dm"
+	permissionApp "pink/internal/application/permission"
+	"pink/internal/application/realtime"
 	"pink/internal/application/user"
+	"pink/internal/domain/channel"
 	"pink/internal/domain/live"
+	"pink/internal/domain/presence"
+	"pink/internal/domain/server"
+	"pink/internal/domain/slowmode"
 	"pink/internal/domain/ws"
 	wsInfra "pink/internal/infrastructure/ws"
 )
@@ -21,15 +31,55 @@ type WebSocketHandler struct {
 	hub           *wsInfra.Hub
 	userService   *user.Service
 	streamMsgRepo live.StreamMessageRepository
+	authorizer    ws.SubscriptionAuthorizer
+	channelRepo   channel.Repository
+	presence      *realtime.PresenceService
+
+	// Request/response commands, answered by requestId
+	messageService *channelApp.MessageService
+	channelService *channelApp.Service
+	dmService      *dmApp.Service
+	commands       map[ws.EventType]commandFunc
+
+	// Stream chat slowmode
+	streamRepo  live.StreamRepository
+	slowmode    slowmode.Limiter
+	permissions *permissionApp.Resolver
 }
 
 // NewWebSocketHandler creates a new WebSocketHandler.
-func NewWebSocketHandler(hub *wsInfra.Hub, userService *user.Service, streamMsgRepo live.StreamMessageRepository) *WebSocketHandler {
-	return &WebSocketHandler{
-		hub:           hub,
-		userService:   userService,
-		streamMsgRepo: streamMsgRepo,
+func NewWebSocketHandler(
+	hub *wsInfra.Hub,
+	userService *user.Service,
+	streamMsgRepo live.StreamMessageRepository,
+	authorizer ws.SubscriptionAuthorizer,
+	channelRepo channel.Repository,
+	presence *realtime.PresenceService,
+	messageService *channelApp.MessageService,
+	channelService *channelApp.Service,
+	dmService *dmApp.Service,
+) *WebSocketHandler {
+	h := &WebSocketHandler{
+		hub:            hub,
+		userService:    userService,
+		streamMsgRepo:  streamMsgRepo,
+		authorizer:     authorizer,
+		channelRepo:    channelRepo,
+		presence:       presence,
+		messageService: messageService,
+		channelService: channelService,
+		dmService:      dmService,
 	}
+	h.registerCommands()
+	return h
+}
+
+// SetStreamSlowmode enables slowmode in stream chat. Until it is set,
+// streams' slowmode is not enforced.
+func (h *WebSocketHandler) SetStreamSlowmode(streamRepo live.StreamRepository, limiter slowmode.Limiter, permissions *permissionApp.Resolver) {
+	h.streamRepo = streamRepo
+	h.slowmode = limiter
+	h.permissions = permissions
 }
 
 // Upgrade is the middleware to upgrade HTTP to WebSocket.
@@ -53,23 +103,58 @@ func (h *WebSocketHandler) Handle() fiber.Handler {
 			return
 		}
 
+		// Try to resume a previous session (?sessionId=...&lastSeq=...)
+		if client, replayed, ok := h.resume(c, userID); ok {
+			defer h.hub.Unregister(client)
+
+			resumedMsg, _ := ws.NewMessage(ws.EventResumed, map[string]interface{}{
+				"sessionId": client.ID,
+				"userId":    userID,
+				"replayed":  replayed,
+			})
+			if data, err := json.Marshal(resumedMsg); err == nil {
+				client.Deliver(data)
+			}
+
+			go h.writePump(client)
+			h.readPump(client)
+			return
+		}
+
 		// Create client
 		clientID := uuid.New().String()
-		client := wsInfra.NewClient(clientID, userID, c)
+		client := h.hub.NewClient(clientID, userID, c)
 
 		// Register client
 		h.hub.Register(client)
 		defer h.hub.Unregister(client)
 
+		// A session that cannot be resumed here (expired, replay window
+		// passed, or held by another node) is rejected explicitly so the
+		// client knows to refetch state instead of trusting its cache
+		if sessionID := c.Query("sessionId"); sessionID != "" {
+			invalidMsg, _ := ws.NewMessage(ws.EventInvalidSession, map[string]interface{}{
+				"sessionId": sessionID,
+				"resumable": false,
+			})
+			if data, err := json.Marshal(invalidMsg); err == nil {
+				client.Deliver(data)
+			}
+		}
+
 		// Send connected message
 		connectedMsg, _ := ws.NewMessage(ws.EventConnected, map[string]string{
-			"clientId": clientID,
-			"userId":   userID,
+			"clientId":  clientID,
+			"sessionId": clientID,
+			"userId":    userID,
 		})
 		if data, err := json.Marshal(connectedMsg); err == nil {
-			client.Send <- data
+			client.Deliver(data)
 		}
 
+		// Send presence snapshot
+		go h.sendPresenceSync(client)
+
 		// Start write pump
 		go h.writePump(client)
 
@@ -78,12 +163,41 @@ func (h *WebSocketHandler) Handle() fiber.Handler {
 	})
 }
 
+// resume attaches the connection to an earlier session if the client asked
+// for one and this node still holds it. Sessions are node-local, so a
+// reconnect routed to another node falls back to a fresh session.
+func (h *WebSocketHandler) resume(c *websocket.Conn, userID string) (*wsInfra.Client, int, bool) {
+	sessionID := c.Query("sessionId")
+	if sessionID == "" {
+		return nil, 0, false
+	}
+
+	lastSeq, err := strconv.ParseInt(c.Query("lastSeq", "0"), 10, 64)
+	if err != nil {
+		return nil, 0, false
+	}
+
+	client := h.hub.NewClient(sessionID, userID, c)
+	replayed, ok := h.hub.Resume(client, lastSeq)
+	if !ok {
+		return nil, 0, false
+	}
+	return client, replayed, true
+}
+
 func (h *WebSocketHandler) readPump(client *wsInfra.Client) {
 	defer func() {
 		h.hub.Unregister(client)
 		client.Conn.Close()
 	}()
 
+	// Connections that stop answering pings are closed
+	pongTimeout := h.hub.Options().PongTimeout
+	_ = client.Conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	client.Conn.SetPongHandler(func(string) error {
+		return client.Conn.SetReadDeadline(time.Now().Add(pongTimeout))
+	})
+
 	for {
 		_, message, err := client.Conn.ReadMessage()
 		if err != nil {
@@ -92,34 +206,56 @@ func (h *WebSocketHandler) readPump(client *wsInfra.Client) {
 			}
 			break
 		}
+		_ = client.Conn.SetReadDeadline(time.Now().Add(pongTimeout))
 
 		h.handleMessage(client, message)
 	}
 }
 
+// writePump sends queued events to the socket. Every write is bounded by the
+// hub's write timeout so a stalled connection cannot hold the goroutine.
 func (h *WebSocketHandler) writePump(client *wsInfra.Client) {
-	ticker := time.NewTicker(30 * time.Second)
+	opts := h.hub.Options()
+	ticker := time.NewTicker(opts.PingInterval())
 	defer func() {
 		ticker.Stop()
 		client.Conn.Close()
 	}()
 
+	write := func(messageType int, data []byte) error {
+		if err := client.Conn.SetWriteDeadline(time.Now().Add(opts.WriteTimeout)); err != nil {
+			return err
+		}
+		return client.Conn.WriteMessage(messageType, data)
+	}
+
 	for {
 		select {
 		case message, ok := <-client.Send:
 			if !ok {
-				// Channel closed
-				_ = client.Conn.WriteMessage(websocket.CloseMessage, []byte{})
+				// Channel closed; tell slow consumers to resync
+				closeMsg := []byte{}
+				if code, reason := client.CloseCode(); code != 0 {
+					closeMsg = websocket.FormatCloseMessage(code, reason)
+				}
+				_ = write(websocket.CloseMessage, closeMsg)
 				return
 			}
 
-			if err := client.Conn.WriteMessage(websocket.TextMessage, message); err != nil {
+			if err := write(websocket.TextMessage, message); err != nil {
 				return
 			}
 
+			// Coalesced events wait until the queue drains
+			if len(client.Send) == 0 {
+				client.FlushPending()
+			}
+
 		case <-ticker.C:
+			client.FlushPending()
+
 			// Send ping
-			if err := client.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			if err := write(websocket.PingMessage, nil); err != nil {
 				return
 			}
 		}
@@ -146,8 +282,27 @@ func (h *WebSocketHandler) handleMessage(client *wsInfra.Client, data []byte) {
 	case ws.EventStreamChatMsg:
 		h.handleStreamChatMessage(client, msg)
 
+	case ws.EventPresenceUpdate:
+		h.handlePresenceUpdate(client, msg.Data)
+
 	default:
+		if command, ok := h.commands[msg.Type]; ok {
+			h.handleCommand(client, msg, command)
+			return
+		}
 		slog.Debug("Unknown message type", slog.String("type", string(msg.Type)))
+
+		// A client waiting on a reply is told the command does not exist
+		if msg.RequestID != "" {
+			h.sendReply(client, msg.RequestID, ws.ReplyEventData{
+				Command: msg.Type,
+				Error: &ws.ReplyError{
+					Code:    "UNKNOWN_COMMAND",
+					Message: "Unknown command",
+					Status:  fiber.StatusBadRequest,
+				},
+			})
+		}
 	}
 }
 
@@ -157,16 +312,53 @@ func (h *WebSocketHandler) handleSubscribe(client *wsInfra.Client, data json.Raw
 		return
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	granted := make([]ws.Subscription, 0, len(req.Subscriptions))
 	for _, sub := range req.Subscriptions {
+		if err := h.authorizer.Authorize(ctx, client.UserID, sub); err != nil {
+			h.sendSubscriptionError(client, sub, err)
+			continue
+		}
 		h.hub.Subscribe(client.ID, sub.Type, sub.ID)
+		granted = append(granted, sub)
+	}
+
+	if len(granted) == 0 {
+		return
 	}
 
 	// Send confirmation
 	resp, _ := ws.NewMessage(ws.EventSubscribed, map[string]interface{}{
-		"subscriptions": req.Subscriptions,
+		"subscriptions": granted,
 	})
 	if data, err := json.Marshal(resp); err == nil {
-		client.Send <- data
+		client.Deliver(data)
+	}
+}
+
+func (h *WebSocketHandler) sendSubscriptionError(client *wsInfra.Client, sub ws.Subscription, err error) {
+	payload := ws.ErrorEventData{
+		Code:         "SUBSCRIPTION_FAILED",
+		Message:      "Failed to subscribe",
+		Subscription: &sub,
+	}
+
+	switch {
+	case errors.Is(err, ws.ErrSubscriptionForbidden):
+		payload.Code = "SUBSCRIPTION_FORBIDDEN"
+		payload.Message = "You do not have access to this subscription"
+	case errors.Is(err, ws.ErrInvalidSubscription):
+		payload.Code = "INVALID_SUBSCRIPTION"
+		payload.Message = "Invalid subscription"
+	default:
+		slog.Error("Subscription authorization failed", slog.Any("error", err), slog.String("key", sub.Key()))
+	}
+
+	msg, _ := ws.NewMessage(ws.EventError, payload)
+	if data, err := json.Marshal(msg); err == nil {
+		client.Deliver(data)
 	}
 }
 
@@ -177,6 +369,10 @@ func (h *WebSocketHandler) handleUnsubscribe(client *wsInfra.Client, data json.R
 	}
 
 	for _, sub := range req.Subscriptions {
+		// The personal user channel is required for direct events
+		if sub.Type == ws.SubUser {
+			continue
+		}
 		h.hub.Unsubscribe(client.ID, sub.Type, sub.ID)
 	}
 }
@@ -196,14 +392,84 @@ func (h *WebSocketHandler) handleTyping(client *wsInfra.Client, msg ws.Message)
 		typingData.UserDisplayName = u.DisplayName
 	}
 
-	// Broadcast to conversation or channel subscribers
+	// Broadcast to conversation or channel subscribers.
+	// Only clients with an authorized subscription may send typing events.
 	broadcastMsg, _ := ws.NewMessage(msg.Type, typingData)
 	data, _ := json.Marshal(broadcastMsg)
 
 	if typingData.ConversationID != "" {
-		h.hub.BroadcastToSubscription(ws.SubConversation, typingData.ConversationID, data)
+		sub := ws.Subscription{Type: ws.SubConversation, ID: typingData.ConversationID}
+		if client.IsSubscribed(sub.Key()) {
+			h.hub.BroadcastToSubscription(ws.SubConversation, typingData.ConversationID, data)
+		}
 	} else if typingData.ChannelID != "" {
-		h.hub.BroadcastToSubscription(ws.SubChannel, typingData.ChannelID, data)
+		sub := ws.Subscription{Type: ws.SubChannel, ID: typingData.ChannelID}
+		if client.IsSubscribed(sub.Key()) {
+			h.hub.BroadcastToSubscription(ws.SubChannel, typingData.ChannelID, data)
+		}
+	}
+}
+
+func (h *WebSocketHandler) sendPresenceSync(client *wsInfra.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	presences, err := h.presence.Snapshot(ctx, client.UserID)
+	if err != nil {
+		slog.Warn("Failed to build presence snapshot", slog.Any("error", err), slog.String("userId", client.UserID))
+		return
+	}
+
+	msg, _ := ws.NewMessage(ws.EventPresenceSync, ws.PresenceSyncEventData{Presences: presences})
+	if data, err := json.Marshal(msg); err == nil {
+		client.Deliver(data)
+	}
+}
+
+func (h *WebSocketHandler) handlePresenceUpdate(client *wsInfra.Client, data json.RawMessage) {
+	var req ws.PresenceUpdateRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return
+	}
+
+	cmd := realtime.UpdatePresenceCommand{
+		UserID: client.UserID,
+		Status: presence.Status(req.Status),
+	}
+	if req.CustomStatus != nil && (req.CustomStatus.Text != "" || req.CustomStatus.Emoji != "") {
+		cmd.CustomStatus = &presence.CustomStatus{
+			Text:  req.CustomStatus.Text,
+			Emoji: req.CustomStatus.Emoji,
+		}
+		if req.CustomStatus.ExpiresAt != nil {
+			if t, err := time.Parse(time.RFC3339, *req.CustomStatus.ExpiresAt); err == nil {
+				cmd.CustomStatus.ExpiresAt = &t
+			}
+		}
+	}
+	if req.Activity != nil {
+		cmd.Activity = &presence.Activity{
+			Type:    presence.ActivityType(req.Activity.Type),
+			Name:    req.Activity.Name,
+			Details: req.Activity.Details,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := h.presence.Update(ctx, cmd); err != nil {
+		payload := ws.ErrorEventData{Code: "PRESENCE_UPDATE_FAILED", Message: "Failed to update presence"}
+		if errors.Is(err, presence.ErrInvalidStatus) {
+			payload = ws.ErrorEventData{Code: "INVALID_PRESENCE", Message: "Invalid presence"}
+		} else {
+			slog.Error("Failed to update presence", slog.Any("error", err), slog.String("userId", client.UserID))
+		}
+
+		msg, _ := ws.NewMessage(ws.EventError, payload)
+		if data, err := json.Marshal(msg); err == nil {
+			client.Deliver(data)
+		}
 	}
 }
 
@@ -244,6 +510,21 @@ func (h *WebSocketHandler) BroadcastChannelMessage(serverID, channelID string, e
 	h.hub.BroadcastToSubscription(ws.SubChannel, channelID, data)
 }
 
+// BroadcastToChannel sends an event to a channel's subscribers.
+func (h *WebSocketHandler) BroadcastToChannel(channelID string, eventType ws.EventType, payload interface{}) {
+	msg, err := ws.NewMessage(eventType, payload)
+	if err != nil {
+		return
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	h.hub.BroadcastToSubscription(ws.SubChannel, channelID, data)
+}
+
 // BroadcastToUser sends a message to all connections of a user.
 func (h *WebSocketHandler) BroadcastToUser(userID string, eventType ws.EventType, payload interface{}) {
 	msg, err := ws.NewMessage(eventType, payload)
@@ -259,6 +540,38 @@ func (h *WebSocketHandler) BroadcastToUser(userID string, eventType ws.EventType
 	h.hub.BroadcastToUser(userID, data)
 }
 
+// RevokeUserSubscriptions re-checks all of a user's subscriptions, e.g. after
+// they were kicked, banned or had their roles changed.
+func (h *WebSocketHandler) RevokeUserSubscriptions(userID string) {
+	h.hub.RevalidateUser(userID)
+}
+
+// RevalidateChannel re-checks every subscriber of a channel, e.g. after its
+// permission overwrites changed.
+func (h *WebSocketHandler) RevalidateChannel(channelID string) {
+	h.hub.RevalidateSubscription(ws.SubChannel, channelID)
+}
+
+// RevalidateServer re-checks every subscriber of a server's channels, e.g.
+// after a role's permissions changed.
+func (h *WebSocketHandler) RevalidateServer(serverID string) {
+	h.hub.RevalidateSubscription(ws.SubServer, serverID)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		channels, err := h.channelRepo.FindByServerID(ctx, serverID)
+		if err != nil {
+			slog.Warn("Failed to list channels for revalidation", slog.Any("error", err), slog.String("serverId", serverID))
+			return
+		}
+		for _, ch := range channels {
+			h.hub.RevalidateSubscription(ws.SubChannel, ch.ID)
+		}
+	}()
+}
+
 func toMap(v interface{}) map[string]interface{} {
 	data, _ := json.Marshal(v)
 	var result map[string]interface{}
@@ -279,6 +592,17 @@ func (h *WebSocketHandler) handleStreamChatMessage(client *wsInfra.Client, msg w
 		return
 	}
 
+	// Only viewers with an authorized stream subscription may chat
+	if !client.IsSubscribed(ws.Subscription{Type: ws.SubStream, ID: input.StreamID}.Key()) {
+		return
+	}
+
+	held, err := h.enforceStreamSlowmode(context.Background(), input.StreamID, client.UserID)
+	if err != nil {
+		h.sendStreamChatError(client, err)
+		return
+	}
+
 	// Persist message
 	chatMsg := &live.ChatMessage{
 		ID:        uuid.New().String(),
@@ -290,6 +614,9 @@ func (h *WebSocketHandler) handleStreamChatMessage(client *wsInfra.Client, msg w
 
 	if err := h.streamMsgRepo.Create(context.Background(), chatMsg); err != nil {
 		slog.Error("Failed to save stream chat message", "error", err)
+		if held {
+			h.releaseStreamSlowmode(context.Background(), input.StreamID, client.UserID)
+		}
 		return
 	}
 
@@ -309,3 +636,57 @@ func (h *WebSocketHandler) handleStreamChatMessage(client *wsInfra.Client, msg w
 
 	h.hub.BroadcastToSubscription(ws.SubStream, input.StreamID, data)
 }
+
+// enforceStreamSlowmode holds a viewer to the stream's slowmode and reports
+// whether a cooldown was started. The streamer is exempt, and so are members
+// of a server stream's server with ManageMessages or BypassSlowmode.
+func (h *WebSocketHandler) enforceStreamSlowmode(ctx context.Context, streamID, userID string) (bool, error) {
+	if h.slowmode == nil {
+		return false, nil
+	}
+
+	stream, err := h.streamRepo.FindByID(ctx, streamID)
+	if err != nil {
+		slog.Warn("Failed to load stream for slowmode", slog.Any("error", err), slog.String("streamId", streamID))
+		return false, nil
+	}
+	if stream.SlowmodeSeconds == 0 || stream.UserID == userID {
+		return false, nil
+	}
+
+	if stream.ServerID != nil {
+		perms, err := h.permissions.ServerPermissions(ctx, *stream.ServerID, userID)
+		if err == nil && (perms.Has(server.PermissionManageMessages) || perms.Has(server.PermissionBypassSlowmode)) {
+			return false, nil
+		}
+	}
+
+	if err := slowmode.Enforce(ctx, h.slowmode, stream.ID, userID, stream.SlowmodeSeconds); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// releaseStreamSlowmode ends the cooldown enforceStreamSlowmode started for a
+// chat message that could not be saved, so the viewer can retry straight away.
+func (h *WebSocketHandler) releaseStreamSlowmode(ctx context.Context, streamID, userID string) {
+	if err := h.slowmode.Release(ctx, streamID, userID); err != nil {
+		slog.Warn("Failed to release stream slowmode cooldown", slog.Any("error", err), slog.String("streamId", streamID))
+	}
+}
+
+func (h *WebSocketHandler) sendStreamChatError(client *wsInfra.Client, err error) {
+	payload := ws.ErrorEventData{
+		Code:    "SLOWMODE",
+		Message: "Slowmode is enabled; wait before sending another message",
+	}
+	var cooldown *slowmode.CooldownError
+	if errors.As(err, &cooldown) {
+		payload.RetryAfter = cooldown.RetryAfterSeconds()
+	}
+
+	msg, _ := ws.NewMessage(ws.EventError, payload)
+	if data, err := json.Marshal(msg); err == nil {
+		client.Deliver(data)
+	}
+}
@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"pink/internal/adapters/http/middleware"
+	channelApp "pink/internal/application/channel"
+	dmApp "pink/internal/application/dm"
+	"pink/internal/domain/dm"
+	"pink/internal/domain/ws"
+	wsInfra "pink/internal/infrastructure/ws"
+)
+
+// errBadCommand is returned for command payloads that cannot be decoded or
+// are missing required fields.
+var errBadCommand = errors.New("bad command")
+
+// commandFunc executes a WebSocket command for a client and returns its result.
+type commandFunc func(ctx context.Context, client *wsInfra.Client, data json.RawMessage) (interface{}, error)
+
+func (h *WebSocketHandler) registerCommands() {
+	h.commands = map[ws.EventType]commandFunc{
+		ws.CommandSendChannelMessage: h.commandSendChannelMessage,
+		ws.CommandSendDM:             h.commandSendDM,
+		ws.CommandEditMessage:        h.commandEditMessage,
+		ws.CommandDeleteMessage:      h.commandDeleteMessage,
+		ws.CommandAckChannel:         h.commandAckChannel,
+		ws.CommandMarkDMRead:         h.commandMarkDMRead,
+	}
+}
+
+// handleCommand runs a command and replies with its result, correlated by the
+// message's requestId. Commands run in order on the read loop, so a client's
+// sends are applied in the order it issued them.
+func (h *WebSocketHandler) handleCommand(client *wsInfra.Client, msg ws.Message, command commandFunc) {
+	reply := ws.ReplyEventData{Command: msg.Type}
+
+	if msg.RequestID == "" {
+		reply.Error = &ws.ReplyError{
+			Code:    "BAD_REQUEST",
+			Message: "requestId is required",
+			Status:  fiber.StatusBadRequest,
+		}
+		h.sendReply(client, msg.RequestID, reply)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := command(ctx, client, msg.Data)
+	if err != nil {
+		reply.Error = commandError(err)
+	} else {
+		reply.OK = true
+		reply.Result = result
+	}
+
+	h.sendReply(client, msg.RequestID, reply)
+}
+
+func (h *WebSocketHandler) sendReply(client *wsInfra.Client, requestID string, reply ws.ReplyEventData) {
+	msg, err := ws.NewMessage(ws.EventReply, reply)
+	if err != nil {
+		return
+	}
+	msg.RequestID = requestID
+
+	if data, err := json.Marshal(msg); err == nil {
+		client.Deliver(data)
+	}
+}
+
+// commandError maps a command failure to the same code and status the HTTP
+// API would return.
+func commandError(err error) *ws.ReplyError {
+	if errors.Is(err, errBadCommand) {
+		return &ws.ReplyError{
+			Code:    "BAD_REQUEST",
+			Message: "Invalid command payload",
+			Status:  fiber.StatusBadRequest,
+		}
+	}
+
+	status, resp := middleware.DomainError(err)
+	return &ws.ReplyError{
+		Code:    resp.Error.Code,
+		Message: resp.Error.Message,
+		Status:  status,
+		Details: resp.Error.Details,
+	}
+}
+
+func decodeCommand(data json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return errBadCommand
+	}
+	return nil
+}
+
+// ============================================================================
+// CHANNEL MESSAGES
+// ============================================================================
+
+func (h *WebSocketHandler) commandSendChannelMessage(ctx context.Context, client *wsInfra.Client, data json.RawMessage) (interface{}, error) {
+	var cmd ws.SendChannelMessageCommand
+	if err := decodeCommand(data, &cmd); err != nil {
+		return nil, err
+	}
+	if cmd.ServerID == "" || cmd.ChannelID == "" {
+		return nil, errBadCommand
+	}
+
+	msg, err := h.messageService.SendMessage(ctx, channelApp.SendMessageCommand{
+		ServerID:      cmd.ServerID,
+		ChannelID:     cmd.ChannelID,
+		UserID:        client.UserID,
+		Content:       cmd.Content,
+		ReplyToID:     cmd.ReplyToID,
+		AttachmentIDs: cmd.AttachmentIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := channelMessageToDTO(msg)
+	h.BroadcastChannelMessage(cmd.ServerID, cmd.ChannelID, ws.EventChannelMessage, resp)
+	return resp, nil
+}
+
+func (h *WebSocketHandler) commandAckChannel(ctx context.Context, client *wsInfra.Client, data json.RawMessage) (interface{}, error) {
+	var cmd ws.AckChannelCommand
+	if err := decodeCommand(data, &cmd); err != nil {
+		return nil, err
+	}
+	if cmd.ChannelID == "" || cmd.MessageID == "" {
+		return nil, errBadCommand
+	}
+
+	if err := h.channelService.AckMessage(ctx, client.UserID, cmd.ChannelID, cmd.MessageID); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+// ============================================================================
+// DIRECT MESSAGES
+// ============================================================================
+
+func (h *WebSocketHandler) commandSendDM(ctx context.Context, client *wsInfra.Client, data json.RawMessage) (interface{}, error) {
+	var cmd ws.SendDMCommand
+	if err := decodeCommand(data, &cmd); err != nil {
+		return nil, err
+	}
+	if cmd.ConversationID == "" {
+		return nil, errBadCommand
+	}
+
+	msg, err := h.dmService.SendMessage(ctx, dmApp.SendMessageCommand{
+		ConversationID: cmd.ConversationID,
+		SenderID:       client.UserID,
+		Content:        cmd.Content,
+		AttachmentIDs:  cmd.AttachmentIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.broadcastDM(ctx, ws.EventDMMessage, msg)
+	return messageToDTO(msg), nil
+}
+
+func (h *WebSocketHandler) commandMarkDMRead(ctx context.Context, client *wsInfra.Client, data json.RawMessage) (interface{}, error) {
+	var cmd ws.MarkDMReadCommand
+	if err := decodeCommand(data, &cmd); err != nil {
+		return nil, err
+	}
+	if cmd.ConversationID == "" {
+		return nil, errBadCommand
+	}
+
+	if err := h.dmService.MarkAsRead(ctx, cmd.ConversationID, client.UserID); err != nil {
+		return nil, err
+	}
+
+	readMsg, err := ws.NewMessage(ws.EventDMRead, map[string]string{
+		"conversationId": cmd.ConversationID,
+		"userId":         client.UserID,
+	})
+	if err == nil {
+		if data, err := json.Marshal(readMsg); err == nil {
+			h.hub.BroadcastToSubscription(ws.SubConversation, cmd.ConversationID, data)
+		}
+	}
+	return cmd, nil
+}
+
+// broadcastDM sends a DM event to conversation subscribers, with the sender
+// filled in like the HTTP API does.
+func (h *WebSocketHandler) broadcastDM(ctx context.Context, eventType ws.EventType, msg *dm.Message) {
+	if msg.Sender == nil {
+		if sender, err := h.userService.GetByID(ctx, msg.SenderID); err == nil {
+			msg.Sender = &dm.ConversationUser{
+				ID:             sender.ID,
+				Handle:         sender.Handle,
+				DisplayName:    sender.DisplayName,
+				AvatarGradient: sender.AvatarGradient,
+			}
+		} else {
+			slog.Warn("Failed to load DM sender", slog.Any("error", err), slog.String("userId", msg.SenderID))
+		}
+	}
+
+	h.BroadcastDMMessage(msg.ConversationID, eventType, messageToMap(msg))
+}
+
+// ============================================================================
+// SHARED
+// ============================================================================
+
+func (h *WebSocketHandler) commandEditMessage(ctx context.Context, client *wsInfra.Client, data json.RawMessage) (interface{}, error) {
+	var cmd ws.EditMessageCommand
+	if err := decodeCommand(data, &cmd); err != nil {
+		return nil, err
+	}
+	if cmd.MessageID == "" {
+		return nil, errBadCommand
+	}
+
+	if cmd.ChannelID != "" {
+		if cmd.ServerID == "" {
+			return nil, errBadCommand
+		}
+		msg, err := h.messageService.EditMessage(ctx, cmd.ServerID, cmd.ChannelID, cmd.MessageID, client.UserID, cmd.Content)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := channelMessageToDTO(msg)
+		h.BroadcastChannelMessage(cmd.ServerID, cmd.ChannelID, ws.EventChannelMessageEdited, resp)
+		return resp, nil
+	}
+
+	msg, err := h.dmService.EditMessage(ctx, cmd.MessageID, client.UserID, cmd.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	h.broadcastDM(ctx, ws.EventDMMessageEdited, msg)
+	return messageToDTO(msg), nil
+}
+
+func (h *WebSocketHandler) commandDeleteMessage(ctx context.Context, client *wsInfra.Client, data json.RawMessage) (interface{}, error) {
+	var cmd ws.DeleteMessageCommand
+	if err := decodeCommand(data, &cmd); err != nil {
+		return nil, err
+	}
+	if cmd.MessageID == "" {
+		return nil, errBadCommand
+	}
+
+	if cmd.ChannelID != "" {
+		if cmd.ServerID == "" {
+			return nil, errBadCommand
+		}
+		if err := h.messageService.DeleteMessage(ctx, cmd.ServerID, cmd.ChannelID, cmd.MessageID, client.UserID); err != nil {
+			return nil, err
+		}
+
+		h.BroadcastChannelMessage(cmd.ServerID, cmd.ChannelID, ws.EventChannelMessageDeleted, map[string]string{"id": cmd.MessageID})
+		return cmd, nil
+	}
+
+	msg, err := h.dmService.DeleteMessage(ctx, cmd.MessageID, client.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tell the conversation the message was in, not the one the client named
+	cmd.ConversationID = msg.ConversationID
+	h.BroadcastDMMessage(msg.ConversationID, ws.EventDMMessageDeleted, messageToMap(&dm.Message{ID: msg.ID, ConversationID: msg.ConversationID}))
+	return cmd, nil
+}
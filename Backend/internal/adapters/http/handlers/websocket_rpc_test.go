@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	dmApp "pink/internal/application/dm"
+	"pink/internal/application/testutil"
+	"pink/internal/domain/ws"
+	wsInfra "pink/internal/infrastructure/ws"
+)
+
+// setupRPC creates a WebSocket handler whose DM commands run against mocked
+// repositories. user_1 takes part in conv_1; user_3 does not.
+func setupRPC(t *testing.T) (*WebSocketHandler, *testutil.MockDMMessageRepository) {
+	convRepo := new(testutil.MockConversationRepository)
+	messageRepo := new(testutil.MockDMMessageRepository)
+	convRepo.On("IsParticipant", mock.Anything, "conv_1", "user_1").Return(true, nil)
+	convRepo.On("IsParticipant", mock.Anything, "conv_1", "user_3").Return(false, nil)
+
+	h := &WebSocketHandler{
+		hub:       wsInfra.NewHub(nil, wsInfra.Options{}),
+		dmService: dmApp.NewService(convRepo, messageRepo),
+	}
+	h.registerCommands()
+	return h, messageRepo
+}
+
+// sendCommand runs a raw client message through the handler and returns the
+// reply the client received.
+func sendCommand(t *testing.T, h *WebSocketHandler, client *wsInfra.Client, raw string) ws.Message {
+	t.Helper()
+	h.handleMessage(client, []byte(raw))
+
+	require.NotEmpty(t, client.Send, "no reply")
+	var reply ws.Message
+	require.NoError(t, json.Unmarshal(<-client.Send, &reply))
+	require.Equal(t, ws.EventReply, reply.Type)
+	return reply
+}
+
+func replyData(t *testing.T, msg ws.Message) ws.ReplyEventData {
+	t.Helper()
+	var data ws.ReplyEventData
+	require.NoError(t, json.Unmarshal(msg.Data, &data))
+	return data
+}
+
+func TestWebSocketRPC_EchoesRequestID(t *testing.T) {
+	h, messageRepo := setupRPC(t)
+	messageRepo.On("MarkAsRead", mock.Anything, "conv_1", "user_1", "").Return(nil)
+	client := wsInfra.NewClient("c1", "user_1", nil)
+
+	reply := sendCommand(t, h, client, `{"type":"mark_dm_read","requestId":"req-42","data":{"conversationId":"conv_1"}}`)
+
+	assert.Equal(t, "req-42", reply.RequestID)
+	data := replyData(t, reply)
+	assert.True(t, data.OK)
+	assert.Equal(t, ws.CommandMarkDMRead, data.Command)
+	assert.Nil(t, data.Error)
+}
+
+func TestWebSocketRPC_RequiresRequestID(t *testing.T) {
+	h, messageRepo := setupRPC(t)
+	client := wsInfra.NewClient("c1", "user_1", nil)
+
+	data := replyData(t, sendCommand(t, h, client, `{"type":"mark_dm_read","data":{"conversationId":"conv_1"}}`))
+
+	require.NotNil(t, data.Error)
+	assert.Equal(t, fiber.StatusBadRequest, data.Error.Status)
+	messageRepo.AssertNotCalled(t, "MarkAsRead", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebSocketRPC_UnknownCommand(t *testing.T) {
+	h, _ := setupRPC(t)
+	client := wsInfra.NewClient("c1", "user_1", nil)
+
+	reply := sendCommand(t, h, client, `{"type":"launch_rockets","requestId":"req-1"}`)
+
+	assert.Equal(t, "req-1", reply.RequestID)
+	data := replyData(t, reply)
+	assert.False(t, data.OK)
+	require.NotNil(t, data.Error)
+	assert.Equal(t, "UNKNOWN_COMMAND", data.Error.Code)
+	assert.Equal(t, fiber.StatusBadRequest, data.Error.Status)
+}
+
+func TestWebSocketRPC_MalformedPayload(t *testing.T) {
+	for name, payload := range map[string]string{
+		"not an object":  `"conv_1"`,
+		"missing fields": `{}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			h, messageRepo := setupRPC(t)
+			client := wsInfra.NewClient("c1", "user_1", nil)
+
+			data := replyData(t, sendCommand(t, h, client, `{"type":"mark_dm_read","requestId":"req-1","data":`+payload+`}`))
+
+			require.NotNil(t, data.Error)
+			assert.Equal(t, "BAD_REQUEST", data.Error.Code)
+			assert.Equal(t, fiber.StatusBadRequest, data.Error.Status)
+			messageRepo.AssertNotCalled(t, "MarkAsRead", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestWebSocketRPC_PermissionDenied(t *testing.T) {
+	h, messageRepo := setupRPC(t)
+	client := wsInfra.NewClient("c1", "user_3", nil)
+
+	reply := sendCommand(t, h, client, `{"type":"mark_dm_read","requestId":"req-7","data":{"conversationId":"conv_1"}}`)
+
+	assert.Equal(t, "req-7", reply.RequestID)
+	data := replyData(t, reply)
+	assert.False(t, data.OK)
+	require.NotNil(t, data.Error)
+	assert.Equal(t, "FORBIDDEN", data.Error.Code)
+	assert.Equal(t, fiber.StatusForbidden, data.Error.Status)
+	messageRepo.AssertNotCalled(t, "MarkAsRead", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/domain/live"
+	"pink/internal/domain/ws"
+	wsInfra "pink/internal/infrastructure/ws"
+)
+
+// stubStreams serves a single stream.
+type stubStreams struct {
+	live.StreamRepository
+	stream *live.Stream
+}
+
+func (s stubStreams) FindByID(ctx context.Context, id string) (*live.Stream, error) {
+	return s.stream, nil
+}
+
+// failingChat fails to save every chat message.
+type failingChat struct {
+	live.StreamMessageRepository
+}
+
+func (failingChat) Create(ctx context.Context, msg *live.ChatMessage) error {
+	return errors.New("db down")
+}
+
+// cooldowns is an in-memory slowmode limiter.
+type cooldowns map[string]bool
+
+func (c cooldowns) Acquire(ctx context.Context, chatID, userID string, interval time.Duration) (time.Duration, error) {
+	if c[chatID+":"+userID] {
+		return interval, nil
+	}
+	c[chatID+":"+userID] = true
+	return 0, nil
+}
+
+func (c cooldowns) Release(ctx context.Context, chatID, userID string) error {
+	delete(c, chatID+":"+userID)
+	return nil
+}
+
+func TestWebSocketHandler_StreamChat_ReleasesCooldownWhenSaveFails(t *testing.T) {
+	limiter := cooldowns{}
+	h := &WebSocketHandler{streamMsgRepo: failingChat{}}
+	h.SetStreamSlowmode(stubStreams{stream: &live.Stream{ID: "stream_1", UserID: "streamer", SlowmodeSeconds: 30}}, limiter, nil)
+
+	client := wsInfra.NewClient("c1", "viewer", nil)
+	client.Subscribe(ws.Subscription{Type: ws.SubStream, ID: "stream_1"}.Key())
+
+	data, err := json.Marshal(map[string]string{"streamId": "stream_1", "content": "hi"})
+	require.NoError(t, err)
+	h.handleStreamChatMessage(client, ws.Message{Type: ws.EventStreamChatMsg, Data: data})
+
+	assert.Empty(t, limiter, "a message that was not saved does not start a cooldown")
+	assert.Empty(t, client.Send, "the viewer is not told to wait")
+}
@@ -4,167 +4,389 @@ package middleware
 import (
 	"errors"
 	"log/slog"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 
 	"pink/internal/adapters/http/dto"
 	"pink/internal/domain/channel"
 	"pink/internal/domain/dm"
+	"pink/internal/domain/history"
+	"pink/internal/domain/media"
 	"pink/internal/domain/post"
+	"pink/internal/domain/reaction"
 	"pink/internal/domain/server"
+	"pink/internal/domain/slowmode"
 	"pink/internal/domain/user"
 )
 
 // HandleDomainError maps domain errors to HTTP responses.
 // Use this function to centralize error handling across all handlers.
 func HandleDomainError(c *fiber.Ctx, err error) error {
+	status, resp := DomainError(err)
+	if retryAfter, ok := resp.Error.Details["retryAfter"]; ok {
+		c.Set("Retry-After", retryAfter)
+	}
+	return c.Status(status).JSON(resp)
+}
+
+// DomainError maps a domain error to an HTTP status and error body. It is
+// shared by HTTP handlers and WebSocket command replies.
+func DomainError(err error) (int, dto.ErrorResponse) {
 	// Server domain errors
 	switch {
 	case errors.Is(err, server.ErrNotFound):
-		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
+		return fiber.StatusNotFound, dto.NewErrorResponse(
 			"NOT_FOUND",
 			"Server not found",
-		))
+		)
 	case errors.Is(err, server.ErrNotMember):
-		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
+		return fiber.StatusForbidden, dto.NewErrorResponse(
 			"FORBIDDEN",
 			"Not a member of this server",
-		))
+		)
 	case errors.Is(err, server.ErrNoPermission):
-		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
+		return fiber.StatusForbidden, dto.NewErrorResponse(
 			"FORBIDDEN",
 			"No permission to perform this action",
-		))
+		)
 	case errors.Is(err, server.ErrAlreadyMember):
-		return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse(
+		return fiber.StatusConflict, dto.NewErrorResponse(
 			"ALREADY_MEMBER",
 			"Already a member of this server",
-		))
+		)
 	case errors.Is(err, server.ErrOwnerCannotLeave):
-		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
 			"OWNER_CANNOT_LEAVE",
 			"Server owner cannot leave the server",
-		))
+		)
 	case errors.Is(err, server.ErrRoleNotFound):
-		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
+		return fiber.StatusNotFound, dto.NewErrorResponse(
 			"NOT_FOUND",
 			"Role not found",
-		))
+		)
+	case errors.Is(err, server.ErrChannelNotFound):
+		return fiber.StatusNotFound, dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Channel not found",
+		)
+	case errors.Is(err, server.ErrInviteNotFound):
+		return fiber.StatusNotFound, dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Invite not found",
+		)
+	case errors.Is(err, server.ErrInviteExpired):
+		return fiber.StatusGone, dto.NewErrorResponse(
+			"INVITE_EXPIRED",
+			"Invite has expired or reached its maximum uses",
+		)
+	case errors.Is(err, server.ErrInvalidNotificationSettings):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_NOTIFICATION_SETTINGS",
+			"Level must be all, mentions or nothing",
+		)
+	case errors.Is(err, server.ErrInvalidInvite):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_INVITE",
+			"Max uses must be between 0 and 100 and max age at most 7 days",
+		)
+	case errors.Is(err, server.ErrBanned):
+		return fiber.StatusForbidden, dto.NewErrorResponse(
+			"BANNED",
+			"You are banned from this server",
+		)
+	case errors.Is(err, server.ErrRoleHierarchy):
+		return fiber.StatusForbidden, dto.NewErrorResponse(
+			"ROLE_HIERARCHY",
+			"You can only act on members and roles below your highest role",
+		)
+	case errors.Is(err, server.ErrInvalidAuditLogQuery):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_QUERY",
+			"Invalid audit log filter or cursor",
+		)
 
 	// Channel domain errors
 	case errors.Is(err, channel.ErrNotFound):
-		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
+		return fiber.StatusNotFound, dto.NewErrorResponse(
 			"NOT_FOUND",
 			"Channel not found",
-		))
+		)
 	case errors.Is(err, channel.ErrNoPermission):
-		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
+		return fiber.StatusForbidden, dto.NewErrorResponse(
 			"FORBIDDEN",
 			"No permission to access channel",
-		))
+		)
 	case errors.Is(err, channel.ErrMessageNotFound):
-		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
+		return fiber.StatusNotFound, dto.NewErrorResponse(
 			"NOT_FOUND",
 			"Message not found",
-		))
+		)
 	case errors.Is(err, channel.ErrInvalidContent):
-		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
 			"INVALID_CONTENT",
 			"Message content must be between 1 and 2000 characters",
-		))
+		)
 	case errors.Is(err, channel.ErrInvalidType):
-		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
 			"INVALID_TYPE",
 			"Invalid channel type",
-		))
+		)
 	case errors.Is(err, channel.ErrChannelFull):
-		return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse(
+		return fiber.StatusConflict, dto.NewErrorResponse(
 			"CHANNEL_FULL",
 			"Channel is full",
-		))
+		)
+	case errors.Is(err, channel.ErrOverwriteNotFound):
+		return fiber.StatusNotFound, dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Permission overwrite not found",
+		)
+	case errors.Is(err, channel.ErrInvalidOverwrite):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_OVERWRITE",
+			"Overwrite must use known permissions and not both allow and deny the same one",
+		)
+	case errors.Is(err, channel.ErrThreadNotFound):
+		return fiber.StatusNotFound, dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Thread not found",
+		)
+	case errors.Is(err, channel.ErrThreadExists):
+		return fiber.StatusConflict, dto.NewErrorResponse(
+			"THREAD_EXISTS",
+			"A thread was already started from this message",
+		)
+	case errors.Is(err, channel.ErrThreadLocked):
+		return fiber.StatusForbidden, dto.NewErrorResponse(
+			"THREAD_LOCKED",
+			"Thread is locked",
+		)
+	case errors.Is(err, channel.ErrInvalidThread):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_THREAD",
+			"Invalid thread name, auto-archive duration, starting message or cursor",
+		)
+	case errors.Is(err, channel.ErrNotThreadMember):
+		return fiber.StatusNotFound, dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Not a member of this thread",
+		)
+
+	case errors.Is(err, channel.ErrTooManyPins):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"TOO_MANY_PINS",
+			"Channel has reached the maximum number of pinned messages",
+		)
+
+	case errors.Is(err, channel.ErrInvalidBulkDelete):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_BULK_DELETE",
+			"Limit must be between 1 and 500 and after must be before before",
+		)
+
+	case errors.Is(err, history.ErrInvalidCursor):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_CURSOR",
+			"Only one of before, after and around can be given",
+		)
+
+	case errors.Is(err, channel.ErrInvalidSearch):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_SEARCH",
+			"Search must have words or filters and valid has:, before:, after: and pinned: values",
+		)
+
+	case errors.Is(err, channel.ErrScheduledNotFound):
+		return fiber.StatusNotFound, dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Scheduled message not found",
+		)
+	case errors.Is(err, channel.ErrScheduledPublished):
+		return fiber.StatusConflict, dto.NewErrorResponse(
+			"SCHEDULED_PUBLISHING",
+			"Scheduled message is already being published",
+		)
+	case errors.Is(err, channel.ErrInvalidSchedule):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_SCHEDULE",
+			"Publish time must be in the future and at most 90 days away",
+		)
+	case errors.Is(err, channel.ErrTooManyScheduled):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"TOO_MANY_SCHEDULED",
+			"Channel has reached the maximum number of scheduled messages",
+		)
+
+	case errors.Is(err, channel.ErrFollowNotFound):
+		return fiber.StatusNotFound, dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Channel follow not found",
+		)
+	case errors.Is(err, channel.ErrAlreadyFollowing):
+		return fiber.StatusConflict, dto.NewErrorResponse(
+			"ALREADY_FOLLOWING",
+			"Channel already follows this announcement channel",
+		)
+	case errors.Is(err, channel.ErrTooManyFollows):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"TOO_MANY_FOLLOWS",
+			"Channel has reached the maximum number of followed announcement channels",
+		)
+	case errors.Is(err, channel.ErrAlreadyCrossposted):
+		return fiber.StatusConflict, dto.NewErrorResponse(
+			"ALREADY_PUBLISHED",
+			"Message has already been published",
+		)
+	case errors.Is(err, channel.ErrCrosspostLimited):
+		resp := dto.NewErrorResponse(
+			"PUBLISH_RATE_LIMITED",
+			"Channel has published too many announcements; try again later",
+		)
+		var limited *channel.CrosspostLimitError
+		if errors.As(err, &limited) {
+			resp.Error.Details = map[string]string{"retryAfter": strconv.Itoa(limited.RetryAfterSeconds())}
+		}
+		return fiber.StatusTooManyRequests, resp
+
+	// Reaction domain errors
+	case errors.Is(err, reaction.ErrInvalidEmoji):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_EMOJI",
+			"Invalid emoji",
+		)
+	case errors.Is(err, reaction.ErrTooManyReactions):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"TOO_MANY_REACTIONS",
+			"Message has reached the maximum number of different reactions",
+		)
+	case errors.Is(err, reaction.ErrInvalidCursor):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_CURSOR",
+			"Invalid cursor",
+		)
+
+	// Media domain errors
+	case errors.Is(err, media.ErrNotFound):
+		return fiber.StatusNotFound, dto.NewErrorResponse(
+			"NOT_FOUND",
+			"Media not found",
+		)
+	case errors.Is(err, media.ErrNoPermission):
+		return fiber.StatusForbidden, dto.NewErrorResponse(
+			"FORBIDDEN",
+			"Only your own uploads can be attached",
+		)
+	case errors.Is(err, media.ErrAlreadyAttached):
+		return fiber.StatusConflict, dto.NewErrorResponse(
+			"ALREADY_ATTACHED",
+			"Media is already attached to a message",
+		)
+	case errors.Is(err, media.ErrTooManyAttachments):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"TOO_MANY_ATTACHMENTS",
+			"Message has too many attachments",
+		)
 
 	// User domain errors
 	case errors.Is(err, user.ErrNotFound):
-		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
+		return fiber.StatusNotFound, dto.NewErrorResponse(
 			"NOT_FOUND",
 			"User not found",
-		))
+		)
 	case errors.Is(err, user.ErrEmailAlreadyExists):
-		return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse(
+		return fiber.StatusConflict, dto.NewErrorResponse(
 			"EMAIL_EXISTS",
 			"Email already in use",
-		))
+		)
 	case errors.Is(err, user.ErrHandleAlreadyExists):
-		return c.Status(fiber.StatusConflict).JSON(dto.NewErrorResponse(
+		return fiber.StatusConflict, dto.NewErrorResponse(
 			"HANDLE_EXISTS",
 			"Handle already in use",
-		))
+		)
 	case errors.Is(err, user.ErrInvalidPassword):
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse(
+		return fiber.StatusUnauthorized, dto.NewErrorResponse(
 			"INVALID_CREDENTIALS",
 			"Invalid email or password",
-		))
+		)
 	case errors.Is(err, user.ErrUnauthorized):
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse(
+		return fiber.StatusUnauthorized, dto.NewErrorResponse(
 			"UNAUTHORIZED",
 			"Account is inactive or unauthorized",
-		))
+		)
 	case errors.Is(err, user.ErrSessionExpired):
-		return c.Status(fiber.StatusUnauthorized).JSON(dto.NewErrorResponse(
+		return fiber.StatusUnauthorized, dto.NewErrorResponse(
 			"SESSION_EXPIRED",
 			"Your session has expired, please login again",
-		))
+		)
 
 	// DM domain errors
 	case errors.Is(err, dm.ErrConversationNotFound):
-		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
+		return fiber.StatusNotFound, dto.NewErrorResponse(
 			"NOT_FOUND",
 			"Conversation not found",
-		))
+		)
 	case errors.Is(err, dm.ErrNotParticipant):
-		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
+		return fiber.StatusForbidden, dto.NewErrorResponse(
 			"FORBIDDEN",
 			"Not a participant in this conversation",
-		))
+		)
 	case errors.Is(err, dm.ErrCannotMessageSelf):
-		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
 			"CANNOT_MESSAGE_SELF",
 			"Cannot start a conversation with yourself",
-		))
+		)
 	case errors.Is(err, dm.ErrNoPermission):
-		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
+		return fiber.StatusForbidden, dto.NewErrorResponse(
 			"FORBIDDEN",
 			"No permission to perform this action",
-		))
+		)
 	case errors.Is(err, dm.ErrInvalidContent):
-		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
 			"INVALID_CONTENT",
 			"Invalid message content",
-		))
+		)
+
+	// Slowmode errors
+	case errors.Is(err, slowmode.ErrCooldown):
+		resp := dto.NewErrorResponse(
+			"SLOWMODE",
+			"Slowmode is enabled; wait before sending another message",
+		)
+		var cooldown *slowmode.CooldownError
+		if errors.As(err, &cooldown) {
+			resp.Error.Details = map[string]string{"retryAfter": strconv.Itoa(cooldown.RetryAfterSeconds())}
+		}
+		return fiber.StatusTooManyRequests, resp
+	case errors.Is(err, slowmode.ErrInvalidInterval):
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
+			"INVALID_SLOWMODE",
+			"Slowmode must be between 0 and 21600 seconds",
+		)
 
 	// Post/Feed domain errors
 	case errors.Is(err, post.ErrNotFound):
-		return c.Status(fiber.StatusNotFound).JSON(dto.NewErrorResponse(
+		return fiber.StatusNotFound, dto.NewErrorResponse(
 			"NOT_FOUND",
 			"Post not found",
-		))
+		)
 	case errors.Is(err, post.ErrNoPermission):
-		return c.Status(fiber.StatusForbidden).JSON(dto.NewErrorResponse(
+		return fiber.StatusForbidden, dto.NewErrorResponse(
 			"FORBIDDEN",
 			"No permission to modify this post",
-		))
+		)
 	case errors.Is(err, post.ErrInvalidContent):
-		return c.Status(fiber.StatusBadRequest).JSON(dto.NewErrorResponse(
+		return fiber.StatusBadRequest, dto.NewErrorResponse(
 			"INVALID_CONTENT",
 			"Invalid post content",
-		))
+		)
 
 	// Default: internal server error
 	default:
 		slog.Error("unhandled domain error", slog.Any("error", err))
-		return c.Status(fiber.StatusInternalServerError).JSON(dto.NewErrorResponse(
+		return fiber.StatusInternalServerError, dto.NewErrorResponse(
 			"INTERNAL_ERROR",
 			"An internal error occurred",
-		))
+		)
 	}
 }
@@ -15,9 +15,13 @@ type Config struct {
 	AuthHandler           *handlers.AuthHandler
 	UserHandler           *handlers.UserHandler
 	ServerHandler         *handlers.ServerHandler
+	InviteHandler         *handlers.InviteHandler
 	ServerWallHandler     *handlers.ServerWallHandler
 	ChannelHandler        *handlers.ChannelHandler
 	ChannelMessageHandler *handlers.ChannelMessageHandler
+	ThreadHandler         *handlers.ThreadHandler
+	ScheduledHandler      *handlers.ScheduledMessageHandler
+	ChannelFollowHandler  *handlers.ChannelFollowHandler
 	FeedHandler           *handlers.FeedHandler
 	DMHandler             *handlers.DMHandler
 	LiveHandler           *handlers.LiveHandler
@@ -52,6 +56,7 @@ func Setup(app *fiber.App, cfg *Config) {
 	// Health endpoints (no auth)
 	app.Get("/health", cfg.HealthHandler.Health)
 	app.Get("/ready", cfg.HealthHandler.Ready)
+	app.Get("/ready/ws", cfg.HealthHandler.WebSocketStats)
 
 	// API v1
 	api := app.Group("/api/v1")
@@ -63,6 +68,9 @@ func Setup(app *fiber.App, cfg *Config) {
 	auth.Post("/refresh", cfg.AuthHandler.Refresh)
 	auth.Post("/logout", cfg.AuthHandler.Logout)
 
+	// Invite preview (public, registered before the auth middleware)
+	api.Get("/invites/:code", cfg.InviteHandler.Preview)
+
 	// Protected routes
 	protected := api.Group("", cfg.AuthMiddleware.Authenticate())
 	// Allow getting WS token if authenticated
@@ -116,15 +124,25 @@ func Setup(app *fiber.App, cfg *Config) {
 	servers.Post("/:id/leave", cfg.ServerHandler.Leave)
 	servers.Get("/:id/members", cfg.ServerHandler.ListMembers)
 
+	// Invite routes
+	servers.Get("/:id/invites", cfg.InviteHandler.List)
+	servers.Post("/:id/invites", cfg.InviteHandler.Create)
+	servers.Delete("/:id/invites/:code", cfg.InviteHandler.Revoke)
+	protected.Post("/invites/:code", cfg.InviteHandler.Redeem)
+
 	// Moderation routes
 	servers.Get("/:id/bans", cfg.ServerHandler.GetBans)
 	servers.Post("/:id/bans", cfg.ServerHandler.Ban)
 	servers.Delete("/:id/bans/:userId", cfg.ServerHandler.Unban)
 	servers.Post("/:id/members/:userId/timeout", cfg.ServerHandler.Timeout)
 	servers.Delete("/:id/members/:userId/timeout", cfg.ServerHandler.RemoveTimeout)
+	servers.Get("/:id/audit-logs", cfg.ServerHandler.ListAuditLogs)
+	servers.Get("/:id/notification-settings", cfg.ServerHandler.GetNotificationSettings)
+	servers.Put("/:id/notification-settings", cfg.ServerHandler.UpdateNotificationSettings)
 
 	servers.Get("/:id/roles", cfg.ServerHandler.ListRoles)
 	servers.Post("/:id/roles", cfg.ServerHandler.CreateRole)
+	servers.Patch("/:id/roles/reorder", cfg.ServerHandler.ReorderRoles)
 	servers.Patch("/:id/roles/:roleId", cfg.ServerHandler.UpdateRole)
 	servers.Delete("/:id/roles/:roleId", cfg.ServerHandler.DeleteRole)
 	servers.Put("/:id/members/:userId/roles", cfg.ServerHandler.UpdateMemberRoles)
@@ -140,16 +158,61 @@ func Setup(app *fiber.App, cfg *Config) {
 	servers.Get("/:id/channels", cfg.ChannelHandler.List)
 	servers.Post("/:id/channels", cfg.ChannelHandler.Create)
 	servers.Patch("/:id/channels/reorder", cfg.ChannelHandler.Reorder)
+	servers.Get("/:id/read-states", cfg.ChannelHandler.ReadStates)
 	servers.Patch("/:id/channels/:chId", cfg.ChannelHandler.Update)
 	servers.Delete("/:id/channels/:chId", cfg.ChannelHandler.Delete)
 	servers.Post("/:id/channels/:chId/ack", cfg.ChannelHandler.Ack)
+	servers.Get("/:id/channels/:chId/permissions/:targetType/:targetId", cfg.ChannelHandler.GetOverwrite)
+	servers.Put("/:id/channels/:chId/permissions/:targetType/:targetId", cfg.ChannelHandler.SetOverwrite)
+	servers.Delete("/:id/channels/:chId/permissions/:targetType/:targetId", cfg.ChannelHandler.DeleteOverwrite)
+	servers.Get("/:id/channels/:chId/members/:userId/permissions", cfg.ChannelHandler.ExplainPermissions)
 
 	// Channel message routes
+	servers.Get("/:id/messages/search", cfg.ChannelMessageHandler.SearchServerMessages)
 	servers.Get("/:id/channels/:chId/messages", cfg.ChannelMessageHandler.GetMessages)
 	servers.Post("/:id/channels/:chId/messages", cfg.ChannelMessageHandler.SendMessage)
 	servers.Get("/:id/channels/:chId/messages/search", cfg.ChannelMessageHandler.SearchMessages)
+	servers.Post("/:id/channels/:chId/messages/bulk-delete", cfg.ChannelMessageHandler.BulkDeleteMessages)
 	servers.Patch("/:id/channels/:chId/messages/:msgId", cfg.ChannelMessageHandler.EditMessage)
 	servers.Delete("/:id/channels/:chId/messages/:msgId", cfg.ChannelMessageHandler.DeleteMessage)
+	servers.Get("/:id/channels/:chId/messages/:msgId/revisions", cfg.ChannelMessageHandler.GetRevisions)
+	servers.Get("/:id/channels/:chId/pins", cfg.ChannelMessageHandler.GetPins)
+	servers.Put("/:id/channels/:chId/pins/:msgId", cfg.ChannelMessageHandler.PinMessage)
+	servers.Delete("/:id/channels/:chId/pins/:msgId", cfg.ChannelMessageHandler.UnpinMessage)
+	servers.Get("/:id/channels/:chId/messages/:msgId/reactions/:emoji", cfg.ChannelMessageHandler.ListReactions)
+	servers.Put("/:id/channels/:chId/messages/:msgId/reactions/:emoji/@me", cfg.ChannelMessageHandler.AddReaction)
+	servers.Delete("/:id/channels/:chId/messages/:msgId/reactions/:emoji/@me", cfg.ChannelMessageHandler.RemoveReaction)
+	servers.Delete("/:id/channels/:chId/messages/:msgId/reactions/:emoji/:userId", cfg.ChannelMessageHandler.RemoveUserReaction)
+
+	// Thread routes
+	servers.Get("/:id/channels/:chId/threads", cfg.ThreadHandler.List)
+	servers.Post("/:id/channels/:chId/threads", cfg.ThreadHandler.Create)
+	servers.Get("/:id/channels/:chId/threads/:threadId", cfg.ThreadHandler.Get)
+	servers.Patch("/:id/channels/:chId/threads/:threadId", cfg.ThreadHandler.Update)
+	servers.Delete("/:id/channels/:chId/threads/:threadId", cfg.ThreadHandler.Delete)
+	servers.Get("/:id/channels/:chId/threads/:threadId/messages", cfg.ThreadHandler.GetMessages)
+	servers.Post("/:id/channels/:chId/threads/:threadId/messages", cfg.ThreadHandler.SendMessage)
+	servers.Patch("/:id/channels/:chId/threads/:threadId/messages/:msgId", cfg.ThreadHandler.EditMessage)
+	servers.Delete("/:id/channels/:chId/threads/:threadId/messages/:msgId", cfg.ThreadHandler.DeleteMessage)
+	servers.Get("/:id/channels/:chId/threads/:threadId/messages/:msgId/revisions", cfg.ThreadHandler.GetRevisions)
+	servers.Get("/:id/channels/:chId/threads/:threadId/members", cfg.ThreadHandler.ListMembers)
+	servers.Put("/:id/channels/:chId/threads/:threadId/members/@me", cfg.ThreadHandler.Join)
+	servers.Delete("/:id/channels/:chId/threads/:threadId/members/@me", cfg.ThreadHandler.Leave)
+	servers.Post("/:id/channels/:chId/threads/:threadId/ack", cfg.ThreadHandler.Ack)
+
+	// Scheduled message routes
+	servers.Post("/:id/channels/:chId/scheduled-messages", cfg.ScheduledHandler.Create)
+	servers.Get("/:id/scheduled-messages", cfg.ScheduledHandler.List)
+	servers.Patch("/:id/scheduled-messages/:scheduledId", cfg.ScheduledHandler.Update)
+	servers.Delete("/:id/scheduled-messages/:scheduledId", cfg.ScheduledHandler.Delete)
+
+	// Announcement follow routes
+	servers.Get("/:id/channels/:chId/following", cfg.ChannelFollowHandler.ListFollowing)
+	servers.Post("/:id/channels/:chId/following", cfg.ChannelFollowHandler.Follow)
+	servers.Delete("/:id/channels/:chId/following/:followId", cfg.ChannelFollowHandler.Unfollow)
+	servers.Get("/:id/channels/:chId/followers", cfg.ChannelFollowHandler.ListFollowers)
+	servers.Delete("/:id/channels/:chId/followers/:followId", cfg.ChannelFollowHandler.Unfollow)
+	servers.Post("/:id/channels/:chId/messages/:msgId/crosspost", cfg.ChannelFollowHandler.Crosspost)
 
 	// Feed routes
 	protected.Get("/feed", cfg.FeedHandler.GetFeed)
@@ -175,8 +238,15 @@ func Setup(app *fiber.App, cfg *Config) {
 	dm.Get("/conversations/:id/messages", cfg.DMHandler.GetMessages)
 	dm.Post("/conversations/:id/messages", cfg.DMHandler.SendMessage)
 	dm.Post("/conversations/:id/read", cfg.DMHandler.MarkAsRead)
+	dm.Get("/conversations/:id/pins", cfg.DMHandler.GetPins)
+	dm.Put("/conversations/:id/pins/:msgId", cfg.DMHandler.PinMessage)
+	dm.Delete("/conversations/:id/pins/:msgId", cfg.DMHandler.UnpinMessage)
 	dm.Patch("/messages/:id", cfg.DMHandler.EditMessage)
 	dm.Delete("/messages/:id", cfg.DMHandler.DeleteMessage)
+	dm.Get("/messages/:id/revisions", cfg.DMHandler.GetRevisions)
+	dm.Get("/messages/:id/reactions/:emoji", cfg.DMHandler.ListReactions)
+	dm.Put("/messages/:id/reactions/:emoji/@me", cfg.DMHandler.AddReaction)
+	dm.Delete("/messages/:id/reactions/:emoji/@me", cfg.DMHandler.RemoveReaction)
 
 	// Live streaming routes
 	live := protected.Group("/live")
@@ -0,0 +1,311 @@
+package channel
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
+	"pink/internal/pkg/id"
+)
+
+// FollowService lets servers follow announcement channels into their own
+// text channels and publishes announcements to those followers.
+type FollowService struct {
+	messages *MessageService
+	follows  channel.FollowRepository
+	limiter  channel.CrosspostLimiter
+	events   ws.ChannelEventPublisher
+}
+
+// NewFollowService creates a new FollowService.
+func NewFollowService(messages *MessageService, follows channel.FollowRepository) *FollowService {
+	return &FollowService{
+		messages: messages,
+		follows:  follows,
+	}
+}
+
+// SetCrosspostLimiter enables the publish rate limit. Until it is set,
+// channels can publish without limit.
+func (s *FollowService) SetCrosspostLimiter(limiter channel.CrosspostLimiter) {
+	s.limiter = limiter
+}
+
+// SetEventPublisher sets the publisher that broadcasts copies to the
+// followers' channels.
+func (s *FollowService) SetEventPublisher(events ws.ChannelEventPublisher) {
+	s.events = events
+}
+
+// FollowChannelCommand represents a request to follow an announcement
+// channel into a text channel.
+type FollowChannelCommand struct {
+	ServerID        string // Server of the target channel
+	ChannelID       string // Text channel the announcements are delivered to
+	SourceChannelID string // Announcement channel to follow
+	UserID          string
+}
+
+// Follow follows an announcement channel the user can see into a text
+// channel of their server where they have ManageChannels.
+func (s *FollowService) Follow(ctx context.Context, cmd FollowChannelCommand) (*channel.ChannelFollow, error) {
+	target, err := s.managedChannel(ctx, cmd.ServerID, cmd.ChannelID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Type != channel.TypeText {
+		return nil, channel.ErrInvalidType
+	}
+
+	source, err := s.messages.channelRepo.FindByID(ctx, cmd.SourceChannelID)
+	if err != nil || source == nil {
+		return nil, channel.ErrNotFound
+	}
+	// Announcement channels the user cannot see are not found
+	if err := s.messages.requireMembership(ctx, source.ServerID, cmd.UserID); err != nil {
+		return nil, channel.ErrNotFound
+	}
+	if _, err := s.messages.requireChannelPermission(ctx, source.ID, source.ServerID, cmd.UserID, server.PermissionViewChannel); err != nil {
+		return nil, err
+	}
+	if source.Type != channel.TypeAnnouncement {
+		return nil, channel.ErrInvalidType
+	}
+
+	count, err := s.follows.CountByTarget(ctx, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= channel.MaxFollowsPerChannel {
+		return nil, channel.ErrTooManyFollows
+	}
+
+	follow := &channel.ChannelFollow{
+		ID:              id.Generate("cfol"),
+		SourceServerID:  source.ServerID,
+		SourceChannelID: source.ID,
+		TargetServerID:  target.ServerID,
+		TargetChannelID: target.ID,
+		CreatedBy:       &cmd.UserID,
+		CreatedAt:       time.Now(),
+	}
+	if err := s.follows.Create(ctx, follow); err != nil {
+		return nil, err
+	}
+
+	s.messages.audit(ctx, target.ServerID, cmd.UserID, follow.ID, server.AuditLogActionFollowCreate, server.AuditChanges{
+		"channel_id":        target.ID,
+		"source_channel_id": source.ID,
+		"source_server_id":  source.ServerID,
+	})
+
+	// Return the follow with its names
+	return s.follows.FindByID(ctx, follow.ID)
+}
+
+// ListFollowers lists the channels following an announcement channel. It
+// requires ManageChannels in the announcement channel.
+func (s *FollowService) ListFollowers(ctx context.Context, serverID, channelID, userID string) ([]*channel.ChannelFollow, error) {
+	ch, err := s.managedChannel(ctx, serverID, channelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.follows.FindBySource(ctx, ch.ID)
+}
+
+// ListFollowing lists the announcement channels a channel follows. It
+// requires ManageChannels in the channel.
+func (s *FollowService) ListFollowing(ctx context.Context, serverID, channelID, userID string) ([]*channel.ChannelFollow, error) {
+	ch, err := s.managedChannel(ctx, serverID, channelID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.follows.FindByTarget(ctx, ch.ID)
+}
+
+// Unfollow removes a follow from either side: the announcement channel or
+// the channel it is delivered to. The user needs ManageChannels in the
+// channel of their side.
+func (s *FollowService) Unfollow(ctx context.Context, serverID, channelID, followID, userID string) error {
+	follow, err := s.follows.FindByID(ctx, followID)
+	if err != nil {
+		return err
+	}
+	isSource := follow.SourceServerID == serverID && follow.SourceChannelID == channelID
+	isTarget := follow.TargetServerID == serverID && follow.TargetChannelID == channelID
+	if !isSource && !isTarget {
+		return channel.ErrFollowNotFound
+	}
+
+	if _, err := s.managedChannel(ctx, serverID, channelID, userID); err != nil {
+		return err
+	}
+
+	if err := s.follows.Delete(ctx, follow.ID); err != nil {
+		return err
+	}
+
+	s.messages.audit(ctx, serverID, userID, follow.ID, server.AuditLogActionFollowDelete, server.AuditChanges{
+		"source_channel_id": follow.SourceChannelID,
+		"source_server_id":  follow.SourceServerID,
+		"target_channel_id": follow.TargetChannelID,
+		"target_server_id":  follow.TargetServerID,
+	})
+	return nil
+}
+
+// Crosspost publishes a message of an announcement channel to the channels
+// following it. Authors who can send in the channel publish their own
+// messages; other messages need ManageMessages as well. A message is
+// published once, and a channel publishes at most MaxCrossposts messages per
+// CrosspostWindow. Copies are delivered in the background.
+func (s *FollowService) Crosspost(ctx context.Context, serverID, channelID, messageID, userID string) (*channel.ChannelMessage, error) {
+	if err := s.messages.requireMembership(ctx, serverID, userID); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.messages.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.ServerID != serverID || msg.ChannelID != channelID || msg.ThreadID != nil {
+		return nil, channel.ErrMessageNotFound
+	}
+
+	ch, err := s.messages.channelRepo.FindByID(ctx, channelID)
+	if err != nil || ch == nil || ch.ServerID != serverID {
+		return nil, channel.ErrNotFound
+	}
+	if ch.Type != channel.TypeAnnouncement || msg.IsCrosspost() {
+		return nil, channel.ErrInvalidType
+	}
+
+	perms, err := s.messages.canSendMessage(ctx, ch, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	if msg.AuthorID != userID && !perms.Has(server.PermissionManageMessages) {
+		return nil, channel.ErrNoPermission
+	}
+
+	if msg.CrosspostedAt != nil {
+		return nil, channel.ErrAlreadyCrossposted
+	}
+	if err := s.acquire(ctx, ch.ID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	marked, err := s.messages.messageRepo.MarkCrossposted(ctx, msg.ID, now)
+	if err != nil || !marked {
+		// Lost a race with another publish, which counts on its own
+		s.release(ctx, ch.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, channel.ErrAlreadyCrossposted
+	}
+	msg.CrosspostedAt = &now
+
+	go s.deliver(context.Background(), msg)
+
+	return msg, nil
+}
+
+// acquire counts a publish from the channel, or returns a
+// CrosspostLimitError once it has published too many. Limiter failures let
+// the publish through, like slowmode.
+func (s *FollowService) acquire(ctx context.Context, channelID string) error {
+	if s.limiter == nil {
+		return nil
+	}
+	retryAfter, err := s.limiter.Acquire(ctx, channelID, channel.MaxCrossposts, channel.CrosspostWindow)
+	if err != nil {
+		slog.Warn("failed to check crosspost limit", slog.Any("error", err), slog.String("channel_id", channelID))
+		return nil
+	}
+	if retryAfter > 0 {
+		return &channel.CrosspostLimitError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// release gives back a publish counted by acquire that did not happen.
+func (s *FollowService) release(ctx context.Context, channelID string) {
+	if s.limiter == nil {
+		return
+	}
+	if err := s.limiter.Release(ctx, channelID); err != nil {
+		slog.Warn("failed to release crosspost limit", slog.Any("error", err), slog.String("channel_id", channelID))
+	}
+}
+
+// deliver copies a published message into every channel following its
+// channel. Failures are logged per follower: the message is published
+// either way.
+func (s *FollowService) deliver(ctx context.Context, msg *channel.ChannelMessage) {
+	follows, err := s.follows.FindBySource(ctx, msg.ChannelID)
+	if err != nil {
+		slog.Error("failed to load channel followers", slog.Any("error", err), slog.String("message_id", msg.ID))
+		return
+	}
+
+	for _, follow := range follows {
+		copied := crosspostCopy(msg, follow, time.Now())
+		if err := s.messages.messageRepo.Create(ctx, copied); err != nil {
+			slog.Warn("failed to deliver crosspost",
+				slog.Any("error", err),
+				slog.String("message_id", msg.ID),
+				slog.String("follow_id", follow.ID),
+			)
+			continue
+		}
+
+		if s.events != nil {
+			s.events.PublishChannelEvent(ws.ChannelEvent{
+				Type:      ws.EventChannelMessage,
+				ServerID:  copied.ServerID,
+				ChannelID: copied.ChannelID,
+				ActorID:   copied.AuthorID,
+				Payload:   copied,
+			})
+		}
+	}
+}
+
+// crosspostCopy builds the copy of a published message delivered through a
+// follow. It keeps the author and attachments but not mentions, which
+// belong to the source server.
+func crosspostCopy(msg *channel.ChannelMessage, follow *channel.ChannelFollow, now time.Time) *channel.ChannelMessage {
+	return &channel.ChannelMessage{
+		ID:        id.Generate("cmsg"),
+		ChannelID: follow.TargetChannelID,
+		ServerID:  follow.TargetServerID,
+		AuthorID:  msg.AuthorID,
+		Content:   msg.Content,
+		CreatedAt: now,
+		UpdatedAt: now,
+
+		Attachments: msg.Attachments,
+		Source: &channel.CrosspostSource{
+			MessageID:   msg.ID,
+			ChannelID:   follow.SourceChannelID,
+			ServerID:    follow.SourceServerID,
+			ChannelName: follow.SourceChannelName,
+			ServerName:  follow.SourceServerName,
+		},
+		Author: msg.Author,
+	}
+}
+
+// managedChannel loads a channel of the server that the user has
+// ManageChannels in.
+func (s *FollowService) managedChannel(ctx context.Context, serverID, channelID, userID string) (*channel.Channel, error) {
+	if err := s.messages.requireMembership(ctx, serverID, userID); err != nil {
+		return nil, err
+	}
+	return s.messages.requireChannelPermission(ctx, channelID, serverID, userID, server.PermissionViewChannel|server.PermissionManageChannels)
+}
@@ -0,0 +1,309 @@
+package channel
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/testutil"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
+)
+
+type followMocks struct {
+	*threadMocks
+	follows *testutil.MockChannelFollowRepository
+	limiter *testutil.MockCrosspostLimiter
+}
+
+var partnerEveryone = server.Role{ID: "role_everyone_2", ServerID: "serv_2", IsDefault: true, Permissions: server.PermissionDefaultEveryone}
+
+// setupFollow creates a follow service over the thread service's mocks, with
+// announcement channel chan_news in serv_2 next to text channel chan_1 in
+// serv_1.
+func setupFollow(t *testing.T) (*FollowService, *followMocks) {
+	threads, tm := setupThreadService(t)
+	m := &followMocks{
+		threadMocks: tm,
+		follows:     new(testutil.MockChannelFollowRepository),
+		limiter:     new(testutil.MockCrosspostLimiter),
+	}
+
+	svc := NewFollowService(threads.messages, m.follows)
+	svc.SetCrosspostLimiter(m.limiter)
+	svc.SetEventPublisher(m.events)
+
+	m.channelRepo.On("FindByID", mock.Anything, "chan_news").Return(&channel.Channel{ID: "chan_news", ServerID: "serv_2", Type: channel.TypeAnnouncement}, nil)
+	m.auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	return svc, m
+}
+
+// asPartnerMember makes userID a member of serv_2 with the given roles.
+func (m *followMocks) asPartnerMember(ctx context.Context, userID string, roles ...server.Role) {
+	m.serverRepo.On("FindByID", ctx, "serv_2").Return(&server.Server{ID: "serv_2", OwnerID: "user_owner_2"}, nil)
+	m.memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_2", userID).
+		Return(&server.Member{ID: "memb2_" + userID, ServerID: "serv_2", UserID: userID, Roles: roles}, nil)
+	m.memberRepo.On("FindByServerAndUser", ctx, "serv_2", userID).
+		Return(&server.Member{ID: "memb2_" + userID, ServerID: "serv_2", UserID: userID}, nil)
+}
+
+// withAnnouncement stores a message by user_1 in chan_news.
+func (m *followMocks) withAnnouncement(ctx context.Context, msg channel.ChannelMessage) *channel.ChannelMessage {
+	msg.ServerID = "serv_2"
+	msg.ChannelID = "chan_news"
+	if msg.AuthorID == "" {
+		msg.AuthorID = "user_1"
+	}
+	m.messageRepo.On("FindByID", ctx, msg.ID).Return(&msg, nil)
+	return &msg
+}
+
+var partnerManager = server.Role{ID: "role_manager_2", ServerID: "serv_2", Position: 1, Permissions: server.PermissionManageChannels}
+
+func TestFollowService_Follow(t *testing.T) {
+	svc, m := setupFollow(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone, channelManager)
+	m.asPartnerMember(ctx, "user_1", partnerEveryone)
+	m.follows.On("CountByTarget", ctx, "chan_1").Return(2, nil)
+	m.follows.On("Create", ctx, mock.MatchedBy(func(f *channel.ChannelFollow) bool {
+		return strings.HasPrefix(f.ID, "cfol_") && f.SourceServerID == "serv_2" && f.SourceChannelID == "chan_news" &&
+			f.TargetServerID == "serv_1" && f.TargetChannelID == "chan_1" && *f.CreatedBy == "user_1"
+	})).Return(nil)
+	m.follows.On("FindByID", ctx, mock.Anything).Return(&channel.ChannelFollow{ID: "cfol_1", SourceChannelName: "news"}, nil)
+
+	follow, err := svc.Follow(ctx, FollowChannelCommand{ServerID: "serv_1", ChannelID: "chan_1", SourceChannelID: "chan_news", UserID: "user_1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "news", follow.SourceChannelName)
+	m.follows.AssertExpectations(t)
+	m.auditRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(l *server.AuditLog) bool {
+		return l.ServerID == "serv_1" && l.ActionType == server.AuditLogActionFollowCreate
+	}))
+}
+
+func TestFollowService_Follow_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		roles    []server.Role
+		target   string
+		source   string
+		inSource bool
+		count    int
+		wantErr  error
+	}{
+		{"without manage channels", []server.Role{everyone}, "chan_1", "chan_news", true, 0, channel.ErrNoPermission},
+		{"into an announcement channel", []server.Role{everyone, channelManager}, "chan_ann", "chan_news", true, 0, channel.ErrInvalidType},
+		{"a text channel", []server.Role{everyone, channelManager}, "chan_1", "chan_1", true, 0, channel.ErrInvalidType},
+		{"an unseen announcement channel", []server.Role{everyone, channelManager}, "chan_1", "chan_news", false, 0, channel.ErrNotFound},
+		{"too many follows", []server.Role{everyone, channelManager}, "chan_1", "chan_news", true, channel.MaxFollowsPerChannel, channel.ErrTooManyFollows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupFollow(t)
+			ctx := context.Background()
+
+			m.channelRepo.On("FindByID", mock.Anything, "chan_ann").Return(&channel.Channel{ID: "chan_ann", ServerID: "serv_1", Type: channel.TypeAnnouncement}, nil)
+			m.asThreadMember(ctx, "user_1", tt.roles...)
+			if tt.inSource {
+				m.asPartnerMember(ctx, "user_1", partnerEveryone)
+			} else {
+				m.memberRepo.On("FindByServerAndUser", ctx, "serv_2", "user_1").Return(nil, server.ErrNotMember)
+			}
+			m.follows.On("CountByTarget", ctx, mock.Anything).Return(tt.count, nil)
+
+			_, err := svc.Follow(ctx, FollowChannelCommand{ServerID: "serv_1", ChannelID: tt.target, SourceChannelID: tt.source, UserID: "user_1"})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			m.follows.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestFollowService_Unfollow_FromEitherSide(t *testing.T) {
+	follow := &channel.ChannelFollow{
+		ID: "cfol_1", SourceServerID: "serv_2", SourceChannelID: "chan_news", TargetServerID: "serv_1", TargetChannelID: "chan_1",
+	}
+
+	t.Run("follower", func(t *testing.T) {
+		svc, m := setupFollow(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_1", everyone, channelManager)
+		m.follows.On("FindByID", ctx, "cfol_1").Return(follow, nil)
+		m.follows.On("Delete", ctx, "cfol_1").Return(nil)
+
+		require.NoError(t, svc.Unfollow(ctx, "serv_1", "chan_1", "cfol_1", "user_1"))
+		m.follows.AssertExpectations(t)
+	})
+
+	t.Run("announcer", func(t *testing.T) {
+		svc, m := setupFollow(t)
+		ctx := context.Background()
+
+		m.asPartnerMember(ctx, "user_2", partnerEveryone, partnerManager)
+		m.follows.On("FindByID", ctx, "cfol_1").Return(follow, nil)
+		m.follows.On("Delete", ctx, "cfol_1").Return(nil)
+
+		require.NoError(t, svc.Unfollow(ctx, "serv_2", "chan_news", "cfol_1", "user_2"))
+		m.auditRepo.AssertCalled(t, "Create", ctx, mock.MatchedBy(func(l *server.AuditLog) bool {
+			return l.ServerID == "serv_2" && l.ActionType == server.AuditLogActionFollowDelete
+		}))
+	})
+
+	t.Run("another channel", func(t *testing.T) {
+		svc, m := setupFollow(t)
+		ctx := context.Background()
+
+		m.follows.On("FindByID", ctx, "cfol_1").Return(follow, nil)
+
+		err := svc.Unfollow(ctx, "serv_1", "chan_other", "cfol_1", "user_1")
+		assert.ErrorIs(t, err, channel.ErrFollowNotFound)
+		m.follows.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("without manage channels", func(t *testing.T) {
+		svc, m := setupFollow(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_1", everyone)
+		m.follows.On("FindByID", ctx, "cfol_1").Return(follow, nil)
+
+		err := svc.Unfollow(ctx, "serv_1", "chan_1", "cfol_1", "user_1")
+		assert.ErrorIs(t, err, channel.ErrNoPermission)
+		m.follows.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFollowService_Crosspost(t *testing.T) {
+	svc, m := setupFollow(t)
+	ctx := context.Background()
+
+	m.asPartnerMember(ctx, "user_1", partnerEveryone, partnerManager)
+	msg := m.withAnnouncement(ctx, channel.ChannelMessage{ID: "cmsg_1", Content: "v2 is out"})
+	m.limiter.On("Acquire", ctx, "chan_news", channel.MaxCrossposts, channel.CrosspostWindow).Return(time.Duration(0), nil)
+	m.messageRepo.On("MarkCrossposted", ctx, "cmsg_1", mock.Anything).Return(true, nil)
+
+	// Delivery happens in the background
+	delivered := make(chan struct{})
+	m.follows.On("FindBySource", mock.Anything, "chan_news").Return([]*channel.ChannelFollow{}, nil).
+		Run(func(mock.Arguments) { close(delivered) })
+
+	published, err := svc.Crosspost(ctx, "serv_2", "chan_news", msg.ID, "user_1")
+
+	require.NoError(t, err)
+	assert.NotNil(t, published.CrosspostedAt)
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Fatal("announcement was not delivered")
+	}
+}
+
+func TestFollowService_Crosspost_Rejected(t *testing.T) {
+	published := time.Now()
+	source := &channel.CrosspostSource{MessageID: "cmsg_0"}
+
+	tests := []struct {
+		name    string
+		msg     channel.ChannelMessage
+		roles   []server.Role
+		wantErr error
+	}{
+		{"without manage channels", channel.ChannelMessage{ID: "cmsg_1"}, []server.Role{partnerEveryone}, channel.ErrNoPermission},
+		{"someone else's message", channel.ChannelMessage{ID: "cmsg_1", AuthorID: "user_2"}, []server.Role{partnerEveryone, partnerManager}, channel.ErrNoPermission},
+		{"already published", channel.ChannelMessage{ID: "cmsg_1", CrosspostedAt: &published}, []server.Role{partnerEveryone, partnerManager}, channel.ErrAlreadyCrossposted},
+		{"a copy", channel.ChannelMessage{ID: "cmsg_1", Source: source}, []server.Role{partnerEveryone, partnerManager}, channel.ErrInvalidType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupFollow(t)
+			ctx := context.Background()
+
+			m.asPartnerMember(ctx, "user_1", tt.roles...)
+			m.withAnnouncement(ctx, tt.msg)
+
+			_, err := svc.Crosspost(ctx, "serv_2", "chan_news", "cmsg_1", "user_1")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			m.limiter.AssertNotCalled(t, "Acquire", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			m.messageRepo.AssertNotCalled(t, "MarkCrossposted", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestFollowService_Crosspost_RateLimited(t *testing.T) {
+	svc, m := setupFollow(t)
+	ctx := context.Background()
+
+	m.asPartnerMember(ctx, "user_1", partnerEveryone, partnerManager)
+	m.withAnnouncement(ctx, channel.ChannelMessage{ID: "cmsg_1"})
+	m.limiter.On("Acquire", ctx, "chan_news", channel.MaxCrossposts, channel.CrosspostWindow).Return(90*time.Second, nil)
+
+	_, err := svc.Crosspost(ctx, "serv_2", "chan_news", "cmsg_1", "user_1")
+
+	var limited *channel.CrosspostLimitError
+	require.ErrorAs(t, err, &limited)
+	assert.Equal(t, 90, limited.RetryAfterSeconds())
+	m.messageRepo.AssertNotCalled(t, "MarkCrossposted", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFollowService_Crosspost_LostRaceReleasesLimit(t *testing.T) {
+	svc, m := setupFollow(t)
+	ctx := context.Background()
+
+	m.asPartnerMember(ctx, "user_1", partnerEveryone, partnerManager)
+	m.withAnnouncement(ctx, channel.ChannelMessage{ID: "cmsg_1"})
+	m.limiter.On("Acquire", ctx, "chan_news", channel.MaxCrossposts, channel.CrosspostWindow).Return(time.Duration(0), nil)
+	m.limiter.On("Release", ctx, "chan_news").Return(nil)
+
+	// Another request published it first
+	m.messageRepo.On("MarkCrossposted", ctx, "cmsg_1", mock.Anything).Return(false, nil)
+
+	_, err := svc.Crosspost(ctx, "serv_2", "chan_news", "cmsg_1", "user_1")
+
+	assert.ErrorIs(t, err, channel.ErrAlreadyCrossposted)
+	m.limiter.AssertCalled(t, "Release", ctx, "chan_news")
+	m.follows.AssertNotCalled(t, "FindBySource", mock.Anything, mock.Anything)
+}
+
+func TestFollowService_Deliver(t *testing.T) {
+	svc, m := setupFollow(t)
+	ctx := context.Background()
+
+	msg := &channel.ChannelMessage{
+		ID: "cmsg_1", ServerID: "serv_2", ChannelID: "chan_news", AuthorID: "user_1", Content: "v2 is out @everyone",
+		Mentions: channel.MessageMentions{Everyone: true},
+	}
+	m.follows.On("FindBySource", ctx, "chan_news").Return([]*channel.ChannelFollow{
+		{ID: "cfol_1", SourceServerID: "serv_2", SourceChannelID: "chan_news", SourceServerName: "Partner", SourceChannelName: "news", TargetServerID: "serv_1", TargetChannelID: "chan_1"},
+		{ID: "cfol_2", SourceServerID: "serv_2", SourceChannelID: "chan_news", TargetServerID: "serv_3", TargetChannelID: "chan_gone"},
+	}, nil)
+	m.messageRepo.On("Create", ctx, mock.MatchedBy(func(c *channel.ChannelMessage) bool {
+		return c.ChannelID == "chan_1"
+	})).Return(nil)
+	m.messageRepo.On("Create", ctx, mock.MatchedBy(func(c *channel.ChannelMessage) bool {
+		return c.ChannelID == "chan_gone"
+	})).Return(channel.ErrNotFound)
+
+	svc.deliver(ctx, msg)
+
+	// A failing follower does not stop the others
+	require.Equal(t, []ws.EventType{ws.EventChannelMessage}, m.events.types())
+	copied := m.events.events[0].Payload.(*channel.ChannelMessage)
+	assert.Equal(t, "serv_1", copied.ServerID)
+	assert.Equal(t, msg.Content, copied.Content)
+	assert.True(t, copied.Mentions.IsEmpty(), "mentions stay in the source server")
+	assert.Equal(t, &channel.CrosspostSource{
+		MessageID: "cmsg_1", ChannelID: "chan_news", ServerID: "serv_2", ChannelName: "news", ServerName: "Partner",
+	}, copied.Source)
+}
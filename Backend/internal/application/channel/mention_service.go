@@ -0,0 +1,245 @@
+package channel
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	permissionApp "pink/internal/application/permission"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/notification"
+	"pink/internal/domain/readstate"
+	"pink/internal/domain/server"
+	"pink/internal/domain/user"
+)
+
+// mentionNotifyTimeout bounds the background notification of a message's
+// mentions.
+const mentionNotifyTimeout = 30 * time.Second
+
+// OnlineChecker lists the connected users, for @here.
+type OnlineChecker interface {
+	GetOnlineUsers() []string
+}
+
+// MentionService resolves the mentions written in channel messages and
+// notifies the members they reach.
+type MentionService struct {
+	userRepo     user.Repository
+	memberRepo   server.MemberRepository
+	roleRepo     server.RoleRepository
+	settingsRepo server.NotificationSettingsRepository
+	readStates   readstate.Repository
+	notifier     notification.Dispatcher
+	permissions  *permissionApp.Resolver
+	online       OnlineChecker
+}
+
+// NewMentionService creates a new MentionService.
+func NewMentionService(
+	userRepo user.Repository,
+	memberRepo server.MemberRepository,
+	roleRepo server.RoleRepository,
+	settingsRepo server.NotificationSettingsRepository,
+	readStates readstate.Repository,
+	notifier notification.Dispatcher,
+	permissions *permissionApp.Resolver,
+	online OnlineChecker,
+) *MentionService {
+	return &MentionService{
+		userRepo:     userRepo,
+		memberRepo:   memberRepo,
+		roleRepo:     roleRepo,
+		settingsRepo: settingsRepo,
+		readStates:   readStates,
+		notifier:     notifier,
+		permissions:  permissions,
+		online:       online,
+	}
+}
+
+// Resolve turns the mentions written in content into the set stored with a
+// message by authorID. Handles must belong to members of the server.
+// @everyone, @here and roles that are not mentionable are dropped unless the
+// author has MentionEveryone in the channel; the message is still sent.
+func (s *MentionService) Resolve(ctx context.Context, ch *channel.Channel, authorID, content string) (channel.MessageMentions, error) {
+	tokens := channel.ParseMentions(content)
+	var mentions channel.MessageMentions
+
+	for _, handle := range tokens.Handles {
+		u, err := s.userRepo.FindByHandle(ctx, handle)
+		if err != nil {
+			continue
+		}
+		isMember, err := s.memberRepo.IsMember(ctx, ch.ServerID, u.ID)
+		if err != nil {
+			return channel.MessageMentions{}, err
+		}
+		if isMember {
+			mentions.UserIDs = append(mentions.UserIDs, u.ID)
+		}
+	}
+
+	if len(tokens.RoleIDs) == 0 && !tokens.Everyone && !tokens.Here {
+		return mentions, nil
+	}
+
+	perms, err := s.permissions.ChannelPermissionsFor(ctx, ch, authorID)
+	if err != nil {
+		return channel.MessageMentions{}, err
+	}
+	canMentionAll := perms.Has(server.PermissionMentionEveryone)
+
+	if len(tokens.RoleIDs) > 0 {
+		roles, err := s.roleRepo.FindByServerID(ctx, ch.ServerID)
+		if err != nil {
+			return channel.MessageMentions{}, err
+		}
+		byID := make(map[string]server.Role, len(roles))
+		for _, role := range roles {
+			byID[role.ID] = *role
+		}
+
+		for _, roleID := range tokens.RoleIDs {
+			role, ok := byID[roleID]
+			// @everyone is mentioned by name, not as a role
+			if !ok || role.IsDefault {
+				continue
+			}
+			if role.IsMentionable || canMentionAll {
+				mentions.RoleIDs = append(mentions.RoleIDs, roleID)
+			}
+		}
+	}
+
+	mentions.Everyone = tokens.Everyone && canMentionAll
+	mentions.Here = tokens.Here && canMentionAll
+
+	return mentions, nil
+}
+
+// Notify adds to the mention count of every member a stored message reaches
+// who can see its channel, and notifies those who have not muted the server.
+// Each member's notification settings decide whether role, @everyone and
+// @here mentions count for them. Failures are logged: the message is already
+// sent.
+func (s *MentionService) Notify(ctx context.Context, ch *channel.Channel, msg *channel.ChannelMessage) {
+	if msg.Mentions.IsEmpty() {
+		return
+	}
+
+	viewers, err := s.permissions.MembersWithChannelPermission(ctx, ch, server.PermissionViewChannel)
+	if err != nil {
+		slog.Warn("failed to resolve mentioned members", slog.Any("error", err), slog.String("message_id", msg.ID))
+		return
+	}
+
+	// @here reaches members connected when the message is sent
+	online := make(map[string]bool)
+	if msg.Mentions.Here && !msg.Mentions.Everyone {
+		for _, userID := range s.online.GetOnlineUsers() {
+			online[userID] = true
+		}
+	}
+
+	var userIDs []string
+	kinds := make(map[string][]server.MentionKind)
+	for _, member := range viewers {
+		if member.UserID == msg.AuthorID {
+			continue
+		}
+		if k := mentionKinds(msg.Mentions, member, online); len(k) > 0 {
+			userIDs = append(userIDs, member.UserID)
+			kinds[member.UserID] = k
+		}
+	}
+	if len(userIDs) == 0 {
+		return
+	}
+
+	settings, err := s.settingsRepo.FindByUserIDs(ctx, ch.ServerID, userIDs)
+	if err != nil {
+		slog.Warn("failed to load notification settings", slog.Any("error", err), slog.String("server_id", ch.ServerID))
+		return
+	}
+
+	var counted []string
+	for _, userID := range userIDs {
+		st, ok := settings[userID]
+		if !ok {
+			st = server.DefaultNotificationSettings(ch.ServerID, userID)
+		}
+		if !countsAny(st, kinds[userID]) {
+			continue
+		}
+		counted = append(counted, userID)
+
+		if st.IsMuted() {
+			continue
+		}
+		notif := &notification.Notification{
+			UserID:     userID,
+			Type:       notification.TypeMention,
+			ActorID:    &msg.AuthorID,
+			TargetType: stringPtr("channel_message"),
+			TargetID:   &msg.ID,
+			Message:    "mentioned you in #" + ch.Name,
+		}
+		if err := s.notifier.Dispatch(ctx, notif); err != nil {
+			slog.Warn("failed to dispatch mention notification", slog.Any("error", err), slog.String("user_id", userID))
+		}
+	}
+	if len(counted) == 0 {
+		return
+	}
+
+	if err := s.readStates.IncrementMentions(ctx, ch.ID, counted); err != nil {
+		slog.Warn("failed to count mentions", slog.Any("error", err), slog.String("channel_id", ch.ID))
+	}
+}
+
+// mentionKinds lists the ways a message mentions a member: by handle,
+// through one of their roles, and through @everyone or @here when they are
+// online.
+func mentionKinds(mentions channel.MessageMentions, member *server.Member, online map[string]bool) []server.MentionKind {
+	var kinds []server.MentionKind
+	for _, userID := range mentions.UserIDs {
+		if userID == member.UserID {
+			kinds = append(kinds, server.MentionDirect)
+			break
+		}
+	}
+	if hasAnyRole(member, mentions.RoleIDs) {
+		kinds = append(kinds, server.MentionRole)
+	}
+	if mentions.Everyone || (mentions.Here && online[member.UserID]) {
+		kinds = append(kinds, server.MentionEveryone)
+	}
+	return kinds
+}
+
+func hasAnyRole(member *server.Member, roleIDs []string) bool {
+	for _, roleID := range roleIDs {
+		for _, role := range member.Roles {
+			if role.ID == roleID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// countsAny reports whether any of the ways a member was mentioned counts
+// under their settings.
+func countsAny(settings *server.NotificationSettings, kinds []server.MentionKind) bool {
+	for _, kind := range kinds {
+		if settings.CountsMention(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
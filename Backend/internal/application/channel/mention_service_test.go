@@ -0,0 +1,186 @@
+package channel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/permission"
+	"pink/internal/application/testutil"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/notification"
+	"pink/internal/domain/server"
+	"pink/internal/domain/user"
+)
+
+type mentionMocks struct {
+	*serviceMocks
+	userRepo     *testutil.MockUserRepository
+	settingsRepo *testutil.MockNotificationSettingsRepository
+	readStates   *testutil.MockReadStateRepository
+	notifier     *recordingDispatcher
+}
+
+// recordingDispatcher collects the notifications a service dispatches.
+type recordingDispatcher struct {
+	notifications []*notification.Notification
+}
+
+func (d *recordingDispatcher) Dispatch(_ context.Context, n *notification.Notification) error {
+	d.notifications = append(d.notifications, n)
+	return nil
+}
+
+func (d *recordingDispatcher) SyncUnreadCount(context.Context, string) error {
+	return nil
+}
+
+func (d *recordingDispatcher) recipients() []string {
+	ids := make([]string, len(d.notifications))
+	for i, n := range d.notifications {
+		ids[i] = n.UserID
+	}
+	return ids
+}
+
+// onlineUsers lists the connected users and counts how often they were
+// asked for.
+type onlineUsers struct {
+	ids   []string
+	calls int
+}
+
+func (o *onlineUsers) GetOnlineUsers() []string {
+	o.calls++
+	return o.ids
+}
+
+var (
+	mentionChannel = &channel.Channel{ID: "chan_1", ServerID: "serv_1", Name: "general", Type: channel.TypeText}
+	teamRole       = server.Role{ID: "role_team", ServerID: "serv_1", Position: 1, IsMentionable: true}
+	secretRole     = server.Role{ID: "role_secret", ServerID: "serv_1", Position: 2}
+	announcer      = server.Role{ID: "role_announcer", ServerID: "serv_1", Position: 3, Permissions: server.PermissionMentionEveryone}
+)
+
+// setupMentions creates a mention service with mocked dependencies. Online
+// users count for @here.
+func setupMentions(t *testing.T, online *onlineUsers) (*MentionService, *mentionMocks) {
+	_, sm := setupService(t)
+	m := &mentionMocks{
+		serviceMocks: sm,
+		userRepo:     new(testutil.MockUserRepository),
+		settingsRepo: new(testutil.MockNotificationSettingsRepository),
+		readStates:   new(testutil.MockReadStateRepository),
+		notifier:     &recordingDispatcher{},
+	}
+
+	resolver := permission.NewResolver(permission.NewEngine(), m.serverRepo, m.memberRepo, m.roleRepo, m.channelRepo, m.overwriteRepo)
+	svc := NewMentionService(m.userRepo, m.memberRepo, m.roleRepo, m.settingsRepo, m.readStates, m.notifier, resolver, online)
+
+	m.overwriteRepo.On("FindByChannelIDs", mock.Anything, mock.Anything).Return(map[string][]channel.PermissionOverwrite{}, nil)
+	m.roleRepo.On("FindByServerID", mock.Anything, "serv_1").Return([]*server.Role{&everyone, &teamRole, &secretRole, &announcer}, nil)
+	return svc, m
+}
+
+func TestMentionService_Resolve(t *testing.T) {
+	const content = "@alice @bob <@&role_team> <@&role_secret> <@&role_everyone> @everyone"
+
+	tests := []struct {
+		name string
+		role server.Role
+		want channel.MessageMentions
+	}{
+		{
+			name: "without mention everyone",
+			role: everyone,
+			want: channel.MessageMentions{UserIDs: []string{"user_alice"}, RoleIDs: []string{"role_team"}},
+		},
+		{
+			name: "with mention everyone",
+			role: announcer,
+			want: channel.MessageMentions{UserIDs: []string{"user_alice"}, RoleIDs: []string{"role_team", "role_secret"}, Everyone: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupMentions(t, nil)
+			ctx := context.Background()
+
+			m.asMember(ctx, "user_1", everyone, tt.role)
+			m.userRepo.On("FindByHandle", ctx, "alice").Return(&user.User{ID: "user_alice", Handle: "alice"}, nil)
+			m.userRepo.On("FindByHandle", ctx, "bob").Return(&user.User{ID: "user_bob", Handle: "bob"}, nil)
+			m.memberRepo.On("IsMember", ctx, "serv_1", "user_alice").Return(true, nil)
+			m.memberRepo.On("IsMember", ctx, "serv_1", "user_bob").Return(false, nil)
+
+			got, err := svc.Resolve(ctx, mentionChannel, "user_1", content)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMentionService_Notify(t *testing.T) {
+	online := &onlineUsers{ids: []string{"user_online"}}
+	svc, m := setupMentions(t, online)
+	ctx := context.Background()
+
+	muted := time.Now().Add(time.Hour)
+	m.serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	m.roleRepo.On("FindDefaultRole", ctx, "serv_1").Return(&everyone, nil)
+	m.memberRepo.On("FindByServerID", ctx, "serv_1").Return([]*server.Member{
+		{UserID: "user_author"},
+		{UserID: "user_direct"},
+		{UserID: "user_team", Roles: []server.Role{teamRole}},
+		{UserID: "user_team_quiet", Roles: []server.Role{teamRole}},
+		{UserID: "user_muted"},
+		{UserID: "user_online"},
+		{UserID: "user_offline"},
+	}, nil)
+	m.settingsRepo.On("FindByUserIDs", ctx, "serv_1", []string{"user_direct", "user_team", "user_team_quiet", "user_muted", "user_online"}).
+		Return(map[string]*server.NotificationSettings{
+			"user_team_quiet": {Level: server.NotificationLevelMentions, SuppressRoles: true},
+			"user_muted":      {Level: server.NotificationLevelMentions, MutedUntil: &muted},
+		}, nil)
+	m.readStates.On("IncrementMentions", ctx, "chan_1", []string{"user_direct", "user_team", "user_muted", "user_online"}).Return(nil)
+
+	msg := &channel.ChannelMessage{
+		ID: "cmsg_1", ChannelID: "chan_1", ServerID: "serv_1", AuthorID: "user_author",
+		Mentions: channel.MessageMentions{UserIDs: []string{"user_direct", "user_muted"}, RoleIDs: []string{"role_team"}, Here: true},
+	}
+	svc.Notify(ctx, mentionChannel, msg)
+
+	// Muted members are counted but not notified
+	assert.Equal(t, []string{"user_direct", "user_team", "user_online"}, m.notifier.recipients())
+	assert.Equal(t, notification.TypeMention, m.notifier.notifications[0].Type)
+	assert.Equal(t, 1, online.calls, "online users are fetched once per message")
+	m.readStates.AssertExpectations(t)
+}
+
+func TestMentionService_Notify_SuppressedRoleStillCountsEveryone(t *testing.T) {
+	svc, m := setupMentions(t, nil)
+	ctx := context.Background()
+
+	m.serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	m.roleRepo.On("FindDefaultRole", ctx, "serv_1").Return(&everyone, nil)
+	m.memberRepo.On("FindByServerID", ctx, "serv_1").Return([]*server.Member{
+		{UserID: "user_team", Roles: []server.Role{teamRole}},
+	}, nil)
+	m.settingsRepo.On("FindByUserIDs", ctx, "serv_1", []string{"user_team"}).
+		Return(map[string]*server.NotificationSettings{
+			"user_team": {Level: server.NotificationLevelMentions, SuppressRoles: true},
+		}, nil)
+	m.readStates.On("IncrementMentions", ctx, "chan_1", []string{"user_team"}).Return(nil)
+
+	svc.Notify(ctx, mentionChannel, &channel.ChannelMessage{
+		ID: "cmsg_1", ChannelID: "chan_1", ServerID: "serv_1", AuthorID: "user_author",
+		Mentions: channel.MessageMentions{RoleIDs: []string{"role_team"}, Everyone: true},
+	})
+
+	assert.Equal(t, []string{"user_team"}, m.notifier.recipients())
+}
@@ -3,19 +3,36 @@ package channel
 
 import (
 	"context"
+	"log/slog"
+	"strings"
 	"time"
 
+	permissionApp "pink/internal/application/permission"
 	"pink/internal/domain/channel"
+	"pink/internal/domain/history"
+	"pink/internal/domain/media"
+	"pink/internal/domain/reaction"
+	"pink/internal/domain/revision"
 	"pink/internal/domain/server"
+	"pink/internal/domain/slowmode"
 	"pink/internal/pkg/id"
+	"pink/internal/pkg/periodic"
 )
 
 // MessageService provides channel message-related operations.
 type MessageService struct {
-	messageRepo channel.MessageRepository
-	channelRepo channel.Repository
-	memberRepo  server.MemberRepository
-	serverRepo  server.Repository
+	messageRepo  channel.MessageRepository
+	channelRepo  channel.Repository
+	memberRepo   server.MemberRepository
+	serverRepo   server.Repository
+	auditRepo    server.AuditLogRepository
+	reactionRepo reaction.Repository
+	revisions    revision.Repository
+	mediaRepo    media.Repository
+	mediaFiles   media.Storage
+	mentions     *MentionService
+	slowmode     slowmode.Limiter
+	permissions  *permissionApp.Resolver
 }
 
 // NewMessageService creates a new MessageService.
@@ -24,49 +41,107 @@ func NewMessageService(
 	channelRepo channel.Repository,
 	memberRepo server.MemberRepository,
 	serverRepo server.Repository,
+	auditRepo server.AuditLogRepository,
+	permissions *permissionApp.Resolver,
 ) *MessageService {
 	return &MessageService{
 		messageRepo: messageRepo,
 		channelRepo: channelRepo,
 		memberRepo:  memberRepo,
 		serverRepo:  serverRepo,
+		auditRepo:   auditRepo,
+		permissions: permissions,
 	}
 }
 
+// SetReactionRepository enables message reactions. It must be set before
+// the reaction methods are used; until then messages are listed without
+// reaction counts.
+func (s *MessageService) SetReactionRepository(reactionRepo reaction.Repository) {
+	s.reactionRepo = reactionRepo
+}
+
+// SetRevisionRepository enables edit history. Until it is set, edits
+// overwrite messages without keeping what they replaced.
+func (s *MessageService) SetRevisionRepository(revisions revision.Repository) {
+	s.revisions = revisions
+}
+
+// SetMediaStore enables message attachments: uploaded media is looked up in
+// mediaRepo, and attached media is removed from both stores along with its
+// message. Until it is set, messages cannot carry attachments.
+func (s *MessageService) SetMediaStore(mediaRepo media.Repository, files media.Storage) {
+	s.mediaRepo = mediaRepo
+	s.mediaFiles = files
+}
+
+// SetMentionService enables mentions. Until it is set, mentions in messages
+// are neither stored nor notified.
+func (s *MessageService) SetMentionService(mentions *MentionService) {
+	s.mentions = mentions
+}
+
+// SetSlowmodeLimiter enables slowmode. Until it is set, channels' slowmode
+// is not enforced.
+func (s *MessageService) SetSlowmodeLimiter(limiter slowmode.Limiter) {
+	s.slowmode = limiter
+}
+
 // GetMessagesCommand represents a request to get messages.
 type GetMessagesCommand struct {
 	ServerID  string
 	ChannelID string
 	UserID    string
-	Cursor    string
-	Limit     int
+	Cursor    history.Cursor
 }
 
-// GetMessages retrieves messages from a channel.
-func (s *MessageService) GetMessages(ctx context.Context, cmd GetMessagesCommand) ([]*channel.ChannelMessage, string, error) {
+// GetMessages retrieves the page of a channel's messages the cursor selects.
+// The cursor's anchor must be a message of the channel outside its threads.
+func (s *MessageService) GetMessages(ctx context.Context, cmd GetMessagesCommand) ([]*channel.ChannelMessage, history.Page, error) {
 	// Check membership
 	if err := s.requireMembership(ctx, cmd.ServerID, cmd.UserID); err != nil {
-		return nil, "", err
+		return nil, history.Page{}, err
 	}
 
-	// Verify channel exists and belongs to server
-	if err := s.validateChannel(ctx, cmd.ChannelID, cmd.ServerID); err != nil {
-		return nil, "", err
+	// Verify channel exists, belongs to server and is visible to the user
+	if _, err := s.requireChannelPermission(ctx, cmd.ChannelID, cmd.ServerID, cmd.UserID, server.PermissionViewChannel); err != nil {
+		return nil, history.Page{}, err
+	}
+
+	if anchor := cmd.Cursor.Anchor(); anchor != "" {
+		msg, err := s.messageRepo.FindByID(ctx, anchor)
+		if err != nil {
+			return nil, history.Page{}, err
+		}
+		if msg.ChannelID != cmd.ChannelID || msg.ThreadID != nil {
+			return nil, history.Page{}, channel.ErrMessageNotFound
+		}
 	}
 
-	return s.messageRepo.FindByChannelID(ctx, cmd.ChannelID, cmd.Cursor, cmd.Limit)
+	messages, page, err := s.messageRepo.FindByChannelID(ctx, cmd.ChannelID, cmd.Cursor)
+	if err != nil {
+		return nil, history.Page{}, err
+	}
+
+	if err := s.attachReactions(ctx, messages, cmd.UserID); err != nil {
+		return nil, history.Page{}, err
+	}
+	return messages, page, nil
 }
 
 // SendMessageCommand represents a request to send a message.
 type SendMessageCommand struct {
-	ServerID  string
-	ChannelID string
-	UserID    string
-	Content   string
-	ReplyToID *string
+	ServerID      string
+	ChannelID     string
+	UserID        string
+	Content       string
+	ReplyToID     *string
+	AttachmentIDs []string // Media uploaded by the sender, in display order
 }
 
-// SendMessage sends a message to a channel.
+// SendMessage sends a message to a channel. Unless they have ManageMessages
+// or BypassSlowmode, members wait out the channel's slowmode between
+// messages.
 func (s *MessageService) SendMessage(ctx context.Context, cmd SendMessageCommand) (*channel.ChannelMessage, error) {
 	// Check membership
 	if err := s.requireMembership(ctx, cmd.ServerID, cmd.UserID); err != nil {
@@ -80,15 +155,30 @@ func (s *MessageService) SendMessage(ctx context.Context, cmd SendMessageCommand
 	}
 
 	// Check permission to send in this channel
-	if err := s.canSendMessage(ctx, ch, cmd.ServerID, cmd.UserID); err != nil {
+	perms, err := s.canSendMessage(ctx, ch, cmd.UserID, len(cmd.AttachmentIDs) > 0)
+	if err != nil {
 		return nil, err
 	}
 
 	// Validate content
-	if len(cmd.Content) == 0 || len(cmd.Content) > 2000 {
+	if !validContent(cmd.Content, len(cmd.AttachmentIDs)) {
 		return nil, channel.ErrInvalidContent
 	}
 
+	attachments, err := s.resolveAttachments(ctx, cmd.AttachmentIDs, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.resolveMentions(ctx, ch, cmd.UserID, cmd.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforceSlowmode(ctx, ch, ch.ID, cmd.UserID, perms); err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	msg := &channel.ChannelMessage{
 		ID:        id.Generate("cmsg"),
@@ -99,48 +189,72 @@ func (s *MessageService) SendMessage(ctx context.Context, cmd SendMessageCommand
 		IsEdited:  false,
 		IsPinned:  false,
 		ReplyToID: cmd.ReplyToID,
+		Mentions:  mentions,
 		CreatedAt: now,
 		UpdatedAt: now,
+
+		Attachments: attachments,
 	}
 
 	if err := s.messageRepo.Create(ctx, msg); err != nil {
+		s.releaseSlowmode(ctx, ch, ch.ID, cmd.UserID, perms)
 		return nil, err
 	}
 
+	s.notifyMentions(ch, msg)
+
 	return msg, nil
 }
 
-// EditMessage edits a message.
+// EditMessage edits a message. Thread messages are edited through the
+// thread.
 func (s *MessageService) EditMessage(ctx context.Context, serverID, channelID, messageID, userID, content string) (*channel.ChannelMessage, error) {
+	return s.editMessage(ctx, serverID, channelID, "", messageID, userID, content)
+}
+
+// editMessage edits a message posted in threadID, or directly in the channel
+// when threadID is empty.
+func (s *MessageService) editMessage(ctx context.Context, serverID, channelID, threadID, messageID, userID, content string) (*channel.ChannelMessage, error) {
 	// Check membership
 	if err := s.requireMembership(ctx, serverID, userID); err != nil {
 		return nil, err
 	}
 
-	// Find message
-	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	msg, err := s.findMessage(ctx, serverID, channelID, threadID, messageID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify message belongs to channel and server
-	if msg.ServerID != serverID || msg.ChannelID != channelID {
-		return nil, channel.ErrMessageNotFound
-	}
-
-	// Only author can edit
-	if msg.AuthorID != userID {
+	// Only author can edit, and only while they can still see the channel.
+	// Copies of published announcements are not edited.
+	if msg.AuthorID != userID || msg.IsCrosspost() {
 		return nil, channel.ErrNoPermission
 	}
+	ch, err := s.requireChannelPermission(ctx, channelID, serverID, userID, server.PermissionViewChannel)
+	if err != nil {
+		return nil, err
+	}
 
-	// Validate content
-	if len(content) == 0 || len(content) > 2000 {
+	// Validate content; a message with attachments may be left without text
+	if !validContent(content, len(msg.Attachments)) {
 		return nil, channel.ErrInvalidContent
 	}
 
+	// Mentions follow the new content, but only new messages notify
+	mentions, err := s.resolveMentions(ctx, ch, userID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.recordRevision(ctx, msg, content, now); err != nil {
+		return nil, err
+	}
+
 	msg.Content = content
+	msg.Mentions = mentions
 	msg.IsEdited = true
-	msg.UpdatedAt = time.Now()
+	msg.UpdatedAt = now
 
 	if err := s.messageRepo.Update(ctx, msg); err != nil {
 		return nil, err
@@ -149,32 +263,172 @@ func (s *MessageService) EditMessage(ctx context.Context, serverID, channelID, m
 	return msg, nil
 }
 
-// DeleteMessage deletes a message.
+// recordRevision keeps the content an edit is about to replace. The edit
+// fails if it cannot be kept, so that history cannot be lost.
+func (s *MessageService) recordRevision(ctx context.Context, msg *channel.ChannelMessage, content string, at time.Time) error {
+	if s.revisions == nil || msg.Content == content {
+		return nil
+	}
+	return s.revisions.Create(ctx, &revision.Revision{
+		ID:        id.Generate("rev"),
+		MessageID: msg.ID,
+		Kind:      revision.KindChannel,
+		Content:   msg.Content,
+		EditedAt:  at,
+	})
+}
+
+// GetRevisions lists what a message said before each of its edits, oldest
+// first. Only its author and members with ManageMessages in the channel can
+// see them. Thread messages' history is read through the thread.
+func (s *MessageService) GetRevisions(ctx context.Context, serverID, channelID, messageID, userID string) ([]*revision.Revision, error) {
+	return s.getRevisions(ctx, serverID, channelID, "", messageID, userID)
+}
+
+// getRevisions lists the edit history of a message posted in threadID, or
+// directly in the channel when threadID is empty.
+func (s *MessageService) getRevisions(ctx context.Context, serverID, channelID, threadID, messageID, userID string) ([]*revision.Revision, error) {
+	// Check membership
+	if err := s.requireMembership(ctx, serverID, userID); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.findMessage(ctx, serverID, channelID, threadID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	perm := server.PermissionViewChannel
+	if msg.AuthorID != userID {
+		perm |= server.PermissionManageMessages
+	}
+	if _, err := s.requireChannelPermission(ctx, channelID, serverID, userID, perm); err != nil {
+		return nil, err
+	}
+
+	if s.revisions == nil {
+		return nil, nil
+	}
+	return s.revisions.FindByMessageID(ctx, messageID)
+}
+
+// DeleteMessage deletes a message. Thread messages are deleted through the
+// thread.
 func (s *MessageService) DeleteMessage(ctx context.Context, serverID, channelID, messageID, userID string) error {
+	return s.deleteMessage(ctx, serverID, channelID, "", messageID, userID)
+}
+
+// deleteMessage deletes a message posted in threadID, or directly in the
+// channel when threadID is empty.
+func (s *MessageService) deleteMessage(ctx context.Context, serverID, channelID, threadID, messageID, userID string) error {
 	// Check membership
 	if err := s.requireMembership(ctx, serverID, userID); err != nil {
 		return err
 	}
 
-	// Find message
-	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	msg, err := s.findMessage(ctx, serverID, channelID, threadID, messageID)
 	if err != nil {
 		return err
 	}
 
-	// Verify message belongs to channel and server
-	if msg.ServerID != serverID || msg.ChannelID != channelID {
-		return channel.ErrMessageNotFound
+	// Check permission: author or has ManageMessages in the channel
+	perm := server.PermissionViewChannel
+	if msg.AuthorID != userID {
+		perm |= server.PermissionManageMessages
 	}
+	if _, err := s.requireChannelPermission(ctx, channelID, serverID, userID, perm); err != nil {
+		return err
+	}
+
+	if err := s.messageRepo.Delete(ctx, messageID); err != nil {
+		return err
+	}
+	s.removeAttachments(ctx, msg)
 
-	// Check permission: owner or has ManageMessages
+	// Authors deleting their own messages is not a moderation action
 	if msg.AuthorID != userID {
-		if !s.canManageMessages(ctx, serverID, userID) {
-			return channel.ErrNoPermission
-		}
+		s.audit(ctx, serverID, userID, msg.AuthorID, server.AuditLogActionMessageDelete, server.AuditChanges{
+			"channel_id": channelID,
+			"message_id": messageID,
+		}.Set("content", msg.Content, nil))
 	}
 
-	return s.messageRepo.Delete(ctx, messageID)
+	return nil
+}
+
+// BulkDeleteCommand represents a request to delete many messages of a
+// channel at once.
+type BulkDeleteCommand struct {
+	ServerID  string
+	ChannelID string
+	UserID    string
+	Filter    channel.BulkDeleteFilter
+}
+
+// BulkDeleteMessages deletes the messages of a channel, its threads included,
+// that match the filter, newest first and at most Filter.Limit of them. It
+// requires ManageMessages in the channel and is recorded as a single audit
+// entry. It returns the deleted messages.
+func (s *MessageService) BulkDeleteMessages(ctx context.Context, cmd BulkDeleteCommand) ([]*channel.ChannelMessage, error) {
+	filter := cmd.Filter
+	filter.ChannelID = cmd.ChannelID
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Check membership
+	if err := s.requireMembership(ctx, cmd.ServerID, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	perm := server.PermissionViewChannel | server.PermissionManageMessages
+	if _, err := s.requireChannelPermission(ctx, cmd.ChannelID, cmd.ServerID, cmd.UserID, perm); err != nil {
+		return nil, err
+	}
+
+	deleted, err := s.messageRepo.DeleteMatching(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(deleted) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(deleted))
+	for i, msg := range deleted {
+		ids[i] = msg.ID
+		s.removeAttachments(ctx, msg)
+	}
+
+	changes := server.AuditChanges{
+		"channel_id":  cmd.ChannelID,
+		"count":       len(deleted),
+		"message_ids": ids,
+	}
+	if filter.AuthorID != "" {
+		changes["author_id"] = filter.AuthorID
+	}
+	s.audit(ctx, cmd.ServerID, cmd.UserID, cmd.ChannelID, server.AuditLogActionMessageBulkDelete, changes)
+
+	return deleted, nil
+}
+
+// audit records an audit entry without failing the action it describes.
+func (s *MessageService) audit(ctx context.Context, serverID, actorID, targetID string, action server.AuditLogAction, changes server.AuditChanges) {
+	if s.auditRepo == nil {
+		return
+	}
+	if err := s.auditRepo.Create(ctx, &server.AuditLog{
+		ID:         id.Generate("audi"),
+		ServerID:   serverID,
+		ActorID:    actorID,
+		TargetID:   targetID,
+		ActionType: action,
+		Changes:    changes,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", string(action)))
+	}
 }
 
 // SearchMessages searches messages in a channel.
@@ -188,9 +442,261 @@ func (s *MessageService) SearchMessages(ctx context.Context, serverID, channelID
 		return nil, channel.ErrInvalidContent
 	}
 
+	if _, err := s.requireChannelPermission(ctx, channelID, serverID, userID, server.PermissionViewChannel); err != nil {
+		return nil, err
+	}
+
 	return s.messageRepo.Search(ctx, channelID, query, limit)
 }
 
+// SearchServerCommand represents a search across a server's messages. Query
+// is written in the syntax channel.ParseMessageQuery reads.
+type SearchServerCommand struct {
+	ServerID string
+	UserID   string
+	Query    string
+	Offset   int
+	Limit    int
+}
+
+// SearchServerMessages searches the messages of every channel in the server
+// the user can view, threads included, and returns one page of hits with
+// the total number of matches. in: operators narrow the search to the named
+// channels; naming a channel the user cannot view matches nothing. Offsets
+// past channel.MaxSearchOffset are rejected.
+func (s *MessageService) SearchServerMessages(ctx context.Context, cmd SearchServerCommand) ([]*channel.MessageSearchHit, int, error) {
+	if err := s.requireMembership(ctx, cmd.ServerID, cmd.UserID); err != nil {
+		return nil, 0, err
+	}
+
+	if cmd.Offset > channel.MaxSearchOffset {
+		return nil, 0, channel.ErrInvalidSearch
+	}
+
+	query, err := channel.ParseMessageQuery(cmd.Query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	channels, err := s.channelRepo.FindByServerID(ctx, cmd.ServerID)
+	if err != nil {
+		return nil, 0, err
+	}
+	visible, err := s.permissions.VisibleChannels(ctx, cmd.ServerID, cmd.UserID, channels)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var channelIDs []string
+	for _, ch := range visible {
+		if ch.Type != channel.TypeCategory && matchesChannel(ch, query.In) {
+			channelIDs = append(channelIDs, ch.ID)
+		}
+	}
+	if len(channelIDs) == 0 {
+		return nil, 0, nil
+	}
+
+	limit := cmd.Limit
+	if limit <= 0 || limit > channel.MaxSearchPageSize {
+		limit = 25
+	}
+	offset := cmd.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.messageRepo.SearchServer(ctx, channel.MessageSearch{
+		ServerID:   cmd.ServerID,
+		ChannelIDs: channelIDs,
+		Query:      query,
+		Offset:     offset,
+		Limit:      limit,
+	})
+}
+
+// matchesChannel reports whether a channel is one of those named by in:
+// operators, by ID or case-insensitive name. No names match every channel.
+func matchesChannel(ch *channel.Channel, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, name := range names {
+		if ch.ID == name || strings.EqualFold(ch.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReactionCommand identifies a reaction on a channel message. Emoji is in
+// the form reaction.ParseEmoji reads.
+type ReactionCommand struct {
+	ServerID  string
+	ChannelID string
+	MessageID string
+	UserID    string
+	Emoji     string
+}
+
+// AddReaction reacts to a message as cmd.UserID. It reports whether the
+// reaction is new; reacting twice with the same emoji changes nothing.
+func (s *MessageService) AddReaction(ctx context.Context, cmd ReactionCommand) (*reaction.Reaction, bool, error) {
+	if s.reactionRepo == nil {
+		return nil, false, reaction.ErrUnavailable
+	}
+
+	emoji, err := reaction.ParseEmoji(cmd.Emoji)
+	if err != nil {
+		return nil, false, err
+	}
+
+	msg, ch, err := s.reactionTarget(ctx, cmd.ServerID, cmd.ChannelID, cmd.MessageID, cmd.UserID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	input, err := s.permissions.ChannelInput(ctx, ch, cmd.UserID)
+	if err != nil {
+		return nil, false, server.ErrNotMember
+	}
+	// Timed out members cannot react, unless they own the server
+	if !input.IsOwner && input.Member.IsTimedOut() {
+		return nil, false, channel.ErrNoPermission
+	}
+	if !s.permissions.Calculate(input).Has(server.PermissionViewChannel | server.PermissionAddReactions) {
+		return nil, false, channel.ErrNoPermission
+	}
+
+	rc := &reaction.Reaction{
+		MessageID: msg.ID,
+		Kind:      reaction.KindChannel,
+		UserID:    cmd.UserID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	}
+	added, err := s.reactionRepo.Add(ctx, rc)
+	if err != nil {
+		return nil, false, err
+	}
+	return rc, added, nil
+}
+
+// RemoveReaction removes targetUserID's reaction from a message. Removing
+// someone else's reaction requires ManageMessages. It reports whether the
+// reaction existed.
+func (s *MessageService) RemoveReaction(ctx context.Context, cmd ReactionCommand, targetUserID string) (*reaction.Reaction, bool, error) {
+	if s.reactionRepo == nil {
+		return nil, false, reaction.ErrUnavailable
+	}
+
+	emoji, err := reaction.ParseEmoji(cmd.Emoji)
+	if err != nil {
+		return nil, false, err
+	}
+
+	msg, _, err := s.reactionTarget(ctx, cmd.ServerID, cmd.ChannelID, cmd.MessageID, cmd.UserID)
+	if err != nil {
+		return nil, false, err
+	}
+	if targetUserID != cmd.UserID {
+		if _, err := s.requireChannelPermission(ctx, cmd.ChannelID, cmd.ServerID, cmd.UserID, server.PermissionManageMessages); err != nil {
+			return nil, false, err
+		}
+	}
+
+	removed, err := s.reactionRepo.Remove(ctx, msg.ID, targetUserID, emoji)
+	if err != nil {
+		return nil, false, err
+	}
+	return &reaction.Reaction{MessageID: msg.ID, Kind: reaction.KindChannel, UserID: targetUserID, Emoji: emoji}, removed, nil
+}
+
+// ListReactionUsers lists who reacted to a message with an emoji.
+func (s *MessageService) ListReactionUsers(ctx context.Context, cmd ReactionCommand, cursor string, limit int) ([]*reaction.Reaction, string, error) {
+	if s.reactionRepo == nil {
+		return nil, "", reaction.ErrUnavailable
+	}
+
+	emoji, err := reaction.ParseEmoji(cmd.Emoji)
+	if err != nil {
+		return nil, "", err
+	}
+
+	msg, _, err := s.reactionTarget(ctx, cmd.ServerID, cmd.ChannelID, cmd.MessageID, cmd.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.reactionRepo.FindUsers(ctx, msg.ID, emoji, cursor, limit)
+}
+
+// PinMessage pins a message to its channel. It reports whether the message
+// was not already pinned.
+func (s *MessageService) PinMessage(ctx context.Context, serverID, channelID, messageID, userID string) (*channel.ChannelMessage, bool, error) {
+	msg, err := s.pinTarget(ctx, serverID, channelID, messageID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if msg.IsPinned {
+		return msg, false, nil
+	}
+
+	msg.Pin(userID, time.Now())
+	if err := s.messageRepo.UpdatePin(ctx, msg); err != nil {
+		return nil, false, err
+	}
+
+	s.audit(ctx, serverID, userID, msg.AuthorID, server.AuditLogActionMessagePin, server.AuditChanges{
+		"channel_id": channelID,
+		"message_id": messageID,
+	})
+	return msg, true, nil
+}
+
+// UnpinMessage removes a message from its channel's pins. It reports whether
+// the message was pinned.
+func (s *MessageService) UnpinMessage(ctx context.Context, serverID, channelID, messageID, userID string) (*channel.ChannelMessage, bool, error) {
+	msg, err := s.pinTarget(ctx, serverID, channelID, messageID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !msg.IsPinned {
+		return msg, false, nil
+	}
+
+	msg.Unpin()
+	if err := s.messageRepo.UpdatePin(ctx, msg); err != nil {
+		return nil, false, err
+	}
+
+	s.audit(ctx, serverID, userID, msg.AuthorID, server.AuditLogActionMessageUnpin, server.AuditChanges{
+		"channel_id": channelID,
+		"message_id": messageID,
+	})
+	return msg, true, nil
+}
+
+// GetPins lists a channel's pinned messages, most recently pinned first.
+func (s *MessageService) GetPins(ctx context.Context, serverID, channelID, userID string) ([]*channel.ChannelMessage, error) {
+	if err := s.requireMembership(ctx, serverID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.requireChannelPermission(ctx, channelID, serverID, userID, server.PermissionViewChannel); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.messageRepo.FindPinned(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachReactions(ctx, messages, userID); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
 // ============================================================================
 // HELPER METHODS
 // ============================================================================
@@ -203,70 +709,266 @@ func (s *MessageService) requireMembership(ctx context.Context, serverID, userID
 	return nil
 }
 
-func (s *MessageService) validateChannel(ctx context.Context, channelID, serverID string) error {
+// requireChannelPermission loads a channel of the server and checks the user
+// holds perm in it, with overwrites applied.
+func (s *MessageService) requireChannelPermission(ctx context.Context, channelID, serverID, userID string, perm server.Permission) (*channel.Channel, error) {
 	ch, err := s.channelRepo.FindByID(ctx, channelID)
 	if err != nil || ch == nil || ch.ServerID != serverID {
-		return channel.ErrNotFound
+		return nil, channel.ErrNotFound
 	}
-	return nil
-}
 
-func (s *MessageService) canManageMessages(ctx context.Context, serverID, userID string) bool {
-	// Check if owner
-	srv, err := s.serverRepo.FindByID(ctx, serverID)
-	if err == nil && srv.OwnerID == userID {
-		return true
+	perms, err := s.permissions.ChannelPermissionsFor(ctx, ch, userID)
+	if err != nil {
+		return nil, err
 	}
+	if !perms.Has(perm) {
+		return nil, channel.ErrNoPermission
+	}
+	return ch, nil
+}
 
-	// Check role permissions
-	member, err := s.memberRepo.FindByServerAndUserWithRoles(ctx, serverID, userID)
+// canSendMessage checks if the user can send a message in a channel,
+// attaching files if attaching is set, and returns their permissions in it.
+// This handles: owner and admin bypass, channel overwrites, announcement
+// channels, and timeouts.
+func (s *MessageService) canSendMessage(ctx context.Context, ch *channel.Channel, userID string, attaching bool) (server.Permission, error) {
+	input, err := s.permissions.ChannelInput(ctx, ch, userID)
 	if err != nil {
+		return 0, server.ErrNotMember
+	}
+
+	// Timed out members cannot send, unless they own the server
+	if !input.IsOwner && input.Member.IsTimedOut() {
+		return 0, channel.ErrNoPermission
+	}
+
+	perms := s.permissions.Calculate(input)
+
+	required := server.PermissionViewChannel | server.PermissionSendMessages
+	// Announcement channels are read-only for anyone who can't manage channels
+	if ch.Type == channel.TypeAnnouncement {
+		required |= server.PermissionManageChannels
+	}
+	if attaching {
+		required |= server.PermissionAttachFiles
+	}
+
+	if !perms.Has(required) {
+		return 0, channel.ErrNoPermission
+	}
+
+	return perms, nil
+}
+
+// enforceSlowmode holds the user to the channel's slowmode in chatID, the
+// channel itself or one of its threads, each with its own cooldown.
+// ManageMessages and BypassSlowmode exempt them.
+func (s *MessageService) enforceSlowmode(ctx context.Context, ch *channel.Channel, chatID, userID string, perms server.Permission) error {
+	if bypassesSlowmode(perms) {
+		return nil
+	}
+	return slowmode.Enforce(ctx, s.slowmode, chatID, userID, ch.SlowmodeSeconds)
+}
+
+// releaseSlowmode ends the cooldown enforceSlowmode started for a message
+// that could not be saved, so the user can retry straight away.
+func (s *MessageService) releaseSlowmode(ctx context.Context, ch *channel.Channel, chatID, userID string, perms server.Permission) {
+	if s.slowmode == nil || ch.SlowmodeSeconds <= 0 || bypassesSlowmode(perms) {
+		return
+	}
+	if err := s.slowmode.Release(ctx, chatID, userID); err != nil {
+		slog.Warn("failed to release slowmode cooldown", slog.Any("error", err), slog.String("chat_id", chatID))
+	}
+}
+
+// bypassesSlowmode reports whether perms exempt a member from slowmode.
+func bypassesSlowmode(perms server.Permission) bool {
+	return perms.Has(server.PermissionManageMessages) || perms.Has(server.PermissionBypassSlowmode)
+}
+
+// validContent reports whether content is acceptable for a message with the
+// given number of attachments: text is optional only when files are attached.
+func validContent(content string, attachments int) bool {
+	if len(content) > 2000 {
 		return false
 	}
+	return len(content) > 0 || attachments > 0
+}
+
+// resolveAttachments loads the media to attach to a new message, checking
+// that the sender uploaded all of it.
+func (s *MessageService) resolveAttachments(ctx context.Context, ids []string, userID string) ([]*media.Media, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if s.mediaRepo == nil {
+		return nil, media.ErrNotFound
+	}
+	if len(ids) > media.MaxAttachmentsPerMessage {
+		return nil, media.ErrTooManyAttachments
+	}
+
+	found, err := s.mediaRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return media.OrderAttachments(ids, found, userID)
+}
 
-	return member.HasPermission(server.PermissionManageMessages)
+// removeAttachments deletes the media of a deleted message. Published
+// announcements share their media with their copies, so media stays until
+// neither the source message nor any copy has it attached. Failures are
+// logged: the message is already gone.
+func (s *MessageService) removeAttachments(ctx context.Context, msg *channel.ChannelMessage) {
+	if s.mediaRepo == nil {
+		return
+	}
+	for _, m := range msg.Attachments {
+		deleted, err := s.mediaRepo.DeleteUnattached(ctx, m.ID)
+		if err != nil {
+			slog.Warn("failed to delete attachment", slog.Any("error", err), slog.String("media_id", m.ID))
+			continue
+		}
+		if !deleted {
+			continue
+		}
+		if err := s.mediaFiles.Remove(m); err != nil {
+			slog.Warn("failed to remove attachment file", slog.Any("error", err), slog.String("media_id", m.ID))
+		}
+	}
 }
 
-// canSendMessage checks if the user can send a message in a channel.
-// This handles: owner bypass, admin bypass, announcement channels, and timeouts.
-func (s *MessageService) canSendMessage(ctx context.Context, ch *channel.Channel, serverID, userID string) error {
-	// 1. Check if server owner - always allowed
-	srv, err := s.serverRepo.FindByID(ctx, serverID)
-	if err == nil && srv != nil && srv.OwnerID == userID {
-		return nil // Owner can send anywhere
+// resolveMentions resolves the mentions in a message's content.
+func (s *MessageService) resolveMentions(ctx context.Context, ch *channel.Channel, authorID, content string) (channel.MessageMentions, error) {
+	if s.mentions == nil {
+		return channel.MessageMentions{}, nil
 	}
+	return s.mentions.Resolve(ctx, ch, authorID, content)
+}
+
+// notifyMentions notifies the members a stored message mentions, in the
+// background and within mentionNotifyTimeout.
+func (s *MessageService) notifyMentions(ch *channel.Channel, msg *channel.ChannelMessage) {
+	if s.mentions == nil || msg.Mentions.IsEmpty() {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mentionNotifyTimeout)
+		defer cancel()
+		s.mentions.Notify(ctx, ch, msg)
+	}()
+}
 
-	// 2. Get member with roles for permission checks
-	member, err := s.memberRepo.FindByServerAndUserWithRoles(ctx, serverID, userID)
+// reactionTarget loads a message of a channel the user can see for reacting.
+func (s *MessageService) reactionTarget(ctx context.Context, serverID, channelID, messageID, userID string) (*channel.ChannelMessage, *channel.Channel, error) {
+	if err := s.requireMembership(ctx, serverID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	ch, err := s.requireChannelPermission(ctx, channelID, serverID, userID, server.PermissionViewChannel)
 	if err != nil {
-		return server.ErrNotMember
+		return nil, nil, err
 	}
 
-	// 3. Check if user is timed out
-	if member.IsTimedOut() {
-		return channel.ErrNoPermission
+	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if msg.ServerID != serverID || msg.ChannelID != channelID {
+		return nil, nil, channel.ErrMessageNotFound
 	}
+	return msg, ch, nil
+}
 
-	// 4. Administrator permission bypasses all channel restrictions
-	if member.HasPermission(server.PermissionAdministrator) {
-		return nil
+// findMessage loads a message of the server's channel, posted in threadID,
+// or directly in the channel when threadID is empty.
+func (s *MessageService) findMessage(ctx context.Context, serverID, channelID, threadID, messageID string) (*channel.ChannelMessage, error) {
+	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	var postedIn string
+	if msg.ThreadID != nil {
+		postedIn = *msg.ThreadID
+	}
+	if msg.ServerID != serverID || msg.ChannelID != channelID || postedIn != threadID {
+		return nil, channel.ErrMessageNotFound
 	}
+	return msg, nil
+}
 
-	// 5. Announcement channel - requires ManageChannels permission
-	if ch.Type == channel.TypeAnnouncement {
-		if !member.HasPermission(server.PermissionManageChannels) {
-			return channel.ErrNoPermission
-		}
+// pinTarget loads a channel message for pinning or unpinning, which needs
+// ManageMessages in the channel. Thread messages cannot be pinned.
+func (s *MessageService) pinTarget(ctx context.Context, serverID, channelID, messageID, userID string) (*channel.ChannelMessage, error) {
+	if err := s.requireMembership(ctx, serverID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.requireChannelPermission(ctx, channelID, serverID, userID, server.PermissionViewChannel|server.PermissionManageMessages); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.ServerID != serverID || msg.ChannelID != channelID || msg.ThreadID != nil {
+		return nil, channel.ErrMessageNotFound
 	}
+	return msg, nil
+}
 
-	// 6. Check SendMessages permission (for regular channels)
-	// Note: Default @everyone role usually has this, but can be overwritten
-	if !member.HasPermission(server.PermissionSendMessages) {
-		return channel.ErrNoPermission
+// attachReactions fills in each message's reaction counts for userID.
+func (s *MessageService) attachReactions(ctx context.Context, messages []*channel.ChannelMessage, userID string) error {
+	if s.reactionRepo == nil || len(messages) == 0 {
+		return nil
 	}
 
-	// TODO: Check channel permission overwrites in the future
-	// This would check ch.PermissionOverwrites for role/member-specific denies
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
 
+	summaries, err := s.reactionRepo.Summarize(ctx, ids, userID)
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		msg.Reactions = summaries[msg.ID]
+	}
 	return nil
 }
+
+// RevisionRetentionWorker periodically deletes channel message revisions
+// older than their server's retention.
+type RevisionRetentionWorker struct {
+	revisions revision.Repository
+	interval  time.Duration
+}
+
+// NewRevisionRetentionWorker creates a new revision retention worker.
+func NewRevisionRetentionWorker(revisions revision.Repository, interval time.Duration) *RevisionRetentionWorker {
+	return &RevisionRetentionWorker{
+		revisions: revisions,
+		interval:  interval,
+	}
+}
+
+// Start runs the sweep until ctx is cancelled.
+func (w *RevisionRetentionWorker) Start(ctx context.Context) {
+	periodic.Run(ctx, "revision retention worker", w.interval, w.Sweep)
+}
+
+// Sweep deletes the revisions that have outlived their server's retention.
+func (w *RevisionRetentionWorker) Sweep(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	deleted, err := w.revisions.DeleteExpired(ctx)
+	if err != nil {
+		slog.Error("revision retention sweep failed", slog.Any("error", err))
+		return
+	}
+	if deleted > 0 {
+		slog.Info("revision retention sweep", slog.Int64("deleted", deleted))
+	}
+}
@@ -0,0 +1,702 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/permission"
+	"pink/internal/application/testutil"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/history"
+	"pink/internal/domain/media"
+	"pink/internal/domain/reaction"
+	"pink/internal/domain/revision"
+	"pink/internal/domain/server"
+	"pink/internal/domain/slowmode"
+)
+
+// setupReactions returns the message service behind a thread service with a
+// mocked reaction repository and message cmsg_1 in chan_1.
+func setupReactions(t *testing.T) (*MessageService, *threadMocks, *testutil.MockMessageReactionRepository) {
+	svc, m := setupThreadService(t)
+	reactions := new(testutil.MockMessageReactionRepository)
+	svc.messages.SetReactionRepository(reactions)
+
+	m.messageRepo.On("FindByID", mock.Anything, "cmsg_1").Return(&channel.ChannelMessage{
+		ID: "cmsg_1", ChannelID: "chan_1", ServerID: "serv_1", AuthorID: "user_author",
+	}, nil)
+	return svc.messages, m, reactions
+}
+
+func reactionCmd(userID, emoji string) ReactionCommand {
+	return ReactionCommand{ServerID: "serv_1", ChannelID: "chan_1", MessageID: "cmsg_1", UserID: userID, Emoji: emoji}
+}
+
+func TestMessageService_AddReaction(t *testing.T) {
+	svc, m, reactions := setupReactions(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	reactions.On("Add", ctx, mock.MatchedBy(func(rc *reaction.Reaction) bool {
+		return rc.MessageID == "cmsg_1" && rc.Kind == reaction.KindChannel && rc.UserID == "user_1" && rc.Emoji.Name == "👍"
+	})).Return(true, nil)
+
+	rc, added, err := svc.AddReaction(ctx, reactionCmd("user_1", "👍"))
+
+	require.NoError(t, err)
+	assert.True(t, added)
+	assert.Equal(t, "user_1", rc.UserID)
+}
+
+func TestMessageService_AddReaction_Rejected(t *testing.T) {
+	noReactions := server.Role{ID: "role_everyone", ServerID: "serv_1", IsDefault: true,
+		Permissions: server.PermissionDefaultEveryone &^ server.PermissionAddReactions}
+
+	tests := []struct {
+		name    string
+		role    server.Role
+		emoji   string
+		wantErr error
+	}{
+		{"without add reactions", noReactions, "👍", channel.ErrNoPermission},
+		{"invalid emoji", everyone, "nope", reaction.ErrInvalidEmoji},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m, reactions := setupReactions(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, "user_1", tt.role)
+
+			_, _, err := svc.AddReaction(ctx, reactionCmd("user_1", tt.emoji))
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			reactions.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMessageService_AddReaction_TooManyEmoji(t *testing.T) {
+	svc, m, reactions := setupReactions(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	reactions.On("Add", ctx, mock.Anything).Return(false, reaction.ErrTooManyReactions)
+
+	_, added, err := svc.AddReaction(ctx, reactionCmd("user_1", "👍"))
+
+	assert.ErrorIs(t, err, reaction.ErrTooManyReactions)
+	assert.False(t, added)
+}
+
+func TestMessageService_Reactions_WithoutRepository(t *testing.T) {
+	svc, _ := setupThreadService(t)
+	ctx := context.Background()
+
+	_, _, err := svc.messages.AddReaction(ctx, reactionCmd("user_1", "👍"))
+	assert.ErrorIs(t, err, reaction.ErrUnavailable)
+
+	_, _, err = svc.messages.RemoveReaction(ctx, reactionCmd("user_1", "👍"), "user_1")
+	assert.ErrorIs(t, err, reaction.ErrUnavailable)
+
+	_, _, err = svc.messages.ListReactionUsers(ctx, reactionCmd("user_1", "👍"), "", 25)
+	assert.ErrorIs(t, err, reaction.ErrUnavailable)
+}
+
+func TestMessageService_RemoveReaction_OthersNeedManageMessages(t *testing.T) {
+	thumbs := reaction.Emoji{Name: "👍"}
+
+	t.Run("members cannot remove others' reactions", func(t *testing.T) {
+		svc, m, reactions := setupReactions(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_1", everyone)
+
+		_, _, err := svc.RemoveReaction(ctx, reactionCmd("user_1", "👍"), "user_2")
+
+		assert.ErrorIs(t, err, channel.ErrNoPermission)
+		reactions.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("members remove their own", func(t *testing.T) {
+		svc, m, reactions := setupReactions(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_1", everyone)
+		reactions.On("Remove", ctx, "cmsg_1", "user_1", thumbs).Return(true, nil)
+
+		_, removed, err := svc.RemoveReaction(ctx, reactionCmd("user_1", "👍"), "user_1")
+
+		require.NoError(t, err)
+		assert.True(t, removed)
+	})
+
+	t.Run("moderators remove others' reactions", func(t *testing.T) {
+		svc, m, reactions := setupReactions(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_mod", everyone, moderator)
+		reactions.On("Remove", ctx, "cmsg_1", "user_2", thumbs).Return(true, nil)
+
+		rc, _, err := svc.RemoveReaction(ctx, reactionCmd("user_mod", "👍"), "user_2")
+
+		require.NoError(t, err)
+		assert.Equal(t, "user_2", rc.UserID)
+	})
+}
+
+func TestMessageService_GetMessages_AttachesReactions(t *testing.T) {
+	svc, m, reactions := setupReactions(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.messageRepo.On("FindByChannelID", ctx, "chan_1", history.Cursor{Limit: 20}).Return([]*channel.ChannelMessage{
+		{ID: "cmsg_2", ChannelID: "chan_1"}, {ID: "cmsg_1", ChannelID: "chan_1"},
+	}, history.Page{}, nil)
+	reactions.On("Summarize", ctx, []string{"cmsg_2", "cmsg_1"}, "user_1").Return(map[string][]reaction.Summary{
+		"cmsg_1": {{Emoji: reaction.Emoji{Name: "👍"}, Count: 3, Me: true}},
+	}, nil)
+
+	messages, _, err := svc.GetMessages(ctx, GetMessagesCommand{ServerID: "serv_1", ChannelID: "chan_1", UserID: "user_1", Cursor: history.Cursor{Limit: 20}})
+
+	require.NoError(t, err)
+	assert.Empty(t, messages[0].Reactions)
+	assert.Equal(t, []reaction.Summary{{Emoji: reaction.Emoji{Name: "👍"}, Count: 3, Me: true}}, messages[1].Reactions)
+}
+
+func TestMessageService_GetMessages_AroundAnchor(t *testing.T) {
+	threadID := "thrd_1"
+
+	tests := []struct {
+		name    string
+		anchor  *channel.ChannelMessage
+		wantErr error
+	}{
+		{"message of the channel", &channel.ChannelMessage{ID: "cmsg_1", ChannelID: "chan_1"}, nil},
+		{"message of another channel", &channel.ChannelMessage{ID: "cmsg_1", ChannelID: "chan_2"}, channel.ErrMessageNotFound},
+		{"thread message", &channel.ChannelMessage{ID: "cmsg_1", ChannelID: "chan_1", ThreadID: &threadID}, channel.ErrMessageNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupThreadService(t)
+			ctx := context.Background()
+
+			cursor := history.Cursor{Around: "cmsg_1", Limit: 10}
+			m.asThreadMember(ctx, "user_1", everyone)
+			m.messageRepo.On("FindByID", ctx, "cmsg_1").Return(tt.anchor, nil)
+			m.messageRepo.On("FindByChannelID", ctx, "chan_1", cursor).
+				Return([]*channel.ChannelMessage{tt.anchor}, history.Page{Before: "cmsg_1"}, nil)
+
+			_, page, err := svc.messages.GetMessages(ctx, GetMessagesCommand{
+				ServerID: "serv_1", ChannelID: "chan_1", UserID: "user_1", Cursor: cursor,
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				m.messageRepo.AssertNotCalled(t, "FindByChannelID", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, history.Page{Before: "cmsg_1"}, page)
+		})
+	}
+}
+
+func TestMessageService_PinMessage(t *testing.T) {
+	threadID := "thrd_1"
+
+	tests := []struct {
+		name       string
+		userID     string
+		roles      []server.Role
+		messageID  string
+		saveErr    error
+		wantErr    error
+		wantPinned bool
+	}{
+		{"members cannot pin", "user_1", []server.Role{everyone}, "cmsg_1", nil, channel.ErrNoPermission, false},
+		{"moderators pin", "user_mod", []server.Role{everyone, moderator}, "cmsg_1", nil, nil, true},
+		{"already pinned", "user_mod", []server.Role{everyone, moderator}, "cmsg_pinned", nil, nil, false},
+		{"pin limit reached", "user_mod", []server.Role{everyone, moderator}, "cmsg_1", channel.ErrTooManyPins, channel.ErrTooManyPins, false},
+		{"thread message", "user_mod", []server.Role{everyone, moderator}, "cmsg_thread", nil, channel.ErrMessageNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m, _ := setupReactions(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, tt.userID, tt.roles...)
+			m.messageRepo.On("FindByID", ctx, "cmsg_pinned").Return(&channel.ChannelMessage{ID: "cmsg_pinned", ChannelID: "chan_1", ServerID: "serv_1", IsPinned: true}, nil)
+			m.messageRepo.On("FindByID", ctx, "cmsg_thread").Return(&channel.ChannelMessage{ID: "cmsg_thread", ChannelID: "chan_1", ServerID: "serv_1", ThreadID: &threadID}, nil)
+			m.messageRepo.On("UpdatePin", ctx, mock.MatchedBy(func(msg *channel.ChannelMessage) bool {
+				return msg.IsPinned && *msg.PinnedBy == tt.userID && msg.PinnedAt != nil
+			})).Return(tt.saveErr)
+			m.auditRepo.On("Create", ctx, mock.MatchedBy(func(l *server.AuditLog) bool {
+				return l.ActionType == server.AuditLogActionMessagePin && l.TargetID == "user_author" && l.Changes["message_id"] == "cmsg_1"
+			})).Return(nil)
+
+			_, pinned, err := svc.PinMessage(ctx, "serv_1", "chan_1", tt.messageID, tt.userID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantPinned, pinned)
+			if tt.wantPinned {
+				m.auditRepo.AssertNumberOfCalls(t, "Create", 1)
+			} else {
+				if tt.saveErr == nil {
+					m.messageRepo.AssertNotCalled(t, "UpdatePin", mock.Anything, mock.Anything)
+				}
+				m.auditRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestMessageService_UnpinMessage(t *testing.T) {
+	svc, m, _ := setupReactions(t)
+	ctx := context.Background()
+
+	pinnedAt := time.Now()
+	pinnedBy := "user_mod"
+	m.asThreadMember(ctx, "user_mod", everyone, moderator)
+	m.messageRepo.On("FindByID", ctx, "cmsg_pinned").Return(&channel.ChannelMessage{
+		ID: "cmsg_pinned", ChannelID: "chan_1", ServerID: "serv_1", AuthorID: "user_author",
+		IsPinned: true, PinnedAt: &pinnedAt, PinnedBy: &pinnedBy,
+	}, nil)
+	m.messageRepo.On("UpdatePin", ctx, mock.MatchedBy(func(msg *channel.ChannelMessage) bool {
+		return !msg.IsPinned && msg.PinnedAt == nil && msg.PinnedBy == nil
+	})).Return(nil)
+	m.auditRepo.On("Create", ctx, mock.MatchedBy(func(l *server.AuditLog) bool {
+		return l.ActionType == server.AuditLogActionMessageUnpin
+	})).Return(nil)
+
+	_, unpinned, err := svc.UnpinMessage(ctx, "serv_1", "chan_1", "cmsg_pinned", "user_mod")
+
+	require.NoError(t, err)
+	assert.True(t, unpinned)
+	m.auditRepo.AssertExpectations(t)
+}
+
+// setupAttachments returns the message service behind a thread service with
+// mocked media stores.
+func setupAttachments(t *testing.T) (*MessageService, *threadMocks, *testutil.MockMediaRepository, *testutil.MockMediaStorage) {
+	svc, m := setupThreadService(t)
+	mediaRepo := new(testutil.MockMediaRepository)
+	files := new(testutil.MockMediaStorage)
+	svc.messages.SetMediaStore(mediaRepo, files)
+	return svc.messages, m, mediaRepo, files
+}
+
+func TestMessageService_SendMessage_WithAttachments(t *testing.T) {
+	svc, m, mediaRepo, _ := setupAttachments(t)
+	ctx := context.Background()
+
+	a := &media.Media{ID: "med_a", UserID: "user_1", MimeType: "image/png"}
+	b := &media.Media{ID: "med_b", UserID: "user_1", MimeType: "application/pdf"}
+	m.asThreadMember(ctx, "user_1", everyone)
+	mediaRepo.On("FindByIDs", ctx, []string{"med_b", "med_a"}).Return([]*media.Media{a, b}, nil)
+	m.messageRepo.On("Create", ctx, mock.MatchedBy(func(msg *channel.ChannelMessage) bool {
+		return msg.Content == "" && len(msg.Attachments) == 2 && msg.Attachments[0] == b && msg.Attachments[1] == a
+	})).Return(nil)
+
+	msg, err := svc.SendMessage(ctx, SendMessageCommand{
+		ServerID: "serv_1", ChannelID: "chan_1", UserID: "user_1", AttachmentIDs: []string{"med_b", "med_a"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []*media.Media{b, a}, msg.Attachments)
+}
+
+func TestMessageService_SendMessage_AttachmentsRejected(t *testing.T) {
+	noFiles := server.Role{ID: "role_everyone", ServerID: "serv_1", IsDefault: true,
+		Permissions: server.PermissionDefaultEveryone &^ server.PermissionAttachFiles}
+
+	tests := []struct {
+		name    string
+		role    server.Role
+		owner   string
+		wantErr error
+	}{
+		{"without attach files", noFiles, "user_1", channel.ErrNoPermission},
+		{"someone else's upload", everyone, "user_2", media.ErrNoPermission},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m, mediaRepo, _ := setupAttachments(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, "user_1", tt.role)
+			mediaRepo.On("FindByIDs", ctx, []string{"med_a"}).Return([]*media.Media{{ID: "med_a", UserID: tt.owner}}, nil)
+
+			_, err := svc.SendMessage(ctx, SendMessageCommand{
+				ServerID: "serv_1", ChannelID: "chan_1", UserID: "user_1", Content: "look", AttachmentIDs: []string{"med_a"},
+			})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			m.messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMessageService_DeleteMessage_RemovesAttachments(t *testing.T) {
+	svc, m, mediaRepo, files := setupAttachments(t)
+	ctx := context.Background()
+
+	a := &media.Media{ID: "med_a", UserID: "user_1", Filename: "a.png"}
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.messageRepo.On("FindByID", ctx, "cmsg_1").Return(&channel.ChannelMessage{
+		ID: "cmsg_1", ChannelID: "chan_1", ServerID: "serv_1", AuthorID: "user_1", Attachments: []*media.Media{a},
+	}, nil)
+	m.messageRepo.On("Delete", ctx, "cmsg_1").Return(nil)
+	mediaRepo.On("DeleteUnattached", ctx, "med_a").Return(true, nil)
+	files.On("Remove", a).Return(nil)
+
+	err := svc.DeleteMessage(ctx, "serv_1", "chan_1", "cmsg_1", "user_1")
+
+	require.NoError(t, err)
+	mediaRepo.AssertExpectations(t)
+	files.AssertExpectations(t)
+}
+
+func TestMessageService_DeleteMessage_KeepsMediaOfPublishedCopies(t *testing.T) {
+	svc, m, mediaRepo, files := setupAttachments(t)
+	ctx := context.Background()
+
+	published := time.Now()
+	a := &media.Media{ID: "med_a", UserID: "user_1", Filename: "a.png"}
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.messageRepo.On("FindByID", ctx, "cmsg_1").Return(&channel.ChannelMessage{
+		ID: "cmsg_1", ChannelID: "chan_1", ServerID: "serv_1", AuthorID: "user_1", Attachments: []*media.Media{a},
+		CrosspostedAt: &published,
+	}, nil)
+	m.messageRepo.On("Delete", ctx, "cmsg_1").Return(nil)
+
+	// Copies in following channels still have the media attached
+	mediaRepo.On("DeleteUnattached", ctx, "med_a").Return(false, nil)
+
+	err := svc.DeleteMessage(ctx, "serv_1", "chan_1", "cmsg_1", "user_1")
+
+	require.NoError(t, err)
+	files.AssertNotCalled(t, "Remove", mock.Anything)
+}
+
+// setupSlowmode returns a thread service with a mocked slowmode limiter and
+// a ten second slowmode in chan_1.
+func setupSlowmode(t *testing.T) (*ThreadService, *threadMocks, *testutil.MockSlowmodeLimiter) {
+	svc, m := setupThreadService(t)
+	limiter := new(testutil.MockSlowmodeLimiter)
+	svc.messages.SetSlowmodeLimiter(limiter)
+
+	ch, _ := m.channelRepo.FindByID(context.Background(), "chan_1")
+	ch.SlowmodeSeconds = 10
+	return svc, m, limiter
+}
+
+func TestMessageService_SendMessage_Slowmode(t *testing.T) {
+	bypass := server.Role{ID: "role_bypass", ServerID: "serv_1", Position: 1, Permissions: server.PermissionBypassSlowmode}
+
+	tests := []struct {
+		name    string
+		roles   []server.Role
+		waiting bool
+	}{
+		{"member waits", []server.Role{everyone}, true},
+		{"moderator bypasses", []server.Role{everyone, moderator}, false},
+		{"bypass permission", []server.Role{everyone, bypass}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m, limiter := setupSlowmode(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, "user_1", tt.roles...)
+			limiter.On("Acquire", ctx, "chan_1", "user_1", 10*time.Second).Return(4*time.Second, nil)
+			m.messageRepo.On("Create", ctx, mock.AnythingOfType("*channel.ChannelMessage")).Return(nil)
+
+			_, err := svc.messages.SendMessage(ctx, SendMessageCommand{ServerID: "serv_1", ChannelID: "chan_1", UserID: "user_1", Content: "hi"})
+
+			if !tt.waiting {
+				require.NoError(t, err)
+				limiter.AssertNotCalled(t, "Acquire", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			var cooldown *slowmode.CooldownError
+			require.ErrorAs(t, err, &cooldown)
+			assert.Equal(t, 4*time.Second, cooldown.RetryAfter)
+			m.messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMessageService_SendMessage_FailedSaveEndsCooldown(t *testing.T) {
+	svc, m, limiter := setupSlowmode(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	limiter.On("Acquire", ctx, "chan_1", "user_1", 10*time.Second).Return(time.Duration(0), nil)
+	limiter.On("Release", ctx, "chan_1", "user_1").Return(nil)
+	m.messageRepo.On("Create", ctx, mock.AnythingOfType("*channel.ChannelMessage")).Return(errors.New("db down"))
+
+	_, err := svc.messages.SendMessage(ctx, SendMessageCommand{ServerID: "serv_1", ChannelID: "chan_1", UserID: "user_1", Content: "hi"})
+
+	require.Error(t, err)
+	limiter.AssertExpectations(t)
+}
+
+// setupRevisions returns the message service behind a thread service with a
+// mocked revision repository and message cmsg_1 by user_author in chan_1.
+func setupRevisions(t *testing.T) (*MessageService, *threadMocks, *testutil.MockMessageRevisionRepository) {
+	svc, m := setupThreadService(t)
+	revisions := new(testutil.MockMessageRevisionRepository)
+	svc.messages.SetRevisionRepository(revisions)
+
+	m.messageRepo.On("FindByID", mock.Anything, "cmsg_1").Return(&channel.ChannelMessage{
+		ID: "cmsg_1", ChannelID: "chan_1", ServerID: "serv_1", AuthorID: "user_author", Content: "first",
+	}, nil)
+	return svc.messages, m, revisions
+}
+
+func TestMessageService_EditMessage_RecordsRevision(t *testing.T) {
+	t.Run("keeps the replaced content", func(t *testing.T) {
+		svc, m, revisions := setupRevisions(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_author", everyone)
+		revisions.On("Create", ctx, mock.MatchedBy(func(rev *revision.Revision) bool {
+			return rev.MessageID == "cmsg_1" && rev.Kind == revision.KindChannel && rev.Content == "first"
+		})).Return(nil)
+		m.messageRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+		msg, err := svc.EditMessage(ctx, "serv_1", "chan_1", "cmsg_1", "user_author", "second")
+
+		require.NoError(t, err)
+		assert.Equal(t, "second", msg.Content)
+		revisions.AssertExpectations(t)
+	})
+
+	t.Run("unchanged content is not a revision", func(t *testing.T) {
+		svc, m, revisions := setupRevisions(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_author", everyone)
+		m.messageRepo.On("Update", ctx, mock.Anything).Return(nil)
+
+		_, err := svc.EditMessage(ctx, "serv_1", "chan_1", "cmsg_1", "user_author", "first")
+
+		require.NoError(t, err)
+		revisions.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("edit fails if history cannot be kept", func(t *testing.T) {
+		svc, m, revisions := setupRevisions(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_author", everyone)
+		revisions.On("Create", ctx, mock.Anything).Return(assert.AnError)
+
+		_, err := svc.EditMessage(ctx, "serv_1", "chan_1", "cmsg_1", "user_author", "second")
+
+		assert.ErrorIs(t, err, assert.AnError)
+		m.messageRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestMessageService_GetRevisions(t *testing.T) {
+	history := []*revision.Revision{{ID: "rev_1", MessageID: "cmsg_1", Kind: revision.KindChannel, Content: "first"}}
+
+	tests := []struct {
+		name    string
+		userID  string
+		roles   []server.Role
+		wantErr error
+	}{
+		{"author", "user_author", []server.Role{everyone}, nil},
+		{"moderator", "user_mod", []server.Role{everyone, moderator}, nil},
+		{"other member", "user_1", []server.Role{everyone}, channel.ErrNoPermission},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m, revisions := setupRevisions(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, tt.userID, tt.roles...)
+			revisions.On("FindByMessageID", ctx, "cmsg_1").Return(history, nil)
+
+			got, err := svc.GetRevisions(ctx, "serv_1", "chan_1", "cmsg_1", tt.userID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				revisions.AssertNotCalled(t, "FindByMessageID", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, history, got)
+		})
+	}
+}
+
+func TestMessageService_BulkDeleteMessages(t *testing.T) {
+	svc, m, mediaRepo, files := setupAttachments(t)
+	ctx := context.Background()
+
+	a := &media.Media{ID: "med_a", UserID: "user_spam"}
+	m.asThreadMember(ctx, "user_mod", everyone, moderator)
+	m.messageRepo.On("DeleteMatching", ctx, channel.BulkDeleteFilter{ChannelID: "chan_1", AuthorID: "user_spam", HasLinks: true, Limit: 50}).
+		Return([]*channel.ChannelMessage{
+			{ID: "cmsg_2", ChannelID: "chan_1", AuthorID: "user_spam", Attachments: []*media.Media{a}},
+			{ID: "cmsg_1", ChannelID: "chan_1", AuthorID: "user_spam"},
+		}, nil)
+	mediaRepo.On("DeleteUnattached", ctx, "med_a").Return(true, nil)
+	files.On("Remove", a).Return(nil)
+	m.auditRepo.On("Create", ctx, mock.MatchedBy(func(l *server.AuditLog) bool {
+		return l.ActionType == server.AuditLogActionMessageBulkDelete && l.TargetID == "chan_1" &&
+			l.Changes["count"] == 2 && l.Changes["author_id"] == "user_spam"
+	})).Return(nil)
+
+	deleted, err := svc.BulkDeleteMessages(ctx, BulkDeleteCommand{
+		ServerID: "serv_1", ChannelID: "chan_1", UserID: "user_mod",
+		Filter: channel.BulkDeleteFilter{AuthorID: "user_spam", HasLinks: true, Limit: 50},
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+	m.auditRepo.AssertNumberOfCalls(t, "Create", 1)
+	files.AssertExpectations(t)
+}
+
+func TestMessageService_BulkDeleteMessages_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		roles   []server.Role
+		limit   int
+		wantErr error
+	}{
+		{"without ManageMessages", []server.Role{everyone}, 10, channel.ErrNoPermission},
+		{"over the limit", []server.Role{everyone, moderator}, channel.MaxBulkDelete + 1, channel.ErrInvalidBulkDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupThreadService(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, "user_1", tt.roles...)
+
+			_, err := svc.messages.BulkDeleteMessages(ctx, BulkDeleteCommand{
+				ServerID: "serv_1", ChannelID: "chan_1", UserID: "user_1",
+				Filter: channel.BulkDeleteFilter{Limit: tt.limit},
+			})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			m.messageRepo.AssertNotCalled(t, "DeleteMatching", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// hiddenStaff hides chan_staff from members with only @everyone.
+var hiddenStaff = map[string][]channel.PermissionOverwrite{
+	"chan_staff": {
+		{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionViewChannel},
+	},
+}
+
+// setupSearch creates a message service for serv_1 whose channels have the
+// given overwrites.
+func setupSearch(t *testing.T, overwrites map[string][]channel.PermissionOverwrite) (*MessageService, *threadMocks) {
+	_, sm := setupService(t)
+	m := &threadMocks{serviceMocks: sm, messageRepo: new(testutil.MockChannelMessageRepository)}
+
+	resolver := permission.NewResolver(permission.NewEngine(), m.serverRepo, m.memberRepo, m.roleRepo, m.channelRepo, m.overwriteRepo)
+	m.overwriteRepo.On("FindByChannelIDs", mock.Anything, mock.Anything).Return(overwrites, nil)
+	return NewMessageService(m.messageRepo, m.channelRepo, m.memberRepo, m.serverRepo, m.auditRepo, resolver), m
+}
+
+func TestMessageService_SearchServerMessages_OnlyVisibleChannels(t *testing.T) {
+	svc, m := setupSearch(t, hiddenStaff)
+	ctx := context.Background()
+
+	category := &channel.Channel{ID: "chan_cat", ServerID: "serv_1", Type: channel.TypeCategory}
+	general := &channel.Channel{ID: "chan_general", ServerID: "serv_1", Name: "general", Type: channel.TypeText, ParentID: &category.ID}
+	random := &channel.Channel{ID: "chan_random", ServerID: "serv_1", Name: "random", Type: channel.TypeText}
+	staff := &channel.Channel{ID: "chan_staff", ServerID: "serv_1", Name: "staff", Type: channel.TypeText}
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.channelRepo.On("FindByServerID", ctx, "serv_1").Return([]*channel.Channel{category, general, random, staff}, nil)
+	hit := &channel.MessageSearchHit{
+		Message: &channel.ChannelMessage{ID: "cmsg_1", ChannelID: "chan_general"},
+		Snippet: "the <mark>deploy</mark> is done",
+	}
+	m.messageRepo.On("SearchServer", ctx, mock.MatchedBy(func(s channel.MessageSearch) bool {
+		return assert.ObjectsAreEqual([]string{"chan_general", "chan_random"}, s.ChannelIDs) &&
+			s.Query.Text == "deploy" && s.Limit == 25 && s.Offset == 0
+	})).Return([]*channel.MessageSearchHit{hit}, 1, nil)
+
+	hits, total, err := svc.SearchServerMessages(ctx, SearchServerCommand{
+		ServerID: "serv_1", UserID: "user_1", Query: "deploy", Offset: -5, Limit: 500,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []*channel.MessageSearchHit{hit}, hits)
+	assert.Equal(t, 1, total)
+}
+
+func TestMessageService_SearchServerMessages_InHiddenChannel(t *testing.T) {
+	svc, m := setupSearch(t, hiddenStaff)
+	ctx := context.Background()
+
+	staff := &channel.Channel{ID: "chan_staff", ServerID: "serv_1", Name: "staff", Type: channel.TypeText}
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.channelRepo.On("FindByServerID", ctx, "serv_1").Return([]*channel.Channel{staff}, nil)
+
+	hits, total, err := svc.SearchServerMessages(ctx, SearchServerCommand{
+		ServerID: "serv_1", UserID: "user_1", Query: "in:#staff salaries",
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+	assert.Zero(t, total)
+	m.messageRepo.AssertNotCalled(t, "SearchServer", mock.Anything, mock.Anything)
+}
+
+func TestMessageService_SearchServerMessages_InvalidQuery(t *testing.T) {
+	svc, m := setupThreadService(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+
+	_, _, err := svc.messages.SearchServerMessages(ctx, SearchServerCommand{
+		ServerID: "serv_1", UserID: "user_1", Query: "has:video",
+	})
+
+	assert.ErrorIs(t, err, channel.ErrInvalidSearch)
+}
+
+func TestMessageService_SearchServerMessages_OffsetTooDeep(t *testing.T) {
+	svc, m := setupThreadService(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+
+	_, _, err := svc.messages.SearchServerMessages(ctx, SearchServerCommand{
+		ServerID: "serv_1", UserID: "user_1", Query: "hello", Offset: channel.MaxSearchOffset + 1,
+	})
+
+	assert.ErrorIs(t, err, channel.ErrInvalidSearch)
+	m.messageRepo.AssertNotCalled(t, "SearchServer", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,371 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"pink/internal/domain/channel"
+	"pink/internal/domain/media"
+	"pink/internal/domain/notification"
+	"pink/internal/domain/server"
+	"pink/internal/domain/slowmode"
+	"pink/internal/domain/ws"
+	"pink/internal/pkg/id"
+	"pink/internal/pkg/periodic"
+)
+
+// publishBatchSize is how many due messages one sweep publishes.
+const publishBatchSize = 100
+
+// settleTimeout bounds saving the outcome of one publish. It does not share
+// the sweep's deadline, so a sweep running out of time does not leave a
+// message claimed until its lease ends.
+const settleTimeout = 5 * time.Second
+
+// publishFailures are the errors a scheduled message can fail with that its
+// author can act on; their text is shown as the failure. Anything else is
+// reported as an internal error.
+var publishFailures = []error{
+	server.ErrNotMember,
+	channel.ErrNotFound,
+	channel.ErrNoPermission,
+	channel.ErrInvalidContent,
+	media.ErrNotFound,
+	media.ErrNoPermission,
+	media.ErrAlreadyAttached,
+	media.ErrTooManyAttachments,
+}
+
+// ScheduledMessageService queues channel messages to be sent later and
+// publishes them through MessageService.SendMessage once they are due.
+type ScheduledMessageService struct {
+	messages  *MessageService
+	scheduled channel.ScheduledMessageRepository
+	events    ws.ChannelEventPublisher
+	notifier  notification.Dispatcher
+}
+
+// NewScheduledMessageService creates a new ScheduledMessageService.
+func NewScheduledMessageService(messages *MessageService, scheduled channel.ScheduledMessageRepository) *ScheduledMessageService {
+	return &ScheduledMessageService{
+		messages:  messages,
+		scheduled: scheduled,
+	}
+}
+
+// SetEventPublisher sets the publisher that broadcasts published messages to
+// the channel.
+func (s *ScheduledMessageService) SetEventPublisher(events ws.ChannelEventPublisher) {
+	s.events = events
+}
+
+// SetNotifier sets the dispatcher that tells authors their message failed.
+func (s *ScheduledMessageService) SetNotifier(notifier notification.Dispatcher) {
+	s.notifier = notifier
+}
+
+// ScheduleMessageCommand represents a request to send a message later.
+type ScheduleMessageCommand struct {
+	ServerID      string
+	ChannelID     string
+	UserID        string
+	Content       string
+	AttachmentIDs []string // Media uploaded by the sender, in display order
+	PublishAt     time.Time
+}
+
+// Schedule queues a message for a text or announcement channel. The author
+// must be allowed to send it now; permissions are checked again when it is
+// published.
+func (s *ScheduledMessageService) Schedule(ctx context.Context, cmd ScheduleMessageCommand) (*channel.ScheduledMessage, error) {
+	ch, err := s.sendableChannel(ctx, cmd.ServerID, cmd.ChannelID, cmd.UserID, len(cmd.AttachmentIDs) > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := channel.ValidatePublishAt(cmd.PublishAt, now); err != nil {
+		return nil, err
+	}
+	if !validContent(cmd.Content, len(cmd.AttachmentIDs)) {
+		return nil, channel.ErrInvalidContent
+	}
+	if _, err := s.messages.resolveAttachments(ctx, cmd.AttachmentIDs, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.scheduled.CountByChannel(ctx, ch.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= channel.MaxScheduledPerChannel {
+		return nil, channel.ErrTooManyScheduled
+	}
+
+	msg := &channel.ScheduledMessage{
+		ID:            id.Generate("schd"),
+		ServerID:      cmd.ServerID,
+		ChannelID:     ch.ID,
+		AuthorID:      cmd.UserID,
+		Content:       cmd.Content,
+		AttachmentIDs: cmd.AttachmentIDs,
+		PublishAt:     cmd.PublishAt,
+		Status:        channel.ScheduledPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.scheduled.Create(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// List returns the user's scheduled messages in a server, failed ones
+// included, next to be published first.
+func (s *ScheduledMessageService) List(ctx context.Context, serverID, userID string) ([]*channel.ScheduledMessage, error) {
+	if err := s.messages.requireMembership(ctx, serverID, userID); err != nil {
+		return nil, err
+	}
+	return s.scheduled.FindByAuthor(ctx, serverID, userID)
+}
+
+// EditScheduledCommand represents a change to a scheduled message. Nil
+// fields are left unchanged.
+type EditScheduledCommand struct {
+	ServerID      string
+	ScheduledID   string
+	UserID        string
+	Content       *string
+	AttachmentIDs *[]string
+	PublishAt     *time.Time
+}
+
+// Edit changes a pending or failed scheduled message. A failed message is
+// queued again, which needs a publish time in the future.
+func (s *ScheduledMessageService) Edit(ctx context.Context, cmd EditScheduledCommand) (*channel.ScheduledMessage, error) {
+	msg, err := s.ownScheduled(ctx, cmd.ServerID, cmd.ScheduledID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Content != nil {
+		msg.Content = *cmd.Content
+	}
+	if cmd.AttachmentIDs != nil {
+		msg.AttachmentIDs = *cmd.AttachmentIDs
+	}
+	publishAt := msg.PublishAt
+	if cmd.PublishAt != nil {
+		publishAt = *cmd.PublishAt
+	}
+
+	if _, err := s.sendableChannel(ctx, msg.ServerID, msg.ChannelID, cmd.UserID, len(msg.AttachmentIDs) > 0); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := channel.ValidatePublishAt(publishAt, now); err != nil {
+		return nil, err
+	}
+	if !validContent(msg.Content, len(msg.AttachmentIDs)) {
+		return nil, channel.ErrInvalidContent
+	}
+	if _, err := s.messages.resolveAttachments(ctx, msg.AttachmentIDs, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	msg.Reschedule(publishAt, now)
+	if err := s.scheduled.Update(ctx, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Cancel deletes a pending or failed scheduled message.
+func (s *ScheduledMessageService) Cancel(ctx context.Context, serverID, scheduledID, userID string) error {
+	msg, err := s.ownScheduled(ctx, serverID, scheduledID, userID)
+	if err != nil {
+		return err
+	}
+	return s.scheduled.Delete(ctx, msg.ID)
+}
+
+// PublishDue sends the scheduled messages that are due, returning how many
+// were published. Each is sent as its author, so membership, channel
+// permissions, attachments and slowmode are checked again. Messages held
+// back by slowmode are tried again on a later sweep; other failures are kept
+// on the message and the author is notified.
+func (s *ScheduledMessageService) PublishDue(ctx context.Context) (int, error) {
+	due, err := s.scheduled.ClaimDue(ctx, time.Now(), publishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, msg := range due {
+		if s.publish(ctx, msg) {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// publish sends one claimed message and reports whether it went out.
+func (s *ScheduledMessageService) publish(ctx context.Context, scheduled *channel.ScheduledMessage) bool {
+	msg, err := s.messages.SendMessage(ctx, SendMessageCommand{
+		ServerID:      scheduled.ServerID,
+		ChannelID:     scheduled.ChannelID,
+		UserID:        scheduled.AuthorID,
+		Content:       scheduled.Content,
+		AttachmentIDs: scheduled.AttachmentIDs,
+	})
+	if err != nil {
+		s.release(ctx, scheduled, err)
+		return false
+	}
+
+	// The message is out. A row left behind would be sent again once its
+	// claim runs out, so this does not stop with the sweep.
+	settleCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+	defer cancel()
+	if err := s.scheduled.MarkPublished(settleCtx, scheduled.ID); err != nil {
+		slog.Warn("failed to delete published scheduled message", slog.Any("error", err), slog.String("scheduled_id", scheduled.ID))
+	}
+
+	if s.events != nil {
+		s.events.PublishChannelEvent(ws.ChannelEvent{
+			Type:      ws.EventChannelMessage,
+			ServerID:  msg.ServerID,
+			ChannelID: msg.ChannelID,
+			ActorID:   msg.AuthorID,
+			Payload:   msg,
+		})
+	}
+	return true
+}
+
+// release hands a message that could not be sent back to its author, or
+// back to the queue when only slowmode held it back.
+func (s *ScheduledMessageService) release(ctx context.Context, scheduled *channel.ScheduledMessage, sendErr error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+	defer cancel()
+
+	now := time.Now()
+	if errors.Is(sendErr, slowmode.ErrCooldown) {
+		scheduled.Status = channel.ScheduledPending
+		scheduled.UpdatedAt = now
+	} else {
+		scheduled.Fail(publishFailure(sendErr), now)
+		slog.Warn("scheduled message failed", slog.Any("error", sendErr), slog.String("scheduled_id", scheduled.ID))
+	}
+
+	if err := s.scheduled.Release(ctx, scheduled); err != nil {
+		slog.Error("failed to release scheduled message", slog.Any("error", err), slog.String("scheduled_id", scheduled.ID))
+		return
+	}
+	if scheduled.Status == channel.ScheduledFailed {
+		s.notifyFailure(ctx, scheduled)
+	}
+}
+
+// notifyFailure tells the author their message was not published. Failures
+// are logged: the failure is kept on the message either way.
+func (s *ScheduledMessageService) notifyFailure(ctx context.Context, scheduled *channel.ScheduledMessage) {
+	if s.notifier == nil {
+		return
+	}
+	notif := &notification.Notification{
+		UserID:     scheduled.AuthorID,
+		Type:       notification.TypeSystem,
+		TargetType: stringPtr("scheduled_message"),
+		TargetID:   &scheduled.ID,
+		Message:    "your scheduled message could not be published: " + scheduled.Failure,
+	}
+	if err := s.notifier.Dispatch(ctx, notif); err != nil {
+		slog.Warn("failed to dispatch scheduled message failure", slog.Any("error", err), slog.String("scheduled_id", scheduled.ID))
+	}
+}
+
+// publishFailure describes why a message could not be sent.
+func publishFailure(err error) string {
+	for _, known := range publishFailures {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return "internal error"
+}
+
+// sendableChannel loads a text or announcement channel of the server that
+// the user can send messages in.
+func (s *ScheduledMessageService) sendableChannel(ctx context.Context, serverID, channelID, userID string, attaching bool) (*channel.Channel, error) {
+	if err := s.messages.requireMembership(ctx, serverID, userID); err != nil {
+		return nil, err
+	}
+
+	ch, err := s.messages.channelRepo.FindByID(ctx, channelID)
+	if err != nil || ch == nil || ch.ServerID != serverID {
+		return nil, channel.ErrNotFound
+	}
+	if ch.Type != channel.TypeText && ch.Type != channel.TypeAnnouncement {
+		return nil, channel.ErrInvalidType
+	}
+
+	if _, err := s.messages.canSendMessage(ctx, ch, userID, attaching); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// ownScheduled loads one of the user's scheduled messages in the server that
+// can still be changed. Other users' messages are not found.
+func (s *ScheduledMessageService) ownScheduled(ctx context.Context, serverID, scheduledID, userID string) (*channel.ScheduledMessage, error) {
+	msg, err := s.scheduled.FindByID(ctx, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.ServerID != serverID || msg.AuthorID != userID {
+		return nil, channel.ErrScheduledNotFound
+	}
+	if !msg.IsEditable() {
+		return nil, channel.ErrScheduledPublished
+	}
+	return msg, nil
+}
+
+// ScheduledMessageWorker periodically publishes scheduled messages that are
+// due.
+type ScheduledMessageWorker struct {
+	scheduled *ScheduledMessageService
+	interval  time.Duration
+}
+
+// NewScheduledMessageWorker creates a new scheduled message worker.
+func NewScheduledMessageWorker(scheduled *ScheduledMessageService, interval time.Duration) *ScheduledMessageWorker {
+	return &ScheduledMessageWorker{
+		scheduled: scheduled,
+		interval:  interval,
+	}
+}
+
+// Start runs the sweep until ctx is cancelled.
+func (w *ScheduledMessageWorker) Start(ctx context.Context) {
+	periodic.Run(ctx, "scheduled message worker", w.interval, w.Sweep)
+}
+
+// Sweep publishes the scheduled messages that are due.
+func (w *ScheduledMessageWorker) Sweep(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	published, err := w.scheduled.PublishDue(ctx)
+	if err != nil {
+		slog.Error("scheduled message sweep failed", slog.Any("error", err))
+		return
+	}
+	if published > 0 {
+		slog.Info("scheduled message sweep", slog.Int("published", published))
+	}
+}
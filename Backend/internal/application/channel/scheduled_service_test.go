@@ -0,0 +1,283 @@
+package channel
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/testutil"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/notification"
+	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
+)
+
+type scheduledMocks struct {
+	*threadMocks
+	scheduled *testutil.MockScheduledMessageRepository
+	notifier  *recordingDispatcher
+}
+
+// setupScheduled creates a scheduled message service over the thread
+// service's mocks, with announcement channel chan_news and hybrid channel
+// chan_hybrid next to text channel chan_1.
+func setupScheduled(t *testing.T) (*ScheduledMessageService, *scheduledMocks) {
+	threads, tm := setupThreadService(t)
+	m := &scheduledMocks{
+		threadMocks: tm,
+		scheduled:   new(testutil.MockScheduledMessageRepository),
+		notifier:    &recordingDispatcher{},
+	}
+
+	svc := NewScheduledMessageService(threads.messages, m.scheduled)
+	svc.SetEventPublisher(m.events)
+	svc.SetNotifier(m.notifier)
+
+	m.channelRepo.On("FindByID", mock.Anything, "chan_news").Return(&channel.Channel{ID: "chan_news", ServerID: "serv_1", Type: channel.TypeAnnouncement}, nil)
+	m.channelRepo.On("FindByID", mock.Anything, "chan_hybrid").Return(&channel.Channel{ID: "chan_hybrid", ServerID: "serv_1", Type: channel.TypeHybrid}, nil)
+	return svc, m
+}
+
+// withScheduled stores a scheduled message by user_1 in chan_1.
+func (m *scheduledMocks) withScheduled(ctx context.Context, msg channel.ScheduledMessage) *channel.ScheduledMessage {
+	msg.ServerID = "serv_1"
+	msg.ChannelID = "chan_1"
+	if msg.AuthorID == "" {
+		msg.AuthorID = "user_1"
+	}
+	if msg.PublishAt.IsZero() {
+		msg.PublishAt = time.Now().Add(time.Hour)
+	}
+	m.scheduled.On("FindByID", ctx, msg.ID).Return(&msg, nil)
+	return &msg
+}
+
+var channelManager = server.Role{ID: "role_manager", ServerID: "serv_1", Position: 1, Permissions: server.PermissionManageChannels}
+
+func TestScheduledMessageService_Schedule(t *testing.T) {
+	svc, m := setupScheduled(t)
+	ctx := context.Background()
+
+	publishAt := time.Now().Add(2 * time.Hour)
+	m.asThreadMember(ctx, "user_1", everyone, channelManager)
+	m.scheduled.On("CountByChannel", ctx, "chan_news").Return(3, nil)
+	m.scheduled.On("Create", ctx, mock.MatchedBy(func(msg *channel.ScheduledMessage) bool {
+		return msg.ChannelID == "chan_news" && msg.AuthorID == "user_1" && msg.PublishAt.Equal(publishAt)
+	})).Return(nil)
+
+	msg, err := svc.Schedule(ctx, ScheduleMessageCommand{
+		ServerID: "serv_1", ChannelID: "chan_news", UserID: "user_1", Content: "Launch day!", PublishAt: publishAt,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(msg.ID, "schd_"))
+	assert.Equal(t, channel.ScheduledPending, msg.Status)
+	m.scheduled.AssertExpectations(t)
+}
+
+func TestScheduledMessageService_Schedule_Rejected(t *testing.T) {
+	readOnly := server.Role{ID: "role_everyone", ServerID: "serv_1", IsDefault: true, Permissions: server.PermissionViewChannel}
+	inAnHour := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		channelID string
+		role      server.Role
+		publishAt time.Time
+		count     int
+		wantErr   error
+	}{
+		{"without send messages", "chan_1", readOnly, inAnHour, 0, channel.ErrNoPermission},
+		{"announcement without manage channels", "chan_news", everyone, inAnHour, 0, channel.ErrNoPermission},
+		{"hybrid channel", "chan_hybrid", everyone, inAnHour, 0, channel.ErrInvalidType},
+		{"in the past", "chan_1", everyone, time.Now().Add(-time.Minute), 0, channel.ErrInvalidSchedule},
+		{"too far ahead", "chan_1", everyone, time.Now().Add(channel.MaxScheduleAhead + time.Hour), 0, channel.ErrInvalidSchedule},
+		{"channel queue full", "chan_1", everyone, inAnHour, channel.MaxScheduledPerChannel, channel.ErrTooManyScheduled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupScheduled(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, "user_1", tt.role)
+			m.scheduled.On("CountByChannel", ctx, tt.channelID).Return(tt.count, nil)
+
+			_, err := svc.Schedule(ctx, ScheduleMessageCommand{
+				ServerID: "serv_1", ChannelID: tt.channelID, UserID: "user_1", Content: "soon", PublishAt: tt.publishAt,
+			})
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			m.scheduled.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestScheduledMessageService_Edit_ReschedulesFailed(t *testing.T) {
+	svc, m := setupScheduled(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.withScheduled(ctx, channel.ScheduledMessage{
+		ID: "schd_1", Content: "old", Status: channel.ScheduledFailed, Failure: "no permission to access channel",
+		PublishAt: time.Now().Add(-time.Hour),
+	})
+	m.scheduled.On("Update", ctx, mock.AnythingOfType("*channel.ScheduledMessage")).Return(nil)
+
+	content := "new"
+	publishAt := time.Now().Add(time.Hour)
+	msg, err := svc.Edit(ctx, EditScheduledCommand{
+		ServerID: "serv_1", ScheduledID: "schd_1", UserID: "user_1", Content: &content, PublishAt: &publishAt,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "new", msg.Content)
+	assert.Equal(t, channel.ScheduledPending, msg.Status)
+	assert.Empty(t, msg.Failure)
+	m.scheduled.AssertExpectations(t)
+}
+
+func TestScheduledMessageService_EditAndCancel_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  string
+		status  channel.ScheduledStatus
+		wantErr error
+	}{
+		{"someone else's message", "user_2", channel.ScheduledPending, channel.ErrScheduledNotFound},
+		{"already publishing", "user_1", channel.ScheduledPublishing, channel.ErrScheduledPublished},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupScheduled(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, tt.userID, everyone)
+			m.withScheduled(ctx, channel.ScheduledMessage{ID: "schd_1", Content: "soon", Status: tt.status})
+
+			content := "changed"
+			_, err := svc.Edit(ctx, EditScheduledCommand{ServerID: "serv_1", ScheduledID: "schd_1", UserID: tt.userID, Content: &content})
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			err = svc.Cancel(ctx, "serv_1", "schd_1", tt.userID)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			m.scheduled.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			m.scheduled.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestScheduledMessageService_PublishDue(t *testing.T) {
+	svc, m := setupScheduled(t)
+	ctx := context.Background()
+
+	due := &channel.ScheduledMessage{
+		ID: "schd_1", ServerID: "serv_1", ChannelID: "chan_1", AuthorID: "user_1",
+		Content: "Good morning", Status: channel.ScheduledPublishing,
+	}
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.scheduled.On("ClaimDue", ctx, mock.AnythingOfType("time.Time"), publishBatchSize).Return([]*channel.ScheduledMessage{due}, nil)
+	m.messageRepo.On("Create", ctx, mock.MatchedBy(func(msg *channel.ChannelMessage) bool {
+		return msg.AuthorID == "user_1" && msg.Content == "Good morning"
+	})).Return(nil)
+	m.scheduled.On("MarkPublished", mock.Anything, "schd_1").Return(nil)
+
+	published, err := svc.PublishDue(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, []ws.EventType{ws.EventChannelMessage}, m.events.types())
+	msg, ok := m.events.events[0].Payload.(*channel.ChannelMessage)
+	require.True(t, ok)
+	assert.Equal(t, "Good morning", msg.Content)
+	assert.Empty(t, m.notifier.notifications)
+	m.scheduled.AssertExpectations(t)
+}
+
+func TestScheduledMessageService_PublishDue_PermissionLost(t *testing.T) {
+	svc, m := setupScheduled(t)
+	ctx := context.Background()
+
+	readOnly := server.Role{ID: "role_everyone", ServerID: "serv_1", IsDefault: true, Permissions: server.PermissionViewChannel}
+	due := &channel.ScheduledMessage{
+		ID: "schd_1", ServerID: "serv_1", ChannelID: "chan_1", AuthorID: "user_1",
+		Content: "Good morning", Status: channel.ScheduledPublishing,
+	}
+	m.asThreadMember(ctx, "user_1", readOnly)
+	m.scheduled.On("ClaimDue", ctx, mock.AnythingOfType("time.Time"), publishBatchSize).Return([]*channel.ScheduledMessage{due}, nil)
+	m.scheduled.On("Release", mock.Anything, mock.MatchedBy(func(msg *channel.ScheduledMessage) bool {
+		return msg.Status == channel.ScheduledFailed && msg.Failure == channel.ErrNoPermission.Error()
+	})).Return(nil)
+
+	published, err := svc.PublishDue(ctx)
+
+	require.NoError(t, err)
+	assert.Zero(t, published)
+	assert.Empty(t, m.events.events)
+	require.Len(t, m.notifier.notifications, 1)
+	n := m.notifier.notifications[0]
+	assert.Equal(t, "user_1", n.UserID)
+	assert.Equal(t, notification.TypeSystem, n.Type)
+	assert.Equal(t, "schd_1", *n.TargetID)
+	m.messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	m.scheduled.AssertNotCalled(t, "MarkPublished", mock.Anything, mock.Anything)
+	m.scheduled.AssertExpectations(t)
+}
+
+func TestScheduledMessageService_PublishDue_ReleasesAfterSweepDeadline(t *testing.T) {
+	svc, m := setupScheduled(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	readOnly := server.Role{ID: "role_everyone", ServerID: "serv_1", IsDefault: true, Permissions: server.PermissionViewChannel}
+	due := &channel.ScheduledMessage{
+		ID: "schd_1", ServerID: "serv_1", ChannelID: "chan_1", AuthorID: "user_1",
+		Content: "Good morning", Status: channel.ScheduledPublishing,
+	}
+	m.asThreadMember(ctx, "user_1", readOnly)
+	m.scheduled.On("ClaimDue", ctx, mock.AnythingOfType("time.Time"), publishBatchSize).Run(func(mock.Arguments) {
+		cancel()
+	}).Return([]*channel.ScheduledMessage{due}, nil)
+	m.scheduled.On("Release", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Err() == nil
+	}), mock.Anything).Return(nil)
+
+	_, err := svc.PublishDue(ctx)
+
+	require.NoError(t, err)
+	m.scheduled.AssertExpectations(t)
+}
+
+func TestScheduledMessageService_PublishDue_SlowmodeRetries(t *testing.T) {
+	svc, m := setupScheduled(t)
+	ctx := context.Background()
+
+	limiter := new(testutil.MockSlowmodeLimiter)
+	svc.messages.SetSlowmodeLimiter(limiter)
+	ch, _ := m.channelRepo.FindByID(ctx, "chan_1")
+	ch.SlowmodeSeconds = 10
+
+	due := &channel.ScheduledMessage{
+		ID: "schd_1", ServerID: "serv_1", ChannelID: "chan_1", AuthorID: "user_1",
+		Content: "Good morning", Status: channel.ScheduledPublishing,
+	}
+	m.asThreadMember(ctx, "user_1", everyone)
+	limiter.On("Acquire", ctx, "chan_1", "user_1", 10*time.Second).Return(4*time.Second, nil)
+	m.scheduled.On("ClaimDue", ctx, mock.AnythingOfType("time.Time"), publishBatchSize).Return([]*channel.ScheduledMessage{due}, nil)
+	m.scheduled.On("Release", mock.Anything, mock.MatchedBy(func(msg *channel.ScheduledMessage) bool {
+		return msg.Status == channel.ScheduledPending && msg.Failure == ""
+	})).Return(nil)
+
+	published, err := svc.PublishDue(ctx)
+
+	require.NoError(t, err)
+	assert.Zero(t, published)
+	assert.Empty(t, m.notifier.notifications)
+	m.scheduled.AssertExpectations(t)
+}
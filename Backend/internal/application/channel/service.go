@@ -3,11 +3,16 @@ package channel
 
 import (
 	"context"
+	"log/slog"
+	"strings"
 	"time"
 
+	permissionApp "pink/internal/application/permission"
 	"pink/internal/domain/channel"
 	"pink/internal/domain/readstate"
 	"pink/internal/domain/server"
+	"pink/internal/domain/slowmode"
+	"pink/internal/domain/ws"
 	"pink/internal/pkg/id"
 )
 
@@ -17,6 +22,13 @@ type Service struct {
 	memberRepo    server.MemberRepository
 	serverRepo    server.Repository
 	readStateRepo readstate.Repository
+	overwriteRepo channel.OverwriteRepository
+	roleRepo      server.RoleRepository
+	auditRepo     server.AuditLogRepository
+	permissions   *permissionApp.Resolver
+
+	// Notifies connected clients of changes, nil if not configured
+	events ws.ServerEventPublisher
 }
 
 // NewService creates a new channel service.
@@ -25,15 +37,28 @@ func NewService(
 	memberRepo server.MemberRepository,
 	serverRepo server.Repository,
 	readStateRepo readstate.Repository,
+	overwriteRepo channel.OverwriteRepository,
+	roleRepo server.RoleRepository,
+	auditRepo server.AuditLogRepository,
+	permissions *permissionApp.Resolver,
 ) *Service {
 	return &Service{
 		channelRepo:   channelRepo,
 		memberRepo:    memberRepo,
 		serverRepo:    serverRepo,
 		readStateRepo: readStateRepo,
+		overwriteRepo: overwriteRepo,
+		roleRepo:      roleRepo,
+		auditRepo:     auditRepo,
+		permissions:   permissions,
 	}
 }
 
+// SetEventPublisher sets the publisher notified whenever channels change.
+func (s *Service) SetEventPublisher(events ws.ServerEventPublisher) {
+	s.events = events
+}
+
 // CreateCommand represents a channel creation request.
 type CreateCommand struct {
 	ServerID    string
@@ -41,6 +66,7 @@ type CreateCommand struct {
 	Name        string
 	Description string
 	Type        channel.ChannelType
+	IsPrivate   bool
 	UserID      string
 }
 
@@ -71,7 +97,7 @@ func (s *Service) Create(ctx context.Context, cmd CreateCommand) (*channel.Chann
 		Description: cmd.Description,
 		Type:        cmd.Type,
 		Position:    len(channels),
-		IsPrivate:   false,
+		IsPrivate:   cmd.IsPrivate,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -80,10 +106,35 @@ func (s *Service) Create(ctx context.Context, cmd CreateCommand) (*channel.Chann
 		return nil, err
 	}
 
+	if ch.IsPrivate {
+		if err := s.applyPrivacy(ctx, ch); err != nil {
+			return nil, err
+		}
+
+		// Make sure the creator can still see the channel they just hid
+		if srv, err := s.serverRepo.FindByID(ctx, cmd.ServerID); err == nil && srv.OwnerID != cmd.UserID {
+			if member, err := s.memberRepo.FindByServerAndUser(ctx, cmd.ServerID, cmd.UserID); err == nil {
+				if err := s.overwriteRepo.Create(ctx, &channel.PermissionOverwrite{
+					ID:         id.Generate("perm"),
+					ChannelID:  ch.ID,
+					TargetType: channel.OverwriteTargetMember,
+					TargetID:   member.ID,
+					Allow:      server.PermissionViewChannel,
+				}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	s.audit(ctx, cmd.ServerID, cmd.UserID, ch.ID, server.AuditLogActionChannelCreate, channelChanges(nil, ch))
+
+	s.publishChange(ctx, ch, cmd.UserID, nil)
+
 	return ch, nil
 }
 
-// ListByServer lists all channels in a server.
+// ListByServer lists the channels in a server the user can view.
 func (s *Service) ListByServer(ctx context.Context, serverID, userID string) ([]*channel.Channel, error) {
 	// Check membership
 	isMember, err := s.memberRepo.IsMember(ctx, serverID, userID)
@@ -94,7 +145,12 @@ func (s *Service) ListByServer(ctx context.Context, serverID, userID string) ([]
 		return nil, server.ErrNotMember
 	}
 
-	return s.channelRepo.FindByServerID(ctx, serverID)
+	channels, err := s.channelRepo.FindByServerID(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.permissions.VisibleChannels(ctx, serverID, userID, channels)
 }
 
 // GetByID retrieves a channel by ID.
@@ -104,12 +160,8 @@ func (s *Service) GetByID(ctx context.Context, id, userID string) (*channel.Chan
 		return nil, err
 	}
 
-	// Check membership
-	isMember, err := s.memberRepo.IsMember(ctx, ch.ServerID, userID)
-	if err != nil {
-		return nil, err
-	}
-	if !isMember {
+	perms, err := s.permissions.ChannelPermissionsFor(ctx, ch, userID)
+	if err != nil || !perms.Has(server.PermissionViewChannel) {
 		return nil, channel.ErrNoPermission
 	}
 
@@ -124,7 +176,10 @@ type UpdateCommand struct {
 	Description string
 	ParentID    *string
 	Position    *int
+	IsPrivate   *bool
 	UserID      string
+
+	SlowmodeSeconds *int // 0 turns slowmode off
 }
 
 // Update updates a channel.
@@ -143,17 +198,43 @@ func (s *Service) Update(ctx context.Context, cmd UpdateCommand) (*channel.Chann
 		return nil, channel.ErrNotFound
 	}
 
+	if cmd.SlowmodeSeconds != nil {
+		if err := slowmode.ValidateSeconds(*cmd.SlowmodeSeconds); err != nil {
+			return nil, err
+		}
+	}
+
+	before := *ch
+	viewers := s.viewersOf(ctx, &before)
+
 	ch.Name = cmd.Name
 	ch.Description = cmd.Description
 	ch.ParentID = cmd.ParentID
 	if cmd.Position != nil {
 		ch.Position = *cmd.Position
 	}
+	if cmd.SlowmodeSeconds != nil {
+		ch.SlowmodeSeconds = *cmd.SlowmodeSeconds
+	}
+	privacyChanged := cmd.IsPrivate != nil && *cmd.IsPrivate != ch.IsPrivate
+	if privacyChanged {
+		ch.IsPrivate = *cmd.IsPrivate
+	}
 
 	if err := s.channelRepo.Update(ctx, ch); err != nil {
 		return nil, err
 	}
 
+	if privacyChanged {
+		if err := s.applyPrivacy(ctx, ch); err != nil {
+			return nil, err
+		}
+	}
+
+	s.audit(ctx, cmd.ServerID, cmd.UserID, ch.ID, server.AuditLogActionChannelUpdate, channelChanges(&before, ch))
+
+	s.publishChange(ctx, ch, cmd.UserID, viewers)
+
 	return ch, nil
 }
 
@@ -173,7 +254,18 @@ func (s *Service) Delete(ctx context.Context, id, serverID, userID string) error
 		return channel.ErrNotFound
 	}
 
-	return s.channelRepo.Delete(ctx, id)
+	// Work out who could see it while its overwrites still exist
+	viewers := s.viewersOf(ctx, ch)
+
+	if err := s.channelRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit(ctx, serverID, userID, ch.ID, server.AuditLogActionChannelDelete, channelChanges(ch, nil))
+
+	s.publish(ws.EventChannelDelete, serverID, userID, ch, viewers)
+
+	return nil
 }
 
 // ReorderCommand represents a channel reorder request.
@@ -202,6 +294,7 @@ func (s *Service) Reorder(ctx context.Context, cmd ReorderCommand) error {
 
 	// In a real implementation, we should use a transaction.
 	// Here we will iterate and update.
+	changes := server.AuditChanges{}
 	for _, update := range cmd.Updates {
 		ch, err := s.channelRepo.FindByID(ctx, update.ID)
 		if err != nil {
@@ -212,21 +305,463 @@ func (s *Service) Reorder(ctx context.Context, cmd ReorderCommand) error {
 			continue // wrong server
 		}
 
+		before := *ch
 		ch.Position = update.Position
 		ch.ParentID = update.ParentID
 
 		if err := s.channelRepo.Update(ctx, ch); err != nil {
 			return err
 		}
+
+		if moved := channelChanges(&before, ch); len(moved) > 0 {
+			changes[ch.ID] = moved
+		}
+	}
+
+	if len(changes) > 0 {
+		s.audit(ctx, cmd.ServerID, cmd.UserID, cmd.ServerID, server.AuditLogActionChannelReorder, changes)
+	}
+
+	// Clients replace their whole channel list, so each member gets the
+	// channels they can see
+	if s.events != nil {
+		s.publishReorder(ctx, cmd.ServerID, cmd.UserID)
+	}
+
+	return nil
+}
+
+// publish sends a server event to the given users, if a publisher is set.
+// Channels can be hidden from part of the server, so channel events never go
+// to every server subscriber.
+func (s *Service) publish(eventType ws.EventType, serverID, actorID string, payload interface{}, userIDs []string) {
+	if s.events == nil || len(userIDs) == 0 {
+		return
+	}
+	s.events.PublishServerEvent(ws.ServerEvent{
+		Type:     eventType,
+		ServerID: serverID,
+		ActorID:  actorID,
+		Payload:  payload,
+		UserIDs:  userIDs,
+	})
+}
+
+// viewersOf returns the users who can view a channel. It returns nil when no
+// publisher is set or the viewers cannot be loaded, so nothing is sent.
+func (s *Service) viewersOf(ctx context.Context, ch *channel.Channel) []string {
+	if s.events == nil {
+		return nil
+	}
+
+	members, err := s.permissions.MembersWithChannelPermission(ctx, ch, server.PermissionViewChannel)
+	if err != nil {
+		slog.Warn("failed to load channel viewers", slog.Any("error", err), slog.String("channelId", ch.ID))
+		return nil
+	}
+
+	userIDs := make([]string, len(members))
+	for i, m := range members {
+		userIDs[i] = m.UserID
+	}
+	return userIDs
+}
+
+// publishChange tells a channel's viewers it was created or changed. before
+// holds who could see it beforehand: those who lost access are told it was
+// deleted, and those who gained it that it was created.
+func (s *Service) publishChange(ctx context.Context, ch *channel.Channel, actorID string, before []string) {
+	if s.events == nil {
+		return
+	}
+
+	had := make(map[string]bool, len(before))
+	for _, userID := range before {
+		had[userID] = true
+	}
+
+	var updated, created []string
+	for _, userID := range s.viewersOf(ctx, ch) {
+		if had[userID] {
+			updated = append(updated, userID)
+			delete(had, userID)
+		} else {
+			created = append(created, userID)
+		}
+	}
+	var lost []string
+	for _, userID := range before {
+		if had[userID] {
+			lost = append(lost, userID)
+		}
+	}
+
+	s.publish(ws.EventChannelUpdate, ch.ServerID, actorID, ch, updated)
+	s.publish(ws.EventChannelCreate, ch.ServerID, actorID, ch, created)
+	s.publish(ws.EventChannelDelete, ch.ServerID, actorID, ch, lost)
+}
+
+// publishReorder sends every member the server's channels they can see.
+// Members who see the same channels share one event.
+func (s *Service) publishReorder(ctx context.Context, serverID, actorID string) {
+	channels, err := s.channelRepo.FindByServerID(ctx, serverID)
+	if err != nil {
+		return
+	}
+
+	byUser, err := s.permissions.VisibleChannelsByUser(ctx, serverID, channels)
+	if err != nil {
+		slog.Warn("failed to load visible channels", slog.Any("error", err), slog.String("serverId", serverID))
+		return
+	}
+
+	type group struct {
+		channels []*channel.Channel
+		userIDs  []string
+	}
+	groups := make(map[string]*group)
+	var keys []string
+	for userID, visible := range byUser {
+		ids := make([]string, len(visible))
+		for i, ch := range visible {
+			ids[i] = ch.ID
+		}
+		key := strings.Join(ids, ",")
+
+		g, ok := groups[key]
+		if !ok {
+			g = &group{channels: visible}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.userIDs = append(g.userIDs, userID)
+	}
+
+	for _, key := range keys {
+		g := groups[key]
+		s.publish(ws.EventChannelReorder, serverID, actorID, g.channels, g.userIDs)
+	}
+}
+
+// ============================================================================
+// PERMISSION OVERWRITES
+// ============================================================================
+
+// GetOverwrite returns a channel's overwrite for a role or member.
+func (s *Service) GetOverwrite(ctx context.Context, serverID, channelID, userID string, targetType channel.OverwriteTargetType, targetID string) (*channel.PermissionOverwrite, error) {
+	ch, err := s.findInServer(ctx, serverID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.canManageOverwrites(ctx, ch, userID) {
+		return nil, channel.ErrNoPermission
+	}
+
+	overwrites, err := s.overwriteRepo.FindByChannelID(ctx, ch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ow := channel.FindOverwrite(overwrites, targetType, targetID)
+	if ow == nil {
+		return nil, channel.ErrOverwriteNotFound
+	}
+	return ow, nil
+}
+
+// SetOverwriteCommand represents a request to create or replace an overwrite.
+type SetOverwriteCommand struct {
+	ServerID   string
+	ChannelID  string
+	UserID     string
+	TargetType channel.OverwriteTargetType
+	TargetID   string // role ID or member ID
+	Allow      server.Permission
+	Deny       server.Permission
+}
+
+// SetOverwrite creates or replaces a channel's overwrite for a role or member.
+func (s *Service) SetOverwrite(ctx context.Context, cmd SetOverwriteCommand) (*channel.PermissionOverwrite, error) {
+	ch, err := s.findInServer(ctx, cmd.ServerID, cmd.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	perms, err := s.permissions.ChannelPermissionsFor(ctx, ch, cmd.UserID)
+	if err != nil || !perms.Has(server.PermissionManageRoles) {
+		return nil, channel.ErrNoPermission
+	}
+
+	ow := &channel.PermissionOverwrite{
+		ChannelID:  ch.ID,
+		TargetType: cmd.TargetType,
+		TargetID:   cmd.TargetID,
+		Allow:      cmd.Allow,
+		Deny:       cmd.Deny,
+	}
+	if err := ow.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.validateOverwriteTarget(ctx, ch.ServerID, ow.TargetType, ow.TargetID); err != nil {
+		return nil, err
+	}
+
+	overwrites, err := s.overwriteRepo.FindByChannelID(ctx, ch.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only bits the actor holds in the channel can be allowed, denied or
+	// lifted again; owners and administrators hold them all
+	existing := channel.FindOverwrite(overwrites, ow.TargetType, ow.TargetID)
+	changed := ow.Allow | ow.Deny
+	if existing != nil {
+		changed = (ow.Allow ^ existing.Allow) | (ow.Deny ^ existing.Deny)
+	}
+	if !perms.Has(changed) {
+		return nil, channel.ErrNoPermission
+	}
+
+	viewers := s.viewersOf(ctx, ch)
+
+	var oldAllow, oldDeny interface{}
+	if existing != nil {
+		oldAllow, oldDeny = int64(existing.Allow), int64(existing.Deny)
+		ow.ID = existing.ID
+		if err := s.overwriteRepo.Update(ctx, ow); err != nil {
+			return nil, err
+		}
+	} else {
+		ow.ID = id.Generate("perm")
+		if err := s.overwriteRepo.Create(ctx, ow); err != nil {
+			return nil, err
+		}
+	}
+
+	s.audit(ctx, ch.ServerID, cmd.UserID, ow.TargetID, server.AuditLogActionOverwriteSet, server.AuditChanges{
+		"channel_id":  ch.ID,
+		"target_type": string(ow.TargetType),
+	}.Diff("allow", oldAllow, int64(ow.Allow)).Diff("deny", oldDeny, int64(ow.Deny)))
+
+	s.overwritesChanged(ctx, ch, cmd.UserID, viewers)
+
+	return ow, nil
+}
+
+// DeleteOverwrite removes a channel's overwrite for a role or member.
+func (s *Service) DeleteOverwrite(ctx context.Context, serverID, channelID, userID string, targetType channel.OverwriteTargetType, targetID string) error {
+	ch, err := s.findInServer(ctx, serverID, channelID)
+	if err != nil {
+		return err
+	}
+	perms, err := s.permissions.ChannelPermissionsFor(ctx, ch, userID)
+	if err != nil || !perms.Has(server.PermissionManageRoles) {
+		return channel.ErrNoPermission
+	}
+
+	overwrites, err := s.overwriteRepo.FindByChannelID(ctx, ch.ID)
+	if err != nil {
+		return err
+	}
+
+	existing := channel.FindOverwrite(overwrites, targetType, targetID)
+	if existing == nil {
+		return channel.ErrOverwriteNotFound
+	}
+	if !perms.Has(existing.Allow | existing.Deny) {
+		return channel.ErrNoPermission
 	}
 
+	viewers := s.viewersOf(ctx, ch)
+
+	if err := s.overwriteRepo.DeleteByChannelAndTarget(ctx, ch.ID, targetType, targetID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, ch.ServerID, userID, targetID, server.AuditLogActionOverwriteDel, server.AuditChanges{
+		"channel_id":  ch.ID,
+		"target_type": string(targetType),
+	}.Set("allow", int64(existing.Allow), nil).Set("deny", int64(existing.Deny), nil))
+
+	s.overwritesChanged(ctx, ch, userID, viewers)
+
 	return nil
 }
 
+// ExplainPermissions breaks down a member's effective permissions in a
+// channel. Anyone may explain their own permissions; explaining someone
+// else's takes ManageRoles in the channel.
+func (s *Service) ExplainPermissions(ctx context.Context, serverID, channelID, actorID, targetUserID string) (*permissionApp.Explanation, error) {
+	ch, err := s.findInServer(ctx, serverID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if actorID != targetUserID && !s.canManageOverwrites(ctx, ch, actorID) {
+		return nil, channel.ErrNoPermission
+	}
+
+	return s.permissions.ExplainChannelPermissions(ctx, ch, targetUserID)
+}
+
+// applyPrivacy hides a private channel from @everyone by denying
+// ViewChannel on its @everyone overwrite, or lifts that deny again when the
+// channel is made public. Other bits on the overwrite are left alone.
+func (s *Service) applyPrivacy(ctx context.Context, ch *channel.Channel) error {
+	everyone, err := s.roleRepo.FindDefaultRole(ctx, ch.ServerID)
+	if err != nil {
+		return err
+	}
+
+	overwrites, err := s.overwriteRepo.FindByChannelID(ctx, ch.ID)
+	if err != nil {
+		return err
+	}
+
+	existing := channel.FindOverwrite(overwrites, channel.OverwriteTargetRole, everyone.ID)
+	ow := channel.PermissionOverwrite{
+		ID:         id.Generate("perm"),
+		ChannelID:  ch.ID,
+		TargetType: channel.OverwriteTargetRole,
+		TargetID:   everyone.ID,
+	}
+	if existing != nil {
+		ow = *existing
+	}
+
+	if ch.IsPrivate {
+		ow.Allow = ow.Allow.Remove(server.PermissionViewChannel)
+		ow.Deny = ow.Deny.Add(server.PermissionViewChannel)
+	} else {
+		ow.Deny = ow.Deny.Remove(server.PermissionViewChannel)
+	}
+
+	switch {
+	case existing == nil && ow.IsEmpty():
+		return nil
+	case existing == nil:
+		return s.overwriteRepo.Create(ctx, &ow)
+	case ow.IsEmpty():
+		return s.overwriteRepo.DeleteByChannelAndTarget(ctx, ch.ID, ow.TargetType, ow.TargetID)
+	default:
+		return s.overwriteRepo.Update(ctx, &ow)
+	}
+}
+
+// overwritesChanged keeps IsPrivate in line with the @everyone overwrite and
+// tells clients to refresh the channel. viewers holds who could see the
+// channel before the overwrites changed.
+func (s *Service) overwritesChanged(ctx context.Context, ch *channel.Channel, actorID string, viewers []string) {
+	if everyone, err := s.roleRepo.FindDefaultRole(ctx, ch.ServerID); err == nil {
+		if overwrites, err := s.overwriteRepo.FindByChannelID(ctx, ch.ID); err == nil {
+			isPrivate := false
+			if ow := channel.FindOverwrite(overwrites, channel.OverwriteTargetRole, everyone.ID); ow != nil {
+				isPrivate = ow.Deny&server.PermissionViewChannel != 0
+			}
+			if isPrivate != ch.IsPrivate {
+				ch.IsPrivate = isPrivate
+				if err := s.channelRepo.Update(ctx, ch); err != nil {
+					slog.Warn("failed to update channel privacy", slog.Any("error", err), slog.String("channelId", ch.ID))
+				}
+			}
+		}
+	}
+
+	s.publishChange(ctx, ch, actorID, viewers)
+}
+
+// validateOverwriteTarget checks that the role or member belongs to the server.
+func (s *Service) validateOverwriteTarget(ctx context.Context, serverID string, targetType channel.OverwriteTargetType, targetID string) error {
+	switch targetType {
+	case channel.OverwriteTargetRole:
+		role, err := s.roleRepo.FindByID(ctx, targetID)
+		if err != nil || role.ServerID != serverID {
+			return server.ErrRoleNotFound
+		}
+	case channel.OverwriteTargetMember:
+		member, err := s.memberRepo.FindByID(ctx, targetID)
+		if err != nil || member.ServerID != serverID {
+			return server.ErrNotMember
+		}
+	}
+	return nil
+}
+
+func (s *Service) findInServer(ctx context.Context, serverID, channelID string) (*channel.Channel, error) {
+	ch, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if ch.ServerID != serverID {
+		return nil, channel.ErrNotFound
+	}
+	return ch, nil
+}
+
+// audit records an audit entry. Failing to record it does not fail the
+// action it describes.
+func (s *Service) audit(ctx context.Context, serverID, actorID, targetID string, action server.AuditLogAction, changes server.AuditChanges) {
+	if s.auditRepo == nil {
+		return
+	}
+	if err := s.auditRepo.Create(ctx, &server.AuditLog{
+		ID:         id.Generate("audi"),
+		ServerID:   serverID,
+		ActorID:    actorID,
+		TargetID:   targetID,
+		ActionType: action,
+		Changes:    changes,
+		CreatedAt:  time.Now(),
+	}); err != nil {
+		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", string(action)))
+	}
+}
+
+// channelChanges describes a channel's fields before and after an action.
+// before is nil for a created channel and after for a deleted one.
+func channelChanges(before, after *channel.Channel) server.AuditChanges {
+	field := func(ch *channel.Channel, get func(*channel.Channel) interface{}) interface{} {
+		if ch == nil {
+			return nil
+		}
+		return get(ch)
+	}
+
+	changes := server.AuditChanges{}
+	for _, f := range []struct {
+		name string
+		get  func(*channel.Channel) interface{}
+	}{
+		{"name", func(ch *channel.Channel) interface{} { return ch.Name }},
+		{"description", func(ch *channel.Channel) interface{} { return ch.Description }},
+		{"type", func(ch *channel.Channel) interface{} { return string(ch.Type) }},
+		{"parent_id", func(ch *channel.Channel) interface{} {
+			if ch.ParentID == nil {
+				return nil
+			}
+			return *ch.ParentID
+		}},
+		{"position", func(ch *channel.Channel) interface{} { return ch.Position }},
+		{"is_private", func(ch *channel.Channel) interface{} { return ch.IsPrivate }},
+		{"slowmode_seconds", func(ch *channel.Channel) interface{} { return ch.SlowmodeSeconds }},
+	} {
+		changes.Diff(f.name, field(before, f.get), field(after, f.get))
+	}
+	return changes
+}
+
 // ============================================================================
 // PERMISSION HELPERS
 // ============================================================================
 
+// canManageOverwrites checks if a user can edit a channel's overwrites, which
+// takes ManageRoles in that channel.
+func (s *Service) canManageOverwrites(ctx context.Context, ch *channel.Channel, userID string) bool {
+	perms, err := s.permissions.ChannelPermissionsFor(ctx, ch, userID)
+	if err != nil {
+		return false
+	}
+	return perms.Has(server.PermissionManageRoles)
+}
+
 // canManageChannels checks if a user can manage channels in a server.
 func (s *Service) canManageChannels(ctx context.Context, serverID, userID string) bool {
 	srv, err := s.serverRepo.FindByID(ctx, serverID)
@@ -256,13 +791,12 @@ func (s *Service) AckMessage(ctx context.Context, userID, channelID, messageID s
 		return err
 	}
 
-	// Check membership
-	isMember, err := s.memberRepo.IsMember(ctx, ch.ServerID, userID)
+	perms, err := s.permissions.ChannelPermissionsFor(ctx, ch, userID)
 	if err != nil {
 		return err
 	}
-	if !isMember {
-		return server.ErrNotMember
+	if !perms.Has(server.PermissionViewChannel) {
+		return channel.ErrNoPermission
 	}
 
 	return s.readStateRepo.Upsert(ctx, &readstate.ReadState{
@@ -272,3 +806,19 @@ func (s *Service) AckMessage(ctx context.Context, userID, channelID, messageID s
 		LastReadAt:        time.Now(),
 	})
 }
+
+// GetReadStates returns the user's read states, with mention counts, in the
+// channels of a server they can see. Channels they never read and were never
+// mentioned in are left out.
+func (s *Service) GetReadStates(ctx context.Context, serverID, userID string) ([]*readstate.ReadState, error) {
+	channels, err := s.ListByServer(ctx, serverID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	channelIDs := make([]string, len(channels))
+	for i, ch := range channels {
+		channelIDs[i] = ch.ID
+	}
+	return s.readStateRepo.GetByUserAndChannels(ctx, userID, channelIDs)
+}
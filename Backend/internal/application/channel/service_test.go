@@ -0,0 +1,385 @@
+package channel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/permission"
+	"pink/internal/application/testutil"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
+)
+
+type serviceMocks struct {
+	channelRepo   *testutil.MockChannelRepository
+	memberRepo    *testutil.MockMemberRepository
+	serverRepo    *testutil.MockServerRepository
+	roleRepo      *testutil.MockRoleRepository
+	overwriteRepo *testutil.MockOverwriteRepository
+	auditRepo     *testutil.MockAuditLogRepository
+}
+
+var everyone = server.Role{ID: "role_everyone", ServerID: "serv_1", IsDefault: true, Permissions: server.PermissionDefaultEveryone}
+
+// setupService creates a channel service with mocked dependencies.
+func setupService(t *testing.T) (*Service, *serviceMocks) {
+	m := &serviceMocks{
+		channelRepo:   new(testutil.MockChannelRepository),
+		memberRepo:    new(testutil.MockMemberRepository),
+		serverRepo:    new(testutil.MockServerRepository),
+		roleRepo:      new(testutil.MockRoleRepository),
+		overwriteRepo: new(testutil.MockOverwriteRepository),
+		auditRepo:     new(testutil.MockAuditLogRepository),
+	}
+
+	resolver := permission.NewResolver(permission.NewEngine(), m.serverRepo, m.memberRepo, m.roleRepo, m.channelRepo, m.overwriteRepo)
+	svc := NewService(m.channelRepo, m.memberRepo, m.serverRepo, nil, m.overwriteRepo, m.roleRepo, m.auditRepo, resolver)
+	return svc, m
+}
+
+// asMember makes userID a member of serv_1 with the given roles.
+func (m *serviceMocks) asMember(ctx context.Context, userID string, roles ...server.Role) {
+	m.serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	m.memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", userID).
+		Return(&server.Member{ID: "memb_" + userID, ServerID: "serv_1", UserID: userID, Roles: roles}, nil)
+}
+
+func TestService_ListByServer_HidesChannelsWithoutView(t *testing.T) {
+	svc, m := setupService(t)
+	ctx := context.Background()
+
+	category := &channel.Channel{ID: "chan_cat", ServerID: "serv_1", Type: channel.TypeCategory}
+	public := &channel.Channel{ID: "chan_public", ServerID: "serv_1", Type: channel.TypeText}
+	inCategory := &channel.Channel{ID: "chan_child", ServerID: "serv_1", Type: channel.TypeText, ParentID: &category.ID}
+	private := &channel.Channel{ID: "chan_private", ServerID: "serv_1", Type: channel.TypeText, IsPrivate: true}
+
+	m.memberRepo.On("IsMember", ctx, "serv_1", "user_1").Return(true, nil)
+	m.channelRepo.On("FindByServerID", ctx, "serv_1").Return([]*channel.Channel{category, public, inCategory, private}, nil)
+	m.asMember(ctx, "user_1", everyone)
+	m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).Return(map[string][]channel.PermissionOverwrite{
+		// The category itself is hidden, but a channel inside it is visible
+		"chan_cat": {
+			{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionViewChannel},
+		},
+		"chan_child": {
+			{TargetType: channel.OverwriteTargetMember, TargetID: "memb_user_1", Allow: server.PermissionViewChannel},
+		},
+		"chan_private": {
+			{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionViewChannel},
+		},
+	}, nil)
+
+	channels, err := svc.ListByServer(ctx, "serv_1", "user_1")
+	require.NoError(t, err)
+
+	ids := make([]string, len(channels))
+	for i, ch := range channels {
+		ids[i] = ch.ID
+	}
+	assert.Equal(t, []string{"chan_cat", "chan_public", "chan_child"}, ids)
+}
+
+// serverEventRecorder collects the server events a service publishes.
+type serverEventRecorder struct {
+	events []ws.ServerEvent
+}
+
+func (p *serverEventRecorder) PublishServerEvent(event ws.ServerEvent) {
+	p.events = append(p.events, event)
+}
+
+// byType returns the recipients of each published event type.
+func (p *serverEventRecorder) byType() map[ws.EventType][]string {
+	result := make(map[ws.EventType][]string)
+	for _, e := range p.events {
+		result[e.Type] = append(result[e.Type], e.UserIDs...)
+	}
+	return result
+}
+
+func TestService_Reorder_SendsEachMemberTheirVisibleChannels(t *testing.T) {
+	svc, m := setupService(t)
+	events := &serverEventRecorder{}
+	svc.SetEventPublisher(events)
+	ctx := context.Background()
+
+	public := &channel.Channel{ID: "chan_public", ServerID: "serv_1", Position: 0}
+	private := &channel.Channel{ID: "chan_private", ServerID: "serv_1", Position: 1, IsPrivate: true}
+
+	m.serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	m.channelRepo.On("FindByID", ctx, "chan_public").Return(public, nil)
+	m.channelRepo.On("Update", ctx, mock.Anything).Return(nil)
+	m.channelRepo.On("FindByServerID", ctx, "serv_1").Return([]*channel.Channel{public, private}, nil)
+	m.auditRepo.On("Create", ctx, mock.Anything).Return(nil)
+	m.roleRepo.On("FindDefaultRole", ctx, "serv_1").Return(&everyone, nil)
+	m.memberRepo.On("FindByServerID", ctx, "serv_1").Return([]*server.Member{
+		{ID: "memb_owner", ServerID: "serv_1", UserID: "user_owner"},
+		{ID: "memb_1", ServerID: "serv_1", UserID: "user_1"},
+		{ID: "memb_2", ServerID: "serv_1", UserID: "user_2"},
+	}, nil)
+	m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).Return(map[string][]channel.PermissionOverwrite{
+		"chan_private": {
+			{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionViewChannel},
+			{TargetType: channel.OverwriteTargetMember, TargetID: "memb_2", Allow: server.PermissionViewChannel},
+		},
+	}, nil)
+
+	require.NoError(t, svc.Reorder(ctx, ReorderCommand{
+		ServerID: "serv_1",
+		UserID:   "user_owner",
+		Updates:  []ChannelPositionUpdate{{ID: "chan_public", Position: 2}},
+	}))
+
+	visible := make(map[string][]*channel.Channel)
+	for _, e := range events.events {
+		assert.Equal(t, ws.EventChannelReorder, e.Type)
+		for _, userID := range e.UserIDs {
+			visible[userID] = e.Payload.([]*channel.Channel)
+		}
+	}
+	assert.Equal(t, []*channel.Channel{public, private}, visible["user_owner"])
+	assert.Equal(t, []*channel.Channel{public}, visible["user_1"])
+	assert.Equal(t, []*channel.Channel{public, private}, visible["user_2"])
+}
+
+func TestService_SetOverwrite_PublishesOnlyToViewers(t *testing.T) {
+	svc, m := setupService(t)
+	events := &serverEventRecorder{}
+	svc.SetEventPublisher(events)
+	ctx := context.Background()
+
+	ch := &channel.Channel{ID: "chan_1", ServerID: "serv_1"}
+	hidden := []channel.PermissionOverwrite{
+		{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionViewChannel},
+	}
+
+	m.channelRepo.On("FindByID", ctx, "chan_1").Return(ch, nil)
+	m.channelRepo.On("Update", ctx, mock.Anything).Return(nil)
+	m.asMember(ctx, "user_owner")
+	m.roleRepo.On("FindByID", ctx, "role_everyone").Return(&everyone, nil)
+	m.roleRepo.On("FindDefaultRole", ctx, "serv_1").Return(&everyone, nil)
+	m.memberRepo.On("FindByServerID", ctx, "serv_1").Return([]*server.Member{
+		{ID: "memb_owner", ServerID: "serv_1", UserID: "user_owner"},
+		{ID: "memb_1", ServerID: "serv_1", UserID: "user_1"},
+	}, nil)
+	m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).Return(map[string][]channel.PermissionOverwrite{}, nil).Times(2)
+	m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).Return(map[string][]channel.PermissionOverwrite{"chan_1": hidden}, nil)
+	m.overwriteRepo.On("FindByChannelID", ctx, "chan_1").Return([]channel.PermissionOverwrite{}, nil).Once()
+	m.overwriteRepo.On("FindByChannelID", ctx, "chan_1").Return(hidden, nil)
+	m.overwriteRepo.On("Create", ctx, mock.Anything).Return(nil)
+	m.auditRepo.On("Create", ctx, mock.Anything).Return(nil)
+
+	_, err := svc.SetOverwrite(ctx, SetOverwriteCommand{
+		ServerID:   "serv_1",
+		ChannelID:  "chan_1",
+		UserID:     "user_owner",
+		TargetType: channel.OverwriteTargetRole,
+		TargetID:   "role_everyone",
+		Deny:       server.PermissionViewChannel,
+	})
+	require.NoError(t, err)
+
+	// The member who lost access is told the channel is gone; nothing goes
+	// to the whole server
+	assert.Equal(t, map[ws.EventType][]string{
+		ws.EventChannelUpdate: {"user_owner"},
+		ws.EventChannelDelete: {"user_1"},
+	}, events.byType())
+}
+
+func TestService_SetOverwrite_CreatesAndAudits(t *testing.T) {
+	svc, m := setupService(t)
+	ctx := context.Background()
+
+	ch := &channel.Channel{ID: "chan_1", ServerID: "serv_1"}
+	manager := server.Role{ID: "role_mod", ServerID: "serv_1", Permissions: server.PermissionManageRoles}
+
+	m.channelRepo.On("FindByID", ctx, "chan_1").Return(ch, nil)
+	m.asMember(ctx, "user_mod", everyone, manager)
+	m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).Return(map[string][]channel.PermissionOverwrite{}, nil)
+	m.roleRepo.On("FindByID", ctx, "role_everyone").Return(&everyone, nil)
+	m.roleRepo.On("FindDefaultRole", ctx, "serv_1").Return(&everyone, nil)
+	m.overwriteRepo.On("FindByChannelID", ctx, "chan_1").Return([]channel.PermissionOverwrite{}, nil).Once()
+	m.overwriteRepo.On("Create", ctx, mock.MatchedBy(func(ow *channel.PermissionOverwrite) bool {
+		return ow.TargetID == "role_everyone" && ow.Deny == server.PermissionViewChannel
+	})).Return(nil)
+	m.auditRepo.On("Create", ctx, mock.MatchedBy(func(log *server.AuditLog) bool {
+		return log.ActionType == server.AuditLogActionOverwriteSet && log.TargetID == "role_everyone"
+	})).Return(nil)
+
+	// Denying ViewChannel to @everyone marks the channel private
+	m.overwriteRepo.On("FindByChannelID", ctx, "chan_1").Return([]channel.PermissionOverwrite{
+		{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionViewChannel},
+	}, nil)
+	m.channelRepo.On("Update", ctx, mock.MatchedBy(func(c *channel.Channel) bool { return c.IsPrivate })).Return(nil)
+
+	ow, err := svc.SetOverwrite(ctx, SetOverwriteCommand{
+		ServerID:   "serv_1",
+		ChannelID:  "chan_1",
+		UserID:     "user_mod",
+		TargetType: channel.OverwriteTargetRole,
+		TargetID:   "role_everyone",
+		Deny:       server.PermissionViewChannel,
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, ow.ID)
+	assert.True(t, ch.IsPrivate)
+	m.auditRepo.AssertExpectations(t)
+	m.channelRepo.AssertExpectations(t)
+}
+
+func TestService_SetOverwrite_RequiresManageRoles(t *testing.T) {
+	svc, m := setupService(t)
+	ctx := context.Background()
+
+	m.channelRepo.On("FindByID", ctx, "chan_1").Return(&channel.Channel{ID: "chan_1", ServerID: "serv_1"}, nil)
+	m.asMember(ctx, "user_1", everyone)
+	m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).Return(map[string][]channel.PermissionOverwrite{}, nil)
+
+	_, err := svc.SetOverwrite(ctx, SetOverwriteCommand{
+		ServerID:   "serv_1",
+		ChannelID:  "chan_1",
+		UserID:     "user_1",
+		TargetType: channel.OverwriteTargetRole,
+		TargetID:   "role_everyone",
+		Allow:      server.PermissionManageMessages,
+	})
+
+	assert.ErrorIs(t, err, channel.ErrNoPermission)
+	m.overwriteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestService_SetOverwrite_OnlyGrantsHeldPermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		allow    server.Permission
+		deny     server.Permission
+		existing *channel.PermissionOverwrite
+		wantErr  error
+	}{
+		{name: "allows held bit", allow: server.PermissionSendMessages},
+		{name: "allows unheld bit", allow: server.PermissionManageMessages, wantErr: channel.ErrNoPermission},
+		{name: "denies unheld bit", deny: server.PermissionMentionEveryone, wantErr: channel.ErrNoPermission},
+		{name: "allows administrator", allow: server.PermissionAdministrator, wantErr: channel.ErrInvalidOverwrite},
+		{
+			name:     "keeps unheld bit set by someone else",
+			allow:    server.PermissionManageMessages | server.PermissionSendMessages,
+			existing: &channel.PermissionOverwrite{TargetType: channel.OverwriteTargetMember, TargetID: "memb_user_mod", Allow: server.PermissionManageMessages},
+		},
+		{
+			name:     "lifts unheld bit set by someone else",
+			existing: &channel.PermissionOverwrite{TargetType: channel.OverwriteTargetMember, TargetID: "memb_user_mod", Deny: server.PermissionBypassSlowmode},
+			wantErr:  channel.ErrNoPermission,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupService(t)
+			ctx := context.Background()
+
+			manager := server.Role{ID: "role_mod", ServerID: "serv_1", Permissions: server.PermissionManageRoles}
+			var overwrites []channel.PermissionOverwrite
+			if tt.existing != nil {
+				overwrites = append(overwrites, *tt.existing)
+			}
+
+			m.channelRepo.On("FindByID", ctx, "chan_1").Return(&channel.Channel{ID: "chan_1", ServerID: "serv_1"}, nil)
+			m.asMember(ctx, "user_mod", everyone, manager)
+			m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).Return(map[string][]channel.PermissionOverwrite{}, nil)
+			m.memberRepo.On("FindByID", ctx, "memb_user_mod").Return(&server.Member{ID: "memb_user_mod", ServerID: "serv_1"}, nil)
+			m.overwriteRepo.On("FindByChannelID", ctx, "chan_1").Return(overwrites, nil)
+			m.overwriteRepo.On("Create", ctx, mock.Anything).Return(nil)
+			m.overwriteRepo.On("Update", ctx, mock.Anything).Return(nil)
+			m.auditRepo.On("Create", ctx, mock.Anything).Return(nil)
+			m.roleRepo.On("FindDefaultRole", ctx, "serv_1").Return(&everyone, nil)
+
+			_, err := svc.SetOverwrite(ctx, SetOverwriteCommand{
+				ServerID:   "serv_1",
+				ChannelID:  "chan_1",
+				UserID:     "user_mod",
+				TargetType: channel.OverwriteTargetMember,
+				TargetID:   "memb_user_mod",
+				Allow:      tt.allow,
+				Deny:       tt.deny,
+			})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				m.overwriteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				m.overwriteRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestService_SetOverwrite_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     SetOverwriteCommand
+		wantErr error
+	}{
+		{
+			name:    "unknown target type",
+			cmd:     SetOverwriteCommand{TargetType: "user", TargetID: "user_1"},
+			wantErr: channel.ErrInvalidOverwrite,
+		},
+		{
+			name: "allow and deny overlap",
+			cmd: SetOverwriteCommand{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone",
+				Allow: server.PermissionSendMessages, Deny: server.PermissionSendMessages},
+			wantErr: channel.ErrInvalidOverwrite,
+		},
+		{
+			name:    "role from another server",
+			cmd:     SetOverwriteCommand{TargetType: channel.OverwriteTargetRole, TargetID: "role_other"},
+			wantErr: server.ErrRoleNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupService(t)
+			ctx := context.Background()
+
+			m.channelRepo.On("FindByID", ctx, "chan_1").Return(&channel.Channel{ID: "chan_1", ServerID: "serv_1"}, nil)
+			m.serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+			m.memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_owner").
+				Return(&server.Member{ID: "memb_owner", ServerID: "serv_1", UserID: "user_owner"}, nil)
+			m.roleRepo.On("FindDefaultRole", ctx, "serv_1").Return(&everyone, nil)
+			m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).Return(map[string][]channel.PermissionOverwrite{}, nil)
+			m.roleRepo.On("FindByID", ctx, "role_other").Return(&server.Role{ID: "role_other", ServerID: "serv_2"}, nil)
+
+			tt.cmd.ServerID = "serv_1"
+			tt.cmd.ChannelID = "chan_1"
+			tt.cmd.UserID = "user_owner"
+
+			_, err := svc.SetOverwrite(ctx, tt.cmd)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestService_DeleteOverwrite_NotFound(t *testing.T) {
+	svc, m := setupService(t)
+	ctx := context.Background()
+
+	m.channelRepo.On("FindByID", ctx, "chan_1").Return(&channel.Channel{ID: "chan_1", ServerID: "serv_1"}, nil)
+	m.serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	m.memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_owner").
+		Return(&server.Member{ID: "memb_owner", ServerID: "serv_1", UserID: "user_owner"}, nil)
+	m.roleRepo.On("FindDefaultRole", ctx, "serv_1").Return(&everyone, nil)
+	m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).Return(map[string][]channel.PermissionOverwrite{}, nil)
+	m.overwriteRepo.On("FindByChannelID", ctx, "chan_1").Return([]channel.PermissionOverwrite{}, nil)
+
+	err := svc.DeleteOverwrite(ctx, "serv_1", "chan_1", "user_owner", channel.OverwriteTargetMember, "memb_1")
+	assert.ErrorIs(t, err, channel.ErrOverwriteNotFound)
+}
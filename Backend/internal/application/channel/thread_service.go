@@ -0,0 +1,625 @@
+package channel
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"pink/internal/domain/channel"
+	"pink/internal/domain/history"
+	"pink/internal/domain/revision"
+	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
+	"pink/internal/pkg/id"
+	"pink/internal/pkg/periodic"
+)
+
+// ThreadService manages threads. Threads have no permissions of their own:
+// every check is made against the parent channel, and ManageMessages there
+// lets a member moderate its threads. It shares the message service's
+// repositories so thread messages are validated like channel messages.
+type ThreadService struct {
+	messages   *MessageService
+	threadRepo channel.ThreadRepository
+	events     ws.ChannelEventPublisher
+}
+
+// NewThreadService creates a new ThreadService.
+func NewThreadService(messages *MessageService, threadRepo channel.ThreadRepository) *ThreadService {
+	return &ThreadService{
+		messages:   messages,
+		threadRepo: threadRepo,
+	}
+}
+
+// SetEventPublisher sets the publisher notified whenever threads change.
+func (s *ThreadService) SetEventPublisher(events ws.ChannelEventPublisher) {
+	s.events = events
+}
+
+// CreateThreadCommand represents a request to create a thread.
+type CreateThreadCommand struct {
+	ServerID         string
+	ChannelID        string
+	UserID           string
+	Name             string        // Defaults to the start of the message when started from one
+	MessageID        *string       // Message to start the thread from, nil for a standalone thread
+	AutoArchiveAfter time.Duration // 0 = channel.DefaultThreadAutoArchive
+}
+
+// CreateThread starts a thread in a channel, from a message or on its own.
+// Anyone who can send messages in the channel can start a thread, and joins
+// it as its owner.
+func (s *ThreadService) CreateThread(ctx context.Context, cmd CreateThreadCommand) (*channel.Thread, error) {
+	ch, _, err := s.channelAccess(ctx, cmd.ServerID, cmd.ChannelID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !ch.Type.IsTextEnabled() {
+		return nil, channel.ErrInvalidType
+	}
+	if _, err := s.messages.canSendMessage(ctx, ch, cmd.UserID, false); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(cmd.Name)
+	if cmd.MessageID != nil {
+		msg, err := s.messages.messageRepo.FindByID(ctx, *cmd.MessageID)
+		if err != nil {
+			return nil, err
+		}
+		if msg.ChannelID != ch.ID {
+			return nil, channel.ErrMessageNotFound
+		}
+		// Threads don't nest
+		if msg.ThreadID != nil {
+			return nil, channel.ErrInvalidThread
+		}
+		if name == "" {
+			name = threadNameFrom(msg.Content)
+		}
+	}
+
+	autoArchive := cmd.AutoArchiveAfter
+	if autoArchive == 0 {
+		autoArchive = channel.DefaultThreadAutoArchive
+	}
+	if err := validateThread(name, autoArchive); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	thread := &channel.Thread{
+		ID:               id.Generate("thrd"),
+		ChannelID:        ch.ID,
+		ServerID:         ch.ServerID,
+		ParentMessageID:  cmd.MessageID,
+		OwnerID:          cmd.UserID,
+		Name:             name,
+		AutoArchiveAfter: autoArchive,
+		LastMessageAt:    now,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	if err := s.threadRepo.Create(ctx, thread); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.threadRepo.AddMember(ctx, &channel.ThreadMember{ThreadID: thread.ID, UserID: cmd.UserID, JoinedAt: now}); err != nil {
+		return nil, err
+	}
+	thread.MemberCount = 1
+
+	s.publish(ws.EventThreadCreate, thread, cmd.UserID, thread)
+
+	return thread, nil
+}
+
+// ListThreads lists a channel's active or archived threads.
+func (s *ThreadService) ListThreads(ctx context.Context, serverID, channelID, userID string, archived bool, cursor string, limit int) ([]*channel.Thread, string, error) {
+	if _, _, err := s.channelAccess(ctx, serverID, channelID, userID); err != nil {
+		return nil, "", err
+	}
+
+	return s.threadRepo.FindByChannelID(ctx, channelID, archived, cursor, limit)
+}
+
+// GetThread returns a thread and the user's read state in it, which is nil if
+// they have never read it.
+func (s *ThreadService) GetThread(ctx context.Context, serverID, channelID, threadID, userID string) (*channel.Thread, *channel.ThreadReadState, error) {
+	thread, _, err := s.threadAccess(ctx, serverID, channelID, threadID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	readState, err := s.threadRepo.FindReadState(ctx, threadID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return thread, readState, nil
+}
+
+// UpdateThreadCommand represents a request to update a thread.
+type UpdateThreadCommand struct {
+	ServerID         string
+	ChannelID        string
+	ThreadID         string
+	UserID           string
+	Name             *string
+	Archived         *bool
+	Locked           *bool
+	AutoArchiveAfter *time.Duration
+}
+
+// UpdateThread renames, archives or locks a thread. The owner can rename and
+// archive their thread; locking, and any change to a locked thread, requires
+// ManageMessages in the parent channel.
+func (s *ThreadService) UpdateThread(ctx context.Context, cmd UpdateThreadCommand) (*channel.Thread, error) {
+	thread, perms, err := s.threadAccess(ctx, cmd.ServerID, cmd.ChannelID, cmd.ThreadID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	moderator := perms.Has(server.PermissionManageMessages)
+	if thread.IsLocked && !moderator {
+		return nil, channel.ErrThreadLocked
+	}
+	if cmd.Locked != nil && !moderator {
+		return nil, channel.ErrNoPermission
+	}
+	if thread.OwnerID != cmd.UserID && !moderator {
+		return nil, channel.ErrNoPermission
+	}
+
+	if cmd.Name != nil {
+		thread.Name = strings.TrimSpace(*cmd.Name)
+	}
+	if cmd.AutoArchiveAfter != nil {
+		thread.AutoArchiveAfter = *cmd.AutoArchiveAfter
+	}
+	if err := validateThread(thread.Name, thread.AutoArchiveAfter); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if cmd.Locked != nil {
+		thread.IsLocked = *cmd.Locked
+	}
+	if cmd.Archived != nil && *cmd.Archived != thread.IsArchived {
+		if *cmd.Archived {
+			thread.Archive(now)
+		} else {
+			thread.Unarchive(now)
+		}
+	}
+	thread.UpdatedAt = now
+
+	if err := s.threadRepo.Update(ctx, thread); err != nil {
+		return nil, err
+	}
+
+	s.publish(ws.EventThreadUpdate, thread, cmd.UserID, thread)
+
+	return thread, nil
+}
+
+// DeleteThread deletes a thread and its messages. Only the owner or members
+// with ManageMessages in the parent channel can delete a thread.
+func (s *ThreadService) DeleteThread(ctx context.Context, serverID, channelID, threadID, userID string) error {
+	thread, perms, err := s.threadAccess(ctx, serverID, channelID, threadID, userID)
+	if err != nil {
+		return err
+	}
+
+	if thread.OwnerID != userID && !perms.Has(server.PermissionManageMessages) {
+		return channel.ErrNoPermission
+	}
+
+	if err := s.threadRepo.Delete(ctx, threadID); err != nil {
+		return err
+	}
+
+	s.publish(ws.EventThreadDelete, thread, userID, thread)
+
+	return nil
+}
+
+// GetThreadMessagesCommand represents a request to get a thread's messages.
+type GetThreadMessagesCommand struct {
+	ServerID  string
+	ChannelID string
+	ThreadID  string
+	UserID    string
+	Cursor    history.Cursor
+}
+
+// GetMessages retrieves the page of a thread's messages the cursor selects.
+// The cursor's anchor must be a message of the thread.
+func (s *ThreadService) GetMessages(ctx context.Context, cmd GetThreadMessagesCommand) ([]*channel.ChannelMessage, history.Page, error) {
+	thread, _, err := s.threadAccess(ctx, cmd.ServerID, cmd.ChannelID, cmd.ThreadID, cmd.UserID)
+	if err != nil {
+		return nil, history.Page{}, err
+	}
+
+	if anchor := cmd.Cursor.Anchor(); anchor != "" {
+		if err := s.requireThreadMessage(ctx, thread, anchor); err != nil {
+			return nil, history.Page{}, err
+		}
+	}
+
+	messages, page, err := s.messages.messageRepo.FindByThreadID(ctx, cmd.ThreadID, cmd.Cursor)
+	if err != nil {
+		return nil, history.Page{}, err
+	}
+
+	if err := s.messages.attachReactions(ctx, messages, cmd.UserID); err != nil {
+		return nil, history.Page{}, err
+	}
+	return messages, page, nil
+}
+
+// SendThreadMessageCommand represents a request to send a message in a thread.
+type SendThreadMessageCommand struct {
+	ServerID      string
+	ChannelID     string
+	ThreadID      string
+	UserID        string
+	Content       string
+	ReplyToID     *string
+	AttachmentIDs []string // Media uploaded by the sender, in display order
+}
+
+// SendMessage sends a message in a thread. Posting needs the same
+// permissions as posting in the parent channel; posting in an archived thread
+// reopens it, and only moderators can post in a locked thread. The parent
+// channel's slowmode applies, with a cooldown of its own. The sender joins
+// the thread.
+func (s *ThreadService) SendMessage(ctx context.Context, cmd SendThreadMessageCommand) (*channel.ChannelMessage, error) {
+	thread, perms, err := s.threadAccess(ctx, cmd.ServerID, cmd.ChannelID, cmd.ThreadID, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.IsLocked && !perms.Has(server.PermissionManageMessages) {
+		return nil, channel.ErrThreadLocked
+	}
+
+	ch, err := s.messages.channelRepo.FindByID(ctx, thread.ChannelID)
+	if err != nil {
+		return nil, channel.ErrNotFound
+	}
+	if _, err := s.messages.canSendMessage(ctx, ch, cmd.UserID, len(cmd.AttachmentIDs) > 0); err != nil {
+		return nil, err
+	}
+
+	if !validContent(cmd.Content, len(cmd.AttachmentIDs)) {
+		return nil, channel.ErrInvalidContent
+	}
+
+	// Replies stay within the thread
+	if cmd.ReplyToID != nil {
+		if err := s.requireThreadMessage(ctx, thread, *cmd.ReplyToID); err != nil {
+			return nil, err
+		}
+	}
+
+	attachments, err := s.messages.resolveAttachments(ctx, cmd.AttachmentIDs, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.messages.resolveMentions(ctx, ch, cmd.UserID, cmd.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.messages.enforceSlowmode(ctx, ch, thread.ID, cmd.UserID, perms); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	msg := &channel.ChannelMessage{
+		ID:        id.Generate("cmsg"),
+		ChannelID: thread.ChannelID,
+		ServerID:  thread.ServerID,
+		AuthorID:  cmd.UserID,
+		Content:   cmd.Content,
+		ReplyToID: cmd.ReplyToID,
+		ThreadID:  &thread.ID,
+		Mentions:  mentions,
+		CreatedAt: now,
+		UpdatedAt: now,
+
+		Attachments: attachments,
+	}
+
+	if err := s.messages.messageRepo.Create(ctx, msg); err != nil {
+		s.messages.releaseSlowmode(ctx, ch, thread.ID, cmd.UserID, perms)
+		return nil, err
+	}
+	s.messages.notifyMentions(ch, msg)
+
+	if err := s.threadRepo.RecordMessage(ctx, thread.ID, now); err != nil {
+		slog.Warn("failed to record thread activity", slog.Any("error", err), slog.String("thread_id", thread.ID))
+	}
+	thread.MessageCount++
+	thread.LastMessageAt = now
+
+	if thread.IsArchived {
+		thread.Unarchive(now)
+		if err := s.threadRepo.Update(ctx, thread); err != nil {
+			slog.Warn("failed to unarchive thread", slog.Any("error", err), slog.String("thread_id", thread.ID))
+		} else {
+			s.publish(ws.EventThreadUpdate, thread, cmd.UserID, thread)
+		}
+	}
+
+	s.join(ctx, thread, cmd.UserID)
+
+	return msg, nil
+}
+
+// EditMessage edits a message in a thread. Only the author can edit, and not
+// while the thread is locked unless they can moderate it.
+func (s *ThreadService) EditMessage(ctx context.Context, serverID, channelID, threadID, messageID, userID, content string) (*channel.ChannelMessage, error) {
+	thread, perms, err := s.threadAccess(ctx, serverID, channelID, threadID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.IsLocked && !perms.Has(server.PermissionManageMessages) {
+		return nil, channel.ErrThreadLocked
+	}
+
+	return s.messages.editMessage(ctx, serverID, channelID, thread.ID, messageID, userID, content)
+}
+
+// GetRevisions lists a thread message's edit history, with the same rules as
+// for channel messages.
+func (s *ThreadService) GetRevisions(ctx context.Context, serverID, channelID, threadID, messageID, userID string) ([]*revision.Revision, error) {
+	thread, _, err := s.threadAccess(ctx, serverID, channelID, threadID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.messages.getRevisions(ctx, serverID, channelID, thread.ID, messageID, userID)
+}
+
+// DeleteMessage deletes a message in a thread, with the same rules as
+// deleting a channel message.
+func (s *ThreadService) DeleteMessage(ctx context.Context, serverID, channelID, threadID, messageID, userID string) error {
+	thread, _, err := s.threadAccess(ctx, serverID, channelID, threadID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.messages.deleteMessage(ctx, serverID, channelID, thread.ID, messageID, userID)
+}
+
+// JoinThread adds the user to a thread's members.
+func (s *ThreadService) JoinThread(ctx context.Context, serverID, channelID, threadID, userID string) error {
+	thread, _, err := s.threadAccess(ctx, serverID, channelID, threadID, userID)
+	if err != nil {
+		return err
+	}
+
+	member := &channel.ThreadMember{ThreadID: thread.ID, UserID: userID, JoinedAt: time.Now()}
+	added, err := s.threadRepo.AddMember(ctx, member)
+	if err != nil {
+		return err
+	}
+	if added {
+		s.publish(ws.EventThreadMemberJoin, thread, userID, member)
+	}
+
+	return nil
+}
+
+// LeaveThread removes the user from a thread's members.
+func (s *ThreadService) LeaveThread(ctx context.Context, serverID, channelID, threadID, userID string) error {
+	thread, _, err := s.threadAccess(ctx, serverID, channelID, threadID, userID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.threadRepo.RemoveMember(ctx, thread.ID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return channel.ErrNotThreadMember
+	}
+
+	s.publish(ws.EventThreadMemberLeave, thread, userID, &channel.ThreadMember{ThreadID: thread.ID, UserID: userID})
+
+	return nil
+}
+
+// ListMembers lists a thread's members.
+func (s *ThreadService) ListMembers(ctx context.Context, serverID, channelID, threadID, userID string) ([]*channel.ThreadMember, error) {
+	if _, _, err := s.threadAccess(ctx, serverID, channelID, threadID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.threadRepo.FindMembers(ctx, threadID)
+}
+
+// MarkRead records the last message the user has read in a thread.
+func (s *ThreadService) MarkRead(ctx context.Context, serverID, channelID, threadID, userID, messageID string) error {
+	thread, _, err := s.threadAccess(ctx, serverID, channelID, threadID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.requireThreadMessage(ctx, thread, messageID); err != nil {
+		return err
+	}
+
+	return s.threadRepo.UpsertReadState(ctx, &channel.ThreadReadState{
+		ThreadID:          thread.ID,
+		UserID:            userID,
+		LastReadMessageID: &messageID,
+		LastReadAt:        time.Now(),
+	})
+}
+
+// ArchiveInactive archives every thread that has been inactive for longer
+// than its auto-archive duration and tells the parent channels.
+func (s *ThreadService) ArchiveInactive(ctx context.Context) (int, error) {
+	threads, err := s.threadRepo.ArchiveInactive(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, thread := range threads {
+		s.publish(ws.EventThreadUpdate, thread, "", thread)
+	}
+
+	return len(threads), nil
+}
+
+// ============================================================================
+// HELPER METHODS
+// ============================================================================
+
+// channelAccess loads a channel of the server and the user's permissions in
+// it. The user must be able to view the channel.
+func (s *ThreadService) channelAccess(ctx context.Context, serverID, channelID, userID string) (*channel.Channel, server.Permission, error) {
+	if err := s.messages.requireMembership(ctx, serverID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	ch, err := s.messages.channelRepo.FindByID(ctx, channelID)
+	if err != nil || ch == nil || ch.ServerID != serverID {
+		return nil, 0, channel.ErrNotFound
+	}
+
+	perms, err := s.messages.permissions.ChannelPermissionsFor(ctx, ch, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !perms.Has(server.PermissionViewChannel) {
+		return nil, 0, channel.ErrNoPermission
+	}
+
+	return ch, perms, nil
+}
+
+// threadAccess loads a thread of the channel and the user's permissions in
+// its parent channel, which the user must be able to view.
+func (s *ThreadService) threadAccess(ctx context.Context, serverID, channelID, threadID, userID string) (*channel.Thread, server.Permission, error) {
+	_, perms, err := s.channelAccess(ctx, serverID, channelID, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	thread, err := s.threadRepo.FindByID(ctx, threadID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if thread.ChannelID != channelID {
+		return nil, 0, channel.ErrThreadNotFound
+	}
+
+	return thread, perms, nil
+}
+
+// requireThreadMessage checks a message was posted in the thread.
+func (s *ThreadService) requireThreadMessage(ctx context.Context, thread *channel.Thread, messageID string) error {
+	msg, err := s.messages.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if msg.ThreadID == nil || *msg.ThreadID != thread.ID {
+		return channel.ErrMessageNotFound
+	}
+	return nil
+}
+
+// join adds a user to a thread's members, announcing it if they were not
+// one already. Failing to join does not fail the action that caused it.
+func (s *ThreadService) join(ctx context.Context, thread *channel.Thread, userID string) {
+	member := &channel.ThreadMember{ThreadID: thread.ID, UserID: userID, JoinedAt: time.Now()}
+	added, err := s.threadRepo.AddMember(ctx, member)
+	if err != nil {
+		slog.Warn("failed to add thread member", slog.Any("error", err), slog.String("thread_id", thread.ID))
+		return
+	}
+	if added {
+		thread.MemberCount++
+		s.publish(ws.EventThreadMemberJoin, thread, userID, member)
+	}
+}
+
+// publish sends a channel event to subscribers of the thread's parent
+// channel, if a publisher is set.
+func (s *ThreadService) publish(eventType ws.EventType, thread *channel.Thread, actorID string, payload interface{}) {
+	if s.events == nil {
+		return
+	}
+	s.events.PublishChannelEvent(ws.ChannelEvent{
+		Type:      eventType,
+		ServerID:  thread.ServerID,
+		ChannelID: thread.ChannelID,
+		ActorID:   actorID,
+		Payload:   payload,
+	})
+}
+
+// validateThread checks a thread's name and auto-archive duration.
+func validateThread(name string, autoArchive time.Duration) error {
+	if name == "" || utf8.RuneCountInString(name) > channel.MaxThreadNameLength {
+		return channel.ErrInvalidThread
+	}
+	if !channel.IsValidAutoArchive(autoArchive) {
+		return channel.ErrInvalidThread
+	}
+	return nil
+}
+
+// threadNameFrom derives a thread name from the first line of a message.
+func threadNameFrom(content string) string {
+	name, _, _ := strings.Cut(strings.TrimSpace(content), "\n")
+	name = strings.TrimSpace(name)
+	if utf8.RuneCountInString(name) > channel.MaxThreadNameLength {
+		name = string([]rune(name)[:channel.MaxThreadNameLength])
+	}
+	return name
+}
+
+// ThreadArchiveWorker periodically archives threads that have been inactive
+// for longer than their auto-archive duration.
+type ThreadArchiveWorker struct {
+	threads  *ThreadService
+	interval time.Duration
+}
+
+// NewThreadArchiveWorker creates a new archive worker.
+func NewThreadArchiveWorker(threads *ThreadService, interval time.Duration) *ThreadArchiveWorker {
+	return &ThreadArchiveWorker{
+		threads:  threads,
+		interval: interval,
+	}
+}
+
+// Start runs the sweep until ctx is cancelled.
+func (w *ThreadArchiveWorker) Start(ctx context.Context) {
+	periodic.Run(ctx, "thread archive worker", w.interval, w.Sweep)
+}
+
+// Sweep archives the threads that have gone inactive.
+func (w *ThreadArchiveWorker) Sweep(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	archived, err := w.threads.ArchiveInactive(ctx)
+	if err != nil {
+		slog.Error("thread archive sweep failed", slog.Any("error", err))
+		return
+	}
+	if archived > 0 {
+		slog.Info("thread archive sweep", slog.Int("archived", archived))
+	}
+}
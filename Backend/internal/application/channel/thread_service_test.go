@@ -0,0 +1,348 @@
+package channel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/permission"
+	"pink/internal/application/testutil"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
+)
+
+type threadMocks struct {
+	*serviceMocks
+	messageRepo *testutil.MockChannelMessageRepository
+	threadRepo  *testutil.MockThreadRepository
+	events      *recordingPublisher
+}
+
+// recordingPublisher collects the channel events a service publishes.
+type recordingPublisher struct {
+	events []ws.ChannelEvent
+}
+
+func (p *recordingPublisher) PublishChannelEvent(event ws.ChannelEvent) {
+	p.events = append(p.events, event)
+}
+
+func (p *recordingPublisher) types() []ws.EventType {
+	types := make([]ws.EventType, len(p.events))
+	for i, e := range p.events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+var moderator = server.Role{ID: "role_mod", ServerID: "serv_1", Position: 1, Permissions: server.PermissionManageMessages}
+
+// setupThreadService creates a thread service with mocked dependencies and
+// a text channel chan_1 in serv_1 without overwrites.
+func setupThreadService(t *testing.T) (*ThreadService, *threadMocks) {
+	_, sm := setupService(t)
+	m := &threadMocks{
+		serviceMocks: sm,
+		messageRepo:  new(testutil.MockChannelMessageRepository),
+		threadRepo:   new(testutil.MockThreadRepository),
+		events:       &recordingPublisher{},
+	}
+
+	resolver := permission.NewResolver(permission.NewEngine(), m.serverRepo, m.memberRepo, m.roleRepo, m.channelRepo, m.overwriteRepo)
+	messages := NewMessageService(m.messageRepo, m.channelRepo, m.memberRepo, m.serverRepo, m.auditRepo, resolver)
+	svc := NewThreadService(messages, m.threadRepo)
+	svc.SetEventPublisher(m.events)
+
+	m.channelRepo.On("FindByID", mock.Anything, "chan_1").Return(&channel.Channel{ID: "chan_1", ServerID: "serv_1", Type: channel.TypeText}, nil)
+	m.overwriteRepo.On("FindByChannelIDs", mock.Anything, mock.Anything).Return(map[string][]channel.PermissionOverwrite{}, nil)
+	return svc, m
+}
+
+// asThreadMember makes userID a member of serv_1 with the given roles.
+func (m *threadMocks) asThreadMember(ctx context.Context, userID string, roles ...server.Role) {
+	m.asMember(ctx, userID, roles...)
+	m.memberRepo.On("FindByServerAndUser", ctx, "serv_1", userID).
+		Return(&server.Member{ID: "memb_" + userID, ServerID: "serv_1", UserID: userID}, nil)
+}
+
+func (m *threadMocks) withThread(ctx context.Context, thread channel.Thread) *channel.Thread {
+	thread.ChannelID = "chan_1"
+	thread.ServerID = "serv_1"
+	if thread.AutoArchiveAfter == 0 {
+		thread.AutoArchiveAfter = channel.DefaultThreadAutoArchive
+	}
+	m.threadRepo.On("FindByID", ctx, thread.ID).Return(&thread, nil)
+	return &thread
+}
+
+func TestThreadService_CreateThread_FromMessage(t *testing.T) {
+	svc, m := setupThreadService(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.messageRepo.On("FindByID", ctx, "cmsg_1").Return(&channel.ChannelMessage{
+		ID: "cmsg_1", ChannelID: "chan_1", ServerID: "serv_1", Content: "Release notes\nfull text below",
+	}, nil)
+	m.threadRepo.On("Create", ctx, mock.MatchedBy(func(t *channel.Thread) bool {
+		return t.Name == "Release notes" && *t.ParentMessageID == "cmsg_1" && t.AutoArchiveAfter == 24*time.Hour
+	})).Return(nil)
+	m.threadRepo.On("AddMember", ctx, mock.MatchedBy(func(tm *channel.ThreadMember) bool {
+		return tm.UserID == "user_1"
+	})).Return(true, nil)
+
+	msgID := "cmsg_1"
+	thread, err := svc.CreateThread(ctx, CreateThreadCommand{ServerID: "serv_1", ChannelID: "chan_1", UserID: "user_1", MessageID: &msgID})
+
+	require.NoError(t, err)
+	assert.Equal(t, "user_1", thread.OwnerID)
+	assert.Equal(t, 1, thread.MemberCount)
+	assert.Equal(t, []ws.EventType{ws.EventThreadCreate}, m.events.types())
+	assert.Equal(t, "chan_1", m.events.events[0].ChannelID)
+	m.threadRepo.AssertExpectations(t)
+}
+
+func TestThreadService_CreateThread_Rejected(t *testing.T) {
+	readOnly := server.Role{ID: "role_everyone", ServerID: "serv_1", IsDefault: true, Permissions: server.PermissionViewChannel}
+	threadID := "thrd_1"
+
+	tests := []struct {
+		name    string
+		roles   []server.Role
+		cmd     CreateThreadCommand
+		wantErr error
+	}{
+		{"without send messages", []server.Role{readOnly}, CreateThreadCommand{Name: "Topic"}, channel.ErrNoPermission},
+		{"empty name", []server.Role{everyone}, CreateThreadCommand{Name: "  "}, channel.ErrInvalidThread},
+		{"unsupported auto-archive", []server.Role{everyone}, CreateThreadCommand{Name: "Topic", AutoArchiveAfter: 2 * time.Hour}, channel.ErrInvalidThread},
+		{"from a thread message", []server.Role{everyone}, CreateThreadCommand{Name: "Topic", MessageID: &threadID}, channel.ErrInvalidThread},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupThreadService(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, "user_1", tt.roles...)
+			m.messageRepo.On("FindByID", ctx, "thrd_1").Return(&channel.ChannelMessage{
+				ID: "thrd_1", ChannelID: "chan_1", ServerID: "serv_1", ThreadID: &threadID,
+			}, nil)
+
+			cmd := tt.cmd
+			cmd.ServerID, cmd.ChannelID, cmd.UserID = "serv_1", "chan_1", "user_1"
+			_, err := svc.CreateThread(ctx, cmd)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			m.threadRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestThreadService_HiddenParentChannel(t *testing.T) {
+	svc, m := setupThreadService(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.channelRepo.On("FindByID", ctx, "chan_private").Return(&channel.Channel{ID: "chan_private", ServerID: "serv_1", Type: channel.TypeText}, nil)
+	m.overwriteRepo.ExpectedCalls = nil
+	m.overwriteRepo.On("FindByChannelIDs", ctx, []string{"chan_private"}).Return(map[string][]channel.PermissionOverwrite{
+		"chan_private": {{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionViewChannel}},
+	}, nil)
+
+	_, _, err := svc.GetMessages(ctx, GetThreadMessagesCommand{ServerID: "serv_1", ChannelID: "chan_private", ThreadID: "thrd_1", UserID: "user_1"})
+
+	assert.ErrorIs(t, err, channel.ErrNoPermission)
+	m.messageRepo.AssertNotCalled(t, "FindByThreadID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestThreadService_SendMessage_LockedThread(t *testing.T) {
+	t.Run("members cannot post", func(t *testing.T) {
+		svc, m := setupThreadService(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_1", everyone)
+		m.withThread(ctx, channel.Thread{ID: "thrd_1", IsLocked: true})
+
+		_, err := svc.SendMessage(ctx, SendThreadMessageCommand{ServerID: "serv_1", ChannelID: "chan_1", ThreadID: "thrd_1", UserID: "user_1", Content: "hi"})
+
+		assert.ErrorIs(t, err, channel.ErrThreadLocked)
+		m.messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("moderators can post", func(t *testing.T) {
+		svc, m := setupThreadService(t)
+		ctx := context.Background()
+
+		m.asThreadMember(ctx, "user_mod", everyone, moderator)
+		m.withThread(ctx, channel.Thread{ID: "thrd_1", IsLocked: true})
+		m.messageRepo.On("Create", ctx, mock.MatchedBy(func(msg *channel.ChannelMessage) bool {
+			return *msg.ThreadID == "thrd_1" && msg.ChannelID == "chan_1"
+		})).Return(nil)
+		m.threadRepo.On("RecordMessage", ctx, "thrd_1", mock.Anything).Return(nil)
+		m.threadRepo.On("AddMember", ctx, mock.Anything).Return(false, nil)
+
+		_, err := svc.SendMessage(ctx, SendThreadMessageCommand{ServerID: "serv_1", ChannelID: "chan_1", ThreadID: "thrd_1", UserID: "user_mod", Content: "hi"})
+
+		require.NoError(t, err)
+		assert.Empty(t, m.events.events)
+	})
+}
+
+func TestThreadService_SendMessage_UnarchivesAndJoins(t *testing.T) {
+	svc, m := setupThreadService(t)
+	ctx := context.Background()
+
+	archivedAt := time.Now().Add(-time.Hour)
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.withThread(ctx, channel.Thread{ID: "thrd_1", IsArchived: true, ArchivedAt: &archivedAt})
+	m.messageRepo.On("Create", ctx, mock.AnythingOfType("*channel.ChannelMessage")).Return(nil)
+	m.threadRepo.On("RecordMessage", ctx, "thrd_1", mock.Anything).Return(nil)
+	m.threadRepo.On("Update", ctx, mock.MatchedBy(func(t *channel.Thread) bool {
+		return !t.IsArchived && t.ArchivedAt == nil
+	})).Return(nil)
+	m.threadRepo.On("AddMember", ctx, mock.Anything).Return(true, nil)
+
+	_, err := svc.SendMessage(ctx, SendThreadMessageCommand{ServerID: "serv_1", ChannelID: "chan_1", ThreadID: "thrd_1", UserID: "user_1", Content: "bump"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []ws.EventType{ws.EventThreadUpdate, ws.EventThreadMemberJoin}, m.events.types())
+	m.threadRepo.AssertExpectations(t)
+}
+
+func TestThreadService_SendMessage_ReplyOutsideThread(t *testing.T) {
+	svc, m := setupThreadService(t)
+	ctx := context.Background()
+
+	otherThread := "thrd_2"
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.withThread(ctx, channel.Thread{ID: "thrd_1"})
+	m.messageRepo.On("FindByID", ctx, "cmsg_channel").Return(&channel.ChannelMessage{ID: "cmsg_channel", ChannelID: "chan_1"}, nil)
+	m.messageRepo.On("FindByID", ctx, "cmsg_other").Return(&channel.ChannelMessage{ID: "cmsg_other", ChannelID: "chan_1", ThreadID: &otherThread}, nil)
+	m.messageRepo.On("FindByID", ctx, "cmsg_gone").Return(nil, channel.ErrMessageNotFound)
+
+	for _, replyTo := range []string{"cmsg_channel", "cmsg_other", "cmsg_gone"} {
+		_, err := svc.SendMessage(ctx, SendThreadMessageCommand{ServerID: "serv_1", ChannelID: "chan_1", ThreadID: "thrd_1", UserID: "user_1", Content: "hi", ReplyToID: &replyTo})
+
+		assert.ErrorIs(t, err, channel.ErrMessageNotFound, replyTo)
+	}
+	m.messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestThreadService_SendMessage_SlowmodePerThread(t *testing.T) {
+	svc, m, limiter := setupSlowmode(t)
+	ctx := context.Background()
+
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.withThread(ctx, channel.Thread{ID: "thrd_1"})
+	limiter.On("Acquire", ctx, "thrd_1", "user_1", 10*time.Second).Return(time.Duration(0), nil)
+	m.messageRepo.On("Create", ctx, mock.AnythingOfType("*channel.ChannelMessage")).Return(nil)
+	m.threadRepo.On("RecordMessage", ctx, "thrd_1", mock.Anything).Return(nil)
+	m.threadRepo.On("AddMember", ctx, mock.Anything).Return(false, nil)
+
+	_, err := svc.SendMessage(ctx, SendThreadMessageCommand{ServerID: "serv_1", ChannelID: "chan_1", ThreadID: "thrd_1", UserID: "user_1", Content: "hi"})
+
+	require.NoError(t, err)
+	limiter.AssertExpectations(t)
+}
+
+func TestThreadService_UpdateThread_Permissions(t *testing.T) {
+	locked := true
+	name := "Renamed"
+
+	tests := []struct {
+		name    string
+		userID  string
+		roles   []server.Role
+		thread  channel.Thread
+		cmd     UpdateThreadCommand
+		wantErr error
+	}{
+		{"other member renames", "user_2", []server.Role{everyone}, channel.Thread{ID: "thrd_1", OwnerID: "user_1"}, UpdateThreadCommand{Name: &name}, channel.ErrNoPermission},
+		{"owner locks", "user_1", []server.Role{everyone}, channel.Thread{ID: "thrd_1", OwnerID: "user_1"}, UpdateThreadCommand{Locked: &locked}, channel.ErrNoPermission},
+		{"owner renames locked thread", "user_1", []server.Role{everyone}, channel.Thread{ID: "thrd_1", OwnerID: "user_1", IsLocked: true}, UpdateThreadCommand{Name: &name}, channel.ErrThreadLocked},
+		{"owner renames", "user_1", []server.Role{everyone}, channel.Thread{ID: "thrd_1", OwnerID: "user_1", Name: "Topic"}, UpdateThreadCommand{Name: &name}, nil},
+		{"moderator locks", "user_mod", []server.Role{everyone, moderator}, channel.Thread{ID: "thrd_1", OwnerID: "user_1", Name: "Topic"}, UpdateThreadCommand{Locked: &locked}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, m := setupThreadService(t)
+			ctx := context.Background()
+
+			m.asThreadMember(ctx, tt.userID, tt.roles...)
+			m.withThread(ctx, tt.thread)
+			m.threadRepo.On("Update", ctx, mock.AnythingOfType("*channel.Thread")).Return(nil)
+
+			cmd := tt.cmd
+			cmd.ServerID, cmd.ChannelID, cmd.ThreadID, cmd.UserID = "serv_1", "chan_1", "thrd_1", tt.userID
+			_, err := svc.UpdateThread(ctx, cmd)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				m.threadRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []ws.EventType{ws.EventThreadUpdate}, m.events.types())
+		})
+	}
+}
+
+func TestMessageService_ThreadMessagesOnlyThroughThread(t *testing.T) {
+	svc, m := setupThreadService(t)
+	ctx := context.Background()
+
+	threadID := "thrd_1"
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.withThread(ctx, channel.Thread{ID: threadID, IsLocked: true})
+	m.messageRepo.On("FindByID", ctx, "cmsg_thread").Return(&channel.ChannelMessage{
+		ID: "cmsg_thread", ChannelID: "chan_1", ServerID: "serv_1", AuthorID: "user_1", ThreadID: &threadID, Content: "hi",
+	}, nil)
+	m.messageRepo.On("Delete", ctx, "cmsg_thread").Return(nil)
+
+	_, err := svc.messages.EditMessage(ctx, "serv_1", "chan_1", "cmsg_thread", "user_1", "edited")
+	assert.ErrorIs(t, err, channel.ErrMessageNotFound)
+	_, err = svc.EditMessage(ctx, "serv_1", "chan_1", threadID, "cmsg_thread", "user_1", "edited")
+	assert.ErrorIs(t, err, channel.ErrThreadLocked)
+	m.messageRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+
+	assert.ErrorIs(t, svc.messages.DeleteMessage(ctx, "serv_1", "chan_1", "cmsg_thread", "user_1"), channel.ErrMessageNotFound)
+	m.messageRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	require.NoError(t, svc.DeleteMessage(ctx, "serv_1", "chan_1", threadID, "cmsg_thread", "user_1"))
+}
+
+func TestThreadService_MarkRead_RequiresThreadMessage(t *testing.T) {
+	svc, m := setupThreadService(t)
+	ctx := context.Background()
+
+	threadID := "thrd_1"
+	m.asThreadMember(ctx, "user_1", everyone)
+	m.withThread(ctx, channel.Thread{ID: threadID})
+	m.messageRepo.On("FindByID", ctx, "cmsg_channel").Return(&channel.ChannelMessage{ID: "cmsg_channel", ChannelID: "chan_1"}, nil)
+	m.messageRepo.On("FindByID", ctx, "cmsg_thread").Return(&channel.ChannelMessage{ID: "cmsg_thread", ChannelID: "chan_1", ThreadID: &threadID}, nil)
+	m.threadRepo.On("UpsertReadState", ctx, mock.MatchedBy(func(rs *channel.ThreadReadState) bool {
+		return rs.UserID == "user_1" && *rs.LastReadMessageID == "cmsg_thread"
+	})).Return(nil)
+
+	assert.ErrorIs(t, svc.MarkRead(ctx, "serv_1", "chan_1", threadID, "user_1", "cmsg_channel"), channel.ErrMessageNotFound)
+	require.NoError(t, svc.MarkRead(ctx, "serv_1", "chan_1", threadID, "user_1", "cmsg_thread"))
+	m.threadRepo.AssertNumberOfCalls(t, "UpsertReadState", 1)
+}
+
+func TestThreadArchiveWorker_Sweep(t *testing.T) {
+	svc, m := setupThreadService(t)
+
+	m.threadRepo.On("ArchiveInactive", mock.Anything, mock.AnythingOfType("time.Time")).Return([]*channel.Thread{
+		{ID: "thrd_1", ChannelID: "chan_1", ServerID: "serv_1", IsArchived: true},
+		{ID: "thrd_2", ChannelID: "chan_2", ServerID: "serv_1", IsArchived: true},
+	}, nil)
+
+	NewThreadArchiveWorker(svc, time.Minute).Sweep(context.Background())
+
+	assert.Equal(t, []ws.EventType{ws.EventThreadUpdate, ws.EventThreadUpdate}, m.events.types())
+	assert.Equal(t, "chan_2", m.events.events[1].ChannelID)
+}
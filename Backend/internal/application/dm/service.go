@@ -3,16 +3,25 @@ package dm
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	"pink/internal/domain/dm"
+	"pink/internal/domain/history"
+	"pink/internal/domain/media"
+	"pink/internal/domain/reaction"
+	"pink/internal/domain/revision"
 	"pink/internal/pkg/id"
 )
 
 // Service provides DM-related operations.
 type Service struct {
-	convRepo    dm.ConversationRepository
-	messageRepo dm.MessageRepository
+	convRepo     dm.ConversationRepository
+	messageRepo  dm.MessageRepository
+	reactionRepo reaction.Repository
+	revisions    revision.Repository
+	mediaRepo    media.Repository
+	mediaFiles   media.Storage
 }
 
 // NewService creates a new DM service.
@@ -26,6 +35,27 @@ func NewService(
 	}
 }
 
+// SetReactionRepository enables message reactions. It must be set before
+// the reaction methods are used; until then messages are listed without
+// reaction counts.
+func (s *Service) SetReactionRepository(reactionRepo reaction.Repository) {
+	s.reactionRepo = reactionRepo
+}
+
+// SetRevisionRepository enables edit history. Until it is set, edits
+// overwrite messages without keeping what they replaced.
+func (s *Service) SetRevisionRepository(revisions revision.Repository) {
+	s.revisions = revisions
+}
+
+// SetMediaStore enables message attachments: uploaded media is looked up in
+// mediaRepo, and attached media is removed from both stores along with its
+// message. Until it is set, messages cannot carry attachments.
+func (s *Service) SetMediaStore(mediaRepo media.Repository, files media.Storage) {
+	s.mediaRepo = mediaRepo
+	s.mediaFiles = files
+}
+
 // GetConversations retrieves all conversations for a user.
 func (s *Service) GetConversations(ctx context.Context, userID string) ([]*dm.Conversation, error) {
 	convs, err := s.convRepo.FindByUserID(ctx, userID)
@@ -88,17 +118,36 @@ func (s *Service) StartConversation(ctx context.Context, userID, otherUserID str
 	return conv, nil
 }
 
-// GetMessages retrieves messages in a conversation.
-func (s *Service) GetMessages(ctx context.Context, convID, userID, cursor string, limit int) ([]*dm.Message, string, error) {
+// GetMessages retrieves the page of a conversation's messages the cursor
+// selects. The cursor's anchor must be a message of the conversation.
+func (s *Service) GetMessages(ctx context.Context, convID, userID string, cursor history.Cursor) ([]*dm.Message, history.Page, error) {
 	isParticipant, err := s.convRepo.IsParticipant(ctx, convID, userID)
 	if err != nil {
-		return nil, "", err
+		return nil, history.Page{}, err
 	}
 	if !isParticipant {
-		return nil, "", dm.ErrNotParticipant
+		return nil, history.Page{}, dm.ErrNotParticipant
+	}
+
+	if anchor := cursor.Anchor(); anchor != "" {
+		msg, err := s.messageRepo.FindByID(ctx, anchor)
+		if err != nil {
+			return nil, history.Page{}, err
+		}
+		if msg.ConversationID != convID {
+			return nil, history.Page{}, dm.ErrMessageNotFound
+		}
 	}
 
-	return s.messageRepo.FindByConversationID(ctx, convID, cursor, limit)
+	messages, page, err := s.messageRepo.FindByConversationID(ctx, convID, cursor)
+	if err != nil {
+		return nil, history.Page{}, err
+	}
+
+	if err := s.attachReactions(ctx, messages, userID); err != nil {
+		return nil, history.Page{}, err
+	}
+	return messages, page, nil
 }
 
 // SendMessageCommand represents a message send request.
@@ -106,11 +155,12 @@ type SendMessageCommand struct {
 	ConversationID string
 	SenderID       string
 	Content        string
+	AttachmentIDs  []string // Media uploaded by the sender, in display order
 }
 
 // SendMessage sends a message in a conversation.
 func (s *Service) SendMessage(ctx context.Context, cmd SendMessageCommand) (*dm.Message, error) {
-	if len(cmd.Content) == 0 || len(cmd.Content) > 2000 {
+	if !validContent(cmd.Content, len(cmd.AttachmentIDs)) {
 		return nil, dm.ErrInvalidContent
 	}
 
@@ -122,6 +172,11 @@ func (s *Service) SendMessage(ctx context.Context, cmd SendMessageCommand) (*dm.
 		return nil, dm.ErrNotParticipant
 	}
 
+	attachments, err := s.resolveAttachments(ctx, cmd.AttachmentIDs, cmd.SenderID)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
 	msg := &dm.Message{
 		ID:             id.Generate("dmsg"),
@@ -131,6 +186,7 @@ func (s *Service) SendMessage(ctx context.Context, cmd SendMessageCommand) (*dm.
 		IsEdited:       false,
 		CreatedAt:      now,
 		UpdatedAt:      now,
+		Attachments:    attachments,
 	}
 
 	if err := s.messageRepo.Create(ctx, msg); err != nil {
@@ -145,7 +201,7 @@ func (s *Service) SendMessage(ctx context.Context, cmd SendMessageCommand) (*dm.
 
 // EditMessage edits a message.
 func (s *Service) EditMessage(ctx context.Context, messageID, userID, content string) (*dm.Message, error) {
-	if len(content) == 0 || len(content) > 2000 {
+	if len(content) > 2000 {
 		return nil, dm.ErrInvalidContent
 	}
 
@@ -158,6 +214,24 @@ func (s *Service) EditMessage(ctx context.Context, messageID, userID, content st
 		return nil, dm.ErrNoPermission
 	}
 
+	// A message with attachments may be left without text
+	if !validContent(content, len(msg.Attachments)) {
+		return nil, dm.ErrInvalidContent
+	}
+
+	// Keep what the edit replaces; the edit fails if it cannot be kept
+	if s.revisions != nil && msg.Content != content {
+		if err := s.revisions.Create(ctx, &revision.Revision{
+			ID:        id.Generate("rev"),
+			MessageID: msg.ID,
+			Kind:      revision.KindDM,
+			Content:   msg.Content,
+			EditedAt:  time.Now(),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	msg.Content = content
 	msg.IsEdited = true
 
@@ -168,18 +242,88 @@ func (s *Service) EditMessage(ctx context.Context, messageID, userID, content st
 	return msg, nil
 }
 
-// DeleteMessage deletes a message.
-func (s *Service) DeleteMessage(ctx context.Context, messageID, userID string) error {
+// GetRevisions lists what a message said before each of its edits, oldest
+// first. Only its sender can see them.
+func (s *Service) GetRevisions(ctx context.Context, messageID, userID string) ([]*revision.Revision, error) {
 	msg, err := s.messageRepo.FindByID(ctx, messageID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if msg.SenderID != userID {
+		return nil, dm.ErrNoPermission
+	}
+
+	if s.revisions == nil {
+		return nil, nil
+	}
+	return s.revisions.FindByMessageID(ctx, messageID)
+}
+
+// DeleteMessage deletes a message and returns it, so callers can tell the
+// conversation it actually belonged to.
+func (s *Service) DeleteMessage(ctx context.Context, messageID, userID string) (*dm.Message, error) {
+	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
 	}
 
 	if msg.SenderID != userID {
-		return dm.ErrNoPermission
+		return nil, dm.ErrNoPermission
+	}
+
+	if err := s.messageRepo.Delete(ctx, messageID); err != nil {
+		return nil, err
+	}
+	s.removeAttachments(ctx, msg.Attachments)
+
+	return msg, nil
+}
+
+// validContent reports whether content is acceptable for a message with the
+// given number of attachments: text is optional only when files are attached.
+func validContent(content string, attachments int) bool {
+	if len(content) > 2000 {
+		return false
+	}
+	return len(content) > 0 || attachments > 0
+}
+
+// resolveAttachments loads the media to attach to a new message, checking
+// that the sender uploaded all of it.
+func (s *Service) resolveAttachments(ctx context.Context, ids []string, userID string) ([]*media.Media, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if s.mediaRepo == nil {
+		return nil, media.ErrNotFound
+	}
+	if len(ids) > media.MaxAttachmentsPerMessage {
+		return nil, media.ErrTooManyAttachments
 	}
 
-	return s.messageRepo.Delete(ctx, messageID)
+	found, err := s.mediaRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return media.OrderAttachments(ids, found, userID)
+}
+
+// removeAttachments deletes the media of a deleted message. Failures are
+// logged: the message is already gone.
+func (s *Service) removeAttachments(ctx context.Context, attachments []*media.Media) {
+	if s.mediaRepo == nil {
+		return
+	}
+	for _, m := range attachments {
+		if err := s.mediaRepo.Delete(ctx, m.ID); err != nil {
+			slog.Warn("failed to delete attachment", slog.Any("error", err), slog.String("media_id", m.ID))
+			continue
+		}
+		if err := s.mediaFiles.Remove(m); err != nil {
+			slog.Warn("failed to remove attachment file", slog.Any("error", err), slog.String("media_id", m.ID))
+		}
+	}
 }
 
 // MarkAsRead marks a conversation as read.
@@ -194,3 +338,178 @@ func (s *Service) MarkAsRead(ctx context.Context, convID, userID string) error {
 
 	return s.messageRepo.MarkAsRead(ctx, convID, userID, "")
 }
+
+// AddReaction reacts to a message as userID. It reports whether the reaction
+// is new; reacting twice with the same emoji changes nothing.
+func (s *Service) AddReaction(ctx context.Context, messageID, userID, emojiStr string) (*dm.Message, *reaction.Reaction, bool, error) {
+	if s.reactionRepo == nil {
+		return nil, nil, false, reaction.ErrUnavailable
+	}
+
+	emoji, err := reaction.ParseEmoji(emojiStr)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	msg, err := s.participantMessage(ctx, messageID, userID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	rc := &reaction.Reaction{
+		MessageID: msg.ID,
+		Kind:      reaction.KindDM,
+		UserID:    userID,
+		Emoji:     emoji,
+		CreatedAt: time.Now(),
+	}
+	added, err := s.reactionRepo.Add(ctx, rc)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return msg, rc, added, nil
+}
+
+// RemoveReaction removes the user's own reaction from a message. It reports
+// whether the reaction existed.
+func (s *Service) RemoveReaction(ctx context.Context, messageID, userID, emojiStr string) (*dm.Message, *reaction.Reaction, bool, error) {
+	if s.reactionRepo == nil {
+		return nil, nil, false, reaction.ErrUnavailable
+	}
+
+	emoji, err := reaction.ParseEmoji(emojiStr)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	msg, err := s.participantMessage(ctx, messageID, userID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	removed, err := s.reactionRepo.Remove(ctx, msg.ID, userID, emoji)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return msg, &reaction.Reaction{MessageID: msg.ID, Kind: reaction.KindDM, UserID: userID, Emoji: emoji}, removed, nil
+}
+
+// ListReactionUsers lists who reacted to a message with an emoji.
+func (s *Service) ListReactionUsers(ctx context.Context, messageID, userID, emojiStr, cursor string, limit int) ([]*reaction.Reaction, string, error) {
+	if s.reactionRepo == nil {
+		return nil, "", reaction.ErrUnavailable
+	}
+
+	emoji, err := reaction.ParseEmoji(emojiStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	msg, err := s.participantMessage(ctx, messageID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return s.reactionRepo.FindUsers(ctx, msg.ID, emoji, cursor, limit)
+}
+
+// PinMessage pins a message to its conversation; either participant can. It
+// reports whether the message was not already pinned.
+func (s *Service) PinMessage(ctx context.Context, convID, messageID, userID string) (*dm.Message, bool, error) {
+	msg, err := s.participantMessage(ctx, messageID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if msg.ConversationID != convID {
+		return nil, false, dm.ErrMessageNotFound
+	}
+	if msg.IsPinned {
+		return msg, false, nil
+	}
+
+	msg.Pin(userID, time.Now())
+	if err := s.messageRepo.UpdatePin(ctx, msg); err != nil {
+		return nil, false, err
+	}
+	return msg, true, nil
+}
+
+// UnpinMessage removes a message from its conversation's pins. It reports
+// whether the message was pinned.
+func (s *Service) UnpinMessage(ctx context.Context, convID, messageID, userID string) (*dm.Message, bool, error) {
+	msg, err := s.participantMessage(ctx, messageID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+	if msg.ConversationID != convID {
+		return nil, false, dm.ErrMessageNotFound
+	}
+	if !msg.IsPinned {
+		return msg, false, nil
+	}
+
+	msg.Unpin()
+	if err := s.messageRepo.UpdatePin(ctx, msg); err != nil {
+		return nil, false, err
+	}
+	return msg, true, nil
+}
+
+// GetPins lists a conversation's pinned messages, most recently pinned first.
+func (s *Service) GetPins(ctx context.Context, convID, userID string) ([]*dm.Message, error) {
+	isParticipant, err := s.convRepo.IsParticipant(ctx, convID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isParticipant {
+		return nil, dm.ErrNotParticipant
+	}
+
+	messages, err := s.messageRepo.FindPinned(ctx, convID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachReactions(ctx, messages, userID); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// participantMessage loads a message of a conversation the user takes part in.
+func (s *Service) participantMessage(ctx context.Context, messageID, userID string) (*dm.Message, error) {
+	msg, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	isParticipant, err := s.convRepo.IsParticipant(ctx, msg.ConversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isParticipant {
+		return nil, dm.ErrNotParticipant
+	}
+	return msg, nil
+}
+
+// attachReactions fills in each message's reaction counts for userID.
+func (s *Service) attachReactions(ctx context.Context, messages []*dm.Message, userID string) error {
+	if s.reactionRepo == nil || len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+
+	summaries, err := s.reactionRepo.Summarize(ctx, ids, userID)
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		msg.Reactions = summaries[msg.ID]
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package dm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/testutil"
+	"pink/internal/domain/dm"
+	"pink/internal/domain/history"
+	"pink/internal/domain/media"
+	"pink/internal/domain/revision"
+)
+
+// setupDMService creates a DM service with mocked dependencies and message
+// dmsg_1 from user_1 in conv_1, which user_1 and user_2 take part in.
+func setupDMService(t *testing.T) (*Service, *testutil.MockConversationRepository, *testutil.MockDMMessageRepository) {
+	convRepo := new(testutil.MockConversationRepository)
+	messageRepo := new(testutil.MockDMMessageRepository)
+
+	messageRepo.On("FindByID", mock.Anything, "dmsg_1").Return(&dm.Message{ID: "dmsg_1", ConversationID: "conv_1", SenderID: "user_1"}, nil)
+	convRepo.On("IsParticipant", mock.Anything, "conv_1", "user_1").Return(true, nil)
+	convRepo.On("IsParticipant", mock.Anything, "conv_1", "user_2").Return(true, nil)
+	convRepo.On("IsParticipant", mock.Anything, "conv_1", "user_3").Return(false, nil)
+
+	return NewService(convRepo, messageRepo), convRepo, messageRepo
+}
+
+func TestService_PinMessage_EitherParticipant(t *testing.T) {
+	svc, _, messageRepo := setupDMService(t)
+	ctx := context.Background()
+
+	messageRepo.On("UpdatePin", ctx, mock.MatchedBy(func(msg *dm.Message) bool {
+		return msg.IsPinned && *msg.PinnedBy == "user_2"
+	})).Return(nil)
+
+	msg, pinned, err := svc.PinMessage(ctx, "conv_1", "dmsg_1", "user_2")
+
+	require.NoError(t, err)
+	assert.True(t, pinned)
+	assert.True(t, msg.IsPinned)
+}
+
+func TestService_PinMessage_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		convID  string
+		userID  string
+		wantErr error
+	}{
+		{"not a participant", "conv_1", "user_3", dm.ErrNotParticipant},
+		{"message from another conversation", "conv_2", "user_1", dm.ErrMessageNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, messageRepo := setupDMService(t)
+			ctx := context.Background()
+
+			_, _, err := svc.PinMessage(ctx, tt.convID, "dmsg_1", tt.userID)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			messageRepo.AssertNotCalled(t, "UpdatePin", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestService_PinMessage_LimitReached(t *testing.T) {
+	svc, _, messageRepo := setupDMService(t)
+	ctx := context.Background()
+
+	messageRepo.On("UpdatePin", ctx, mock.Anything).Return(dm.ErrTooManyPins)
+
+	_, pinned, err := svc.PinMessage(ctx, "conv_1", "dmsg_1", "user_1")
+
+	assert.ErrorIs(t, err, dm.ErrTooManyPins)
+	assert.False(t, pinned)
+}
+
+func TestService_SendMessage_Attachments(t *testing.T) {
+	t.Run("attachment-only message", func(t *testing.T) {
+		svc, convRepo, messageRepo := setupDMService(t)
+		mediaRepo := new(testutil.MockMediaRepository)
+		svc.SetMediaStore(mediaRepo, new(testutil.MockMediaStorage))
+		ctx := context.Background()
+
+		mediaRepo.On("FindByIDs", ctx, []string{"med_a"}).Return([]*media.Media{{ID: "med_a", UserID: "user_1"}}, nil)
+		messageRepo.On("Create", ctx, mock.MatchedBy(func(msg *dm.Message) bool {
+			return msg.Content == "" && len(msg.Attachments) == 1
+		})).Return(nil)
+		convRepo.On("UpdateLastMessage", ctx, "conv_1", mock.Anything).Return(nil)
+
+		msg, err := svc.SendMessage(ctx, SendMessageCommand{ConversationID: "conv_1", SenderID: "user_1", AttachmentIDs: []string{"med_a"}})
+
+		require.NoError(t, err)
+		assert.Equal(t, "med_a", msg.Attachments[0].ID)
+	})
+
+	t.Run("someone else's upload", func(t *testing.T) {
+		svc, _, messageRepo := setupDMService(t)
+		mediaRepo := new(testutil.MockMediaRepository)
+		svc.SetMediaStore(mediaRepo, new(testutil.MockMediaStorage))
+		ctx := context.Background()
+
+		mediaRepo.On("FindByIDs", ctx, []string{"med_a"}).Return([]*media.Media{{ID: "med_a", UserID: "user_2"}}, nil)
+
+		_, err := svc.SendMessage(ctx, SendMessageCommand{ConversationID: "conv_1", SenderID: "user_1", AttachmentIDs: []string{"med_a"}})
+
+		assert.ErrorIs(t, err, media.ErrNoPermission)
+		messageRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_EditMessage_RecordsRevision(t *testing.T) {
+	svc, _, messageRepo := setupDMService(t)
+	revisions := new(testutil.MockMessageRevisionRepository)
+	svc.SetRevisionRepository(revisions)
+	ctx := context.Background()
+
+	msg, _ := messageRepo.FindByID(ctx, "dmsg_1")
+	msg.Content = "first"
+	revisions.On("Create", ctx, mock.MatchedBy(func(rev *revision.Revision) bool {
+		return rev.MessageID == "dmsg_1" && rev.Kind == revision.KindDM && rev.Content == "first"
+	})).Return(nil)
+	messageRepo.On("Update", ctx, msg).Return(nil)
+
+	edited, err := svc.EditMessage(ctx, "dmsg_1", "user_1", "second")
+
+	require.NoError(t, err)
+	assert.Equal(t, "second", edited.Content)
+	revisions.AssertExpectations(t)
+}
+
+func TestService_GetRevisions_OnlySender(t *testing.T) {
+	svc, _, _ := setupDMService(t)
+	revisions := new(testutil.MockMessageRevisionRepository)
+	svc.SetRevisionRepository(revisions)
+	ctx := context.Background()
+
+	history := []*revision.Revision{{ID: "rev_1", MessageID: "dmsg_1", Kind: revision.KindDM, Content: "first"}}
+	revisions.On("FindByMessageID", ctx, "dmsg_1").Return(history, nil)
+
+	got, err := svc.GetRevisions(ctx, "dmsg_1", "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, history, got)
+
+	_, err = svc.GetRevisions(ctx, "dmsg_1", "user_2")
+	assert.ErrorIs(t, err, dm.ErrNoPermission)
+}
+
+func TestService_GetMessages_AnchorFromAnotherConversation(t *testing.T) {
+	svc, convRepo, messageRepo := setupDMService(t)
+	ctx := context.Background()
+
+	convRepo.On("IsParticipant", ctx, "conv_2", "user_1").Return(true, nil)
+
+	_, _, err := svc.GetMessages(ctx, "conv_2", "user_1", history.Cursor{Around: "dmsg_1", Limit: 20})
+
+	assert.ErrorIs(t, err, dm.ErrMessageNotFound)
+	messageRepo.AssertNotCalled(t, "FindByConversationID", mock.Anything, mock.Anything, mock.Anything)
+}
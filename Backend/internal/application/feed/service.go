@@ -18,7 +18,7 @@ type Service struct {
 	postRepo     post.Repository
 	reactionRepo post.ReactionRepository
 	userRepo     user.Repository
-	notifRepo    notification.Repository
+	notifier     notification.Dispatcher
 }
 
 // NewService creates a new feed service.
@@ -26,13 +26,13 @@ func NewService(
 	postRepo post.Repository,
 	reactionRepo post.ReactionRepository,
 	userRepo user.Repository,
-	notifRepo notification.Repository,
+	notifier notification.Dispatcher,
 ) *Service {
 	return &Service{
 		postRepo:     postRepo,
 		reactionRepo: reactionRepo,
 		userRepo:     userRepo,
-		notifRepo:    notifRepo,
+		notifier:     notifier,
 	}
 }
 
@@ -123,17 +123,15 @@ func (s *Service) processMentions(ctx context.Context, p *post.Post) {
 
 		// Create notification
 		notif := &notification.Notification{
-			ID:         id.Generate("notif"),
 			UserID:     u.ID,
 			Type:       notification.TypeMention,
 			ActorID:    &p.AuthorID,
 			TargetType: stringPtr("post"),
 			TargetID:   &p.ID,
 			Message:    "mentioned you in a post",
-			CreatedAt:  time.Now(),
 		}
 
-		_ = s.notifRepo.Create(ctx, notif)
+		_ = s.notifier.Dispatch(ctx, notif)
 	}
 }
 
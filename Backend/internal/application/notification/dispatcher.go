@@ -0,0 +1,104 @@
+// Package notification provides notification delivery services.
+package notification
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"pink/internal/domain/notification"
+	"pink/internal/pkg/id"
+)
+
+// ErrMissingRecipient is returned when a notification has no recipient.
+var ErrMissingRecipient = errors.New("notification has no recipient")
+
+// Dispatcher implements notification.Dispatcher. Delivery failures are
+// logged rather than returned since the notification is already stored and
+// will be picked up the next time the client lists notifications.
+type Dispatcher struct {
+	repo       notification.Repository
+	deliverers []notification.Deliverer
+}
+
+// NewDispatcher creates a new notification dispatcher.
+func NewDispatcher(repo notification.Repository, deliverers ...notification.Deliverer) *Dispatcher {
+	return &Dispatcher{
+		repo:       repo,
+		deliverers: deliverers,
+	}
+}
+
+// AddDeliverer registers an additional delivery channel.
+func (d *Dispatcher) AddDeliverer(deliverer notification.Deliverer) {
+	d.deliverers = append(d.deliverers, deliverer)
+}
+
+// Dispatch persists a notification and delivers it to the recipient.
+// Notifications about a user's own actions are dropped.
+func (d *Dispatcher) Dispatch(ctx context.Context, n *notification.Notification) error {
+	if n.UserID == "" {
+		return ErrMissingRecipient
+	}
+	if n.ActorID != nil && *n.ActorID == n.UserID {
+		return nil
+	}
+
+	if n.ID == "" {
+		n.ID = id.Generate("noti")
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+
+	if err := d.repo.Create(ctx, n); err != nil {
+		return err
+	}
+
+	if len(d.deliverers) == 0 {
+		return nil
+	}
+
+	// Reload to pick up the joined actor
+	stored, err := d.repo.FindByID(ctx, n.ID)
+	if err != nil {
+		slog.Warn("failed to reload notification", slog.Any("error", err), slog.String("notificationId", n.ID))
+		stored = n
+	}
+
+	count, err := d.repo.GetUnreadCount(ctx, n.UserID)
+	if err != nil {
+		slog.Warn("failed to get unread count", slog.Any("error", err), slog.String("userId", n.UserID))
+		return nil
+	}
+
+	for _, deliverer := range d.deliverers {
+		if err := deliverer.Deliver(ctx, stored, count); err != nil {
+			slog.Error("failed to deliver notification", slog.Any("error", err), slog.String("notificationId", n.ID))
+		}
+	}
+
+	return nil
+}
+
+// SyncUnreadCount pushes the user's current unread count to every delivery
+// channel.
+func (d *Dispatcher) SyncUnreadCount(ctx context.Context, userID string) error {
+	if len(d.deliverers) == 0 {
+		return nil
+	}
+
+	count, err := d.repo.GetUnreadCount(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, deliverer := range d.deliverers {
+		if err := deliverer.DeliverUnreadCount(ctx, userID, count); err != nil {
+			slog.Error("failed to deliver unread count", slog.Any("error", err), slog.String("userId", userID))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/testutil"
+	"pink/internal/domain/notification"
+)
+
+type recordingDeliverer struct {
+	delivered []*notification.Notification
+	counts    []int
+}
+
+func (d *recordingDeliverer) Deliver(ctx context.Context, n *notification.Notification, unreadCount int) error {
+	d.delivered = append(d.delivered, n)
+	d.counts = append(d.counts, unreadCount)
+	return nil
+}
+
+func (d *recordingDeliverer) DeliverUnreadCount(ctx context.Context, userID string, unreadCount int) error {
+	d.counts = append(d.counts, unreadCount)
+	return nil
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestDispatcher_Dispatch_PersistsAndDelivers(t *testing.T) {
+	ctx := context.Background()
+	repo := new(testutil.MockNotificationRepository)
+	deliverer := &recordingDeliverer{}
+	d := NewDispatcher(repo, deliverer)
+
+	n := &notification.Notification{
+		UserID:  "user_recipient",
+		Type:    notification.TypeFollow,
+		ActorID: strPtr("user_actor"),
+		Message: "started following you",
+	}
+	stored := &notification.Notification{
+		UserID: n.UserID,
+		Type:   n.Type,
+		Actor:  &notification.ActorInfo{ID: "user_actor", Handle: "actor"},
+	}
+
+	repo.On("Create", ctx, n).Return(nil)
+	repo.On("FindByID", ctx, mock.AnythingOfType("string")).Return(stored, nil)
+	repo.On("GetUnreadCount", ctx, "user_recipient").Return(3, nil)
+
+	require.NoError(t, d.Dispatch(ctx, n))
+
+	assert.NotEmpty(t, n.ID)
+	assert.False(t, n.CreatedAt.IsZero())
+	require.Len(t, deliverer.delivered, 1)
+	assert.Same(t, stored, deliverer.delivered[0])
+	assert.Equal(t, []int{3}, deliverer.counts)
+	repo.AssertExpectations(t)
+}
+
+func TestDispatcher_Dispatch_SkipsSelfNotifications(t *testing.T) {
+	repo := new(testutil.MockNotificationRepository)
+	deliverer := &recordingDeliverer{}
+	d := NewDispatcher(repo, deliverer)
+
+	err := d.Dispatch(context.Background(), &notification.Notification{
+		UserID:  "user_1",
+		ActorID: strPtr("user_1"),
+	})
+
+	require.NoError(t, err)
+	assert.Empty(t, deliverer.delivered)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestDispatcher_Dispatch_CreateFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := new(testutil.MockNotificationRepository)
+	deliverer := &recordingDeliverer{}
+	d := NewDispatcher(repo, deliverer)
+
+	n := &notification.Notification{UserID: "user_1"}
+	repo.On("Create", ctx, n).Return(errors.New("db down"))
+
+	assert.Error(t, d.Dispatch(ctx, n))
+	assert.Empty(t, deliverer.delivered)
+}
+
+func TestDispatcher_Dispatch_MissingRecipient(t *testing.T) {
+	d := NewDispatcher(new(testutil.MockNotificationRepository))
+
+	err := d.Dispatch(context.Background(), &notification.Notification{})
+	assert.ErrorIs(t, err, ErrMissingRecipient)
+}
+
+func TestDispatcher_SyncUnreadCount(t *testing.T) {
+	ctx := context.Background()
+	repo := new(testutil.MockNotificationRepository)
+	deliverer := &recordingDeliverer{}
+	d := NewDispatcher(repo)
+	d.AddDeliverer(deliverer)
+
+	repo.On("GetUnreadCount", ctx, "user_1").Return(0, nil)
+
+	require.NoError(t, d.SyncUnreadCount(ctx, "user_1"))
+	assert.Equal(t, []int{0}, deliverer.counts)
+}
@@ -46,19 +46,46 @@ type CalculateInput struct {
 
 // Calculate computes the final permissions for a member.
 func (e *Engine) Calculate(input CalculateInput) server.Permission {
+	return e.evaluate(input, nil)
+}
+
+// Explain computes the same permissions as Calculate and records how every
+// step of the resolution changed them.
+func (e *Engine) Explain(input CalculateInput) *Explanation {
+	ex := &Explanation{}
+	ex.Permissions = e.evaluate(input, ex)
+	ex.Bits = ex.decideBits()
+	return ex
+}
+
+// evaluate runs the resolution, recording each step into ex when it is
+// non-nil. Calculate and Explain share it so they can never disagree.
+func (e *Engine) evaluate(input CalculateInput, ex *Explanation) server.Permission {
 	// Server owners have all permissions
 	if input.IsOwner {
+		ex.shortCircuit(ShortCircuitOwner)
 		return server.PermissionAll
 	}
 
 	// Step 1: Start with base permissions from all roles (OR them together)
 	var perms server.Permission
 	for _, role := range input.Roles {
+		before := perms
 		perms = perms.Add(role.Permissions)
+		ex.record(Step{
+			Stage:      StageRole,
+			TargetType: channel.OverwriteTargetRole,
+			TargetID:   role.ID,
+			Name:       role.Name,
+			Allow:      role.Permissions,
+			Granted:    perms &^ before,
+			Result:     perms,
+		})
 	}
 
 	// Administrator bypasses all further checks
 	if perms.Has(server.PermissionAdministrator) {
+		ex.shortCircuit(ShortCircuitAdministrator)
 		return server.PermissionAll
 	}
 
@@ -69,12 +96,12 @@ func (e *Engine) Calculate(input CalculateInput) server.Permission {
 
 	// Step 2: Apply category overwrites (if channel has a parent category)
 	if input.ParentCategory != nil && len(input.CategoryOverwrites) > 0 {
-		perms = e.applyOverwrites(perms, input.CategoryOverwrites, input.Roles, input.Member.ID)
+		perms = e.applyOverwrites(perms, input.CategoryOverwrites, input.Roles, input.Member.ID, StageCategoryOverwrite, ex)
 	}
 
 	// Step 3: Apply channel overwrites
 	if len(input.ChannelOverwrites) > 0 {
-		perms = e.applyOverwrites(perms, input.ChannelOverwrites, input.Roles, input.Member.ID)
+		perms = e.applyOverwrites(perms, input.ChannelOverwrites, input.Roles, input.Member.ID, StageChannelOverwrite, ex)
 	}
 
 	return perms
@@ -89,14 +116,16 @@ func (e *Engine) applyOverwrites(
 	overwrites []channel.PermissionOverwrite,
 	roles []server.Role,
 	memberID string,
+	stage Stage,
+	ex *Explanation,
 ) server.Permission {
 	perms := base
 
 	// Collect role IDs for quick lookup
-	roleIDs := make(map[string]bool)
+	roleNames := make(map[string]string)
 	var everyoneRoleID string
 	for _, r := range roles {
-		roleIDs[r.ID] = true
+		roleNames[r.ID] = r.Name
 		if r.IsDefault {
 			everyoneRoleID = r.ID
 		}
@@ -105,30 +134,42 @@ func (e *Engine) applyOverwrites(
 	// Phase 1: Apply @everyone role overwrite first
 	for _, ow := range overwrites {
 		if ow.TargetType == channel.OverwriteTargetRole && ow.TargetID == everyoneRoleID {
+			before := perms
 			perms = perms.Remove(ow.Deny)
 			perms = perms.Add(ow.Allow)
+			ex.recordOverwrite(stage, ow, roleNames[ow.TargetID], perms&^before, before&^perms, perms)
 			break
 		}
 	}
 
 	// Phase 2: Apply other role overwrites
 	var allow, deny server.Permission
+	var matched []channel.PermissionOverwrite
 	for _, ow := range overwrites {
 		if ow.TargetType == channel.OverwriteTargetRole && ow.TargetID != everyoneRoleID {
-			if roleIDs[ow.TargetID] {
+			if _, ok := roleNames[ow.TargetID]; ok {
 				allow = allow.Add(ow.Allow)
 				deny = deny.Add(ow.Deny)
+				matched = append(matched, ow)
 			}
 		}
 	}
+	before := perms
 	perms = perms.Remove(deny)
 	perms = perms.Add(allow)
+	// Role overwrites apply together, so an allow on any role beats a deny
+	// on another
+	for _, ow := range matched {
+		ex.recordOverwrite(stage, ow, roleNames[ow.TargetID], ow.Allow&^before, (ow.Deny&before)&^allow, perms)
+	}
 
 	// Phase 3: Apply member-specific overwrite (highest priority)
 	for _, ow := range overwrites {
 		if ow.TargetType == channel.OverwriteTargetMember && ow.TargetID == memberID {
+			before := perms
 			perms = perms.Remove(ow.Deny)
 			perms = perms.Add(ow.Allow)
+			ex.recordOverwrite(stage, ow, "", perms&^before, before&^perms, perms)
 			break
 		}
 	}
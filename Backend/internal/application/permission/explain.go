@@ -0,0 +1,115 @@
+package permission
+
+import (
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+)
+
+// ShortCircuit names the rule that granted every permission without
+// evaluating the remaining steps.
+type ShortCircuit string
+
+const (
+	ShortCircuitNone          ShortCircuit = ""
+	ShortCircuitOwner         ShortCircuit = "owner"
+	ShortCircuitAdministrator ShortCircuit = "administrator"
+)
+
+// Stage is the part of the resolution a step belongs to.
+type Stage string
+
+const (
+	StageRole              Stage = "role"
+	StageCategoryOverwrite Stage = "category_overwrite"
+	StageChannelOverwrite  Stage = "channel_overwrite"
+)
+
+// Step records how one role or overwrite changed the permissions.
+type Step struct {
+	Stage      Stage
+	TargetType channel.OverwriteTargetType
+	TargetID   string // role ID, or member ID for member overwrites
+	Name       string // role name, empty for member overwrites
+	Allow      server.Permission
+	Deny       server.Permission
+	Granted    server.Permission // bits this step turned on
+	Revoked    server.Permission // bits this step turned off
+	Result     server.Permission // permissions after this step
+}
+
+// BitDecision explains the final state of a single permission flag.
+type BitDecision struct {
+	Permission server.Permission
+	Allowed    bool
+	// Step is the index of the last step that turned the bit on or off, or
+	// -1 if no step touched it (or the owner short-circuit applied).
+	Step int
+}
+
+// Explanation is the result of Engine.Explain.
+type Explanation struct {
+	Permissions  server.Permission
+	ShortCircuit ShortCircuit
+	Steps        []Step
+	Bits         []BitDecision
+}
+
+func (ex *Explanation) record(step Step) {
+	if ex == nil {
+		return
+	}
+	ex.Steps = append(ex.Steps, step)
+}
+
+func (ex *Explanation) recordOverwrite(stage Stage, ow channel.PermissionOverwrite, name string, granted, revoked, result server.Permission) {
+	ex.record(Step{
+		Stage:      stage,
+		TargetType: ow.TargetType,
+		TargetID:   ow.TargetID,
+		Name:       name,
+		Allow:      ow.Allow,
+		Deny:       ow.Deny,
+		Granted:    granted,
+		Revoked:    revoked,
+		Result:     result,
+	})
+}
+
+func (ex *Explanation) shortCircuit(reason ShortCircuit) {
+	if ex == nil {
+		return
+	}
+	ex.ShortCircuit = reason
+}
+
+// decideBits attributes every known flag to the step that last changed it.
+// Under the Administrator short-circuit every flag is attributed to the role
+// that granted Administrator.
+func (ex *Explanation) decideBits() []BitDecision {
+	flags := server.PermissionAll.Flags()
+	bits := make([]BitDecision, len(flags))
+
+	for i, flag := range flags {
+		decidedBy := flag
+		if ex.ShortCircuit == ShortCircuitAdministrator {
+			decidedBy = server.PermissionAdministrator
+		}
+
+		step := -1
+		if ex.ShortCircuit != ShortCircuitOwner {
+			for j, s := range ex.Steps {
+				if (s.Granted|s.Revoked)&decidedBy != 0 {
+					step = j
+				}
+			}
+		}
+
+		bits[i] = BitDecision{
+			Permission: flag,
+			Allowed:    ex.Permissions&flag != 0,
+			Step:       step,
+		}
+	}
+
+	return bits
+}
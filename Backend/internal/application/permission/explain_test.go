@@ -0,0 +1,128 @@
+package permission
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+)
+
+var (
+	everyone  = server.Role{ID: "role_everyone", Name: "@everyone", IsDefault: true, Permissions: server.PermissionDefaultEveryone}
+	moderator = server.Role{ID: "role_mod", Name: "Moderator", Permissions: server.PermissionManageMessages}
+	muted     = server.Role{ID: "role_muted", Name: "Muted"}
+	category  = &channel.Channel{ID: "chan_cat", Type: channel.TypeCategory}
+	textChan  = &channel.Channel{ID: "chan_1", Type: channel.TypeText, ParentID: &category.ID}
+)
+
+func bitFor(t *testing.T, ex *Explanation, perm server.Permission) BitDecision {
+	t.Helper()
+	for _, bit := range ex.Bits {
+		if bit.Permission == perm {
+			return bit
+		}
+	}
+	t.Fatalf("no decision for %s", perm.Name())
+	return BitDecision{}
+}
+
+func TestEngine_Explain(t *testing.T) {
+	input := CalculateInput{
+		Member:         &server.Member{ID: "memb_1"},
+		Roles:          []server.Role{everyone, moderator, muted},
+		Channel:        textChan,
+		ParentCategory: category,
+		CategoryOverwrites: []channel.PermissionOverwrite{
+			{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionSendMessages},
+		},
+		ChannelOverwrites: []channel.PermissionOverwrite{
+			{TargetType: channel.OverwriteTargetRole, TargetID: "role_mod", Allow: server.PermissionSendMessages},
+			{TargetType: channel.OverwriteTargetRole, TargetID: "role_muted", Deny: server.PermissionSendMessages | server.PermissionSpeak},
+			{TargetType: channel.OverwriteTargetMember, TargetID: "memb_1", Deny: server.PermissionVideo},
+		},
+	}
+
+	engine := NewEngine()
+	ex := engine.Explain(input)
+
+	assert.Equal(t, engine.Calculate(input), ex.Permissions)
+	assert.Equal(t, ShortCircuitNone, ex.ShortCircuit)
+	require.Len(t, ex.Steps, 7)
+
+	// Roles, then the category's @everyone deny, then both channel role
+	// overwrites, then the member overwrite
+	stages := make([]Stage, len(ex.Steps))
+	for i, s := range ex.Steps {
+		stages[i] = s.Stage
+	}
+	assert.Equal(t, []Stage{
+		StageRole, StageRole, StageRole,
+		StageCategoryOverwrite,
+		StageChannelOverwrite, StageChannelOverwrite, StageChannelOverwrite,
+	}, stages)
+
+	assert.Equal(t, server.PermissionSendMessages, ex.Steps[3].Revoked)
+	assert.Equal(t, "Moderator", ex.Steps[4].Name)
+	assert.Equal(t, server.PermissionSendMessages, ex.Steps[4].Granted)
+	// Muted's deny on SendMessages loses to Moderator's allow
+	assert.Equal(t, server.PermissionSpeak, ex.Steps[5].Revoked)
+
+	send := bitFor(t, ex, server.PermissionSendMessages)
+	assert.True(t, send.Allowed)
+	assert.Equal(t, 4, send.Step)
+
+	video := bitFor(t, ex, server.PermissionVideo)
+	assert.False(t, video.Allowed)
+	assert.Equal(t, 6, video.Step)
+
+	view := bitFor(t, ex, server.PermissionViewChannel)
+	assert.True(t, view.Allowed)
+	assert.Equal(t, 0, view.Step)
+
+	ban := bitFor(t, ex, server.PermissionBanMembers)
+	assert.False(t, ban.Allowed)
+	assert.Equal(t, -1, ban.Step)
+}
+
+func TestEngine_Explain_ShortCircuits(t *testing.T) {
+	admin := server.Role{ID: "role_admin", Name: "Admin", Permissions: server.PermissionAdministrator}
+	hideAll := []channel.PermissionOverwrite{
+		{TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionViewChannel},
+	}
+
+	t.Run("owner", func(t *testing.T) {
+		ex := NewEngine().Explain(CalculateInput{
+			Member:            &server.Member{ID: "memb_1"},
+			Roles:             []server.Role{everyone},
+			Channel:           textChan,
+			ChannelOverwrites: hideAll,
+			IsOwner:           true,
+		})
+
+		assert.Equal(t, ShortCircuitOwner, ex.ShortCircuit)
+		assert.Equal(t, server.PermissionAll, ex.Permissions)
+		assert.Empty(t, ex.Steps)
+		assert.Equal(t, -1, bitFor(t, ex, server.PermissionViewChannel).Step)
+	})
+
+	t.Run("administrator", func(t *testing.T) {
+		ex := NewEngine().Explain(CalculateInput{
+			Member:            &server.Member{ID: "memb_1"},
+			Roles:             []server.Role{everyone, admin},
+			Channel:           textChan,
+			ChannelOverwrites: hideAll,
+		})
+
+		assert.Equal(t, ShortCircuitAdministrator, ex.ShortCircuit)
+		assert.Equal(t, server.PermissionAll, ex.Permissions)
+		require.Len(t, ex.Steps, 2)
+
+		// Every bit is attributed to the role granting Administrator
+		view := bitFor(t, ex, server.PermissionViewChannel)
+		assert.True(t, view.Allowed)
+		assert.Equal(t, 1, view.Step)
+	})
+}
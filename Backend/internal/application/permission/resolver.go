@@ -0,0 +1,325 @@
+package permission
+
+import (
+	"context"
+
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+)
+
+// Resolver loads the members, roles, channels and overwrites the Engine needs
+// and evaluates a user's effective permissions.
+type Resolver struct {
+	engine        *Engine
+	serverRepo    server.Repository
+	memberRepo    server.MemberRepository
+	roleRepo      server.RoleRepository
+	channelRepo   channel.Repository
+	overwriteRepo channel.OverwriteRepository
+}
+
+// NewResolver creates a new permission resolver.
+func NewResolver(
+	engine *Engine,
+	serverRepo server.Repository,
+	memberRepo server.MemberRepository,
+	roleRepo server.RoleRepository,
+	channelRepo channel.Repository,
+	overwriteRepo channel.OverwriteRepository,
+) *Resolver {
+	return &Resolver{
+		engine:        engine,
+		serverRepo:    serverRepo,
+		memberRepo:    memberRepo,
+		roleRepo:      roleRepo,
+		channelRepo:   channelRepo,
+		overwriteRepo: overwriteRepo,
+	}
+}
+
+// ServerPermissions returns a user's server-level permissions.
+// Returns server.ErrNotMember if the user is not a member of the server.
+func (r *Resolver) ServerPermissions(ctx context.Context, serverID, userID string) (server.Permission, error) {
+	input, err := r.baseInput(ctx, serverID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return r.engine.Calculate(input), nil
+}
+
+// ChannelPermissions returns a user's effective permissions in a channel.
+// Returns server.ErrNotMember if the user is not a member of the channel's server.
+func (r *Resolver) ChannelPermissions(ctx context.Context, channelID, userID string) (server.Permission, error) {
+	ch, err := r.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return 0, err
+	}
+	return r.ChannelPermissionsFor(ctx, ch, userID)
+}
+
+// ChannelPermissionsFor is like ChannelPermissions for an already loaded channel.
+func (r *Resolver) ChannelPermissionsFor(ctx context.Context, ch *channel.Channel, userID string) (server.Permission, error) {
+	input, err := r.ChannelInput(ctx, ch, userID)
+	if err != nil {
+		return 0, err
+	}
+	return r.engine.Calculate(input), nil
+}
+
+// ExplainChannelPermissions is like ChannelPermissionsFor but also returns
+// how the permissions were derived.
+func (r *Resolver) ExplainChannelPermissions(ctx context.Context, ch *channel.Channel, userID string) (*Explanation, error) {
+	input, err := r.ChannelInput(ctx, ch, userID)
+	if err != nil {
+		return nil, err
+	}
+	return r.engine.Explain(input), nil
+}
+
+// HasChannelPermission checks a single permission in a channel.
+// Non-members and unknown channels simply have no permissions.
+func (r *Resolver) HasChannelPermission(ctx context.Context, channelID, userID string, perm server.Permission) bool {
+	perms, err := r.ChannelPermissions(ctx, channelID, userID)
+	if err != nil {
+		return false
+	}
+	return perms.Has(perm)
+}
+
+// Calculate evaluates an input built by ChannelInput.
+func (r *Resolver) Calculate(input CalculateInput) server.Permission {
+	return r.engine.Calculate(input)
+}
+
+// VisibleChannels filters a server's channels down to the ones the user has
+// ViewChannel in. Categories are kept while any of their channels is
+// visible. Overwrites are loaded in a single batch.
+// Returns server.ErrNotMember if the user is not a member of the server.
+func (r *Resolver) VisibleChannels(ctx context.Context, serverID, userID string, channels []*channel.Channel) ([]*channel.Channel, error) {
+	input, err := r.baseInput(ctx, serverID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Owners and administrators see everything
+	if r.engine.Calculate(input).Has(server.PermissionAdministrator) {
+		return channels, nil
+	}
+
+	overwrites, err := r.overwritesFor(ctx, channels)
+	if err != nil {
+		return nil, err
+	}
+	return r.visibleChannels(input, channels, overwrites), nil
+}
+
+// VisibleChannelsByUser is VisibleChannels for every member of the server at
+// once, keyed by user ID. Like MembersWithChannelPermission, members, roles
+// and overwrites are each loaded once.
+func (r *Resolver) VisibleChannelsByUser(ctx context.Context, serverID string, channels []*channel.Channel) (map[string][]*channel.Channel, error) {
+	srv, err := r.serverRepo.FindByID(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := r.memberRepo.FindByServerID(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a default role, members keep just their own roles
+	everyone, err := r.roleRepo.FindDefaultRole(ctx, serverID)
+	if err != nil {
+		everyone = nil
+	}
+
+	overwrites, err := r.overwritesFor(ctx, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]*channel.Channel, len(members))
+	for _, member := range members {
+		input := CalculateInput{
+			Member:  member,
+			Roles:   withDefaultRole(member.Roles, everyone),
+			IsOwner: srv.OwnerID == member.UserID,
+		}
+		if r.engine.Calculate(input).Has(server.PermissionAdministrator) {
+			result[member.UserID] = channels
+			continue
+		}
+		result[member.UserID] = r.visibleChannels(input, channels, overwrites)
+	}
+	return result, nil
+}
+
+// overwritesFor loads the overwrites of channels in a single batch.
+func (r *Resolver) overwritesFor(ctx context.Context, channels []*channel.Channel) (map[string][]channel.PermissionOverwrite, error) {
+	channelIDs := make([]string, len(channels))
+	for i, ch := range channels {
+		channelIDs[i] = ch.ID
+	}
+	return r.overwriteRepo.FindByChannelIDs(ctx, channelIDs)
+}
+
+// visibleChannels keeps the channels a member's server-level input has
+// ViewChannel in, along with the categories holding them.
+func (r *Resolver) visibleChannels(input CalculateInput, channels []*channel.Channel, overwrites map[string][]channel.PermissionOverwrite) []*channel.Channel {
+	byID := make(map[string]*channel.Channel, len(channels))
+	for _, ch := range channels {
+		byID[ch.ID] = ch
+	}
+
+	visible := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		in := input
+		in.Channel = ch
+		in.ChannelOverwrites = overwrites[ch.ID]
+		if ch.ParentID != nil {
+			if parent, ok := byID[*ch.ParentID]; ok {
+				in.ParentCategory = parent
+				in.CategoryOverwrites = overwrites[parent.ID]
+			}
+		}
+
+		if r.engine.Calculate(in).Has(server.PermissionViewChannel) {
+			visible[ch.ID] = true
+			if ch.ParentID != nil {
+				visible[*ch.ParentID] = true
+			}
+		}
+	}
+
+	result := make([]*channel.Channel, 0, len(visible))
+	for _, ch := range channels {
+		if visible[ch.ID] {
+			result = append(result, ch)
+		}
+	}
+	return result
+}
+
+// MembersWithChannelPermission returns the members of the channel's server
+// who have perm in it. Members, roles and overwrites are each loaded once, so
+// this suits fan-out to a whole server.
+func (r *Resolver) MembersWithChannelPermission(ctx context.Context, ch *channel.Channel, perm server.Permission) ([]*server.Member, error) {
+	srv, err := r.serverRepo.FindByID(ctx, ch.ServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := r.memberRepo.FindByServerID(ctx, ch.ServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a default role, members keep just their own roles
+	everyone, err := r.roleRepo.FindDefaultRole(ctx, ch.ServerID)
+	if err != nil {
+		everyone = nil
+	}
+
+	input := CalculateInput{Channel: ch}
+	channelIDs := []string{ch.ID}
+	if ch.ParentID != nil {
+		if parent, err := r.channelRepo.FindByID(ctx, *ch.ParentID); err == nil {
+			input.ParentCategory = parent
+			channelIDs = append(channelIDs, parent.ID)
+		}
+	}
+
+	overwrites, err := r.overwriteRepo.FindByChannelIDs(ctx, channelIDs)
+	if err != nil {
+		return nil, err
+	}
+	input.ChannelOverwrites = overwrites[ch.ID]
+	if input.ParentCategory != nil {
+		input.CategoryOverwrites = overwrites[input.ParentCategory.ID]
+	}
+
+	var result []*server.Member
+	for _, member := range members {
+		in := input
+		in.Member = member
+		in.IsOwner = srv.OwnerID == member.UserID
+		in.Roles = withDefaultRole(member.Roles, everyone)
+
+		if r.engine.Calculate(in).Has(perm) {
+			result = append(result, member)
+		}
+	}
+	return result, nil
+}
+
+// ChannelInput builds the full Engine input for a user in a channel.
+func (r *Resolver) ChannelInput(ctx context.Context, ch *channel.Channel, userID string) (CalculateInput, error) {
+	input, err := r.baseInput(ctx, ch.ServerID, userID)
+	if err != nil {
+		return CalculateInput{}, err
+	}
+	input.Channel = ch
+
+	channelIDs := []string{ch.ID}
+	if ch.ParentID != nil {
+		parent, err := r.channelRepo.FindByID(ctx, *ch.ParentID)
+		if err == nil {
+			input.ParentCategory = parent
+			channelIDs = append(channelIDs, parent.ID)
+		}
+	}
+
+	overwrites, err := r.overwriteRepo.FindByChannelIDs(ctx, channelIDs)
+	if err != nil {
+		return CalculateInput{}, err
+	}
+	input.ChannelOverwrites = overwrites[ch.ID]
+	if input.ParentCategory != nil {
+		input.CategoryOverwrites = overwrites[input.ParentCategory.ID]
+	}
+
+	return input, nil
+}
+
+func (r *Resolver) baseInput(ctx context.Context, serverID, userID string) (CalculateInput, error) {
+	srv, err := r.serverRepo.FindByID(ctx, serverID)
+	if err != nil {
+		return CalculateInput{}, err
+	}
+
+	member, err := r.memberRepo.FindByServerAndUserWithRoles(ctx, serverID, userID)
+	if err != nil {
+		return CalculateInput{}, err
+	}
+
+	// Every member implicitly has @everyone, even if the assignment is missing
+	roles := member.Roles
+	if !hasDefaultRole(roles) {
+		if everyone, err := r.roleRepo.FindDefaultRole(ctx, serverID); err == nil {
+			roles = append(roles, *everyone)
+		}
+	}
+
+	return CalculateInput{
+		Member:  member,
+		Roles:   roles,
+		IsOwner: srv.OwnerID == userID,
+	}, nil
+}
+
+// withDefaultRole adds @everyone to roles if the assignment is missing.
+func withDefaultRole(roles []server.Role, everyone *server.Role) []server.Role {
+	if everyone == nil || hasDefaultRole(roles) {
+		return roles
+	}
+	return append(roles[:len(roles):len(roles)], *everyone)
+}
+
+func hasDefaultRole(roles []server.Role) bool {
+	for _, role := range roles {
+		if role.IsDefault {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+// Package realtime provides application logic for WebSocket subscriptions.
+package realtime
+
+import (
+	"context"
+	"errors"
+
+	"pink/internal/application/permission"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/dm"
+	"pink/internal/domain/live"
+	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
+)
+
+// authorizeFunc checks access to a single subscription type.
+type authorizeFunc func(ctx context.Context, userID, id string) error
+
+// Authorizer implements ws.SubscriptionAuthorizer with one check per
+// subscription type.
+type Authorizer struct {
+	convRepo    dm.ConversationRepository
+	serverRepo  server.Repository
+	memberRepo  server.MemberRepository
+	streamRepo  live.StreamRepository
+	permissions *permission.Resolver
+
+	checks map[ws.SubscriptionType]authorizeFunc
+}
+
+// NewAuthorizer creates a new subscription authorizer.
+func NewAuthorizer(
+	convRepo dm.ConversationRepository,
+	serverRepo server.Repository,
+	memberRepo server.MemberRepository,
+	streamRepo live.StreamRepository,
+	permissions *permission.Resolver,
+) *Authorizer {
+	a := &Authorizer{
+		convRepo:    convRepo,
+		serverRepo:  serverRepo,
+		memberRepo:  memberRepo,
+		streamRepo:  streamRepo,
+		permissions: permissions,
+	}
+	a.checks = map[ws.SubscriptionType]authorizeFunc{
+		ws.SubUser:         a.authorizeUser,
+		ws.SubConversation: a.authorizeConversation,
+		ws.SubServer:       a.authorizeServer,
+		ws.SubChannel:      a.authorizeChannel,
+		ws.SubStream:       a.authorizeStream,
+	}
+	return a
+}
+
+// Authorize checks whether a user may subscribe to sub.
+func (a *Authorizer) Authorize(ctx context.Context, userID string, sub ws.Subscription) error {
+	if sub.ID == "" {
+		return ws.ErrInvalidSubscription
+	}
+
+	check, ok := a.checks[sub.Type]
+	if !ok {
+		return ws.ErrInvalidSubscription
+	}
+
+	return check(ctx, userID, sub.ID)
+}
+
+// authorizeUser only allows users to subscribe to their own channel.
+func (a *Authorizer) authorizeUser(_ context.Context, userID, id string) error {
+	if id != userID {
+		return ws.ErrSubscriptionForbidden
+	}
+	return nil
+}
+
+// authorizeConversation requires DM participation.
+func (a *Authorizer) authorizeConversation(ctx context.Context, userID, id string) error {
+	ok, err := a.convRepo.IsParticipant(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ws.ErrSubscriptionForbidden
+	}
+	return nil
+}
+
+// authorizeServer requires server membership.
+func (a *Authorizer) authorizeServer(ctx context.Context, userID, id string) error {
+	ok, err := a.memberRepo.IsMember(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ws.ErrSubscriptionForbidden
+	}
+	return nil
+}
+
+// authorizeChannel requires ViewChannel in the channel.
+func (a *Authorizer) authorizeChannel(ctx context.Context, userID, id string) error {
+	perms, err := a.permissions.ChannelPermissions(ctx, id, userID)
+	if err != nil {
+		if isAccessError(err) {
+			return ws.ErrSubscriptionForbidden
+		}
+		return err
+	}
+	if !perms.Has(server.PermissionViewChannel) {
+		return ws.ErrSubscriptionForbidden
+	}
+	return nil
+}
+
+// authorizeStream allows user streams and streams of public servers to
+// everyone, and streams of private servers to members only.
+func (a *Authorizer) authorizeStream(ctx context.Context, userID, id string) error {
+	stream, err := a.streamRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, live.ErrStreamNotFound) {
+			return ws.ErrSubscriptionForbidden
+		}
+		return err
+	}
+
+	if stream.UserID == userID || stream.Type != live.StreamTypeServer || stream.ServerID == nil {
+		return nil
+	}
+
+	srv, err := a.serverRepo.FindByID(ctx, *stream.ServerID)
+	if err != nil {
+		if errors.Is(err, server.ErrNotFound) {
+			return ws.ErrSubscriptionForbidden
+		}
+		return err
+	}
+	if srv.IsPublic {
+		return nil
+	}
+
+	return a.authorizeServer(ctx, userID, srv.ID)
+}
+
+func isAccessError(err error) bool {
+	return errors.Is(err, server.ErrNotMember) ||
+		errors.Is(err, server.ErrNotFound) ||
+		errors.Is(err, channel.ErrNotFound)
+}
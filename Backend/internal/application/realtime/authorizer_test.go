@@ -0,0 +1,131 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"pink/internal/application/permission"
+	"pink/internal/application/testutil"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
+)
+
+type authorizerMocks struct {
+	convRepo      *testutil.MockConversationRepository
+	serverRepo    *testutil.MockServerRepository
+	memberRepo    *testutil.MockMemberRepository
+	roleRepo      *testutil.MockRoleRepository
+	channelRepo   *testutil.MockChannelRepository
+	overwriteRepo *testutil.MockOverwriteRepository
+}
+
+// setupAuthorizer creates an authorizer with mocked dependencies.
+func setupAuthorizer(t *testing.T) (*Authorizer, *authorizerMocks) {
+	m := &authorizerMocks{
+		convRepo:      new(testutil.MockConversationRepository),
+		serverRepo:    new(testutil.MockServerRepository),
+		memberRepo:    new(testutil.MockMemberRepository),
+		roleRepo:      new(testutil.MockRoleRepository),
+		channelRepo:   new(testutil.MockChannelRepository),
+		overwriteRepo: new(testutil.MockOverwriteRepository),
+	}
+
+	resolver := permission.NewResolver(permission.NewEngine(), m.serverRepo, m.memberRepo, m.roleRepo, m.channelRepo, m.overwriteRepo)
+	return NewAuthorizer(m.convRepo, m.serverRepo, m.memberRepo, nil, resolver), m
+}
+
+func TestAuthorizer_User(t *testing.T) {
+	a, _ := setupAuthorizer(t)
+	ctx := context.Background()
+
+	assert.NoError(t, a.Authorize(ctx, "user_1", ws.Subscription{Type: ws.SubUser, ID: "user_1"}))
+	assert.ErrorIs(t, a.Authorize(ctx, "user_1", ws.Subscription{Type: ws.SubUser, ID: "user_2"}), ws.ErrSubscriptionForbidden)
+}
+
+func TestAuthorizer_InvalidSubscription(t *testing.T) {
+	a, _ := setupAuthorizer(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, a.Authorize(ctx, "user_1", ws.Subscription{Type: "unknown", ID: "x"}), ws.ErrInvalidSubscription)
+	assert.ErrorIs(t, a.Authorize(ctx, "user_1", ws.Subscription{Type: ws.SubChannel}), ws.ErrInvalidSubscription)
+}
+
+func TestAuthorizer_Conversation(t *testing.T) {
+	a, m := setupAuthorizer(t)
+	ctx := context.Background()
+
+	m.convRepo.On("IsParticipant", ctx, "conv_1", "user_1").Return(true, nil)
+	m.convRepo.On("IsParticipant", ctx, "conv_1", "user_2").Return(false, nil)
+
+	assert.NoError(t, a.Authorize(ctx, "user_1", ws.Subscription{Type: ws.SubConversation, ID: "conv_1"}))
+	assert.ErrorIs(t, a.Authorize(ctx, "user_2", ws.Subscription{Type: ws.SubConversation, ID: "conv_1"}), ws.ErrSubscriptionForbidden)
+}
+
+func TestAuthorizer_Server_NotMember(t *testing.T) {
+	a, m := setupAuthorizer(t)
+	ctx := context.Background()
+
+	m.memberRepo.On("IsMember", ctx, "serv_1", "user_1").Return(false, nil)
+
+	err := a.Authorize(ctx, "user_1", ws.Subscription{Type: ws.SubServer, ID: "serv_1"})
+	assert.ErrorIs(t, err, ws.ErrSubscriptionForbidden)
+}
+
+func TestAuthorizer_Channel(t *testing.T) {
+	everyone := server.Role{ID: "role_everyone", IsDefault: true, Permissions: server.PermissionDefaultEveryone}
+	ch := &channel.Channel{ID: "chan_1", ServerID: "serv_1"}
+
+	tests := []struct {
+		name       string
+		overwrites []channel.PermissionOverwrite
+		wantErr    error
+	}{
+		{
+			name: "visible by default",
+		},
+		{
+			name: "hidden by @everyone overwrite",
+			overwrites: []channel.PermissionOverwrite{
+				{ChannelID: "chan_1", TargetType: channel.OverwriteTargetRole, TargetID: "role_everyone", Deny: server.PermissionViewChannel},
+			},
+			wantErr: ws.ErrSubscriptionForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, m := setupAuthorizer(t)
+			ctx := context.Background()
+
+			m.channelRepo.On("FindByID", ctx, "chan_1").Return(ch, nil)
+			m.serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+			m.memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_1").
+				Return(&server.Member{ID: "memb_1", ServerID: "serv_1", UserID: "user_1", Roles: []server.Role{everyone}}, nil)
+			m.overwriteRepo.On("FindByChannelIDs", ctx, mock.Anything).
+				Return(map[string][]channel.PermissionOverwrite{"chan_1": tt.overwrites}, nil)
+
+			err := a.Authorize(ctx, "user_1", ws.Subscription{Type: ws.SubChannel, ID: "chan_1"})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthorizer_Channel_NotMember(t *testing.T) {
+	a, m := setupAuthorizer(t)
+	ctx := context.Background()
+
+	m.channelRepo.On("FindByID", ctx, "chan_1").Return(&channel.Channel{ID: "chan_1", ServerID: "serv_1"}, nil)
+	m.serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	m.memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_1").Return(nil, server.ErrNotMember)
+
+	err := a.Authorize(ctx, "user_1", ws.Subscription{Type: ws.SubChannel, ID: "chan_1"})
+	assert.ErrorIs(t, err, ws.ErrSubscriptionForbidden)
+}
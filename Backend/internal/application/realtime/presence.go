@@ -0,0 +1,315 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	privacyApp "pink/internal/application/privacy"
+	"pink/internal/domain/presence"
+	"pink/internal/domain/privacy"
+	"pink/internal/domain/ws"
+)
+
+// Broadcaster delivers events to connected users across the cluster.
+type Broadcaster interface {
+	BroadcastToUser(userID string, message []byte)
+	GetOnlineUsers() []string
+	IsUserOnline(userID string) bool
+}
+
+// PresenceService manages rich presence and delivers presence events only to
+// related users allowed to see them by the target's privacy settings.
+type PresenceService struct {
+	presenceRepo   presence.Repository
+	audienceRepo   presence.AudienceRepository
+	privacyService *privacyApp.Service
+	hub            Broadcaster
+}
+
+// NewPresenceService creates a new presence service.
+func NewPresenceService(
+	presenceRepo presence.Repository,
+	audienceRepo presence.AudienceRepository,
+	privacyService *privacyApp.Service,
+	hub Broadcaster,
+) *PresenceService {
+	return &PresenceService{
+		presenceRepo:   presenceRepo,
+		audienceRepo:   audienceRepo,
+		privacyService: privacyService,
+		hub:            hub,
+	}
+}
+
+// UpdatePresenceCommand replaces a user's presence.
+type UpdatePresenceCommand struct {
+	UserID       string
+	Status       presence.Status
+	CustomStatus *presence.CustomStatus
+	Activity     *presence.Activity
+}
+
+// Get returns a user's presence, falling back to the default.
+func (s *PresenceService) Get(ctx context.Context, userID string) (*presence.Presence, error) {
+	p, err := s.presenceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, presence.ErrNotFound) {
+			return presence.Default(userID), nil
+		}
+		return nil, err
+	}
+
+	if p.CustomStatus != nil && p.CustomStatus.IsExpired() {
+		p.CustomStatus = nil
+	}
+	return p, nil
+}
+
+// Update replaces a user's presence and notifies everyone allowed to see it.
+func (s *PresenceService) Update(ctx context.Context, cmd UpdatePresenceCommand) (*presence.Presence, error) {
+	if !cmd.Status.IsValid() {
+		return nil, presence.ErrInvalidStatus
+	}
+	if cmd.CustomStatus != nil && utf8.RuneCountInString(cmd.CustomStatus.Text) > presence.MaxCustomStatusLength {
+		return nil, presence.ErrInvalidStatus
+	}
+	if cmd.Activity != nil {
+		if !cmd.Activity.Type.IsValid() || cmd.Activity.Name == "" ||
+			utf8.RuneCountInString(cmd.Activity.Name) > presence.MaxActivityLength ||
+			utf8.RuneCountInString(cmd.Activity.Details) > presence.MaxActivityLength {
+			return nil, presence.ErrInvalidStatus
+		}
+	}
+
+	prev, err := s.Get(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &presence.Presence{
+		UserID:       cmd.UserID,
+		Status:       cmd.Status,
+		CustomStatus: cmd.CustomStatus,
+		Activity:     cmd.Activity,
+		UpdatedAt:    time.Now(),
+	}
+	if err := s.presenceRepo.Upsert(ctx, p); err != nil {
+		return nil, err
+	}
+
+	connected := s.hub.IsUserOnline(cmd.UserID)
+	s.broadcast(ctx, p, prev.VisibleStatus(connected), connected)
+
+	return p, nil
+}
+
+// UserOnline is called when a user's first connection in the cluster opens.
+func (s *PresenceService) UserOnline(userID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	p, err := s.Get(ctx, userID)
+	if err != nil {
+		slog.Warn("presence lookup failed", slog.Any("error", err), slog.String("userId", userID))
+		return
+	}
+	s.broadcast(ctx, p, p.VisibleStatus(false), true)
+}
+
+// UserOffline is called when a user's last connection in the cluster closes.
+func (s *PresenceService) UserOffline(userID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	p, err := s.Get(ctx, userID)
+	if err != nil {
+		p = presence.Default(userID)
+	}
+	s.broadcast(ctx, p, p.VisibleStatus(true), false)
+}
+
+// Snapshot returns the presence of every online user related to viewerID
+// whose privacy settings allow viewerID to see it.
+func (s *PresenceService) Snapshot(ctx context.Context, viewerID string) ([]ws.PresenceEventData, error) {
+	related, err := s.onlineRelated(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, err := s.audienceRepo.FilterVisibleTo(ctx, viewerID, related)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, len(visible))
+	for i, v := range visible {
+		userIDs[i] = v.UserID
+	}
+	presences, err := s.presenceRepo.FindByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make([]ws.PresenceEventData, 0, len(visible))
+	for _, v := range visible {
+		p, ok := presences[v.UserID]
+		if !ok {
+			p = presence.Default(v.UserID)
+		}
+		if p.VisibleStatus(true) == presence.StatusOffline {
+			continue
+		}
+
+		snapshot = append(snapshot, eventData(p, true, false, v.ShowActivity))
+	}
+
+	return snapshot, nil
+}
+
+// broadcast sends a presence event to the user's own connections and to every
+// online related user allowed to see it. The event follows the change from
+// prevVisible to the status others now see; while that stays offline, as for
+// an invisible user connecting or disconnecting, others are told nothing.
+func (s *PresenceService) broadcast(ctx context.Context, p *presence.Presence, prevVisible presence.Status, connected bool) {
+	nextVisible := p.VisibleStatus(connected)
+	eventType := transitionEvent(prevVisible, nextVisible)
+
+	// The user's own devices always see their real status
+	selfEvent := eventType
+	if connected {
+		selfEvent = ws.EventPresenceUpdate
+	}
+	if data, err := marshalEvent(selfEvent, eventData(p, connected, true, true)); err == nil {
+		s.hub.BroadcastToUser(p.UserID, data)
+	}
+
+	if prevVisible == presence.StatusOffline && nextVisible == presence.StatusOffline {
+		return
+	}
+
+	audience, err := s.audience(ctx, p.UserID)
+	if err != nil {
+		slog.Warn("presence audience lookup failed", slog.Any("error", err), slog.String("userId", p.UserID))
+		return
+	}
+	if len(audience) == 0 {
+		return
+	}
+
+	showActivity, _ := s.privacyService.ShouldShowActivity(ctx, p.UserID)
+	data, err := marshalEvent(eventType, eventData(p, connected, false, showActivity))
+	if err != nil {
+		return
+	}
+	for _, viewerID := range audience {
+		s.hub.BroadcastToUser(viewerID, data)
+	}
+}
+
+// audience returns the online users allowed to see userID's presence.
+func (s *PresenceService) audience(ctx context.Context, userID string) ([]string, error) {
+	related, err := s.onlineRelated(ctx, userID)
+	if err != nil || len(related) == 0 {
+		return nil, err
+	}
+
+	settings, err := s.privacyService.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	switch settings.OnlineStatusVisibility {
+	case privacy.OnlineVisibilityEveryone:
+		return related, nil
+	case privacy.OnlineVisibilityFriends:
+		return s.audienceRepo.FilterFriends(ctx, userID, related)
+	default:
+		return nil, nil
+	}
+}
+
+// onlineRelated returns the users related to userID who are online anywhere
+// in the cluster.
+func (s *PresenceService) onlineRelated(ctx context.Context, userID string) ([]string, error) {
+	related, err := s.audienceRepo.FindRelated(ctx, userID)
+	if err != nil || len(related) == 0 {
+		return nil, err
+	}
+
+	online := make(map[string]bool)
+	for _, id := range s.hub.GetOnlineUsers() {
+		online[id] = true
+	}
+
+	result := make([]string, 0, len(related))
+	for _, id := range related {
+		if online[id] {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// eventData converts a presence to its event payload. Activity is only
+// included for the user themselves or when showActivity is set.
+func eventData(p *presence.Presence, connected, self, showActivity bool) ws.PresenceEventData {
+	status := p.VisibleStatus(connected)
+	if self && connected {
+		status = p.Status
+	}
+
+	data := ws.PresenceEventData{
+		UserID:   p.UserID,
+		IsOnline: status != presence.StatusOffline,
+		Status:   string(status),
+	}
+	if !data.IsOnline && !self {
+		return data
+	}
+
+	if p.CustomStatus != nil && !p.CustomStatus.IsExpired() {
+		data.CustomStatus = &ws.PresenceCustomStatus{
+			Text:  p.CustomStatus.Text,
+			Emoji: p.CustomStatus.Emoji,
+		}
+		if p.CustomStatus.ExpiresAt != nil {
+			expiresAt := p.CustomStatus.ExpiresAt.Format("2006-01-02T15:04:05.000Z")
+			data.CustomStatus.ExpiresAt = &expiresAt
+		}
+	}
+
+	if p.Activity != nil {
+		if self || showActivity {
+			data.Activity = &ws.PresenceActivity{
+				Type:    string(p.Activity.Type),
+				Name:    p.Activity.Name,
+				Details: p.Activity.Details,
+			}
+		}
+	}
+
+	return data
+}
+
+// transitionEvent picks the event type for a change in visible status.
+func transitionEvent(prev, next presence.Status) ws.EventType {
+	switch {
+	case prev == presence.StatusOffline && next != presence.StatusOffline:
+		return ws.EventUserOnline
+	case prev != presence.StatusOffline && next == presence.StatusOffline:
+		return ws.EventUserOffline
+	default:
+		return ws.EventPresenceUpdate
+	}
+}
+
+func marshalEvent(eventType ws.EventType, payload interface{}) ([]byte, error) {
+	msg, err := ws.NewMessage(eventType, payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(msg)
+}
@@ -0,0 +1,149 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	privacyApp "pink/internal/application/privacy"
+	"pink/internal/application/testutil"
+	"pink/internal/domain/presence"
+	"pink/internal/domain/privacy"
+)
+
+// presenceStore is an in-memory presence.Repository.
+type presenceStore map[string]*presence.Presence
+
+func (s presenceStore) FindByUserID(ctx context.Context, userID string) (*presence.Presence, error) {
+	if p, ok := s[userID]; ok {
+		return p, nil
+	}
+	return nil, presence.ErrNotFound
+}
+
+func (s presenceStore) FindByUserIDs(ctx context.Context, userIDs []string) (map[string]*presence.Presence, error) {
+	result := make(map[string]*presence.Presence)
+	for _, userID := range userIDs {
+		if p, ok := s[userID]; ok {
+			result[userID] = p
+		}
+	}
+	return result, nil
+}
+
+func (s presenceStore) Upsert(ctx context.Context, p *presence.Presence) error {
+	s[p.UserID] = p
+	return nil
+}
+
+// audience relates users to everyone in related and treats friends as
+// mutual follows. Everyone is visible to everyone.
+type audience struct {
+	related []string
+	friends map[string]bool
+}
+
+func (a audience) FindRelated(ctx context.Context, userID string) ([]string, error) {
+	return a.related, nil
+}
+
+func (a audience) FilterFriends(ctx context.Context, userID string, candidateIDs []string) ([]string, error) {
+	var friends []string
+	for _, id := range candidateIDs {
+		if a.friends[id] {
+			friends = append(friends, id)
+		}
+	}
+	return friends, nil
+}
+
+func (a audience) FilterVisibleTo(ctx context.Context, viewerID string, targetIDs []string) ([]presence.Viewable, error) {
+	visible := make([]presence.Viewable, len(targetIDs))
+	for i, id := range targetIDs {
+		visible[i] = presence.Viewable{UserID: id}
+	}
+	return visible, nil
+}
+
+// recordingHub records the event types delivered to each user.
+type recordingHub struct {
+	online   []string
+	received map[string][]string
+}
+
+func (h *recordingHub) BroadcastToUser(userID string, message []byte) {
+	var msg struct {
+		Type string `json:"type"`
+	}
+	_ = json.Unmarshal(message, &msg)
+	h.received[userID] = append(h.received[userID], msg.Type)
+}
+
+func (h *recordingHub) GetOnlineUsers() []string        { return h.online }
+func (h *recordingHub) IsUserOnline(userID string) bool { return true }
+
+func setupPresence(t *testing.T, status presence.Status) (*PresenceService, *recordingHub) {
+	return setupPresenceWith(t, status, privacy.OnlineVisibilityEveryone, audience{related: []string{"user_viewer", "user_away"}})
+}
+
+func setupPresenceWith(t *testing.T, status presence.Status, visibility privacy.OnlineVisibility, related audience) (*PresenceService, *recordingHub) {
+	privacyRepo := new(testutil.MockPrivacyRepository)
+	privacyRepo.On("FindByUserID", mock.Anything, mock.Anything).
+		Return(&privacy.Settings{OnlineStatusVisibility: visibility}, nil)
+
+	hub := &recordingHub{online: []string{"user_viewer", "user_friend"}, received: make(map[string][]string)}
+	store := presenceStore{"user_1": {UserID: "user_1", Status: status}}
+	svc := NewPresenceService(store, related, privacyApp.NewService(privacyRepo, nil), hub)
+	return svc, hub
+}
+
+func TestPresenceService_ConnectAndDisconnect(t *testing.T) {
+	svc, hub := setupPresence(t, presence.StatusOnline)
+
+	svc.UserOnline("user_1")
+	svc.UserOffline("user_1")
+
+	assert.Equal(t, []string{"user_online", "user_offline"}, hub.received["user_viewer"])
+}
+
+func TestPresenceService_InvisibleConnectIsHidden(t *testing.T) {
+	svc, hub := setupPresence(t, presence.StatusInvisible)
+
+	svc.UserOnline("user_1")
+	svc.UserOffline("user_1")
+
+	require.NotEmpty(t, hub.received["user_1"], "own devices still hear about it")
+	assert.Empty(t, hub.received["user_viewer"])
+}
+
+func TestPresenceService_FriendsOnlyReachesOnlineFriends(t *testing.T) {
+	related := audience{
+		related: []string{"user_viewer", "user_friend", "user_away"},
+		friends: map[string]bool{"user_friend": true, "user_away": true},
+	}
+	svc, hub := setupPresenceWith(t, presence.StatusOnline, privacy.OnlineVisibilityFriends, related)
+
+	svc.UserOnline("user_1")
+
+	assert.Equal(t, []string{"user_online"}, hub.received["user_friend"])
+	assert.Empty(t, hub.received["user_viewer"], "not a friend")
+	assert.Empty(t, hub.received["user_away"], "not online")
+}
+
+func TestPresenceService_Snapshot_OnlyOnlineRelatedUsers(t *testing.T) {
+	privacyRepo := new(testutil.MockPrivacyRepository)
+	hub := &recordingHub{online: []string{"user_1", "user_2", "user_stranger"}, received: make(map[string][]string)}
+	store := presenceStore{"user_2": {UserID: "user_2", Status: presence.StatusInvisible}}
+	svc := NewPresenceService(store, audience{related: []string{"user_1", "user_2", "user_away"}}, privacyApp.NewService(privacyRepo, nil), hub)
+
+	snapshot, err := svc.Snapshot(context.Background(), "user_viewer")
+
+	require.NoError(t, err)
+	require.Len(t, snapshot, 1, "invisible, offline and unrelated users are left out")
+	assert.Equal(t, "user_1", snapshot[0].UserID)
+	privacyRepo.AssertNotCalled(t, "FindByUserID", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"pink/internal/domain/server"
+	"pink/internal/pkg/periodic"
+)
+
+// ListAuditLogs lists a server's audit log, newest first. Reading the audit
+// log requires ViewAuditLog.
+func (s *Service) ListAuditLogs(ctx context.Context, serverID, userID string, filter server.AuditLogFilter, cursor string, limit int) ([]*server.AuditLog, string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	srv, err := s.serverRepo.FindByID(ctx, serverID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !s.canViewAuditLog(ctx, srv, userID) {
+		return nil, "", server.ErrNoPermission
+	}
+
+	return s.auditRepo.FindByServerID(ctx, serverID, filter, cursor, limit)
+}
+
+// canViewAuditLog checks if a user can read the audit log.
+func (s *Service) canViewAuditLog(ctx context.Context, srv *server.Server, userID string) bool {
+	if srv.OwnerID == userID {
+		return true
+	}
+
+	member, err := s.memberRepo.FindByServerAndUserWithRoles(ctx, srv.ID, userID)
+	if err != nil {
+		return false
+	}
+
+	return member.HasPermission(server.PermissionViewAuditLog)
+}
+
+// AuditRetentionWorker periodically deletes audit log entries older than the
+// configured retention.
+type AuditRetentionWorker struct {
+	auditRepo server.AuditLogRepository
+	retention time.Duration
+	interval  time.Duration
+}
+
+// NewAuditRetentionWorker creates a new retention worker. A zero retention
+// keeps entries forever.
+func NewAuditRetentionWorker(auditRepo server.AuditLogRepository, retention, interval time.Duration) *AuditRetentionWorker {
+	return &AuditRetentionWorker{
+		auditRepo: auditRepo,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+// Start runs the sweep until ctx is cancelled.
+func (w *AuditRetentionWorker) Start(ctx context.Context) {
+	if w.retention <= 0 {
+		return
+	}
+
+	periodic.Run(ctx, "audit retention worker", w.interval, w.Sweep)
+}
+
+// Sweep deletes the entries that have outlived the retention.
+func (w *AuditRetentionWorker) Sweep(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	deleted, err := w.auditRepo.DeleteOlderThan(ctx, time.Now().Add(-w.retention))
+	if err != nil {
+		slog.Error("audit retention sweep failed", slog.Any("error", err))
+		return
+	}
+	if deleted > 0 {
+		slog.Info("audit retention sweep", slog.Int64("deleted", deleted))
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/testutil"
+	"pink/internal/domain/server"
+)
+
+func TestService_ListAuditLogs_RequiresViewAuditLog(t *testing.T) {
+	svc, serverRepo, memberRepo, _, _ := setupServerService(t)
+	auditRepo := svc.auditRepo.(*testutil.MockAuditLogRepository)
+	ctx := context.Background()
+
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_1").Return(&server.Member{
+		ID: "memb_1", ServerID: "serv_1", UserID: "user_1",
+		Roles: []server.Role{{ID: "role_everyone", IsDefault: true, Permissions: server.PermissionDefaultEveryone}},
+	}, nil)
+	memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_mod").Return(&server.Member{
+		ID: "memb_mod", ServerID: "serv_1", UserID: "user_mod",
+		Roles: []server.Role{{ID: "role_mod", Permissions: server.PermissionViewAuditLog}},
+	}, nil)
+
+	_, _, err := svc.ListAuditLogs(ctx, "serv_1", "user_1", server.AuditLogFilter{}, "", 50)
+	assert.ErrorIs(t, err, server.ErrNoPermission)
+
+	filter := server.AuditLogFilter{ActorID: "user_owner", ActionType: server.AuditLogActionMemberBan}
+	logs := []*server.AuditLog{{ID: "audi_1", ServerID: "serv_1", ActionType: server.AuditLogActionMemberBan}}
+	auditRepo.On("FindByServerID", ctx, "serv_1", filter, "", 50).Return(logs, "next", nil)
+
+	result, next, err := svc.ListAuditLogs(ctx, "serv_1", "user_mod", filter, "", 50)
+	require.NoError(t, err)
+	assert.Equal(t, logs, result)
+	assert.Equal(t, "next", next)
+}
+
+func TestService_ListAuditLogs_InvalidFilter(t *testing.T) {
+	svc, serverRepo, _, _, _ := setupServerService(t)
+
+	_, _, err := svc.ListAuditLogs(context.Background(), "serv_1", "user_owner", server.AuditLogFilter{ActionType: "NOT_AN_ACTION"}, "", 50)
+
+	assert.ErrorIs(t, err, server.ErrInvalidAuditLogQuery)
+	serverRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+}
+
+func TestService_BanMember_AuditsReason(t *testing.T) {
+	svc, serverRepo, memberRepo, _, _ := setupServerService(t)
+	auditRepo := svc.auditRepo.(*testutil.MockAuditLogRepository)
+	banRepo := svc.banRepo.(*testutil.MockBanRepository)
+	ctx := context.Background()
+
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	memberRepo.On("IsMember", ctx, "serv_1", "user_1").Return(false, nil)
+	banRepo.On("Create", ctx, mock.AnythingOfType("*server.Ban")).Return(nil)
+	auditRepo.On("Create", ctx, mock.MatchedBy(func(log *server.AuditLog) bool {
+		return log.ActionType == server.AuditLogActionMemberBan && log.Reason == "spam" && log.TargetID == "user_1"
+	})).Return(nil)
+
+	require.NoError(t, svc.BanMember(ctx, "serv_1", "user_1", "user_owner", "spam"))
+	auditRepo.AssertExpectations(t)
+}
+
+func TestAuditRetentionWorker_Sweep(t *testing.T) {
+	auditRepo := new(testutil.MockAuditLogRepository)
+	worker := NewAuditRetentionWorker(auditRepo, 24*time.Hour, time.Hour)
+
+	auditRepo.On("DeleteOlderThan", mock.Anything, mock.MatchedBy(func(cutoff time.Time) bool {
+		return time.Since(cutoff) > 23*time.Hour && time.Since(cutoff) < 25*time.Hour
+	})).Return(int64(3), nil)
+
+	worker.Sweep(context.Background())
+
+	auditRepo.AssertExpectations(t)
+}
@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
+)
+
+// authority is how far an actor's power reaches in a server. The owner is
+// above every role; everyone else can only act on members and roles below
+// their highest role, and can only grant permissions they hold themselves.
+type authority struct {
+	owner       bool
+	top         int
+	permissions server.Permission
+}
+
+// authorityOf loads the actor's authority. Non-members have none.
+func (s *Service) authorityOf(ctx context.Context, srv *server.Server, userID string) authority {
+	if srv.OwnerID == userID {
+		return authority{owner: true}
+	}
+
+	member, err := s.memberRepo.FindByServerAndUserWithRoles(ctx, srv.ID, userID)
+	if err != nil {
+		return authority{}
+	}
+
+	return authority{top: member.TopRolePosition(), permissions: member.GetPermissions()}
+}
+
+// outranks reports whether a role at position is below the actor.
+func (a authority) outranks(position int) bool {
+	return a.owner || position < a.top
+}
+
+// canGrant reports whether the actor holds every permission in perms.
+func (a authority) canGrant(perms server.Permission) bool {
+	return a.owner || a.permissions.Has(perms)
+}
+
+// requireOutranksMember fails unless the target member's highest role is
+// below the actor's. Users who are not members, like someone being banned
+// pre-emptively, have no roles to compare.
+func (s *Service) requireOutranksMember(ctx context.Context, srv *server.Server, actor authority, targetUserID string) error {
+	if actor.owner {
+		return nil
+	}
+	if srv.OwnerID == targetUserID {
+		return server.ErrRoleHierarchy
+	}
+
+	target, err := s.memberRepo.FindByServerAndUserWithRoles(ctx, srv.ID, targetUserID)
+	if errors.Is(err, server.ErrNotMember) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !actor.outranks(target.TopRolePosition()) {
+		return server.ErrRoleHierarchy
+	}
+	return nil
+}
+
+// ReorderRoles moves roles to new positions. Only roles below the actor can be
+// moved, and only to positions that are still below the actor. The @everyone
+// role always stays at the bottom.
+func (s *Service) ReorderRoles(ctx context.Context, serverID, actorUserID string, positions map[string]int) ([]*server.Role, error) {
+	srv, err := s.serverRepo.FindByID(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canManageRoles(ctx, srv, actorUserID) {
+		return nil, server.ErrNoPermission
+	}
+	actor := s.authorityOf(ctx, srv, actorUserID)
+
+	roles, err := s.roleRepo.FindByServerID(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*server.Role, len(roles))
+	for _, r := range roles {
+		byID[r.ID] = r
+	}
+
+	for roleID, position := range positions {
+		role, ok := byID[roleID]
+		if !ok {
+			return nil, server.ErrRoleNotFound
+		}
+		if role.IsDefault || position < 1 {
+			return nil, server.ErrRoleHierarchy
+		}
+		if !actor.outranks(role.Position) || !actor.outranks(position) {
+			return nil, server.ErrRoleHierarchy
+		}
+	}
+
+	if err := s.roleRepo.UpdatePositions(ctx, serverID, positions); err != nil {
+		return nil, err
+	}
+
+	changes := server.AuditChanges{}
+	for roleID, position := range positions {
+		role := byID[roleID]
+		changes.Diff(roleID, role.Position, position)
+		role.Position = position
+	}
+	if len(changes) > 0 {
+		s.audit(ctx, serverID, actorUserID, serverID, server.AuditLogActionRoleReorder, changes, "")
+	}
+
+	sort.SliceStable(roles, func(i, j int) bool { return roles[i].Position > roles[j].Position })
+
+	// Clients replace their whole role list
+	s.publish(ws.EventRoleReorder, serverID, actorUserID, roles)
+
+	return roles, nil
+}
@@ -0,0 +1,226 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/testutil"
+	"pink/internal/domain/server"
+)
+
+var (
+	roleEveryone = server.Role{ID: "role_everyone", ServerID: "serv_1", Position: 0, IsDefault: true, Permissions: server.PermissionDefaultEveryone}
+	roleMember   = server.Role{ID: "role_member", ServerID: "serv_1", Position: 1}
+	roleMod      = server.Role{ID: "role_mod", ServerID: "serv_1", Position: 2,
+		Permissions: server.PermissionKickMembers | server.PermissionBanMembers | server.PermissionManageRoles}
+	roleAdmin = server.Role{ID: "role_admin", ServerID: "serv_1", Position: 3, Permissions: server.PermissionAdministrator}
+)
+
+// withMember makes userID a member of serv_1 with the given roles.
+func withMember(ctx context.Context, memberRepo *testutil.MockMemberRepository, userID string, roles ...server.Role) {
+	memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", userID).
+		Return(&server.Member{ID: "memb_" + userID, ServerID: "serv_1", UserID: userID, Roles: roles}, nil)
+}
+
+func TestService_Moderation_RequiresHigherRole(t *testing.T) {
+	tests := []struct {
+		name string
+		act  func(svc *Service, ctx context.Context) error
+	}{
+		{"ban", func(svc *Service, ctx context.Context) error {
+			return svc.BanMember(ctx, "serv_1", "user_target", "user_mod", "")
+		}},
+		{"kick", func(svc *Service, ctx context.Context) error {
+			return svc.RemoveMember(ctx, "serv_1", "user_target", "user_mod")
+		}},
+		{"timeout", func(svc *Service, ctx context.Context) error {
+			return svc.TimeoutMember(ctx, "serv_1", "user_target", "user_mod", time.Hour, "")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, serverRepo, memberRepo, _, _ := setupServerService(t)
+			ctx := context.Background()
+
+			serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+			withMember(ctx, memberRepo, "user_mod", roleEveryone, roleMod)
+			// Same position as the actor is not below them
+			withMember(ctx, memberRepo, "user_target", roleEveryone, server.Role{ID: "role_other_mod", Position: 2})
+
+			assert.ErrorIs(t, tt.act(svc, ctx), server.ErrRoleHierarchy)
+			svc.banRepo.(*testutil.MockBanRepository).AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			memberRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+			memberRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestService_RemoveMember_TargetLookupFails(t *testing.T) {
+	svc, serverRepo, memberRepo, _, _ := setupServerService(t)
+	ctx := context.Background()
+	dbErr := errors.New("db down")
+
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	withMember(ctx, memberRepo, "user_mod", roleEveryone, roleMod)
+	memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_target").Return(nil, dbErr)
+
+	// Only a target who is not a member skips the rank check
+	assert.ErrorIs(t, svc.RemoveMember(ctx, "serv_1", "user_target", "user_mod"), dbErr)
+	memberRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_RemoveMember_BelowActor(t *testing.T) {
+	svc, serverRepo, memberRepo, _, _ := setupServerService(t)
+	ctx := context.Background()
+
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	withMember(ctx, memberRepo, "user_mod", roleEveryone, roleMod)
+	withMember(ctx, memberRepo, "user_target", roleEveryone, roleMember)
+	memberRepo.On("Delete", ctx, "serv_1", "user_target").Return(nil)
+	serverRepo.On("IncrementMemberCount", ctx, "serv_1", -1).Return(nil)
+	svc.auditRepo.(*testutil.MockAuditLogRepository).On("Create", ctx, mock.AnythingOfType("*server.AuditLog")).Return(nil)
+
+	require.NoError(t, svc.RemoveMember(ctx, "serv_1", "user_target", "user_mod"))
+	memberRepo.AssertCalled(t, "Delete", ctx, "serv_1", "user_target")
+}
+
+func TestService_UpdateMemberRoles_CannotAssignRoleAboveSelf(t *testing.T) {
+	svc, serverRepo, memberRepo, roleRepo, _ := setupServerService(t)
+	ctx := context.Background()
+
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	withMember(ctx, memberRepo, "user_mod", roleEveryone, roleMod)
+	memberRepo.On("FindByServerAndUser", ctx, "serv_1", "user_mod").Return(&server.Member{ID: "memb_user_mod", ServerID: "serv_1", UserID: "user_mod"}, nil)
+	roleRepo.On("FindByMemberID", ctx, "memb_user_mod").Return([]*server.Role{&roleEveryone, &roleMod}, nil)
+	roleRepo.On("FindByID", ctx, "role_mod").Return(&roleMod, nil)
+	roleRepo.On("FindByID", ctx, "role_admin").Return(&roleAdmin, nil)
+
+	err := svc.UpdateMemberRoles(ctx, "serv_1", "user_mod", []string{"role_mod", "role_admin"}, "user_mod")
+
+	assert.ErrorIs(t, err, server.ErrRoleHierarchy)
+	memberRepo.AssertNotCalled(t, "RemoveRole", mock.Anything, mock.Anything, mock.Anything)
+	memberRepo.AssertNotCalled(t, "AssignRole", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestService_CreateRole_Hierarchy(t *testing.T) {
+	t.Run("cannot grant missing permissions", func(t *testing.T) {
+		svc, serverRepo, memberRepo, roleRepo, _ := setupServerService(t)
+		ctx := context.Background()
+
+		serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+		withMember(ctx, memberRepo, "user_mod", roleEveryone, roleMod)
+
+		_, err := svc.CreateRole(ctx, "serv_1", "Admins", "", server.PermissionAdministrator, "user_mod")
+
+		assert.ErrorIs(t, err, server.ErrNoPermission)
+		roleRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("created below the actor", func(t *testing.T) {
+		svc, serverRepo, memberRepo, roleRepo, _ := setupServerService(t)
+		ctx := context.Background()
+
+		serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+		withMember(ctx, memberRepo, "user_mod", roleEveryone, roleMod)
+		roleRepo.On("FindByServerID", ctx, "serv_1").Return([]*server.Role{&roleAdmin, &roleMod, &roleMember, &roleEveryone}, nil)
+		roleRepo.On("CreateAt", ctx, mock.MatchedBy(func(r *server.Role) bool { return r.Position == 2 })).Return(nil)
+		svc.auditRepo.(*testutil.MockAuditLogRepository).On("Create", ctx, mock.AnythingOfType("*server.AuditLog")).Return(nil)
+
+		role, err := svc.CreateRole(ctx, "serv_1", "Helpers", "", server.PermissionKickMembers, "user_mod")
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, role.Position)
+		roleRepo.AssertExpectations(t)
+	})
+}
+
+func TestService_UpdateRole_Hierarchy(t *testing.T) {
+	tests := []struct {
+		name        string
+		roleID      string
+		permissions *server.Permission
+		position    *int
+		wantErr     error
+	}{
+		{name: "role at actor's level", roleID: "role_mod", wantErr: server.ErrRoleHierarchy},
+		{name: "moved above actor", roleID: "role_member", position: intPtr(5), wantErr: server.ErrRoleHierarchy},
+		{name: "granting missing permission", roleID: "role_member", permissions: permPtr(server.PermissionManageServer), wantErr: server.ErrNoPermission},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, serverRepo, memberRepo, roleRepo, _ := setupServerService(t)
+			ctx := context.Background()
+
+			serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+			withMember(ctx, memberRepo, "user_mod", roleEveryone, roleMod)
+			roleRepo.On("FindByID", ctx, "role_mod").Return(&server.Role{ID: "role_mod", ServerID: "serv_1", Position: 2}, nil)
+			roleRepo.On("FindByID", ctx, "role_member").Return(&server.Role{ID: "role_member", ServerID: "serv_1", Position: 1}, nil)
+
+			_, err := svc.UpdateRole(ctx, "serv_1", tt.roleID, "Renamed", "", tt.permissions, tt.position, "user_mod")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			roleRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestService_ReorderRoles(t *testing.T) {
+	t.Run("moves roles below the actor", func(t *testing.T) {
+		svc, serverRepo, memberRepo, roleRepo, _ := setupServerService(t)
+		ctx := context.Background()
+
+		helper := &server.Role{ID: "role_helper", ServerID: "serv_1", Position: 1}
+		member := &server.Role{ID: "role_member", ServerID: "serv_1", Position: 2}
+		mod := &server.Role{ID: "role_mod", ServerID: "serv_1", Position: 3, Permissions: server.PermissionManageRoles}
+
+		serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+		withMember(ctx, memberRepo, "user_mod", roleEveryone, *mod)
+		roleRepo.On("FindByServerID", ctx, "serv_1").Return([]*server.Role{mod, member, helper, &roleEveryone}, nil)
+		positions := map[string]int{"role_helper": 2, "role_member": 1}
+		roleRepo.On("UpdatePositions", ctx, "serv_1", positions).Return(nil)
+		svc.auditRepo.(*testutil.MockAuditLogRepository).On("Create", ctx, mock.MatchedBy(func(log *server.AuditLog) bool {
+			return log.ActionType == server.AuditLogActionRoleReorder
+		})).Return(nil)
+
+		roles, err := svc.ReorderRoles(ctx, "serv_1", "user_mod", positions)
+
+		require.NoError(t, err)
+		ids := make([]string, len(roles))
+		for i, r := range roles {
+			ids[i] = r.ID
+		}
+		assert.Equal(t, []string{"role_mod", "role_helper", "role_member", "role_everyone"}, ids)
+	})
+
+	t.Run("cannot move own role or above", func(t *testing.T) {
+		for _, positions := range []map[string]int{
+			{"role_mod": 1},
+			{"role_member": 4},
+			{"role_everyone": 1},
+		} {
+			svc, serverRepo, memberRepo, roleRepo, _ := setupServerService(t)
+			ctx := context.Background()
+
+			serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+			withMember(ctx, memberRepo, "user_mod", roleEveryone, roleMod)
+			roleRepo.On("FindByServerID", ctx, "serv_1").Return([]*server.Role{&roleAdmin, &roleMod, &roleMember, &roleEveryone}, nil)
+
+			_, err := svc.ReorderRoles(ctx, "serv_1", "user_mod", positions)
+
+			assert.ErrorIs(t, err, server.ErrRoleHierarchy)
+			roleRepo.AssertNotCalled(t, "UpdatePositions", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+}
+
+func intPtr(v int) *int { return &v }
+
+func permPtr(p server.Permission) *server.Permission { return &p }
@@ -0,0 +1,351 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log/slog"
+	"math/big"
+	"time"
+
+	permissionApp "pink/internal/application/permission"
+	channelDomain "pink/internal/domain/channel"
+	"pink/internal/domain/server"
+)
+
+const (
+	inviteCodeLength   = 8
+	inviteCodeAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// InviteService manages server invites. It shares the server service's
+// repositories so redeeming an invite adds members exactly like joining does.
+type InviteService struct {
+	servers     *Service
+	inviteRepo  server.InviteRepository
+	permissions *permissionApp.Resolver
+}
+
+// NewInviteService creates a new invite service.
+func NewInviteService(servers *Service, inviteRepo server.InviteRepository, permissions *permissionApp.Resolver) *InviteService {
+	return &InviteService{
+		servers:     servers,
+		inviteRepo:  inviteRepo,
+		permissions: permissions,
+	}
+}
+
+// CreateInviteCommand represents an invite creation request.
+type CreateInviteCommand struct {
+	ServerID  string
+	UserID    string
+	ChannelID *string       // Optional channel to open after joining
+	MaxUses   int           // 0 = unlimited
+	MaxAge    time.Duration // 0 = never expires
+	Temporary bool
+}
+
+// InvitePreview is what anyone holding an invite code may see of the server.
+type InvitePreview struct {
+	Invite  *server.Invite
+	Server  *server.Server
+	Channel *channelDomain.Channel // nil if the invite has no channel
+}
+
+// RedeemResult is the outcome of redeeming an invite.
+type RedeemResult struct {
+	Server        *server.Server
+	Invite        *server.Invite
+	AlreadyMember bool
+}
+
+// Create creates an invite. Creating invites requires InviteMembers, and
+// pointing one at a channel requires being able to view it, since anyone
+// holding the code sees the channel's name in the preview.
+func (s *InviteService) Create(ctx context.Context, cmd CreateInviteCommand) (*server.Invite, error) {
+	if cmd.MaxUses < 0 || cmd.MaxUses > server.MaxInviteUses || cmd.MaxAge < 0 || cmd.MaxAge > server.MaxInviteAge {
+		return nil, server.ErrInvalidInvite
+	}
+
+	srv, err := s.servers.serverRepo.FindByID(ctx, cmd.ServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.canInviteMembers(ctx, srv, cmd.UserID) {
+		return nil, server.ErrNoPermission
+	}
+
+	if cmd.ChannelID != nil {
+		ch, err := s.servers.channelRepo.FindByID(ctx, *cmd.ChannelID)
+		if err != nil || ch.ServerID != srv.ID {
+			return nil, server.ErrChannelNotFound
+		}
+		perms, err := s.permissions.ChannelPermissionsFor(ctx, ch, cmd.UserID)
+		if err != nil || !perms.Has(server.PermissionViewChannel) {
+			return nil, server.ErrChannelNotFound
+		}
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invite := &server.Invite{
+		Code:      code,
+		ServerID:  srv.ID,
+		ChannelID: cmd.ChannelID,
+		CreatorID: cmd.UserID,
+		MaxUses:   cmd.MaxUses,
+		Temporary: cmd.Temporary,
+		CreatedAt: now,
+	}
+	if cmd.MaxAge > 0 {
+		expiresAt := now.Add(cmd.MaxAge)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	if err := s.inviteRepo.Create(ctx, invite); err != nil {
+		return nil, err
+	}
+
+	s.servers.audit(ctx, srv.ID, cmd.UserID, invite.Code, server.AuditLogActionInviteCreate, server.AuditChanges{}.
+		Set("max_uses", nil, invite.MaxUses).
+		Set("max_age", nil, int(cmd.MaxAge.Seconds())).
+		Set("temporary", nil, invite.Temporary), "")
+
+	return invite, nil
+}
+
+// List lists a server's invites. Listing requires ManageServer.
+func (s *InviteService) List(ctx context.Context, serverID, userID string) ([]*server.Invite, error) {
+	srv, err := s.servers.serverRepo.FindByID(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.servers.canManageServer(ctx, srv, userID) {
+		return nil, server.ErrNoPermission
+	}
+
+	return s.inviteRepo.FindByServerID(ctx, serverID)
+}
+
+// Revoke deletes an invite. The creator may always revoke their own invite,
+// anyone else needs ManageServer.
+func (s *InviteService) Revoke(ctx context.Context, serverID, code, userID string) error {
+	invite, err := s.inviteRepo.FindByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+	if invite.ServerID != serverID {
+		return server.ErrInviteNotFound
+	}
+
+	srv, err := s.servers.serverRepo.FindByID(ctx, serverID)
+	if err != nil {
+		return err
+	}
+
+	if invite.CreatorID != userID && !s.servers.canManageServer(ctx, srv, userID) {
+		return server.ErrNoPermission
+	}
+
+	if err := s.inviteRepo.Delete(ctx, code); err != nil {
+		return err
+	}
+
+	s.servers.audit(ctx, serverID, userID, code, server.AuditLogActionInviteDelete, server.AuditChanges{}.
+		Set("uses", invite.Uses, nil), "")
+
+	return nil
+}
+
+// Preview returns the public view of the server an invite leads to.
+func (s *InviteService) Preview(ctx context.Context, code string) (*InvitePreview, error) {
+	invite, err := s.inviteRepo.FindByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !invite.IsUsable() {
+		return nil, server.ErrInviteExpired
+	}
+
+	srv, err := s.servers.serverRepo.FindByID(ctx, invite.ServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &InvitePreview{Invite: invite, Server: srv}
+	if invite.ChannelID != nil {
+		if ch, err := s.servers.channelRepo.FindByID(ctx, *invite.ChannelID); err == nil {
+			preview.Channel = ch
+		}
+	}
+
+	return preview, nil
+}
+
+// Redeem adds a user to the server an invite leads to. Invites skip the join
+// request flow of private servers, but banned users are still refused.
+func (s *InviteService) Redeem(ctx context.Context, code, userID string) (*RedeemResult, error) {
+	invite, err := s.inviteRepo.FindByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !invite.IsUsable() {
+		return nil, server.ErrInviteExpired
+	}
+
+	srv, err := s.servers.serverRepo.FindByID(ctx, invite.ServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RedeemResult{Server: srv, Invite: invite}
+
+	// Members following an invite again do not use it up
+	isMember, err := s.servers.memberRepo.IsMember(ctx, srv.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if isMember {
+		result.AlreadyMember = true
+		return result, nil
+	}
+
+	if s.servers.banRepo != nil {
+		isBanned, err := s.servers.banRepo.IsBanned(ctx, srv.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if isBanned {
+			return nil, server.ErrBanned
+		}
+	}
+
+	member, err := s.servers.createMember(ctx, srv.ID, userID, invite)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		// Joined some other way in the meantime
+		result.AlreadyMember = true
+		return result, nil
+	}
+
+	// Only a join that went through uses up the invite. If it ran out in the
+	// meantime, the join is undone.
+	if err := s.inviteRepo.Use(ctx, code); err != nil {
+		if delErr := s.servers.memberRepo.Delete(ctx, srv.ID, userID); delErr != nil {
+			slog.Warn("undo invite join failed", slog.Any("error", delErr), slog.String("serverId", srv.ID))
+		}
+		return nil, err
+	}
+	invite.Uses++
+
+	if err := s.servers.memberJoined(ctx, member, invite); err != nil {
+		return nil, err
+	}
+
+	s.resolveJoinRequest(ctx, srv.ID, userID)
+
+	return result, nil
+}
+
+// resolveJoinRequest accepts a pending join request made before the user
+// joined with an invite, so it no longer shows up for moderators.
+func (s *InviteService) resolveJoinRequest(ctx context.Context, serverID, userID string) {
+	if s.servers.joinRequestRepo == nil {
+		return
+	}
+
+	req, err := s.servers.joinRequestRepo.FindByServerAndUser(ctx, serverID, userID)
+	if err != nil || req == nil || req.Status != server.JoinRequestStatusPending {
+		return
+	}
+	if err := s.servers.joinRequestRepo.UpdateStatus(ctx, serverID, userID, server.JoinRequestStatusAccepted); err != nil {
+		slog.Warn("resolve join request failed", slog.Any("error", err), slog.String("serverId", serverID))
+	}
+}
+
+// RemoveTemporaryMemberships removes a user from every server they joined
+// with a temporary invite and were not given a role in since. It is called
+// when the user's last connection closes.
+func (s *InviteService) RemoveTemporaryMemberships(userID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	members, err := s.servers.memberRepo.FindTemporaryByUserID(ctx, userID)
+	if err != nil {
+		slog.Warn("load temporary memberships failed", slog.Any("error", err), slog.String("userId", userID))
+		return
+	}
+
+	for _, m := range members {
+		if err := s.removeTemporaryMember(ctx, m); err != nil {
+			slog.Warn("remove temporary member failed", slog.Any("error", err), slog.String("memberId", m.ID))
+		}
+	}
+}
+
+func (s *InviteService) removeTemporaryMember(ctx context.Context, m *server.Member) error {
+	withRoles, err := s.servers.memberRepo.FindByServerAndUserWithRoles(ctx, m.ServerID, m.UserID)
+	if err != nil {
+		return err
+	}
+	for _, r := range withRoles.Roles {
+		if !r.IsDefault {
+			m.IsTemporary = false
+			return s.servers.memberRepo.Update(ctx, m)
+		}
+	}
+
+	if err := s.servers.memberRepo.Delete(ctx, m.ServerID, m.UserID); err != nil {
+		if errors.Is(err, server.ErrNotMember) {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.servers.serverRepo.IncrementMemberCount(ctx, m.ServerID, -1); err != nil {
+		return err
+	}
+
+	s.servers.audit(ctx, m.ServerID, m.UserID, m.UserID, server.AuditLogActionMemberLeave, server.AuditChanges{}.
+		Set("temporary", true, nil), "")
+
+	s.servers.publishMemberLeave(withRoles, m.ServerID, m.UserID, m.UserID)
+
+	return nil
+}
+
+// canInviteMembers checks if a user can create invites.
+func (s *InviteService) canInviteMembers(ctx context.Context, srv *server.Server, userID string) bool {
+	if srv.OwnerID == userID {
+		return true
+	}
+
+	member, err := s.servers.memberRepo.FindByServerAndUserWithRoles(ctx, srv.ID, userID)
+	if err != nil {
+		return false
+	}
+
+	return member.HasPermission(server.PermissionInviteMembers)
+}
+
+// generateInviteCode returns a random base62 invite code.
+func generateInviteCode() (string, error) {
+	max := big.NewInt(int64(len(inviteCodeAlphabet)))
+	code := make([]byte, inviteCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = inviteCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
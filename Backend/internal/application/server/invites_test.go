@@ -0,0 +1,280 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"pink/internal/application/permission"
+	"pink/internal/application/testutil"
+	"pink/internal/domain/channel"
+	"pink/internal/domain/server"
+)
+
+// setupInviteService creates an invite service on top of a mocked server
+// service. Channels have no overwrites.
+func setupInviteService(t *testing.T) (*InviteService, *Service, *testutil.MockInviteRepository) {
+	svc, serverRepo, memberRepo, roleRepo, channelRepo := setupServerService(t)
+	inviteRepo := new(testutil.MockInviteRepository)
+	overwriteRepo := new(testutil.MockOverwriteRepository)
+	overwriteRepo.On("FindByChannelIDs", mock.Anything, mock.Anything).Return(map[string][]channel.PermissionOverwrite{}, nil)
+
+	resolver := permission.NewResolver(permission.NewEngine(), serverRepo, memberRepo, roleRepo, channelRepo, overwriteRepo)
+	return NewInviteService(svc, inviteRepo, resolver), svc, inviteRepo
+}
+
+func TestInviteService_Create_RequiresInviteMembers(t *testing.T) {
+	invites, svc, inviteRepo := setupInviteService(t)
+	ctx := context.Background()
+
+	serverRepo := svc.serverRepo.(*testutil.MockServerRepository)
+	memberRepo := svc.memberRepo.(*testutil.MockMemberRepository)
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_1").Return(&server.Member{
+		ID: "memb_1", ServerID: "serv_1", UserID: "user_1",
+		Roles: []server.Role{{ID: "role_everyone", IsDefault: true, Permissions: server.PermissionViewChannel}},
+	}, nil)
+
+	_, err := invites.Create(ctx, CreateInviteCommand{ServerID: "serv_1", UserID: "user_1"})
+
+	assert.ErrorIs(t, err, server.ErrNoPermission)
+	inviteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestInviteService_Create_Success(t *testing.T) {
+	invites, svc, inviteRepo := setupInviteService(t)
+	ctx := context.Background()
+
+	serverRepo := svc.serverRepo.(*testutil.MockServerRepository)
+	auditRepo := svc.auditRepo.(*testutil.MockAuditLogRepository)
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	inviteRepo.On("Create", ctx, mock.AnythingOfType("*server.Invite")).Return(nil)
+	auditRepo.On("Create", ctx, mock.MatchedBy(func(log *server.AuditLog) bool {
+		return log.ActionType == server.AuditLogActionInviteCreate
+	})).Return(nil)
+
+	invite, err := invites.Create(ctx, CreateInviteCommand{
+		ServerID:  "serv_1",
+		UserID:    "user_owner",
+		MaxUses:   5,
+		MaxAge:    time.Hour,
+		Temporary: true,
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, invite.Code, inviteCodeLength)
+	assert.Equal(t, 5, invite.MaxUses)
+	require.NotNil(t, invite.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), *invite.ExpiresAt, time.Minute)
+	assert.True(t, invite.Temporary)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestInviteService_Create_ChannelMustBeVisible(t *testing.T) {
+	invites, svc, inviteRepo := setupInviteService(t)
+	ctx := context.Background()
+
+	hidden := "chan_hidden"
+	svc.serverRepo.(*testutil.MockServerRepository).On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	svc.channelRepo.(*testutil.MockChannelRepository).On("FindByID", ctx, hidden).Return(&channel.Channel{ID: hidden, ServerID: "serv_1"}, nil)
+	svc.roleRepo.(*testutil.MockRoleRepository).On("FindDefaultRole", ctx, "serv_1").Return(&server.Role{ID: "role_everyone", IsDefault: true}, nil)
+
+	// Can invite, but @everyone grants nothing in the channel
+	svc.memberRepo.(*testutil.MockMemberRepository).On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_1").Return(&server.Member{
+		ID: "memb_1", ServerID: "serv_1", UserID: "user_1",
+		Roles: []server.Role{{ID: "role_everyone", IsDefault: true, Permissions: server.PermissionInviteMembers}},
+	}, nil)
+
+	_, err := invites.Create(ctx, CreateInviteCommand{ServerID: "serv_1", UserID: "user_1", ChannelID: &hidden})
+
+	assert.ErrorIs(t, err, server.ErrChannelNotFound)
+	inviteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestInviteService_Create_InvalidLimits(t *testing.T) {
+	invites, _, _ := setupInviteService(t)
+
+	_, err := invites.Create(context.Background(), CreateInviteCommand{
+		ServerID: "serv_1",
+		UserID:   "user_owner",
+		MaxAge:   server.MaxInviteAge + time.Second,
+	})
+
+	assert.ErrorIs(t, err, server.ErrInvalidInvite)
+}
+
+func TestInviteService_Redeem_PrivateServerSkipsJoinRequest(t *testing.T) {
+	invites, svc, inviteRepo := setupInviteService(t)
+	ctx := context.Background()
+
+	serverRepo := svc.serverRepo.(*testutil.MockServerRepository)
+	memberRepo := svc.memberRepo.(*testutil.MockMemberRepository)
+	roleRepo := svc.roleRepo.(*testutil.MockRoleRepository)
+	banRepo := svc.banRepo.(*testutil.MockBanRepository)
+	joinRequestRepo := svc.joinRequestRepo.(*testutil.MockJoinRequestRepository)
+
+	invite := &server.Invite{Code: "abcd1234", ServerID: "serv_1", CreatorID: "user_owner", MaxUses: 1, Temporary: true}
+	inviteRepo.On("FindByCode", ctx, "abcd1234").Return(invite, nil)
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner", IsPublic: false}, nil)
+	memberRepo.On("IsMember", ctx, "serv_1", "user_1").Return(false, nil)
+	banRepo.On("IsBanned", ctx, "serv_1", "user_1").Return(false, nil)
+	inviteRepo.On("Use", ctx, "abcd1234").Return(nil)
+	memberRepo.On("Create", ctx, mock.MatchedBy(func(m *server.Member) bool {
+		return m.InviteCode != nil && *m.InviteCode == "abcd1234" && m.IsTemporary
+	})).Return(nil)
+	roleRepo.On("FindDefaultRole", ctx, "serv_1").Return(&server.Role{ID: "role_everyone", IsDefault: true}, nil)
+	memberRepo.On("AssignRole", ctx, mock.AnythingOfType("string"), "role_everyone").Return(nil)
+	serverRepo.On("IncrementMemberCount", ctx, "serv_1", 1).Return(nil)
+	joinRequestRepo.On("FindByServerAndUser", ctx, "serv_1", "user_1").Return(nil, server.ErrJoinRequestNotFound)
+	auditRepo := svc.auditRepo.(*testutil.MockAuditLogRepository)
+	auditRepo.On("Create", ctx, mock.MatchedBy(func(log *server.AuditLog) bool {
+		return log.ActionType == server.AuditLogActionMemberJoin && log.Changes["invite_code"] != nil
+	})).Return(nil)
+
+	result, err := invites.Redeem(ctx, "abcd1234", "user_1")
+
+	require.NoError(t, err)
+	assert.False(t, result.AlreadyMember)
+	assert.Equal(t, 1, result.Invite.Uses)
+	memberRepo.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+	joinRequestRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestInviteService_Redeem_Refused(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		invite  *server.Invite
+		banned  bool
+		wantErr error
+	}{
+		{
+			name:    "expired",
+			invite:  &server.Invite{Code: "abcd1234", ServerID: "serv_1", ExpiresAt: &expired},
+			wantErr: server.ErrInviteExpired,
+		},
+		{
+			name:    "used up",
+			invite:  &server.Invite{Code: "abcd1234", ServerID: "serv_1", MaxUses: 2, Uses: 2},
+			wantErr: server.ErrInviteExpired,
+		},
+		{
+			name:    "banned",
+			invite:  &server.Invite{Code: "abcd1234", ServerID: "serv_1"},
+			banned:  true,
+			wantErr: server.ErrBanned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invites, svc, inviteRepo := setupInviteService(t)
+			ctx := context.Background()
+
+			inviteRepo.On("FindByCode", ctx, "abcd1234").Return(tt.invite, nil)
+			svc.serverRepo.(*testutil.MockServerRepository).On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1"}, nil)
+			svc.memberRepo.(*testutil.MockMemberRepository).On("IsMember", ctx, "serv_1", "user_1").Return(false, nil)
+			svc.banRepo.(*testutil.MockBanRepository).On("IsBanned", ctx, "serv_1", "user_1").Return(tt.banned, nil)
+
+			_, err := invites.Redeem(ctx, "abcd1234", "user_1")
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			inviteRepo.AssertNotCalled(t, "Use", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestInviteService_Redeem_FailedJoinKeepsUse(t *testing.T) {
+	invites, svc, inviteRepo := setupInviteService(t)
+	ctx := context.Background()
+
+	inviteRepo.On("FindByCode", ctx, "abcd1234").Return(&server.Invite{Code: "abcd1234", ServerID: "serv_1", MaxUses: 1}, nil)
+	svc.serverRepo.(*testutil.MockServerRepository).On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1"}, nil)
+	memberRepo := svc.memberRepo.(*testutil.MockMemberRepository)
+	memberRepo.On("IsMember", ctx, "serv_1", "user_1").Return(false, nil)
+	svc.banRepo.(*testutil.MockBanRepository).On("IsBanned", ctx, "serv_1", "user_1").Return(false, nil)
+	memberRepo.On("Create", ctx, mock.AnythingOfType("*server.Member")).Return(assert.AnError)
+
+	_, err := invites.Redeem(ctx, "abcd1234", "user_1")
+
+	assert.ErrorIs(t, err, assert.AnError)
+	inviteRepo.AssertNotCalled(t, "Use", mock.Anything, mock.Anything)
+}
+
+func TestInviteService_Redeem_UsedUpMeanwhileUndoesJoin(t *testing.T) {
+	invites, svc, inviteRepo := setupInviteService(t)
+	ctx := context.Background()
+
+	inviteRepo.On("FindByCode", ctx, "abcd1234").Return(&server.Invite{Code: "abcd1234", ServerID: "serv_1", MaxUses: 1}, nil)
+	inviteRepo.On("Use", ctx, "abcd1234").Return(server.ErrInviteExpired)
+	svc.serverRepo.(*testutil.MockServerRepository).On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1"}, nil)
+	memberRepo := svc.memberRepo.(*testutil.MockMemberRepository)
+	memberRepo.On("IsMember", ctx, "serv_1", "user_1").Return(false, nil)
+	svc.banRepo.(*testutil.MockBanRepository).On("IsBanned", ctx, "serv_1", "user_1").Return(false, nil)
+	memberRepo.On("Create", ctx, mock.AnythingOfType("*server.Member")).Return(nil)
+	memberRepo.On("Delete", ctx, "serv_1", "user_1").Return(nil)
+
+	_, err := invites.Redeem(ctx, "abcd1234", "user_1")
+
+	assert.ErrorIs(t, err, server.ErrInviteExpired)
+	memberRepo.AssertCalled(t, "Delete", ctx, "serv_1", "user_1")
+	svc.serverRepo.(*testutil.MockServerRepository).AssertNotCalled(t, "IncrementMemberCount", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestInviteService_Revoke_CreatorOrManageServer(t *testing.T) {
+	invites, svc, inviteRepo := setupInviteService(t)
+	ctx := context.Background()
+
+	serverRepo := svc.serverRepo.(*testutil.MockServerRepository)
+	memberRepo := svc.memberRepo.(*testutil.MockMemberRepository)
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", OwnerID: "user_owner"}, nil)
+	inviteRepo.On("FindByCode", ctx, "abcd1234").Return(&server.Invite{Code: "abcd1234", ServerID: "serv_1", CreatorID: "user_creator"}, nil)
+	memberRepo.On("FindByServerAndUserWithRoles", ctx, "serv_1", "user_other").Return(&server.Member{ID: "memb_other"}, nil)
+
+	err := invites.Revoke(ctx, "serv_1", "abcd1234", "user_other")
+	assert.ErrorIs(t, err, server.ErrNoPermission)
+
+	inviteRepo.On("Delete", ctx, "abcd1234").Return(nil)
+	svc.auditRepo.(*testutil.MockAuditLogRepository).On("Create", ctx, mock.AnythingOfType("*server.AuditLog")).Return(nil)
+
+	err = invites.Revoke(ctx, "serv_1", "abcd1234", "user_creator")
+	require.NoError(t, err)
+	inviteRepo.AssertCalled(t, "Delete", ctx, "abcd1234")
+}
+
+func TestInviteService_RemoveTemporaryMemberships(t *testing.T) {
+	invites, svc, _ := setupInviteService(t)
+
+	serverRepo := svc.serverRepo.(*testutil.MockServerRepository)
+	memberRepo := svc.memberRepo.(*testutil.MockMemberRepository)
+
+	roleless := &server.Member{ID: "memb_1", ServerID: "serv_1", UserID: "user_1", IsTemporary: true}
+	withRole := &server.Member{ID: "memb_2", ServerID: "serv_2", UserID: "user_1", IsTemporary: true}
+
+	memberRepo.On("FindTemporaryByUserID", mock.Anything, "user_1").Return([]*server.Member{roleless, withRole}, nil)
+	memberRepo.On("FindByServerAndUserWithRoles", mock.Anything, "serv_1", "user_1").Return(&server.Member{
+		ID: "memb_1", ServerID: "serv_1", UserID: "user_1",
+		Roles: []server.Role{{ID: "role_everyone", IsDefault: true}},
+	}, nil)
+	memberRepo.On("FindByServerAndUserWithRoles", mock.Anything, "serv_2", "user_1").Return(&server.Member{
+		ID: "memb_2", ServerID: "serv_2", UserID: "user_1",
+		Roles: []server.Role{{ID: "role_everyone", IsDefault: true}, {ID: "role_guest"}},
+	}, nil)
+	memberRepo.On("Delete", mock.Anything, "serv_1", "user_1").Return(nil)
+	serverRepo.On("IncrementMemberCount", mock.Anything, "serv_1", -1).Return(nil)
+	svc.auditRepo.(*testutil.MockAuditLogRepository).On("Create", mock.Anything, mock.AnythingOfType("*server.AuditLog")).Return(nil)
+	memberRepo.On("Update", mock.Anything, mock.MatchedBy(func(m *server.Member) bool {
+		return m.ID == "memb_2" && !m.IsTemporary
+	})).Return(nil)
+
+	invites.RemoveTemporaryMemberships("user_1")
+
+	memberRepo.AssertExpectations(t)
+	memberRepo.AssertNotCalled(t, "Delete", mock.Anything, "serv_2", "user_1")
+}
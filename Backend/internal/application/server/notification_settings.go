@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"pink/internal/domain/server"
+)
+
+// SetNotificationSettingsRepository enables per-server notification
+// settings. It must be set before the notification settings methods are used.
+func (s *Service) SetNotificationSettingsRepository(repo server.NotificationSettingsRepository) {
+	s.notificationSettingsRepo = repo
+}
+
+// GetNotificationSettings returns the user's notification settings for a
+// server, or the defaults if they never changed them.
+func (s *Service) GetNotificationSettings(ctx context.Context, serverID, userID string) (*server.NotificationSettings, error) {
+	if err := s.requireMember(ctx, serverID, userID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.notificationSettingsRepo.Find(ctx, serverID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = server.DefaultNotificationSettings(serverID, userID)
+	}
+	return settings, nil
+}
+
+// UpdateNotificationSettingsCommand replaces a member's notification
+// settings for a server.
+type UpdateNotificationSettingsCommand struct {
+	ServerID         string
+	UserID           string
+	Level            server.NotificationLevel
+	SuppressEveryone bool
+	SuppressRoles    bool
+	MutedUntil       *time.Time // nil = not muted
+}
+
+// UpdateNotificationSettings saves the user's notification settings for a
+// server.
+func (s *Service) UpdateNotificationSettings(ctx context.Context, cmd UpdateNotificationSettingsCommand) (*server.NotificationSettings, error) {
+	settings := &server.NotificationSettings{
+		ServerID:         cmd.ServerID,
+		UserID:           cmd.UserID,
+		Level:            cmd.Level,
+		SuppressEveryone: cmd.SuppressEveryone,
+		SuppressRoles:    cmd.SuppressRoles,
+		MutedUntil:       cmd.MutedUntil,
+		UpdatedAt:        time.Now(),
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.requireMember(ctx, cmd.ServerID, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.notificationSettingsRepo.Upsert(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// requireMember checks that the user is a member of the server.
+func (s *Service) requireMember(ctx context.Context, serverID, userID string) error {
+	isMember, err := s.memberRepo.IsMember(ctx, serverID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return server.ErrNotMember
+	}
+	return nil
+}
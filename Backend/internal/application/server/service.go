@@ -8,12 +8,15 @@ import (
 	"time"
 
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/google/uuid"
 
 	channelDomain "pink/internal/domain/channel"
+	"pink/internal/domain/revision"
 	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
 	"pink/internal/pkg/id"
 	"pink/internal/pkg/validation"
 )
@@ -30,6 +33,12 @@ type Service struct {
 	joinRequestRepo server.JoinRequestRepository
 	banRepo         server.BanRepository
 	auditRepo       server.AuditLogRepository
+
+	// Members' per-server notification settings, nil if not configured
+	notificationSettingsRepo server.NotificationSettingsRepository
+
+	// Notifies connected clients of changes, nil if not configured
+	events ws.ServerEventPublisher
 }
 
 // NewService creates a new server service.
@@ -53,6 +62,11 @@ func NewService(
 	}
 }
 
+// SetEventPublisher sets the publisher notified whenever server state changes.
+func (s *Service) SetEventPublisher(events ws.ServerEventPublisher) {
+	s.events = events
+}
+
 // CreateCommand represents a server creation request.
 type CreateCommand struct {
 	Name         string
@@ -141,6 +155,12 @@ func (s *Service) Create(ctx context.Context, cmd CreateCommand) (*server.Server
 		slog.Warn("create default channel failed", slog.Any("error", err))
 	}
 
+	s.audit(ctx, srv.ID, cmd.OwnerID, srv.ID, server.AuditLogActionServerCreate, server.AuditChanges{}.
+		Set("name", nil, srv.Name).
+		Set("is_public", nil, srv.IsPublic), "")
+
+	s.publishMember(ctx, ws.EventMemberJoin, srv.ID, cmd.OwnerID, cmd.OwnerID)
+
 	return srv, nil
 }
 
@@ -226,6 +246,8 @@ type UpdateCommand struct {
 	IsPublic    bool
 	Tag         *string // Server tag (1-9 chars)
 	UserID      string
+
+	RevisionRetentionDays *int // Days message revisions are kept; 0 = as long as the message
 }
 
 type JoinResult struct {
@@ -245,17 +267,37 @@ func (s *Service) Update(ctx context.Context, cmd UpdateCommand) (*server.Server
 		return nil, server.ErrNoPermission
 	}
 
+	if cmd.RevisionRetentionDays != nil {
+		if err := revision.ValidateRetentionDays(*cmd.RevisionRetentionDays); err != nil {
+			return nil, err
+		}
+	}
+
+	before := *srv
+
 	srv.Name = cmd.Name
 	srv.Description = cmd.Description
 	srv.IsPublic = cmd.IsPublic
 	if cmd.Tag != nil {
 		srv.Tag = *cmd.Tag
 	}
+	if cmd.RevisionRetentionDays != nil {
+		srv.RevisionRetentionDays = *cmd.RevisionRetentionDays
+	}
 
 	if err := s.serverRepo.Update(ctx, srv); err != nil {
 		return nil, err
 	}
 
+	s.audit(ctx, srv.ID, cmd.UserID, srv.ID, server.AuditLogActionServerUpdate, server.AuditChanges{}.
+		Diff("name", before.Name, srv.Name).
+		Diff("description", before.Description, srv.Description).
+		Diff("is_public", before.IsPublic, srv.IsPublic).
+		Diff("tag", before.Tag, srv.Tag).
+		Diff("revision_retention_days", before.RevisionRetentionDays, srv.RevisionRetentionDays), "")
+
+	s.publish(ws.EventServerUpdate, srv.ID, cmd.UserID, srv)
+
 	return srv, nil
 }
 
@@ -271,7 +313,13 @@ func (s *Service) Delete(ctx context.Context, id, userID string) error {
 		return server.ErrNoPermission
 	}
 
-	return s.serverRepo.Delete(ctx, id)
+	if err := s.serverRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.publish(ws.EventServerDelete, srv.ID, userID, srv)
+
+	return nil
 }
 
 // Join allows a user to join a server.
@@ -283,12 +331,7 @@ func (s *Service) Join(ctx context.Context, serverID, userID string) (JoinResult
 	if isMember {
 		member, err := s.memberRepo.FindByServerAndUser(ctx, serverID, userID)
 		if err == nil && member != nil {
-			everyoneRole, err := s.roleRepo.FindDefaultRole(ctx, serverID)
-			if err == nil && everyoneRole != nil {
-				if err := s.memberRepo.AssignRole(ctx, member.ID, everyoneRole.ID); err != nil {
-					slog.Warn("assign @everyone role failed", slog.Any("error", err), slog.String("memberId", member.ID))
-				}
-			}
+			s.assignEveryone(ctx, serverID, member.ID)
 		}
 		return JoinResult{Joined: true}, nil
 	}
@@ -326,42 +369,81 @@ func (s *Service) Join(ctx context.Context, serverID, userID string) (JoinResult
 		}
 	}
 
+	if err := s.addMember(ctx, serverID, userID, nil); err != nil {
+		return JoinResult{}, err
+	}
+
+	return JoinResult{Joined: true}, nil
+}
+
+// addMember creates a membership with the @everyone role and announces it.
+// invite is the invite the user redeemed, or nil. Joining a server the user
+// already belongs to is not an error.
+func (s *Service) addMember(ctx context.Context, serverID, userID string, invite *server.Invite) error {
+	member, err := s.createMember(ctx, serverID, userID, invite)
+	if err != nil || member == nil {
+		return err
+	}
+	return s.memberJoined(ctx, member, invite)
+}
+
+// createMember stores the membership row. It returns a nil member if the user
+// already joined, in which case there is nothing more to do.
+func (s *Service) createMember(ctx context.Context, serverID, userID string, invite *server.Invite) (*server.Member, error) {
 	member := &server.Member{
 		ID:       id.Generate("memb"),
 		ServerID: serverID,
 		UserID:   userID,
 		JoinedAt: time.Now(),
 	}
+	if invite != nil {
+		code := invite.Code
+		member.InviteCode = &code
+		member.IsTemporary = invite.Temporary
+	}
 
 	if err := s.memberRepo.Create(ctx, member); err != nil {
 		if errors.Is(err, server.ErrAlreadyMember) {
 			existing, findErr := s.memberRepo.FindByServerAndUser(ctx, serverID, userID)
 			if findErr == nil && existing != nil {
-				everyoneRole, roleErr := s.roleRepo.FindDefaultRole(ctx, serverID)
-				if roleErr == nil && everyoneRole != nil {
-					if err := s.memberRepo.AssignRole(ctx, existing.ID, everyoneRole.ID); err != nil {
-						slog.Warn("assign @everyone role failed", slog.Any("error", err), slog.String("memberId", existing.ID))
-					}
-				}
+				s.assignEveryone(ctx, serverID, existing.ID)
 			}
-			return JoinResult{Joined: true}, nil
+			return nil, nil
 		}
-		return JoinResult{}, err
+		return nil, err
 	}
+	return member, nil
+}
 
-	// Assign @everyone role
-	everyoneRole, err := s.roleRepo.FindDefaultRole(ctx, serverID)
-	if err == nil && everyoneRole != nil {
-		if err := s.memberRepo.AssignRole(ctx, member.ID, everyoneRole.ID); err != nil {
-			slog.Warn("assign @everyone role failed", slog.Any("error", err), slog.String("memberId", member.ID))
-		}
+// memberJoined finishes a join once the membership row exists: it assigns
+// @everyone, counts the member, audits the join and tells clients.
+func (s *Service) memberJoined(ctx context.Context, member *server.Member, invite *server.Invite) error {
+	s.assignEveryone(ctx, member.ServerID, member.ID)
+
+	if err := s.serverRepo.IncrementMemberCount(ctx, member.ServerID, 1); err != nil {
+		return err
 	}
 
-	if err := s.serverRepo.IncrementMemberCount(ctx, serverID, 1); err != nil {
-		return JoinResult{}, err
+	changes := server.AuditChanges{}
+	if invite != nil {
+		changes.Set("invite_code", nil, invite.Code).Set("temporary", nil, invite.Temporary)
 	}
+	s.audit(ctx, member.ServerID, member.UserID, member.UserID, server.AuditLogActionMemberJoin, changes, "")
 
-	return JoinResult{Joined: true}, nil
+	s.publishMember(ctx, ws.EventMemberJoin, member.ServerID, member.UserID, member.UserID)
+
+	return nil
+}
+
+// assignEveryone gives a member the server's @everyone role.
+func (s *Service) assignEveryone(ctx context.Context, serverID, memberID string) {
+	everyoneRole, err := s.roleRepo.FindDefaultRole(ctx, serverID)
+	if err != nil || everyoneRole == nil {
+		return
+	}
+	if err := s.memberRepo.AssignRole(ctx, memberID, everyoneRole.ID); err != nil {
+		slog.Warn("assign @everyone role failed", slog.Any("error", err), slog.String("memberId", memberID))
+	}
 }
 
 // Leave allows a user to leave a server.
@@ -384,6 +466,8 @@ func (s *Service) Leave(ctx context.Context, serverID, userID string) error {
 		return server.ErrOwnerCannotLeave
 	}
 
+	member := s.memberForEvent(ctx, serverID, userID)
+
 	if err := s.memberRepo.Delete(ctx, serverID, userID); err != nil {
 		if errors.Is(err, server.ErrNotMember) {
 			return nil
@@ -391,7 +475,15 @@ func (s *Service) Leave(ctx context.Context, serverID, userID string) error {
 		return err
 	}
 
-	return s.serverRepo.IncrementMemberCount(ctx, serverID, -1)
+	if err := s.serverRepo.IncrementMemberCount(ctx, serverID, -1); err != nil {
+		return err
+	}
+
+	s.audit(ctx, serverID, userID, userID, server.AuditLogActionMemberLeave, nil, "")
+
+	s.publishMemberLeave(member, serverID, userID, userID)
+
+	return nil
 }
 
 // ListMembers lists all members of a server.
@@ -424,11 +516,25 @@ func (s *Service) RemoveMember(ctx context.Context, serverID, targetUserID, acto
 		return server.ErrNoPermission
 	}
 
+	if err := s.requireOutranksMember(ctx, srv, s.authorityOf(ctx, srv, actorUserID), targetUserID); err != nil {
+		return err
+	}
+
+	member := s.memberForEvent(ctx, serverID, targetUserID)
+
 	if err := s.memberRepo.Delete(ctx, serverID, targetUserID); err != nil {
 		return err
 	}
 
-	return s.serverRepo.IncrementMemberCount(ctx, serverID, -1)
+	if err := s.serverRepo.IncrementMemberCount(ctx, serverID, -1); err != nil {
+		return err
+	}
+
+	s.audit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionMemberKick, nil, "")
+
+	s.publishMemberLeave(member, serverID, targetUserID, actorUserID)
+
+	return nil
 }
 
 // GetMemberWithRoles gets a member with their roles populated.
@@ -524,7 +630,16 @@ func (s *Service) AcceptJoinRequest(ctx context.Context, serverID, targetUserID,
 		return err
 	}
 
-	return s.joinRequestRepo.UpdateStatus(ctx, serverID, targetUserID, server.JoinRequestStatusAccepted)
+	if err := s.joinRequestRepo.UpdateStatus(ctx, serverID, targetUserID, server.JoinRequestStatusAccepted); err != nil {
+		return err
+	}
+
+	s.audit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionJoinRequestAccept, server.AuditChanges{}.
+		Set("status", string(req.Status), string(server.JoinRequestStatusAccepted)), "")
+
+	s.publishMember(ctx, ws.EventMemberJoin, serverID, targetUserID, actorUserID)
+
+	return nil
 }
 
 func (s *Service) RejectJoinRequest(ctx context.Context, serverID, targetUserID, actorUserID string) error {
@@ -541,7 +656,14 @@ func (s *Service) RejectJoinRequest(ctx context.Context, serverID, targetUserID,
 		return server.ErrNoPermission
 	}
 
-	return s.joinRequestRepo.UpdateStatus(ctx, serverID, targetUserID, server.JoinRequestStatusRejected)
+	if err := s.joinRequestRepo.UpdateStatus(ctx, serverID, targetUserID, server.JoinRequestStatusRejected); err != nil {
+		return err
+	}
+
+	s.audit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionJoinRequestReject, server.AuditChanges{}.
+		Set("status", string(server.JoinRequestStatusPending), string(server.JoinRequestStatusRejected)), "")
+
+	return nil
 }
 
 // BanMember bans a member from the server.
@@ -561,6 +683,10 @@ func (s *Service) BanMember(ctx context.Context, serverID, targetUserID, actorUs
 		return server.ErrNoPermission
 	}
 
+	if err := s.requireOutranksMember(ctx, srv, s.authorityOf(ctx, srv, actorUserID), targetUserID); err != nil {
+		return err
+	}
+
 	// 1. Create Ban Record
 	ban := &server.Ban{
 		ID:        id.Generate("ban"),
@@ -579,21 +705,21 @@ func (s *Service) BanMember(ctx context.Context, serverID, targetUserID, actorUs
 	// 2. Remove Member (if they are currently in the server)
 	isMember, err := s.memberRepo.IsMember(ctx, serverID, targetUserID)
 	if err == nil && isMember {
+		member := s.memberForEvent(ctx, serverID, targetUserID)
 		if err := s.memberRepo.Delete(ctx, serverID, targetUserID); err != nil {
 			slog.Warn("failed to remove banned member", slog.Any("error", err))
 		} else {
 			if err := s.serverRepo.IncrementMemberCount(ctx, serverID, -1); err != nil {
 				slog.Warn("failed to decrement member count", slog.Any("error", err), slog.String("serverId", serverID))
 			}
+			s.publishMemberLeave(member, serverID, targetUserID, actorUserID)
 		}
 	}
+	s.publish(ws.EventMemberBan, serverID, actorUserID, ban)
 
 	// 3. Create Audit Log
-	if err := s.logAudit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionMemberBan, map[string]interface{}{
-		"reason": reason,
-	}); err != nil {
-		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", "member_ban"))
-	}
+	s.audit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionMemberBan, server.AuditChanges{}.
+		Set("banned", false, true), reason)
 
 	return nil
 }
@@ -613,10 +739,11 @@ func (s *Service) UnbanMember(ctx context.Context, serverID, targetUserID, actor
 		return err
 	}
 
+	s.publish(ws.EventMemberUnban, serverID, actorUserID, &server.Ban{ServerID: serverID, UserID: targetUserID})
+
 	// Audit Log
-	if err := s.logAudit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionMemberUnban, nil); err != nil {
-		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", "member_unban"))
-	}
+	s.audit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionMemberUnban, server.AuditChanges{}.
+		Set("banned", true, false), "")
 
 	return nil
 }
@@ -659,11 +786,16 @@ func (s *Service) TimeoutMember(ctx context.Context, serverID, targetUserID, act
 		return server.ErrNoPermission
 	}
 
+	if err := s.requireOutranksMember(ctx, srv, s.authorityOf(ctx, srv, actorUserID), targetUserID); err != nil {
+		return err
+	}
+
 	targetMember, err := s.memberRepo.FindByServerAndUser(ctx, serverID, targetUserID)
 	if err != nil {
 		return err
 	}
 
+	previous := targetMember.CommunicationDisabledUntil
 	until := time.Now().Add(duration)
 	targetMember.CommunicationDisabledUntil = &until
 
@@ -671,13 +803,10 @@ func (s *Service) TimeoutMember(ctx context.Context, serverID, targetUserID, act
 		return err
 	}
 
-	if err := s.logAudit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionMemberTimeout, map[string]interface{}{
-		"duration": duration.String(),
-		"until":    until,
-		"reason":   reason,
-	}); err != nil {
-		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", "member_timeout"))
-	}
+	s.audit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionMemberTimeout, server.AuditChanges{}.
+		Set("communication_disabled_until", previous, until), reason)
+
+	s.publishMember(ctx, ws.EventMemberTimeout, serverID, targetUserID, actorUserID)
 
 	return nil
 }
@@ -698,20 +827,26 @@ func (s *Service) RemoveTimeout(ctx context.Context, serverID, targetUserID, act
 		return server.ErrNoPermission
 	}
 
+	if err := s.requireOutranksMember(ctx, srv, s.authorityOf(ctx, srv, actorUserID), targetUserID); err != nil {
+		return err
+	}
+
 	targetMember, err := s.memberRepo.FindByServerAndUser(ctx, serverID, targetUserID)
 	if err != nil {
 		return err
 	}
 
+	previous := targetMember.CommunicationDisabledUntil
 	targetMember.CommunicationDisabledUntil = nil
 
 	if err := s.memberRepo.Update(ctx, targetMember); err != nil {
 		return err
 	}
 
-	if err := s.logAudit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionTimeoutRemove, nil); err != nil {
-		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", "timeout_remove"))
-	}
+	s.audit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionTimeoutRemove, server.AuditChanges{}.
+		Set("communication_disabled_until", previous, nil), "")
+
+	s.publishMember(ctx, ws.EventMemberTimeout, serverID, targetUserID, actorUserID)
 
 	return nil
 }
@@ -727,6 +862,11 @@ func (s *Service) CreateRole(ctx context.Context, serverID, name, color string,
 		return nil, server.ErrNoPermission
 	}
 
+	actor := s.authorityOf(ctx, srv, actorUserID)
+	if !actor.canGrant(permissions) {
+		return nil, server.ErrNoPermission
+	}
+
 	// Determine position: find max existing position + 1
 	roles, err := s.roleRepo.FindByServerID(ctx, serverID)
 	if err != nil {
@@ -737,6 +877,16 @@ func (s *Service) CreateRole(ctx context.Context, serverID, name, color string,
 		position = roles[0].Position + 1
 	}
 
+	// Anyone but the owner creates the role right below their highest role,
+	// moving the roles from there on up by one
+	below := !actor.outranks(position)
+	if below {
+		if actor.top < 1 {
+			return nil, server.ErrRoleHierarchy
+		}
+		position = actor.top
+	}
+
 	role := &server.Role{
 		ID:            id.Generate("role"),
 		ServerID:      serverID,
@@ -750,16 +900,17 @@ func (s *Service) CreateRole(ctx context.Context, serverID, name, color string,
 		UpdatedAt:     time.Now(),
 	}
 
-	if err := s.roleRepo.Create(ctx, role); err != nil {
+	create := s.roleRepo.Create
+	if below {
+		create = s.roleRepo.CreateAt
+	}
+	if err := create(ctx, role); err != nil {
 		return nil, err
 	}
 
-	if err := s.logAudit(ctx, serverID, actorUserID, role.ID, server.AuditLogActionRoleCreate, map[string]interface{}{
-		"name":        name,
-		"permissions": permissions,
-	}); err != nil {
-		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", "role_create"))
-	}
+	s.audit(ctx, serverID, actorUserID, role.ID, server.AuditLogActionRoleCreate, roleChanges(nil, role), "")
+
+	s.publish(ws.EventRoleCreate, serverID, actorUserID, role)
 
 	return role, nil
 }
@@ -784,30 +935,31 @@ func (s *Service) UpdateRole(ctx context.Context, serverID, roleID, name, color
 		return nil, server.ErrRoleNotFound
 	}
 
-	// NOTE: Editing @everyone role - we allow permission changes but preserve role identity
-	// No action needed for default role as of now
+	actor := s.authorityOf(ctx, srv, actorUserID)
+	if !actor.outranks(role.Position) {
+		return nil, server.ErrRoleHierarchy
+	}
+	if position != nil && (role.IsDefault || *position < 1 || !actor.outranks(*position)) {
+		return nil, server.ErrRoleHierarchy
+	}
+	// Removing permissions the actor lacks is fine, adding them is not
+	if permissions != nil && !actor.canGrant(*permissions&^role.Permissions) {
+		return nil, server.ErrNoPermission
+	}
 
-	changes := make(map[string]interface{})
+	before := *role
 
 	if name != "" {
-		changes["name_old"] = role.Name
 		role.Name = name
-		changes["name_new"] = name
 	}
 	if color != "" {
-		changes["color_old"] = role.Color
 		role.Color = color
-		changes["color_new"] = color
 	}
 	if permissions != nil {
-		changes["permissions_old"] = role.Permissions
 		role.Permissions = *permissions
-		changes["permissions_new"] = *permissions
 	}
 	if position != nil {
-		changes["position_old"] = role.Position
 		role.Position = *position
-		changes["position_new"] = *position
 	}
 
 	role.UpdatedAt = time.Now()
@@ -816,9 +968,9 @@ func (s *Service) UpdateRole(ctx context.Context, serverID, roleID, name, color
 		return nil, err
 	}
 
-	if err := s.logAudit(ctx, serverID, actorUserID, role.ID, server.AuditLogActionRoleUpdate, changes); err != nil {
-		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", "role_update"))
-	}
+	s.audit(ctx, serverID, actorUserID, role.ID, server.AuditLogActionRoleUpdate, roleChanges(&before, role), "")
+
+	s.publish(ws.EventRoleUpdate, serverID, actorUserID, role)
 
 	return role, nil
 }
@@ -847,13 +999,17 @@ func (s *Service) DeleteRole(ctx context.Context, serverID, roleID, actorUserID
 		return errors.New("cannot delete default role")
 	}
 
+	if !s.authorityOf(ctx, srv, actorUserID).outranks(role.Position) {
+		return server.ErrRoleHierarchy
+	}
+
 	if err := s.roleRepo.Delete(ctx, roleID); err != nil {
 		return err
 	}
 
-	if err := s.logAudit(ctx, serverID, actorUserID, roleID, server.AuditLogActionRoleDelete, nil); err != nil {
-		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", "role_delete"))
-	}
+	s.audit(ctx, serverID, actorUserID, roleID, server.AuditLogActionRoleDelete, roleChanges(role, nil), "")
+
+	s.publish(ws.EventRoleDelete, serverID, actorUserID, role)
 
 	return nil
 }
@@ -885,6 +1041,28 @@ func (s *Service) UpdateMemberRoles(ctx context.Context, serverID, targetUserID
 		return err
 	}
 
+	// Resolve the requested roles up front, skipping unknown ones
+	requested := make([]*server.Role, 0, len(roleIDs))
+	for _, rid := range roleIDs {
+		r, err := s.roleRepo.FindByID(ctx, rid)
+		if err != nil || r.ServerID != serverID {
+			continue
+		}
+		requested = append(requested, r)
+	}
+
+	// Members can edit their own roles, but only others below them. Either
+	// way every role given or taken away must be below the actor.
+	actor := s.authorityOf(ctx, srv, actorUserID)
+	if targetUserID != actorUserID {
+		if err := s.requireOutranksMember(ctx, srv, actor, targetUserID); err != nil {
+			return err
+		}
+	}
+	if err := requireOutranksChanged(actor, currentRoles, requested); err != nil {
+		return err
+	}
+
 	defaultRole, _ := s.roleRepo.FindDefaultRole(ctx, serverID)
 
 	// Remove all currently assigned roles (except maybe default, but let's just handle IDs cleanly)
@@ -898,18 +1076,14 @@ func (s *Service) UpdateMemberRoles(ctx context.Context, serverID, targetUserID
 	}
 
 	// Add new roles
-	for _, rid := range roleIDs {
-		// Skip adding default role explicitly if we treat it as auto-assigned,
-		// but checking if it's valid role is good.
-		r, err := s.roleRepo.FindByID(ctx, rid)
-		if err != nil {
-			continue
-		}
-		if r.ServerID != serverID {
+	assigned := []string{}
+	for _, r := range requested {
+		if err := s.memberRepo.AssignRole(ctx, member.ID, r.ID); err != nil {
+			slog.Warn("assign role failed", slog.Any("error", err), slog.String("roleId", r.ID))
 			continue
 		}
-		if err := s.memberRepo.AssignRole(ctx, member.ID, rid); err != nil {
-			slog.Warn("assign role failed", slog.Any("error", err), slog.String("roleId", rid))
+		if !r.IsDefault {
+			assigned = append(assigned, r.ID)
 		}
 	}
 
@@ -919,13 +1093,49 @@ func (s *Service) UpdateMemberRoles(ctx context.Context, serverID, targetUserID
 		}
 	}
 
-	if err := s.logAudit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionMemberTimeout, map[string]interface{}{
-		"action": "update_roles",
-		"roles":  roleIDs,
-	}); err != nil {
-		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", "update_roles"))
+	// Being given a role makes a temporary membership permanent
+	if member.IsTemporary && len(roleIDs) > 0 {
+		member.IsTemporary = false
+		if err := s.memberRepo.Update(ctx, member); err != nil {
+			slog.Warn("clear temporary membership failed", slog.Any("error", err), slog.String("memberId", member.ID))
+		}
 	}
 
+	previous := []string{}
+	for _, r := range currentRoles {
+		if !r.IsDefault {
+			previous = append(previous, r.ID)
+		}
+	}
+	sort.Strings(previous)
+	sort.Strings(assigned)
+	s.audit(ctx, serverID, actorUserID, targetUserID, server.AuditLogActionMemberRoleUpdate, server.AuditChanges{}.
+		Diff("roles", previous, assigned), "")
+
+	s.publishMember(ctx, ws.EventMemberUpdate, serverID, targetUserID, actorUserID)
+
+	return nil
+}
+
+// requireOutranksChanged fails if any role being given or taken away is not
+// below the actor. Roles the member keeps are not checked.
+func requireOutranksChanged(actor authority, current, requested []*server.Role) error {
+	inCurrent := make(map[string]bool, len(current))
+	for _, r := range current {
+		inCurrent[r.ID] = true
+	}
+	inRequested := make(map[string]bool, len(requested))
+	for _, r := range requested {
+		inRequested[r.ID] = true
+		if !r.IsDefault && !inCurrent[r.ID] && !actor.outranks(r.Position) {
+			return server.ErrRoleHierarchy
+		}
+	}
+	for _, r := range current {
+		if !r.IsDefault && !inRequested[r.ID] && !actor.outranks(r.Position) {
+			return server.ErrRoleHierarchy
+		}
+	}
 	return nil
 }
 
@@ -938,7 +1148,7 @@ func memberID(ctx context.Context, s *Service, serverID, userID string) string {
 	return ""
 }
 
-func (s *Service) logAudit(ctx context.Context, serverID, actorID, targetID string, action server.AuditLogAction, changes map[string]interface{}) error {
+func (s *Service) logAudit(ctx context.Context, serverID, actorID, targetID string, action server.AuditLogAction, changes map[string]interface{}, reason string) error {
 	log := &server.AuditLog{
 		ID:         id.Generate("audi"),
 		ServerID:   serverID,
@@ -946,11 +1156,92 @@ func (s *Service) logAudit(ctx context.Context, serverID, actorID, targetID stri
 		TargetID:   targetID,
 		ActionType: action,
 		Changes:    changes,
+		Reason:     reason,
 		CreatedAt:  time.Now(),
 	}
 	return s.auditRepo.Create(ctx, log)
 }
 
+// audit records an audit entry. Failing to record it does not fail the
+// action it describes.
+func (s *Service) audit(ctx context.Context, serverID, actorID, targetID string, action server.AuditLogAction, changes server.AuditChanges, reason string) {
+	if err := s.logAudit(ctx, serverID, actorID, targetID, action, changes, reason); err != nil {
+		slog.Warn("audit log failed", slog.Any("error", err), slog.String("action", string(action)))
+	}
+}
+
+// roleChanges describes a role's fields before and after an action. before
+// is nil for a created role and after for a deleted one.
+func roleChanges(before, after *server.Role) server.AuditChanges {
+	field := func(r *server.Role, get func(*server.Role) interface{}) interface{} {
+		if r == nil {
+			return nil
+		}
+		return get(r)
+	}
+
+	changes := server.AuditChanges{}
+	for _, f := range []struct {
+		name string
+		get  func(*server.Role) interface{}
+	}{
+		{"name", func(r *server.Role) interface{} { return r.Name }},
+		{"color", func(r *server.Role) interface{} { return r.Color }},
+		{"permissions", func(r *server.Role) interface{} { return int64(r.Permissions) }},
+		{"position", func(r *server.Role) interface{} { return r.Position }},
+	} {
+		changes.Diff(f.name, field(before, f.get), field(after, f.get))
+	}
+	return changes
+}
+
+// publish sends a server event to connected clients, if a publisher is set.
+func (s *Service) publish(eventType ws.EventType, serverID, actorID string, payload interface{}) {
+	if s.events == nil {
+		return
+	}
+	s.events.PublishServerEvent(ws.ServerEvent{
+		Type:     eventType,
+		ServerID: serverID,
+		ActorID:  actorID,
+		Payload:  payload,
+	})
+}
+
+// publishMember reloads a member with their roles and publishes it, so
+// clients always receive the full member.
+func (s *Service) publishMember(ctx context.Context, eventType ws.EventType, serverID, userID, actorID string) {
+	if s.events == nil {
+		return
+	}
+
+	member, err := s.memberRepo.FindByServerAndUserWithRoles(ctx, serverID, userID)
+	if err != nil {
+		slog.Warn("member event lookup failed", slog.Any("error", err), slog.String("event", string(eventType)))
+		return
+	}
+	s.publish(eventType, serverID, actorID, member)
+}
+
+// memberForEvent loads a member about to be removed so the leave event can
+// carry it. It returns nil when no publisher is set.
+func (s *Service) memberForEvent(ctx context.Context, serverID, userID string) *server.Member {
+	if s.events == nil {
+		return nil
+	}
+	member, _ := s.memberRepo.FindByServerAndUserWithRoles(ctx, serverID, userID)
+	return member
+}
+
+// publishMemberLeave publishes a member's removal. member may be nil if it
+// could not be loaded before deletion.
+func (s *Service) publishMemberLeave(member *server.Member, serverID, userID, actorID string) {
+	if member == nil {
+		member = &server.Member{ServerID: serverID, UserID: userID}
+	}
+	s.publish(ws.EventMemberLeave, serverID, actorID, member)
+}
+
 func (s *Service) IsTimedOut(ctx context.Context, serverID, userID string) bool {
 	member, err := s.memberRepo.FindByServerAndUser(ctx, serverID, userID)
 	if err != nil {
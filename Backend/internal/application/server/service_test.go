@@ -9,7 +9,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"pink/internal/application/testutil"
+	"pink/internal/domain/revision"
 	"pink/internal/domain/server"
+	"pink/internal/domain/ws"
 )
 
 // setupServerService creates a server service with mocked dependencies.
@@ -46,6 +48,10 @@ func TestService_Create_Success(t *testing.T) {
 	roleRepo.On("Create", ctx, mock.AnythingOfType("*server.Role")).Return(nil)
 	memberRepo.On("AssignRole", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return(nil)
 	channelRepo.On("Create", ctx, mock.AnythingOfType("*channel.Channel")).Return(nil)
+	auditRepo := svc.auditRepo.(*testutil.MockAuditLogRepository)
+	auditRepo.On("Create", ctx, mock.MatchedBy(func(log *server.AuditLog) bool {
+		return log.ActionType == server.AuditLogActionServerCreate
+	})).Return(nil)
 
 	result, err := svc.Create(ctx, CreateCommand{
 		Name:         "Test Server",
@@ -60,6 +66,7 @@ func TestService_Create_Success(t *testing.T) {
 	assert.Equal(t, "Test Server", result.Name)
 	assert.Equal(t, "user_owner12345678901", result.OwnerID)
 	assert.True(t, result.IsPublic)
+	auditRepo.AssertExpectations(t)
 }
 
 // =============================================================================
@@ -131,6 +138,9 @@ func TestService_Join_PublicServer_Success(t *testing.T) {
 	roleRepo.On("FindDefaultRole", ctx, testServer.ID).Return(defaultRole, nil)
 	memberRepo.On("AssignRole", ctx, mock.AnythingOfType("string"), defaultRole.ID).Return(nil)
 	serverRepo.On("IncrementMemberCount", ctx, testServer.ID, 1).Return(nil)
+	svc.auditRepo.(*testutil.MockAuditLogRepository).On("Create", ctx, mock.MatchedBy(func(log *server.AuditLog) bool {
+		return log.ActionType == server.AuditLogActionMemberJoin
+	})).Return(nil)
 
 	result, err := svc.Join(ctx, testServer.ID, "user_joiner12345678")
 
@@ -186,6 +196,9 @@ func TestService_Leave_Success(t *testing.T) {
 	serverRepo.On("FindByID", ctx, testServer.ID).Return(testServer, nil)
 	memberRepo.On("Delete", ctx, testServer.ID, "user_member1234567890").Return(nil)
 	serverRepo.On("IncrementMemberCount", ctx, testServer.ID, -1).Return(nil)
+	svc.auditRepo.(*testutil.MockAuditLogRepository).On("Create", ctx, mock.MatchedBy(func(log *server.AuditLog) bool {
+		return log.ActionType == server.AuditLogActionMemberLeave
+	})).Return(nil)
 
 	err := svc.Leave(ctx, testServer.ID, "user_member1234567890")
 
@@ -250,6 +263,24 @@ func TestService_Delete_NotOwner(t *testing.T) {
 	assert.ErrorIs(t, err, server.ErrNoPermission)
 }
 
+// =============================================================================
+// Update Tests
+// =============================================================================
+
+func TestService_Update_InvalidRevisionRetention(t *testing.T) {
+	svc, serverRepo, _, _, _ := setupServerService(t)
+	ctx := context.Background()
+
+	serverRepo.On("FindByID", ctx, "serv_1").Return(&server.Server{ID: "serv_1", Name: "Test Server", OwnerID: "user_owner"}, nil)
+
+	_, err := svc.Update(ctx, UpdateCommand{
+		ID: "serv_1", Name: "Test Server", UserID: "user_owner", RevisionRetentionDays: intPtr(revision.MaxRetentionDays + 1),
+	})
+
+	assert.ErrorIs(t, err, revision.ErrInvalidRetention)
+	serverRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
 // =============================================================================
 // ListRoles Tests
 // =============================================================================
@@ -299,3 +330,66 @@ func TestService_IsOwner(t *testing.T) {
 	notOwner := svc.IsOwner(ctx, testServer.ID, "user_notowner123456789")
 	assert.False(t, notOwner)
 }
+
+// =============================================================================
+// Event Tests
+// =============================================================================
+
+// recordingPublisher collects published server events.
+type recordingPublisher struct {
+	events []ws.ServerEvent
+}
+
+func (p *recordingPublisher) PublishServerEvent(event ws.ServerEvent) {
+	p.events = append(p.events, event)
+}
+
+func TestService_Leave_PublishesMemberLeave(t *testing.T) {
+	svc, serverRepo, memberRepo, _, _ := setupServerService(t)
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+	ctx := context.Background()
+
+	testServer := &server.Server{
+		ID:      "serv_12345678901234567",
+		Name:    "Test Server",
+		OwnerID: "user_owner12345678901",
+	}
+	member := &server.Member{ID: "memb_1", ServerID: testServer.ID, UserID: "user_member1234567890"}
+
+	memberRepo.On("IsMember", ctx, testServer.ID, member.UserID).Return(true, nil)
+	serverRepo.On("FindByID", ctx, testServer.ID).Return(testServer, nil)
+	memberRepo.On("FindByServerAndUserWithRoles", ctx, testServer.ID, member.UserID).Return(member, nil)
+	memberRepo.On("Delete", ctx, testServer.ID, member.UserID).Return(nil)
+	serverRepo.On("IncrementMemberCount", ctx, testServer.ID, -1).Return(nil)
+	svc.auditRepo.(*testutil.MockAuditLogRepository).On("Create", ctx, mock.AnythingOfType("*server.AuditLog")).Return(nil)
+
+	require.NoError(t, svc.Leave(ctx, testServer.ID, member.UserID))
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, ws.EventMemberLeave, publisher.events[0].Type)
+	assert.Equal(t, testServer.ID, publisher.events[0].ServerID)
+	assert.Equal(t, member, publisher.events[0].Payload)
+}
+
+func TestService_Delete_PublishesServerDelete(t *testing.T) {
+	svc, serverRepo, _, _, _ := setupServerService(t)
+	publisher := &recordingPublisher{}
+	svc.SetEventPublisher(publisher)
+	ctx := context.Background()
+
+	testServer := &server.Server{
+		ID:      "serv_12345678901234567",
+		Name:    "Test Server",
+		OwnerID: "user_owner12345678901",
+	}
+
+	serverRepo.On("FindByID", ctx, testServer.ID).Return(testServer, nil)
+	serverRepo.On("Delete", ctx, testServer.ID).Return(nil)
+
+	require.NoError(t, svc.Delete(ctx, testServer.ID, testServer.OwnerID))
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, ws.EventServerDelete, publisher.events[0].Type)
+	assert.Equal(t, testServer.OwnerID, publisher.events[0].ActorID)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/mock"
 
+	"pink/internal/domain/notification"
 	"pink/internal/domain/privacy"
 	"pink/internal/domain/user"
 )
@@ -220,3 +221,58 @@ func (m *MockPrivacyRepository) Delete(ctx context.Context, userID string) error
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
+
+// =============================================================================
+// Mock Notification Repository
+// =============================================================================
+
+// MockNotificationRepository is a mock implementation of notification.Repository.
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) FindByID(ctx context.Context, id string) (*notification.Notification, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*notification.Notification), args.Error(1)
+}
+
+func (m *MockNotificationRepository) FindByUserID(ctx context.Context, userID string, cursor string, limit int) ([]*notification.Notification, string, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]*notification.Notification), args.String(1), args.Error(2)
+}
+
+func (m *MockNotificationRepository) GetUnreadCount(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockNotificationRepository) Create(ctx context.Context, n *notification.Notification) error {
+	args := m.Called(ctx, n)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) MarkAsRead(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) MarkAllAsRead(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) DeleteOldNotifications(ctx context.Context, userID string, olderThan int) error {
+	args := m.Called(ctx, userID, olderThan)
+	return args.Error(0)
+}
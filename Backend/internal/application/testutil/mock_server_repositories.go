@@ -3,11 +3,17 @@ package testutil
 
 import (
 	"context"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 
 	channelDomain "pink/internal/domain/channel"
 	"pink/internal/domain/dm"
+	"pink/internal/domain/history"
+	"pink/internal/domain/media"
+	"pink/internal/domain/reaction"
+	"pink/internal/domain/readstate"
+	"pink/internal/domain/revision"
 	"pink/internal/domain/server"
 )
 
@@ -72,12 +78,12 @@ func (m *MockDMMessageRepository) FindByID(ctx context.Context, id string) (*dm.
 	return args.Get(0).(*dm.Message), args.Error(1)
 }
 
-func (m *MockDMMessageRepository) FindByConversationID(ctx context.Context, convID, cursor string, limit int) ([]*dm.Message, string, error) {
-	args := m.Called(ctx, convID, cursor, limit)
+func (m *MockDMMessageRepository) FindByConversationID(ctx context.Context, convID string, cursor history.Cursor) ([]*dm.Message, history.Page, error) {
+	args := m.Called(ctx, convID, cursor)
 	if args.Get(0) == nil {
-		return nil, args.String(1), args.Error(2)
+		return nil, args.Get(1).(history.Page), args.Error(2)
 	}
-	return args.Get(0).([]*dm.Message), args.String(1), args.Error(2)
+	return args.Get(0).([]*dm.Message), args.Get(1).(history.Page), args.Error(2)
 }
 
 func (m *MockDMMessageRepository) Create(ctx context.Context, message *dm.Message) error {
@@ -105,6 +111,19 @@ func (m *MockDMMessageRepository) GetUnreadCount(ctx context.Context, convID, us
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockDMMessageRepository) FindPinned(ctx context.Context, convID string) ([]*dm.Message, error) {
+	args := m.Called(ctx, convID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*dm.Message), args.Error(1)
+}
+
+func (m *MockDMMessageRepository) UpdatePin(ctx context.Context, message *dm.Message) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
 // =============================================================================
 // Mock Server Repositories
 // =============================================================================
@@ -163,6 +182,14 @@ type MockMemberRepository struct {
 	mock.Mock
 }
 
+func (m *MockMemberRepository) FindByID(ctx context.Context, id string) (*server.Member, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*server.Member), args.Error(1)
+}
+
 func (m *MockMemberRepository) FindByServerID(ctx context.Context, serverID string) ([]*server.Member, error) {
 	args := m.Called(ctx, serverID)
 	if args.Get(0) == nil {
@@ -217,6 +244,14 @@ func (m *MockMemberRepository) IsMember(ctx context.Context, serverID, userID st
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockMemberRepository) FindTemporaryByUserID(ctx context.Context, userID string) ([]*server.Member, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*server.Member), args.Error(1)
+}
+
 // MockRoleRepository is a mock implementation of server.RoleRepository.
 type MockRoleRepository struct {
 	mock.Mock
@@ -259,6 +294,11 @@ func (m *MockRoleRepository) Create(ctx context.Context, role *server.Role) erro
 	return args.Error(0)
 }
 
+func (m *MockRoleRepository) CreateAt(ctx context.Context, role *server.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
 func (m *MockRoleRepository) Update(ctx context.Context, role *server.Role) error {
 	args := m.Called(ctx, role)
 	return args.Error(0)
@@ -274,6 +314,42 @@ func (m *MockRoleRepository) UpdatePositions(ctx context.Context, serverID strin
 	return args.Error(0)
 }
 
+// MockInviteRepository is a mock implementation of server.InviteRepository.
+type MockInviteRepository struct {
+	mock.Mock
+}
+
+func (m *MockInviteRepository) Create(ctx context.Context, invite *server.Invite) error {
+	args := m.Called(ctx, invite)
+	return args.Error(0)
+}
+
+func (m *MockInviteRepository) FindByCode(ctx context.Context, code string) (*server.Invite, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*server.Invite), args.Error(1)
+}
+
+func (m *MockInviteRepository) FindByServerID(ctx context.Context, serverID string) ([]*server.Invite, error) {
+	args := m.Called(ctx, serverID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*server.Invite), args.Error(1)
+}
+
+func (m *MockInviteRepository) Delete(ctx context.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
+func (m *MockInviteRepository) Use(ctx context.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
 // MockBanRepository is a mock implementation of server.BanRepository.
 type MockBanRepository struct {
 	mock.Mock
@@ -356,12 +432,17 @@ func (m *MockAuditLogRepository) Create(ctx context.Context, log *server.AuditLo
 	return args.Error(0)
 }
 
-func (m *MockAuditLogRepository) FindByServerID(ctx context.Context, serverID string, limit, offset int) ([]*server.AuditLog, error) {
-	args := m.Called(ctx, serverID, limit, offset)
+func (m *MockAuditLogRepository) FindByServerID(ctx context.Context, serverID string, filter server.AuditLogFilter, cursor string, limit int) ([]*server.AuditLog, string, error) {
+	args := m.Called(ctx, serverID, filter, cursor, limit)
 	if args.Get(0) == nil {
-		return nil, args.Error(1)
+		return nil, args.String(1), args.Error(2)
 	}
-	return args.Get(0).([]*server.AuditLog), args.Error(1)
+	return args.Get(0).([]*server.AuditLog), args.String(1), args.Error(2)
+}
+
+func (m *MockAuditLogRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
 }
 
 // MockChannelRepository is a mock implementation of channel.Repository.
@@ -425,3 +506,517 @@ func (m *MockChannelRepository) ReorderChannels(ctx context.Context, serverID st
 	args := m.Called(ctx, serverID, positions)
 	return args.Error(0)
 }
+
+// MockOverwriteRepository is a mock implementation of channel.OverwriteRepository.
+type MockOverwriteRepository struct {
+	mock.Mock
+}
+
+func (m *MockOverwriteRepository) FindByChannelID(ctx context.Context, channelID string) ([]channelDomain.PermissionOverwrite, error) {
+	args := m.Called(ctx, channelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]channelDomain.PermissionOverwrite), args.Error(1)
+}
+
+func (m *MockOverwriteRepository) FindByChannelIDs(ctx context.Context, channelIDs []string) (map[string][]channelDomain.PermissionOverwrite, error) {
+	args := m.Called(ctx, channelIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]channelDomain.PermissionOverwrite), args.Error(1)
+}
+
+func (m *MockOverwriteRepository) Create(ctx context.Context, overwrite *channelDomain.PermissionOverwrite) error {
+	args := m.Called(ctx, overwrite)
+	return args.Error(0)
+}
+
+func (m *MockOverwriteRepository) Update(ctx context.Context, overwrite *channelDomain.PermissionOverwrite) error {
+	args := m.Called(ctx, overwrite)
+	return args.Error(0)
+}
+
+func (m *MockOverwriteRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOverwriteRepository) DeleteByChannelAndTarget(ctx context.Context, channelID string, targetType channelDomain.OverwriteTargetType, targetID string) error {
+	args := m.Called(ctx, channelID, targetType, targetID)
+	return args.Error(0)
+}
+
+// MockChannelMessageRepository is a mock implementation of channel.MessageRepository.
+type MockChannelMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockChannelMessageRepository) FindByID(ctx context.Context, id string) (*channelDomain.ChannelMessage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*channelDomain.ChannelMessage), args.Error(1)
+}
+
+func (m *MockChannelMessageRepository) FindByChannelID(ctx context.Context, channelID string, cursor history.Cursor) ([]*channelDomain.ChannelMessage, history.Page, error) {
+	args := m.Called(ctx, channelID, cursor)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(history.Page), args.Error(2)
+	}
+	return args.Get(0).([]*channelDomain.ChannelMessage), args.Get(1).(history.Page), args.Error(2)
+}
+
+func (m *MockChannelMessageRepository) FindByThreadID(ctx context.Context, threadID string, cursor history.Cursor) ([]*channelDomain.ChannelMessage, history.Page, error) {
+	args := m.Called(ctx, threadID, cursor)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(history.Page), args.Error(2)
+	}
+	return args.Get(0).([]*channelDomain.ChannelMessage), args.Get(1).(history.Page), args.Error(2)
+}
+
+func (m *MockChannelMessageRepository) Create(ctx context.Context, msg *channelDomain.ChannelMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockChannelMessageRepository) Update(ctx context.Context, msg *channelDomain.ChannelMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockChannelMessageRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockChannelMessageRepository) DeleteMatching(ctx context.Context, filter channelDomain.BulkDeleteFilter) ([]*channelDomain.ChannelMessage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*channelDomain.ChannelMessage), args.Error(1)
+}
+
+func (m *MockChannelMessageRepository) Search(ctx context.Context, channelID, query string, limit int) ([]*channelDomain.ChannelMessage, error) {
+	args := m.Called(ctx, channelID, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*channelDomain.ChannelMessage), args.Error(1)
+}
+
+func (m *MockChannelMessageRepository) SearchServer(ctx context.Context, search channelDomain.MessageSearch) ([]*channelDomain.MessageSearchHit, int, error) {
+	args := m.Called(ctx, search)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*channelDomain.MessageSearchHit), args.Int(1), args.Error(2)
+}
+
+func (m *MockChannelMessageRepository) FindPinned(ctx context.Context, channelID string) ([]*channelDomain.ChannelMessage, error) {
+	args := m.Called(ctx, channelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*channelDomain.ChannelMessage), args.Error(1)
+}
+
+func (m *MockChannelMessageRepository) UpdatePin(ctx context.Context, message *channelDomain.ChannelMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockChannelMessageRepository) MarkCrossposted(ctx context.Context, id string, at time.Time) (bool, error) {
+	args := m.Called(ctx, id, at)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockThreadRepository is a mock implementation of channel.ThreadRepository.
+type MockThreadRepository struct {
+	mock.Mock
+}
+
+func (m *MockThreadRepository) FindByID(ctx context.Context, id string) (*channelDomain.Thread, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*channelDomain.Thread), args.Error(1)
+}
+
+func (m *MockThreadRepository) FindByChannelID(ctx context.Context, channelID string, archived bool, cursor string, limit int) ([]*channelDomain.Thread, string, error) {
+	args := m.Called(ctx, channelID, archived, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]*channelDomain.Thread), args.String(1), args.Error(2)
+}
+
+func (m *MockThreadRepository) Create(ctx context.Context, thread *channelDomain.Thread) error {
+	args := m.Called(ctx, thread)
+	return args.Error(0)
+}
+
+func (m *MockThreadRepository) Update(ctx context.Context, thread *channelDomain.Thread) error {
+	args := m.Called(ctx, thread)
+	return args.Error(0)
+}
+
+func (m *MockThreadRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockThreadRepository) RecordMessage(ctx context.Context, id string, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockThreadRepository) ArchiveInactive(ctx context.Context, now time.Time) ([]*channelDomain.Thread, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*channelDomain.Thread), args.Error(1)
+}
+
+func (m *MockThreadRepository) AddMember(ctx context.Context, member *channelDomain.ThreadMember) (bool, error) {
+	args := m.Called(ctx, member)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockThreadRepository) RemoveMember(ctx context.Context, threadID, userID string) (bool, error) {
+	args := m.Called(ctx, threadID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockThreadRepository) FindMembers(ctx context.Context, threadID string) ([]*channelDomain.ThreadMember, error) {
+	args := m.Called(ctx, threadID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*channelDomain.ThreadMember), args.Error(1)
+}
+
+func (m *MockThreadRepository) UpsertReadState(ctx context.Context, rs *channelDomain.ThreadReadState) error {
+	args := m.Called(ctx, rs)
+	return args.Error(0)
+}
+
+func (m *MockThreadRepository) FindReadState(ctx context.Context, threadID, userID string) (*channelDomain.ThreadReadState, error) {
+	args := m.Called(ctx, threadID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*channelDomain.ThreadReadState), args.Error(1)
+}
+
+// MockScheduledMessageRepository is a mock implementation of channel.ScheduledMessageRepository.
+type MockScheduledMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockScheduledMessageRepository) FindByID(ctx context.Context, id string) (*channelDomain.ScheduledMessage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*channelDomain.ScheduledMessage), args.Error(1)
+}
+
+func (m *MockScheduledMessageRepository) FindByAuthor(ctx context.Context, serverID, authorID string) ([]*channelDomain.ScheduledMessage, error) {
+	args := m.Called(ctx, serverID, authorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*channelDomain.ScheduledMessage), args.Error(1)
+}
+
+func (m *MockScheduledMessageRepository) CountByChannel(ctx context.Context, channelID string) (int, error) {
+	args := m.Called(ctx, channelID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockScheduledMessageRepository) Create(ctx context.Context, msg *channelDomain.ScheduledMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockScheduledMessageRepository) Update(ctx context.Context, msg *channelDomain.ScheduledMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+func (m *MockScheduledMessageRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockScheduledMessageRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]*channelDomain.ScheduledMessage, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*channelDomain.ScheduledMessage), args.Error(1)
+}
+
+func (m *MockScheduledMessageRepository) MarkPublished(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockScheduledMessageRepository) Release(ctx context.Context, msg *channelDomain.ScheduledMessage) error {
+	args := m.Called(ctx, msg)
+	return args.Error(0)
+}
+
+// MockChannelFollowRepository is a mock implementation of channel.FollowRepository.
+type MockChannelFollowRepository struct {
+	mock.Mock
+}
+
+func (m *MockChannelFollowRepository) FindByID(ctx context.Context, id string) (*channelDomain.ChannelFollow, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*channelDomain.ChannelFollow), args.Error(1)
+}
+
+func (m *MockChannelFollowRepository) FindBySource(ctx context.Context, channelID string) ([]*channelDomain.ChannelFollow, error) {
+	args := m.Called(ctx, channelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*channelDomain.ChannelFollow), args.Error(1)
+}
+
+func (m *MockChannelFollowRepository) FindByTarget(ctx context.Context, channelID string) ([]*channelDomain.ChannelFollow, error) {
+	args := m.Called(ctx, channelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*channelDomain.ChannelFollow), args.Error(1)
+}
+
+func (m *MockChannelFollowRepository) CountByTarget(ctx context.Context, channelID string) (int, error) {
+	args := m.Called(ctx, channelID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockChannelFollowRepository) Create(ctx context.Context, follow *channelDomain.ChannelFollow) error {
+	args := m.Called(ctx, follow)
+	return args.Error(0)
+}
+
+func (m *MockChannelFollowRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockMessageReactionRepository is a mock implementation of reaction.Repository.
+type MockMessageReactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageReactionRepository) Add(ctx context.Context, rc *reaction.Reaction) (bool, error) {
+	args := m.Called(ctx, rc)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageReactionRepository) Remove(ctx context.Context, messageID, userID string, emoji reaction.Emoji) (bool, error) {
+	args := m.Called(ctx, messageID, userID, emoji)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageReactionRepository) FindUsers(ctx context.Context, messageID string, emoji reaction.Emoji, cursor string, limit int) ([]*reaction.Reaction, string, error) {
+	args := m.Called(ctx, messageID, emoji, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]*reaction.Reaction), args.String(1), args.Error(2)
+}
+
+func (m *MockMessageReactionRepository) Summarize(ctx context.Context, messageIDs []string, userID string) (map[string][]reaction.Summary, error) {
+	args := m.Called(ctx, messageIDs, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]reaction.Summary), args.Error(1)
+}
+
+// MockMessageRevisionRepository is a mock implementation of revision.Repository.
+type MockMessageRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageRevisionRepository) Create(ctx context.Context, rev *revision.Revision) error {
+	args := m.Called(ctx, rev)
+	return args.Error(0)
+}
+
+func (m *MockMessageRevisionRepository) FindByMessageID(ctx context.Context, messageID string) ([]*revision.Revision, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*revision.Revision), args.Error(1)
+}
+
+func (m *MockMessageRevisionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockMediaRepository is a mock implementation of media.Repository.
+type MockMediaRepository struct {
+	mock.Mock
+}
+
+func (m *MockMediaRepository) Create(ctx context.Context, md *media.Media) error {
+	args := m.Called(ctx, md)
+	return args.Error(0)
+}
+
+func (m *MockMediaRepository) FindByID(ctx context.Context, id string) (*media.Media, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*media.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) FindByIDs(ctx context.Context, ids []string) ([]*media.Media, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*media.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) FindByUserID(ctx context.Context, userID string, limit, offset int) ([]*media.Media, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*media.Media), args.Error(1)
+}
+
+func (m *MockMediaRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMediaRepository) DeleteUnattached(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockMediaStorage is a mock implementation of media.Storage.
+type MockMediaStorage struct {
+	mock.Mock
+}
+
+func (m *MockMediaStorage) Remove(md *media.Media) error {
+	args := m.Called(md)
+	return args.Error(0)
+}
+
+// MockNotificationSettingsRepository is a mock implementation of
+// server.NotificationSettingsRepository.
+type MockNotificationSettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationSettingsRepository) Find(ctx context.Context, serverID, userID string) (*server.NotificationSettings, error) {
+	args := m.Called(ctx, serverID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*server.NotificationSettings), args.Error(1)
+}
+
+func (m *MockNotificationSettingsRepository) FindByUserIDs(ctx context.Context, serverID string, userIDs []string) (map[string]*server.NotificationSettings, error) {
+	args := m.Called(ctx, serverID, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*server.NotificationSettings), args.Error(1)
+}
+
+func (m *MockNotificationSettingsRepository) Upsert(ctx context.Context, settings *server.NotificationSettings) error {
+	args := m.Called(ctx, settings)
+	return args.Error(0)
+}
+
+// MockReadStateRepository is a mock implementation of readstate.Repository.
+type MockReadStateRepository struct {
+	mock.Mock
+}
+
+func (m *MockReadStateRepository) Upsert(ctx context.Context, rs *readstate.ReadState) error {
+	args := m.Called(ctx, rs)
+	return args.Error(0)
+}
+
+func (m *MockReadStateRepository) Get(ctx context.Context, userID, channelID string) (*readstate.ReadState, error) {
+	args := m.Called(ctx, userID, channelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*readstate.ReadState), args.Error(1)
+}
+
+func (m *MockReadStateRepository) GetByChannelID(ctx context.Context, channelID string) ([]*readstate.ReadState, error) {
+	args := m.Called(ctx, channelID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*readstate.ReadState), args.Error(1)
+}
+
+func (m *MockReadStateRepository) GetByUserAndChannels(ctx context.Context, userID string, channelIDs []string) ([]*readstate.ReadState, error) {
+	args := m.Called(ctx, userID, channelIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*readstate.ReadState), args.Error(1)
+}
+
+func (m *MockReadStateRepository) IncrementMentions(ctx context.Context, channelID string, userIDs []string) error {
+	args := m.Called(ctx, channelID, userIDs)
+	return args.Error(0)
+}
+
+// MockSlowmodeLimiter is a mock implementation of slowmode.Limiter.
+type MockSlowmodeLimiter struct {
+	mock.Mock
+}
+
+func (m *MockSlowmodeLimiter) Acquire(ctx context.Context, chatID, userID string, interval time.Duration) (time.Duration, error) {
+	args := m.Called(ctx, chatID, userID, interval)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *MockSlowmodeLimiter) Release(ctx context.Context, chatID, userID string) error {
+	args := m.Called(ctx, chatID, userID)
+	return args.Error(0)
+}
+
+// MockCrosspostLimiter is a mock implementation of channel.CrosspostLimiter.
+type MockCrosspostLimiter struct {
+	mock.Mock
+}
+
+func (m *MockCrosspostLimiter) Acquire(ctx context.Context, channelID string, limit int, window time.Duration) (time.Duration, error) {
+	args := m.Called(ctx, channelID, limit, window)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *MockCrosspostLimiter) Release(ctx context.Context, channelID string) error {
+	args := m.Called(ctx, channelID)
+	return args.Error(0)
+}
@@ -4,10 +4,12 @@ package user
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 
+	"pink/internal/domain/notification"
 	"pink/internal/domain/privacy"
 	"pink/internal/domain/user"
 	"pink/internal/infrastructure/auth"
@@ -23,6 +25,7 @@ type Service struct {
 	privacyRepo privacy.Repository
 	hasher      *auth.PasswordHasher
 	jwt         *auth.JWTService
+	notifier    notification.Dispatcher
 }
 
 // NewService creates a new user service.
@@ -44,6 +47,11 @@ func NewService(
 	}
 }
 
+// SetNotifier sets the dispatcher used for follow notifications.
+func (s *Service) SetNotifier(notifier notification.Dispatcher) {
+	s.notifier = notifier
+}
+
 // RegisterCommand represents a user registration request.
 type RegisterCommand struct {
 	Handle      string
@@ -448,6 +456,12 @@ func (s *Service) Follow(ctx context.Context, followerID, followedID string) (*F
 		return nil, err
 	}
 
+	message := "started following you"
+	if status == user.FollowStatusPending {
+		message = "requested to follow you"
+	}
+	s.notifyFollow(ctx, followedID, followerID, message)
+
 	return &FollowResult{Status: status, Pending: status == user.FollowStatusPending}, nil
 }
 
@@ -471,7 +485,32 @@ func (s *Service) AcceptFollowRequest(ctx context.Context, targetID, requesterID
 	}
 
 	// Update status to active
-	return s.followRepo.UpdateStatus(ctx, requesterID, targetID, user.FollowStatusActive)
+	if err := s.followRepo.UpdateStatus(ctx, requesterID, targetID, user.FollowStatusActive); err != nil {
+		return err
+	}
+
+	s.notifyFollow(ctx, requesterID, targetID, "accepted your follow request")
+	return nil
+}
+
+// notifyFollow tells recipientID about a follow change made by actorID.
+func (s *Service) notifyFollow(ctx context.Context, recipientID, actorID, message string) {
+	if s.notifier == nil {
+		return
+	}
+
+	targetType := "user"
+	err := s.notifier.Dispatch(ctx, &notification.Notification{
+		UserID:     recipientID,
+		Type:       notification.TypeFollow,
+		ActorID:    &actorID,
+		TargetType: &targetType,
+		TargetID:   &actorID,
+		Message:    message,
+	})
+	if err != nil {
+		slog.Warn("failed to dispatch follow notification", slog.Any("error", err), slog.String("userId", recipientID))
+	}
 }
 
 // RejectFollowRequest rejects a pending follow request.
@@ -13,13 +13,18 @@ import (
 
 // Config holds all application configuration.
 type Config struct {
-	Env   string
-	HTTP  HTTPConfig
-	DB    DatabaseConfig
-	Redis RedisConfig
-	JWT   JWTConfig
-	CORS  CORSConfig
-	Log   LogConfig
+	Env       string
+	HTTP      HTTPConfig
+	DB        DatabaseConfig
+	Redis     RedisConfig
+	JWT       JWTConfig
+	CORS      CORSConfig
+	Log       LogConfig
+	WS        WebSocketConfig
+	Audit     AuditConfig
+	Threads   ThreadConfig
+	Revisions RevisionConfig
+	Scheduled ScheduledConfig
 }
 
 // HTTPConfig holds HTTP server configuration.
@@ -64,6 +69,36 @@ type LogConfig struct {
 	Format string
 }
 
+// WebSocketConfig holds WebSocket connection configuration.
+type WebSocketConfig struct {
+	SendBufferSize     int    // must exceed the 200-event session replay buffer
+	SlowConsumerPolicy string // disconnect or coalesce
+	WriteTimeout       time.Duration
+	PongTimeout        time.Duration
+}
+
+// AuditConfig holds audit log retention configuration.
+type AuditConfig struct {
+	Retention     time.Duration // 0 keeps entries forever
+	SweepInterval time.Duration
+}
+
+// ThreadConfig holds thread auto-archive configuration.
+type ThreadConfig struct {
+	ArchiveInterval time.Duration // How often inactive threads are archived
+}
+
+// RevisionConfig holds message revision retention configuration. How long
+// revisions are kept is set per server.
+type RevisionConfig struct {
+	SweepInterval time.Duration
+}
+
+// ScheduledConfig holds scheduled message configuration.
+type ScheduledConfig struct {
+	PublishInterval time.Duration // How often due scheduled messages are sent
+}
+
 // Load reads configuration from environment variables.
 // In development mode, it loads from .env file.
 func Load() (*Config, error) {
@@ -110,6 +145,25 @@ func Load() (*Config, error) {
 			Level:  getEnv("LOG_LEVEL", "info"),
 			Format: getEnv("LOG_FORMAT", "json"),
 		},
+		WS: WebSocketConfig{
+			SendBufferSize:     getInt("WS_SEND_BUFFER_SIZE", 256),
+			SlowConsumerPolicy: getEnv("WS_SLOW_CONSUMER_POLICY", "disconnect"),
+			WriteTimeout:       getDuration("WS_WRITE_TIMEOUT", 10*time.Second),
+			PongTimeout:        getDuration("WS_PONG_TIMEOUT", 60*time.Second),
+		},
+		Audit: AuditConfig{
+			Retention:     getDuration("AUDIT_LOG_RETENTION", 0),
+			SweepInterval: getDuration("AUDIT_LOG_SWEEP_INTERVAL", time.Hour),
+		},
+		Threads: ThreadConfig{
+			ArchiveInterval: getDuration("THREAD_ARCHIVE_INTERVAL", time.Minute),
+		},
+		Revisions: RevisionConfig{
+			SweepInterval: getDuration("MESSAGE_REVISION_SWEEP_INTERVAL", time.Hour),
+		},
+		Scheduled: ScheduledConfig{
+			PublishInterval: getDuration("SCHEDULED_MESSAGE_PUBLISH_INTERVAL", 15*time.Second),
+		},
 	}
 
 	if err := cfg.Validate(); err != nil {
@@ -119,6 +173,10 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// minWSSendBufferSize is the WebSocket session replay size. Send buffers must
+// be larger so a resumed session's missed events can be queued at once.
+const minWSSendBufferSize = 200
+
 // Validate checks if required configuration values are present.
 func (c *Config) Validate() error {
 	if c.DB.URL == "" {
@@ -127,6 +185,24 @@ func (c *Config) Validate() error {
 	if c.Redis.URL == "" {
 		return fmt.Errorf("REDIS_URL is required")
 	}
+	if c.WS.SendBufferSize <= minWSSendBufferSize {
+		return fmt.Errorf("WS_SEND_BUFFER_SIZE must be greater than %d", minWSSendBufferSize)
+	}
+	if c.WS.SlowConsumerPolicy != "disconnect" && c.WS.SlowConsumerPolicy != "coalesce" {
+		return fmt.Errorf("WS_SLOW_CONSUMER_POLICY must be disconnect or coalesce")
+	}
+	if c.Audit.Retention < 0 || c.Audit.SweepInterval <= 0 {
+		return fmt.Errorf("AUDIT_LOG_RETENTION must not be negative and AUDIT_LOG_SWEEP_INTERVAL must be positive")
+	}
+	if c.Threads.ArchiveInterval <= 0 {
+		return fmt.Errorf("THREAD_ARCHIVE_INTERVAL must be positive")
+	}
+	if c.Revisions.SweepInterval <= 0 {
+		return fmt.Errorf("MESSAGE_REVISION_SWEEP_INTERVAL must be positive")
+	}
+	if c.Scheduled.PublishInterval <= 0 {
+		return fmt.Errorf("SCHEDULED_MESSAGE_PUBLISH_INTERVAL must be positive")
+	}
 	return nil
 }
 
@@ -0,0 +1,32 @@
+package channel
+
+import "time"
+
+// MaxBulkDelete is how many messages one bulk delete can remove.
+const MaxBulkDelete = 500
+
+// BulkDeleteFilter selects the messages of a channel, its threads included,
+// that a bulk delete removes: the newest Limit messages matching every filter
+// that is set.
+type BulkDeleteFilter struct {
+	ChannelID      string
+	MessageIDs     []string   // Only these messages
+	AuthorID       string     // Only messages by this user
+	After          *time.Time // Only messages sent after this time
+	Before         *time.Time // Only messages sent before this time
+	HasLinks       bool       // Only messages whose content contains a link
+	HasAttachments bool       // Only messages with attachments
+	Limit          int
+}
+
+// Validate checks the limit is between 1 and MaxBulkDelete, no more IDs than
+// that are listed, and the time window is ordered.
+func (f BulkDeleteFilter) Validate() error {
+	if f.Limit < 1 || f.Limit > MaxBulkDelete || len(f.MessageIDs) > MaxBulkDelete {
+		return ErrInvalidBulkDelete
+	}
+	if f.After != nil && f.Before != nil && !f.After.Before(*f.Before) {
+		return ErrInvalidBulkDelete
+	}
+	return nil
+}
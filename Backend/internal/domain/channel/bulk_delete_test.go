@@ -0,0 +1,37 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkDeleteFilter_Validate(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  BulkDeleteFilter
+		wantErr bool
+	}{
+		{"limit only", BulkDeleteFilter{Limit: 100}, false},
+		{"time window", BulkDeleteFilter{After: &earlier, Before: &now, Limit: MaxBulkDelete}, false},
+		{"no limit", BulkDeleteFilter{}, true},
+		{"limit too high", BulkDeleteFilter{Limit: MaxBulkDelete + 1}, true},
+		{"too many IDs", BulkDeleteFilter{MessageIDs: make([]string, MaxBulkDelete+1), Limit: 1}, true},
+		{"window reversed", BulkDeleteFilter{After: &now, Before: &earlier, Limit: 10}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidBulkDelete)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
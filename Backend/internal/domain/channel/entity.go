@@ -5,6 +5,8 @@ import (
 	"errors"
 	"time"
 
+	"pink/internal/domain/media"
+	"pink/internal/domain/reaction"
 	"pink/internal/domain/server"
 )
 
@@ -14,6 +16,9 @@ var (
 	ErrInvalidType  = errors.New("invalid channel type")
 	ErrNoPermission = errors.New("no permission to access channel")
 	ErrChannelFull  = errors.New("channel is full")
+
+	ErrOverwriteNotFound = errors.New("permission overwrite not found")
+	ErrInvalidOverwrite  = errors.New("invalid permission overwrite")
 )
 
 // ChannelType represents the type of channel.
@@ -63,6 +68,9 @@ type Channel struct {
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 
+	// Seconds a member waits between messages, here and in threads; 0 = off
+	SlowmodeSeconds int
+
 	// Voice/Video capabilities (for voice-enabled channel types)
 	UserLimit   int    // 0 = unlimited
 	Bitrate     int    // Audio bitrate in kbps (default: 64)
@@ -87,6 +95,11 @@ const (
 	OverwriteTargetMember OverwriteTargetType = "member"
 )
 
+// IsValid checks if the target type is valid.
+func (t OverwriteTargetType) IsValid() bool {
+	return t == OverwriteTargetRole || t == OverwriteTargetMember
+}
+
 // PermissionOverwrite represents a permission override for a channel.
 type PermissionOverwrite struct {
 	ID         string
@@ -97,6 +110,41 @@ type PermissionOverwrite struct {
 	Deny       server.Permission // Bitwise: explicitly denied
 }
 
+// Validate checks that the overwrite only uses known permission bits and
+// does not both allow and deny the same permission. Administrator is a
+// server-wide role permission and can never be set per channel, since it
+// would grant every other bit along with it.
+func (o *PermissionOverwrite) Validate() error {
+	if !o.TargetType.IsValid() || o.TargetID == "" {
+		return ErrInvalidOverwrite
+	}
+	if o.Allow&^server.PermissionAll != 0 || o.Deny&^server.PermissionAll != 0 {
+		return ErrInvalidOverwrite
+	}
+	if (o.Allow|o.Deny)&server.PermissionAdministrator != 0 {
+		return ErrInvalidOverwrite
+	}
+	if o.Allow&o.Deny != 0 {
+		return ErrInvalidOverwrite
+	}
+	return nil
+}
+
+// IsEmpty reports whether the overwrite neither allows nor denies anything.
+func (o *PermissionOverwrite) IsEmpty() bool {
+	return o.Allow == 0 && o.Deny == 0
+}
+
+// FindOverwrite returns the overwrite for a target, or nil if there is none.
+func FindOverwrite(overwrites []PermissionOverwrite, targetType OverwriteTargetType, targetID string) *PermissionOverwrite {
+	for i := range overwrites {
+		if overwrites[i].TargetType == targetType && overwrites[i].TargetID == targetID {
+			return &overwrites[i]
+		}
+	}
+	return nil
+}
+
 // ============================================================================
 // CHANNEL MESSAGE
 // ============================================================================
@@ -110,12 +158,47 @@ type ChannelMessage struct {
 	Content   string
 	IsEdited  bool
 	IsPinned  bool
+	PinnedAt  *time.Time
+	PinnedBy  *string
 	ReplyToID *string
+	ThreadID  *string // Set for messages posted in a thread
+	Mentions  MessageMentions
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
+	// Files attached when the message was sent, in display order. Copies of
+	// published announcements share the source message's media.
+	Attachments []*media.Media
+
+	CrosspostedAt *time.Time       // Set once an announcement is published to followers
+	Source        *CrosspostSource // Set on copies of a published announcement
+
 	// Joined fields
-	Author *MessageAuthor
+	Author    *MessageAuthor
+	Reactions []reaction.Summary
+}
+
+// MaxPinsPerChannel is how many messages a channel can have pinned.
+const MaxPinsPerChannel = 50
+
+// Pin marks the message as pinned by userID.
+func (m *ChannelMessage) Pin(userID string, at time.Time) {
+	m.IsPinned = true
+	m.PinnedAt = &at
+	m.PinnedBy = &userID
+}
+
+// IsCrosspost reports whether the message is a copy of another channel's
+// published announcement.
+func (m *ChannelMessage) IsCrosspost() bool {
+	return m.Source != nil
+}
+
+// Unpin clears the message's pin.
+func (m *ChannelMessage) Unpin() {
+	m.IsPinned = false
+	m.PinnedAt = nil
+	m.PinnedBy = nil
 }
 
 // MessageAuthor represents the author info in a message.
@@ -131,4 +214,7 @@ var (
 	ErrMessageNotFound     = errors.New("message not found")
 	ErrMessageNoPermission = errors.New("no permission to modify message")
 	ErrInvalidContent      = errors.New("invalid message content")
+	ErrTooManyPins         = errors.New("channel has too many pinned messages")
+	ErrInvalidBulkDelete   = errors.New("invalid bulk delete")
+	ErrInvalidSearch       = errors.New("invalid search query")
 )
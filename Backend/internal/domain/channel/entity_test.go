@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"pink/internal/domain/server"
 )
 
 // =============================================================================
@@ -85,3 +87,47 @@ func TestChannelType_IsTextEnabled(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// PermissionOverwrite Tests
+// =============================================================================
+
+func TestPermissionOverwrite_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		ow      PermissionOverwrite
+		wantErr bool
+	}{
+		{"role overwrite", PermissionOverwrite{TargetType: OverwriteTargetRole, TargetID: "role_1", Deny: server.PermissionViewChannel}, false},
+		{"member overwrite", PermissionOverwrite{TargetType: OverwriteTargetMember, TargetID: "memb_1", Allow: server.PermissionSendMessages}, false},
+		{"empty overwrite", PermissionOverwrite{TargetType: OverwriteTargetRole, TargetID: "role_1"}, false},
+		{"unknown target type", PermissionOverwrite{TargetType: "user", TargetID: "user_1"}, true},
+		{"missing target", PermissionOverwrite{TargetType: OverwriteTargetRole}, true},
+		{"unknown bits", PermissionOverwrite{TargetType: OverwriteTargetRole, TargetID: "role_1", Allow: 1 << 40}, true},
+		{"allows administrator", PermissionOverwrite{TargetType: OverwriteTargetMember, TargetID: "memb_1", Allow: server.PermissionAdministrator}, true},
+		{"denies administrator", PermissionOverwrite{TargetType: OverwriteTargetRole, TargetID: "role_1", Deny: server.PermissionAdministrator}, true},
+		{"allow and deny overlap", PermissionOverwrite{TargetType: OverwriteTargetRole, TargetID: "role_1",
+			Allow: server.PermissionViewChannel | server.PermissionSendMessages, Deny: server.PermissionSendMessages}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ow.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidOverwrite)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestFindOverwrite(t *testing.T) {
+	overwrites := []PermissionOverwrite{
+		{ID: "perm_1", TargetType: OverwriteTargetRole, TargetID: "x"},
+		{ID: "perm_2", TargetType: OverwriteTargetMember, TargetID: "x"},
+	}
+
+	assert.Equal(t, "perm_2", FindOverwrite(overwrites, OverwriteTargetMember, "x").ID)
+	assert.Nil(t, FindOverwrite(overwrites, OverwriteTargetMember, "y"))
+}
@@ -0,0 +1,106 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Domain errors for channel follows
+var (
+	ErrFollowNotFound     = errors.New("channel follow not found")
+	ErrAlreadyFollowing   = errors.New("channel already follows this announcement channel")
+	ErrTooManyFollows     = errors.New("channel follows too many announcement channels")
+	ErrAlreadyCrossposted = errors.New("message is already published")
+	ErrCrosspostLimited   = errors.New("too many announcements published")
+)
+
+// Cross-posting limits.
+const (
+	MaxFollowsPerChannel = 10 // Announcement channels one text channel can follow
+	MaxCrossposts        = 10 // Announcements a channel can publish per CrosspostWindow
+	CrosspostWindow      = time.Hour
+)
+
+// ChannelFollow delivers the announcements published in a source
+// announcement channel to a target text channel, usually in another server.
+type ChannelFollow struct {
+	ID              string
+	SourceServerID  string
+	SourceChannelID string
+	TargetServerID  string
+	TargetChannelID string
+	CreatedBy       *string // Nil once the user is deleted
+	CreatedAt       time.Time
+
+	// Joined fields
+	SourceServerName  string
+	SourceChannelName string
+	TargetServerName  string
+	TargetChannelName string
+}
+
+// CrosspostSource attributes a copy of a published announcement to the
+// message it was copied from. Names are the ones it was published under.
+type CrosspostSource struct {
+	MessageID   string
+	ChannelID   string
+	ServerID    string
+	ChannelName string
+	ServerName  string
+}
+
+// CrosspostLimitError is returned when a channel publishes more than
+// MaxCrossposts announcements within CrosspostWindow. It matches
+// ErrCrosspostLimited.
+type CrosspostLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CrosspostLimitError) Error() string {
+	return ErrCrosspostLimited.Error()
+}
+
+func (e *CrosspostLimitError) Unwrap() error {
+	return ErrCrosspostLimited
+}
+
+// RetryAfterSeconds is RetryAfter rounded up to whole seconds.
+func (e *CrosspostLimitError) RetryAfterSeconds() int {
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// CrosspostLimiter counts the announcements each channel publishes.
+type CrosspostLimiter interface {
+	// Acquire counts a publish from channelID unless limit were already made
+	// within window, in which case it returns the time until the next one
+	// is allowed.
+	Acquire(ctx context.Context, channelID string, limit int, window time.Duration) (time.Duration, error)
+
+	// Release gives back a publish counted by Acquire that did not happen.
+	Release(ctx context.Context, channelID string) error
+}
+
+// FollowRepository defines the interface for channel follow data access.
+type FollowRepository interface {
+	// FindByID finds a follow by its ID, with channel and server names.
+	FindByID(ctx context.Context, id string) (*ChannelFollow, error)
+
+	// FindBySource finds the follows of an announcement channel, oldest
+	// first, with channel and server names.
+	FindBySource(ctx context.Context, channelID string) ([]*ChannelFollow, error)
+
+	// FindByTarget finds the announcement channels a channel follows,
+	// oldest first, with channel and server names.
+	FindByTarget(ctx context.Context, channelID string) ([]*ChannelFollow, error)
+
+	// CountByTarget counts the announcement channels a channel follows.
+	CountByTarget(ctx context.Context, channelID string) (int, error)
+
+	// Create creates a follow. Returns ErrAlreadyFollowing if the target
+	// already follows the source.
+	Create(ctx context.Context, follow *ChannelFollow) error
+
+	// Delete deletes a follow.
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,20 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrosspostLimitError(t *testing.T) {
+	err := &CrosspostLimitError{RetryAfter: 2100 * time.Millisecond}
+
+	assert.ErrorIs(t, err, ErrCrosspostLimited)
+	assert.Equal(t, 3, err.RetryAfterSeconds())
+}
+
+func TestChannelMessage_IsCrosspost(t *testing.T) {
+	assert.False(t, (&ChannelMessage{}).IsCrosspost())
+	assert.True(t, (&ChannelMessage{Source: &CrosspostSource{MessageID: "cmsg_1"}}).IsCrosspost())
+}
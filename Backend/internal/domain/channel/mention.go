@@ -0,0 +1,78 @@
+package channel
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxMentionsPerMessage caps how many users and roles a message can mention;
+// further mentions are ignored.
+const MaxMentionsPerMessage = 50
+
+// MessageMentions is the resolved set of mentions stored with a message.
+type MessageMentions struct {
+	UserIDs  []string
+	RoleIDs  []string
+	Everyone bool // @everyone: every member who can see the channel
+	Here     bool // @here: members who can see the channel and are online
+}
+
+// IsEmpty reports whether the message mentions no one.
+func (m MessageMentions) IsEmpty() bool {
+	return len(m.UserIDs) == 0 && len(m.RoleIDs) == 0 && !m.Everyone && !m.Here
+}
+
+// MentionTokens are the mentions written in a message, before they are
+// resolved against the server.
+type MentionTokens struct {
+	Handles  []string // @handle, lowercased
+	RoleIDs  []string // <@&roleId>
+	Everyone bool     // @everyone
+	Here     bool     // @here
+}
+
+var (
+	// A mention starts the text or follows a non-word character, so that
+	// e-mail addresses are not mentions.
+	handleMention = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9][A-Za-z0-9._-]*[A-Za-z0-9]|[A-Za-z0-9])`)
+	roleMention   = regexp.MustCompile(`<@&([A-Za-z0-9_]+)>`)
+)
+
+// ParseMentions finds the mentions written in message content. Repeated
+// mentions are listed once, and at most MaxMentionsPerMessage users and roles
+// are kept.
+func ParseMentions(content string) MentionTokens {
+	var tokens MentionTokens
+	seen := make(map[string]bool)
+	count := 0
+
+	for _, match := range roleMention.FindAllStringSubmatch(content, -1) {
+		roleID := match[1]
+		if seen["&"+roleID] || count >= MaxMentionsPerMessage {
+			continue
+		}
+		seen["&"+roleID] = true
+		tokens.RoleIDs = append(tokens.RoleIDs, roleID)
+		count++
+	}
+
+	for _, match := range handleMention.FindAllStringSubmatch(content, -1) {
+		handle := strings.ToLower(match[1])
+		switch handle {
+		case "everyone":
+			tokens.Everyone = true
+			continue
+		case "here":
+			tokens.Here = true
+			continue
+		}
+		if seen[handle] || count >= MaxMentionsPerMessage {
+			continue
+		}
+		seen[handle] = true
+		tokens.Handles = append(tokens.Handles, handle)
+		count++
+	}
+
+	return tokens
+}
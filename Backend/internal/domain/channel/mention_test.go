@@ -0,0 +1,37 @@
+package channel
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	tokens := ParseMentions("@Alice, ask @bob.smith (not bob@example.com) and <@&role_1> @alice\n@everyone")
+
+	assert.Equal(t, []string{"alice", "bob.smith"}, tokens.Handles)
+	assert.Equal(t, []string{"role_1"}, tokens.RoleIDs)
+	assert.True(t, tokens.Everyone)
+	assert.False(t, tokens.Here)
+}
+
+func TestParseMentions_Here(t *testing.T) {
+	tokens := ParseMentions("standup in 5 @here.")
+
+	assert.Empty(t, tokens.Handles)
+	assert.True(t, tokens.Here)
+	assert.False(t, tokens.Everyone)
+}
+
+func TestParseMentions_Capped(t *testing.T) {
+	var b strings.Builder
+	for i := 0; i < MaxMentionsPerMessage+10; i++ {
+		fmt.Fprintf(&b, "@user%d ", i)
+	}
+
+	tokens := ParseMentions(b.String())
+
+	assert.Len(t, tokens.Handles, MaxMentionsPerMessage)
+}
@@ -1,6 +1,11 @@
 package channel
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"pink/internal/domain/history"
+)
 
 // Repository defines the interface for channel data access.
 type Repository interface {
@@ -37,8 +42,13 @@ type MessageRepository interface {
 	// FindByID finds a message by its ID.
 	FindByID(ctx context.Context, id string) (*ChannelMessage, error)
 
-	// FindByChannelID finds messages in a channel with pagination.
-	FindByChannelID(ctx context.Context, channelID string, cursor string, limit int) ([]*ChannelMessage, string, error)
+	// FindByChannelID finds the page of a channel's messages a cursor
+	// selects, newest first. Thread messages are not included.
+	FindByChannelID(ctx context.Context, channelID string, cursor history.Cursor) ([]*ChannelMessage, history.Page, error)
+
+	// FindByThreadID finds the page of a thread's messages a cursor selects,
+	// newest first.
+	FindByThreadID(ctx context.Context, threadID string, cursor history.Cursor) ([]*ChannelMessage, history.Page, error)
 
 	// Create creates a new message.
 	Create(ctx context.Context, message *ChannelMessage) error
@@ -49,8 +59,29 @@ type MessageRepository interface {
 	// Delete deletes a message by its ID.
 	Delete(ctx context.Context, id string) error
 
+	// DeleteMatching deletes the messages a bulk delete filter selects and
+	// returns them with their attachments.
+	DeleteMatching(ctx context.Context, filter BulkDeleteFilter) ([]*ChannelMessage, error)
+
 	// Search searches messages in a channel.
 	Search(ctx context.Context, channelID, query string, limit int) ([]*ChannelMessage, error)
+
+	// SearchServer finds a page of the messages in the searched channels that
+	// match the query, and the total number of matches.
+	SearchServer(ctx context.Context, search MessageSearch) ([]*MessageSearchHit, int, error)
+
+	// FindPinned finds a channel's pinned messages, most recently pinned
+	// first. Thread messages are not included.
+	FindPinned(ctx context.Context, channelID string) ([]*ChannelMessage, error)
+
+	// UpdatePin saves a message's IsPinned, PinnedAt and PinnedBy. Pinning
+	// returns ErrTooManyPins, without saving anything, when the channel
+	// already has MaxPinsPerChannel pinned messages.
+	UpdatePin(ctx context.Context, message *ChannelMessage) error
+
+	// MarkCrossposted sets CrosspostedAt on a message that has not been
+	// published yet, reporting whether it did.
+	MarkCrossposted(ctx context.Context, id string, at time.Time) (bool, error)
 }
 
 // OverwriteRepository defines the interface for permission overwrite data access.
@@ -0,0 +1,121 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Domain errors for scheduled messages
+var (
+	ErrScheduledNotFound  = errors.New("scheduled message not found")
+	ErrScheduledPublished = errors.New("scheduled message is already being published")
+	ErrInvalidSchedule    = errors.New("invalid publish time")
+	ErrTooManyScheduled   = errors.New("channel has too many scheduled messages")
+)
+
+// Scheduling limits.
+const (
+	MaxScheduleAhead       = 90 * 24 * time.Hour
+	MaxScheduledPerChannel = 50 // Pending or failed messages per channel
+
+	// ScheduledClaimLease is how long a publisher holds a claimed message
+	// before another may take it over, in case the first stopped midway.
+	ScheduledClaimLease = 5 * time.Minute
+)
+
+// ScheduledStatus is where a scheduled message is on its way to the channel.
+type ScheduledStatus string
+
+const (
+	ScheduledPending    ScheduledStatus = "pending"    // Waiting for its publish time
+	ScheduledPublishing ScheduledStatus = "publishing" // Claimed by the publisher
+	ScheduledFailed     ScheduledStatus = "failed"     // Could not be published; see Failure
+)
+
+// ScheduledMessage is a channel message queued to be sent at PublishAt. It
+// is deleted once published; until then its author can edit or cancel it.
+type ScheduledMessage struct {
+	ID            string
+	ServerID      string
+	ChannelID     string
+	AuthorID      string
+	Content       string
+	AttachmentIDs []string // Media uploaded by the author, in display order
+	PublishAt     time.Time
+	Status        ScheduledStatus
+	Failure       string     // Why publishing failed, set while Status is failed
+	ClaimedAt     *time.Time // When the publisher claimed it, set while Status is publishing
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// IsEditable reports whether the author can still change or cancel the
+// message: it is waiting, or failed and can be rescheduled.
+func (m *ScheduledMessage) IsEditable() bool {
+	return m.Status == ScheduledPending || m.Status == ScheduledFailed
+}
+
+// Reschedule sets a new publish time and queues the message again.
+func (m *ScheduledMessage) Reschedule(publishAt, now time.Time) {
+	m.PublishAt = publishAt
+	m.Status = ScheduledPending
+	m.Failure = ""
+	m.UpdatedAt = now
+}
+
+// Fail records why the message could not be published.
+func (m *ScheduledMessage) Fail(reason string, now time.Time) {
+	m.Status = ScheduledFailed
+	m.Failure = reason
+	m.UpdatedAt = now
+}
+
+// ValidatePublishAt checks a publish time is in the future and at most
+// MaxScheduleAhead away.
+func ValidatePublishAt(publishAt, now time.Time) error {
+	if !publishAt.After(now) || publishAt.Sub(now) > MaxScheduleAhead {
+		return ErrInvalidSchedule
+	}
+	return nil
+}
+
+// ScheduledMessageRepository defines the interface for scheduled message
+// data access.
+type ScheduledMessageRepository interface {
+	// FindByID finds a scheduled message by its ID.
+	FindByID(ctx context.Context, id string) (*ScheduledMessage, error)
+
+	// FindByAuthor finds an author's scheduled messages in a server, next
+	// to be published first.
+	FindByAuthor(ctx context.Context, serverID, authorID string) ([]*ScheduledMessage, error)
+
+	// CountByChannel counts a channel's pending and failed scheduled
+	// messages.
+	CountByChannel(ctx context.Context, channelID string) (int, error)
+
+	// Create creates a scheduled message.
+	Create(ctx context.Context, msg *ScheduledMessage) error
+
+	// Update saves the content, attachments, publish time, status and
+	// failure of a message that is pending or failed. Returns
+	// ErrScheduledPublished once the publisher has claimed it.
+	Update(ctx context.Context, msg *ScheduledMessage) error
+
+	// Delete deletes a message that is pending or failed. Returns
+	// ErrScheduledPublished once the publisher has claimed it.
+	Delete(ctx context.Context, id string) error
+
+	// ClaimDue marks up to limit pending messages due by now as publishing
+	// and returns them, along with messages whose claim is older than
+	// ScheduledClaimLease. Messages claimed by another publisher are skipped.
+	ClaimDue(ctx context.Context, now time.Time, limit int) ([]*ScheduledMessage, error)
+
+	// MarkPublished deletes a claimed message that has been sent.
+	MarkPublished(ctx context.Context, id string) error
+
+	// Release saves the status and failure of a claimed message that was not
+	// sent: failed, or pending to be tried again. Nothing is saved if the
+	// claim was taken over by another publisher meanwhile.
+	Release(ctx context.Context, msg *ScheduledMessage) error
+}
@@ -0,0 +1,52 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePublishAt(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		publishAt time.Time
+		wantErr   bool
+	}{
+		{"in an hour", now.Add(time.Hour), false},
+		{"at the limit", now.Add(MaxScheduleAhead), false},
+		{"now", now, true},
+		{"in the past", now.Add(-time.Minute), true},
+		{"beyond the limit", now.Add(MaxScheduleAhead + time.Second), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePublishAt(tt.publishAt, now)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidSchedule)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestScheduledMessage_Reschedule(t *testing.T) {
+	now := time.Now()
+	msg := &ScheduledMessage{Status: ScheduledPending}
+
+	msg.Fail("no permission to access channel", now)
+	assert.Equal(t, ScheduledFailed, msg.Status)
+	assert.True(t, msg.IsEditable())
+
+	msg.Reschedule(now.Add(time.Hour), now)
+	assert.Equal(t, ScheduledPending, msg.Status)
+	assert.Empty(t, msg.Failure)
+	assert.Equal(t, now.Add(time.Hour), msg.PublishAt)
+
+	msg.Status = ScheduledPublishing
+	assert.False(t, msg.IsEditable())
+}
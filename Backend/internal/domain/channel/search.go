@@ -0,0 +1,146 @@
+package channel
+
+import (
+	"strings"
+	"time"
+)
+
+// Search limits. Paging deeper than MaxSearchOffset would have the database
+// rank and skip ever more matches; narrow the search instead.
+const (
+	MaxSearchLength   = 512
+	MaxSearchPageSize = 50
+	MaxSearchOffset   = 5000
+)
+
+// Markers around the matched words of a search snippet.
+const (
+	SearchHighlightStart = "<mark>"
+	SearchHighlightEnd   = "</mark>"
+)
+
+// SearchHas is a kind of content the has: operator asks for.
+type SearchHas string
+
+const (
+	SearchHasLink  SearchHas = "link"  // Content contains a link
+	SearchHasFile  SearchHas = "file"  // Any attachment
+	SearchHasImage SearchHas = "image" // An image attachment
+)
+
+// MessageQuery is a parsed message search: the words to match and the
+// operators written with them. Every operator given must match; from:, in:
+// and mentions: match any of their values when repeated.
+type MessageQuery struct {
+	Text     string      // Words to match, in web search syntax
+	From     []string    // from: author handles, lowercased
+	In       []string    // in: channel names or IDs
+	Has      []SearchHas // has: every kind given
+	Mentions []string    // mentions: handles of mentioned users, lowercased
+	Before   *time.Time  // before: sent before this instant
+	After    *time.Time  // after: sent at or after this instant
+	Pinned   *bool       // pinned: only pinned or only unpinned messages
+}
+
+// ParseMessageQuery splits a search into its words and operators. Operators
+// are written key:value; before: and after: take a YYYY-MM-DD date (UTC) and
+// exclude that day. A leading @ on handles and # on channels is optional.
+// Tokens whose key is not an operator, such as links, are searched as text.
+// Returns ErrInvalidSearch for an empty or too long search, or an operator
+// with a value it does not accept.
+func ParseMessageQuery(raw string) (MessageQuery, error) {
+	var q MessageQuery
+	if len(raw) > MaxSearchLength {
+		return q, ErrInvalidSearch
+	}
+
+	var words []string
+	hasOperator := false
+	for _, token := range strings.Fields(raw) {
+		key, value, ok := strings.Cut(token, ":")
+		if !ok || value == "" || !isSearchOperator(strings.ToLower(key)) {
+			words = append(words, token)
+			continue
+		}
+		if err := q.apply(strings.ToLower(key), value); err != nil {
+			return MessageQuery{}, err
+		}
+		hasOperator = true
+	}
+	q.Text = strings.Join(words, " ")
+
+	if q.Text == "" && !hasOperator {
+		return MessageQuery{}, ErrInvalidSearch
+	}
+	if q.After != nil && q.Before != nil && !q.After.Before(*q.Before) {
+		return MessageQuery{}, ErrInvalidSearch
+	}
+	return q, nil
+}
+
+func isSearchOperator(key string) bool {
+	switch key {
+	case "from", "in", "has", "mentions", "before", "after", "pinned":
+		return true
+	}
+	return false
+}
+
+func (q *MessageQuery) apply(key, value string) error {
+	switch key {
+	case "from":
+		q.From = append(q.From, strings.ToLower(strings.TrimPrefix(value, "@")))
+	case "mentions":
+		q.Mentions = append(q.Mentions, strings.ToLower(strings.TrimPrefix(value, "@")))
+	case "in":
+		q.In = append(q.In, strings.TrimPrefix(value, "#"))
+	case "has":
+		has := SearchHas(strings.ToLower(value))
+		switch has {
+		case SearchHasLink, SearchHasFile, SearchHasImage:
+			q.Has = append(q.Has, has)
+		default:
+			return ErrInvalidSearch
+		}
+	case "before", "after":
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return ErrInvalidSearch
+		}
+		if key == "before" {
+			q.Before = &day
+		} else {
+			next := day.AddDate(0, 0, 1)
+			q.After = &next
+		}
+	case "pinned":
+		var pinned bool
+		switch strings.ToLower(value) {
+		case "true", "yes":
+			pinned = true
+		case "false", "no":
+		default:
+			return ErrInvalidSearch
+		}
+		q.Pinned = &pinned
+	}
+	return nil
+}
+
+// MessageSearch is a message query narrowed to what a member may search: the
+// channels they can view, and one page of results.
+type MessageSearch struct {
+	ServerID   string
+	ChannelIDs []string // Channels searched, their threads included
+	Query      MessageQuery
+	Offset     int
+	Limit      int
+}
+
+// MessageSearchHit is a message matching a search. Snippet is its content,
+// HTML-escaped and shortened around the matched words, which are wrapped in
+// SearchHighlightStart and SearchHighlightEnd.
+type MessageSearchHit struct {
+	Message *ChannelMessage
+	Snippet string
+}
@@ -0,0 +1,57 @@
+package channel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessageQuery(t *testing.T) {
+	q, err := ParseMessageQuery(`release notes from:@Alice in:#general has:image mentions:bob after:2024-03-01 before:2024-04-01 pinned:true https://example.com`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "release notes https://example.com", q.Text)
+	assert.Equal(t, []string{"alice"}, q.From)
+	assert.Equal(t, []string{"general"}, q.In)
+	assert.Equal(t, []SearchHas{SearchHasImage}, q.Has)
+	assert.Equal(t, []string{"bob"}, q.Mentions)
+	require.NotNil(t, q.After)
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), *q.After)
+	require.NotNil(t, q.Before)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), *q.Before)
+	require.NotNil(t, q.Pinned)
+	assert.True(t, *q.Pinned)
+}
+
+func TestParseMessageQuery_OperatorsOnly(t *testing.T) {
+	q, err := ParseMessageQuery("from:alice from:carol has:link")
+	require.NoError(t, err)
+
+	assert.Empty(t, q.Text)
+	assert.Equal(t, []string{"alice", "carol"}, q.From)
+	assert.Equal(t, []SearchHas{SearchHasLink}, q.Has)
+}
+
+func TestParseMessageQuery_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"empty", "   "},
+		{"too long", strings.Repeat("a", MaxSearchLength+1)},
+		{"unknown has", "has:video"},
+		{"bad date", "before:yesterday"},
+		{"bad pinned", "pinned:maybe"},
+		{"empty window", "after:2024-03-01 before:2024-03-02"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMessageQuery(tt.query)
+			assert.ErrorIs(t, err, ErrInvalidSearch)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Domain errors for threads
+var (
+	ErrThreadNotFound  = errors.New("thread not found")
+	ErrThreadExists    = errors.New("message already has a thread")
+	ErrThreadLocked    = errors.New("thread is locked")
+	ErrInvalidThread   = errors.New("invalid thread")
+	ErrNotThreadMember = errors.New("not a thread member")
+)
+
+// Thread name limits and auto-archive durations.
+const (
+	MaxThreadNameLength      = 100
+	DefaultThreadAutoArchive = 24 * time.Hour
+)
+
+// threadAutoArchiveDurations are the inactivity periods a thread can be
+// archived after.
+var threadAutoArchiveDurations = map[time.Duration]bool{
+	time.Hour:          true,
+	24 * time.Hour:     true,
+	3 * 24 * time.Hour: true,
+	7 * 24 * time.Hour: true,
+}
+
+// IsValidAutoArchive checks d is one of the supported auto-archive durations.
+func IsValidAutoArchive(d time.Duration) bool {
+	return threadAutoArchiveDurations[d]
+}
+
+// Thread is a sub-conversation in a text channel, started from a message or
+// on its own. Its messages are channel messages with ThreadID set, and it
+// inherits the parent channel's permissions.
+type Thread struct {
+	ID               string
+	ChannelID        string // Parent channel
+	ServerID         string
+	ParentMessageID  *string // Message the thread was started from, nil if standalone
+	OwnerID          string
+	Name             string
+	MessageCount     int
+	MemberCount      int
+	IsArchived       bool
+	IsLocked         bool // Only members with ManageMessages can post or unarchive
+	AutoArchiveAfter time.Duration
+	LastMessageAt    time.Time
+	ArchivedAt       *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// IsInactive reports whether the thread has gone without messages for longer
+// than its auto-archive duration.
+func (t *Thread) IsInactive(now time.Time) bool {
+	return !t.IsArchived && now.Sub(t.LastMessageAt) > t.AutoArchiveAfter
+}
+
+// Archive marks the thread archived.
+func (t *Thread) Archive(now time.Time) {
+	t.IsArchived = true
+	t.ArchivedAt = &now
+	t.UpdatedAt = now
+}
+
+// Unarchive reopens the thread and restarts its inactivity timer.
+func (t *Thread) Unarchive(now time.Time) {
+	t.IsArchived = false
+	t.ArchivedAt = nil
+	t.LastMessageAt = now
+	t.UpdatedAt = now
+}
+
+// ThreadMember is a user following a thread.
+type ThreadMember struct {
+	ThreadID string
+	UserID   string
+	JoinedAt time.Time
+}
+
+// ThreadReadState is how far a user has read in a thread.
+type ThreadReadState struct {
+	ThreadID          string
+	UserID            string
+	LastReadMessageID *string
+	LastReadAt        time.Time
+}
+
+// ThreadRepository defines the interface for thread data access.
+type ThreadRepository interface {
+	// FindByID finds a thread by its ID.
+	FindByID(ctx context.Context, id string) (*Thread, error)
+
+	// FindByChannelID lists a channel's active threads by latest activity,
+	// or its archived threads by archive time, with pagination.
+	FindByChannelID(ctx context.Context, channelID string, archived bool, cursor string, limit int) ([]*Thread, string, error)
+
+	// Create creates a new thread.
+	Create(ctx context.Context, thread *Thread) error
+
+	// Update updates a thread's name, state and auto-archive duration.
+	Update(ctx context.Context, thread *Thread) error
+
+	// Delete deletes a thread and its messages.
+	Delete(ctx context.Context, id string) error
+
+	// RecordMessage counts a new message and moves the thread's activity time.
+	RecordMessage(ctx context.Context, id string, at time.Time) error
+
+	// ArchiveInactive archives every thread inactive past its auto-archive
+	// duration and returns them.
+	ArchiveInactive(ctx context.Context, now time.Time) ([]*Thread, error)
+
+	// AddMember adds a member, reporting whether they were not one already.
+	AddMember(ctx context.Context, member *ThreadMember) (bool, error)
+
+	// RemoveMember removes a member, reporting whether they were one.
+	RemoveMember(ctx context.Context, threadID, userID string) (bool, error)
+
+	// FindMembers lists a thread's members.
+	FindMembers(ctx context.Context, threadID string) ([]*ThreadMember, error)
+
+	// UpsertReadState records how far a user has read.
+	UpsertReadState(ctx context.Context, rs *ThreadReadState) error
+
+	// FindReadState finds a user's read state, or nil if they have none.
+	FindReadState(ctx context.Context, threadID, userID string) (*ThreadReadState, error)
+}
@@ -0,0 +1,34 @@
+package channel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidAutoArchive(t *testing.T) {
+	for _, d := range []time.Duration{time.Hour, 24 * time.Hour, 3 * 24 * time.Hour, 7 * 24 * time.Hour} {
+		assert.True(t, IsValidAutoArchive(d), d.String())
+	}
+	for _, d := range []time.Duration{0, time.Minute, 2 * time.Hour, 30 * 24 * time.Hour} {
+		assert.False(t, IsValidAutoArchive(d), d.String())
+	}
+}
+
+func TestThread_IsInactive(t *testing.T) {
+	now := time.Now()
+	thread := &Thread{AutoArchiveAfter: time.Hour, LastMessageAt: now.Add(-2 * time.Hour)}
+
+	assert.True(t, thread.IsInactive(now))
+	assert.False(t, thread.IsInactive(now.Add(-90*time.Minute)))
+
+	thread.Archive(now)
+	assert.False(t, thread.IsInactive(now), "archived threads are not archived again")
+	assert.Equal(t, now, *thread.ArchivedAt)
+
+	thread.Unarchive(now)
+	assert.False(t, thread.IsArchived)
+	assert.Nil(t, thread.ArchivedAt)
+	assert.False(t, thread.IsInactive(now.Add(30*time.Minute)), "unarchiving restarts the timer")
+}
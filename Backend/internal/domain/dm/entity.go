@@ -4,6 +4,9 @@ package dm
 import (
 	"errors"
 	"time"
+
+	"pink/internal/domain/media"
+	"pink/internal/domain/reaction"
 )
 
 // Domain errors
@@ -14,6 +17,7 @@ var (
 	ErrNoPermission         = errors.New("no permission")
 	ErrCannotMessageSelf    = errors.New("cannot message yourself")
 	ErrInvalidContent       = errors.New("invalid message content")
+	ErrTooManyPins          = errors.New("conversation has too many pinned messages")
 )
 
 // Conversation represents a DM conversation between users.
@@ -45,12 +49,36 @@ type Message struct {
 	SenderID       string
 	Content        string
 	IsEdited       bool
+	IsPinned       bool
+	PinnedAt       *time.Time
+	PinnedBy       *string
 	ReadAt         *time.Time
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 
+	// Files attached when the message was sent, in display order
+	Attachments []*media.Media
+
 	// Joined fields
-	Sender *ConversationUser
+	Sender    *ConversationUser
+	Reactions []reaction.Summary
+}
+
+// MaxPinsPerConversation is how many messages a conversation can have pinned.
+const MaxPinsPerConversation = 50
+
+// Pin marks the message as pinned by userID.
+func (m *Message) Pin(userID string, at time.Time) {
+	m.IsPinned = true
+	m.PinnedAt = &at
+	m.PinnedBy = &userID
+}
+
+// Unpin clears the message's pin.
+func (m *Message) Unpin() {
+	m.IsPinned = false
+	m.PinnedAt = nil
+	m.PinnedBy = nil
 }
 
 // ReadReceipt represents a read receipt for a conversation.
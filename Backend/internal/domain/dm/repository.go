@@ -1,6 +1,10 @@
 package dm
 
-import "context"
+import (
+	"context"
+
+	"pink/internal/domain/history"
+)
 
 // ConversationRepository defines the interface for conversation data access.
 type ConversationRepository interface {
@@ -28,8 +32,9 @@ type MessageRepository interface {
 	// FindByID finds a message by its ID.
 	FindByID(ctx context.Context, id string) (*Message, error)
 
-	// FindByConversationID finds messages in a conversation with pagination.
-	FindByConversationID(ctx context.Context, convID, cursor string, limit int) ([]*Message, string, error)
+	// FindByConversationID finds the page of a conversation's messages a
+	// cursor selects, newest first.
+	FindByConversationID(ctx context.Context, convID string, cursor history.Cursor) ([]*Message, history.Page, error)
 
 	// Create creates a new message.
 	Create(ctx context.Context, message *Message) error
@@ -45,4 +50,13 @@ type MessageRepository interface {
 
 	// GetUnreadCount gets the unread message count for a user in a conversation.
 	GetUnreadCount(ctx context.Context, convID, userID string) (int, error)
+
+	// FindPinned finds a conversation's pinned messages, most recently
+	// pinned first.
+	FindPinned(ctx context.Context, convID string) ([]*Message, error)
+
+	// UpdatePin saves a message's IsPinned, PinnedAt and PinnedBy. Pinning
+	// returns ErrTooManyPins, without saving anything, when the
+	// conversation already has MaxPinsPerConversation pinned messages.
+	UpdatePin(ctx context.Context, message *Message) error
 }
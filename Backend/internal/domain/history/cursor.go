@@ -0,0 +1,121 @@
+// Package history defines the cursors used to page through message history:
+// channel, thread, DM and stream chat messages.
+package history
+
+import "errors"
+
+// Domain errors
+var (
+	ErrInvalidCursor = errors.New("invalid history cursor")
+)
+
+// Page size limits.
+const (
+	DefaultLimit = 20
+	MaxLimit     = 50
+)
+
+// Cursor positions a page of message history relative to an anchor message.
+// At most one of Before, After and Around is set; with none of them the page
+// holds the newest messages.
+type Cursor struct {
+	Before string // Messages older than this message
+	After  string // Messages newer than this message
+	Around string // Messages centered on this message, which is included
+	Limit  int
+}
+
+// NewCursor builds a cursor from message IDs, of which at most one may be
+// given. A limit outside 1..MaxLimit is replaced by defaultLimit.
+func NewCursor(before, after, around string, limit, defaultLimit int) (Cursor, error) {
+	set := 0
+	for _, anchor := range []string{before, after, around} {
+		if anchor != "" {
+			set++
+		}
+	}
+	if set > 1 {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	if limit <= 0 || limit > MaxLimit {
+		limit = defaultLimit
+	}
+	return Cursor{Before: before, After: after, Around: around, Limit: limit}, nil
+}
+
+// Anchor returns the message the page is positioned by, empty for the newest
+// messages.
+func (c Cursor) Anchor() string {
+	switch {
+	case c.Around != "":
+		return c.Around
+	case c.After != "":
+		return c.After
+	}
+	return c.Before
+}
+
+// PageSize returns the number of messages a page holds.
+func (c Cursor) PageSize() int {
+	if c.Limit <= 0 || c.Limit > MaxLimit {
+		return DefaultLimit
+	}
+	return c.Limit
+}
+
+// Span returns how many messages the page reads older than its anchor and
+// how many from the anchor on. Around pages include the anchor with the
+// newer half.
+func (c Cursor) Span() (older, newer int) {
+	size := c.PageSize()
+	switch {
+	case c.Around != "":
+		return size / 2, size - size/2
+	case c.After != "":
+		return 0, size
+	}
+	return size, 0
+}
+
+// Page tells where a page of history sits: the cursors that continue it in
+// either direction, empty when there is nothing more that way.
+type Page struct {
+	Before string // Pass as Before to read older messages
+	After  string // Pass as After to read newer messages
+}
+
+// Window assembles a page, newest first, from the messages read on either
+// side of the cursor's anchor: older newest first, newer oldest first, each
+// with up to one message more than Span asks for to tell whether the
+// history continues. id returns a message's ID.
+func Window[T any](c Cursor, older, newer []T, id func(T) string) ([]T, Page) {
+	olderSpan, newerSpan := c.Span()
+	moreOlder := len(older) > olderSpan
+	moreNewer := len(newer) > newerSpan
+	if moreOlder {
+		older = older[:olderSpan]
+	}
+	if moreNewer {
+		newer = newer[:newerSpan]
+	}
+
+	messages := make([]T, 0, len(newer)+len(older))
+	for i := len(newer) - 1; i >= 0; i-- {
+		messages = append(messages, newer[i])
+	}
+	messages = append(messages, older...)
+
+	var page Page
+	if len(messages) == 0 {
+		return messages, page
+	}
+	// Paging away from the anchor leaves it on the other side
+	if moreOlder || c.After != "" {
+		page.Before = id(messages[len(messages)-1])
+	}
+	if moreNewer || c.Before != "" {
+		page.After = id(messages[0])
+	}
+	return messages, page
+}
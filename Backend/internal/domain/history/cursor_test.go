@@ -0,0 +1,92 @@
+package history
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCursor(t *testing.T) {
+	c, err := NewCursor("", "", "msg_5", 0, DefaultLimit)
+	require.NoError(t, err)
+	assert.Equal(t, Cursor{Around: "msg_5", Limit: DefaultLimit}, c)
+	assert.Equal(t, "msg_5", c.Anchor())
+
+	c, err = NewCursor("msg_5", "", "", MaxLimit+1, 30)
+	require.NoError(t, err)
+	assert.Equal(t, 30, c.Limit)
+
+	_, err = NewCursor("msg_5", "msg_1", "", 10, DefaultLimit)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func identity(id string) string { return id }
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		cursor   Cursor
+		older    []string // Newest first
+		newer    []string // Oldest first
+		want     []string
+		wantPage Page
+	}{
+		{
+			name:     "latest with more",
+			cursor:   Cursor{Limit: 2},
+			older:    []string{"m9", "m8", "m7"},
+			want:     []string{"m9", "m8"},
+			wantPage: Page{Before: "m8"},
+		},
+		{
+			name:   "latest at the start",
+			cursor: Cursor{Limit: 3},
+			older:  []string{"m2", "m1"},
+			want:   []string{"m2", "m1"},
+		},
+		{
+			name:     "before leaves the anchor newer",
+			cursor:   Cursor{Before: "m5", Limit: 2},
+			older:    []string{"m4", "m3"},
+			want:     []string{"m4", "m3"},
+			wantPage: Page{After: "m4"},
+		},
+		{
+			name:     "after with more",
+			cursor:   Cursor{After: "m5", Limit: 2},
+			newer:    []string{"m6", "m7", "m8"},
+			want:     []string{"m7", "m6"},
+			wantPage: Page{Before: "m6", After: "m7"},
+		},
+		{
+			name:     "around centers on the anchor",
+			cursor:   Cursor{Around: "m5", Limit: 4},
+			older:    []string{"m4", "m3", "m2"},
+			newer:    []string{"m5", "m6"},
+			want:     []string{"m6", "m5", "m4", "m3"},
+			wantPage: Page{Before: "m3"},
+		},
+		{
+			name:     "around one message",
+			cursor:   Cursor{Around: "m5", Limit: 1},
+			older:    []string{"m4"},
+			newer:    []string{"m5", "m6"},
+			want:     []string{"m5"},
+			wantPage: Page{Before: "m5", After: "m5"},
+		},
+		{
+			name:   "empty",
+			cursor: Cursor{Before: "m1", Limit: 5},
+			want:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, page := Window(tt.cursor, tt.older, tt.newer, identity)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPage, page)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"pink/internal/domain/history"
 	"pink/internal/domain/user"
 )
 
@@ -24,7 +25,7 @@ type StreamMessageRepository interface {
 	// Create persists a new chat message.
 	Create(ctx context.Context, msg *ChatMessage) error
 
-	// FindByStreamID returns recent messages for a stream with cursor-based pagination.
-	// before is the ID of the message to start fetching before (for pagination).
-	FindByStreamID(ctx context.Context, streamID string, limit int, before string) ([]*ChatMessage, error)
+	// FindByStreamID returns the page of a stream's chat messages a cursor
+	// selects, newest first.
+	FindByStreamID(ctx context.Context, streamID string, cursor history.Cursor) ([]*ChatMessage, history.Page, error)
 }
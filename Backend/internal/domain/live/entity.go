@@ -59,6 +59,9 @@ type Stream struct {
 	PlaybackURL string // HLS playback URL
 	MaxQuality  string // 1080p, 720p, 480p
 
+	// Seconds a viewer waits between chat messages; 0 = off
+	SlowmodeSeconds int
+
 	// Joined fields
 	Streamer *StreamerInfo
 	Category *Category
@@ -14,6 +14,9 @@ var (
 	ErrInvalidFile  = errors.New("invalid file")
 	ErrFileTooLarge = errors.New("file too large")
 	ErrInvalidType  = errors.New("invalid file type")
+
+	ErrAlreadyAttached    = errors.New("media is already attached to a message")
+	ErrTooManyAttachments = errors.New("too many attachments")
 )
 
 // MediaType represents the type of media.
@@ -29,6 +32,9 @@ const (
 // MaxFileSize is the maximum allowed file size (25MB)
 const MaxFileSize = 25 * 1024 * 1024
 
+// MaxAttachmentsPerMessage is how many files a message can carry.
+const MaxAttachmentsPerMessage = 10
+
 // AllowedMimeTypes defines which MIME types are allowed
 var AllowedMimeTypes = map[string]MediaType{
 	"image/jpeg":      TypeImage,
@@ -53,6 +59,8 @@ type Media struct {
 	MimeType     string
 	Type         MediaType
 	Size         int64
+	Width        *int // Set for images whose dimensions could be read
+	Height       *int
 	URL          string
 	CreatedAt    time.Time
 }
@@ -61,8 +69,20 @@ type Media struct {
 type Repository interface {
 	Create(ctx context.Context, media *Media) error
 	FindByID(ctx context.Context, id string) (*Media, error)
+	FindByIDs(ctx context.Context, ids []string) ([]*Media, error)
 	FindByUserID(ctx context.Context, userID string, limit, offset int) ([]*Media, error)
 	Delete(ctx context.Context, id string) error
+
+	// DeleteUnattached deletes a media record unless a message still has it
+	// attached, and reports whether it did. Published announcements share
+	// their media with their copies.
+	DeleteUnattached(ctx context.Context, id string) (bool, error)
+}
+
+// Storage holds the uploaded files behind media records.
+type Storage interface {
+	// Remove deletes a media record's file. Missing files are not an error.
+	Remove(media *Media) error
 }
 
 // ValidateMimeType checks if the MIME type is allowed.
@@ -81,3 +101,36 @@ func ValidateFileSize(size int64) error {
 	}
 	return nil
 }
+
+// OrderAttachments checks that every requested ID was found and is owned by
+// userID, returning the media in the order requested. Repeated IDs are
+// attached once.
+func OrderAttachments(ids []string, found []*Media, userID string) ([]*Media, error) {
+	byID := make(map[string]*Media, len(found))
+	for _, m := range found {
+		byID[m.ID] = m
+	}
+
+	seen := make(map[string]bool, len(ids))
+	ordered := make([]*Media, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		m, ok := byID[id]
+		if !ok {
+			return nil, ErrNotFound
+		}
+		if m.UserID != userID {
+			return nil, ErrNoPermission
+		}
+		ordered = append(ordered, m)
+	}
+
+	if len(ordered) > MaxAttachmentsPerMessage {
+		return nil, ErrTooManyAttachments
+	}
+	return ordered, nil
+}
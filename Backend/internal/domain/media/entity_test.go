@@ -0,0 +1,46 @@
+package media
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderAttachments(t *testing.T) {
+	a := &Media{ID: "med_a", UserID: "user_1"}
+	b := &Media{ID: "med_b", UserID: "user_1"}
+
+	got, err := OrderAttachments([]string{"med_b", "med_a", "med_b"}, []*Media{a, b}, "user_1")
+
+	require.NoError(t, err)
+	assert.Equal(t, []*Media{b, a}, got)
+}
+
+func TestOrderAttachments_Rejected(t *testing.T) {
+	many := make([]*Media, MaxAttachmentsPerMessage+1)
+	manyIDs := make([]string, len(many))
+	for i := range many {
+		manyIDs[i] = fmt.Sprintf("med_%d", i)
+		many[i] = &Media{ID: manyIDs[i], UserID: "user_1"}
+	}
+
+	tests := []struct {
+		name    string
+		ids     []string
+		found   []*Media
+		wantErr error
+	}{
+		{"missing", []string{"med_a", "med_gone"}, []*Media{{ID: "med_a", UserID: "user_1"}}, ErrNotFound},
+		{"someone else's", []string{"med_a"}, []*Media{{ID: "med_a", UserID: "user_2"}}, ErrNoPermission},
+		{"too many", manyIDs, many, ErrTooManyAttachments},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := OrderAttachments(tt.ids, tt.found, "user_1")
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
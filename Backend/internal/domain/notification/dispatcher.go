@@ -0,0 +1,23 @@
+package notification
+
+import "context"
+
+// Dispatcher is the single entry point for raising notifications. It stores
+// the notification and hands it to every delivery channel.
+type Dispatcher interface {
+	// Dispatch persists a notification and delivers it to the recipient.
+	Dispatch(ctx context.Context, notification *Notification) error
+
+	// SyncUnreadCount pushes the user's current unread count, e.g. after
+	// notifications were marked as read on another device.
+	SyncUnreadCount(ctx context.Context, userID string) error
+}
+
+// Deliverer pushes stored notifications to users over one delivery channel.
+type Deliverer interface {
+	// Deliver sends a new notification along with the recipient's unread count.
+	Deliver(ctx context.Context, notification *Notification, unreadCount int) error
+
+	// DeliverUnreadCount sends an updated unread count on its own.
+	DeliverUnreadCount(ctx context.Context, userID string, unreadCount int) error
+}
@@ -0,0 +1,105 @@
+// Package presence defines user presence domain entities.
+package presence
+
+import (
+	"errors"
+	"time"
+)
+
+// Domain errors
+var (
+	ErrNotFound      = errors.New("presence not found")
+	ErrInvalidStatus = errors.New("invalid presence status")
+)
+
+// Status is the status a user chose to display.
+type Status string
+
+const (
+	StatusOnline    Status = "online"
+	StatusIdle      Status = "idle"
+	StatusDND       Status = "dnd"
+	StatusInvisible Status = "invisible"
+
+	// StatusOffline is never stored; it is shown for disconnected or invisible users.
+	StatusOffline Status = "offline"
+)
+
+// IsValid checks if the status can be chosen by a user.
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusOnline, StatusIdle, StatusDND, StatusInvisible:
+		return true
+	}
+	return false
+}
+
+// Maximum lengths for custom status and activity text
+const (
+	MaxCustomStatusLength = 128
+	MaxActivityLength     = 128
+)
+
+// CustomStatus is a short user-defined status message.
+type CustomStatus struct {
+	Text      string
+	Emoji     string
+	ExpiresAt *time.Time
+}
+
+// IsExpired checks if the custom status has expired.
+func (c *CustomStatus) IsExpired() bool {
+	return c.ExpiresAt != nil && c.ExpiresAt.Before(time.Now())
+}
+
+// ActivityType describes what the user is doing.
+type ActivityType string
+
+const (
+	ActivityPlaying   ActivityType = "playing"
+	ActivityListening ActivityType = "listening"
+	ActivityWatching  ActivityType = "watching"
+	ActivityStreaming ActivityType = "streaming"
+)
+
+// IsValid checks if the activity type is valid.
+func (t ActivityType) IsValid() bool {
+	switch t {
+	case ActivityPlaying, ActivityListening, ActivityWatching, ActivityStreaming:
+		return true
+	}
+	return false
+}
+
+// Activity is what the user is currently doing.
+type Activity struct {
+	Type    ActivityType
+	Name    string
+	Details string
+}
+
+// Presence is a user's chosen status, custom status and activity.
+type Presence struct {
+	UserID       string
+	Status       Status
+	CustomStatus *CustomStatus
+	Activity     *Activity
+	UpdatedAt    time.Time
+}
+
+// Default returns the presence of a user who never set one.
+func Default(userID string) *Presence {
+	return &Presence{
+		UserID:    userID,
+		Status:    StatusOnline,
+		UpdatedAt: time.Now(),
+	}
+}
+
+// VisibleStatus returns the status others see given whether the user is connected.
+func (p *Presence) VisibleStatus(connected bool) Status {
+	if !connected || p.Status == StatusInvisible {
+		return StatusOffline
+	}
+	return p.Status
+}
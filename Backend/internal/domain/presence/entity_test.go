@@ -0,0 +1,66 @@
+package presence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// =============================================================================
+// Status Tests
+// =============================================================================
+
+func TestStatus_IsValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   Status
+		expected bool
+	}{
+		{"online is valid", StatusOnline, true},
+		{"idle is valid", StatusIdle, true},
+		{"dnd is valid", StatusDND, true},
+		{"invisible is valid", StatusInvisible, true},
+		{"offline cannot be chosen", StatusOffline, false},
+		{"empty is invalid", Status(""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.status.IsValid())
+		})
+	}
+}
+
+// =============================================================================
+// Presence Tests
+// =============================================================================
+
+func TestPresence_VisibleStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    Status
+		connected bool
+		expected  Status
+	}{
+		{"connected dnd", StatusDND, true, StatusDND},
+		{"disconnected online", StatusOnline, false, StatusOffline},
+		{"connected invisible", StatusInvisible, true, StatusOffline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Presence{UserID: "user_1", Status: tt.status}
+			assert.Equal(t, tt.expected, p.VisibleStatus(tt.connected))
+		})
+	}
+}
+
+func TestCustomStatus_IsExpired(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Minute)
+
+	assert.False(t, (&CustomStatus{Text: "brb"}).IsExpired())
+	assert.True(t, (&CustomStatus{Text: "brb", ExpiresAt: &past}).IsExpired())
+	assert.False(t, (&CustomStatus{Text: "brb", ExpiresAt: &future}).IsExpired())
+}
@@ -0,0 +1,37 @@
+package presence
+
+import "context"
+
+// Repository defines the interface for presence data access.
+type Repository interface {
+	// FindByUserID finds a user's presence.
+	FindByUserID(ctx context.Context, userID string) (*Presence, error)
+
+	// FindByUserIDs finds presences for multiple users (batch).
+	// Users without a stored presence are omitted.
+	FindByUserIDs(ctx context.Context, userIDs []string) (map[string]*Presence, error)
+
+	// Upsert creates or updates a user's presence.
+	Upsert(ctx context.Context, presence *Presence) error
+}
+
+// AudienceRepository finds who may see whose presence.
+type AudienceRepository interface {
+	// FindRelated returns the users that follow userID, are followed by
+	// userID, or share a server with userID.
+	FindRelated(ctx context.Context, userID string) ([]string, error)
+
+	// FilterFriends returns the subset of candidateIDs that follow userID
+	// and are followed back.
+	FilterFriends(ctx context.Context, userID string, candidateIDs []string) ([]string, error)
+
+	// FilterVisibleTo returns the subset of targetIDs whose privacy settings
+	// let viewerID see their online status, in one query.
+	FilterVisibleTo(ctx context.Context, viewerID string, targetIDs []string) ([]Viewable, error)
+}
+
+// Viewable is a user whose online status a viewer may see.
+type Viewable struct {
+	UserID       string
+	ShowActivity bool // The user shares their activity
+}
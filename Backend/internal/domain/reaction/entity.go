@@ -0,0 +1,121 @@
+// Package reaction defines emoji reactions on channel and DM messages.
+package reaction
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Domain errors
+var (
+	ErrInvalidEmoji     = errors.New("invalid emoji")
+	ErrTooManyReactions = errors.New("message has too many different reactions")
+	ErrInvalidCursor    = errors.New("invalid reaction cursor")
+	ErrUnavailable      = errors.New("reactions are not available")
+)
+
+// MaxEmojisPerMessage is how many different emoji a message can collect.
+const MaxEmojisPerMessage = 20
+
+// MessageKind tells which kind of message a reaction is on.
+type MessageKind string
+
+const (
+	KindChannel MessageKind = "channel"
+	KindDM      MessageKind = "dm"
+)
+
+// Emoji is either a Unicode emoji, with only Name set, or a custom emoji
+// identified by ID with Name as its display name.
+type Emoji struct {
+	Name string
+	ID   string
+}
+
+var (
+	customEmojiName = regexp.MustCompile(`^[A-Za-z0-9_]{2,32}$`)
+	customEmojiID   = regexp.MustCompile(`^[A-Za-z0-9_]{1,26}$`)
+)
+
+// ParseEmoji parses an emoji as used in URLs: the emoji itself for Unicode
+// emoji, or "name:id" for custom emoji.
+func ParseEmoji(s string) (Emoji, error) {
+	if name, emojiID, ok := strings.Cut(s, ":"); ok {
+		if !customEmojiName.MatchString(name) || !customEmojiID.MatchString(emojiID) {
+			return Emoji{}, ErrInvalidEmoji
+		}
+		return Emoji{Name: name, ID: emojiID}, nil
+	}
+
+	if !isUnicodeEmoji(s) {
+		return Emoji{}, ErrInvalidEmoji
+	}
+	return Emoji{Name: s}, nil
+}
+
+// IsCustom reports whether this is a custom emoji.
+func (e Emoji) IsCustom() bool {
+	return e.ID != ""
+}
+
+// String formats the emoji the way ParseEmoji reads it.
+func (e Emoji) String() string {
+	if e.IsCustom() {
+		return e.Name + ":" + e.ID
+	}
+	return e.Name
+}
+
+// isUnicodeEmoji accepts a single emoji, including modifier, ZWJ, flag and
+// keycap sequences. It rejects text, whitespace and anything too long to be
+// one emoji.
+func isUnicodeEmoji(s string) bool {
+	if s == "" || len(s) > 64 || utf8.RuneCountInString(s) > 16 || !utf8.ValidString(s) {
+		return false
+	}
+
+	hasSymbol := false
+	for _, r := range s {
+		switch {
+		case unicode.IsLetter(r), unicode.IsSpace(r), unicode.IsControl(r):
+			return false
+		case unicode.Is(unicode.So, r):
+			hasSymbol = true
+		case r == 0x20E3:
+			// Combining keycap, as in 1️⃣
+			hasSymbol = true
+		}
+	}
+	return hasSymbol
+}
+
+// Reaction is a user's reaction to a message with one emoji.
+type Reaction struct {
+	MessageID string
+	Kind      MessageKind
+	UserID    string
+	Emoji     Emoji
+	CreatedAt time.Time
+
+	// Joined fields - populated when listing who reacted
+	User *User
+}
+
+// User is the public profile of someone who reacted.
+type User struct {
+	ID             string
+	Handle         string
+	DisplayName    string
+	AvatarGradient [2]string
+}
+
+// Summary is one emoji's reactions on a message, as shown under it.
+type Summary struct {
+	Emoji Emoji
+	Count int
+	Me    bool // The requesting user reacted with this emoji
+}
@@ -0,0 +1,50 @@
+package reaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEmoji(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Emoji
+	}{
+		{"👍", Emoji{Name: "👍"}},
+		{"❤️", Emoji{Name: "❤️"}},
+		{"👍🏽", Emoji{Name: "👍🏽"}},
+		{"👩‍💻", Emoji{Name: "👩‍💻"}},
+		{"🇹🇷", Emoji{Name: "🇹🇷"}},
+		{"1️⃣", Emoji{Name: "1️⃣"}},
+		{"party_blob:emo_01", Emoji{Name: "party_blob", ID: "emo_01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseEmoji(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.input, got.String())
+		})
+	}
+}
+
+func TestParseEmoji_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"hello",
+		"👍 👍",
+		"a👍",
+		"1",
+		"👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍👍",
+		"x:emo_01",
+		"party blob:emo_01",
+		"party_blob:",
+		":emo_01",
+	} {
+		_, err := ParseEmoji(input)
+		assert.ErrorIs(t, err, ErrInvalidEmoji, input)
+	}
+}
@@ -0,0 +1,23 @@
+package reaction
+
+import "context"
+
+// Repository defines the interface for message reaction data access.
+type Repository interface {
+	// Add adds a reaction, reporting whether the user had not already
+	// reacted with that emoji. It returns ErrTooManyReactions, without
+	// adding anything, when the emoji would take the message past
+	// MaxEmojisPerMessage different emoji.
+	Add(ctx context.Context, reaction *Reaction) (bool, error)
+
+	// Remove removes a user's reaction, reporting whether it existed.
+	Remove(ctx context.Context, messageID, userID string, emoji Emoji) (bool, error)
+
+	// FindUsers lists who reacted to a message with an emoji, in the order
+	// they reacted, with pagination.
+	FindUsers(ctx context.Context, messageID string, emoji Emoji, cursor string, limit int) ([]*Reaction, string, error)
+
+	// Summarize counts the reactions on each message per emoji, in the order
+	// each emoji was first used, flagging the ones userID reacted with.
+	Summarize(ctx context.Context, messageIDs []string, userID string) (map[string][]Summary, error)
+}
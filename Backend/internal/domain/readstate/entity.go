@@ -10,4 +10,5 @@ type ReadState struct {
 	ChannelID         string    `json:"channel_id"`
 	LastReadMessageID *string   `json:"last_read_message_id"`
 	LastReadAt        time.Time `json:"last_read_at"`
+	MentionCount      int       `json:"mention_count"` // Mentions since the channel was last acknowledged
 }
@@ -5,7 +5,15 @@ import (
 )
 
 type Repository interface {
+	// Upsert records the last read message, clearing the mention count.
 	Upsert(ctx context.Context, rs *ReadState) error
 	Get(ctx context.Context, userID, channelID string) (*ReadState, error)
 	GetByChannelID(ctx context.Context, channelID string) ([]*ReadState, error)
+
+	// GetByUserAndChannels gets a user's read states in the given channels.
+	// Channels the user never read or was mentioned in are omitted.
+	GetByUserAndChannels(ctx context.Context, userID string, channelIDs []string) ([]*ReadState, error)
+
+	// IncrementMentions adds one to each user's mention count in a channel.
+	IncrementMentions(ctx context.Context, channelID string, userIDs []string) error
 }
@@ -0,0 +1,41 @@
+// Package revision defines the edit history of channel and DM messages.
+package revision
+
+import (
+	"errors"
+	"time"
+)
+
+// Domain errors
+var (
+	ErrInvalidRetention = errors.New("invalid revision retention")
+)
+
+// MaxRetentionDays is the longest a server can choose to keep revisions.
+const MaxRetentionDays = 3650
+
+// MessageKind tells which kind of message a revision belongs to.
+type MessageKind string
+
+const (
+	KindChannel MessageKind = "channel"
+	KindDM      MessageKind = "dm"
+)
+
+// Revision is the content a message had before one of its edits.
+type Revision struct {
+	ID        string
+	MessageID string
+	Kind      MessageKind
+	Content   string    // Content before the edit
+	EditedAt  time.Time // When the edit replaced it
+}
+
+// ValidateRetentionDays checks a server's revision retention: 0 keeps
+// revisions as long as their message, otherwise at most MaxRetentionDays.
+func ValidateRetentionDays(days int) error {
+	if days < 0 || days > MaxRetentionDays {
+		return ErrInvalidRetention
+	}
+	return nil
+}
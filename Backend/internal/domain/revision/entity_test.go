@@ -0,0 +1,15 @@
+package revision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRetentionDays(t *testing.T) {
+	assert.NoError(t, ValidateRetentionDays(0))
+	assert.NoError(t, ValidateRetentionDays(30))
+	assert.NoError(t, ValidateRetentionDays(MaxRetentionDays))
+	assert.ErrorIs(t, ValidateRetentionDays(-1), ErrInvalidRetention)
+	assert.ErrorIs(t, ValidateRetentionDays(MaxRetentionDays+1), ErrInvalidRetention)
+}
@@ -0,0 +1,16 @@
+package revision
+
+import "context"
+
+// Repository defines the interface for message revision data access.
+type Repository interface {
+	// Create stores a revision.
+	Create(ctx context.Context, revision *Revision) error
+
+	// FindByMessageID finds a message's revisions, oldest first.
+	FindByMessageID(ctx context.Context, messageID string) ([]*Revision, error)
+
+	// DeleteExpired deletes the channel message revisions that have outlived
+	// their server's retention, returning how many were deleted.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
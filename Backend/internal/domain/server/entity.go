@@ -3,20 +3,23 @@ package server
 
 import (
 	"errors"
+	"reflect"
 	"time"
 )
 
 // Domain errors
 var (
-	ErrNotFound            = errors.New("server not found")
-	ErrAlreadyMember       = errors.New("already a member")
-	ErrNotMember           = errors.New("not a member")
-	ErrOwnerCannotLeave    = errors.New("owner cannot leave server")
-	ErrNoPermission        = errors.New("no permission")
-	ErrChannelNotFound     = errors.New("channel not found")
-	ErrInvalidServerName   = errors.New("invalid server name")
-	ErrRoleNotFound        = errors.New("role not found")
-	ErrJoinRequestNotFound = errors.New("join request not found")
+	ErrNotFound             = errors.New("server not found")
+	ErrAlreadyMember        = errors.New("already a member")
+	ErrNotMember            = errors.New("not a member")
+	ErrOwnerCannotLeave     = errors.New("owner cannot leave server")
+	ErrNoPermission         = errors.New("no permission")
+	ErrChannelNotFound      = errors.New("channel not found")
+	ErrInvalidServerName    = errors.New("invalid server name")
+	ErrRoleNotFound         = errors.New("role not found")
+	ErrJoinRequestNotFound  = errors.New("join request not found")
+	ErrInvalidAuditLogQuery = errors.New("invalid audit log query")
+	ErrRoleHierarchy        = errors.New("target is not below your highest role")
 )
 
 // ============================================================================
@@ -36,6 +39,7 @@ const (
 	PermissionKickMembers    Permission = 1 << 4 // Kick members from server
 	PermissionBanMembers     Permission = 1 << 5 // Ban members from server
 	PermissionInviteMembers  Permission = 1 << 6 // Create invites
+	PermissionViewAuditLog   Permission = 1 << 7 // Read the server's audit log
 
 	// Channel Permissions
 	PermissionViewChannel     Permission = 1 << 10 // View channel and read messages
@@ -44,6 +48,8 @@ const (
 	PermissionEmbedLinks      Permission = 1 << 13 // Post embedded links
 	PermissionAttachFiles     Permission = 1 << 14 // Upload files
 	PermissionMentionEveryone Permission = 1 << 15 // Use @everyone, @here
+	PermissionAddReactions    Permission = 1 << 16 // React to messages with emoji
+	PermissionBypassSlowmode  Permission = 1 << 17 // Send without waiting out slowmode
 
 	// Voice Permissions
 	PermissionConnect       Permission = 1 << 20 // Connect to voice channels
@@ -57,14 +63,14 @@ const (
 	PermissionStream Permission = 1 << 26 // Professional broadcasting (OBS/RTMP)
 
 	// Default permissions for @everyone role
-	PermissionDefaultEveryone = PermissionViewChannel | PermissionSendMessages | PermissionConnect | PermissionSpeak | PermissionVideo
+	PermissionDefaultEveryone = PermissionViewChannel | PermissionSendMessages | PermissionAttachFiles | PermissionAddReactions | PermissionConnect | PermissionSpeak | PermissionVideo
 
 	// All permissions
 	PermissionAll = PermissionAdministrator | PermissionManageServer | PermissionManageRoles |
 		PermissionManageChannels | PermissionKickMembers | PermissionBanMembers | PermissionInviteMembers |
-		PermissionViewChannel | PermissionSendMessages | PermissionManageMessages |
-		PermissionEmbedLinks | PermissionAttachFiles | PermissionMentionEveryone |
-		PermissionConnect | PermissionSpeak | PermissionVideo |
+		PermissionViewAuditLog | PermissionViewChannel | PermissionSendMessages | PermissionManageMessages |
+		PermissionEmbedLinks | PermissionAttachFiles | PermissionMentionEveryone | PermissionAddReactions |
+		PermissionBypassSlowmode | PermissionConnect | PermissionSpeak | PermissionVideo |
 		PermissionMuteMembers | PermissionDeafenMembers | PermissionMoveMembers |
 		PermissionStream
 )
@@ -88,6 +94,57 @@ func (p Permission) Remove(perm Permission) Permission {
 	return p &^ perm
 }
 
+// permissionNames holds the API name of every permission flag, in bit order.
+var permissionNames = []struct {
+	flag Permission
+	name string
+}{
+	{PermissionAdministrator, "ADMINISTRATOR"},
+	{PermissionManageServer, "MANAGE_SERVER"},
+	{PermissionManageRoles, "MANAGE_ROLES"},
+	{PermissionManageChannels, "MANAGE_CHANNELS"},
+	{PermissionKickMembers, "KICK_MEMBERS"},
+	{PermissionBanMembers, "BAN_MEMBERS"},
+	{PermissionInviteMembers, "INVITE_MEMBERS"},
+	{PermissionViewAuditLog, "VIEW_AUDIT_LOG"},
+	{PermissionViewChannel, "VIEW_CHANNEL"},
+	{PermissionSendMessages, "SEND_MESSAGES"},
+	{PermissionManageMessages, "MANAGE_MESSAGES"},
+	{PermissionEmbedLinks, "EMBED_LINKS"},
+	{PermissionAttachFiles, "ATTACH_FILES"},
+	{PermissionMentionEveryone, "MENTION_EVERYONE"},
+	{PermissionAddReactions, "ADD_REACTIONS"},
+	{PermissionBypassSlowmode, "BYPASS_SLOWMODE"},
+	{PermissionConnect, "CONNECT"},
+	{PermissionSpeak, "SPEAK"},
+	{PermissionVideo, "VIDEO"},
+	{PermissionMuteMembers, "MUTE_MEMBERS"},
+	{PermissionDeafenMembers, "DEAFEN_MEMBERS"},
+	{PermissionMoveMembers, "MOVE_MEMBERS"},
+	{PermissionStream, "STREAM"},
+}
+
+// Flags splits the set into its individual known flags, in bit order.
+func (p Permission) Flags() []Permission {
+	var flags []Permission
+	for _, pn := range permissionNames {
+		if p&pn.flag != 0 {
+			flags = append(flags, pn.flag)
+		}
+	}
+	return flags
+}
+
+// Name returns the API name of a single permission flag, or "" if unknown.
+func (p Permission) Name() string {
+	for _, pn := range permissionNames {
+		if pn.flag == p {
+			return pn.name
+		}
+	}
+	return ""
+}
+
 // ============================================================================
 // SERVER ENTITY
 // ============================================================================
@@ -105,6 +162,9 @@ type Server struct {
 	Tag          string // Server role tag (1-9 characters)
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+
+	// Days channel message revisions are kept; 0 = as long as the message
+	RevisionRetentionDays int
 }
 
 // ============================================================================
@@ -137,6 +197,8 @@ type Member struct {
 	Nickname                   string
 	JoinedAt                   time.Time
 	CommunicationDisabledUntil *time.Time // Nullable for timeout
+	InviteCode                 *string    // Invite the member joined with, nil otherwise
+	IsTemporary                bool       // Joined with a temporary invite and not given a role yet
 	Roles                      []Role     // Populated when needed
 }
 
@@ -162,6 +224,18 @@ func (m *Member) HasPermission(perm Permission) bool {
 	return m.GetPermissions().Has(perm)
 }
 
+// TopRolePosition returns the position of the member's highest role. Members
+// can only act on members and roles strictly below it.
+func (m *Member) TopRolePosition() int {
+	top := 0
+	for _, role := range m.Roles {
+		if role.Position > top {
+			top = role.Position
+		}
+	}
+	return top
+}
+
 // MemberRole is kept for backward compatibility but deprecated for new code.
 // Deprecated: Use Role entity instead.
 type MemberRole string
@@ -229,26 +303,61 @@ type Ban struct {
 type AuditLogAction string
 
 const (
-	AuditLogActionMemberKick    AuditLogAction = "MEMBER_KICK"
-	AuditLogActionMemberBan     AuditLogAction = "MEMBER_BAN"
-	AuditLogActionMemberUnban   AuditLogAction = "MEMBER_UNBAN"
-	AuditLogActionMemberTimeout AuditLogAction = "MEMBER_TIMEOUT"
-	AuditLogActionTimeoutRemove AuditLogAction = "TIMEOUT_REMOVE"
-	AuditLogActionChannelCreate AuditLogAction = "CHANNEL_CREATE"
-	AuditLogActionChannelUpdate AuditLogAction = "CHANNEL_UPDATE"
-	AuditLogActionChannelDelete AuditLogAction = "CHANNEL_DELETE"
-	AuditLogActionRoleCreate    AuditLogAction = "ROLE_CREATE"
-	AuditLogActionRoleUpdate    AuditLogAction = "ROLE_UPDATE"
-	AuditLogActionRoleDelete    AuditLogAction = "ROLE_DELETE"
-	AuditLogActionServerUpdate  AuditLogAction = "SERVER_UPDATE"
-	AuditLogActionInviteCreate  AuditLogAction = "INVITE_CREATE"
-	AuditLogActionInviteDelete  AuditLogAction = "INVITE_DELETE"
-	AuditLogActionMessageDelete AuditLogAction = "MESSAGE_DELETE"
-	AuditLogActionMessagePin    AuditLogAction = "MESSAGE_PIN"
-	AuditLogActionMessageUnpin  AuditLogAction = "MESSAGE_UNPIN"
-	AuditLogActionBotAdd        AuditLogAction = "BOT_ADD"
+	AuditLogActionServerCreate      AuditLogAction = "SERVER_CREATE"
+	AuditLogActionServerUpdate      AuditLogAction = "SERVER_UPDATE"
+	AuditLogActionMemberJoin        AuditLogAction = "MEMBER_JOIN"
+	AuditLogActionMemberLeave       AuditLogAction = "MEMBER_LEAVE"
+	AuditLogActionMemberKick        AuditLogAction = "MEMBER_KICK"
+	AuditLogActionMemberBan         AuditLogAction = "MEMBER_BAN"
+	AuditLogActionMemberUnban       AuditLogAction = "MEMBER_UNBAN"
+	AuditLogActionMemberTimeout     AuditLogAction = "MEMBER_TIMEOUT"
+	AuditLogActionTimeoutRemove     AuditLogAction = "TIMEOUT_REMOVE"
+	AuditLogActionMemberRoleUpdate  AuditLogAction = "MEMBER_ROLE_UPDATE"
+	AuditLogActionJoinRequestAccept AuditLogAction = "JOIN_REQUEST_ACCEPT"
+	AuditLogActionJoinRequestReject AuditLogAction = "JOIN_REQUEST_REJECT"
+	AuditLogActionChannelCreate     AuditLogAction = "CHANNEL_CREATE"
+	AuditLogActionChannelUpdate     AuditLogAction = "CHANNEL_UPDATE"
+	AuditLogActionChannelDelete     AuditLogAction = "CHANNEL_DELETE"
+	AuditLogActionChannelReorder    AuditLogAction = "CHANNEL_REORDER"
+	AuditLogActionOverwriteSet      AuditLogAction = "CHANNEL_OVERWRITE_SET"
+	AuditLogActionOverwriteDel      AuditLogAction = "CHANNEL_OVERWRITE_DELETE"
+	AuditLogActionRoleCreate        AuditLogAction = "ROLE_CREATE"
+	AuditLogActionRoleUpdate        AuditLogAction = "ROLE_UPDATE"
+	AuditLogActionRoleDelete        AuditLogAction = "ROLE_DELETE"
+	AuditLogActionRoleReorder       AuditLogAction = "ROLE_REORDER"
+	AuditLogActionInviteCreate      AuditLogAction = "INVITE_CREATE"
+	AuditLogActionInviteDelete      AuditLogAction = "INVITE_DELETE"
+	AuditLogActionMessageDelete     AuditLogAction = "MESSAGE_DELETE"
+	AuditLogActionMessageBulkDelete AuditLogAction = "MESSAGE_BULK_DELETE"
+	AuditLogActionMessagePin        AuditLogAction = "MESSAGE_PIN"
+	AuditLogActionMessageUnpin      AuditLogAction = "MESSAGE_UNPIN"
+	AuditLogActionFollowCreate      AuditLogAction = "CHANNEL_FOLLOW_CREATE"
+	AuditLogActionFollowDelete      AuditLogAction = "CHANNEL_FOLLOW_DELETE"
+	AuditLogActionBotAdd            AuditLogAction = "BOT_ADD"
 )
 
+// auditLogActions holds every known action, for validating query filters.
+var auditLogActions = map[AuditLogAction]bool{
+	AuditLogActionServerCreate: true, AuditLogActionServerUpdate: true,
+	AuditLogActionMemberJoin: true, AuditLogActionMemberLeave: true,
+	AuditLogActionMemberKick: true, AuditLogActionMemberBan: true, AuditLogActionMemberUnban: true,
+	AuditLogActionMemberTimeout: true, AuditLogActionTimeoutRemove: true, AuditLogActionMemberRoleUpdate: true,
+	AuditLogActionJoinRequestAccept: true, AuditLogActionJoinRequestReject: true,
+	AuditLogActionChannelCreate: true, AuditLogActionChannelUpdate: true, AuditLogActionChannelDelete: true,
+	AuditLogActionChannelReorder: true, AuditLogActionOverwriteSet: true, AuditLogActionOverwriteDel: true,
+	AuditLogActionRoleCreate: true, AuditLogActionRoleUpdate: true, AuditLogActionRoleDelete: true,
+	AuditLogActionRoleReorder: true, AuditLogActionInviteCreate: true, AuditLogActionInviteDelete: true,
+	AuditLogActionMessageDelete: true, AuditLogActionMessageBulkDelete: true,
+	AuditLogActionMessagePin: true, AuditLogActionMessageUnpin: true,
+	AuditLogActionFollowCreate: true, AuditLogActionFollowDelete: true,
+	AuditLogActionBotAdd: true,
+}
+
+// IsValid checks if the action is a known audit log action.
+func (a AuditLogAction) IsValid() bool {
+	return auditLogActions[a]
+}
+
 type AuditLog struct {
 	ID         string
 	ServerID   string
@@ -259,3 +368,42 @@ type AuditLog struct {
 	Reason     string
 	CreatedAt  time.Time
 }
+
+// AuditChanges holds what an audited action changed, keyed by field. Each
+// value is an {"old": ..., "new": ...} pair; creations have no old value and
+// deletions no new one.
+type AuditChanges map[string]interface{}
+
+// Set records a field's value before and after the action.
+func (c AuditChanges) Set(field string, old, new interface{}) AuditChanges {
+	c[field] = map[string]interface{}{"old": old, "new": new}
+	return c
+}
+
+// Diff records a field only if its value changed.
+func (c AuditChanges) Diff(field string, old, new interface{}) AuditChanges {
+	if !reflect.DeepEqual(old, new) {
+		c.Set(field, old, new)
+	}
+	return c
+}
+
+// AuditLogFilter narrows an audit log query. Zero values match everything.
+type AuditLogFilter struct {
+	ActorID    string
+	TargetID   string
+	ActionType AuditLogAction
+	After      *time.Time // Only entries created after this time
+	Before     *time.Time // Only entries created before this time
+}
+
+// Validate checks the action type is known and the time range is ordered.
+func (f AuditLogFilter) Validate() error {
+	if f.ActionType != "" && !f.ActionType.IsValid() {
+		return ErrInvalidAuditLogQuery
+	}
+	if f.After != nil && f.Before != nil && !f.After.Before(*f.Before) {
+		return ErrInvalidAuditLogQuery
+	}
+	return nil
+}
@@ -316,3 +316,95 @@ func TestMemberRole_CanDeleteServer(t *testing.T) {
 		})
 	}
 }
+
+func TestPermission_Flags(t *testing.T) {
+	perms := PermissionSendMessages | PermissionAdministrator | PermissionStream
+
+	assert.Equal(t, []Permission{PermissionAdministrator, PermissionSendMessages, PermissionStream}, perms.Flags())
+	assert.Empty(t, Permission(0).Flags())
+	assert.Len(t, PermissionAll.Flags(), 23)
+}
+
+func TestPermission_Name(t *testing.T) {
+	assert.Equal(t, "VIEW_CHANNEL", PermissionViewChannel.Name())
+	assert.Equal(t, "STREAM", PermissionStream.Name())
+	assert.Equal(t, "", (PermissionViewChannel | PermissionSpeak).Name())
+}
+
+// =============================================================================
+// Invite Tests
+// =============================================================================
+
+func TestInvite_IsUsable(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		invite Invite
+		want   bool
+	}{
+		{name: "unlimited", invite: Invite{}, want: true},
+		{name: "uses left", invite: Invite{MaxUses: 2, Uses: 1, ExpiresAt: &future}, want: true},
+		{name: "used up", invite: Invite{MaxUses: 2, Uses: 2}, want: false},
+		{name: "expired", invite: Invite{ExpiresAt: &past}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.invite.IsUsable())
+		})
+	}
+}
+
+// =============================================================================
+// Audit Log Tests
+// =============================================================================
+
+func TestAuditChanges_Diff(t *testing.T) {
+	changes := AuditChanges{}.
+		Diff("name", "old", "new").
+		Diff("color", "#fff", "#fff").
+		Diff("roles", []string{"role_1"}, []string{"role_1"}).
+		Set("banned", false, true)
+
+	assert.Equal(t, AuditChanges{
+		"name":   map[string]interface{}{"old": "old", "new": "new"},
+		"banned": map[string]interface{}{"old": false, "new": true},
+	}, changes)
+}
+
+func TestAuditLogFilter_Validate(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  AuditLogFilter
+		wantErr bool
+	}{
+		{name: "empty", filter: AuditLogFilter{}},
+		{name: "known action", filter: AuditLogFilter{ActionType: AuditLogActionMemberBan}},
+		{name: "unknown action", filter: AuditLogFilter{ActionType: "MEMBER_DANCE"}, wantErr: true},
+		{name: "ordered range", filter: AuditLogFilter{After: &earlier, Before: &now}},
+		{name: "reversed range", filter: AuditLogFilter{After: &now, Before: &earlier}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidAuditLogQuery)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMember_TopRolePosition(t *testing.T) {
+	assert.Equal(t, 0, (&Member{}).TopRolePosition())
+
+	member := &Member{Roles: []Role{{Position: 0, IsDefault: true}, {Position: 3}, {Position: 1}}}
+	assert.Equal(t, 3, member.TopRolePosition())
+}
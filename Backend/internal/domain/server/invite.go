@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Invite errors
+var (
+	ErrInviteNotFound = errors.New("invite not found")
+	ErrInviteExpired  = errors.New("invite expired or used up")
+	ErrInvalidInvite  = errors.New("invalid invite")
+	ErrBanned         = errors.New("banned from server")
+)
+
+// MaxInviteUses and MaxInviteAge bound what a single invite may allow.
+const (
+	MaxInviteUses = 100
+	MaxInviteAge  = 7 * 24 * time.Hour
+)
+
+// ============================================================================
+// INVITE ENTITY
+// ============================================================================
+
+// Invite is a shareable code that lets anyone holding it join a server,
+// including private servers that otherwise require a join request.
+type Invite struct {
+	Code      string
+	ServerID  string
+	ChannelID *string // Channel opened after joining, nil for the default
+	CreatorID string
+	MaxUses   int // 0 = unlimited
+	Uses      int
+	ExpiresAt *time.Time // nil = never expires
+	Temporary bool       // Members joining with it are removed when they go offline without a role
+	CreatedAt time.Time
+}
+
+// IsExpired reports whether the invite's lifetime has passed.
+func (i *Invite) IsExpired() bool {
+	return i.ExpiresAt != nil && !i.ExpiresAt.After(time.Now())
+}
+
+// IsExhausted reports whether the invite has reached its use limit.
+func (i *Invite) IsExhausted() bool {
+	return i.MaxUses > 0 && i.Uses >= i.MaxUses
+}
+
+// IsUsable reports whether the invite can still be redeemed.
+func (i *Invite) IsUsable() bool {
+	return !i.IsExpired() && !i.IsExhausted()
+}
+
+// InviteRepository defines the interface for server invites.
+type InviteRepository interface {
+	// Create stores a new invite.
+	Create(ctx context.Context, invite *Invite) error
+
+	// FindByCode finds an invite by its code.
+	FindByCode(ctx context.Context, code string) (*Invite, error)
+
+	// FindByServerID finds all invites of a server, newest first.
+	FindByServerID(ctx context.Context, serverID string) ([]*Invite, error)
+
+	// Delete removes an invite.
+	Delete(ctx context.Context, code string) error
+
+	// Use consumes one use of an invite. It returns ErrInviteExpired if the
+	// invite expired or ran out of uses, so concurrent redeems cannot
+	// exceed MaxUses.
+	Use(ctx context.Context, code string) error
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Notification settings errors
+var (
+	ErrInvalidNotificationSettings = errors.New("invalid notification settings")
+)
+
+// ============================================================================
+// NOTIFICATION SETTINGS ENTITY
+// ============================================================================
+
+// NotificationLevel is what a member wants to be notified about in a server.
+type NotificationLevel string
+
+const (
+	NotificationLevelAll      NotificationLevel = "all"
+	NotificationLevelMentions NotificationLevel = "mentions"
+	NotificationLevelNothing  NotificationLevel = "nothing"
+)
+
+// IsValid checks if the level is known.
+func (l NotificationLevel) IsValid() bool {
+	switch l {
+	case NotificationLevelAll, NotificationLevelMentions, NotificationLevelNothing:
+		return true
+	}
+	return false
+}
+
+// MentionKind tells how a message mentioned someone.
+type MentionKind int
+
+const (
+	MentionDirect   MentionKind = iota // By handle
+	MentionRole                        // Through one of their roles
+	MentionEveryone                    // Through @everyone or @here
+)
+
+// NotificationSettings are a member's notification settings for one server.
+type NotificationSettings struct {
+	ServerID         string
+	UserID           string
+	Level            NotificationLevel
+	SuppressEveryone bool       // Ignore @everyone and @here
+	SuppressRoles    bool       // Ignore role mentions
+	MutedUntil       *time.Time // Mentions still count but don't notify; nil = not muted
+	UpdatedAt        time.Time
+}
+
+// DefaultNotificationSettings returns the settings of a member who never
+// changed them.
+func DefaultNotificationSettings(serverID, userID string) *NotificationSettings {
+	return &NotificationSettings{
+		ServerID: serverID,
+		UserID:   userID,
+		Level:    NotificationLevelMentions,
+	}
+}
+
+// Validate checks the settings.
+func (s *NotificationSettings) Validate() error {
+	if !s.Level.IsValid() {
+		return ErrInvalidNotificationSettings
+	}
+	return nil
+}
+
+// IsMuted reports whether the server is muted.
+func (s *NotificationSettings) IsMuted() bool {
+	return s.MutedUntil != nil && s.MutedUntil.After(time.Now())
+}
+
+// CountsMention reports whether a mention of the given kind is a mention for
+// this member, adding to their mention count.
+func (s *NotificationSettings) CountsMention(kind MentionKind) bool {
+	if s.Level == NotificationLevelNothing {
+		return false
+	}
+	switch kind {
+	case MentionRole:
+		return !s.SuppressRoles
+	case MentionEveryone:
+		return !s.SuppressEveryone
+	}
+	return true
+}
+
+// NotificationSettingsRepository defines the interface for members'
+// notification settings.
+type NotificationSettingsRepository interface {
+	// Find finds a member's settings, returning nil if they never changed
+	// them.
+	Find(ctx context.Context, serverID, userID string) (*NotificationSettings, error)
+
+	// FindByUserIDs finds the settings of several members of a server.
+	// Members without stored settings are omitted.
+	FindByUserIDs(ctx context.Context, serverID string, userIDs []string) (map[string]*NotificationSettings, error)
+
+	// Upsert creates or updates a member's settings.
+	Upsert(ctx context.Context, settings *NotificationSettings) error
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationSettings_CountsMention(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings NotificationSettings
+		kind     MentionKind
+		expected bool
+	}{
+		{"default direct", NotificationSettings{Level: NotificationLevelMentions}, MentionDirect, true},
+		{"default everyone", NotificationSettings{Level: NotificationLevelMentions}, MentionEveryone, true},
+		{"suppressed everyone", NotificationSettings{Level: NotificationLevelAll, SuppressEveryone: true}, MentionEveryone, false},
+		{"suppressed roles", NotificationSettings{Level: NotificationLevelAll, SuppressRoles: true}, MentionRole, false},
+		{"suppressed roles still direct", NotificationSettings{Level: NotificationLevelAll, SuppressRoles: true}, MentionDirect, true},
+		{"nothing", NotificationSettings{Level: NotificationLevelNothing}, MentionDirect, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.settings.CountsMention(tt.kind))
+		})
+	}
+}
+
+func TestNotificationSettings_IsMuted(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	assert.False(t, (&NotificationSettings{}).IsMuted())
+	assert.False(t, (&NotificationSettings{MutedUntil: &past}).IsMuted())
+	assert.True(t, (&NotificationSettings{MutedUntil: &future}).IsMuted())
+}
+
+func TestNotificationSettings_Validate(t *testing.T) {
+	assert.NoError(t, DefaultNotificationSettings("serv_1", "user_1").Validate())
+	assert.ErrorIs(t, (&NotificationSettings{Level: "loud"}).Validate(), ErrInvalidNotificationSettings)
+}
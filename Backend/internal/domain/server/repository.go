@@ -1,6 +1,9 @@
 package server
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository defines the interface for server data access.
 type Repository interface {
@@ -28,6 +31,9 @@ type Repository interface {
 
 // MemberRepository defines the interface for server member data access.
 type MemberRepository interface {
+	// FindByID finds a member by its ID.
+	FindByID(ctx context.Context, id string) (*Member, error)
+
 	// FindByServerID finds all members of a server.
 	FindByServerID(ctx context.Context, serverID string) ([]*Member, error)
 
@@ -54,6 +60,9 @@ type MemberRepository interface {
 
 	// IsMember checks if a user is a member of a server.
 	IsMember(ctx context.Context, serverID, userID string) (bool, error)
+
+	// FindTemporaryByUserID finds a user's temporary memberships.
+	FindTemporaryByUserID(ctx context.Context, userID string) ([]*Member, error)
 }
 
 // RoleRepository defines the interface for server role data access.
@@ -73,6 +82,11 @@ type RoleRepository interface {
 	// Create creates a new role.
 	Create(ctx context.Context, role *Role) error
 
+	// CreateAt creates a new role at its position, moving the server's other
+	// non-default roles from that position on up by one in the same
+	// transaction.
+	CreateAt(ctx context.Context, role *Role) error
+
 	// Update updates an existing role.
 	Update(ctx context.Context, role *Role) error
 
@@ -103,5 +117,12 @@ type BanRepository interface {
 // AuditLogRepository defines the interface for audit logs.
 type AuditLogRepository interface {
 	Create(ctx context.Context, log *AuditLog) error
-	FindByServerID(ctx context.Context, serverID string, limit, offset int) ([]*AuditLog, error)
+
+	// FindByServerID returns a server's entries matching filter, newest
+	// first, and the cursor of the next page ("" on the last page).
+	FindByServerID(ctx context.Context, serverID string, filter AuditLogFilter, cursor string, limit int) ([]*AuditLog, string, error)
+
+	// DeleteOlderThan removes entries created before cutoff and returns how
+	// many were removed.
+	DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error)
 }
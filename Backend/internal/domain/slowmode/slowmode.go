@@ -0,0 +1,71 @@
+// Package slowmode defines per-member cooldowns between chat messages.
+package slowmode
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Domain errors
+var (
+	ErrInvalidInterval = errors.New("invalid slowmode interval")
+	ErrCooldown        = errors.New("slowmode cooldown active")
+)
+
+// MaxSeconds is the longest slowmode interval: six hours.
+const MaxSeconds = 6 * 60 * 60
+
+// ValidateSeconds checks a slowmode interval; 0 turns slowmode off.
+func ValidateSeconds(seconds int) error {
+	if seconds < 0 || seconds > MaxSeconds {
+		return ErrInvalidInterval
+	}
+	return nil
+}
+
+// CooldownError is returned when a member sends again before their cooldown
+// is over. It matches ErrCooldown.
+type CooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CooldownError) Error() string {
+	return ErrCooldown.Error()
+}
+
+func (e *CooldownError) Unwrap() error {
+	return ErrCooldown
+}
+
+// RetryAfterSeconds is RetryAfter rounded up to whole seconds.
+func (e *CooldownError) RetryAfterSeconds() int {
+	return int((e.RetryAfter + time.Second - 1) / time.Second)
+}
+
+// Limiter keeps track of members' cooldowns.
+type Limiter interface {
+	// Acquire starts userID's cooldown in a chat unless one is running, in
+	// which case it returns the time left.
+	Acquire(ctx context.Context, chatID, userID string, interval time.Duration) (time.Duration, error)
+
+	// Release ends userID's cooldown in a chat early, for a message that
+	// was held to it but never sent.
+	Release(ctx context.Context, chatID, userID string) error
+}
+
+// Enforce starts userID's cooldown in a chat with the given interval in
+// seconds, or returns a CooldownError if one is running. Slowmode is off
+// when seconds is 0. Limiter failures let the message through, like the
+// global rate limiter.
+func Enforce(ctx context.Context, limiter Limiter, chatID, userID string, seconds int) error {
+	if limiter == nil || seconds <= 0 {
+		return nil
+	}
+
+	retryAfter, err := limiter.Acquire(ctx, chatID, userID, time.Duration(seconds)*time.Second)
+	if err != nil || retryAfter <= 0 {
+		return nil
+	}
+	return &CooldownError{RetryAfter: retryAfter}
+}
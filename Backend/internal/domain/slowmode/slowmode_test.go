@@ -0,0 +1,46 @@
+package slowmode
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedLimiter reports the same time left for every cooldown.
+type fixedLimiter struct {
+	left time.Duration
+	err  error
+}
+
+func (l fixedLimiter) Acquire(context.Context, string, string, time.Duration) (time.Duration, error) {
+	return l.left, l.err
+}
+
+func (l fixedLimiter) Release(context.Context, string, string) error {
+	return nil
+}
+
+func TestValidateSeconds(t *testing.T) {
+	assert.NoError(t, ValidateSeconds(0))
+	assert.NoError(t, ValidateSeconds(MaxSeconds))
+	assert.ErrorIs(t, ValidateSeconds(-1), ErrInvalidInterval)
+	assert.ErrorIs(t, ValidateSeconds(MaxSeconds+1), ErrInvalidInterval)
+}
+
+func TestEnforce(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, Enforce(ctx, fixedLimiter{}, "chat_1", "user_1", 10))
+	assert.NoError(t, Enforce(ctx, fixedLimiter{left: time.Second}, "chat_1", "user_1", 0), "slowmode off")
+	assert.NoError(t, Enforce(ctx, fixedLimiter{err: errors.New("down")}, "chat_1", "user_1", 10), "fails open")
+
+	err := Enforce(ctx, fixedLimiter{left: 2100 * time.Millisecond}, "chat_1", "user_1", 10)
+	var cooldown *CooldownError
+	if assert.ErrorAs(t, err, &cooldown) {
+		assert.ErrorIs(t, err, ErrCooldown)
+		assert.Equal(t, 3, cooldown.RetryAfterSeconds())
+	}
+}
@@ -0,0 +1,31 @@
+package ws
+
+import (
+	"context"
+	"errors"
+)
+
+// Subscription authorization errors
+var (
+	ErrSubscriptionForbidden = errors.New("subscription forbidden")
+	ErrInvalidSubscription   = errors.New("invalid subscription")
+)
+
+// SubscriptionAuthorizer decides whether a user may receive the events of a
+// subscription. It returns nil when access is allowed.
+type SubscriptionAuthorizer interface {
+	Authorize(ctx context.Context, userID string, sub Subscription) error
+}
+
+// ErrorEventData is the payload of an EventError message.
+type ErrorEventData struct {
+	Code         string        `json:"code"`
+	Message      string        `json:"message"`
+	Subscription *Subscription `json:"subscription,omitempty"`
+	RetryAfter   int           `json:"retryAfter,omitempty"` // Seconds until the action may be retried
+}
+
+// SubscriptionRevokedEventData is the payload of an EventRevoked message.
+type SubscriptionRevokedEventData struct {
+	Subscription Subscription `json:"subscription"`
+}
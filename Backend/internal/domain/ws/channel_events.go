@@ -0,0 +1,18 @@
+package ws
+
+// ChannelEvent is a change inside a channel, such as a thread being created
+// or archived, delivered to "channel:<id>" subscribers. Like ServerEvent,
+// Payload is the changed domain object (e.g. *channel.Thread) and publishers
+// convert it to the DTO the HTTP API returns.
+type ChannelEvent struct {
+	Type      EventType
+	ServerID  string
+	ChannelID string
+	ActorID   string
+	Payload   interface{}
+}
+
+// ChannelEventPublisher delivers channel events to connected clients.
+type ChannelEventPublisher interface {
+	PublishChannelEvent(event ChannelEvent)
+}
@@ -3,6 +3,7 @@ package ws
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -11,19 +12,23 @@ type EventType string
 
 const (
 	// Connection events
-	EventConnected    EventType = "connected"
-	EventDisconnected EventType = "disconnected"
-	EventError        EventType = "error"
+	EventConnected      EventType = "connected"
+	EventResumed        EventType = "resumed"
+	EventInvalidSession EventType = "invalid_session"
+	EventDisconnected   EventType = "disconnected"
+	EventError          EventType = "error"
 
 	// Subscription events
 	EventSubscribe   EventType = "subscribe"
 	EventUnsubscribe EventType = "unsubscribe"
 	EventSubscribed  EventType = "subscribed"
+	EventRevoked     EventType = "subscription_revoked"
 
 	// Presence events
-	EventUserOnline   EventType = "user_online"
-	EventUserOffline  EventType = "user_offline"
-	EventPresenceSync EventType = "presence_sync"
+	EventUserOnline     EventType = "user_online"
+	EventUserOffline    EventType = "user_offline"
+	EventPresenceSync   EventType = "presence_sync"
+	EventPresenceUpdate EventType = "presence_update"
 
 	// Typing events
 	EventTypingStart EventType = "typing_start"
@@ -40,14 +45,49 @@ const (
 	EventChannelMessageEdited  EventType = "channel_message_edited"
 	EventChannelMessageDeleted EventType = "channel_message_deleted"
 
+	// Bulk delete events (sent to channel subscribers)
+	EventChannelMessagesBulkDeleted EventType = "channel_messages_bulk_deleted"
+
+	// Reaction events (sent to channel or conversation subscribers)
+	EventReactionAdd    EventType = "reaction_add"
+	EventReactionRemove EventType = "reaction_remove"
+
+	// Pin events (sent to channel or conversation subscribers)
+	EventPinsUpdate EventType = "pins_update"
+
+	// Thread events (sent to subscribers of the parent channel)
+	EventThreadCreate         EventType = "thread_create"
+	EventThreadUpdate         EventType = "thread_update"
+	EventThreadDelete         EventType = "thread_delete"
+	EventThreadMemberJoin     EventType = "thread_member_join"
+	EventThreadMemberLeave    EventType = "thread_member_leave"
+	EventThreadMessage        EventType = "thread_message"
+	EventThreadMessageEdited  EventType = "thread_message_edited"
+	EventThreadMessageDeleted EventType = "thread_message_deleted"
+
 	// Server events
-	EventServerJoin  EventType = "server_join"
-	EventServerLeave EventType = "server_leave"
-	EventMemberJoin  EventType = "member_join"
-	EventMemberLeave EventType = "member_leave"
+	EventServerJoin     EventType = "server_join"
+	EventServerLeave    EventType = "server_leave"
+	EventServerUpdate   EventType = "server_update"
+	EventServerDelete   EventType = "server_delete"
+	EventMemberJoin     EventType = "member_join"
+	EventMemberLeave    EventType = "member_leave"
+	EventMemberUpdate   EventType = "member_update"
+	EventMemberBan      EventType = "member_ban"
+	EventMemberUnban    EventType = "member_unban"
+	EventMemberTimeout  EventType = "member_timeout"
+	EventRoleCreate     EventType = "role_create"
+	EventRoleUpdate     EventType = "role_update"
+	EventRoleDelete     EventType = "role_delete"
+	EventRoleReorder    EventType = "role_reorder"
+	EventChannelCreate  EventType = "channel_create"
+	EventChannelUpdate  EventType = "channel_update"
+	EventChannelDelete  EventType = "channel_delete"
+	EventChannelReorder EventType = "channel_reorder"
 
 	// Notification events
-	EventNotification EventType = "notification"
+	EventNotification      EventType = "notification"
+	EventNotificationCount EventType = "notification_count"
 
 	// Call signaling events
 	EventCallIncoming EventType = "call_incoming"
@@ -64,7 +104,10 @@ const (
 )
 
 // Message represents a WebSocket message.
+// Seq is assigned per session when the message is delivered, so clients can
+// resume from the last sequence number they processed.
 type Message struct {
+	Seq       int64           `json:"seq,omitempty"`
 	Type      EventType       `json:"type"`
 	Data      json.RawMessage `json:"data,omitempty"`
 	Timestamp time.Time       `json:"timestamp"`
@@ -106,6 +149,20 @@ type Subscription struct {
 	ID   string           `json:"id"`
 }
 
+// Key returns the hub subscription key, e.g. "channel:<id>".
+func (s Subscription) Key() string {
+	return string(s.Type) + ":" + s.ID
+}
+
+// ParseSubscriptionKey parses a hub subscription key back into a Subscription.
+func ParseSubscriptionKey(key string) (Subscription, bool) {
+	subType, id, ok := strings.Cut(key, ":")
+	if !ok || id == "" {
+		return Subscription{}, false
+	}
+	return Subscription{Type: SubscriptionType(subType), ID: id}, true
+}
+
 // SubscribeRequest represents a subscription request.
 type SubscribeRequest struct {
 	Subscriptions []Subscription `json:"subscriptions"`
@@ -128,8 +185,37 @@ type TypingEventData struct {
 
 // PresenceEvent represents a presence change event.
 type PresenceEventData struct {
-	UserID   string `json:"userId"`
-	IsOnline bool   `json:"isOnline"`
+	UserID       string                `json:"userId"`
+	IsOnline     bool                  `json:"isOnline"`
+	Status       string                `json:"status"` // online, idle, dnd, invisible (self only), offline
+	CustomStatus *PresenceCustomStatus `json:"customStatus,omitempty"`
+	Activity     *PresenceActivity     `json:"activity,omitempty"`
+}
+
+// PresenceCustomStatus is a user-defined status message.
+type PresenceCustomStatus struct {
+	Text      string  `json:"text,omitempty"`
+	Emoji     string  `json:"emoji,omitempty"`
+	ExpiresAt *string `json:"expiresAt,omitempty"`
+}
+
+// PresenceActivity is what a user is currently doing.
+type PresenceActivity struct {
+	Type    string `json:"type"` // playing, listening, watching, streaming
+	Name    string `json:"name"`
+	Details string `json:"details,omitempty"`
+}
+
+// PresenceSyncEventData is the presence snapshot sent on connect.
+type PresenceSyncEventData struct {
+	Presences []PresenceEventData `json:"presences"`
+}
+
+// PresenceUpdateRequest is sent by a client to replace its own presence.
+type PresenceUpdateRequest struct {
+	Status       string                `json:"status"`
+	CustomStatus *PresenceCustomStatus `json:"customStatus,omitempty"`
+	Activity     *PresenceActivity     `json:"activity,omitempty"`
 }
 
 // DMMessageEvent represents a DM message event.
@@ -145,6 +231,44 @@ type ChannelMessageEventData struct {
 	Message   map[string]interface{} `json:"message"`
 }
 
+// ReactionEventData represents a reaction being added to or removed from a
+// channel or DM message.
+type ReactionEventData struct {
+	ServerID       string            `json:"serverId,omitempty"`
+	ChannelID      string            `json:"channelId,omitempty"`
+	ConversationID string            `json:"conversationId,omitempty"`
+	MessageID      string            `json:"messageId"`
+	UserID         string            `json:"userId"`
+	Emoji          ReactionEmojiData `json:"emoji"`
+}
+
+// ReactionEmojiData is the emoji of a reaction event. ID is only set for
+// custom emoji.
+type ReactionEmojiData struct {
+	Name string `json:"name"`
+	ID   string `json:"id,omitempty"`
+}
+
+// PinsUpdateEventData represents a message being pinned or unpinned in a
+// channel or DM conversation.
+type PinsUpdateEventData struct {
+	ServerID       string `json:"serverId,omitempty"`
+	ChannelID      string `json:"channelId,omitempty"`
+	ConversationID string `json:"conversationId,omitempty"`
+	MessageID      string `json:"messageId"`
+	Pinned         bool   `json:"pinned"`
+	ActorID        string `json:"actorId"`
+}
+
+// MessagesBulkDeletedEventData represents the messages one bulk delete
+// removed from a channel and its threads.
+type MessagesBulkDeletedEventData struct {
+	ServerID   string   `json:"serverId"`
+	ChannelID  string   `json:"channelId"`
+	MessageIDs []string `json:"messageIds"`
+	ActorID    string   `json:"actorId"`
+}
+
 // CallEventData represents a voice/video call signaling event.
 type CallEventData struct {
 	CallID       string `json:"callId"`
@@ -0,0 +1,78 @@
+package ws
+
+// Commands a client can send over the socket instead of an HTTP request.
+// Every command carries a requestId and is answered with an EventReply
+// carrying the same requestId.
+const (
+	CommandSendChannelMessage EventType = "send_channel_message"
+	CommandSendDM             EventType = "send_dm"
+	CommandEditMessage        EventType = "edit_message"
+	CommandDeleteMessage      EventType = "delete_message"
+	CommandAckChannel         EventType = "ack_channel"
+	CommandMarkDMRead         EventType = "mark_dm_read"
+
+	// EventReply answers a command.
+	EventReply EventType = "reply"
+)
+
+// ReplyEventData is the result of a command. Exactly one of Result and Error
+// is set.
+type ReplyEventData struct {
+	Command EventType   `json:"command"`
+	OK      bool        `json:"ok"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *ReplyError `json:"error,omitempty"`
+}
+
+// ReplyError is a failed command. Code and Status match the HTTP API.
+type ReplyError struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Status  int               `json:"status"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// SendChannelMessageCommand sends a message to a server channel.
+type SendChannelMessageCommand struct {
+	ServerID      string   `json:"serverId"`
+	ChannelID     string   `json:"channelId"`
+	Content       string   `json:"content"`
+	ReplyToID     *string  `json:"replyToId,omitempty"`
+	AttachmentIDs []string `json:"attachmentIds,omitempty"`
+}
+
+// SendDMCommand sends a direct message.
+type SendDMCommand struct {
+	ConversationID string   `json:"conversationId"`
+	Content        string   `json:"content"`
+	AttachmentIDs  []string `json:"attachmentIds,omitempty"`
+}
+
+// EditMessageCommand edits a channel message when ChannelID is set and a
+// direct message otherwise.
+type EditMessageCommand struct {
+	MessageID string `json:"messageId"`
+	Content   string `json:"content"`
+	ServerID  string `json:"serverId,omitempty"`
+	ChannelID string `json:"channelId,omitempty"`
+}
+
+// DeleteMessageCommand deletes a channel message when ChannelID is set and a
+// direct message otherwise.
+type DeleteMessageCommand struct {
+	MessageID      string `json:"messageId"`
+	ServerID       string `json:"serverId,omitempty"`
+	ChannelID      string `json:"channelId,omitempty"`
+	ConversationID string `json:"conversationId,omitempty"`
+}
+
+// AckChannelCommand marks a channel as read up to a message.
+type AckChannelCommand struct {
+	ChannelID string `json:"channelId"`
+	MessageID string `json:"messageId"`
+}
+
+// MarkDMReadCommand marks a conversation as read.
+type MarkDMReadCommand struct {
+	ConversationID string `json:"conversationId"`
+}
@@ -0,0 +1,22 @@
+package ws
+
+// ServerEvent is a change to a server's state, delivered to "server:<id>"
+// subscribers. Payload is the changed domain object (e.g. *server.Member or
+// *channel.Channel); publishers convert it to the DTO the HTTP API returns so
+// clients can update their caches in place.
+//
+// Events about something only part of the server may see, like a private
+// channel, set UserIDs; they then go to just those users instead of every
+// server subscriber.
+type ServerEvent struct {
+	Type     EventType
+	ServerID string
+	ActorID  string
+	Payload  interface{}
+	UserIDs  []string
+}
+
+// ServerEventPublisher delivers server events to connected clients.
+type ServerEventPublisher interface {
+	PublishServerEvent(event ServerEvent)
+}
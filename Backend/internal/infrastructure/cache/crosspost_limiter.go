@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseCrosspost uncounts a publish, unless its window already ended.
+var releaseCrosspost = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("DECR", KEYS[1])
+end
+return 0
+`)
+
+// CrosspostLimiter implements channel.CrosspostLimiter with a Redis counter
+// per channel that expires with its window, so limits hold across
+// instances.
+type CrosspostLimiter struct {
+	redis *redis.Client
+}
+
+// NewCrosspostLimiter creates a new CrosspostLimiter.
+func NewCrosspostLimiter(redis *redis.Client) *CrosspostLimiter {
+	return &CrosspostLimiter{redis: redis}
+}
+
+// Acquire counts a publish from channelID unless limit were already made in
+// the current window, returning the time until the window ends.
+func (l *CrosspostLimiter) Acquire(ctx context.Context, channelID string, limit int, window time.Duration) (time.Duration, error) {
+	key := fmt.Sprintf("crosspost:%s", channelID)
+
+	count, err := l.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("count crosspost: %w", err)
+	}
+	if count == 1 {
+		if err := l.redis.PExpire(ctx, key, window).Err(); err != nil {
+			return 0, fmt.Errorf("start crosspost window: %w", err)
+		}
+	}
+	if count <= int64(limit) {
+		return 0, nil
+	}
+
+	left, err := l.redis.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("read crosspost window: %w", err)
+	}
+	if left < 0 {
+		// The counter lost its expiry; start the window over
+		if err := l.redis.PExpire(ctx, key, window).Err(); err != nil {
+			return 0, fmt.Errorf("start crosspost window: %w", err)
+		}
+		return window, nil
+	}
+	return left, nil
+}
+
+// Release uncounts a publish from channelID in the current window.
+func (l *CrosspostLimiter) Release(ctx context.Context, channelID string) error {
+	key := fmt.Sprintf("crosspost:%s", channelID)
+
+	if err := releaseCrosspost.Run(ctx, l.redis, []string{key}).Err(); err != nil {
+		return fmt.Errorf("release crosspost: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SlowmodeLimiter implements slowmode.Limiter with Redis keys that expire
+// when a member's cooldown is over, so cooldowns hold across instances.
+type SlowmodeLimiter struct {
+	redis *redis.Client
+}
+
+// NewSlowmodeLimiter creates a new SlowmodeLimiter.
+func NewSlowmodeLimiter(redis *redis.Client) *SlowmodeLimiter {
+	return &SlowmodeLimiter{redis: redis}
+}
+
+// Acquire starts a cooldown unless one is running, returning the time left.
+func (l *SlowmodeLimiter) Acquire(ctx context.Context, chatID, userID string, interval time.Duration) (time.Duration, error) {
+	key := slowmodeKey(chatID, userID)
+
+	started, err := l.redis.SetNX(ctx, key, 1, interval).Result()
+	if err != nil {
+		return 0, fmt.Errorf("start slowmode cooldown: %w", err)
+	}
+	if started {
+		return 0, nil
+	}
+
+	left, err := l.redis.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("read slowmode cooldown: %w", err)
+	}
+	if left <= 0 {
+		// The cooldown ended between the two calls
+		if err := l.redis.Set(ctx, key, 1, interval).Err(); err != nil {
+			return 0, fmt.Errorf("start slowmode cooldown: %w", err)
+		}
+		return 0, nil
+	}
+	return left, nil
+}
+
+// Release ends a cooldown early.
+func (l *SlowmodeLimiter) Release(ctx context.Context, chatID, userID string) error {
+	if err := l.redis.Del(ctx, slowmodeKey(chatID, userID)).Err(); err != nil {
+		return fmt.Errorf("release slowmode cooldown: %w", err)
+	}
+	return nil
+}
+
+func slowmodeKey(chatID, userID string) string {
+	return fmt.Sprintf("slowmode:%s:%s", chatID, userID)
+}
@@ -2,12 +2,18 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"pink/internal/domain/server"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// auditCursorLayout formats the created_at half of an audit log cursor.
+const auditCursorLayout = "2006-01-02T15:04:05.000000Z"
+
 type AuditLogRepository struct {
 	db *pgxpool.Pool
 }
@@ -34,40 +40,116 @@ func (r *AuditLogRepository) Create(ctx context.Context, log *server.AuditLog) e
 	return err
 }
 
-func (r *AuditLogRepository) FindByServerID(ctx context.Context, serverID string, limit, offset int) ([]*server.AuditLog, error) {
+// FindByServerID returns a server's entries matching filter, newest first.
+// Pages are keyed on (created_at, id) so entries written while paging are
+// neither skipped nor repeated.
+func (r *AuditLogRepository) FindByServerID(ctx context.Context, serverID string, filter server.AuditLogFilter, cursor string, limit int) ([]*server.AuditLog, string, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	conditions := []string{"server_id = $1"}
+	args := []interface{}{serverID}
+	addCondition := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.ActorID != "" {
+		addCondition("actor_id = $%d", filter.ActorID)
+	}
+	if filter.TargetID != "" {
+		addCondition("target_id = $%d", filter.TargetID)
+	}
+	if filter.ActionType != "" {
+		addCondition("action_type = $%d", string(filter.ActionType))
+	}
+	if filter.After != nil {
+		addCondition("created_at > $%d", *filter.After)
+	}
+	if filter.Before != nil {
+		addCondition("created_at < $%d", *filter.Before)
+	}
+	if cursor != "" {
+		createdAt, id, err := parseAuditCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		args = append(args, createdAt, id)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	args = append(args, limit+1)
 	query := `
 		SELECT id, server_id, actor_id, target_id, action_type, changes, reason, created_at
 		FROM audit_logs
-		WHERE server_id = $1
-		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-	rows, err := r.db.Query(ctx, query, serverID, limit, offset)
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT $` + fmt.Sprint(len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", fmt.Errorf("query audit logs: %w", err)
 	}
 	defer rows.Close()
 
 	var logs []*server.AuditLog
 	for rows.Next() {
 		log := &server.AuditLog{}
+		var actorID, targetID, reason *string
 		var changes map[string]interface{}
 
 		err := rows.Scan(
 			&log.ID,
 			&log.ServerID,
-			&log.ActorID,
-			&log.TargetID,
+			&actorID,
+			&targetID,
 			&log.ActionType,
 			&changes,
-			&log.Reason,
+			&reason,
 			&log.CreatedAt,
 		)
 		if err != nil {
-			return nil, err
+			return nil, "", fmt.Errorf("scan audit log: %w", err)
 		}
+		log.ActorID = derefString(actorID)
+		log.TargetID = derefString(targetID)
+		log.Reason = derefString(reason)
 		log.Changes = changes
 		logs = append(logs, log)
 	}
-	return logs, nil
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("iterate audit logs: %w", err)
+	}
+
+	var nextCursor string
+	if len(logs) > limit {
+		last := logs[limit-1]
+		nextCursor = last.CreatedAt.UTC().Format(auditCursorLayout) + "|" + last.ID
+		logs = logs[:limit]
+	}
+
+	return logs, nextCursor, nil
+}
+
+// DeleteOlderThan removes entries created before cutoff.
+func (r *AuditLogRepository) DeleteOlderThan(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM audit_logs WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("delete old audit logs: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// parseAuditCursor splits a "<created_at>|<id>" cursor.
+func parseAuditCursor(cursor string) (time.Time, string, error) {
+	ts, id, ok := strings.Cut(cursor, "|")
+	if !ok || id == "" {
+		return time.Time{}, "", server.ErrInvalidAuditLogQuery
+	}
+	createdAt, err := time.Parse(auditCursorLayout, ts)
+	if err != nil {
+		return time.Time{}, "", server.ErrInvalidAuditLogQuery
+	}
+	return createdAt, id, nil
 }
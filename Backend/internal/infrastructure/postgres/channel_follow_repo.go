@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/channel"
+)
+
+// ChannelFollowRepository implements channel.FollowRepository using
+// PostgreSQL.
+type ChannelFollowRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewChannelFollowRepository creates a new ChannelFollowRepository.
+func NewChannelFollowRepository(pool *pgxpool.Pool) *ChannelFollowRepository {
+	return &ChannelFollowRepository{pool: pool}
+}
+
+// channelFollowSelect selects follows with the names of both channels and
+// servers.
+const channelFollowSelect = `
+	SELECT f.id, f.source_server_id, f.source_channel_id, f.target_server_id, f.target_channel_id, f.created_by, f.created_at,
+	       ss.name, sc.name, ts.name, tc.name
+	FROM channel_follows f
+	JOIN servers ss ON f.source_server_id = ss.id
+	JOIN channels sc ON f.source_channel_id = sc.id
+	JOIN servers ts ON f.target_server_id = ts.id
+	JOIN channels tc ON f.target_channel_id = tc.id`
+
+func scanChannelFollow(row pgx.Row) (*channel.ChannelFollow, error) {
+	var f channel.ChannelFollow
+	err := row.Scan(
+		&f.ID, &f.SourceServerID, &f.SourceChannelID, &f.TargetServerID, &f.TargetChannelID, &f.CreatedBy, &f.CreatedAt,
+		&f.SourceServerName, &f.SourceChannelName, &f.TargetServerName, &f.TargetChannelName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *ChannelFollowRepository) query(ctx context.Context, where string, args ...interface{}) ([]*channel.ChannelFollow, error) {
+	rows, err := r.pool.Query(ctx, channelFollowSelect+` WHERE `+where+` ORDER BY f.created_at, f.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query channel follows: %w", err)
+	}
+	defer rows.Close()
+
+	var follows []*channel.ChannelFollow
+	for rows.Next() {
+		f, err := scanChannelFollow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan channel follow: %w", err)
+		}
+		follows = append(follows, f)
+	}
+	return follows, rows.Err()
+}
+
+// FindByID finds a follow by its ID, with channel and server names.
+func (r *ChannelFollowRepository) FindByID(ctx context.Context, id string) (*channel.ChannelFollow, error) {
+	f, err := scanChannelFollow(r.pool.QueryRow(ctx, channelFollowSelect+` WHERE f.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, channel.ErrFollowNotFound
+		}
+		return nil, fmt.Errorf("query channel follow by id: %w", err)
+	}
+	return f, nil
+}
+
+// FindBySource finds the follows of an announcement channel, oldest first.
+func (r *ChannelFollowRepository) FindBySource(ctx context.Context, channelID string) ([]*channel.ChannelFollow, error) {
+	return r.query(ctx, `f.source_channel_id = $1`, channelID)
+}
+
+// FindByTarget finds the announcement channels a channel follows, oldest
+// first.
+func (r *ChannelFollowRepository) FindByTarget(ctx context.Context, channelID string) ([]*channel.ChannelFollow, error) {
+	return r.query(ctx, `f.target_channel_id = $1`, channelID)
+}
+
+// CountByTarget counts the announcement channels a channel follows.
+func (r *ChannelFollowRepository) CountByTarget(ctx context.Context, channelID string) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM channel_follows WHERE target_channel_id = $1`, channelID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count channel follows: %w", err)
+	}
+	return count, nil
+}
+
+// Create creates a follow.
+func (r *ChannelFollowRepository) Create(ctx context.Context, f *channel.ChannelFollow) error {
+	query := `
+		INSERT INTO channel_follows (id, source_server_id, source_channel_id, target_server_id, target_channel_id, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		f.ID, f.SourceServerID, f.SourceChannelID, f.TargetServerID, f.TargetChannelID, f.CreatedBy, f.CreatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return channel.ErrAlreadyFollowing
+		}
+		return fmt.Errorf("insert channel follow: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes a follow.
+func (r *ChannelFollowRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM channel_follows WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete channel follow: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return channel.ErrFollowNotFound
+	}
+	return nil
+}
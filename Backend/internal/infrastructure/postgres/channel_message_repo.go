@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"pink/internal/domain/channel"
+	"pink/internal/domain/history"
 )
 
 // ChannelMessageRepository implements channel.MessageRepository using PostgreSQL.
@@ -21,124 +24,245 @@ func NewChannelMessageRepository(pool *pgxpool.Pool) *ChannelMessageRepository {
 	return &ChannelMessageRepository{pool: pool}
 }
 
-// FindByID finds a message by its ID.
-func (r *ChannelMessageRepository) FindByID(ctx context.Context, id string) (*channel.ChannelMessage, error) {
-	query := `
-		SELECT m.id, m.channel_id, m.server_id, m.author_id, m.content, m.is_edited, m.is_pinned, m.reply_to_id, m.created_at, m.updated_at,
-		       u.id, u.handle, u.display_name, u.avatar_gradient
+// channelMessageColumns and channelMessageFrom select messages with their
+// authors; scan the rows with scanChannelMessage.
+const (
+	channelMessageColumns = `
+		m.id, m.channel_id, m.server_id, m.author_id, m.content, m.is_edited, m.is_pinned, m.pinned_at, m.pinned_by,
+		m.reply_to_id, m.thread_id, m.mention_user_ids, m.mention_role_ids, m.mention_everyone, m.mention_here,
+		m.created_at, m.updated_at, m.crossposted_at,
+		m.source_message_id, m.source_channel_id, m.source_server_id, m.source_channel_name, m.source_server_name,
+		u.id, u.handle, u.display_name, u.avatar_gradient`
+	channelMessageFrom = `
 		FROM channel_messages m
-		JOIN users u ON m.author_id = u.id
-		WHERE m.id = $1
-	`
+		JOIN users u ON m.author_id = u.id`
+)
+
+// channelMessageSelect selects messages with their authors.
+const channelMessageSelect = `SELECT` + channelMessageColumns + channelMessageFrom
 
+func scanChannelMessage(row pgx.Row) (*channel.ChannelMessage, error) {
 	var msg channel.ChannelMessage
 	var author channel.MessageAuthor
 	var gradient []string
+	var sourceMessageID, sourceChannelID, sourceServerID, sourceChannelName, sourceServerName *string
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&msg.ID, &msg.ChannelID, &msg.ServerID, &msg.AuthorID, &msg.Content, &msg.IsEdited, &msg.IsPinned, &msg.ReplyToID, &msg.CreatedAt, &msg.UpdatedAt,
+	err := row.Scan(
+		&msg.ID, &msg.ChannelID, &msg.ServerID, &msg.AuthorID, &msg.Content, &msg.IsEdited, &msg.IsPinned, &msg.PinnedAt, &msg.PinnedBy,
+		&msg.ReplyToID, &msg.ThreadID, &msg.Mentions.UserIDs, &msg.Mentions.RoleIDs, &msg.Mentions.Everyone, &msg.Mentions.Here,
+		&msg.CreatedAt, &msg.UpdatedAt, &msg.CrosspostedAt,
+		&sourceMessageID, &sourceChannelID, &sourceServerID, &sourceChannelName, &sourceServerName,
 		&author.ID, &author.Handle, &author.DisplayName, &gradient,
 	)
-
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, channel.ErrMessageNotFound
+		return nil, err
+	}
+
+	if sourceMessageID != nil {
+		msg.Source = &channel.CrosspostSource{
+			MessageID:   *sourceMessageID,
+			ChannelID:   derefString(sourceChannelID),
+			ServerID:    derefString(sourceServerID),
+			ChannelName: derefString(sourceChannelName),
+			ServerName:  derefString(sourceServerName),
 		}
-		return nil, fmt.Errorf("query message by id: %w", err)
 	}
 
 	if len(gradient) >= 2 {
 		author.AvatarGradient = [2]string{gradient[0], gradient[1]}
 	}
 	msg.Author = &author
-
 	return &msg, nil
 }
 
-// FindByChannelID finds messages in a channel with pagination.
-func (r *ChannelMessageRepository) FindByChannelID(ctx context.Context, channelID, cursor string, limit int) ([]*channel.ChannelMessage, string, error) {
-	if limit <= 0 || limit > 50 {
-		limit = 20
+func scanChannelMessages(rows pgx.Rows) ([]*channel.ChannelMessage, error) {
+	defer rows.Close()
+
+	var messages []*channel.ChannelMessage
+	for rows.Next() {
+		msg, err := scanChannelMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+		messages = append(messages, msg)
 	}
+	return messages, rows.Err()
+}
 
-	var args []interface{}
-	args = append(args, channelID, limit+1)
+// FindByID finds a message by its ID.
+func (r *ChannelMessageRepository) FindByID(ctx context.Context, id string) (*channel.ChannelMessage, error) {
+	query := channelMessageSelect + ` WHERE m.id = $1`
 
-	query := `
-		SELECT m.id, m.channel_id, m.server_id, m.author_id, m.content, m.is_edited, m.is_pinned, m.reply_to_id, m.created_at, m.updated_at,
-		       u.id, u.handle, u.display_name, u.avatar_gradient
-		FROM channel_messages m
-		JOIN users u ON m.author_id = u.id
-		WHERE m.channel_id = $1
-	`
+	msg, err := scanChannelMessage(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, channel.ErrMessageNotFound
+		}
+		return nil, fmt.Errorf("query message by id: %w", err)
+	}
 
-	if cursor != "" {
-		query += ` AND m.created_at < $3`
-		args = append(args, cursor)
+	if err := r.loadAttachments(ctx, []*channel.ChannelMessage{msg}); err != nil {
+		return nil, err
 	}
 
-	query += ` ORDER BY m.created_at DESC LIMIT $2`
+	return msg, nil
+}
 
-	rows, err := r.pool.Query(ctx, query, args...)
+// FindByChannelID finds the page of a channel's messages a cursor selects,
+// newest first. Thread messages are not included.
+func (r *ChannelMessageRepository) FindByChannelID(ctx context.Context, channelID string, cursor history.Cursor) ([]*channel.ChannelMessage, history.Page, error) {
+	return r.findPage(ctx, `m.channel_id = $1 AND m.thread_id IS NULL`, channelID, cursor)
+}
+
+// FindByThreadID finds the page of a thread's messages a cursor selects,
+// newest first.
+func (r *ChannelMessageRepository) FindByThreadID(ctx context.Context, threadID string, cursor history.Cursor) ([]*channel.ChannelMessage, history.Page, error) {
+	return r.findPage(ctx, `m.thread_id = $1`, threadID, cursor)
+}
+
+// findPage reads the page of the messages matching where that a cursor
+// selects.
+func (r *ChannelMessageRepository) findPage(ctx context.Context, where, id string, cursor history.Cursor) ([]*channel.ChannelMessage, history.Page, error) {
+	messages, page, err := readHistory(ctx, r.pool, channelMessageSelect, "channel_messages", where, id, cursor, scanChannelMessages,
+		func(msg *channel.ChannelMessage) string { return msg.ID })
 	if err != nil {
-		return nil, "", fmt.Errorf("query messages: %w", err)
+		return nil, history.Page{}, err
 	}
-	defer rows.Close()
+	if err := r.loadAttachments(ctx, messages); err != nil {
+		return nil, history.Page{}, err
+	}
+	return messages, page, nil
+}
 
-	var messages []*channel.ChannelMessage
-	for rows.Next() {
-		var msg channel.ChannelMessage
-		var author channel.MessageAuthor
-		var gradient []string
-
-		err := rows.Scan(
-			&msg.ID, &msg.ChannelID, &msg.ServerID, &msg.AuthorID, &msg.Content, &msg.IsEdited, &msg.IsPinned, &msg.ReplyToID, &msg.CreatedAt, &msg.UpdatedAt,
-			&author.ID, &author.Handle, &author.DisplayName, &gradient,
-		)
+// FindPinned finds a channel's pinned messages, most recently pinned first.
+// Thread messages are not included.
+func (r *ChannelMessageRepository) FindPinned(ctx context.Context, channelID string) ([]*channel.ChannelMessage, error) {
+	query := channelMessageSelect + `
+		WHERE m.channel_id = $1 AND m.thread_id IS NULL AND m.is_pinned
+		ORDER BY m.pinned_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("query pinned messages: %w", err)
+	}
+	return r.scanWithAttachments(ctx, rows)
+}
+
+// UpdatePin saves a message's pin state. Pinning returns
+// channel.ErrTooManyPins, without saving anything, when the channel already
+// has channel.MaxPinsPerChannel pinned messages.
+func (r *ChannelMessageRepository) UpdatePin(ctx context.Context, msg *channel.ChannelMessage) error {
+	if !msg.IsPinned {
+		query := `UPDATE channel_messages SET is_pinned = FALSE, pinned_at = NULL, pinned_by = NULL WHERE id = $1`
+
+		result, err := r.pool.Exec(ctx, query, msg.ID)
 		if err != nil {
-			return nil, "", fmt.Errorf("scan message: %w", err)
+			return fmt.Errorf("update message pin: %w", err)
 		}
-
-		if len(gradient) >= 2 {
-			author.AvatarGradient = [2]string{gradient[0], gradient[1]}
+		if result.RowsAffected() == 0 {
+			return channel.ErrMessageNotFound
 		}
-		msg.Author = &author
+		return nil
+	}
 
-		messages = append(messages, &msg)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Serialize pins in the same channel so two cannot both take the last
+	// free slot
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('channel_pins:' || $1))`, msg.ChannelID); err != nil {
+		return fmt.Errorf("lock channel pins: %w", err)
 	}
 
-	var nextCursor string
-	if len(messages) > limit {
-		nextCursor = messages[limit-1].CreatedAt.Format("2006-01-02T15:04:05.000000Z")
-		messages = messages[:limit]
+	query := `
+		UPDATE channel_messages SET is_pinned = TRUE, pinned_at = $2, pinned_by = $3
+		WHERE id = $1 AND (
+			SELECT COUNT(*) FROM channel_messages
+			WHERE channel_id = $4 AND thread_id IS NULL AND is_pinned AND id <> $1
+		) < $5
+	`
+
+	result, err := tx.Exec(ctx, query, msg.ID, msg.PinnedAt, msg.PinnedBy, msg.ChannelID, channel.MaxPinsPerChannel)
+	if err != nil {
+		return fmt.Errorf("update message pin: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM channel_messages WHERE id = $1)`, msg.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("check message: %w", err)
+		}
+		if !exists {
+			return channel.ErrMessageNotFound
+		}
+		return channel.ErrTooManyPins
 	}
 
-	return messages, nextCursor, nil
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
 }
 
-// Create creates a new message.
+// Create creates a new message together with its attachments.
 func (r *ChannelMessageRepository) Create(ctx context.Context, msg *channel.ChannelMessage) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
 	query := `
-		INSERT INTO channel_messages (id, channel_id, server_id, author_id, content, is_edited, is_pinned, reply_to_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO channel_messages (id, channel_id, server_id, author_id, content, is_edited, is_pinned, reply_to_id, thread_id,
+		                              mention_user_ids, mention_role_ids, mention_everyone, mention_here, created_at, updated_at,
+		                              source_message_id, source_channel_id, source_server_id, source_channel_name, source_server_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 	`
 
-	_, err := r.pool.Exec(ctx, query,
-		msg.ID, msg.ChannelID, msg.ServerID, msg.AuthorID, msg.Content, msg.IsEdited, msg.IsPinned, msg.ReplyToID, msg.CreatedAt, msg.UpdatedAt,
+	var sourceMessageID, sourceChannelID, sourceServerID, sourceChannelName, sourceServerName *string
+	if source := msg.Source; source != nil {
+		sourceMessageID, sourceChannelID, sourceServerID = &source.MessageID, &source.ChannelID, &source.ServerID
+		sourceChannelName, sourceServerName = &source.ChannelName, &source.ServerName
+	}
+
+	_, err = tx.Exec(ctx, query,
+		msg.ID, msg.ChannelID, msg.ServerID, msg.AuthorID, msg.Content, msg.IsEdited, msg.IsPinned, msg.ReplyToID, msg.ThreadID,
+		nonNilStrings(msg.Mentions.UserIDs), nonNilStrings(msg.Mentions.RoleIDs), msg.Mentions.Everyone, msg.Mentions.Here, msg.CreatedAt, msg.UpdatedAt,
+		sourceMessageID, sourceChannelID, sourceServerID, sourceChannelName, sourceServerName,
 	)
 
 	if err != nil {
 		return fmt.Errorf("insert message: %w", err)
 	}
 
-	return nil
+	// Copies share the source message's media, so they keep it once the
+	// source is deleted
+	if err := insertAttachments(ctx, tx, "channel_message_id", msg.ID, msg.Attachments, msg.Source != nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 // Update updates a message.
 func (r *ChannelMessageRepository) Update(ctx context.Context, msg *channel.ChannelMessage) error {
-	query := `UPDATE channel_messages SET content = $2, is_edited = TRUE, updated_at = NOW() WHERE id = $1`
+	query := `
+		UPDATE channel_messages
+		SET content = $2, mention_user_ids = $3, mention_role_ids = $4, mention_everyone = $5, mention_here = $6,
+		    is_edited = TRUE, updated_at = NOW()
+		WHERE id = $1
+	`
 
-	result, err := r.pool.Exec(ctx, query, msg.ID, msg.Content)
+	result, err := r.pool.Exec(ctx, query, msg.ID, msg.Content,
+		nonNilStrings(msg.Mentions.UserIDs), nonNilStrings(msg.Mentions.RoleIDs), msg.Mentions.Everyone, msg.Mentions.Here,
+	)
 	if err != nil {
 		return fmt.Errorf("update message: %w", err)
 	}
@@ -166,18 +290,102 @@ func (r *ChannelMessageRepository) Delete(ctx context.Context, id string) error
 	return nil
 }
 
+// DeleteMatching deletes the messages a bulk delete filter selects and
+// returns them with their attachments. The messages are found, their
+// attachments loaded and all of them deleted in one query each.
+func (r *ChannelMessageRepository) DeleteMatching(ctx context.Context, f channel.BulkDeleteFilter) ([]*channel.ChannelMessage, error) {
+	args := []interface{}{f.ChannelID}
+	where := []string{`m.channel_id = $1`}
+	addArg := func(cond string, value interface{}) {
+		args = append(args, value)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if len(f.MessageIDs) > 0 {
+		addArg(`m.id = ANY($%d)`, f.MessageIDs)
+	}
+	if f.AuthorID != "" {
+		addArg(`m.author_id = $%d`, f.AuthorID)
+	}
+	if f.After != nil {
+		addArg(`m.created_at > $%d`, *f.After)
+	}
+	if f.Before != nil {
+		addArg(`m.created_at < $%d`, *f.Before)
+	}
+	if f.HasLinks {
+		where = append(where, `m.content ~* 'https?://'`)
+	}
+	if f.HasAttachments {
+		where = append(where, `EXISTS (SELECT 1 FROM message_attachments a WHERE a.channel_message_id = m.id)`)
+	}
+	args = append(args, f.Limit)
+
+	query := channelMessageSelect + ` WHERE ` + strings.Join(where, ` AND `) +
+		fmt.Sprintf(` ORDER BY m.created_at DESC LIMIT $%d`, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query messages to delete: %w", err)
+	}
+	matched, err := r.scanWithAttachments(ctx, rows)
+	if err != nil || len(matched) == 0 {
+		return nil, err
+	}
+
+	ids := make([]string, len(matched))
+	for i, msg := range matched {
+		ids[i] = msg.ID
+	}
+
+	rows, err = r.pool.Query(ctx, `DELETE FROM channel_messages WHERE id = ANY($1) RETURNING id`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("bulk delete messages: %w", err)
+	}
+	defer rows.Close()
+
+	deleted := make(map[string]bool, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan deleted message: %w", err)
+		}
+		deleted[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("bulk delete messages: %w", err)
+	}
+
+	// Messages deleted in the meantime are not reported twice
+	messages := matched[:0]
+	for _, msg := range matched {
+		if deleted[msg.ID] {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+// MarkCrossposted sets CrosspostedAt on a message that has not been
+// published yet, reporting whether it did.
+func (r *ChannelMessageRepository) MarkCrossposted(ctx context.Context, id string, at time.Time) (bool, error) {
+	query := `UPDATE channel_messages SET crossposted_at = $2 WHERE id = $1 AND crossposted_at IS NULL`
+
+	tag, err := r.pool.Exec(ctx, query, id, at)
+	if err != nil {
+		return false, fmt.Errorf("mark message crossposted: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
 // Search searches messages in a channel.
 func (r *ChannelMessageRepository) Search(ctx context.Context, channelID, query string, limit int) ([]*channel.ChannelMessage, error) {
 	if limit <= 0 || limit > 50 {
 		limit = 20
 	}
 
-	sqlQuery := `
-		SELECT m.id, m.channel_id, m.server_id, m.author_id, m.content, m.is_edited, m.is_pinned, m.reply_to_id, m.created_at, m.updated_at,
-		       u.id, u.handle, u.display_name, u.avatar_gradient
-		FROM channel_messages m
-		JOIN users u ON m.author_id = u.id
-		WHERE m.channel_id = $1 AND to_tsvector('english', m.content) @@ plainto_tsquery('english', $2)
+	sqlQuery := channelMessageSelect + `
+		WHERE m.channel_id = $1 AND m.search_vector @@ plainto_tsquery('english', $2)
 		ORDER BY m.created_at DESC
 		LIMIT $3
 	`
@@ -186,29 +394,154 @@ func (r *ChannelMessageRepository) Search(ctx context.Context, channelID, query
 	if err != nil {
 		return nil, fmt.Errorf("search messages: %w", err)
 	}
+	return r.scanWithAttachments(ctx, rows)
+}
+
+// SearchServer finds a page of the messages in the searched channels that
+// match every part of the query, best matches first when it has words and
+// newest first otherwise. Returns the total number of matches.
+func (r *ChannelMessageRepository) SearchServer(ctx context.Context, search channel.MessageSearch) ([]*channel.MessageSearchHit, int, error) {
+	if len(search.ChannelIDs) == 0 {
+		return nil, 0, nil
+	}
+	q := search.Query
+
+	args := []interface{}{search.ServerID, search.ChannelIDs}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	from := channelMessageFrom
+	snippet := escapeHTML(`left(m.content, 200)`)
+	orderBy := `m.created_at DESC, m.id DESC`
+	where := []string{`m.server_id = $1`, `m.channel_id = ANY($2)`}
+
+	if q.Text != "" {
+		from += ` CROSS JOIN websearch_to_tsquery('english', ` + arg(q.Text) + `) q`
+		where = append(where, `m.search_vector @@ q`)
+		// Highlights are added to escaped content so that they are its only
+		// markup
+		snippet = `ts_headline('english', ` + escapeHTML(`m.content`) + `, q, ` +
+			arg("StartSel="+channel.SearchHighlightStart+", StopSel="+channel.SearchHighlightEnd+", MaxFragments=2, MaxWords=30, MinWords=10") + `)`
+		orderBy = `ts_rank(m.search_vector, q) DESC, ` + orderBy
+	}
+	if len(q.From) > 0 {
+		where = append(where, `LOWER(u.handle) = ANY(`+arg(q.From)+`)`)
+	}
+	if len(q.Mentions) > 0 {
+		where = append(where, `m.mention_user_ids && ARRAY(SELECT id FROM users WHERE LOWER(handle) = ANY(`+arg(q.Mentions)+`))`)
+	}
+	for _, has := range q.Has {
+		switch has {
+		case channel.SearchHasLink:
+			where = append(where, `m.content ~* 'https?://'`)
+		case channel.SearchHasFile:
+			where = append(where, `EXISTS (SELECT 1 FROM message_attachments a WHERE a.channel_message_id = m.id)`)
+		case channel.SearchHasImage:
+			where = append(where, `EXISTS (
+				SELECT 1 FROM message_attachments a JOIN media md ON md.id = a.media_id
+				WHERE a.channel_message_id = m.id AND md.type = 'image'
+			)`)
+		}
+	}
+	if q.Before != nil {
+		where = append(where, `m.created_at < `+arg(*q.Before))
+	}
+	if q.After != nil {
+		where = append(where, `m.created_at >= `+arg(*q.After))
+	}
+	if q.Pinned != nil {
+		where = append(where, `m.is_pinned = `+arg(*q.Pinned))
+	}
+
+	query := `SELECT` + channelMessageColumns + `, ` + snippet + `, COUNT(*) OVER ()` + from + `
+		WHERE ` + strings.Join(where, ` AND `) + `
+		ORDER BY ` + orderBy + `
+		LIMIT ` + arg(search.Limit) + ` OFFSET ` + arg(search.Offset)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search server messages: %w", err)
+	}
 	defer rows.Close()
 
+	var hits []*channel.MessageSearchHit
 	var messages []*channel.ChannelMessage
+	total := 0
 	for rows.Next() {
-		var msg channel.ChannelMessage
-		var author channel.MessageAuthor
-		var gradient []string
-
-		err := rows.Scan(
-			&msg.ID, &msg.ChannelID, &msg.ServerID, &msg.AuthorID, &msg.Content, &msg.IsEdited, &msg.IsPinned, &msg.ReplyToID, &msg.CreatedAt, &msg.UpdatedAt,
-			&author.ID, &author.Handle, &author.DisplayName, &gradient,
-		)
+		var hit channel.MessageSearchHit
+		msg, err := scanChannelMessage(withExtraColumns(rows, &hit.Snippet, &total))
 		if err != nil {
-			return nil, fmt.Errorf("scan message: %w", err)
-		}
-
-		if len(gradient) >= 2 {
-			author.AvatarGradient = [2]string{gradient[0], gradient[1]}
+			return nil, 0, fmt.Errorf("scan search hit: %w", err)
 		}
-		msg.Author = &author
+		hit.Message = msg
+		hits = append(hits, &hit)
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("search server messages: %w", err)
+	}
 
-		messages = append(messages, &msg)
+	if err := r.loadAttachments(ctx, messages); err != nil {
+		return nil, 0, err
 	}
+	return hits, total, nil
+}
+
+// escapeHTML wraps a text expression in SQL that escapes it for HTML.
+func escapeHTML(expr string) string {
+	return `replace(replace(replace(` + expr + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')`
+}
 
+// extraColumnsRow scans the columns selected after a message's into extra.
+type extraColumnsRow struct {
+	pgx.Row
+	extra []interface{}
+}
+
+func withExtraColumns(row pgx.Row, extra ...interface{}) pgx.Row {
+	return extraColumnsRow{Row: row, extra: extra}
+}
+
+func (r extraColumnsRow) Scan(dest ...interface{}) error {
+	return r.Row.Scan(append(dest, r.extra...)...)
+}
+
+// scanWithAttachments scans a result set of messages and loads their
+// attachments.
+func (r *ChannelMessageRepository) scanWithAttachments(ctx context.Context, rows pgx.Rows) ([]*channel.ChannelMessage, error) {
+	messages, err := scanChannelMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadAttachments(ctx, messages); err != nil {
+		return nil, err
+	}
 	return messages, nil
 }
+
+// loadAttachments fills in the attachments of the given messages.
+func (r *ChannelMessageRepository) loadAttachments(ctx context.Context, messages []*channel.ChannelMessage) error {
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
+
+	attachments, err := findAttachments(ctx, r.pool, ids)
+	if err != nil {
+		return err
+	}
+	for _, msg := range messages {
+		msg.Attachments = attachments[msg.ID]
+	}
+	return nil
+}
+
+// nonNilStrings stores a nil slice as an empty array rather than NULL.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -30,7 +30,7 @@ func NewChannelRepository(pool *pgxpool.Pool) *ChannelRepository {
 func (r *ChannelRepository) FindByID(ctx context.Context, id string) (*channel.Channel, error) {
 	query := `
 		SELECT id, server_id, parent_id, name, description, type, position, is_private, 
-		       user_limit, bitrate, livekit_room, created_at, updated_at
+		       user_limit, bitrate, livekit_room, slowmode_seconds, created_at, updated_at
 		FROM channels
 		WHERE id = $1
 	`
@@ -50,6 +50,7 @@ func (r *ChannelRepository) FindByID(ctx context.Context, id string) (*channel.C
 		&ch.UserLimit,
 		&ch.Bitrate,
 		&livekitRoom,
+		&ch.SlowmodeSeconds,
 		&ch.CreatedAt,
 		&ch.UpdatedAt,
 	)
@@ -82,7 +83,7 @@ func (r *ChannelRepository) FindByID(ctx context.Context, id string) (*channel.C
 func (r *ChannelRepository) FindByServerID(ctx context.Context, serverID string) ([]*channel.Channel, error) {
 	query := `
 		SELECT id, server_id, parent_id, name, description, type, position, is_private,
-		       user_limit, bitrate, livekit_room, created_at, updated_at
+		       user_limit, bitrate, livekit_room, slowmode_seconds, created_at, updated_at
 		FROM channels
 		WHERE server_id = $1
 		ORDER BY position, created_at
@@ -111,6 +112,7 @@ func (r *ChannelRepository) FindByServerID(ctx context.Context, serverID string)
 			&ch.UserLimit,
 			&ch.Bitrate,
 			&livekitRoom,
+			&ch.SlowmodeSeconds,
 			&ch.CreatedAt,
 			&ch.UpdatedAt,
 		)
@@ -184,8 +186,8 @@ func (r *ChannelRepository) Create(ctx context.Context, ch *channel.Channel) err
 	query := `
 		INSERT INTO channels (
 			id, server_id, parent_id, name, description, type, position, is_private,
-			user_limit, bitrate, livekit_room, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			user_limit, bitrate, livekit_room, slowmode_seconds, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	var desc, livekitRoom *string
@@ -208,6 +210,7 @@ func (r *ChannelRepository) Create(ctx context.Context, ch *channel.Channel) err
 		ch.UserLimit,
 		ch.Bitrate,
 		livekitRoom,
+		ch.SlowmodeSeconds,
 		ch.CreatedAt,
 		ch.UpdatedAt,
 	)
@@ -232,6 +235,7 @@ func (r *ChannelRepository) Update(ctx context.Context, ch *channel.Channel) err
 			user_limit = $8,
 			bitrate = $9,
 			livekit_room = $10,
+			slowmode_seconds = $11,
 			updated_at = NOW()
 		WHERE id = $1
 	`
@@ -255,6 +259,7 @@ func (r *ChannelRepository) Update(ctx context.Context, ch *channel.Channel) err
 		ch.UserLimit,
 		ch.Bitrate,
 		livekitRoom,
+		ch.SlowmodeSeconds,
 	)
 
 	if err != nil {
@@ -310,7 +315,7 @@ func (r *ChannelRepository) ReorderChannels(ctx context.Context, serverID string
 func (r *ChannelRepository) FindVoiceEnabled(ctx context.Context, serverID string) ([]*channel.Channel, error) {
 	query := `
 		SELECT id, server_id, parent_id, name, description, type, position, is_private,
-		       user_limit, bitrate, livekit_room, created_at, updated_at
+		       user_limit, bitrate, livekit_room, slowmode_seconds, created_at, updated_at
 		FROM channels
 		WHERE server_id = $1 AND type IN ('voice', 'video', 'stage', 'hybrid')
 		ORDER BY position, created_at
@@ -339,6 +344,7 @@ func (r *ChannelRepository) FindVoiceEnabled(ctx context.Context, serverID strin
 			&ch.UserLimit,
 			&ch.Bitrate,
 			&livekitRoom,
+			&ch.SlowmodeSeconds,
 			&ch.CreatedAt,
 			&ch.UpdatedAt,
 		)
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"pink/internal/domain/dm"
+	"pink/internal/domain/history"
 )
 
 // DMMessageRepository implements dm.MessageRepository using PostgreSQL.
@@ -21,25 +22,55 @@ func NewDMMessageRepository(pool *pgxpool.Pool) *DMMessageRepository {
 	return &DMMessageRepository{pool: pool}
 }
 
-// FindByID finds a message by its ID.
-func (r *DMMessageRepository) FindByID(ctx context.Context, id string) (*dm.Message, error) {
-	query := `
-		SELECT m.id, m.conversation_id, m.sender_id, m.content, m.is_edited, m.created_at, m.updated_at,
+// dmMessageSelect selects messages with their senders; scan the rows with
+// scanDMMessage.
+const dmMessageSelect = `
+		SELECT m.id, m.conversation_id, m.sender_id, m.content, m.is_edited, m.is_pinned, m.pinned_at, m.pinned_by,
+		       m.created_at, m.updated_at,
 		       u.id, u.handle, u.display_name, u.avatar_gradient
 		FROM dm_messages m
-		JOIN users u ON m.sender_id = u.id
-		WHERE m.id = $1
-	`
+		JOIN users u ON m.sender_id = u.id`
 
+func scanDMMessage(row pgx.Row) (*dm.Message, error) {
 	var msg dm.Message
 	var sender dm.ConversationUser
 	var gradient []string
 
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.Content, &msg.IsEdited, &msg.CreatedAt, &msg.UpdatedAt,
+	err := row.Scan(
+		&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.Content, &msg.IsEdited, &msg.IsPinned, &msg.PinnedAt, &msg.PinnedBy,
+		&msg.CreatedAt, &msg.UpdatedAt,
 		&sender.ID, &sender.Handle, &sender.DisplayName, &gradient,
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(gradient) >= 2 {
+		sender.AvatarGradient = [2]string{gradient[0], gradient[1]}
+	}
+	msg.Sender = &sender
+	return &msg, nil
+}
 
+func scanDMMessages(rows pgx.Rows) ([]*dm.Message, error) {
+	defer rows.Close()
+
+	var messages []*dm.Message
+	for rows.Next() {
+		msg, err := scanDMMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// FindByID finds a message by its ID.
+func (r *DMMessageRepository) FindByID(ctx context.Context, id string) (*dm.Message, error) {
+	query := dmMessageSelect + ` WHERE m.id = $1`
+
+	msg, err := scanDMMessage(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, dm.ErrMessageNotFound
@@ -47,83 +78,143 @@ func (r *DMMessageRepository) FindByID(ctx context.Context, id string) (*dm.Mess
 		return nil, fmt.Errorf("query message by id: %w", err)
 	}
 
-	if len(gradient) >= 2 {
-		sender.AvatarGradient = [2]string{gradient[0], gradient[1]}
+	if err := r.loadAttachments(ctx, []*dm.Message{msg}); err != nil {
+		return nil, err
 	}
-	msg.Sender = &sender
 
-	return &msg, nil
+	return msg, nil
 }
 
-// FindByConversationID finds messages in a conversation with pagination.
-func (r *DMMessageRepository) FindByConversationID(ctx context.Context, convID, cursor string, limit int) ([]*dm.Message, string, error) {
-	if limit <= 0 || limit > 50 {
-		limit = 20
+// FindByConversationID finds the page of a conversation's messages a cursor
+// selects, newest first.
+func (r *DMMessageRepository) FindByConversationID(ctx context.Context, convID string, cursor history.Cursor) ([]*dm.Message, history.Page, error) {
+	messages, page, err := readHistory(ctx, r.pool, dmMessageSelect, "dm_messages", `m.conversation_id = $1`, convID, cursor, scanDMMessages,
+		func(msg *dm.Message) string { return msg.ID })
+	if err != nil {
+		return nil, history.Page{}, err
 	}
+	if err := r.loadAttachments(ctx, messages); err != nil {
+		return nil, history.Page{}, err
+	}
+	return messages, page, nil
+}
 
-	var args []interface{}
-	args = append(args, convID, limit+1)
-
-	query := `
-		SELECT m.id, m.conversation_id, m.sender_id, m.content, m.is_edited, m.created_at, m.updated_at,
-		       u.id, u.handle, u.display_name, u.avatar_gradient
-		FROM dm_messages m
-		JOIN users u ON m.sender_id = u.id
-		WHERE m.conversation_id = $1
+// FindPinned finds a conversation's pinned messages, most recently pinned
+// first.
+func (r *DMMessageRepository) FindPinned(ctx context.Context, convID string) ([]*dm.Message, error) {
+	query := dmMessageSelect + `
+		WHERE m.conversation_id = $1 AND m.is_pinned
+		ORDER BY m.pinned_at DESC
 	`
 
-	if cursor != "" {
-		query += ` AND m.created_at < $3`
-		args = append(args, cursor)
+	rows, err := r.pool.Query(ctx, query, convID)
+	if err != nil {
+		return nil, fmt.Errorf("query pinned messages: %w", err)
 	}
 
-	query += ` ORDER BY m.created_at DESC LIMIT $2`
+	messages, err := scanDMMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadAttachments(ctx, messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// loadAttachments fills in the attachments of the given messages.
+func (r *DMMessageRepository) loadAttachments(ctx context.Context, messages []*dm.Message) error {
+	ids := make([]string, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.ID
+	}
 
-	rows, err := r.pool.Query(ctx, query, args...)
+	attachments, err := findAttachments(ctx, r.pool, ids)
 	if err != nil {
-		return nil, "", fmt.Errorf("query messages: %w", err)
+		return err
 	}
-	defer rows.Close()
+	for _, msg := range messages {
+		msg.Attachments = attachments[msg.ID]
+	}
+	return nil
+}
 
-	var messages []*dm.Message
-	for rows.Next() {
-		var msg dm.Message
-		var sender dm.ConversationUser
-		var gradient []string
-
-		err := rows.Scan(
-			&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.Content, &msg.IsEdited, &msg.CreatedAt, &msg.UpdatedAt,
-			&sender.ID, &sender.Handle, &sender.DisplayName, &gradient,
-		)
+// UpdatePin saves a message's pin state. Pinning returns dm.ErrTooManyPins,
+// without saving anything, when the conversation already has
+// dm.MaxPinsPerConversation pinned messages.
+func (r *DMMessageRepository) UpdatePin(ctx context.Context, msg *dm.Message) error {
+	if !msg.IsPinned {
+		query := `UPDATE dm_messages SET is_pinned = FALSE, pinned_at = NULL, pinned_by = NULL WHERE id = $1`
+
+		result, err := r.pool.Exec(ctx, query, msg.ID)
 		if err != nil {
-			return nil, "", fmt.Errorf("scan message: %w", err)
+			return fmt.Errorf("update message pin: %w", err)
 		}
-
-		if len(gradient) >= 2 {
-			sender.AvatarGradient = [2]string{gradient[0], gradient[1]}
+		if result.RowsAffected() == 0 {
+			return dm.ErrMessageNotFound
 		}
-		msg.Sender = &sender
+		return nil
+	}
 
-		messages = append(messages, &msg)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Serialize pins in the same conversation so two cannot both take the
+	// last free slot
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('dm_pins:' || $1))`, msg.ConversationID); err != nil {
+		return fmt.Errorf("lock conversation pins: %w", err)
 	}
 
-	var nextCursor string
-	if len(messages) > limit {
-		nextCursor = messages[limit-1].CreatedAt.Format("2006-01-02T15:04:05.000000Z")
-		messages = messages[:limit]
+	query := `
+		UPDATE dm_messages SET is_pinned = TRUE, pinned_at = $2, pinned_by = $3
+		WHERE id = $1 AND (
+			SELECT COUNT(*) FROM dm_messages
+			WHERE conversation_id = $4 AND is_pinned AND id <> $1
+		) < $5
+	`
+
+	result, err := tx.Exec(ctx, query, msg.ID, msg.PinnedAt, msg.PinnedBy, msg.ConversationID, dm.MaxPinsPerConversation)
+	if err != nil {
+		return fmt.Errorf("update message pin: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		var exists bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM dm_messages WHERE id = $1)`, msg.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("check message: %w", err)
+		}
+		if !exists {
+			return dm.ErrMessageNotFound
+		}
+		return dm.ErrTooManyPins
 	}
 
-	return messages, nextCursor, nil
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
 }
 
-// Create creates a new message.
+// Create creates a new message together with its attachments.
 func (r *DMMessageRepository) Create(ctx context.Context, msg *dm.Message) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
 	query := `
 		INSERT INTO dm_messages (id, conversation_id, sender_id, content, is_edited, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	_, err := r.pool.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		msg.ID, msg.ConversationID, msg.SenderID, msg.Content, msg.IsEdited, msg.CreatedAt, msg.UpdatedAt,
 	)
 
@@ -131,7 +222,11 @@ func (r *DMMessageRepository) Create(ctx context.Context, msg *dm.Message) error
 		return fmt.Errorf("insert message: %w", err)
 	}
 
-	return nil
+	if err := insertAttachments(ctx, tx, "dm_message_id", msg.ID, msg.Attachments, false); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 // Update updates a message.
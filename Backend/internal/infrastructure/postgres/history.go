@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/history"
+)
+
+// readHistory reads the page of a message history a cursor selects, newest
+// first. selectSQL selects messages from table aliased as m, where narrows
+// them to the history with its ID as $1, and scan reads the rows. Messages
+// are ordered by creation time, then ID.
+func readHistory[T any](
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	selectSQL, table, where, historyID string,
+	cursor history.Cursor,
+	scan func(pgx.Rows) ([]T, error),
+	id func(T) string,
+) ([]T, history.Page, error) {
+	olderSpan, newerSpan := cursor.Span()
+	anchor := cursor.Anchor()
+
+	read := func(cmp, order string, limit int) ([]T, error) {
+		args := []interface{}{historyID, limit + 1}
+		query := selectSQL + ` WHERE ` + where
+		if anchor != "" {
+			query += ` AND (m.created_at, m.id) ` + cmp + ` (SELECT created_at, id FROM ` + table + ` WHERE id = $3)`
+			args = append(args, anchor)
+		}
+		query += ` ORDER BY m.created_at ` + order + `, m.id ` + order + ` LIMIT $2`
+
+		rows, err := pool.Query(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("query messages: %w", err)
+		}
+		return scan(rows)
+	}
+
+	var older, newer []T
+	var err error
+	if cursor.After == "" {
+		if older, err = read("<", "DESC", olderSpan); err != nil {
+			return nil, history.Page{}, err
+		}
+	}
+	if cursor.After != "" || cursor.Around != "" {
+		cmp := ">"
+		if cursor.Around != "" {
+			cmp = ">="
+		}
+		if newer, err = read(cmp, "ASC", newerSpan); err != nil {
+			return nil, history.Page{}, err
+		}
+	}
+
+	messages, page := history.Window(cursor, older, newer, id)
+	return messages, page, nil
+}
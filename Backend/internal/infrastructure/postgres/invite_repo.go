@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/server"
+)
+
+// InviteRepository implements server.InviteRepository using PostgreSQL.
+type InviteRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewInviteRepository creates a new InviteRepository.
+func NewInviteRepository(pool *pgxpool.Pool) *InviteRepository {
+	return &InviteRepository{pool: pool}
+}
+
+const inviteColumns = `code, server_id, channel_id, creator_id, max_uses, uses, expires_at, temporary, created_at`
+
+func scanInvite(row pgx.Row) (*server.Invite, error) {
+	var inv server.Invite
+	var creatorID *string
+	err := row.Scan(
+		&inv.Code, &inv.ServerID, &inv.ChannelID, &creatorID,
+		&inv.MaxUses, &inv.Uses, &inv.ExpiresAt, &inv.Temporary, &inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inv.CreatorID = derefString(creatorID)
+	return &inv, nil
+}
+
+// Create stores a new invite.
+func (r *InviteRepository) Create(ctx context.Context, inv *server.Invite) error {
+	query := `
+		INSERT INTO server_invites (` + inviteColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		inv.Code, inv.ServerID, inv.ChannelID, inv.CreatorID,
+		inv.MaxUses, inv.Uses, inv.ExpiresAt, inv.Temporary, inv.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert invite: %w", err)
+	}
+	return nil
+}
+
+// FindByCode finds an invite by its code.
+func (r *InviteRepository) FindByCode(ctx context.Context, code string) (*server.Invite, error) {
+	query := `SELECT ` + inviteColumns + ` FROM server_invites WHERE code = $1`
+
+	inv, err := scanInvite(r.pool.QueryRow(ctx, query, code))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.ErrInviteNotFound
+		}
+		return nil, fmt.Errorf("query invite: %w", err)
+	}
+	return inv, nil
+}
+
+// FindByServerID finds all invites of a server, newest first.
+func (r *InviteRepository) FindByServerID(ctx context.Context, serverID string) ([]*server.Invite, error) {
+	query := `
+		SELECT ` + inviteColumns + `
+		FROM server_invites
+		WHERE server_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, serverID)
+	if err != nil {
+		return nil, fmt.Errorf("query invites by server id: %w", err)
+	}
+	defer rows.Close()
+
+	var invites []*server.Invite
+	for rows.Next() {
+		inv, err := scanInvite(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan invite: %w", err)
+		}
+		invites = append(invites, inv)
+	}
+	return invites, rows.Err()
+}
+
+// Delete removes an invite.
+func (r *InviteRepository) Delete(ctx context.Context, code string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM server_invites WHERE code = $1`, code)
+	if err != nil {
+		return fmt.Errorf("delete invite: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return server.ErrInviteNotFound
+	}
+	return nil
+}
+
+// Use consumes one use of an invite. The limits are checked in the same
+// statement so concurrent redeems cannot exceed max_uses.
+func (r *InviteRepository) Use(ctx context.Context, code string) error {
+	query := `
+		UPDATE server_invites
+		SET uses = uses + 1
+		WHERE code = $1
+		  AND (max_uses = 0 OR uses < max_uses)
+		  AND (expires_at IS NULL OR expires_at > NOW())
+	`
+
+	result, err := r.pool.Exec(ctx, query, code)
+	if err != nil {
+		return fmt.Errorf("use invite: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return server.ErrInviteExpired
+	}
+	return nil
+}
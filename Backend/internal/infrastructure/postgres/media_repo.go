@@ -21,15 +21,28 @@ func NewMediaRepository(pool *pgxpool.Pool) *MediaRepository {
 	return &MediaRepository{pool: pool}
 }
 
+const mediaColumns = `id, user_id, filename, original_name, mime_type, type, size, width, height, url, created_at`
+
+func scanMedia(row pgx.Row) (*media.Media, error) {
+	var m media.Media
+	err := row.Scan(
+		&m.ID, &m.UserID, &m.Filename, &m.OriginalName, &m.MimeType, &m.Type, &m.Size, &m.Width, &m.Height, &m.URL, &m.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
 // Create inserts a new media record.
 func (r *MediaRepository) Create(ctx context.Context, m *media.Media) error {
 	query := `
-		INSERT INTO media (id, user_id, filename, original_name, mime_type, type, size, url, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO media (id, user_id, filename, original_name, mime_type, type, size, width, height, url, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	_, err := r.pool.Exec(ctx, query,
-		m.ID, m.UserID, m.Filename, m.OriginalName, m.MimeType, m.Type, m.Size, m.URL, m.CreatedAt,
+		m.ID, m.UserID, m.Filename, m.OriginalName, m.MimeType, m.Type, m.Size, m.Width, m.Height, m.URL, m.CreatedAt,
 	)
 
 	if err != nil {
@@ -42,16 +55,12 @@ func (r *MediaRepository) Create(ctx context.Context, m *media.Media) error {
 // FindByID finds a media by its ID.
 func (r *MediaRepository) FindByID(ctx context.Context, id string) (*media.Media, error) {
 	query := `
-		SELECT id, user_id, filename, original_name, mime_type, type, size, url, created_at
+		SELECT ` + mediaColumns + `
 		FROM media
 		WHERE id = $1
 	`
 
-	var m media.Media
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&m.ID, &m.UserID, &m.Filename, &m.OriginalName, &m.MimeType, &m.Type, &m.Size, &m.URL, &m.CreatedAt,
-	)
-
+	m, err := scanMedia(r.pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, media.ErrNotFound
@@ -59,13 +68,39 @@ func (r *MediaRepository) FindByID(ctx context.Context, id string) (*media.Media
 		return nil, fmt.Errorf("query media by id: %w", err)
 	}
 
-	return &m, nil
+	return m, nil
+}
+
+// FindByIDs finds the media with the given IDs. Unknown IDs are skipped.
+func (r *MediaRepository) FindByIDs(ctx context.Context, ids []string) ([]*media.Media, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT ` + mediaColumns + ` FROM media WHERE id = ANY($1)`
+
+	rows, err := r.pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("query media by ids: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*media.Media
+	for rows.Next() {
+		m, err := scanMedia(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan media: %w", err)
+		}
+		result = append(result, m)
+	}
+
+	return result, rows.Err()
 }
 
 // FindByUserID finds media by user ID with pagination.
 func (r *MediaRepository) FindByUserID(ctx context.Context, userID string, limit, offset int) ([]*media.Media, error) {
 	query := `
-		SELECT id, user_id, filename, original_name, mime_type, type, size, url, created_at
+		SELECT ` + mediaColumns + `
 		FROM media
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -80,14 +115,11 @@ func (r *MediaRepository) FindByUserID(ctx context.Context, userID string, limit
 
 	var result []*media.Media
 	for rows.Next() {
-		var m media.Media
-		err := rows.Scan(
-			&m.ID, &m.UserID, &m.Filename, &m.OriginalName, &m.MimeType, &m.Type, &m.Size, &m.URL, &m.CreatedAt,
-		)
+		m, err := scanMedia(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan media: %w", err)
 		}
-		result = append(result, &m)
+		result = append(result, m)
 	}
 
 	return result, nil
@@ -108,3 +140,19 @@ func (r *MediaRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// DeleteUnattached removes a media record no message has attached any more.
+func (r *MediaRepository) DeleteUnattached(ctx context.Context, id string) (bool, error) {
+	query := `
+		DELETE FROM media md
+		WHERE md.id = $1
+		  AND NOT EXISTS (SELECT 1 FROM message_attachments a WHERE a.media_id = md.id)
+	`
+
+	result, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, fmt.Errorf("delete unattached media: %w", err)
+	}
+
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/media"
+)
+
+// insertAttachments links media to a new message in the order given. column
+// is the message_attachments column naming the message's table. isCopy marks
+// the rows of a published announcement's copy, which share the source
+// message's media.
+func insertAttachments(ctx context.Context, tx pgx.Tx, column, messageID string, items []*media.Media, isCopy bool) error {
+	query := `INSERT INTO message_attachments (media_id, ` + column + `, position, is_copy) VALUES ($1, $2, $3, $4)`
+
+	for i, m := range items {
+		if _, err := tx.Exec(ctx, query, m.ID, messageID, i, isCopy); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case "23505":
+					return media.ErrAlreadyAttached
+				case "23503":
+					// Deleted by its uploader since it was looked up
+					return media.ErrNotFound
+				}
+			}
+			return fmt.Errorf("insert message attachment: %w", err)
+		}
+	}
+	return nil
+}
+
+// findAttachments loads the attachments of each message, in display order.
+func findAttachments(ctx context.Context, pool *pgxpool.Pool, messageIDs []string) (map[string][]*media.Media, error) {
+	result := make(map[string][]*media.Media)
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT a.message_id, md.id, md.user_id, md.filename, md.original_name, md.mime_type, md.type, md.size,
+		       md.width, md.height, md.url, md.created_at
+		FROM message_attachments a
+		JOIN media md ON a.media_id = md.id
+		WHERE a.message_id = ANY($1)
+		ORDER BY a.message_id, a.position
+	`
+
+	rows, err := pool.Query(ctx, query, messageIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query message attachments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID string
+		var m media.Media
+		err := rows.Scan(
+			&messageID, &m.ID, &m.UserID, &m.Filename, &m.OriginalName, &m.MimeType, &m.Type, &m.Size,
+			&m.Width, &m.Height, &m.URL, &m.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan message attachment: %w", err)
+		}
+		result[messageID] = append(result[messageID], &m)
+	}
+
+	return result, rows.Err()
+}
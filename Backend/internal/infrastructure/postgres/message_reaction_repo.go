@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/reaction"
+)
+
+// reactionCursorLayout formats the time half of a reaction user list cursor.
+const reactionCursorLayout = "2006-01-02T15:04:05.000000Z"
+
+// MessageReactionRepository implements reaction.Repository using PostgreSQL.
+type MessageReactionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewMessageReactionRepository creates a new MessageReactionRepository.
+func NewMessageReactionRepository(pool *pgxpool.Pool) *MessageReactionRepository {
+	return &MessageReactionRepository{pool: pool}
+}
+
+// Add adds a reaction, reporting whether the user had not already reacted
+// with that emoji. It returns reaction.ErrTooManyReactions when the emoji
+// would take the message past reaction.MaxEmojisPerMessage different emoji.
+func (r *MessageReactionRepository) Add(ctx context.Context, rc *reaction.Reaction) (bool, error) {
+	column := "channel_message_id"
+	if rc.Kind == reaction.KindDM {
+		column = "dm_message_id"
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	// Serialize adds on the same message so two new emoji cannot both
+	// squeeze in under the limit
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('message_reactions:' || $1))`, rc.MessageID); err != nil {
+		return false, fmt.Errorf("lock message reactions: %w", err)
+	}
+
+	// Only insert when the emoji is already on the message or there is
+	// still room for another one
+	query := `
+		WITH allowed AS (
+			SELECT EXISTS (
+				SELECT 1 FROM message_reactions
+				WHERE message_id = $1 AND emoji_name = $3 AND emoji_id = $4
+			) OR (
+				SELECT COUNT(DISTINCT (emoji_name, emoji_id)) FROM message_reactions
+				WHERE message_id = $1
+			) < $6 AS ok
+		), inserted AS (
+			INSERT INTO message_reactions (` + column + `, user_id, emoji_name, emoji_id, created_at)
+			SELECT $1, $2, $3, $4, $5 FROM allowed WHERE ok
+			ON CONFLICT (message_id, emoji_name, emoji_id, user_id) DO NOTHING
+			RETURNING 1
+		)
+		SELECT (SELECT ok FROM allowed), EXISTS (SELECT 1 FROM inserted)
+	`
+
+	var allowed, added bool
+	err = tx.QueryRow(ctx, query, rc.MessageID, rc.UserID, rc.Emoji.Name, rc.Emoji.ID, rc.CreatedAt, reaction.MaxEmojisPerMessage).
+		Scan(&allowed, &added)
+	if err != nil {
+		return false, fmt.Errorf("insert message reaction: %w", err)
+	}
+	if !allowed {
+		return false, reaction.ErrTooManyReactions
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit tx: %w", err)
+	}
+	return added, nil
+}
+
+// Remove removes a user's reaction, reporting whether it existed.
+func (r *MessageReactionRepository) Remove(ctx context.Context, messageID, userID string, emoji reaction.Emoji) (bool, error) {
+	query := `
+		DELETE FROM message_reactions
+		WHERE message_id = $1 AND user_id = $2 AND emoji_name = $3 AND emoji_id = $4
+	`
+
+	tag, err := r.pool.Exec(ctx, query, messageID, userID, emoji.Name, emoji.ID)
+	if err != nil {
+		return false, fmt.Errorf("delete message reaction: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// FindUsers lists who reacted to a message with an emoji, in the order they
+// reacted, with pagination.
+func (r *MessageReactionRepository) FindUsers(ctx context.Context, messageID string, emoji reaction.Emoji, cursor string, limit int) ([]*reaction.Reaction, string, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 25
+	}
+
+	args := []interface{}{messageID, emoji.Name, emoji.ID, limit + 1}
+	query := `
+		SELECT mr.message_id, mr.user_id, mr.emoji_name, mr.emoji_id, mr.dm_message_id IS NOT NULL, mr.created_at,
+		       u.id, u.handle, u.display_name, u.avatar_gradient
+		FROM message_reactions mr
+		JOIN users u ON mr.user_id = u.id
+		WHERE mr.message_id = $1 AND mr.emoji_name = $2 AND mr.emoji_id = $3
+	`
+
+	if cursor != "" {
+		at, userID, err := parseReactionCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		args = append(args, at, userID)
+		query += ` AND (mr.created_at, mr.user_id) > ($5, $6)`
+	}
+
+	query += ` ORDER BY mr.created_at, mr.user_id LIMIT $4`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("query reaction users: %w", err)
+	}
+	defer rows.Close()
+
+	var reactions []*reaction.Reaction
+	for rows.Next() {
+		var rc reaction.Reaction
+		var u reaction.User
+		var isDM bool
+		var gradient []string
+
+		err := rows.Scan(
+			&rc.MessageID, &rc.UserID, &rc.Emoji.Name, &rc.Emoji.ID, &isDM, &rc.CreatedAt,
+			&u.ID, &u.Handle, &u.DisplayName, &gradient,
+		)
+		if err != nil {
+			return nil, "", fmt.Errorf("scan reaction user: %w", err)
+		}
+
+		rc.Kind = reaction.KindChannel
+		if isDM {
+			rc.Kind = reaction.KindDM
+		}
+		if len(gradient) >= 2 {
+			u.AvatarGradient = [2]string{gradient[0], gradient[1]}
+		}
+		rc.User = &u
+		reactions = append(reactions, &rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("iterate reaction users: %w", err)
+	}
+
+	var nextCursor string
+	if len(reactions) > limit {
+		last := reactions[limit-1]
+		nextCursor = last.CreatedAt.UTC().Format(reactionCursorLayout) + "|" + last.UserID
+		reactions = reactions[:limit]
+	}
+
+	return reactions, nextCursor, nil
+}
+
+// Summarize counts the reactions on each message per emoji, in the order each
+// emoji was first used, flagging the ones userID reacted with.
+func (r *MessageReactionRepository) Summarize(ctx context.Context, messageIDs []string, userID string) (map[string][]reaction.Summary, error) {
+	result := make(map[string][]reaction.Summary)
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	query := `
+		SELECT message_id, emoji_name, emoji_id, COUNT(*), BOOL_OR(user_id = $2)
+		FROM message_reactions
+		WHERE message_id = ANY($1)
+		GROUP BY message_id, emoji_name, emoji_id
+		ORDER BY message_id, MIN(created_at)
+	`
+
+	rows, err := r.pool.Query(ctx, query, messageIDs, userID)
+	if err != nil {
+		return nil, fmt.Errorf("summarize reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID string
+		var s reaction.Summary
+		if err := rows.Scan(&messageID, &s.Emoji.Name, &s.Emoji.ID, &s.Count, &s.Me); err != nil {
+			return nil, fmt.Errorf("scan reaction summary: %w", err)
+		}
+		result[messageID] = append(result[messageID], s)
+	}
+
+	return result, rows.Err()
+}
+
+func parseReactionCursor(cursor string) (time.Time, string, error) {
+	ts, userID, ok := strings.Cut(cursor, "|")
+	if !ok || userID == "" {
+		return time.Time{}, "", reaction.ErrInvalidCursor
+	}
+	at, err := time.Parse(reactionCursorLayout, ts)
+	if err != nil {
+		return time.Time{}, "", reaction.ErrInvalidCursor
+	}
+	return at, userID, nil
+}
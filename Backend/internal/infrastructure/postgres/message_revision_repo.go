@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/revision"
+)
+
+// MessageRevisionRepository implements revision.Repository using PostgreSQL.
+type MessageRevisionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewMessageRevisionRepository creates a new MessageRevisionRepository.
+func NewMessageRevisionRepository(pool *pgxpool.Pool) *MessageRevisionRepository {
+	return &MessageRevisionRepository{pool: pool}
+}
+
+// Create stores a revision.
+func (r *MessageRevisionRepository) Create(ctx context.Context, rev *revision.Revision) error {
+	column := "channel_message_id"
+	if rev.Kind == revision.KindDM {
+		column = "dm_message_id"
+	}
+
+	query := `
+		INSERT INTO message_revisions (id, ` + column + `, content, edited_at)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := r.pool.Exec(ctx, query, rev.ID, rev.MessageID, rev.Content, rev.EditedAt); err != nil {
+		return fmt.Errorf("insert message revision: %w", err)
+	}
+	return nil
+}
+
+// FindByMessageID finds a message's revisions, oldest first.
+func (r *MessageRevisionRepository) FindByMessageID(ctx context.Context, messageID string) ([]*revision.Revision, error) {
+	query := `
+		SELECT id, message_id, dm_message_id IS NOT NULL, content, edited_at
+		FROM message_revisions
+		WHERE message_id = $1
+		ORDER BY edited_at, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, messageID)
+	if err != nil {
+		return nil, fmt.Errorf("query message revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*revision.Revision
+	for rows.Next() {
+		var rev revision.Revision
+		var isDM bool
+		if err := rows.Scan(&rev.ID, &rev.MessageID, &isDM, &rev.Content, &rev.EditedAt); err != nil {
+			return nil, fmt.Errorf("scan message revision: %w", err)
+		}
+
+		rev.Kind = revision.KindChannel
+		if isDM {
+			rev.Kind = revision.KindDM
+		}
+		revisions = append(revisions, &rev)
+	}
+
+	return revisions, rows.Err()
+}
+
+// DeleteExpired deletes the channel message revisions that have outlived
+// their server's retention, returning how many were deleted.
+func (r *MessageRevisionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `
+		DELETE FROM message_revisions mr
+		USING channel_messages cm, servers s
+		WHERE mr.channel_message_id = cm.id
+		  AND cm.server_id = s.id
+		  AND s.revision_retention_days > 0
+		  AND mr.edited_at < NOW() - make_interval(days => s.revision_retention_days)
+	`
+
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired message revisions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/server"
+)
+
+// NotificationSettingsRepository implements server.NotificationSettingsRepository
+// using PostgreSQL.
+type NotificationSettingsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewNotificationSettingsRepository creates a new NotificationSettingsRepository.
+func NewNotificationSettingsRepository(pool *pgxpool.Pool) *NotificationSettingsRepository {
+	return &NotificationSettingsRepository{pool: pool}
+}
+
+const notificationSettingsColumns = `server_id, user_id, level, suppress_everyone, suppress_roles, muted_until, updated_at`
+
+func scanNotificationSettings(row pgx.Row) (*server.NotificationSettings, error) {
+	var s server.NotificationSettings
+	err := row.Scan(&s.ServerID, &s.UserID, &s.Level, &s.SuppressEveryone, &s.SuppressRoles, &s.MutedUntil, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Find finds a member's settings, returning nil if they never changed them.
+func (r *NotificationSettingsRepository) Find(ctx context.Context, serverID, userID string) (*server.NotificationSettings, error) {
+	query := `SELECT ` + notificationSettingsColumns + ` FROM server_notification_settings WHERE server_id = $1 AND user_id = $2`
+
+	s, err := scanNotificationSettings(r.pool.QueryRow(ctx, query, serverID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query notification settings: %w", err)
+	}
+	return s, nil
+}
+
+// FindByUserIDs finds the settings of several members of a server.
+func (r *NotificationSettingsRepository) FindByUserIDs(ctx context.Context, serverID string, userIDs []string) (map[string]*server.NotificationSettings, error) {
+	result := make(map[string]*server.NotificationSettings)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT ` + notificationSettingsColumns + ` FROM server_notification_settings WHERE server_id = $1 AND user_id = ANY($2)`
+
+	rows, err := r.pool.Query(ctx, query, serverID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query notification settings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		s, err := scanNotificationSettings(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan notification settings: %w", err)
+		}
+		result[s.UserID] = s
+	}
+
+	return result, rows.Err()
+}
+
+// Upsert creates or updates a member's settings.
+func (r *NotificationSettingsRepository) Upsert(ctx context.Context, s *server.NotificationSettings) error {
+	query := `
+		INSERT INTO server_notification_settings (server_id, user_id, level, suppress_everyone, suppress_roles, muted_until, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (server_id, user_id) DO UPDATE SET
+			level = EXCLUDED.level,
+			suppress_everyone = EXCLUDED.suppress_everyone,
+			suppress_roles = EXCLUDED.suppress_roles,
+			muted_until = EXCLUDED.muted_until,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.pool.Exec(ctx, query, s.ServerID, s.UserID, s.Level, s.SuppressEveryone, s.SuppressRoles, s.MutedUntil, s.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert notification settings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/presence"
+)
+
+// PresenceRepository implements presence.Repository and
+// presence.AudienceRepository using PostgreSQL.
+type PresenceRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPresenceRepository creates a new PresenceRepository.
+func NewPresenceRepository(pool *pgxpool.Pool) *PresenceRepository {
+	return &PresenceRepository{pool: pool}
+}
+
+const presenceColumns = `
+	user_id, status, custom_status_text, custom_status_emoji, custom_status_expires_at,
+	activity_type, activity_name, activity_details, updated_at
+`
+
+// FindByUserID finds a user's presence.
+func (r *PresenceRepository) FindByUserID(ctx context.Context, userID string) (*presence.Presence, error) {
+	query := `SELECT ` + presenceColumns + ` FROM user_presences WHERE user_id = $1`
+
+	p, err := scanPresence(r.pool.QueryRow(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, presence.ErrNotFound
+		}
+		return nil, fmt.Errorf("query presence: %w", err)
+	}
+
+	return p, nil
+}
+
+// FindByUserIDs finds presences for multiple users (batch).
+func (r *PresenceRepository) FindByUserIDs(ctx context.Context, userIDs []string) (map[string]*presence.Presence, error) {
+	result := make(map[string]*presence.Presence)
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT ` + presenceColumns + ` FROM user_presences WHERE user_id = ANY($1)`
+
+	rows, err := r.pool.Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query presences: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanPresence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan presence: %w", err)
+		}
+		result[p.UserID] = p
+	}
+
+	return result, rows.Err()
+}
+
+// Upsert creates or updates a user's presence.
+func (r *PresenceRepository) Upsert(ctx context.Context, p *presence.Presence) error {
+	query := `
+		INSERT INTO user_presences (
+			user_id, status, custom_status_text, custom_status_emoji, custom_status_expires_at,
+			activity_type, activity_name, activity_details, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			custom_status_text = EXCLUDED.custom_status_text,
+			custom_status_emoji = EXCLUDED.custom_status_emoji,
+			custom_status_expires_at = EXCLUDED.custom_status_expires_at,
+			activity_type = EXCLUDED.activity_type,
+			activity_name = EXCLUDED.activity_name,
+			activity_details = EXCLUDED.activity_details
+	`
+
+	var statusText, statusEmoji *string
+	var statusExpiresAt *time.Time
+	if p.CustomStatus != nil {
+		statusText = &p.CustomStatus.Text
+		statusEmoji = &p.CustomStatus.Emoji
+		statusExpiresAt = p.CustomStatus.ExpiresAt
+	}
+
+	var activityType, activityName, activityDetails *string
+	if p.Activity != nil {
+		t := string(p.Activity.Type)
+		activityType = &t
+		activityName = &p.Activity.Name
+		activityDetails = &p.Activity.Details
+	}
+
+	_, err := r.pool.Exec(ctx, query,
+		p.UserID, p.Status, statusText, statusEmoji, statusExpiresAt,
+		activityType, activityName, activityDetails, p.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert presence: %w", err)
+	}
+
+	return nil
+}
+
+// FindRelated returns the users that follow, are followed by, or share a
+// server with the user.
+func (r *PresenceRepository) FindRelated(ctx context.Context, userID string) ([]string, error) {
+	query := `
+		SELECT f.followed_id FROM follows f
+		WHERE f.follower_id = $1 AND f.status = 'active'
+		UNION
+		SELECT f.follower_id FROM follows f
+		WHERE f.followed_id = $1 AND f.status = 'active'
+		UNION
+		SELECT other.user_id FROM server_members me
+		INNER JOIN server_members other ON other.server_id = me.server_id
+		WHERE me.user_id = $1 AND other.user_id <> $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query related users: %w", err)
+	}
+	return scanUserIDs(rows, "related user")
+}
+
+// FilterFriends returns the candidates that follow the user and are followed
+// back.
+func (r *PresenceRepository) FilterFriends(ctx context.Context, userID string, candidateIDs []string) ([]string, error) {
+	if len(candidateIDs) == 0 {
+		return []string{}, nil
+	}
+
+	query := `
+		SELECT f.follower_id FROM follows f
+		WHERE f.followed_id = $1 AND f.follower_id = ANY($2)
+		  AND EXISTS (SELECT 1 FROM follows b WHERE b.follower_id = $1 AND b.followed_id = f.follower_id)
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, candidateIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query friends: %w", err)
+	}
+	return scanUserIDs(rows, "friend")
+}
+
+// FilterVisibleTo returns the targets whose online status the viewer may
+// see. Users without privacy settings have the defaults: visible to
+// everyone, activity shown.
+func (r *PresenceRepository) FilterVisibleTo(ctx context.Context, viewerID string, targetIDs []string) ([]presence.Viewable, error) {
+	if len(targetIDs) == 0 {
+		return []presence.Viewable{}, nil
+	}
+
+	query := `
+		SELECT t.id, COALESCE(ps.show_activity, TRUE)
+		FROM unnest($2::varchar[]) AS t(id)
+		LEFT JOIN privacy_settings ps ON ps.user_id = t.id
+		WHERE t.id = $1
+		   OR COALESCE(ps.online_status_visibility, 'everyone') = 'everyone'
+		   OR (ps.online_status_visibility = 'friends'
+		       AND EXISTS (SELECT 1 FROM follows WHERE follower_id = $1 AND followed_id = t.id)
+		       AND EXISTS (SELECT 1 FROM follows WHERE follower_id = t.id AND followed_id = $1))
+	`
+
+	rows, err := r.pool.Query(ctx, query, viewerID, targetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query visible users: %w", err)
+	}
+	defer rows.Close()
+
+	visible := make([]presence.Viewable, 0)
+	for rows.Next() {
+		var v presence.Viewable
+		if err := rows.Scan(&v.UserID, &v.ShowActivity); err != nil {
+			return nil, fmt.Errorf("scan visible user: %w", err)
+		}
+		visible = append(visible, v)
+	}
+	return visible, rows.Err()
+}
+
+func scanUserIDs(rows pgx.Rows, what string) ([]string, error) {
+	defer rows.Close()
+
+	ids := make([]string, 0)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", what, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func scanPresence(row pgx.Row) (*presence.Presence, error) {
+	var p presence.Presence
+	var status string
+	var statusText, statusEmoji, activityType, activityName, activityDetails *string
+	var statusExpiresAt *time.Time
+
+	err := row.Scan(
+		&p.UserID, &status, &statusText, &statusEmoji, &statusExpiresAt,
+		&activityType, &activityName, &activityDetails, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Status = presence.Status(status)
+	if statusText != nil || statusEmoji != nil {
+		p.CustomStatus = &presence.CustomStatus{
+			Text:      derefString(statusText),
+			Emoji:     derefString(statusEmoji),
+			ExpiresAt: statusExpiresAt,
+		}
+	}
+	if activityType != nil {
+		p.Activity = &presence.Activity{
+			Type:    presence.ActivityType(*activityType),
+			Name:    derefString(activityName),
+			Details: derefString(activityDetails),
+		}
+	}
+
+	return &p, nil
+}
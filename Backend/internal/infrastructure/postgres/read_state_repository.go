@@ -24,7 +24,8 @@ func (r *ReadStateRepository) Upsert(ctx context.Context, rs *readstate.ReadStat
 		ON CONFLICT (user_id, channel_id)
 		DO UPDATE SET
 			last_read_message_id = EXCLUDED.last_read_message_id,
-			last_read_at = EXCLUDED.last_read_at
+			last_read_at = EXCLUDED.last_read_at,
+			mention_count = 0
 		RETURNING id
 	`
 	return r.db.QueryRow(ctx, query, rs.UserID, rs.ChannelID, rs.LastReadMessageID, rs.LastReadAt).Scan(&rs.ID)
@@ -32,13 +33,13 @@ func (r *ReadStateRepository) Upsert(ctx context.Context, rs *readstate.ReadStat
 
 func (r *ReadStateRepository) Get(ctx context.Context, userID, channelID string) (*readstate.ReadState, error) {
 	query := `
-		SELECT id, user_id, channel_id, last_read_message_id, last_read_at
+		SELECT id, user_id, channel_id, last_read_message_id, last_read_at, mention_count
 		FROM read_states
 		WHERE user_id = $1 AND channel_id = $2
 	`
 	var rs readstate.ReadState
 	err := r.db.QueryRow(ctx, query, userID, channelID).Scan(
-		&rs.ID, &rs.UserID, &rs.ChannelID, &rs.LastReadMessageID, &rs.LastReadAt,
+		&rs.ID, &rs.UserID, &rs.ChannelID, &rs.LastReadMessageID, &rs.LastReadAt, &rs.MentionCount,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -51,7 +52,7 @@ func (r *ReadStateRepository) Get(ctx context.Context, userID, channelID string)
 
 func (r *ReadStateRepository) GetByChannelID(ctx context.Context, channelID string) ([]*readstate.ReadState, error) {
 	query := `
-		SELECT id, user_id, channel_id, last_read_message_id, last_read_at
+		SELECT id, user_id, channel_id, last_read_message_id, last_read_at, mention_count
 		FROM read_states
 		WHERE channel_id = $1
 	`
@@ -59,15 +60,48 @@ func (r *ReadStateRepository) GetByChannelID(ctx context.Context, channelID stri
 	if err != nil {
 		return nil, err
 	}
+	return scanReadStates(rows)
+}
+
+func (r *ReadStateRepository) GetByUserAndChannels(ctx context.Context, userID string, channelIDs []string) ([]*readstate.ReadState, error) {
+	query := `
+		SELECT id, user_id, channel_id, last_read_message_id, last_read_at, mention_count
+		FROM read_states
+		WHERE user_id = $1 AND channel_id = ANY($2)
+	`
+	rows, err := r.db.Query(ctx, query, userID, channelIDs)
+	if err != nil {
+		return nil, err
+	}
+	return scanReadStates(rows)
+}
+
+func (r *ReadStateRepository) IncrementMentions(ctx context.Context, channelID string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	// Users who never read the channel get a read state holding just the count
+	query := `
+		INSERT INTO read_states (user_id, channel_id, mention_count)
+		SELECT unnest($2::VARCHAR[]), $1, 1
+		ON CONFLICT (user_id, channel_id)
+		DO UPDATE SET mention_count = read_states.mention_count + 1
+	`
+	_, err := r.db.Exec(ctx, query, channelID, userIDs)
+	return err
+}
+
+func scanReadStates(rows pgx.Rows) ([]*readstate.ReadState, error) {
 	defer rows.Close()
 
 	var states []*readstate.ReadState
 	for rows.Next() {
 		var rs readstate.ReadState
-		if err := rows.Scan(&rs.ID, &rs.UserID, &rs.ChannelID, &rs.LastReadMessageID, &rs.LastReadAt); err != nil {
+		if err := rows.Scan(&rs.ID, &rs.UserID, &rs.ChannelID, &rs.LastReadMessageID, &rs.LastReadAt, &rs.MentionCount); err != nil {
 			return nil, err
 		}
 		states = append(states, &rs)
 	}
-	return states, nil
+	return states, rows.Err()
 }
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/channel"
+)
+
+// ScheduledMessageRepository implements channel.ScheduledMessageRepository
+// using PostgreSQL.
+type ScheduledMessageRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewScheduledMessageRepository creates a new ScheduledMessageRepository.
+func NewScheduledMessageRepository(pool *pgxpool.Pool) *ScheduledMessageRepository {
+	return &ScheduledMessageRepository{pool: pool}
+}
+
+const scheduledMessageColumns = `id, server_id, channel_id, author_id, content, attachment_ids, publish_at, status, failure, claimed_at, created_at, updated_at`
+
+func scanScheduledMessage(row pgx.Row) (*channel.ScheduledMessage, error) {
+	var msg channel.ScheduledMessage
+	err := row.Scan(
+		&msg.ID, &msg.ServerID, &msg.ChannelID, &msg.AuthorID, &msg.Content, &msg.AttachmentIDs,
+		&msg.PublishAt, &msg.Status, &msg.Failure, &msg.ClaimedAt, &msg.CreatedAt, &msg.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func scanScheduledMessages(rows pgx.Rows) ([]*channel.ScheduledMessage, error) {
+	defer rows.Close()
+
+	var messages []*channel.ScheduledMessage
+	for rows.Next() {
+		msg, err := scanScheduledMessage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan scheduled message: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages, rows.Err()
+}
+
+// FindByID finds a scheduled message by its ID.
+func (r *ScheduledMessageRepository) FindByID(ctx context.Context, id string) (*channel.ScheduledMessage, error) {
+	query := `SELECT ` + scheduledMessageColumns + ` FROM scheduled_messages WHERE id = $1`
+
+	msg, err := scanScheduledMessage(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, channel.ErrScheduledNotFound
+		}
+		return nil, fmt.Errorf("query scheduled message by id: %w", err)
+	}
+	return msg, nil
+}
+
+// FindByAuthor finds an author's scheduled messages in a server, next to be
+// published first.
+func (r *ScheduledMessageRepository) FindByAuthor(ctx context.Context, serverID, authorID string) ([]*channel.ScheduledMessage, error) {
+	query := `
+		SELECT ` + scheduledMessageColumns + `
+		FROM scheduled_messages
+		WHERE server_id = $1 AND author_id = $2
+		ORDER BY publish_at, id
+	`
+
+	rows, err := r.pool.Query(ctx, query, serverID, authorID)
+	if err != nil {
+		return nil, fmt.Errorf("query scheduled messages: %w", err)
+	}
+	return scanScheduledMessages(rows)
+}
+
+// CountByChannel counts a channel's pending and failed scheduled messages.
+func (r *ScheduledMessageRepository) CountByChannel(ctx context.Context, channelID string) (int, error) {
+	query := `SELECT COUNT(*) FROM scheduled_messages WHERE channel_id = $1 AND status <> 'publishing'`
+
+	var count int
+	if err := r.pool.QueryRow(ctx, query, channelID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count scheduled messages: %w", err)
+	}
+	return count, nil
+}
+
+// Create creates a scheduled message.
+func (r *ScheduledMessageRepository) Create(ctx context.Context, msg *channel.ScheduledMessage) error {
+	query := `
+		INSERT INTO scheduled_messages (` + scheduledMessageColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		msg.ID, msg.ServerID, msg.ChannelID, msg.AuthorID, msg.Content, nonNilStrings(msg.AttachmentIDs),
+		msg.PublishAt, msg.Status, msg.Failure, msg.ClaimedAt, msg.CreatedAt, msg.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert scheduled message: %w", err)
+	}
+	return nil
+}
+
+// Update saves the content, attachments, publish time, status and failure
+// of a message that is pending or failed.
+func (r *ScheduledMessageRepository) Update(ctx context.Context, msg *channel.ScheduledMessage) error {
+	query := `
+		UPDATE scheduled_messages
+		SET content = $2, attachment_ids = $3, publish_at = $4, status = $5, failure = $6, updated_at = $7
+		WHERE id = $1 AND status IN ('pending', 'failed')
+	`
+
+	tag, err := r.pool.Exec(ctx, query,
+		msg.ID, msg.Content, nonNilStrings(msg.AttachmentIDs), msg.PublishAt, msg.Status, msg.Failure, msg.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update scheduled message: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return channel.ErrScheduledPublished
+	}
+	return nil
+}
+
+// Delete deletes a message that is pending or failed.
+func (r *ScheduledMessageRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM scheduled_messages WHERE id = $1 AND status IN ('pending', 'failed')`
+
+	tag, err := r.pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("delete scheduled message: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return channel.ErrScheduledPublished
+	}
+	return nil
+}
+
+// ClaimDue marks up to limit pending messages due by now as publishing and
+// returns them, oldest publish time first. Messages whose claim is older
+// than channel.ScheduledClaimLease are claimed again. Rows locked by another
+// publisher are skipped.
+func (r *ScheduledMessageRepository) ClaimDue(ctx context.Context, now time.Time, limit int) ([]*channel.ScheduledMessage, error) {
+	query := `
+		UPDATE scheduled_messages
+		SET status = 'publishing', claimed_at = $1, updated_at = $1
+		WHERE id IN (
+			SELECT id FROM scheduled_messages
+			WHERE (status = 'pending' AND publish_at <= $1)
+			   OR (status = 'publishing' AND claimed_at <= $3)
+			ORDER BY publish_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + scheduledMessageColumns
+
+	rows, err := r.pool.Query(ctx, query, now, limit, now.Add(-channel.ScheduledClaimLease))
+	if err != nil {
+		return nil, fmt.Errorf("claim scheduled messages: %w", err)
+	}
+	return scanScheduledMessages(rows)
+}
+
+// MarkPublished deletes a claimed message that has been sent.
+func (r *ScheduledMessageRepository) MarkPublished(ctx context.Context, id string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM scheduled_messages WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete published scheduled message: %w", err)
+	}
+	return nil
+}
+
+// Release saves the status and failure of a claimed message that was not
+// sent, unless another publisher has claimed it since.
+func (r *ScheduledMessageRepository) Release(ctx context.Context, msg *channel.ScheduledMessage) error {
+	query := `
+		UPDATE scheduled_messages
+		SET status = $2, failure = $3, claimed_at = NULL, updated_at = $4
+		WHERE id = $1 AND status = 'publishing' AND claimed_at = $5
+	`
+
+	if _, err := r.pool.Exec(ctx, query, msg.ID, msg.Status, msg.Failure, msg.UpdatedAt, msg.ClaimedAt); err != nil {
+		return fmt.Errorf("release scheduled message: %w", err)
+	}
+	return nil
+}
@@ -26,7 +26,8 @@ func NewServerRepository(pool *pgxpool.Pool) *ServerRepository {
 func (r *ServerRepository) FindByID(ctx context.Context, id string) (*server.Server, error) {
 	query := `
 		SELECT id, name, description, icon_gradient, owner_id, 
-		       member_count, is_public, created_at, updated_at, handle, tag
+		       member_count, is_public, created_at, updated_at, handle, tag,
+		       revision_retention_days
 		FROM servers
 		WHERE id = $1
 	`
@@ -48,6 +49,7 @@ func (r *ServerRepository) FindByID(ctx context.Context, id string) (*server.Ser
 		&s.UpdatedAt,
 		&s.Handle,
 		&tag,
+		&s.RevisionRetentionDays,
 	)
 
 	if err != nil {
@@ -74,7 +76,8 @@ func (r *ServerRepository) FindByID(ctx context.Context, id string) (*server.Ser
 func (r *ServerRepository) FindByHandle(ctx context.Context, handle string) (*server.Server, error) {
 	query := `
 		SELECT id, name, description, icon_gradient, owner_id, 
-		       member_count, is_public, created_at, updated_at, handle, tag
+		       member_count, is_public, created_at, updated_at, handle, tag,
+		       revision_retention_days
 		FROM servers
 		WHERE handle = $1
 	`
@@ -96,6 +99,7 @@ func (r *ServerRepository) FindByHandle(ctx context.Context, handle string) (*se
 		&s.UpdatedAt,
 		&s.Handle,
 		&tag,
+		&s.RevisionRetentionDays,
 	)
 
 	if err != nil {
@@ -122,7 +126,8 @@ func (r *ServerRepository) FindByHandle(ctx context.Context, handle string) (*se
 func (r *ServerRepository) FindByUserID(ctx context.Context, userID string) ([]*server.Server, error) {
 	query := `
 		SELECT s.id, s.name, s.description, s.icon_gradient, s.owner_id, 
-		       s.member_count, s.is_public, s.created_at, s.updated_at, s.handle, s.tag
+		       s.member_count, s.is_public, s.created_at, s.updated_at, s.handle, s.tag,
+		       s.revision_retention_days
 		FROM servers s
 		INNER JOIN server_members sm ON s.id = sm.server_id
 		WHERE sm.user_id = $1
@@ -154,6 +159,7 @@ func (r *ServerRepository) FindByUserID(ctx context.Context, userID string) ([]*
 			&s.UpdatedAt,
 			&s.Handle,
 			&tag,
+			&s.RevisionRetentionDays,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan server: %w", err)
@@ -180,8 +186,9 @@ func (r *ServerRepository) Create(ctx context.Context, s *server.Server) error {
 	query := `
 		INSERT INTO servers (
 			id, name, description, icon_gradient, owner_id,
-			member_count, is_public, created_at, updated_at, handle, tag
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			member_count, is_public, created_at, updated_at, handle, tag,
+			revision_retention_days
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
 	var desc *string
@@ -206,6 +213,7 @@ func (r *ServerRepository) Create(ctx context.Context, s *server.Server) error {
 		s.UpdatedAt,
 		s.Handle,
 		tag,
+		s.RevisionRetentionDays,
 	)
 
 	if err != nil {
@@ -224,6 +232,7 @@ func (r *ServerRepository) Update(ctx context.Context, s *server.Server) error {
 			icon_gradient = $4,
 			is_public = $5,
 			tag = $6,
+			revision_retention_days = $7,
 			updated_at = NOW()
 		WHERE id = $1
 	`
@@ -245,6 +254,7 @@ func (r *ServerRepository) Update(ctx context.Context, s *server.Server) error {
 		s.IconGradient[:],
 		s.IsPublic,
 		tag,
+		s.RevisionRetentionDays,
 	)
 
 	if err != nil {
@@ -305,6 +315,7 @@ func (r *MemberRepository) FindByServerID(ctx context.Context, serverID string)
 	query := `
 		SELECT
 			m.id, m.server_id, m.user_id, m.nickname, m.joined_at, m.communication_disabled_until,
+			m.invite_code, m.is_temporary,
 			r.id, r.server_id, r.name, r.color, r.position,
 			r.permissions, r.is_default, r.is_mentionable, r.created_at, r.updated_at
 		FROM server_members m
@@ -329,6 +340,8 @@ func (r *MemberRepository) FindByServerID(ctx context.Context, serverID string)
 		var userID string
 		var joinedAt time.Time
 		var commDisabledUntil *time.Time
+		var inviteCode *string
+		var isTemporary bool
 
 		var roleID *string
 		var roleServerID *string
@@ -343,6 +356,7 @@ func (r *MemberRepository) FindByServerID(ctx context.Context, serverID string)
 
 		err := rows.Scan(
 			&memberID, &srvID, &userID, &nickname, &joinedAt, &commDisabledUntil,
+			&inviteCode, &isTemporary,
 			&roleID, &roleServerID, &roleName, &roleColor, &rolePosition,
 			&rolePermissions, &roleIsDefault, &roleIsMentionable, &roleCreatedAt, &roleUpdatedAt,
 		)
@@ -358,6 +372,8 @@ func (r *MemberRepository) FindByServerID(ctx context.Context, serverID string)
 				UserID:                     userID,
 				JoinedAt:                   joinedAt,
 				CommunicationDisabledUntil: commDisabledUntil,
+				InviteCode:                 inviteCode,
+				IsTemporary:                isTemporary,
 			}
 			if nickname != nil {
 				m.Nickname = *nickname
@@ -421,10 +437,41 @@ func derefTime(v *time.Time) time.Time {
 	return *v
 }
 
+// FindByID finds a member by its ID.
+func (r *MemberRepository) FindByID(ctx context.Context, id string) (*server.Member, error) {
+	query := `
+		SELECT id, server_id, user_id, nickname, joined_at, communication_disabled_until,
+		       invite_code, is_temporary
+		FROM server_members
+		WHERE id = $1
+	`
+
+	var m server.Member
+	var nickname *string
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&m.ID, &m.ServerID, &m.UserID, &nickname, &m.JoinedAt, &m.CommunicationDisabledUntil,
+		&m.InviteCode, &m.IsTemporary,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, server.ErrNotMember
+		}
+		return nil, fmt.Errorf("query member by id: %w", err)
+	}
+
+	if nickname != nil {
+		m.Nickname = *nickname
+	}
+
+	return &m, nil
+}
+
 // FindByServerAndUser finds a member by server and user ID.
 func (r *MemberRepository) FindByServerAndUser(ctx context.Context, serverID, userID string) (*server.Member, error) {
 	query := `
-		SELECT id, server_id, user_id, nickname, joined_at, communication_disabled_until
+		SELECT id, server_id, user_id, nickname, joined_at, communication_disabled_until,
+		       invite_code, is_temporary
 		FROM server_members
 		WHERE server_id = $1 AND user_id = $2
 	`
@@ -433,6 +480,7 @@ func (r *MemberRepository) FindByServerAndUser(ctx context.Context, serverID, us
 	var nickname *string
 	err := r.pool.QueryRow(ctx, query, serverID, userID).Scan(
 		&m.ID, &m.ServerID, &m.UserID, &nickname, &m.JoinedAt, &m.CommunicationDisabledUntil,
+		&m.InviteCode, &m.IsTemporary,
 	)
 
 	if err != nil {
@@ -491,8 +539,8 @@ func (r *MemberRepository) FindByServerAndUserWithRoles(ctx context.Context, ser
 // Create adds a member to a server.
 func (r *MemberRepository) Create(ctx context.Context, m *server.Member) error {
 	query := `
-		INSERT INTO server_members (id, server_id, user_id, nickname, joined_at, communication_disabled_until)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO server_members (id, server_id, user_id, nickname, joined_at, communication_disabled_until, invite_code, is_temporary)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	var nickname *string
@@ -501,7 +549,7 @@ func (r *MemberRepository) Create(ctx context.Context, m *server.Member) error {
 	}
 
 	_, err := r.pool.Exec(ctx, query,
-		m.ID, m.ServerID, m.UserID, nickname, m.JoinedAt, m.CommunicationDisabledUntil,
+		m.ID, m.ServerID, m.UserID, nickname, m.JoinedAt, m.CommunicationDisabledUntil, m.InviteCode, m.IsTemporary,
 	)
 
 	if err != nil {
@@ -519,7 +567,7 @@ func (r *MemberRepository) Create(ctx context.Context, m *server.Member) error {
 func (r *MemberRepository) Update(ctx context.Context, m *server.Member) error {
 	query := `
 		UPDATE server_members
-		SET nickname = $3, communication_disabled_until = $4
+		SET nickname = $3, communication_disabled_until = $4, is_temporary = $5
 		WHERE server_id = $1 AND user_id = $2
 	`
 
@@ -529,7 +577,7 @@ func (r *MemberRepository) Update(ctx context.Context, m *server.Member) error {
 	}
 
 	_, err := r.pool.Exec(ctx, query,
-		m.ServerID, m.UserID, nickname, m.CommunicationDisabledUntil,
+		m.ServerID, m.UserID, nickname, m.CommunicationDisabledUntil, m.IsTemporary,
 	)
 
 	if err != nil {
@@ -591,6 +639,38 @@ func (r *MemberRepository) IsMember(ctx context.Context, serverID, userID string
 	return exists, nil
 }
 
+// FindTemporaryByUserID finds a user's temporary memberships.
+func (r *MemberRepository) FindTemporaryByUserID(ctx context.Context, userID string) ([]*server.Member, error) {
+	query := `
+		SELECT id, server_id, user_id, nickname, joined_at, communication_disabled_until,
+		       invite_code, is_temporary
+		FROM server_members
+		WHERE user_id = $1 AND is_temporary
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query temporary members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*server.Member
+	for rows.Next() {
+		var m server.Member
+		var nickname *string
+		if err := rows.Scan(
+			&m.ID, &m.ServerID, &m.UserID, &nickname, &m.JoinedAt, &m.CommunicationDisabledUntil,
+			&m.InviteCode, &m.IsTemporary,
+		); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		m.Nickname = derefString(nickname)
+		members = append(members, &m)
+	}
+
+	return members, rows.Err()
+}
+
 // ============================================================================
 // ROLE REPOSITORY
 // ============================================================================
@@ -741,6 +821,41 @@ func (r *RoleRepository) Create(ctx context.Context, role *server.Role) error {
 	return nil
 }
 
+// CreateAt creates a new role at its position, moving the server's other
+// non-default roles from that position on up by one in the same transaction.
+func (r *RoleRepository) CreateAt(ctx context.Context, role *server.Role) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	shift := `
+		UPDATE roles SET position = position + 1, updated_at = NOW()
+		WHERE server_id = $1 AND position >= $2 AND NOT is_default
+	`
+	if _, err := tx.Exec(ctx, shift, role.ServerID, role.Position); err != nil {
+		return fmt.Errorf("shift role positions: %w", err)
+	}
+
+	insert := `
+		INSERT INTO roles (id, server_id, name, color, position, permissions, 
+		                   is_default, is_mentionable, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err = tx.Exec(ctx, insert,
+		role.ID, role.ServerID, role.Name, role.Color, role.Position,
+		role.Permissions, role.IsDefault, role.IsMentionable, role.CreatedAt, role.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert role: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
 // Update updates an existing role.
 func (r *RoleRepository) Update(ctx context.Context, role *server.Role) error {
 	query := `
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"pink/internal/domain/history"
 	"pink/internal/domain/live"
 	"pink/internal/domain/user"
 )
@@ -35,29 +37,21 @@ func (r *StreamMessageRepository) Create(ctx context.Context, msg *live.ChatMess
 	return nil
 }
 
-// FindByStreamID returns recent messages for a stream with optional pagination.
-func (r *StreamMessageRepository) FindByStreamID(ctx context.Context, streamID string, limit int, before string) ([]*live.ChatMessage, error) {
-	query := `
-		SELECT sm.id, sm.stream_id, sm.user_id, sm.content, sm.created_at,
+// streamMessageSelect selects chat messages with their authors.
+const streamMessageSelect = `
+		SELECT m.id, m.stream_id, m.user_id, m.content, m.created_at,
 		       u.id, u.handle, u.display_name, u.avatar_gradient, u.is_verified
-		FROM stream_messages sm
-		JOIN users u ON sm.user_id = u.id
-		WHERE sm.stream_id = $1
-	`
-	args := []interface{}{streamID}
-
-	if before != "" {
-		query += ` AND sm.created_at < (SELECT created_at FROM stream_messages WHERE id = $2)`
-		args = append(args, before)
-	}
-
-	// Use limit + 1 for pagination/consistency but strictly just limit here based on interface
-	query += fmt.Sprintf(` ORDER BY sm.created_at DESC LIMIT %d`, limit)
+		FROM stream_messages m
+		JOIN users u ON m.user_id = u.id`
+
+// FindByStreamID returns the page of a stream's chat messages a cursor
+// selects, newest first.
+func (r *StreamMessageRepository) FindByStreamID(ctx context.Context, streamID string, cursor history.Cursor) ([]*live.ChatMessage, history.Page, error) {
+	return readHistory(ctx, r.pool, streamMessageSelect, "stream_messages", `m.stream_id = $1`, streamID, cursor, scanStreamMessages,
+		func(msg *live.ChatMessage) string { return msg.ID })
+}
 
-	rows, err := r.pool.Query(ctx, query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("query chat messages: %w", err)
-	}
+func scanStreamMessages(rows pgx.Rows) ([]*live.ChatMessage, error) {
 	defer rows.Close()
 
 	var messages []*live.ChatMessage
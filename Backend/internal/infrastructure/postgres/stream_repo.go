@@ -26,7 +26,7 @@ func (r *StreamRepository) FindByID(ctx context.Context, id string) (*live.Strea
 	query := `
 		SELECT s.id, s.user_id, s.title, s.description, s.category_id, s.thumbnail_url, 
 		       s.stream_key, s.status, s.viewer_count, s.is_nsfw, s.started_at, s.ended_at, s.created_at, s.updated_at,
-		       s.type, s.server_id, s.ingest_url, s.playback_url, s.max_quality,
+		       s.type, s.server_id, s.ingest_url, s.playback_url, s.max_quality, s.slowmode_seconds,
 		       u.id, u.handle, u.display_name, u.avatar_gradient, u.is_verified,
 		       c.id, c.name, c.slug
 		FROM streams s
@@ -43,7 +43,7 @@ func (r *StreamRepository) FindByID(ctx context.Context, id string) (*live.Strea
 	err := r.pool.QueryRow(ctx, query, id).Scan(
 		&stream.ID, &stream.UserID, &stream.Title, &stream.Description, &stream.CategoryID, &stream.ThumbnailURL,
 		&stream.StreamKey, &stream.Status, &stream.ViewerCount, &stream.IsNSFW, &stream.StartedAt, &stream.EndedAt, &stream.CreatedAt, &stream.UpdatedAt,
-		&stream.Type, &stream.ServerID, &stream.IngestURL, &stream.PlaybackURL, &stream.MaxQuality,
+		&stream.Type, &stream.ServerID, &stream.IngestURL, &stream.PlaybackURL, &stream.MaxQuality, &stream.SlowmodeSeconds,
 		&streamer.ID, &streamer.Handle, &streamer.DisplayName, &gradient, &streamer.IsVerified,
 		&catID, &catName, &catSlug,
 	)
@@ -108,7 +108,7 @@ func (r *StreamRepository) FindLive(ctx context.Context, cursor string, limit in
 	query := `
 		SELECT s.id, s.user_id, s.title, s.description, s.category_id, s.thumbnail_url, 
 		       s.stream_key, s.status, s.viewer_count, s.is_nsfw, s.started_at, s.ended_at, s.created_at, s.updated_at,
-		       s.type, s.server_id, s.ingest_url, s.playback_url, s.max_quality,
+		       s.type, s.server_id, s.ingest_url, s.playback_url, s.max_quality, s.slowmode_seconds,
 		       u.id, u.handle, u.display_name, u.avatar_gradient, u.is_verified,
 		       c.id, c.name, c.slug
 		FROM streams s
@@ -137,7 +137,7 @@ func (r *StreamRepository) FindByCategoryID(ctx context.Context, categoryID, cur
 	query := `
 		SELECT s.id, s.user_id, s.title, s.description, s.category_id, s.thumbnail_url, 
 		       s.stream_key, s.status, s.viewer_count, s.is_nsfw, s.started_at, s.ended_at, s.created_at, s.updated_at,
-		       s.type, s.server_id, s.ingest_url, s.playback_url, s.max_quality,
+		       s.type, s.server_id, s.ingest_url, s.playback_url, s.max_quality, s.slowmode_seconds,
 		       u.id, u.handle, u.display_name, u.avatar_gradient, u.is_verified,
 		       c.id, c.name, c.slug
 		FROM streams s
@@ -166,7 +166,7 @@ func (r *StreamRepository) FindByServerID(ctx context.Context, serverID, cursor
 	query := `
 		SELECT s.id, s.user_id, s.title, s.description, s.category_id, s.thumbnail_url, 
 		       s.stream_key, s.status, s.viewer_count, s.is_nsfw, s.started_at, s.ended_at, s.created_at, s.updated_at,
-		       s.type, s.server_id, s.ingest_url, s.playback_url, s.max_quality,
+		       s.type, s.server_id, s.ingest_url, s.playback_url, s.max_quality, s.slowmode_seconds,
 		       u.id, u.handle, u.display_name, u.avatar_gradient, u.is_verified,
 		       c.id, c.name, c.slug
 		FROM streams s
@@ -198,7 +198,7 @@ func (r *StreamRepository) scanStreams(rows pgx.Rows, limit int) ([]*live.Stream
 		err := rows.Scan(
 			&stream.ID, &stream.UserID, &stream.Title, &stream.Description, &stream.CategoryID, &stream.ThumbnailURL,
 			&stream.StreamKey, &stream.Status, &stream.ViewerCount, &stream.IsNSFW, &stream.StartedAt, &stream.EndedAt, &stream.CreatedAt, &stream.UpdatedAt,
-			&stream.Type, &stream.ServerID, &stream.IngestURL, &stream.PlaybackURL, &stream.MaxQuality,
+			&stream.Type, &stream.ServerID, &stream.IngestURL, &stream.PlaybackURL, &stream.MaxQuality, &stream.SlowmodeSeconds,
 			&streamer.ID, &streamer.Handle, &streamer.DisplayName, &gradient, &streamer.IsVerified,
 			&catID, &catName, &catSlug,
 		)
@@ -255,12 +255,12 @@ func (r *StreamRepository) Create(ctx context.Context, stream *live.Stream) erro
 func (r *StreamRepository) Update(ctx context.Context, stream *live.Stream) error {
 	query := `
 		UPDATE streams 
-		SET title = $2, description = $3, category_id = $4, thumbnail_url = $5, is_nsfw = $6, updated_at = NOW()
+		SET title = $2, description = $3, category_id = $4, thumbnail_url = $5, is_nsfw = $6, slowmode_seconds = $7, updated_at = NOW()
 		WHERE id = $1
 	`
 
 	result, err := r.pool.Exec(ctx, query,
-		stream.ID, stream.Title, stream.Description, stream.CategoryID, stream.ThumbnailURL, stream.IsNSFW,
+		stream.ID, stream.Title, stream.Description, stream.CategoryID, stream.ThumbnailURL, stream.IsNSFW, stream.SlowmodeSeconds,
 	)
 	if err != nil {
 		return fmt.Errorf("update stream: %w", err)
@@ -0,0 +1,314 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"pink/internal/domain/channel"
+)
+
+// threadCursorLayout formats the time half of a thread list cursor.
+const threadCursorLayout = "2006-01-02T15:04:05.000000Z"
+
+// ThreadRepository implements channel.ThreadRepository using PostgreSQL.
+type ThreadRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewThreadRepository creates a new ThreadRepository.
+func NewThreadRepository(pool *pgxpool.Pool) *ThreadRepository {
+	return &ThreadRepository{pool: pool}
+}
+
+const threadColumns = `id, channel_id, server_id, parent_message_id, owner_id, name, message_count, member_count,
+	is_archived, is_locked, auto_archive_minutes, last_message_at, archived_at, created_at, updated_at`
+
+func scanThread(row pgx.Row) (*channel.Thread, error) {
+	var t channel.Thread
+	var ownerID *string
+	var autoArchiveMinutes int
+	err := row.Scan(
+		&t.ID, &t.ChannelID, &t.ServerID, &t.ParentMessageID, &ownerID, &t.Name, &t.MessageCount, &t.MemberCount,
+		&t.IsArchived, &t.IsLocked, &autoArchiveMinutes, &t.LastMessageAt, &t.ArchivedAt, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	t.OwnerID = derefString(ownerID)
+	t.AutoArchiveAfter = time.Duration(autoArchiveMinutes) * time.Minute
+	return &t, nil
+}
+
+func scanThreads(rows pgx.Rows) ([]*channel.Thread, error) {
+	defer rows.Close()
+
+	var threads []*channel.Thread
+	for rows.Next() {
+		t, err := scanThread(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan thread: %w", err)
+		}
+		threads = append(threads, t)
+	}
+	return threads, rows.Err()
+}
+
+// FindByID finds a thread by its ID.
+func (r *ThreadRepository) FindByID(ctx context.Context, id string) (*channel.Thread, error) {
+	query := `SELECT ` + threadColumns + ` FROM threads WHERE id = $1`
+
+	t, err := scanThread(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, channel.ErrThreadNotFound
+		}
+		return nil, fmt.Errorf("query thread by id: %w", err)
+	}
+	return t, nil
+}
+
+// FindByChannelID lists a channel's active threads by latest activity, or its
+// archived threads by archive time, with pagination.
+func (r *ThreadRepository) FindByChannelID(ctx context.Context, channelID string, archived bool, cursor string, limit int) ([]*channel.Thread, string, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 20
+	}
+
+	sortColumn := "last_message_at"
+	if archived {
+		sortColumn = "archived_at"
+	}
+
+	args := []interface{}{channelID, archived, limit + 1}
+	query := `SELECT ` + threadColumns + ` FROM threads WHERE channel_id = $1 AND is_archived = $2`
+
+	if cursor != "" {
+		at, id, err := parseThreadCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		args = append(args, at, id)
+		query += ` AND (` + sortColumn + `, id) < ($4, $5)`
+	}
+
+	query += ` ORDER BY ` + sortColumn + ` DESC, id DESC LIMIT $3`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("query threads: %w", err)
+	}
+
+	threads, err := scanThreads(rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(threads) > limit {
+		last := threads[limit-1]
+		at := last.LastMessageAt
+		if archived && last.ArchivedAt != nil {
+			at = *last.ArchivedAt
+		}
+		nextCursor = at.UTC().Format(threadCursorLayout) + "|" + last.ID
+		threads = threads[:limit]
+	}
+
+	return threads, nextCursor, nil
+}
+
+// Create creates a new thread.
+func (r *ThreadRepository) Create(ctx context.Context, t *channel.Thread) error {
+	query := `
+		INSERT INTO threads (` + threadColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		t.ID, t.ChannelID, t.ServerID, t.ParentMessageID, t.OwnerID, t.Name, t.MessageCount, t.MemberCount,
+		t.IsArchived, t.IsLocked, int(t.AutoArchiveAfter/time.Minute), t.LastMessageAt, t.ArchivedAt, t.CreatedAt, t.UpdatedAt,
+	)
+	if err != nil {
+		// A message can only start one thread
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return channel.ErrThreadExists
+		}
+		return fmt.Errorf("insert thread: %w", err)
+	}
+	return nil
+}
+
+// Update updates a thread's name, state and auto-archive duration.
+func (r *ThreadRepository) Update(ctx context.Context, t *channel.Thread) error {
+	query := `
+		UPDATE threads
+		SET name = $2, is_archived = $3, is_locked = $4, auto_archive_minutes = $5, last_message_at = $6, archived_at = $7
+		WHERE id = $1
+	`
+
+	result, err := r.pool.Exec(ctx, query,
+		t.ID, t.Name, t.IsArchived, t.IsLocked, int(t.AutoArchiveAfter/time.Minute), t.LastMessageAt, t.ArchivedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update thread: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return channel.ErrThreadNotFound
+	}
+	return nil
+}
+
+// Delete deletes a thread. Its messages, members and read states cascade.
+func (r *ThreadRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM threads WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete thread: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return channel.ErrThreadNotFound
+	}
+	return nil
+}
+
+// RecordMessage counts a new message and moves the thread's activity time.
+func (r *ThreadRepository) RecordMessage(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE threads SET message_count = message_count + 1, last_message_at = $2 WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, at); err != nil {
+		return fmt.Errorf("record thread message: %w", err)
+	}
+	return nil
+}
+
+// ArchiveInactive archives every thread inactive past its auto-archive
+// duration and returns them.
+func (r *ThreadRepository) ArchiveInactive(ctx context.Context, now time.Time) ([]*channel.Thread, error) {
+	query := `
+		UPDATE threads
+		SET is_archived = TRUE, archived_at = $1
+		WHERE NOT is_archived
+		  AND last_message_at < $1 - make_interval(mins => auto_archive_minutes)
+		RETURNING ` + threadColumns
+
+	rows, err := r.pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, fmt.Errorf("archive inactive threads: %w", err)
+	}
+	return scanThreads(rows)
+}
+
+// AddMember adds a member, reporting whether they were not one already. The
+// member count is kept in the same statement.
+func (r *ThreadRepository) AddMember(ctx context.Context, m *channel.ThreadMember) (bool, error) {
+	query := `
+		WITH added AS (
+			INSERT INTO thread_members (thread_id, user_id, joined_at)
+			VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING
+			RETURNING thread_id
+		)
+		UPDATE threads SET member_count = member_count + 1
+		WHERE id IN (SELECT thread_id FROM added)
+	`
+
+	result, err := r.pool.Exec(ctx, query, m.ThreadID, m.UserID, m.JoinedAt)
+	if err != nil {
+		return false, fmt.Errorf("add thread member: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// RemoveMember removes a member, reporting whether they were one.
+func (r *ThreadRepository) RemoveMember(ctx context.Context, threadID, userID string) (bool, error) {
+	query := `
+		WITH removed AS (
+			DELETE FROM thread_members
+			WHERE thread_id = $1 AND user_id = $2
+			RETURNING thread_id
+		)
+		UPDATE threads SET member_count = GREATEST(member_count - 1, 0)
+		WHERE id IN (SELECT thread_id FROM removed)
+	`
+
+	result, err := r.pool.Exec(ctx, query, threadID, userID)
+	if err != nil {
+		return false, fmt.Errorf("remove thread member: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// FindMembers lists a thread's members in join order.
+func (r *ThreadRepository) FindMembers(ctx context.Context, threadID string) ([]*channel.ThreadMember, error) {
+	query := `SELECT thread_id, user_id, joined_at FROM thread_members WHERE thread_id = $1 ORDER BY joined_at`
+
+	rows, err := r.pool.Query(ctx, query, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("query thread members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*channel.ThreadMember
+	for rows.Next() {
+		var m channel.ThreadMember
+		if err := rows.Scan(&m.ThreadID, &m.UserID, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("scan thread member: %w", err)
+		}
+		members = append(members, &m)
+	}
+	return members, rows.Err()
+}
+
+// UpsertReadState records how far a user has read.
+func (r *ThreadRepository) UpsertReadState(ctx context.Context, rs *channel.ThreadReadState) error {
+	query := `
+		INSERT INTO thread_read_states (thread_id, user_id, last_read_message_id, last_read_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (thread_id, user_id)
+		DO UPDATE SET last_read_message_id = EXCLUDED.last_read_message_id, last_read_at = EXCLUDED.last_read_at
+	`
+
+	if _, err := r.pool.Exec(ctx, query, rs.ThreadID, rs.UserID, rs.LastReadMessageID, rs.LastReadAt); err != nil {
+		return fmt.Errorf("upsert thread read state: %w", err)
+	}
+	return nil
+}
+
+// FindReadState finds a user's read state, or nil if they have none.
+func (r *ThreadRepository) FindReadState(ctx context.Context, threadID, userID string) (*channel.ThreadReadState, error) {
+	query := `
+		SELECT thread_id, user_id, last_read_message_id, last_read_at
+		FROM thread_read_states
+		WHERE thread_id = $1 AND user_id = $2
+	`
+
+	var rs channel.ThreadReadState
+	err := r.pool.QueryRow(ctx, query, threadID, userID).Scan(&rs.ThreadID, &rs.UserID, &rs.LastReadMessageID, &rs.LastReadAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query thread read state: %w", err)
+	}
+	return &rs, nil
+}
+
+// parseThreadCursor splits a "<time>|<id>" cursor.
+func parseThreadCursor(cursor string) (time.Time, string, error) {
+	ts, id, ok := strings.Cut(cursor, "|")
+	if !ok || id == "" {
+		return time.Time{}, "", channel.ErrInvalidThread
+	}
+	at, err := time.Parse(threadCursorLayout, ts)
+	if err != nil {
+		return time.Time{}, "", channel.ErrInvalidThread
+	}
+	return at, id, nil
+}
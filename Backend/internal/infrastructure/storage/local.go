@@ -0,0 +1,36 @@
+// Package storage provides file storage for uploaded media.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"pink/internal/domain/media"
+)
+
+// LocalStorage implements media.Storage on the local filesystem, with each
+// user's uploads in their own directory under dir.
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates a new LocalStorage rooted at dir.
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
+
+// Path returns where a media record's file is stored.
+func (s *LocalStorage) Path(m *media.Media) string {
+	return filepath.Join(s.dir, m.UserID, m.Filename)
+}
+
+// Remove deletes a media record's file.
+func (s *LocalStorage) Remove(m *media.Media) error {
+	if err := os.Remove(s.Path(m)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove media file: %w", err)
+	}
+	return nil
+}
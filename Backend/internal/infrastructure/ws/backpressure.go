@@ -0,0 +1,145 @@
+package ws
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	wsDomain "pink/internal/domain/ws"
+)
+
+// SlowConsumerPolicy decides what happens when a client's send queue is full.
+type SlowConsumerPolicy string
+
+const (
+	// SlowConsumerDisconnect closes the connection with CloseResyncRequired
+	// as soon as an event cannot be queued.
+	SlowConsumerDisconnect SlowConsumerPolicy = "disconnect"
+
+	// SlowConsumerCoalesce keeps only the latest typing and presence event per
+	// user and target while the queue is full. Any other event that does not
+	// fit still disconnects the client.
+	SlowConsumerCoalesce SlowConsumerPolicy = "coalesce"
+)
+
+// IsValid checks if the policy is known.
+func (p SlowConsumerPolicy) IsValid() bool {
+	return p == SlowConsumerDisconnect || p == SlowConsumerCoalesce
+}
+
+// CloseResyncRequired is the close code sent to a client that fell too far
+// behind. Its session cannot be resumed; the client must reconnect and
+// refetch its state.
+const CloseResyncRequired = 4008
+
+const closeResyncReason = "slow consumer: resync required"
+
+// Options configures client queues and connection timeouts.
+type Options struct {
+	// SendBufferSize is the number of events queued per connection. Values
+	// not above the session replay size are raised to the default so a full
+	// replay always fits.
+	SendBufferSize int
+
+	// SlowConsumerPolicy applies when a connection's queue is full.
+	SlowConsumerPolicy SlowConsumerPolicy
+
+	// WriteTimeout bounds every write to the socket.
+	WriteTimeout time.Duration
+
+	// PongTimeout is how long a connection may stay silent before it is
+	// considered dead. Pings are sent at half this interval.
+	PongTimeout time.Duration
+}
+
+// DefaultOptions returns the options used when none are configured.
+func DefaultOptions() Options {
+	return Options{
+		SendBufferSize:     256,
+		SlowConsumerPolicy: SlowConsumerDisconnect,
+		WriteTimeout:       10 * time.Second,
+		PongTimeout:        60 * time.Second,
+	}
+}
+
+// withDefaults fills in unset or invalid fields.
+func (o Options) withDefaults() Options {
+	d := DefaultOptions()
+	if o.SendBufferSize <= sessionReplaySize {
+		if o.SendBufferSize > 0 {
+			slog.Warn("WebSocket send buffer too small for session replay, using default",
+				slog.Int("configured", o.SendBufferSize),
+				slog.Int("replaySize", sessionReplaySize),
+				slog.Int("default", d.SendBufferSize),
+			)
+		}
+		o.SendBufferSize = d.SendBufferSize
+	}
+	if !o.SlowConsumerPolicy.IsValid() {
+		o.SlowConsumerPolicy = d.SlowConsumerPolicy
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = d.WriteTimeout
+	}
+	if o.PongTimeout <= 0 {
+		o.PongTimeout = d.PongTimeout
+	}
+	return o
+}
+
+// PingInterval returns how often pings are sent.
+func (o Options) PingInterval() time.Duration {
+	return o.PongTimeout / 2
+}
+
+// ClientStats are the queue metrics of a single connection.
+type ClientStats struct {
+	ClientID      string `json:"clientId"`
+	UserID        string `json:"userId"`
+	QueueDepth    int    `json:"queueDepth"`
+	QueueCapacity int    `json:"queueCapacity"`
+	MaxQueueDepth int    `json:"maxQueueDepth"`
+	Pending       int    `json:"pending"`
+	Delivered     int64  `json:"delivered"`
+	Coalesced     int64  `json:"coalesced"`
+	Dropped       int64  `json:"dropped"`
+	Detached      bool   `json:"detached"`
+}
+
+// HubStats summarizes the connections on this node. It only holds totals:
+// client IDs double as session IDs for resuming, so they are never exposed.
+type HubStats struct {
+	Policy        SlowConsumerPolicy `json:"policy"`
+	Connections   int                `json:"connections"`
+	Detached      int                `json:"detached"`
+	MaxQueueDepth int                `json:"maxQueueDepth"`
+	Pending       int                `json:"pending"`
+	Delivered     int64              `json:"delivered"`
+	Coalesced     int64              `json:"coalesced"`
+	Dropped       int64              `json:"dropped"`
+}
+
+// coalesceKey returns the key under which an event may replace an older
+// queued event, or false if the event must not be collapsed.
+func coalesceKey(message []byte) (string, bool) {
+	var msg struct {
+		Type wsDomain.EventType `json:"type"`
+		Data struct {
+			UserID         string `json:"userId"`
+			ChannelID      string `json:"channelId"`
+			ConversationID string `json:"conversationId"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil || msg.Data.UserID == "" {
+		return "", false
+	}
+
+	switch msg.Type {
+	case wsDomain.EventTypingStart, wsDomain.EventTypingStop:
+		return "typing:" + msg.Data.ConversationID + ":" + msg.Data.ChannelID + ":" + msg.Data.UserID, true
+	case wsDomain.EventUserOnline, wsDomain.EventUserOffline, wsDomain.EventPresenceUpdate:
+		return "presence:" + msg.Data.UserID, true
+	default:
+		return "", false
+	}
+}
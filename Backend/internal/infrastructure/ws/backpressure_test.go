@@ -0,0 +1,146 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClient(policy SlowConsumerPolicy, size int) *Client {
+	return newClient("c1", "u1", nil, Options{SendBufferSize: size, SlowConsumerPolicy: policy})
+}
+
+func drain(c *Client) []string {
+	var types []string
+	for len(c.Send) > 0 {
+		var msg struct {
+			Type string `json:"type"`
+		}
+		_ = json.Unmarshal(<-c.Send, &msg)
+		types = append(types, msg.Type)
+	}
+	return types
+}
+
+func TestCoalesceKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+		ok      bool
+	}{
+		{name: "typing start", message: `{"type":"typing_start","data":{"channelId":"ch1","userId":"u2"}}`, want: "typing::ch1:u2", ok: true},
+		{name: "typing stop shares key", message: `{"type":"typing_stop","data":{"channelId":"ch1","userId":"u2"}}`, want: "typing::ch1:u2", ok: true},
+		{name: "presence", message: `{"type":"presence_update","data":{"userId":"u2"}}`, want: "presence:u2", ok: true},
+		{name: "offline shares presence key", message: `{"type":"user_offline","data":{"userId":"u2"}}`, want: "presence:u2", ok: true},
+		{name: "channel message", message: `{"type":"channel_message","data":{"channelId":"ch1"}}`, ok: false},
+		{name: "invalid json", message: `nope`, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ok := coalesceKey([]byte(tt.message))
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, key)
+		})
+	}
+}
+
+func TestOptions_WithDefaults(t *testing.T) {
+	opts := Options{SendBufferSize: 10, SlowConsumerPolicy: "bogus"}.withDefaults()
+
+	assert.Equal(t, DefaultOptions().SendBufferSize, opts.SendBufferSize)
+	assert.Equal(t, SlowConsumerDisconnect, opts.SlowConsumerPolicy)
+	assert.Positive(t, opts.WriteTimeout)
+	assert.Equal(t, opts.PongTimeout/2, opts.PingInterval())
+}
+
+func TestClient_Deliver_DisconnectPolicy(t *testing.T) {
+	c := testClient(SlowConsumerDisconnect, 2)
+
+	c.Deliver([]byte(`{"type":"a"}`))
+	c.Deliver([]byte(`{"type":"b"}`))
+	c.Deliver([]byte(`{"type":"c"}`))
+
+	code, reason := c.CloseCode()
+	assert.Equal(t, CloseResyncRequired, code)
+	assert.NotEmpty(t, reason)
+
+	// Queued events are discarded and the channel is closed
+	_, ok := <-c.Send
+	assert.False(t, ok)
+
+	stats := c.Stats()
+	assert.Equal(t, int64(1), stats.Dropped)
+	assert.Equal(t, 2, stats.MaxQueueDepth)
+	assert.True(t, stats.Detached)
+
+	// The session cannot be resumed
+	_, ok = c.session.since(0)
+	assert.False(t, ok)
+}
+
+func TestClient_Deliver_CoalescePolicy(t *testing.T) {
+	c := testClient(SlowConsumerCoalesce, 2)
+
+	c.Deliver([]byte(`{"type":"channel_message","data":{"channelId":"ch1"}}`))
+	c.Deliver([]byte(`{"type":"channel_message","data":{"channelId":"ch1"}}`))
+	c.Deliver([]byte(`{"type":"typing_start","data":{"channelId":"ch1","userId":"u2"}}`))
+	c.Deliver([]byte(`{"type":"presence_update","data":{"userId":"u3"}}`))
+	c.Deliver([]byte(`{"type":"typing_stop","data":{"channelId":"ch1","userId":"u2"}}`))
+
+	code, _ := c.CloseCode()
+	assert.Zero(t, code)
+
+	stats := c.Stats()
+	assert.Equal(t, 2, stats.Pending)
+	assert.Equal(t, int64(1), stats.Coalesced)
+
+	// Pending events are flushed in order once the queue drains, and only the
+	// latest typing event survives
+	var types []string
+	types = append(types, drain(c)...)
+	for c.Stats().Pending > 0 {
+		c.FlushPending()
+		types = append(types, drain(c)...)
+	}
+	assert.Equal(t, []string{"channel_message", "channel_message", "presence_update", "typing_stop"}, types)
+}
+
+func TestClient_Deliver_CoalescePolicyDisconnectsOnOtherEvents(t *testing.T) {
+	c := testClient(SlowConsumerCoalesce, 1)
+
+	c.Deliver([]byte(`{"type":"channel_message","data":{"channelId":"ch1"}}`))
+	c.Deliver([]byte(`{"type":"typing_start","data":{"channelId":"ch1","userId":"u2"}}`))
+	c.Deliver([]byte(`{"type":"channel_message","data":{"channelId":"ch1"}}`))
+
+	code, _ := c.CloseCode()
+	require.Equal(t, CloseResyncRequired, code)
+	assert.Equal(t, int64(1), c.Stats().Dropped)
+	assert.Zero(t, c.Stats().Pending)
+}
+
+func TestHub_Stats_OnlyTotals(t *testing.T) {
+	h := NewHub(nil, Options{SendBufferSize: 4})
+	c1 := newClient("c1", "u1", nil, h.options)
+	c2 := newClient("c2", "u2", nil, h.options)
+	h.clients[c1.ID] = c1
+	h.clients[c2.ID] = c2
+
+	c1.Deliver([]byte(`{"type":"a"}`))
+	c1.Deliver([]byte(`{"type":"b"}`))
+	c2.Deliver([]byte(`{"type":"c"}`))
+
+	stats := h.Stats()
+	assert.Equal(t, 2, stats.Connections)
+	assert.Equal(t, 2, stats.MaxQueueDepth)
+	assert.Equal(t, int64(3), stats.Delivered)
+
+	// Client IDs resume sessions and must never leave the node
+	data, err := json.Marshal(stats)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "c1")
+	assert.NotContains(t, string(data), "u1")
+}
@@ -0,0 +1,276 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"pink/internal/pkg/id"
+)
+
+const (
+	clusterEventsChannel = "xcord:ws:events"
+	clusterNodesKey      = "xcord:ws:nodes"
+
+	clusterHeartbeatInterval = 10 * time.Second
+	clusterNodeTTL           = 30 * time.Second
+)
+
+// Delivery targets carried in a cluster envelope.
+const (
+	targetSubscription = "sub"
+	targetUser         = "user"
+
+	targetRevalidateUser         = "revalidate_user"
+	targetRevalidateSubscription = "revalidate_sub"
+)
+
+// clusterEnvelope wraps an outgoing WebSocket payload so other nodes know
+// which of their local clients should receive it.
+type clusterEnvelope struct {
+	Origin  string          `json:"origin"`
+	Target  string          `json:"target"`
+	Key     string          `json:"key,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Cluster fans hub events out to other API instances over Redis pub/sub and
+// tracks which users are connected to which node.
+//
+// Every node publishes the events it broadcasts and delivers the events
+// published by other nodes to its own local clients. Presence is kept in one
+// Redis set per node, changed only as users connect and disconnect. The
+// heartbeat keeps the set alive so that a crashed node's users expire on
+// their own.
+type Cluster struct {
+	redis  *redis.Client
+	nodeID string
+	hub    *Hub
+}
+
+// NewCluster creates a new Cluster backed by the given Redis client.
+func NewCluster(redisClient *redis.Client) *Cluster {
+	return &Cluster{
+		redis:  redisClient,
+		nodeID: id.Generate("node"),
+	}
+}
+
+// NodeID returns the unique ID of this API instance.
+func (c *Cluster) NodeID() string {
+	return c.nodeID
+}
+
+func (c *Cluster) nodeUsersKey(nodeID string) string {
+	return "xcord:ws:node:" + nodeID + ":users"
+}
+
+// run subscribes to cluster events and keeps this node's heartbeat alive
+// until ctx is cancelled.
+func (c *Cluster) run(ctx context.Context) {
+	pubsub := c.redis.Subscribe(ctx, clusterEventsChannel)
+	defer pubsub.Close()
+
+	c.heartbeat(ctx)
+	ticker := time.NewTicker(clusterHeartbeatInterval)
+	defer ticker.Stop()
+
+	events := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			c.leave()
+			return
+		case <-ticker.C:
+			c.heartbeat(ctx)
+		case msg, ok := <-events:
+			if !ok {
+				return
+			}
+			c.handleEvent(msg.Payload)
+		}
+	}
+}
+
+func (c *Cluster) handleEvent(raw string) {
+	var env clusterEnvelope
+	if err := json.Unmarshal([]byte(raw), &env); err != nil {
+		slog.Warn("invalid cluster event", slog.Any("error", err))
+		return
+	}
+
+	// Events from this node were already delivered locally.
+	if env.Origin == c.nodeID {
+		return
+	}
+
+	switch env.Target {
+	case targetSubscription:
+		c.hub.deliverToSubscription(env.Key, env.Payload)
+	case targetUser:
+		c.hub.deliverToUser(env.Key, env.Payload)
+	case targetRevalidateUser:
+		go c.hub.revalidateUser(env.Key)
+	case targetRevalidateSubscription:
+		go c.hub.revalidateSubscription(env.Key)
+	}
+}
+
+// publish sends an event to every other node.
+func (c *Cluster) publish(target, key string, payload []byte) {
+	data, err := json.Marshal(clusterEnvelope{
+		Origin:  c.nodeID,
+		Target:  target,
+		Key:     key,
+		Payload: payload,
+	})
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := c.redis.Publish(ctx, clusterEventsChannel, data).Err(); err != nil {
+		slog.Warn("cluster publish failed", slog.Any("error", err), slog.String("target", target))
+	}
+}
+
+// heartbeat extends the life of this node's presence set and marks the node
+// as alive. The set is only rebuilt if it was lost, such as when Redis
+// restarted.
+func (c *Cluster) heartbeat(ctx context.Context) {
+	var expire *redis.BoolCmd
+	_, err := c.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		expire = pipe.Expire(ctx, c.nodeUsersKey(c.nodeID), clusterNodeTTL)
+		pipe.ZAdd(ctx, clusterNodesKey, redis.Z{Score: float64(time.Now().Unix()), Member: c.nodeID})
+		return nil
+	})
+	if err != nil {
+		slog.Warn("cluster heartbeat failed", slog.Any("error", err))
+	} else if !expire.Val() {
+		c.restoreUsers(ctx)
+	}
+
+	// Prune nodes that stopped sending heartbeats.
+	cutoff := strconv.FormatInt(time.Now().Add(-clusterNodeTTL).Unix(), 10)
+	c.redis.ZRemRangeByScore(ctx, clusterNodesKey, "-inf", "("+cutoff)
+}
+
+// restoreUsers adds this node's local users back to its missing presence set.
+// Users who disconnect meanwhile are taken out again, so the set never keeps
+// someone who left; anyone connecting meanwhile is added by addUser.
+func (c *Cluster) restoreUsers(ctx context.Context) {
+	users := c.hub.localOnlineUsers()
+	if len(users) == 0 {
+		return
+	}
+
+	key := c.nodeUsersKey(c.nodeID)
+	members := make([]interface{}, len(users))
+	for i, u := range users {
+		members[i] = u
+	}
+	_, err := c.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, members...)
+		pipe.Expire(ctx, key, clusterNodeTTL)
+		return nil
+	})
+	if err != nil {
+		slog.Warn("cluster presence restore failed", slog.Any("error", err))
+		return
+	}
+
+	for _, userID := range users {
+		if !c.hub.isLocallyOnline(userID) {
+			c.removeUser(ctx, userID)
+		}
+	}
+}
+
+// leave removes this node from the cluster on shutdown.
+func (c *Cluster) leave() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	c.redis.ZRem(ctx, clusterNodesKey, c.nodeID)
+	c.redis.Del(ctx, c.nodeUsersKey(c.nodeID))
+}
+
+// addUser records that a user has a connection on this node.
+func (c *Cluster) addUser(ctx context.Context, userID string) {
+	key := c.nodeUsersKey(c.nodeID)
+	_, err := c.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, key, userID)
+		pipe.Expire(ctx, key, clusterNodeTTL)
+		return nil
+	})
+	if err != nil {
+		slog.Warn("cluster presence add failed", slog.Any("error", err), slog.String("userId", userID))
+	}
+}
+
+// removeUser records that a user no longer has connections on this node.
+func (c *Cluster) removeUser(ctx context.Context, userID string) {
+	if err := c.redis.SRem(ctx, c.nodeUsersKey(c.nodeID), userID).Err(); err != nil {
+		slog.Warn("cluster presence remove failed", slog.Any("error", err), slog.String("userId", userID))
+	}
+}
+
+// liveNodes returns the IDs of nodes with a recent heartbeat.
+func (c *Cluster) liveNodes(ctx context.Context) ([]string, error) {
+	cutoff := strconv.FormatInt(time.Now().Add(-clusterNodeTTL).Unix(), 10)
+	return c.redis.ZRangeByScore(ctx, clusterNodesKey, &redis.ZRangeBy{Min: cutoff, Max: "+inf"}).Result()
+}
+
+// isOnlineElsewhere reports whether a user is connected to any other node.
+// An error means the answer is unknown, not that the user is offline.
+func (c *Cluster) isOnlineElsewhere(ctx context.Context, userID string) (bool, error) {
+	nodes, err := c.liveNodes(ctx)
+	if err != nil {
+		return false, fmt.Errorf("list live nodes: %w", err)
+	}
+
+	cmds := make([]*redis.BoolCmd, 0, len(nodes))
+	_, err = c.redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, node := range nodes {
+			if node == c.nodeID {
+				continue
+			}
+			cmds = append(cmds, pipe.SIsMember(ctx, c.nodeUsersKey(node), userID))
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return false, fmt.Errorf("check node presence: %w", err)
+	}
+
+	for _, cmd := range cmds {
+		if cmd.Val() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// onlineUsers returns every user connected to any live node.
+func (c *Cluster) onlineUsers(ctx context.Context) ([]string, error) {
+	nodes, err := c.liveNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return []string{}, nil
+	}
+
+	keys := make([]string, len(nodes))
+	for i, node := range nodes {
+		keys[i] = c.nodeUsersKey(node)
+	}
+	return c.redis.SUnion(ctx, keys...).Result()
+}
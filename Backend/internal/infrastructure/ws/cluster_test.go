@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clusterHubs starts n hubs sharing one in-memory Redis.
+func clusterHubs(t *testing.T, n int) (*miniredis.Miniredis, []*Hub) {
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	hubs := make([]*Hub, n)
+	for i := range hubs {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		hubs[i] = NewHub(client, Options{})
+		go hubs[i].Run(ctx)
+	}
+	return mr, hubs
+}
+
+func TestCluster_FanOut(t *testing.T) {
+	_, hubs := clusterHubs(t, 2)
+	c := newClient("c1", "u1", nil, hubs[1].options)
+	hubs[1].registerClient(c)
+
+	// Keep sending until the second hub's subscription is up
+	require.Eventually(t, func() bool {
+		hubs[0].BroadcastToUser("u1", []byte(`{"type":"ping"}`))
+		return len(c.Send) > 0
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Equal(t, []string{"ping"}, drain(c)[:1])
+}
+
+func TestCluster_AddAndRemoveUser(t *testing.T) {
+	mr, hubs := clusterHubs(t, 1)
+	cluster := hubs[0].cluster
+	ctx := context.Background()
+	key := cluster.nodeUsersKey(cluster.nodeID)
+
+	cluster.addUser(ctx, "u1")
+	members, err := mr.SMembers(key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, members)
+	assert.Equal(t, clusterNodeTTL, mr.TTL(key))
+
+	cluster.removeUser(ctx, "u1")
+	assert.False(t, mr.Exists(key))
+}
+
+func TestCluster_HeartbeatKeepsPresenceChanges(t *testing.T) {
+	mr, hubs := clusterHubs(t, 1)
+	cluster := hubs[0].cluster
+	ctx := context.Background()
+	key := cluster.nodeUsersKey(cluster.nodeID)
+
+	// Presence recorded while the hub's local state did not show it yet
+	cluster.addUser(ctx, "u1")
+	cluster.heartbeat(ctx)
+
+	ok, err := mr.SIsMember(key, "u1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestCluster_HeartbeatRestoresLostPresence(t *testing.T) {
+	mr, hubs := clusterHubs(t, 1)
+	h := hubs[0]
+	ctx := context.Background()
+	key := h.cluster.nodeUsersKey(h.cluster.nodeID)
+
+	h.mu.Lock()
+	h.onlineUsers["u1"] = true
+	h.mu.Unlock()
+	mr.FlushAll()
+
+	h.cluster.heartbeat(ctx)
+
+	members, err := mr.SMembers(key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, members)
+}
+
+func TestCluster_IsOnlineElsewhere(t *testing.T) {
+	mr, hubs := clusterHubs(t, 2)
+	a, b := hubs[0].cluster, hubs[1].cluster
+	ctx := context.Background()
+
+	b.heartbeat(ctx)
+	b.addUser(ctx, "u1")
+	a.addUser(ctx, "u2")
+
+	online, err := a.isOnlineElsewhere(ctx, "u1")
+	require.NoError(t, err)
+	assert.True(t, online)
+
+	online, err = a.isOnlineElsewhere(ctx, "u2")
+	require.NoError(t, err)
+	assert.False(t, online, "connections on the asking node do not count")
+
+	mr.Close()
+	_, err = a.isOnlineElsewhere(ctx, "u1")
+	assert.Error(t, err, "an unreachable Redis is not the same as offline")
+}
+
+func TestCluster_ResumeOnlyOnOwningNode(t *testing.T) {
+	_, hubs := clusterHubs(t, 2)
+	c := newClient("s1", "u1", nil, hubs[0].options)
+	hubs[0].registerClient(c)
+	hubs[0].unregisterClient(c)
+
+	// Sessions are not shared: another node rejects the resume
+	_, ok := hubs[1].Resume(newClient("s1", "u1", nil, hubs[1].options), 0)
+	assert.False(t, ok)
+
+	_, ok = hubs[0].Resume(newClient("s1", "u1", nil, hubs[0].options), 0)
+	assert.True(t, ok)
+}
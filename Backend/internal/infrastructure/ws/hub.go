@@ -2,10 +2,15 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/gofiber/contrib/websocket"
+	"github.com/redis/go-redis/v9"
 
 	wsDomain "pink/internal/domain/ws"
 )
@@ -18,17 +23,212 @@ type Client struct {
 	Subscriptions map[string]bool // subscription key -> subscribed
 	Send          chan []byte
 	mu            sync.RWMutex
+
+	// Sequence counter and replay buffer, kept across resumes
+	session *session
+
+	// Set once the connection is gone; events are only buffered for replay
+	detached bool
+
+	// What to do when Send is full
+	policy SlowConsumerPolicy
+
+	// Coalesced events waiting for room in Send, in delivery order
+	pending      map[string][]byte
+	pendingOrder []string
+
+	// Close code sent by the write pump, set when the client must resync
+	closeCode int
+
+	// Queue metrics
+	maxDepth  int
+	delivered int64
+	coalesced int64
+	dropped   int64
 }
 
-// NewClient creates a new WebSocket client.
+// NewClient creates a new WebSocket client with the default options.
+// The client ID doubles as the resumable session ID.
 func NewClient(id, userID string, conn *websocket.Conn) *Client {
+	return newClient(id, userID, conn, DefaultOptions())
+}
+
+func newClient(id, userID string, conn *websocket.Conn, opts Options) *Client {
 	return &Client{
 		ID:            id,
 		UserID:        userID,
 		Conn:          conn,
 		Subscriptions: make(map[string]bool),
-		Send:          make(chan []byte, 256),
+		Send:          make(chan []byte, opts.SendBufferSize),
+		session:       newSession(),
+		policy:        opts.SlowConsumerPolicy,
+		pending:       make(map[string][]byte),
+	}
+}
+
+// Deliver stamps a message with the next session sequence number, records it
+// for replay and queues it for sending. Messages to a detached client are
+// only recorded. When the queue is full the client's slow consumer policy
+// applies.
+func (c *Client) Deliver(message []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	framed := c.session.record(message)
+	if c.detached {
+		return
+	}
+
+	// Coalesced events go first so sequence numbers stay in order
+	c.flushPendingLocked()
+	if len(c.pending) == 0 {
+		select {
+		case c.Send <- framed:
+			c.delivered++
+			c.trackDepthLocked()
+			return
+		default:
+		}
+	}
+
+	c.overflowLocked(message, framed)
+}
+
+// FlushPending moves coalesced events into the send queue as far as it has
+// room. The write pump calls it whenever the queue drains.
+func (c *Client) FlushPending() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.detached {
+		c.flushPendingLocked()
+	}
+}
+
+// CloseCode returns the close code and reason the connection should be
+// closed with, or zero if it closed normally.
+func (c *Client) CloseCode() (int, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closeCode == CloseResyncRequired {
+		return c.closeCode, closeResyncReason
+	}
+	return c.closeCode, ""
+}
+
+// Stats returns the client's queue metrics.
+func (c *Client) Stats() ClientStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return ClientStats{
+		ClientID:      c.ID,
+		UserID:        c.UserID,
+		QueueDepth:    len(c.Send),
+		QueueCapacity: cap(c.Send),
+		MaxQueueDepth: c.maxDepth,
+		Pending:       len(c.pending),
+		Delivered:     c.delivered,
+		Coalesced:     c.coalesced,
+		Dropped:       c.dropped,
+		Detached:      c.detached,
+	}
+}
+
+// needsResync reports whether the client was disconnected for falling behind.
+func (c *Client) needsResync() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.closeCode == CloseResyncRequired
+}
+
+func (c *Client) flushPendingLocked() {
+	for len(c.pendingOrder) > 0 {
+		key := c.pendingOrder[0]
+		select {
+		case c.Send <- c.pending[key]:
+			c.delivered++
+			c.trackDepthLocked()
+			delete(c.pending, key)
+			c.pendingOrder = c.pendingOrder[1:]
+		default:
+			return
+		}
+	}
+}
+
+// overflowLocked handles an event that did not fit in the send queue.
+func (c *Client) overflowLocked(message, framed []byte) {
+	if c.policy == SlowConsumerCoalesce {
+		if key, ok := coalesceKey(message); ok {
+			_, exists := c.pending[key]
+			if exists {
+				// The newer event replaces the queued one and moves to the back
+				c.coalesced++
+				for i, k := range c.pendingOrder {
+					if k == key {
+						c.pendingOrder = append(c.pendingOrder[:i], c.pendingOrder[i+1:]...)
+						break
+					}
+				}
+			}
+			if exists || len(c.pending) < cap(c.Send) {
+				c.pending[key] = framed
+				c.pendingOrder = append(c.pendingOrder, key)
+				return
+			}
+		}
 	}
+
+	c.dropped++
+	c.requireResyncLocked()
+}
+
+// requireResyncLocked drops everything queued and closes the send channel so
+// the write pump closes the connection with CloseResyncRequired. The session
+// can no longer be resumed.
+func (c *Client) requireResyncLocked() {
+	slog.Warn("disconnecting slow WebSocket consumer",
+		slog.String("clientId", c.ID),
+		slog.String("userId", c.UserID),
+		slog.Int("queueDepth", len(c.Send)),
+		slog.Int64("dropped", c.dropped),
+	)
+
+	c.closeCode = CloseResyncRequired
+	c.session.invalidate()
+	for len(c.Send) > 0 {
+		select {
+		case <-c.Send:
+		default:
+		}
+	}
+	c.detachLocked()
+}
+
+func (c *Client) trackDepthLocked() {
+	if depth := len(c.Send); depth > c.maxDepth {
+		c.maxDepth = depth
+	}
+}
+
+// detach closes the send channel and keeps the session for a later resume.
+func (c *Client) detach() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.detachLocked()
+}
+
+func (c *Client) detachLocked() {
+	if c.detached {
+		return
+	}
+	c.detached = true
+	c.pending = make(map[string][]byte)
+	c.pendingOrder = nil
+	c.session.markDetached()
+	close(c.Send)
 }
 
 // Subscribe adds a subscription.
@@ -52,6 +252,18 @@ func (c *Client) IsSubscribed(key string) bool {
 	return c.Subscriptions[key]
 }
 
+// SubscriptionKeys returns a snapshot of the client's subscription keys.
+func (c *Client) SubscriptionKeys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.Subscriptions))
+	for key := range c.Subscriptions {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 // Hub maintains the set of active clients and broadcasts messages.
 type Hub struct {
 	// Registered clients by client ID
@@ -72,13 +284,29 @@ type Hub struct {
 	// Unregister requests
 	unregister chan *Client
 
+	// Cluster fan-out, nil when running as a single node
+	cluster *Cluster
+
+	// Re-checks subscriptions when a user's access may have changed
+	authorizer wsDomain.SubscriptionAuthorizer
+
+	// Notified when a user comes online or goes offline cluster-wide
+	presenceListener func(userID string, online bool)
+
+	// Client queue and timeout settings
+	options Options
+
 	// Mutex for thread safety
 	mu sync.RWMutex
 }
 
 // NewHub creates a new Hub.
-func NewHub() *Hub {
-	return &Hub{
+// When redisClient is non-nil, broadcasts and presence are shared with every
+// other API instance connected to the same Redis. Unset options fall back to
+// DefaultOptions.
+func NewHub(redisClient *redis.Client, opts Options) *Hub {
+	h := &Hub{
+		options:       opts.withDefaults(),
 		clients:       make(map[string]*Client),
 		userClients:   make(map[string]map[string]*Client),
 		subscriptions: make(map[string]map[string]bool),
@@ -86,20 +314,84 @@ func NewHub() *Hub {
 		register:      make(chan *Client),
 		unregister:    make(chan *Client),
 	}
+	if redisClient != nil {
+		h.cluster = NewCluster(redisClient)
+		h.cluster.hub = h
+	}
+	return h
 }
 
 // Run starts the hub's event loop.
-func (h *Hub) Run() {
+func (h *Hub) Run(ctx context.Context) {
+	if h.cluster != nil {
+		go h.cluster.run(ctx)
+	}
+
+	sweep := time.NewTicker(sessionSweepInterval)
+	defer sweep.Stop()
+
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case client := <-h.register:
 			h.registerClient(client)
 		case client := <-h.unregister:
 			h.unregisterClient(client)
+		case now := <-sweep.C:
+			h.removeExpiredSessions(now)
 		}
 	}
 }
 
+// SetAuthorizer sets the authorizer used to revalidate subscriptions.
+func (h *Hub) SetAuthorizer(authorizer wsDomain.SubscriptionAuthorizer) {
+	h.authorizer = authorizer
+}
+
+// SetPresenceListener sets the function called when a user's first connection
+// in the cluster opens or their last one closes. The listener decides who
+// receives presence events. A last local connection closing is not reported
+// while other nodes' connections cannot be checked.
+func (h *Hub) SetPresenceListener(listener func(userID string, online bool)) {
+	h.presenceListener = listener
+}
+
+// Options returns the hub's client options.
+func (h *Hub) Options() Options {
+	return h.options
+}
+
+// NewClient creates a client using the hub's queue size and slow consumer
+// policy. The client ID doubles as the resumable session ID.
+func (h *Hub) NewClient(id, userID string, conn *websocket.Conn) *Client {
+	return newClient(id, userID, conn, h.options)
+}
+
+// Stats returns queue metrics totalled over the connections on this node.
+func (h *Hub) Stats() HubStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := HubStats{Policy: h.options.SlowConsumerPolicy}
+	for _, client := range h.clients {
+		cs := client.Stats()
+		if cs.Detached {
+			stats.Detached++
+		} else {
+			stats.Connections++
+		}
+		if cs.MaxQueueDepth > stats.MaxQueueDepth {
+			stats.MaxQueueDepth = cs.MaxQueueDepth
+		}
+		stats.Pending += cs.Pending
+		stats.Delivered += cs.Delivered
+		stats.Coalesced += cs.Coalesced
+		stats.Dropped += cs.Dropped
+	}
+	return stats
+}
+
 // Register registers a new client.
 func (h *Hub) Register(client *Client) {
 	h.register <- client
@@ -136,42 +428,128 @@ func (h *Hub) registerClient(client *Client) {
 
 	// Broadcast online status if was offline
 	if wasOffline {
-		go h.broadcastPresence(client.UserID, true)
+		go h.userConnected(client.UserID)
 	}
 }
 
+// unregisterClient detaches a client whose connection closed. Its
+// subscriptions stay registered so events keep being buffered until the
+// session is resumed or expires. Clients disconnected for falling behind
+// cannot resume and are removed right away.
 func (h *Hub) unregisterClient(client *Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	if _, ok := h.clients[client.ID]; !ok {
+	// Ignore stale connections whose session was already resumed
+	if current, ok := h.clients[client.ID]; !ok || current != client {
 		return
 	}
 
-	// Remove from subscriptions
-	for key := range client.Subscriptions {
-		if h.subscriptions[key] != nil {
-			delete(h.subscriptions[key], client.ID)
-			if len(h.subscriptions[key]) == 0 {
-				delete(h.subscriptions, key)
-			}
-		}
+	h.removeUserClient(client)
+	client.detach()
+
+	if client.needsResync() {
+		h.removeClient(client)
 	}
+}
 
-	// Remove from user clients
+// removeUserClient removes a client from its user's connections and marks the
+// user offline when it was the last one. Callers must hold h.mu.
+func (h *Hub) removeUserClient(client *Client) {
 	if h.userClients[client.UserID] != nil {
 		delete(h.userClients[client.UserID], client.ID)
 		if len(h.userClients[client.UserID]) == 0 {
 			delete(h.userClients, client.UserID)
 			// Mark user as offline
 			delete(h.onlineUsers, client.UserID)
-			go h.broadcastPresence(client.UserID, false)
+			go h.userDisconnected(client.UserID)
 		}
 	}
+}
+
+// removeExpiredSessions drops detached clients that were not resumed in time.
+func (h *Hub) removeExpiredSessions(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	// Remove client
+	for _, client := range h.clients {
+		if client.session.expired(now) {
+			h.removeClient(client)
+		}
+	}
+}
+
+// removeClient drops a detached client and its subscriptions. Callers must
+// hold h.mu.
+func (h *Hub) removeClient(client *Client) {
+	for _, key := range client.SubscriptionKeys() {
+		if h.subscriptions[key] != nil {
+			delete(h.subscriptions[key], client.ID)
+			if len(h.subscriptions[key]) == 0 {
+				delete(h.subscriptions, key)
+			}
+		}
+	}
 	delete(h.clients, client.ID)
-	close(client.Send)
+}
+
+// Resume attaches a new connection to a detached session. The client must use
+// the session ID as its ID. It returns false if the session is unknown, still
+// connected, owned by another user, or lastSeq can no longer be replayed.
+// Only sessions held by this hub are known: a session detached on another
+// node is rejected like an expired one and the client must do a full resync.
+// On success the missed events are queued ahead of any new events and the
+// session's subscriptions are restored.
+func (h *Hub) Resume(client *Client, lastSeq int64) (int, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	old, ok := h.clients[client.ID]
+	if !ok || old.UserID != client.UserID {
+		return 0, false
+	}
+
+	old.mu.Lock()
+	detached := old.detached
+	old.mu.Unlock()
+	if !detached {
+		return 0, false
+	}
+
+	frames, ok := old.session.since(lastSeq)
+	if !ok {
+		return 0, false
+	}
+
+	client.mu.Lock()
+	client.session = old.session
+	for _, key := range old.SubscriptionKeys() {
+		client.Subscriptions[key] = true
+	}
+	for _, frame := range frames {
+		select {
+		case client.Send <- frame:
+		default:
+		}
+	}
+	client.mu.Unlock()
+
+	client.session.mu.Lock()
+	client.session.detachedAt = time.Time{}
+	client.session.mu.Unlock()
+
+	h.clients[client.ID] = client
+	if h.userClients[client.UserID] == nil {
+		h.userClients[client.UserID] = make(map[string]*Client)
+	}
+	h.userClients[client.UserID][client.ID] = client
+
+	if !h.onlineUsers[client.UserID] {
+		h.onlineUsers[client.UserID] = true
+		go h.userConnected(client.UserID)
+	}
+
+	return len(frames), true
 }
 
 // Subscribe adds a subscription for a client.
@@ -214,12 +592,105 @@ func (h *Hub) Unsubscribe(clientID string, subType wsDomain.SubscriptionType, id
 	}
 }
 
-// BroadcastToSubscription sends a message to all clients subscribed to a key.
+// BroadcastToSubscription sends a message to all clients subscribed to a key,
+// on this node and on every other node in the cluster.
 func (h *Hub) BroadcastToSubscription(subType wsDomain.SubscriptionType, id string, message []byte) {
+	subKey := string(subType) + ":" + id
+	h.deliverToSubscription(subKey, message)
+
+	if h.cluster != nil {
+		h.cluster.publish(targetSubscription, subKey, message)
+	}
+}
+
+// BroadcastToUser sends a message to all connections of a user,
+// on this node and on every other node in the cluster.
+func (h *Hub) BroadcastToUser(userID string, message []byte) {
+	h.deliverToUser(userID, message)
+
+	if h.cluster != nil {
+		h.cluster.publish(targetUser, userID, message)
+	}
+}
+
+// RevalidateUser re-checks every subscription held by a user's connections
+// on all nodes, revoking the ones the user no longer has access to.
+func (h *Hub) RevalidateUser(userID string) {
+	go h.revalidateUser(userID)
+
+	if h.cluster != nil {
+		h.cluster.publish(targetRevalidateUser, userID, nil)
+	}
+}
+
+// RevalidateSubscription re-checks every client subscribed to a key on all
+// nodes, revoking access for clients that are no longer allowed.
+func (h *Hub) RevalidateSubscription(subType wsDomain.SubscriptionType, id string) {
+	subKey := string(subType) + ":" + id
+	go h.revalidateSubscription(subKey)
+
+	if h.cluster != nil {
+		h.cluster.publish(targetRevalidateSubscription, subKey, nil)
+	}
+}
+
+// IsUserOnline checks if a user is online on any node.
+func (h *Hub) IsUserOnline(userID string) bool {
+	h.mu.RLock()
+	online := h.onlineUsers[userID]
+	h.mu.RUnlock()
+
+	if online || h.cluster == nil {
+		return online
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	online, err := h.cluster.isOnlineElsewhere(ctx, userID)
+	if err != nil {
+		slog.Warn("cluster presence check failed", slog.Any("error", err), slog.String("userId", userID))
+	}
+	return online
+}
+
+// GetOnlineUsers returns all online user IDs across the cluster.
+func (h *Hub) GetOnlineUsers() []string {
+	if h.cluster != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		users, err := h.cluster.onlineUsers(ctx)
+		if err == nil {
+			return mergeUsers(users, h.localOnlineUsers())
+		}
+	}
+
+	return h.localOnlineUsers()
+}
+
+// localOnlineUsers returns the users connected to this node.
+func (h *Hub) localOnlineUsers() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	users := make([]string, 0, len(h.onlineUsers))
+	for userID := range h.onlineUsers {
+		users = append(users, userID)
+	}
+	return users
+}
+
+func (h *Hub) isLocallyOnline(userID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.onlineUsers[userID]
+}
+
+// deliverToSubscription sends a message to local clients subscribed to a key.
+func (h *Hub) deliverToSubscription(subKey string, message []byte) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	subKey := string(subType) + ":" + id
 	clientIDs, ok := h.subscriptions[subKey]
 	if !ok {
 		return
@@ -227,78 +698,166 @@ func (h *Hub) BroadcastToSubscription(subType wsDomain.SubscriptionType, id stri
 
 	for clientID := range clientIDs {
 		if client, ok := h.clients[clientID]; ok {
-			select {
-			case client.Send <- message:
-			default:
-				// Client buffer full, skip
-			}
+			client.Deliver(message)
 		}
 	}
 }
 
-// BroadcastToUser sends a message to all connections of a user.
-func (h *Hub) BroadcastToUser(userID string, message []byte) {
+// deliverToUser sends a message to local sessions of a user.
+// Every session is subscribed to its user's personal channel.
+func (h *Hub) deliverToUser(userID string, message []byte) {
+	h.deliverToSubscription("user:"+userID, message)
+}
+
+// subscriptionCheck is a single client subscription to revalidate.
+type subscriptionCheck struct {
+	client *Client
+	sub    wsDomain.Subscription
+}
+
+func (h *Hub) revalidateUser(userID string) {
 	h.mu.RLock()
-	defer h.mu.RUnlock()
+	var checks []subscriptionCheck
+	for clientID := range h.subscriptions["user:"+userID] {
+		client, ok := h.clients[clientID]
+		if !ok {
+			continue
+		}
+		for _, key := range client.SubscriptionKeys() {
+			if sub, ok := wsDomain.ParseSubscriptionKey(key); ok {
+				checks = append(checks, subscriptionCheck{client: client, sub: sub})
+			}
+		}
+	}
+	h.mu.RUnlock()
 
-	clients, ok := h.userClients[userID]
+	h.revalidate(checks)
+}
+
+func (h *Hub) revalidateSubscription(subKey string) {
+	sub, ok := wsDomain.ParseSubscriptionKey(subKey)
 	if !ok {
 		return
 	}
 
-	for _, client := range clients {
-		select {
-		case client.Send <- message:
-		default:
-			// Client buffer full, skip
+	h.mu.RLock()
+	var checks []subscriptionCheck
+	for clientID := range h.subscriptions[subKey] {
+		if client, ok := h.clients[clientID]; ok {
+			checks = append(checks, subscriptionCheck{client: client, sub: sub})
 		}
 	}
+	h.mu.RUnlock()
+
+	h.revalidate(checks)
 }
 
-// IsUserOnline checks if a user is online.
-func (h *Hub) IsUserOnline(userID string) bool {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
-	return h.onlineUsers[userID]
+// revalidate runs the authorizer outside the hub lock and removes the
+// subscriptions it denies. Transient errors keep the subscription.
+func (h *Hub) revalidate(checks []subscriptionCheck) {
+	if h.authorizer == nil || len(checks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, check := range checks {
+		err := h.authorizer.Authorize(ctx, check.client.UserID, check.sub)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, wsDomain.ErrSubscriptionForbidden) && !errors.Is(err, wsDomain.ErrInvalidSubscription) {
+			slog.Warn("subscription revalidation failed", slog.Any("error", err), slog.String("key", check.sub.Key()))
+			continue
+		}
+
+		h.Unsubscribe(check.client.ID, check.sub.Type, check.sub.ID)
+
+		msg, err := wsDomain.NewMessage(wsDomain.EventRevoked, wsDomain.SubscriptionRevokedEventData{
+			Subscription: check.sub,
+		})
+		if err != nil {
+			continue
+		}
+		if data, err := json.Marshal(msg); err == nil {
+			h.deliverToClient(check.client.ID, data)
+		}
+	}
 }
 
-// GetOnlineUsers returns all online user IDs.
-func (h *Hub) GetOnlineUsers() []string {
+// deliverToClient sends a message to a single local client if it is still connected.
+func (h *Hub) deliverToClient(clientID string, message []byte) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	users := make([]string, 0, len(h.onlineUsers))
-	for userID := range h.onlineUsers {
-		users = append(users, userID)
+	if client, ok := h.clients[clientID]; ok {
+		client.Deliver(message)
 	}
-	return users
 }
 
-func (h *Hub) broadcastPresence(userID string, isOnline bool) {
-	msg, err := wsDomain.NewMessage(wsDomain.EventUserOnline, wsDomain.PresenceEventData{
-		UserID:   userID,
-		IsOnline: isOnline,
-	})
-	if err != nil {
-		return
+// userConnected handles a user's first connection to this node.
+// Presence is only broadcast if the user was not already online elsewhere.
+func (h *Hub) userConnected(userID string) {
+	if h.cluster != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		if !h.isLocallyOnline(userID) {
+			return
+		}
+		h.cluster.addUser(ctx, userID)
+		// When other nodes cannot be checked, announcing the user again
+		// is harmless
+		if elsewhere, _ := h.cluster.isOnlineElsewhere(ctx, userID); elsewhere {
+			return
+		}
 	}
 
-	if !isOnline {
-		msg.Type = wsDomain.EventUserOffline
+	if h.presenceListener != nil {
+		h.presenceListener(userID, true)
 	}
+}
 
-	data, err := json.Marshal(msg)
-	if err != nil {
-		return
+// userDisconnected handles a user's last connection to this node closing.
+// Presence is only broadcast if the user has no connections on other nodes.
+func (h *Hub) userDisconnected(userID string) {
+	if h.cluster != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		// The user reconnected before we got here
+		if h.isLocallyOnline(userID) {
+			return
+		}
+		h.cluster.removeUser(ctx, userID)
+		// When other nodes cannot be checked the user may still be
+		// connected to one, so they are not reported offline
+		elsewhere, err := h.cluster.isOnlineElsewhere(ctx, userID)
+		if err != nil {
+			slog.Warn("cluster presence check failed, not reporting user offline", slog.Any("error", err), slog.String("userId", userID))
+			return
+		}
+		if elsewhere {
+			return
+		}
 	}
 
-	// Broadcast to all connected clients (simplified)
-	h.mu.RLock()
-	for _, client := range h.clients {
-		select {
-		case client.Send <- data:
-		default:
+	if h.presenceListener != nil {
+		h.presenceListener(userID, false)
+	}
+}
+
+func mergeUsers(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	users := make([]string, 0, len(a)+len(b))
+	for _, list := range [][]string{a, b} {
+		for _, u := range list {
+			if !seen[u] {
+				seen[u] = true
+				users = append(users, u)
+			}
 		}
 	}
-	h.mu.RUnlock()
+	return users
 }
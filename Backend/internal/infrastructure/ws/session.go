@@ -0,0 +1,125 @@
+package ws
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionReplaySize is the number of recent events kept per session for
+	// replay. It must stay below the client send buffer so a full replay can
+	// be queued at once.
+	sessionReplaySize = 200
+
+	// sessionTTL is how long a disconnected session can still be resumed.
+	sessionTTL = 2 * time.Minute
+
+	// sessionSweepInterval is how often expired sessions are removed.
+	sessionSweepInterval = 30 * time.Second
+)
+
+// sequencedFrame is an outgoing event with its session sequence number.
+type sequencedFrame struct {
+	seq  int64
+	data []byte
+}
+
+// session holds the per-connection state that survives a reconnect: the
+// event sequence counter and a bounded buffer of recent events.
+//
+// Sessions live in the memory of the node that accepted the connection and
+// are not shared through the cluster. A reconnect that lands on another node
+// cannot resume; the client is told its session is invalid and must resync.
+type session struct {
+	mu         sync.Mutex
+	seq        int64
+	buffer     []sequencedFrame
+	detachedAt time.Time
+
+	// Set when the client must resync instead of resuming
+	invalid bool
+}
+
+func newSession() *session {
+	return &session{buffer: make([]sequencedFrame, 0, sessionReplaySize)}
+}
+
+// record assigns the next sequence number to a message, stores it in the
+// replay buffer and returns the framed message.
+func (s *session) record(message []byte) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	framed := withSeq(message, s.seq)
+
+	if len(s.buffer) == sessionReplaySize {
+		s.buffer = append(s.buffer[:0:0], s.buffer[1:]...)
+	}
+	s.buffer = append(s.buffer, sequencedFrame{seq: s.seq, data: framed})
+
+	return framed
+}
+
+// since returns the buffered events after lastSeq. It returns false when
+// events after lastSeq were already evicted, lastSeq is in the future, or the
+// session was invalidated.
+func (s *session) since(lastSeq int64) ([][]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.invalid || lastSeq < 0 || lastSeq > s.seq {
+		return nil, false
+	}
+	if lastSeq == s.seq {
+		return [][]byte{}, true
+	}
+	if len(s.buffer) == 0 || s.buffer[0].seq > lastSeq+1 {
+		return nil, false
+	}
+
+	frames := make([][]byte, 0, s.seq-lastSeq)
+	for _, f := range s.buffer {
+		if f.seq > lastSeq {
+			frames = append(frames, f.data)
+		}
+	}
+	return frames, true
+}
+
+// invalidate prevents the session from being resumed.
+func (s *session) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalid = true
+}
+
+func (s *session) markDetached() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.detachedAt = time.Now()
+}
+
+func (s *session) expired(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.detachedAt.IsZero() && now.Sub(s.detachedAt) > sessionTTL
+}
+
+// withSeq injects a "seq" field into a JSON object message.
+func withSeq(message []byte, seq int64) []byte {
+	if len(message) < 2 || message[0] != '{' {
+		return message
+	}
+
+	prefix := `{"seq":` + strconv.FormatInt(seq, 10)
+	rest := message[1:]
+	if rest[0] != '}' {
+		prefix += ","
+	}
+
+	framed := make([]byte, 0, len(prefix)+len(rest))
+	framed = append(framed, prefix...)
+	return append(framed, rest...)
+}
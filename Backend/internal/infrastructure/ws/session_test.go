@@ -0,0 +1,65 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithSeq(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "object", message: `{"type":"connected"}`, want: `{"seq":7,"type":"connected"}`},
+		{name: "empty object", message: `{}`, want: `{"seq":7}`},
+		{name: "not an object", message: `[1]`, want: `[1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, string(withSeq([]byte(tt.message), 7)))
+		})
+	}
+}
+
+func TestSession_Since(t *testing.T) {
+	s := newSession()
+	for i := 0; i < 3; i++ {
+		s.record([]byte(`{"type":"x"}`))
+	}
+
+	frames, ok := s.since(1)
+	require.True(t, ok)
+	require.Len(t, frames, 2)
+
+	var msg struct {
+		Seq int64 `json:"seq"`
+	}
+	require.NoError(t, json.Unmarshal(frames[0], &msg))
+	assert.Equal(t, int64(2), msg.Seq)
+
+	frames, ok = s.since(3)
+	assert.True(t, ok)
+	assert.Empty(t, frames)
+
+	_, ok = s.since(4)
+	assert.False(t, ok, "future sequence numbers cannot be resumed")
+}
+
+func TestSession_Since_Evicted(t *testing.T) {
+	s := newSession()
+	for i := 0; i < sessionReplaySize+10; i++ {
+		s.record([]byte(`{"type":"x"}`))
+	}
+
+	_, ok := s.since(5)
+	assert.False(t, ok, "evicted events cannot be replayed")
+
+	frames, ok := s.since(10)
+	require.True(t, ok)
+	assert.Len(t, frames, sessionReplaySize)
+}
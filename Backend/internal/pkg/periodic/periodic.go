@@ -0,0 +1,27 @@
+// Package periodic runs background sweeps on a fixed interval.
+package periodic
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Run calls sweep once right away and then every interval until ctx is
+// cancelled. name identifies the worker in the start and stop logs.
+func Run(ctx context.Context, name string, interval time.Duration, sweep func(context.Context)) {
+	slog.Info(name+" started", slog.Duration("interval", interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sweep(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info(name + " stopped")
+			return
+		case <-ticker.C:
+			sweep(ctx)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package periodic
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var sweeps atomic.Int32
+	done := make(chan struct{})
+
+	go func() {
+		Run(ctx, "test worker", 10*time.Millisecond, func(context.Context) {
+			sweeps.Add(1)
+		})
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return sweeps.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func TestRun_SweepsBeforeFirstTick(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	swept := make(chan struct{}, 1)
+
+	go Run(ctx, "test worker", time.Hour, func(context.Context) {
+		select {
+		case swept <- struct{}{}:
+		default:
+		}
+	})
+
+	select {
+	case <-swept:
+	case <-time.After(time.Second):
+		t.Fatal("first sweep waited for the interval")
+	}
+}
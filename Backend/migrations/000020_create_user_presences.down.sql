@@ -0,0 +1,5 @@
+-- 000020_create_user_presences.down.sql
+-- Rollback rich presence
+
+DROP TRIGGER IF EXISTS update_user_presences_updated_at ON user_presences;
+DROP TABLE IF EXISTS user_presences;
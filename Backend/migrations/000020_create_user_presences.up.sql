@@ -0,0 +1,30 @@
+-- 000020_create_user_presences.up.sql
+-- Rich presence: chosen status, custom status and activity
+
+CREATE TABLE user_presences (
+    user_id                 VARCHAR(26) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+
+    -- Status: 'online', 'idle', 'dnd', 'invisible'
+    status                  VARCHAR(20) NOT NULL DEFAULT 'online',
+
+    -- Custom status
+    custom_status_text      VARCHAR(128),
+    custom_status_emoji     VARCHAR(64),
+    custom_status_expires_at TIMESTAMPTZ,
+
+    -- Activity: 'playing', 'listening', 'watching', 'streaming'
+    activity_type           VARCHAR(20),
+    activity_name           VARCHAR(128),
+    activity_details        VARCHAR(128),
+
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT presence_status_valid CHECK (status IN ('online', 'idle', 'dnd', 'invisible')),
+    CONSTRAINT presence_activity_type_valid CHECK (activity_type IS NULL OR activity_type IN ('playing', 'listening', 'watching', 'streaming'))
+);
+
+-- Trigger for updated_at
+CREATE TRIGGER update_user_presences_updated_at
+    BEFORE UPDATE ON user_presences
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
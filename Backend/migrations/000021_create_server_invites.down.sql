@@ -0,0 +1,9 @@
+-- 000021_create_server_invites.down.sql
+
+DROP INDEX IF EXISTS idx_server_members_temporary;
+
+ALTER TABLE server_members
+DROP COLUMN IF EXISTS is_temporary,
+DROP COLUMN IF EXISTS invite_code;
+
+DROP TABLE IF EXISTS server_invites;
@@ -0,0 +1,30 @@
+-- 000021_create_server_invites.up.sql
+-- Invite links and which invite each member joined with
+
+-- ============================================================================
+-- SERVER INVITES TABLE
+-- ============================================================================
+CREATE TABLE server_invites (
+    code        VARCHAR(16) PRIMARY KEY,
+    server_id   VARCHAR(26) NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    channel_id  VARCHAR(26) REFERENCES channels(id) ON DELETE SET NULL, -- Channel shown when joining
+    creator_id  VARCHAR(26) REFERENCES users(id) ON DELETE SET NULL,
+    max_uses    INT NOT NULL DEFAULT 0, -- 0 = unlimited
+    uses        INT NOT NULL DEFAULT 0,
+    expires_at  TIMESTAMPTZ, -- NULL = never
+    temporary   BOOLEAN NOT NULL DEFAULT FALSE, -- Members are removed when they disconnect without a role
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT invite_max_uses_valid CHECK (max_uses >= 0)
+);
+
+CREATE INDEX idx_server_invites_server_id ON server_invites(server_id);
+
+-- ============================================================================
+-- INVITE TRACKING (Add to server_members)
+-- ============================================================================
+ALTER TABLE server_members
+ADD COLUMN invite_code VARCHAR(16), -- Kept after the invite is revoked
+ADD COLUMN is_temporary BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_server_members_temporary ON server_members(user_id) WHERE is_temporary;
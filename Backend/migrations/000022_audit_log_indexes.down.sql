@@ -0,0 +1,5 @@
+-- 000022_audit_log_indexes.down.sql
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_server_id ON audit_logs(server_id);
+DROP INDEX IF EXISTS idx_audit_logs_server_actor;
+DROP INDEX IF EXISTS idx_audit_logs_server_created;
@@ -0,0 +1,11 @@
+-- 000022_audit_log_indexes.up.sql
+-- Indexes for filtering and paging the audit log
+
+-- Keyset pagination of a server's audit log, newest first
+CREATE INDEX idx_audit_logs_server_created ON audit_logs(server_id, created_at DESC, id DESC);
+
+-- Filtering by actor
+CREATE INDEX idx_audit_logs_server_actor ON audit_logs(server_id, actor_id, created_at DESC);
+
+-- Superseded by idx_audit_logs_server_created
+DROP INDEX IF EXISTS idx_audit_logs_server_id;
@@ -0,0 +1,11 @@
+-- 000023_create_threads.down.sql
+
+DROP INDEX IF EXISTS idx_channel_messages_thread;
+
+ALTER TABLE channel_messages
+DROP COLUMN IF EXISTS thread_id;
+
+DROP TABLE IF EXISTS thread_read_states;
+DROP TABLE IF EXISTS thread_members;
+DROP TRIGGER IF EXISTS update_threads_updated_at ON threads;
+DROP TABLE IF EXISTS threads;
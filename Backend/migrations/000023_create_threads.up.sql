@@ -0,0 +1,71 @@
+-- 000023_create_threads.up.sql
+-- Threads: sub-conversations in a channel with their own members and read state
+
+-- ============================================================================
+-- THREADS TABLE
+-- ============================================================================
+CREATE TABLE threads (
+    id                   VARCHAR(26) PRIMARY KEY,
+    channel_id           VARCHAR(26) NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    server_id            VARCHAR(26) NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    parent_message_id    VARCHAR(26) REFERENCES channel_messages(id) ON DELETE SET NULL, -- NULL = standalone
+    owner_id             VARCHAR(26) REFERENCES users(id) ON DELETE SET NULL,
+    name                 VARCHAR(100) NOT NULL,
+    message_count        INT NOT NULL DEFAULT 0,
+    member_count         INT NOT NULL DEFAULT 0,
+    is_archived          BOOLEAN NOT NULL DEFAULT FALSE,
+    is_locked            BOOLEAN NOT NULL DEFAULT FALSE,
+    auto_archive_minutes INT NOT NULL DEFAULT 1440,
+    last_message_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    archived_at          TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT thread_name_length CHECK (char_length(name) >= 1 AND char_length(name) <= 100),
+    CONSTRAINT thread_auto_archive_valid CHECK (auto_archive_minutes IN (60, 1440, 4320, 10080))
+);
+
+-- A message can start at most one thread
+CREATE UNIQUE INDEX idx_threads_parent_message ON threads(parent_message_id) WHERE parent_message_id IS NOT NULL;
+
+-- Listing active threads by activity and archived threads by archive time
+CREATE INDEX idx_threads_channel_active ON threads(channel_id, last_message_at DESC, id DESC) WHERE NOT is_archived;
+CREATE INDEX idx_threads_channel_archived ON threads(channel_id, archived_at DESC, id DESC) WHERE is_archived;
+
+CREATE TRIGGER update_threads_updated_at
+    BEFORE UPDATE ON threads
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- THREAD MEMBERS TABLE
+-- ============================================================================
+CREATE TABLE thread_members (
+    thread_id   VARCHAR(26) NOT NULL REFERENCES threads(id) ON DELETE CASCADE,
+    user_id     VARCHAR(26) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    joined_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (thread_id, user_id)
+);
+
+CREATE INDEX idx_thread_members_user_id ON thread_members(user_id);
+
+-- ============================================================================
+-- THREAD READ STATES TABLE
+-- ============================================================================
+CREATE TABLE thread_read_states (
+    thread_id            VARCHAR(26) NOT NULL REFERENCES threads(id) ON DELETE CASCADE,
+    user_id              VARCHAR(26) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_read_message_id VARCHAR(26),
+    last_read_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (thread_id, user_id)
+);
+
+-- ============================================================================
+-- THREAD MESSAGES (Add to channel_messages)
+-- ============================================================================
+ALTER TABLE channel_messages
+ADD COLUMN thread_id VARCHAR(26) REFERENCES threads(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_channel_messages_thread ON channel_messages(thread_id, created_at DESC) WHERE thread_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- 000024_create_message_reactions.down.sql
+
+UPDATE roles SET permissions = permissions & ~65536::BIGINT;
+
+DROP TABLE IF EXISTS message_reactions;
@@ -0,0 +1,27 @@
+-- 000024_create_message_reactions.up.sql
+-- Emoji reactions on channel and DM messages
+
+-- ============================================================================
+-- MESSAGE REACTIONS TABLE
+-- ============================================================================
+CREATE TABLE message_reactions (
+    channel_message_id VARCHAR(26) REFERENCES channel_messages(id) ON DELETE CASCADE,
+    dm_message_id      VARCHAR(26) REFERENCES dm_messages(id) ON DELETE CASCADE,
+    message_id         VARCHAR(26) GENERATED ALWAYS AS (COALESCE(channel_message_id, dm_message_id)) STORED,
+    user_id            VARCHAR(26) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji_name         VARCHAR(64) NOT NULL, -- Unicode emoji, or the custom emoji's name
+    emoji_id           VARCHAR(26) NOT NULL DEFAULT '', -- Custom emoji ID, '' for Unicode
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT message_reaction_one_target CHECK ((channel_message_id IS NULL) <> (dm_message_id IS NULL)),
+    CONSTRAINT message_reaction_unique UNIQUE (message_id, emoji_name, emoji_id, user_id)
+);
+
+-- Listing who reacted with an emoji, in order
+CREATE INDEX idx_message_reactions_emoji_created ON message_reactions(message_id, emoji_name, emoji_id, created_at, user_id);
+CREATE INDEX idx_message_reactions_user_id ON message_reactions(user_id);
+
+-- ============================================================================
+-- ADD_REACTIONS PERMISSION (1 << 16) for existing @everyone roles
+-- ============================================================================
+UPDATE roles SET permissions = permissions | 65536 WHERE is_default;
@@ -0,0 +1,13 @@
+-- 000025_message_pins.down.sql
+
+DROP INDEX IF EXISTS idx_dm_messages_pinned;
+ALTER TABLE dm_messages
+    DROP COLUMN IF EXISTS pinned_by,
+    DROP COLUMN IF EXISTS pinned_at,
+    DROP COLUMN IF EXISTS is_pinned;
+
+DROP INDEX IF EXISTS idx_channel_messages_pinned;
+CREATE INDEX idx_channel_messages_pinned ON channel_messages(channel_id, is_pinned) WHERE is_pinned = TRUE;
+ALTER TABLE channel_messages
+    DROP COLUMN IF EXISTS pinned_by,
+    DROP COLUMN IF EXISTS pinned_at;
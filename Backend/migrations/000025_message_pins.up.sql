@@ -0,0 +1,25 @@
+-- 000025_message_pins.up.sql
+-- Pinning channel and DM messages
+
+-- ============================================================================
+-- CHANNEL MESSAGE PINS
+-- ============================================================================
+ALTER TABLE channel_messages
+    ADD COLUMN pinned_at TIMESTAMPTZ,
+    ADD COLUMN pinned_by VARCHAR(26) REFERENCES users(id) ON DELETE SET NULL;
+
+UPDATE channel_messages SET pinned_at = updated_at WHERE is_pinned;
+
+-- Listing a channel's pins, most recently pinned first
+DROP INDEX IF EXISTS idx_channel_messages_pinned;
+CREATE INDEX idx_channel_messages_pinned ON channel_messages(channel_id, pinned_at DESC) WHERE is_pinned = TRUE;
+
+-- ============================================================================
+-- DM MESSAGE PINS
+-- ============================================================================
+ALTER TABLE dm_messages
+    ADD COLUMN is_pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN pinned_at TIMESTAMPTZ,
+    ADD COLUMN pinned_by VARCHAR(26) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_dm_messages_pinned ON dm_messages(conversation_id, pinned_at DESC) WHERE is_pinned = TRUE;
@@ -0,0 +1,18 @@
+-- 000026_create_message_attachments.down.sql
+
+UPDATE roles SET permissions = permissions & ~16384::BIGINT WHERE is_default;
+
+-- Attachment-only messages cannot be kept once content is required again
+DELETE FROM dm_messages WHERE char_length(content) = 0;
+ALTER TABLE dm_messages DROP CONSTRAINT dm_message_content_length;
+ALTER TABLE dm_messages ADD CONSTRAINT dm_message_content_length CHECK (char_length(content) >= 1 AND char_length(content) <= 2000);
+
+DELETE FROM channel_messages WHERE char_length(content) = 0;
+ALTER TABLE channel_messages DROP CONSTRAINT channel_message_content_length;
+ALTER TABLE channel_messages ADD CONSTRAINT channel_message_content_length CHECK (char_length(content) >= 1 AND char_length(content) <= 2000);
+
+DROP TABLE IF EXISTS message_attachments;
+
+ALTER TABLE media
+    DROP COLUMN IF EXISTS height,
+    DROP COLUMN IF EXISTS width;
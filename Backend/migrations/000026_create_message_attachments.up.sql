@@ -0,0 +1,47 @@
+-- 000026_create_message_attachments.up.sql
+-- Uploaded files attached to channel and DM messages
+
+-- ============================================================================
+-- MEDIA DIMENSIONS
+-- ============================================================================
+ALTER TABLE media
+    ADD COLUMN width  INT,
+    ADD COLUMN height INT;
+
+-- ============================================================================
+-- MESSAGE ATTACHMENTS TABLE
+-- ============================================================================
+-- Copies of cross-posted announcements get attachment rows of their own that
+-- share the source message's media, so they keep their files when the
+-- source message is deleted
+CREATE TABLE message_attachments (
+    media_id           VARCHAR(50) NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    channel_message_id VARCHAR(26) REFERENCES channel_messages(id) ON DELETE CASCADE,
+    dm_message_id      VARCHAR(26) REFERENCES dm_messages(id) ON DELETE CASCADE,
+    message_id         VARCHAR(26) GENERATED ALWAYS AS (COALESCE(channel_message_id, dm_message_id)) STORED,
+    position           SMALLINT NOT NULL DEFAULT 0,
+    is_copy            BOOLEAN NOT NULL DEFAULT FALSE,
+
+    PRIMARY KEY (media_id, message_id),
+    CONSTRAINT message_attachment_one_target CHECK ((channel_message_id IS NULL) <> (dm_message_id IS NULL))
+);
+
+CREATE INDEX idx_message_attachments_message ON message_attachments(message_id, position);
+
+-- Outside of copies a media record is attached to at most one message
+CREATE UNIQUE INDEX idx_message_attachments_media ON message_attachments(media_id) WHERE NOT is_copy;
+
+-- ============================================================================
+-- ATTACHMENT-ONLY MESSAGES
+-- ============================================================================
+-- Messages may have no text when they carry attachments
+ALTER TABLE channel_messages DROP CONSTRAINT channel_message_content_length;
+ALTER TABLE channel_messages ADD CONSTRAINT channel_message_content_length CHECK (char_length(content) <= 2000);
+
+ALTER TABLE dm_messages DROP CONSTRAINT dm_message_content_length;
+ALTER TABLE dm_messages ADD CONSTRAINT dm_message_content_length CHECK (char_length(content) <= 2000);
+
+-- ============================================================================
+-- ATTACH_FILES PERMISSION (1 << 14) for existing @everyone roles
+-- ============================================================================
+UPDATE roles SET permissions = permissions | 16384 WHERE is_default;
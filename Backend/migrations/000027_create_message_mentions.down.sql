@@ -0,0 +1,14 @@
+-- 000027_create_message_mentions.down.sql
+
+DROP TABLE IF EXISTS server_notification_settings;
+
+ALTER TABLE read_states
+    DROP COLUMN IF EXISTS mention_count;
+
+DROP INDEX IF EXISTS idx_channel_messages_mention_users;
+
+ALTER TABLE channel_messages
+    DROP COLUMN IF EXISTS mention_here,
+    DROP COLUMN IF EXISTS mention_everyone,
+    DROP COLUMN IF EXISTS mention_role_ids,
+    DROP COLUMN IF EXISTS mention_user_ids;
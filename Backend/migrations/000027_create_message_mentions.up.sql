@@ -0,0 +1,40 @@
+-- 000027_create_message_mentions.up.sql
+-- Mentions in channel messages, per-server notification settings and
+-- per-channel mention counts
+
+-- ============================================================================
+-- MESSAGE MENTIONS
+-- ============================================================================
+-- The resolved mention set is stored with each message
+ALTER TABLE channel_messages
+    ADD COLUMN mention_user_ids VARCHAR(26)[] NOT NULL DEFAULT '{}',
+    ADD COLUMN mention_role_ids VARCHAR(26)[] NOT NULL DEFAULT '{}',
+    ADD COLUMN mention_everyone BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN mention_here     BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_channel_messages_mention_users ON channel_messages USING GIN (mention_user_ids);
+
+-- ============================================================================
+-- MENTION COUNTS
+-- ============================================================================
+-- Mentions since the user last acknowledged the channel
+ALTER TABLE read_states
+    ADD COLUMN mention_count INT NOT NULL DEFAULT 0;
+
+-- ============================================================================
+-- SERVER NOTIFICATION SETTINGS TABLE
+-- ============================================================================
+-- Members without a row use the defaults: notify for mentions, nothing
+-- suppressed, not muted
+CREATE TABLE server_notification_settings (
+    server_id         VARCHAR(26) NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    user_id           VARCHAR(26) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    level             VARCHAR(20) NOT NULL DEFAULT 'mentions',
+    suppress_everyone BOOLEAN NOT NULL DEFAULT FALSE,
+    suppress_roles    BOOLEAN NOT NULL DEFAULT FALSE,
+    muted_until       TIMESTAMPTZ,
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (server_id, user_id),
+    CONSTRAINT server_notification_level CHECK (level IN ('all', 'mentions', 'nothing'))
+);
@@ -0,0 +1,12 @@
+-- 000028_add_slowmode.down.sql
+
+ALTER TABLE streams
+    DROP CONSTRAINT IF EXISTS streams_slowmode_range,
+    DROP COLUMN IF EXISTS slowmode_seconds;
+
+ALTER TABLE channels
+    DROP CONSTRAINT IF EXISTS channels_slowmode_range,
+    DROP COLUMN IF EXISTS slowmode_seconds;
+
+-- BYPASS_SLOWMODE (1 << 17) is no longer a permission
+UPDATE roles SET permissions = permissions & ~131072::BIGINT;
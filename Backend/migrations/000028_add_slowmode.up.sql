@@ -0,0 +1,17 @@
+-- 000028_add_slowmode.up.sql
+-- Per-member cooldown between messages in channels (and their threads) and
+-- stream chats. 0 = off, at most six hours.
+
+-- ============================================================================
+-- CHANNEL SLOWMODE
+-- ============================================================================
+ALTER TABLE channels
+    ADD COLUMN slowmode_seconds INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT channels_slowmode_range CHECK (slowmode_seconds BETWEEN 0 AND 21600);
+
+-- ============================================================================
+-- STREAM CHAT SLOWMODE
+-- ============================================================================
+ALTER TABLE streams
+    ADD COLUMN slowmode_seconds INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT streams_slowmode_range CHECK (slowmode_seconds BETWEEN 0 AND 21600);
@@ -0,0 +1,7 @@
+-- 000029_create_message_revisions.down.sql
+
+ALTER TABLE servers
+    DROP CONSTRAINT IF EXISTS servers_revision_retention_range,
+    DROP COLUMN IF EXISTS revision_retention_days;
+
+DROP TABLE IF EXISTS message_revisions;
@@ -0,0 +1,30 @@
+-- 000029_create_message_revisions.up.sql
+-- Edit history of channel and DM messages: the content a message had before
+-- each edit. Servers choose how long channel message revisions are kept.
+
+-- ============================================================================
+-- MESSAGE REVISIONS TABLE
+-- ============================================================================
+CREATE TABLE message_revisions (
+    id                 VARCHAR(26) PRIMARY KEY,
+    channel_message_id VARCHAR(26) REFERENCES channel_messages(id) ON DELETE CASCADE,
+    dm_message_id      VARCHAR(26) REFERENCES dm_messages(id) ON DELETE CASCADE,
+    message_id         VARCHAR(26) GENERATED ALWAYS AS (COALESCE(channel_message_id, dm_message_id)) STORED,
+    content            TEXT NOT NULL, -- Content before the edit
+    edited_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT message_revision_one_target CHECK ((channel_message_id IS NULL) <> (dm_message_id IS NULL))
+);
+
+-- Listing a message's history in order
+CREATE INDEX idx_message_revisions_message_edited ON message_revisions(message_id, edited_at);
+-- Retention sweep
+CREATE INDEX idx_message_revisions_edited_at ON message_revisions(edited_at) WHERE channel_message_id IS NOT NULL;
+
+-- ============================================================================
+-- SERVER REVISION RETENTION
+-- ============================================================================
+-- Days channel message revisions are kept; 0 = as long as the message
+ALTER TABLE servers
+    ADD COLUMN revision_retention_days INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT servers_revision_retention_range CHECK (revision_retention_days BETWEEN 0 AND 3650);
@@ -0,0 +1,8 @@
+-- 000030_message_search_vector.down.sql
+
+DROP INDEX IF EXISTS idx_channel_messages_server_created;
+DROP INDEX IF EXISTS idx_channel_messages_search_vector;
+
+ALTER TABLE channel_messages DROP COLUMN IF EXISTS search_vector;
+
+CREATE INDEX idx_channel_messages_content_search ON channel_messages USING gin(to_tsvector('english', content));
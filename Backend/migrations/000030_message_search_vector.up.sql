@@ -0,0 +1,16 @@
+-- 000030_message_search_vector.up.sql
+-- Stored search vector for server-wide message search
+
+-- ============================================================================
+-- SEARCH VECTOR
+-- ============================================================================
+ALTER TABLE channel_messages
+    ADD COLUMN search_vector TSVECTOR
+        GENERATED ALWAYS AS (to_tsvector('english', content)) STORED;
+
+-- Replaces the expression index; searches now match the stored column
+DROP INDEX IF EXISTS idx_channel_messages_content_search;
+CREATE INDEX idx_channel_messages_search_vector ON channel_messages USING GIN (search_vector);
+
+-- Filter-only searches list a server's messages newest first
+CREATE INDEX idx_channel_messages_server_created ON channel_messages(server_id, created_at DESC);
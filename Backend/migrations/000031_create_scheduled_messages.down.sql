@@ -0,0 +1,3 @@
+-- 000031_create_scheduled_messages.down.sql
+
+DROP TABLE IF EXISTS scheduled_messages;
@@ -0,0 +1,29 @@
+-- 000031_create_scheduled_messages.up.sql
+-- Channel messages queued to be published later
+
+-- ============================================================================
+-- SCHEDULED MESSAGES TABLE
+-- ============================================================================
+CREATE TABLE scheduled_messages (
+    id             VARCHAR(26) PRIMARY KEY,
+    server_id      VARCHAR(26) NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    channel_id     VARCHAR(26) NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    author_id      VARCHAR(26) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content        TEXT NOT NULL DEFAULT '',
+    attachment_ids VARCHAR(50)[] NOT NULL DEFAULT '{}',
+    publish_at     TIMESTAMPTZ NOT NULL,
+    status         VARCHAR(20) NOT NULL DEFAULT 'pending',
+    failure        TEXT NOT NULL DEFAULT '',
+    claimed_at     TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT scheduled_message_status_valid CHECK (status IN ('pending', 'publishing', 'failed'))
+);
+
+-- Due messages are claimed in publish order
+CREATE INDEX idx_scheduled_messages_due ON scheduled_messages(publish_at) WHERE status = 'pending';
+-- Claims left behind by a publisher that stopped are taken over
+CREATE INDEX idx_scheduled_messages_claimed ON scheduled_messages(claimed_at) WHERE status = 'publishing';
+CREATE INDEX idx_scheduled_messages_author ON scheduled_messages(server_id, author_id, publish_at);
+CREATE INDEX idx_scheduled_messages_channel ON scheduled_messages(channel_id);
@@ -0,0 +1,14 @@
+-- 000032_create_channel_follows.down.sql
+
+-- Copies no longer know their source once attribution is dropped
+DELETE FROM message_attachments WHERE is_copy;
+
+ALTER TABLE channel_messages
+    DROP COLUMN IF EXISTS source_server_name,
+    DROP COLUMN IF EXISTS source_channel_name,
+    DROP COLUMN IF EXISTS source_server_id,
+    DROP COLUMN IF EXISTS source_channel_id,
+    DROP COLUMN IF EXISTS source_message_id,
+    DROP COLUMN IF EXISTS crossposted_at;
+
+DROP TABLE IF EXISTS channel_follows;
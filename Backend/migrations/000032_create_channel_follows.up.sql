@@ -0,0 +1,34 @@
+-- 000032_create_channel_follows.up.sql
+-- Announcement channels followed into other servers' text channels, and the
+-- attributed copies their published announcements leave there
+
+-- ============================================================================
+-- CHANNEL FOLLOWS TABLE
+-- ============================================================================
+CREATE TABLE channel_follows (
+    id                VARCHAR(26) PRIMARY KEY,
+    source_server_id  VARCHAR(26) NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    source_channel_id VARCHAR(26) NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    target_server_id  VARCHAR(26) NOT NULL REFERENCES servers(id) ON DELETE CASCADE,
+    target_channel_id VARCHAR(26) NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    created_by        VARCHAR(26) REFERENCES users(id) ON DELETE SET NULL,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_channel_follow UNIQUE (source_channel_id, target_channel_id)
+);
+
+CREATE INDEX idx_channel_follows_target ON channel_follows(target_channel_id);
+
+-- ============================================================================
+-- CROSS-POSTED MESSAGES
+-- ============================================================================
+-- Announcements record when they were published to followers. Copies keep
+-- the source message and the names it was published under, so attribution
+-- outlives the source channel.
+ALTER TABLE channel_messages
+    ADD COLUMN crossposted_at      TIMESTAMPTZ,
+    ADD COLUMN source_message_id   VARCHAR(26),
+    ADD COLUMN source_channel_id   VARCHAR(26),
+    ADD COLUMN source_server_id    VARCHAR(26),
+    ADD COLUMN source_channel_name VARCHAR(100),
+    ADD COLUMN source_server_name  VARCHAR(100);